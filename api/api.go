@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Peripli/service-manager/operations"
 	"github.com/Peripli/service-manager/pkg/env"
@@ -39,8 +40,12 @@ import (
 
 	"github.com/Peripli/service-manager/api/filters"
 	"github.com/Peripli/service-manager/api/info"
+	"github.com/Peripli/service-manager/api/openapi"
 	"github.com/Peripli/service-manager/api/osb"
 	"github.com/Peripli/service-manager/pkg/health"
+	"github.com/Peripli/service-manager/pkg/hotreload"
+	"github.com/Peripli/service-manager/pkg/maintenance"
+	"github.com/Peripli/service-manager/pkg/security/authenticators"
 	"github.com/Peripli/service-manager/pkg/web"
 	"github.com/Peripli/service-manager/storage"
 )
@@ -51,11 +56,61 @@ const osbVersion = "2.13"
 type Settings struct {
 	TokenIssuerURL  string   `mapstructure:"token_issuer_url" description:"url of the token issuer which to use for validating tokens"`
 	ClientID        string   `mapstructure:"client_id" description:"id of the client from which the token must be issued"`
+	TenantClaim     string   `mapstructure:"tenant_claim" description:"claim of the tokens of the token issuer which holds the tenant of the user"`
 	TokenBasicAuth  bool     `mapstructure:"token_basic_auth" description:"specifies if client credentials to the authorization server should be sent in the header as basic auth (true) or in the body (false)"`
 	ProtectedLabels []string `mapstructure:"protected_labels" description:"defines labels which cannot be modified/added by REST API requests"`
 	OSBVersion      string   `mapstructure:"-"`
 	MaxPageSize     int      `mapstructure:"max_page_size" description:"maximum number of items that could be returned in a single page"`
 	DefaultPageSize int      `mapstructure:"default_page_size" description:"default number of items returned in a single page if not specified in request"`
+
+	ClientCertificateSubjects []string              `mapstructure:"client_certificate_subjects" description:"maps subjects of verified client certificates to platforms or users in the form <platform|user>:<platform id|user name>:<certificate subject>"`
+	TokenIssuers              []TokenIssuerSettings `mapstructure:"token_issuers" description:"additional token issuers whose tokens are trusted"`
+
+	PlatformCredentialsGracePeriod time.Duration `mapstructure:"platform_credentials_grace_period" description:"period in which the platform credentials replaced by a rotation remain valid"`
+	IdempotencyKeyTTL              time.Duration `mapstructure:"idempotency_key_ttl" description:"period in which the response of a request with an Idempotency-Key header is replayed for repeated requests"`
+
+	LabelDefinitionsRefreshInterval time.Duration `mapstructure:"label_definitions_refresh_interval" description:"how often the label definitions are reloaded from storage"`
+}
+
+// TokenIssuerSettings configures a trusted token issuer
+type TokenIssuerSettings struct {
+	URL           string   `mapstructure:"url" description:"url of the token issuer"`
+	Issuer        string   `mapstructure:"issuer" description:"value of the iss claim of the tokens if it differs from the url"`
+	Audience      string   `mapstructure:"audience" description:"id of the client from which the token must be issued"`
+	TenantClaim   string   `mapstructure:"tenant_claim" description:"claim of the tokens which holds the tenant of the user"`
+	ScopeMappings []string `mapstructure:"scope_mappings" description:"maps scopes of the tokens to service manager scopes in the form <token scope>=<service manager scope>"`
+}
+
+// TrustedIssuers returns the token issuer configured with token_issuer_url followed by the additional token issuers
+func (s *Settings) TrustedIssuers() []TokenIssuerSettings {
+	issuers := make([]TokenIssuerSettings, 0, len(s.TokenIssuers)+1)
+	if len(s.TokenIssuerURL) != 0 {
+		issuers = append(issuers, TokenIssuerSettings{
+			URL:         s.TokenIssuerURL,
+			Audience:    s.ClientID,
+			TenantClaim: s.TenantClaim,
+		})
+	}
+	return append(issuers, s.TokenIssuers...)
+}
+
+// OIDCOptions returns the options for the authenticators of the trusted token issuers
+func (s *Settings) OIDCOptions() ([]*authenticators.OIDCOptions, error) {
+	options := make([]*authenticators.OIDCOptions, 0)
+	for _, issuer := range s.TrustedIssuers() {
+		scopeMappings, err := authenticators.ParseScopeMappings(issuer.ScopeMappings)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, &authenticators.OIDCOptions{
+			IssuerURL:     issuer.URL,
+			ClientID:      issuer.Audience,
+			Issuer:        issuer.Issuer,
+			TenantClaim:   issuer.TenantClaim,
+			ScopeMappings: scopeMappings,
+		})
+	}
+	return options, nil
 }
 
 // DefaultSettings returns default values for API settings
@@ -68,43 +123,100 @@ func DefaultSettings() *Settings {
 		MaxPageSize:     200,
 		DefaultPageSize: 50,
 		ProtectedLabels: []string{},
+
+		ClientCertificateSubjects: []string{},
+		TokenIssuers:              []TokenIssuerSettings{},
+
+		PlatformCredentialsGracePeriod: 24 * time.Hour,
+		IdempotencyKeyTTL:              24 * time.Hour,
+
+		LabelDefinitionsRefreshInterval: 30 * time.Second,
 	}
 }
 
 // Validate validates the API settings
 func (s *Settings) Validate() error {
-	if (len(s.TokenIssuerURL)) == 0 {
+	if (len(s.TokenIssuerURL)) == 0 && len(s.TokenIssuers) == 0 {
 		return fmt.Errorf("validate Settings: APITokenIssuerURL missing")
 	}
+	for _, issuer := range s.TokenIssuers {
+		if len(issuer.URL) == 0 {
+			return fmt.Errorf("validate Settings: url of token issuer missing")
+		}
+	}
+	if _, err := s.OIDCOptions(); err != nil {
+		return fmt.Errorf("validate Settings: %s", err)
+	}
+	if _, err := authenticators.ParseCertificateSubjectMappings(s.ClientCertificateSubjects); err != nil {
+		return fmt.Errorf("validate Settings: %s", err)
+	}
+	if s.PlatformCredentialsGracePeriod < 0 {
+		return fmt.Errorf("validate Settings: PlatformCredentialsGracePeriod should be >= 0")
+	}
+	if s.IdempotencyKeyTTL <= 0 {
+		return fmt.Errorf("validate Settings: IdempotencyKeyTTL should be > 0")
+	}
+	if s.LabelDefinitionsRefreshInterval <= 0 {
+		return fmt.Errorf("validate Settings: LabelDefinitionsRefreshInterval should be > 0")
+	}
+	if s.DefaultPageSize <= 0 || s.MaxPageSize <= 0 {
+		return fmt.Errorf("validate Settings: DefaultPageSize and MaxPageSize should be > 0")
+	}
 	return nil
 }
 
+// pageSizesSetter is implemented by the controllers which return resources in pages
+type pageSizesSetter interface {
+	SetPageSizes(defaultPageSize, maxPageSize int)
+}
+
+// schedulerConfigurer is implemented by the controllers which schedule operations
+type schedulerConfigurer interface {
+	ConfigureScheduler(settings *operations.Settings)
+}
+
 type Options struct {
-	Repository        storage.TransactionalRepository
-	APISettings       *Settings
-	OperationSettings *operations.Settings
-	WSSettings        *ws.Settings
-	Notificator       storage.Notificator
-	WaitGroup         *sync.WaitGroup
+	Repository            storage.TransactionalRepository
+	APISettings           *Settings
+	OperationSettings     *operations.Settings
+	WSSettings            *ws.Settings
+	MaintenanceSettings   *maintenance.Settings
+	MaintenanceState      *maintenance.State
+	Configuration         *hotreload.Registry
+	IdempotencyStore      storage.IdempotencyStore
+	NotificationsLagStore storage.NotificationsLagStore
+	RequestTimeout        time.Duration
+	Notificator           storage.Notificator
+	WaitGroup             *sync.WaitGroup
 }
 
 // New returns the minimum set of REST APIs needed for the Service Manager
 func New(ctx context.Context, e env.Environment, options *Options) (*web.API, error) {
-	return &web.API{
+	notificationsController := apiNotifications.NewController(ctx, options.Repository, options.WSSettings, options.Notificator)
+	var api *web.API
+	// the related resources are restricted by the filters of the list endpoints of their types in the API
+	relatedScopeCriteria := func(r *web.Request, objectType types.ObjectType) ([]query.Criterion, bool, error) {
+		return scopeCriteria(api, r, objectType)
+	}
+	api = &web.API{
 		// Default controllers - more filters can be registered using the relevant API methods
 		Controllers: []web.Controller{
 			NewAsyncController(ctx, options, web.ServiceBrokersURL, types.ServiceBrokerType, false, func() types.Object {
 				return &types.ServiceBroker{}
 			}),
-			NewController(ctx, options, web.PlatformsURL, types.PlatformType, func() types.Object {
-				return &types.Platform{}
-			}),
+			NewPlatformController(ctx, options),
 			NewController(ctx, options, web.VisibilitiesURL, types.VisibilityType, func() types.Object {
 				return &types.Visibility{}
 			}),
+			NewController(ctx, options, web.RolesURL, types.RoleType, func() types.Object {
+				return &types.Role{}
+			}),
+			NewController(ctx, options, web.LabelDefinitionsURL, types.LabelDefinitionType, func() types.Object {
+				return &types.LabelDefinition{}
+			}),
 			NewServiceInstanceController(ctx, options),
 			NewServiceBindingController(ctx, options),
-			apiNotifications.NewController(ctx, options.Repository, options.WSSettings, options.Notificator),
+			notificationsController,
 
 			NewServiceOfferingController(ctx, options),
 			NewServicePlanController(ctx, options),
@@ -115,8 +227,9 @@ func New(ctx context.Context, e env.Environment, options *Options) (*web.API, er
 			},
 
 			&info.Controller{
-				TokenIssuer:    options.APISettings.TokenIssuerURL,
-				TokenBasicAuth: options.APISettings.TokenBasicAuth,
+				TokenIssuer:      options.APISettings.TokenIssuerURL,
+				TokenBasicAuth:   options.APISettings.TokenBasicAuth,
+				MaintenanceState: options.MaintenanceState,
 			},
 			&osb.Controller{
 				BrokerFetcher: func(ctx context.Context, brokerID string) (*types.ServiceBroker, error) {
@@ -129,18 +242,25 @@ func New(ctx context.Context, e env.Environment, options *Options) (*web.API, er
 				},
 			},
 			&configuration.Controller{
-				Environment: e,
+				Environment:      e,
+				Configuration:    options.Configuration,
+				MaintenanceState: options.MaintenanceState,
 			},
 			&profile.Controller{},
 		},
 		// Default filters - more filters can be registered using the relevant API methods
 		Filters: []web.Filter{
 			&filters.Logging{},
+			filters.NewMaintenanceModeFilter(options.MaintenanceState, options.MaintenanceSettings.RetryAfter),
 			&filters.SupportedEncodingsFilter{},
+			filters.NewIdempotencyFilter(options.IdempotencyStore, options.APISettings.IdempotencyKeyTTL, options.RequestTimeout),
 			&filters.SelectionCriteria{},
+			&filters.FieldsFilter{},
+			filters.NewExpandFilter(options.Repository, relatedScopeCriteria),
 			&filters.ServiceInstanceStripFilter{},
 			&filters.ServiceBindingStripFilter{},
 			filters.NewProtectedLabelsFilter(options.APISettings.ProtectedLabels),
+			filters.NewLabelDefinitionsFilter(options.Repository, options.APISettings.LabelDefinitionsRefreshInterval),
 			&filters.ProtectedSMPlatformFilter{},
 			&filters.PlatformIDInstanceValidationFilter{},
 			&filters.PlatformAwareVisibilityFilter{},
@@ -149,7 +269,35 @@ func New(ctx context.Context, e env.Environment, options *Options) (*web.API, er
 			filters.NewServicesFilterByVisibility(options.Repository),
 			&filters.CheckBrokerCredentialsFilter{},
 			filters.NewServiceInstanceTransferFilter(options.Repository),
+			&filters.RoleLabelSelectorFilter{},
 		},
 		Registry: health.NewDefaultRegistry(),
-	}, nil
+	}
+	api.RegisterControllers(NewSearchController(options, api), openapi.NewController(api))
+
+	// the settings changed at runtime are applied to all controllers and filters of the API
+	options.Configuration.Subscribe("api", func(settings hotreload.Settings) {
+		apiSettings := settings.(*Settings)
+		for _, controller := range api.Controllers {
+			if c, ok := controller.(pageSizesSetter); ok {
+				c.SetPageSizes(apiSettings.DefaultPageSize, apiSettings.MaxPageSize)
+			}
+		}
+		for _, filter := range api.Filters {
+			if f, ok := filter.(*filters.ProtectedLabelsFilter); ok {
+				f.SetProtectedLabels(apiSettings.ProtectedLabels)
+			}
+		}
+	})
+	options.Configuration.Subscribe("operations", func(settings hotreload.Settings) {
+		for _, controller := range api.Controllers {
+			if c, ok := controller.(schedulerConfigurer); ok {
+				c.ConfigureScheduler(settings.(*operations.Settings))
+			}
+		}
+	})
+	options.Configuration.Subscribe("websocket", func(settings hotreload.Settings) {
+		notificationsController.SetWSSettings(settings.(*ws.Settings))
+	})
+	return api, nil
 }
@@ -19,6 +19,8 @@ package api_test
 import (
 	"context"
 	"github.com/Peripli/service-manager/operations"
+	"github.com/Peripli/service-manager/pkg/hotreload"
+	"github.com/Peripli/service-manager/pkg/maintenance"
 	"testing"
 
 	"github.com/Peripli/service-manager/pkg/env/envfakes"
@@ -60,8 +62,10 @@ var _ = Describe("API", func() {
 	Describe("New", func() {
 		It("returns no error if creation is successful", func() {
 			_, err := api.New(context.TODO(), fakeEnvironment, &api.Options{
-				Repository:        mockedStorage,
-				OperationSettings: &operations.Settings{},
+				Repository:          mockedStorage,
+				OperationSettings:   &operations.Settings{},
+				MaintenanceSettings: maintenance.DefaultSettings(),
+				Configuration:       hotreload.NewRegistry(fakeEnvironment, nil),
 				APISettings: &api.Settings{
 					TokenIssuerURL: server.BaseURL,
 					ClientID:       "sm",
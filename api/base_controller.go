@@ -22,8 +22,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/Peripli/service-manager/api/openapi"
 	"github.com/Peripli/service-manager/operations"
 
 	"github.com/tidwall/sjson"
@@ -34,6 +36,7 @@ import (
 
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/security/http/authz"
 	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/pkg/web"
@@ -53,6 +56,7 @@ type BaseController struct {
 	repository      storage.Repository
 	objectBlueprint func() types.Object
 
+	pageSizesMutex  sync.RWMutex
 	DefaultPageSize int
 	MaxPageSize     int
 
@@ -62,13 +66,6 @@ type BaseController struct {
 
 // NewController returns a new base controller
 func NewController(ctx context.Context, options *Options, resourceBaseURL string, objectType types.ObjectType, objectBlueprint func() types.Object) *BaseController {
-	poolSize := options.OperationSettings.DefaultPoolSize
-	for _, pool := range options.OperationSettings.Pools {
-		if pool.Resource == objectType.String() {
-			poolSize = pool.Size
-			break
-		}
-	}
 	controller := &BaseController{
 		repository:      options.Repository,
 		resourceBaseURL: resourceBaseURL,
@@ -76,7 +73,7 @@ func NewController(ctx context.Context, options *Options, resourceBaseURL string
 		objectType:      objectType,
 		DefaultPageSize: options.APISettings.DefaultPageSize,
 		MaxPageSize:     options.APISettings.MaxPageSize,
-		scheduler:       operations.NewScheduler(ctx, options.Repository, options.OperationSettings, poolSize, options.WaitGroup),
+		scheduler:       operations.NewScheduler(ctx, options.Repository, options.OperationSettings, poolSize(options.OperationSettings, objectType), options.WaitGroup),
 	}
 
 	return controller
@@ -91,6 +88,38 @@ func NewAsyncController(ctx context.Context, options *Options, resourceBaseURL s
 	return controller
 }
 
+// SetPageSizes changes the default and the maximum number of items returned in a single page
+func (c *BaseController) SetPageSizes(defaultPageSize, maxPageSize int) {
+	c.pageSizesMutex.Lock()
+	defer c.pageSizesMutex.Unlock()
+	c.DefaultPageSize = defaultPageSize
+	c.MaxPageSize = maxPageSize
+}
+
+// ConfigureScheduler changes the timeouts and the worker pool size of the operations scheduled by the controller
+func (c *BaseController) ConfigureScheduler(settings *operations.Settings) {
+	c.scheduler.Configure(settings, poolSize(settings, c.objectType))
+}
+
+// poolSize returns the size of the worker pool for the operations of the resource
+func poolSize(settings *operations.Settings, objectType types.ObjectType) int {
+	for _, pool := range settings.Pools {
+		if pool.Resource == objectType.String() {
+			return pool.Size
+		}
+	}
+	return settings.DefaultPoolSize
+}
+
+// Resource describes the resources managed by the controller
+func (c *BaseController) Resource() openapi.Resource {
+	return openapi.Resource{
+		BaseURL:       c.resourceBaseURL,
+		Blueprint:     c.objectBlueprint,
+		SupportsAsync: c.supportsAsync,
+	}
+}
+
 // Routes returns the common set of routes for all objects
 func (c *BaseController) Routes() []web.Route {
 	return []web.Route{
@@ -101,6 +130,13 @@ func (c *BaseController) Routes() []web.Route {
 			},
 			Handler: c.CreateObject,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   c.resourceBaseURL + web.ResourceLabelsURL,
+			},
+			Handler: c.ListLabels,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
@@ -156,6 +192,15 @@ func (c *BaseController) CreateObject(r *web.Request) (*web.Response, error) {
 		return nil, err
 	}
 
+	// the labels of the resource type are listed under the path which a resource with such id would have
+	if "/"+result.GetID() == web.ResourceLabelsURL {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("%s is reserved and cannot be used as id", result.GetID()),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
 	if result.GetID() == "" {
 		UUID, err := uuid.NewV4()
 		if err != nil {
@@ -373,14 +418,32 @@ func (c *BaseController) ListObjects(r *web.Request) (*web.Response, error) {
 	}
 
 	rawToken := r.URL.Query().Get("token")
-	pagingSequence, err := c.parsePageToken(ctx, rawToken)
-	if err != nil {
-		return nil, err
-	}
+	orderBy := orderCriteria(criteria)
+	if len(orderBy) == 0 {
+		pagingSequence, err := c.parsePageToken(ctx, rawToken)
+		if err != nil {
+			return nil, err
+		}
 
-	criteria = append(criteria, query.LimitResultBy(limit+pagingLimitOffset),
-		query.OrderResultBy("paging_sequence", query.AscOrder),
-		query.ByField(query.GreaterThanOperator, "paging_sequence", pagingSequence))
+		criteria = append(criteria, query.LimitResultBy(limit+pagingLimitOffset),
+			query.OrderResultBy(pagingSequenceField, query.AscOrder),
+			query.ByField(query.GreaterThanOperator, pagingSequenceField, pagingSequence))
+	} else {
+		if err := validateOrderFields(c.objectBlueprint(), orderBy); err != nil {
+			return nil, err
+		}
+
+		// the paging sequence makes the order unique, so that the token identifies the position in the result
+		criteria = append(criteria, query.LimitResultBy(limit+pagingLimitOffset),
+			query.OrderResultBy(pagingSequenceField, query.AscOrder))
+		if rawToken != "" {
+			values, err := parseKeysetPageToken(ctx, rawToken, orderBy)
+			if err != nil {
+				return nil, err
+			}
+			criteria = append(criteria, query.ResultAfter(values...))
+		}
+	}
 
 	log.C(ctx).Debugf("Getting a page of %ss", c.objectType)
 	objectList, err := c.repository.List(ctx, c.objectType, criteria...)
@@ -388,7 +451,7 @@ func (c *BaseController) ListObjects(r *web.Request) (*web.Response, error) {
 		return nil, util.HandleStorageError(err, c.objectType.String())
 	}
 
-	page := pageFromObjectList(ctx, objectList, count, limit)
+	page := pageFromObjectList(ctx, objectList, count, limit, orderBy)
 	resp, err := util.NewJSONResponse(http.StatusOK, page)
 	if err != nil {
 		return nil, err
@@ -405,6 +468,54 @@ func (c *BaseController) ListObjects(r *web.Request) (*web.Response, error) {
 	return resp, nil
 }
 
+// ListLabels handles the listing of the label keys of the objects together with the number of objects having each of the label values
+func (c *BaseController) ListLabels(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+
+	// the label values are aggregated over all objects matching the criteria, so paging and ordering do not apply
+	criteria := make([]query.Criterion, 0)
+	for _, criterion := range query.CriteriaForContext(ctx) {
+		if criterion.Type != query.ResultQuery {
+			criteria = append(criteria, criterion)
+		}
+	}
+
+	log.C(ctx).Debugf("Counting the label values of %ss", c.objectType)
+	labelValueCounts, err := c.repository.CountLabelValues(ctx, c.objectType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, c.objectType.String())
+	}
+
+	page := labelsPage{Items: make([]*labelKey, 0)}
+	for _, labelValueCount := range labelValueCounts {
+		if len(page.Items) == 0 || page.Items[len(page.Items)-1].Key != labelValueCount.Key {
+			page.Items = append(page.Items, &labelKey{Key: labelValueCount.Key})
+		}
+		key := page.Items[len(page.Items)-1]
+		key.Values = append(key.Values, &labelValue{Value: labelValueCount.Value, Count: labelValueCount.Count})
+	}
+	page.ItemsCount = len(page.Items)
+
+	return util.NewJSONResponse(http.StatusOK, page)
+}
+
+// labelsPage lists the label keys of a resource type
+type labelsPage struct {
+	ItemsCount int         `json:"num_items"`
+	Items      []*labelKey `json:"items"`
+}
+
+// labelKey is a label key with the number of objects having each of its values
+type labelKey struct {
+	Key    string        `json:"key"`
+	Values []*labelValue `json:"values"`
+}
+
+type labelValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
 // PatchObject handles the update of the object with the id specified in the request
 func (c *BaseController) PatchObject(r *web.Request) (*web.Response, error) {
 	objectID := r.PathParams[web.PathParamResourceID]
@@ -443,6 +554,13 @@ func (c *BaseController) PatchObject(r *web.Request) (*web.Response, error) {
 	objFromDB.SetReady(true)
 
 	labels, _, _ := query.ApplyLabelChangesToLabels(labelChanges, objFromDB.GetLabels())
+	if selector, restricted := authz.LabelSelectorFromContext(ctx); restricted && !authz.LabelsSatisfy(labels, selector) {
+		return nil, &util.HTTPError{
+			ErrorType:   "Forbidden",
+			Description: fmt.Sprintf("the label changes move the %s out of the resources which can be updated by the user", c.objectType),
+			StatusCode:  http.StatusForbidden,
+		}
+	}
 	objFromDB.SetLabels(labels)
 
 	action := func(ctx context.Context, repository storage.Repository) (types.Object, error) {
@@ -517,7 +635,14 @@ func attachLastOperation(ctx context.Context, objectID string, object types.Obje
 }
 
 func (c *BaseController) parseMaxItemsQuery(maxItems string) (int, error) {
-	limit := c.DefaultPageSize
+	c.pageSizesMutex.RLock()
+	defer c.pageSizesMutex.RUnlock()
+	return parseMaxItems(maxItems, c.DefaultPageSize, c.MaxPageSize)
+}
+
+// parseMaxItems returns the requested page size limited to the maximum page size or the default page size if none is requested
+func parseMaxItems(maxItems string, defaultPageSize, maxPageSize int) (int, error) {
+	limit := defaultPageSize
 	var err error
 	if maxItems != "" {
 		limit, err = strconv.Atoi(maxItems)
@@ -535,8 +660,8 @@ func (c *BaseController) parseMaxItemsQuery(maxItems string) (int, error) {
 				StatusCode:  http.StatusBadRequest,
 			}
 		}
-		if limit > c.MaxPageSize {
-			limit = c.MaxPageSize
+		if limit > maxPageSize {
+			limit = maxPageSize
 		}
 	}
 	return limit, nil
@@ -596,12 +721,15 @@ func (c *BaseController) checkAsyncSupport() error {
 	return nil
 }
 
-func generateTokenForItem(obj types.Object) string {
+func generateTokenForItem(obj types.Object, orderBy []query.Criterion) string {
+	if len(orderBy) != 0 {
+		return generateKeysetTokenForItem(obj, orderBy)
+	}
 	nextPageToken := obj.GetPagingSequence()
 	return base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(nextPageToken, 10)))
 }
 
-func pageFromObjectList(ctx context.Context, objectList types.ObjectList, count, limit int) *types.ObjectPage {
+func pageFromObjectList(ctx context.Context, objectList types.ObjectList, count, limit int, orderBy []query.Criterion) *types.ObjectPage {
 	page := &types.ObjectPage{
 		ItemsCount: count,
 		Items:      make([]types.Object, 0, objectList.Len()),
@@ -615,7 +743,7 @@ func pageFromObjectList(ctx context.Context, objectList types.ObjectList, count,
 
 	if len(page.Items) > limit {
 		page.Items = page.Items[:len(page.Items)-1]
-		page.Token = generateTokenForItem(page.Items[len(page.Items)-1])
+		page.Token = generateTokenForItem(page.Items[len(page.Items)-1], orderBy)
 	}
 	return page
 }
@@ -4,6 +4,9 @@ import (
 	"net/http"
 
 	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/hotreload"
+	"github.com/Peripli/service-manager/pkg/maintenance"
+	"github.com/Peripli/service-manager/pkg/types"
 
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/pkg/util"
@@ -12,13 +15,30 @@ import (
 
 // Controller configuration controller
 type Controller struct {
-	Environment env.Environment
+	Environment      env.Environment
+	Configuration    *hotreload.Registry
+	MaintenanceState *maintenance.State
 }
 
 func (c *Controller) getConfiguration(r *web.Request) (*web.Response, error) {
 	log.C(r.Context()).Debug("Obtaining application configuration...")
 
-	return util.NewJSONResponse(http.StatusOK, c.Environment.AllSettings())
+	return util.NewJSONResponse(http.StatusOK, c.Configuration.AllSettings())
+}
+
+func (c *Controller) changeConfiguration(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	var changes map[string]map[string]interface{}
+	if err := util.BytesToObject(r.Body, &changes); err != nil {
+		return nil, err
+	}
+
+	log.C(ctx).Infof("Attempting to change configuration at runtime: %s", r.Body)
+	if err := c.Configuration.Change(ctx, changes); err != nil {
+		return nil, err
+	}
+
+	return util.NewJSONResponse(http.StatusOK, c.Configuration.AllSettings())
 }
 
 func (c *Controller) getLoggingConfiguration(r *web.Request) (*web.Response, error) {
@@ -59,7 +79,41 @@ func (c *Controller) setLoggingConfiguration(r *web.Request) (*web.Response, err
 	return util.NewJSONResponse(http.StatusOK, map[string]string{})
 }
 
-// Routes provides endpoints for modifying and obtaining the logging configuration
+func (c *Controller) getMaintenanceMode(r *web.Request) (*web.Response, error) {
+	return util.NewJSONResponse(http.StatusOK, c.MaintenanceState.Mode(r.Context()))
+}
+
+func (c *Controller) setMaintenanceMode(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	var modeChange struct {
+		ReadOnly *bool  `json:"read_only"`
+		Reason   string `json:"reason"`
+	}
+	if err := util.BytesToObject(r.Body, &modeChange); err != nil {
+		return nil, err
+	}
+	if modeChange.ReadOnly == nil {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: "read_only must be specified",
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	log.C(ctx).Infof("Attempting to set maintenance mode to read only: %t", *modeChange.ReadOnly)
+	mode, err := c.MaintenanceState.SetMode(ctx, types.MaintenanceMode{
+		ReadOnly: *modeChange.ReadOnly,
+		Reason:   modeChange.Reason,
+	})
+	if err != nil {
+		return nil, util.HandleStorageError(err, "maintenance mode")
+	}
+
+	return util.NewJSONResponse(http.StatusOK, mode)
+}
+
+// Routes provides endpoints for modifying and obtaining the configuration, the logging configuration and the
+// maintenance mode
 func (c *Controller) Routes() []web.Route {
 	return []web.Route{
 		{
@@ -69,6 +123,13 @@ func (c *Controller) Routes() []web.Route {
 			},
 			Handler: c.getConfiguration,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPatch,
+				Path:   web.ConfigURL,
+			},
+			Handler: c.changeConfiguration,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
@@ -83,5 +144,19 @@ func (c *Controller) Routes() []web.Route {
 			},
 			Handler: c.setLoggingConfiguration,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.MaintenanceConfigURL,
+			},
+			Handler: c.getMaintenanceMode,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPut,
+				Path:   web.MaintenanceConfigURL,
+			},
+			Handler: c.setMaintenanceMode,
+		},
 	}
 }
@@ -4,7 +4,11 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/Peripli/service-manager/pkg/maintenance"
 	"github.com/Peripli/service-manager/pkg/security/authenticators"
+	"github.com/Peripli/service-manager/pkg/security/http/authz"
+	"github.com/Peripli/service-manager/pkg/security/rbac"
+	"github.com/Peripli/service-manager/pkg/types"
 
 	"github.com/Peripli/service-manager/config"
 
@@ -15,9 +19,41 @@ import (
 
 // Register adds security configuration to the service manager builder
 func Register(ctx context.Context, cfg *config.Settings, smb *sm.ServiceManagerBuilder) error {
+	subjectMappings, err := authenticators.ParseCertificateSubjectMappings(cfg.API.ClientCertificateSubjects)
+	if err != nil {
+		return err
+	}
+
+	if len(subjectMappings) > 0 {
+		clientCertificateAuthenticator := &authenticators.ClientCertificate{
+			Repository: smb.Storage,
+			Mappings:   subjectMappings,
+		}
+
+		// the platforms authenticated with client certificates have the same access as the ones using basic credentials
+		smb.Security().Path(
+			web.ServiceBrokersURL+"/*",
+			web.PlatformsURL+"/*",
+			web.ServiceOfferingsURL+"/*",
+			web.ServicePlansURL+"/*",
+			web.VisibilitiesURL+"/*",
+			web.ServiceInstancesURL+"/*",
+			web.ServiceBindingsURL+"/*",
+			web.NotificationsURL+"/*",
+			web.SearchURL).
+			Method(http.MethodGet).
+			WithAuthentication(clientCertificateAuthenticator).Required()
+
+		smb.Security().
+			Path(web.BrokerPlatformCredentialsURL + "/**").
+			Method(http.MethodPut).
+			WithAuthentication(clientCertificateAuthenticator).Required()
+	}
+
 	basicPlatformAuthenticator := &authenticators.Basic{
 		Repository:             smb.Storage,
 		BasicAuthenticatorFunc: authenticators.BasicPlatformAuthenticator,
+		CredentialsUsageStore:  smb.CredentialsUsageStore,
 	}
 
 	smb.Security().Path(
@@ -28,7 +64,8 @@ func Register(ctx context.Context, cfg *config.Settings, smb *sm.ServiceManagerB
 		web.VisibilitiesURL+"/*",
 		web.ServiceInstancesURL+"/*",
 		web.ServiceBindingsURL+"/*",
-		web.NotificationsURL+"/*").
+		web.NotificationsURL+"/*",
+		web.SearchURL).
 		Method(http.MethodGet).
 		WithAuthentication(basicPlatformAuthenticator).Required()
 
@@ -40,6 +77,7 @@ func Register(ctx context.Context, cfg *config.Settings, smb *sm.ServiceManagerB
 	basicOSBAuthenticator := &authenticators.Basic{
 		Repository:             smb.Storage,
 		BasicAuthenticatorFunc: authenticators.BasicOSBAuthenticator,
+		CredentialsUsageStore:  smb.CredentialsUsageStore,
 	}
 
 	smb.Security().
@@ -47,13 +85,15 @@ func Register(ctx context.Context, cfg *config.Settings, smb *sm.ServiceManagerB
 		Method(http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete).
 		WithAuthentication(basicOSBAuthenticator).Required()
 
-	bearerAuthenticator, _, err := authenticators.NewOIDCAuthenticator(ctx, &authenticators.OIDCOptions{
-		IssuerURL: cfg.API.TokenIssuerURL,
-		ClientID:  cfg.API.ClientID,
-	})
+	oidcOptions, err := cfg.API.OIDCOptions()
 	if err != nil {
 		return err
 	}
+	bearerAuthenticator, err := authenticators.NewMultiIssuerOIDCAuthenticator(ctx, oidcOptions)
+	if err != nil {
+		return err
+	}
+	smb.WithTenantClaims(bearerAuthenticator.TenantClaims)
 
 	smb.Security().Path(
 		web.ServiceBrokersURL+"/**",
@@ -67,9 +107,46 @@ func Register(ctx context.Context, cfg *config.Settings, smb *sm.ServiceManagerB
 		web.ConfigURL+"/**",
 		web.ProfileURL+"/**",
 		web.OperationsURL+"/**",
+		web.RolesURL+"/**",
+		web.LabelDefinitionsURL+"/**",
+		web.SearchURL,
 	).
 		Method(http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete).
 		WithAuthentication(bearerAuthenticator).Required()
 
+	// the maintenance mode affects all tenants, so it can be changed only by administrators
+	smb.Security().
+		Path(web.MaintenanceConfigURL).
+		Method(http.MethodPut).
+		WithScopes(maintenance.AdminScope).Required()
+
+	if cfg.RBAC.Enabled {
+		staticRoles := make([]*types.Role, 0)
+		if len(cfg.RBAC.RolesFile) != 0 {
+			if staticRoles, err = rbac.LoadRoles(cfg.RBAC.RolesFile); err != nil {
+				return err
+			}
+		}
+		roleProvider := rbac.NewRoleProvider(smb.Storage, staticRoles, cfg.RBAC.RefreshInterval)
+
+		smb.Security().Path(
+			web.ServiceBrokersURL+"/**",
+			web.PlatformsURL+"/**",
+			web.ServiceOfferingsURL+"/**",
+			web.ServicePlansURL+"/**",
+			web.VisibilitiesURL+"/**",
+			web.ServiceInstancesURL+"/**",
+			web.ServiceBindingsURL+"/**",
+			web.NotificationsURL+"/**",
+			web.ConfigURL+"/**",
+			web.ProfileURL+"/**",
+			web.OperationsURL+"/**",
+			web.RolesURL+"/**",
+			web.LabelDefinitionsURL+"/**",
+		).
+			Method(http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete).
+			WithAuthorization(authz.NewRBACAuthorizer(roleProvider)).Required()
+	}
+
 	return nil
 }
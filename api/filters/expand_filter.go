@@ -0,0 +1,284 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	// ExpandFilterName is the name of the related resources expansion filter
+	ExpandFilterName = "ExpandFilter"
+
+	// ExpandQueryKey is the query parameter which lists the related resources to be embedded in the response
+	ExpandQueryKey = "expand"
+)
+
+// expandableRelation describes a resource referenced by the value of a field
+type expandableRelation struct {
+	field      string
+	objectType types.ObjectType
+}
+
+// expandableRelations lists the relations which can be expanded per resource type
+var expandableRelations = map[types.ObjectType]map[string]expandableRelation{
+	types.ServiceInstanceType: {
+		"service_plan": {field: "service_plan_id", objectType: types.ServicePlanType},
+		"platform":     {field: "platform_id", objectType: types.PlatformType},
+	},
+	types.ServiceBindingType: {
+		"service_instance": {field: "service_instance_id", objectType: types.ServiceInstanceType},
+	},
+	types.ServicePlanType: {
+		"service_offering": {field: "service_offering_id", objectType: types.ServiceOfferingType},
+	},
+	types.ServiceOfferingType: {
+		"service_broker": {field: "broker_id", objectType: types.ServiceBrokerType},
+	},
+}
+
+// expandTree is a parsed expand query - each key is a relation and its value contains the nested relations to expand
+type expandTree map[string]expandTree
+
+// ScopeCriteriaFunc returns the criteria which restrict the resources of the type to the ones that the user of the
+// request can list and whether any of them is visible
+type ScopeCriteriaFunc func(req *web.Request, objectType types.ObjectType) ([]query.Criterion, bool, error)
+
+// NewExpandFilter creates a new ExpandFilter
+func NewExpandFilter(repository storage.Repository, scopeCriteria ScopeCriteriaFunc) *ExpandFilter {
+	return &ExpandFilter{
+		repository:    repository,
+		scopeCriteria: scopeCriteria,
+	}
+}
+
+// ExpandFilter embeds the related resources requested with the expand query parameter in the returned resources.
+// Each relation is resolved with a single query for all returned resources, so the number of queries
+// depends only on the requested relations and not on the size of the page. Only the related resources which the
+// user can list are embedded.
+type ExpandFilter struct {
+	repository    storage.Repository
+	scopeCriteria ScopeCriteriaFunc
+}
+
+// Name implements the web.Filter interface and returns the identifier of the filter.
+func (*ExpandFilter) Name() string {
+	return ExpandFilterName
+}
+
+// Run implements the web.Filter interface and expands the requested relations of the returned resources.
+func (ef *ExpandFilter) Run(req *web.Request, next web.Handler) (*web.Response, error) {
+	expandQuery := req.URL.Query().Get(ExpandQueryKey)
+	if len(expandQuery) == 0 {
+		return next.Handle(req)
+	}
+
+	objectType, found := resourceTypeForPath(req.URL.Path)
+	if !found {
+		return next.Handle(req)
+	}
+	tree, err := parseExpand(objectType, expandQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next.Handle(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	items := gjson.GetBytes(resp.Body, "items")
+	if !items.IsArray() {
+		expanded, err := ef.expand(req, objectType, tree, []string{string(resp.Body)})
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = []byte(expanded[0])
+		return resp, nil
+	}
+
+	objects := make([]string, 0, len(items.Array()))
+	for _, item := range items.Array() {
+		objects = append(objects, item.Raw)
+	}
+	expanded, err := ef.expand(req, objectType, tree, objects)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Body, err = sjson.SetRawBytes(resp.Body, "items", []byte("["+strings.Join(expanded, ",")+"]")); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FilterMatchers implements the web.Filter interface and returns the conditions on which the filter should be executed.
+func (*ExpandFilter) FilterMatchers() []web.FilterMatcher {
+	return []web.FilterMatcher{
+		{
+			Matchers: []web.Matcher{
+				web.Path(
+					web.ServiceInstancesURL+"/*",
+					web.ServiceBindingsURL+"/*",
+					web.ServicePlansURL+"/*",
+					web.ServiceOfferingsURL+"/*",
+				),
+				web.Methods(http.MethodGet),
+			},
+		},
+	}
+}
+
+// expand embeds the relations from the tree in each of the provided JSON objects of the given type
+func (ef *ExpandFilter) expand(req *web.Request, objectType types.ObjectType, tree expandTree, objects []string) ([]string, error) {
+	relationNames := make([]string, 0, len(tree))
+	for name := range tree {
+		relationNames = append(relationNames, name)
+	}
+	sort.Strings(relationNames)
+
+	for _, name := range relationNames {
+		relation := expandableRelations[objectType][name]
+		related, err := ef.fetchRelated(req, relation, tree[name], objects)
+		if err != nil {
+			return nil, err
+		}
+		for i, object := range objects {
+			relatedObject, found := related[gjson.Get(object, relation.field).String()]
+			if !found {
+				continue
+			}
+			if objects[i], err = sjson.SetRaw(object, name, relatedObject); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return objects, nil
+}
+
+// fetchRelated returns the JSON of the resources referenced by the provided objects mapped by their ids. The resources
+// which the user cannot list are left out.
+func (ef *ExpandFilter) fetchRelated(req *web.Request, relation expandableRelation, tree expandTree, objects []string) (map[string]string, error) {
+	ids := make([]string, 0, len(objects))
+	seen := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		id := gjson.Get(object, relation.field).String()
+		if len(id) == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return map[string]string{}, nil
+	}
+
+	criteria, listed, err := ef.scopeCriteria(req, relation.objectType)
+	if err != nil {
+		return nil, err
+	}
+	if !listed {
+		return map[string]string{}, nil
+	}
+	criteria = append(criteria, query.ByField(query.InOperator, "id", ids...))
+	list, err := ef.repository.List(req.Context(), relation.objectType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, relation.objectType.String())
+	}
+	relatedObjects := make([]string, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		object := list.ItemAt(i)
+		if secured, ok := object.(types.Strip); ok {
+			secured.Sanitize()
+		}
+		bytes, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		relatedObjects = append(relatedObjects, string(bytes))
+	}
+	if len(tree) > 0 {
+		if relatedObjects, err = ef.expand(req, relation.objectType, tree, relatedObjects); err != nil {
+			return nil, err
+		}
+	}
+
+	related := make(map[string]string, len(relatedObjects))
+	for _, object := range relatedObjects {
+		related[gjson.Get(object, "id").String()] = object
+	}
+	return related, nil
+}
+
+func resourceTypeForPath(path string) (types.ObjectType, bool) {
+	for objectType := range expandableRelations {
+		if url := string(objectType); path == url || strings.HasPrefix(path, url+"/") {
+			return objectType, true
+		}
+	}
+	return "", false
+}
+
+// expandedRelations returns the names of the relations of the returned resources which are requested in the expand query
+func expandedRelations(value string) []string {
+	relations := make([]string, 0)
+	for _, path := range strings.Split(value, ",") {
+		if relation := strings.TrimSpace(strings.SplitN(path, ".", 2)[0]); len(relation) > 0 {
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// parseExpand parses a comma separated list of dot separated relation paths such as service_plan.service_offering
+func parseExpand(objectType types.ObjectType, value string) (expandTree, error) {
+	tree := expandTree{}
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if len(path) == 0 {
+			continue
+		}
+		node := tree
+		currentType := objectType
+		for _, name := range strings.Split(path, ".") {
+			relation, found := expandableRelations[currentType][name]
+			if !found {
+				return nil, &util.UnsupportedQueryError{
+					Message: fmt.Sprintf("unsupported expand %s for %s", path, objectType),
+				}
+			}
+			if _, found := node[name]; !found {
+				node[name] = expandTree{}
+			}
+			node = node[name]
+			currentType = relation.objectType
+		}
+	}
+	return tree, nil
+}
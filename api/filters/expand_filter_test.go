@@ -0,0 +1,202 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/pkg/web/webfakes"
+	"github.com/Peripli/service-manager/storage/storagefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Expand Filter", func() {
+	var (
+		filter     *ExpandFilter
+		handler    *webfakes.FakeHandler
+		repository *storagefakes.FakeStorage
+		scopes     map[types.ObjectType][]query.Criterion
+		hidden     map[types.ObjectType]bool
+		scopeErr   error
+	)
+
+	instancesPage := func(count int) string {
+		items := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			items = append(items, fmt.Sprintf(`{"id":"instance-%d","service_plan_id":"plan-%d","platform_id":"platform"}`, i, i%3))
+		}
+		return fmt.Sprintf(`{"num_items":%d,"items":[%s]}`, count, strings.Join(items, ","))
+	}
+
+	run := func(path string, body string) (*web.Response, error) {
+		handler.HandleReturns(&web.Response{StatusCode: http.StatusOK, Body: []byte(body)}, nil)
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		Expect(err).ToNot(HaveOccurred())
+		return filter.Run(&web.Request{Request: req}, handler)
+	}
+
+	BeforeEach(func() {
+		handler = &webfakes.FakeHandler{}
+		repository = &storagefakes.FakeStorage{}
+		repository.ListStub = func(_ context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+			ids := criteria[len(criteria)-1].RightOp
+			switch objectType {
+			case types.ServicePlanType:
+				plans := &types.ServicePlans{}
+				for _, id := range ids {
+					plans.ServicePlans = append(plans.ServicePlans, &types.ServicePlan{
+						Base:              types.Base{ID: id},
+						Name:              "name-" + id,
+						ServiceOfferingID: "offering",
+					})
+				}
+				return plans, nil
+			case types.ServiceOfferingType:
+				return &types.ServiceOfferings{ServiceOfferings: []*types.ServiceOffering{
+					{Base: types.Base{ID: "offering"}, Name: "offering-name"},
+				}}, nil
+			case types.ServiceBrokerType:
+				return &types.ServiceBrokers{ServiceBrokers: []*types.ServiceBroker{
+					{Base: types.Base{ID: "broker"}, Name: "broker-name", Credentials: &types.Credentials{
+						Basic: &types.Basic{Username: "admin", Password: "secret"},
+					}},
+				}}, nil
+			case types.PlatformType:
+				return &types.Platforms{Platforms: []*types.Platform{
+					{Base: types.Base{ID: "platform"}, Name: "platform-name", Credentials: &types.Credentials{
+						Basic: &types.Basic{Username: "admin", Password: "secret"},
+					}},
+				}}, nil
+			}
+			return nil, fmt.Errorf("unexpected object type %s", objectType)
+		}
+		scopes = map[types.ObjectType][]query.Criterion{}
+		hidden = map[types.ObjectType]bool{}
+		scopeErr = nil
+		scopeCriteria := func(_ *web.Request, objectType types.ObjectType) ([]query.Criterion, bool, error) {
+			return scopes[objectType], !hidden[objectType], scopeErr
+		}
+		filter = NewExpandFilter(repository, scopeCriteria)
+	})
+
+	When("expand is not requested", func() {
+		It("should not query the repository", func() {
+			resp, err := run(web.ServiceInstancesURL, instancesPage(2))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body).To(MatchJSON(instancesPage(2)))
+			Expect(repository.ListCallCount()).To(Equal(0))
+		})
+	})
+
+	When("an unsupported relation is requested", func() {
+		It("should return an unsupported query error", func() {
+			_, err := run(web.ServiceInstancesURL+"?expand=service_plan.service_broker", instancesPage(1))
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&util.UnsupportedQueryError{}))
+			Expect(handler.HandleCallCount()).To(Equal(0))
+		})
+	})
+
+	When("relations of a page of resources are requested", func() {
+		It("should embed them using a constant number of queries", func() {
+			resp, err := run(web.ServiceInstancesURL+"?expand=service_plan,service_plan.service_offering,platform", instancesPage(200))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.ListCallCount()).To(Equal(3))
+
+			_, _, criteria := repository.ListArgsForCall(1)
+			Expect(criteria[0].Operator).To(Equal(query.InOperator))
+			Expect(criteria[0].RightOp).To(ConsistOf("plan-0", "plan-1", "plan-2"))
+
+			items := gjson.GetBytes(resp.Body, "items").Array()
+			Expect(items).To(HaveLen(200))
+			Expect(items[4].Get("service_plan.id").String()).To(Equal("plan-1"))
+			Expect(items[4].Get("service_plan.name").String()).To(Equal("name-plan-1"))
+			Expect(items[4].Get("service_plan.service_offering.name").String()).To(Equal("offering-name"))
+			Expect(items[4].Get("platform.name").String()).To(Equal("platform-name"))
+			Expect(items[4].Get("platform.credentials").Exists()).To(BeFalse())
+		})
+	})
+
+	When("relations of a single resource are requested", func() {
+		It("should embed them in the resource", func() {
+			resp, err := run(web.ServicePlansURL+"/plan-0?expand=service_offering", `{"id":"plan-0","service_offering_id":"offering"}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.ListCallCount()).To(Equal(1))
+			Expect(gjson.GetBytes(resp.Body, "service_offering.name").String()).To(Equal("offering-name"))
+		})
+	})
+
+	When("the broker of a service offering is requested", func() {
+		It("should embed it without its credentials", func() {
+			resp, err := run(web.ServiceOfferingsURL+"/offering?expand=service_broker", `{"id":"offering","broker_id":"broker"}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gjson.GetBytes(resp.Body, "service_broker.name").String()).To(Equal("broker-name"))
+			Expect(gjson.GetBytes(resp.Body, "service_broker.credentials").Exists()).To(BeFalse())
+		})
+	})
+
+	When("the related resources are restricted for the user", func() {
+		It("should apply the criteria of the related type to the query", func() {
+			byTenant := query.ByLabel(query.EqualsOperator, "tenant", "tenant-id")
+			scopes[types.ServicePlanType] = []query.Criterion{byTenant}
+			_, err := run(web.ServiceInstancesURL+"?expand=service_plan", instancesPage(3))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.ListCallCount()).To(Equal(1))
+
+			_, objectType, criteria := repository.ListArgsForCall(0)
+			Expect(objectType).To(Equal(types.ServicePlanType))
+			Expect(criteria).To(HaveLen(2))
+			Expect(criteria[0]).To(Equal(byTenant))
+			Expect(criteria[1].RightOp).To(ConsistOf("plan-0", "plan-1", "plan-2"))
+		})
+
+		It("should not embed them if none of them is visible", func() {
+			hidden[types.PlatformType] = true
+			resp, err := run(web.ServiceInstancesURL+"?expand=platform", instancesPage(2))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.ListCallCount()).To(Equal(0))
+			Expect(resp.Body).To(MatchJSON(instancesPage(2)))
+		})
+
+		It("should fail if the related type cannot be listed", func() {
+			scopeErr = errors.New("forbidden")
+			_, err := run(web.ServiceInstancesURL+"?expand=platform", instancesPage(2))
+			Expect(err).To(MatchError(scopeErr))
+			Expect(repository.ListCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the referenced resource is not set", func() {
+		It("should not embed it", func() {
+			resp, err := run(web.ServiceInstancesURL+"/1?expand=platform", `{"id":"1","service_plan_id":"plan-0"}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.ListCallCount()).To(Equal(0))
+			Expect(resp.Body).To(MatchJSON(`{"id":"1","service_plan_id":"plan-0"}`))
+		})
+	})
+})
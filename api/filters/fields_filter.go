@@ -0,0 +1,138 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package filters
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const (
+	// FieldsFilterName is the name of the sparse fieldsets filter
+	FieldsFilterName = "FieldsFilter"
+
+	// FieldsQueryKey is the query parameter which restricts the attributes returned for a resource
+	FieldsQueryKey = "fields"
+)
+
+// FieldsFilter restricts the attributes of the returned resources to the ones requested with the fields query parameter.
+// The id of a resource and the relations requested with the expand query parameter are always returned. When a page of
+// resources is returned, the restriction applies to its items.
+type FieldsFilter struct {
+}
+
+// Name implements the web.Filter interface and returns the identifier of the filter.
+func (*FieldsFilter) Name() string {
+	return FieldsFilterName
+}
+
+// Run implements the web.Filter interface and strips the attributes which were not requested from the response.
+func (*FieldsFilter) Run(req *web.Request, next web.Handler) (*web.Response, error) {
+	fields := parseFields(req.URL.Query().Get(FieldsQueryKey))
+	if len(fields) != 0 {
+		for _, relation := range expandedRelations(req.URL.Query().Get(ExpandQueryKey)) {
+			fields[relation] = true
+		}
+	}
+	resp, err := next.Handle(req)
+	if err != nil || len(fields) == 0 || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body := gjson.ParseBytes(resp.Body)
+	if !body.IsObject() {
+		return resp, nil
+	}
+
+	items := body.Get("items")
+	if !items.IsArray() {
+		resp.Body, err = selectFields(resp.Body, fields)
+		return resp, err
+	}
+
+	selectedItems := make([]string, 0, len(items.Array()))
+	for _, item := range items.Array() {
+		selected, err := selectFields([]byte(item.Raw), fields)
+		if err != nil {
+			return nil, err
+		}
+		selectedItems = append(selectedItems, string(selected))
+	}
+	if resp.Body, err = sjson.SetRawBytes(resp.Body, "items", []byte("["+strings.Join(selectedItems, ",")+"]")); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FilterMatchers implements the web.Filter interface and returns the conditions on which the filter should be executed.
+func (*FieldsFilter) FilterMatchers() []web.FilterMatcher {
+	return []web.FilterMatcher{
+		{
+			Matchers: []web.Matcher{
+				web.Path(
+					web.ServiceBrokersURL+"/*",
+					web.ServiceOfferingsURL+"/*",
+					web.ServicePlansURL+"/*",
+					web.ServiceInstancesURL+"/*",
+					web.ServiceBindingsURL+"/*",
+					web.VisibilitiesURL+"/*",
+					web.PlatformsURL+"/*",
+					web.OperationsURL+"/*",
+					web.RolesURL+"/*",
+					web.LabelDefinitionsURL+"/*",
+				),
+				web.Methods(http.MethodGet),
+			},
+		},
+	}
+}
+
+func parseFields(value string) map[string]bool {
+	if len(value) == 0 {
+		return nil
+	}
+	fields := map[string]bool{"id": true}
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			fields[field] = true
+		}
+	}
+	return fields
+}
+
+func selectFields(object []byte, fields map[string]bool) ([]byte, error) {
+	result := []byte("{}")
+	var err error
+	gjson.ParseBytes(object).ForEach(func(key, value gjson.Result) bool {
+		if !fields[key.String()] {
+			return true
+		}
+		result, err = sjson.SetRawBytes(result, escapePath(key.String()), []byte(value.Raw))
+		return err == nil
+	})
+	return result, err
+}
+
+func escapePath(key string) string {
+	replacer := strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`)
+	return replacer.Replace(key)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package filters
+
+import (
+	"net/http"
+
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/pkg/web/webfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fields Filter", func() {
+	var (
+		filter  FieldsFilter
+		handler *webfakes.FakeHandler
+	)
+
+	runWithQuery := func(rawQuery string, body string) *web.Response {
+		handler.HandleReturns(&web.Response{StatusCode: http.StatusOK, Body: []byte(body)}, nil)
+		req, err := http.NewRequest(http.MethodGet, web.ServiceInstancesURL+"?"+rawQuery, nil)
+		Expect(err).ToNot(HaveOccurred())
+		resp, err := filter.Run(&web.Request{Request: req}, handler)
+		Expect(err).ToNot(HaveOccurred())
+		return resp
+	}
+
+	BeforeEach(func() {
+		filter = FieldsFilter{}
+		handler = &webfakes.FakeHandler{}
+	})
+
+	When("fields are not requested", func() {
+		It("should return the response unchanged", func() {
+			resp := runWithQuery("", `{"id":"1","name":"instance","ready":true}`)
+			Expect(string(resp.Body)).To(Equal(`{"id":"1","name":"instance","ready":true}`))
+		})
+	})
+
+	When("fields are requested for a single resource", func() {
+		It("should return only the requested fields and the id", func() {
+			resp := runWithQuery("fields=name,usable", `{"id":"1","name":"instance","ready":true,"usable":false}`)
+			Expect(resp.Body).To(MatchJSON(`{"id":"1","name":"instance","usable":false}`))
+		})
+	})
+
+	When("fields are requested for a page of resources", func() {
+		It("should restrict the fields of each item and keep the page attributes", func() {
+			resp := runWithQuery("fields=name",
+				`{"token":"abc","num_items":2,"items":[{"id":"1","name":"a","ready":true},{"id":"2","name":"b","ready":false}]}`)
+			Expect(resp.Body).To(MatchJSON(`{"token":"abc","num_items":2,"items":[{"id":"1","name":"a"},{"id":"2","name":"b"}]}`))
+		})
+	})
+
+	When("relations are expanded", func() {
+		It("should return the expanded relations together with the requested fields", func() {
+			resp := runWithQuery("fields=name&expand=service_plan.service_offering,platform",
+				`{"id":"1","name":"instance","service_plan_id":"plan","service_plan":{"id":"plan","name":"plan"},"platform":{"id":"platform"}}`)
+			Expect(resp.Body).To(MatchJSON(`{"id":"1","name":"instance","service_plan":{"id":"plan","name":"plan"},"platform":{"id":"platform"}}`))
+		})
+	})
+
+	When("the response is not successful", func() {
+		It("should return the response unchanged", func() {
+			handler.HandleReturns(&web.Response{StatusCode: http.StatusNotFound, Body: []byte(`{"error":"NotFound"}`)}, nil)
+			req, err := http.NewRequest(http.MethodGet, web.ServiceInstancesURL+"/1?fields=name", nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := filter.Run(&web.Request{Request: req}, handler)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(resp.Body)).To(Equal(`{"error":"NotFound"}`))
+		})
+	})
+})
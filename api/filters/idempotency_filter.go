@@ -0,0 +1,217 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	// IdempotencyFilterName is the name of the filter replaying the responses of the requests repeated with the same
+	// Idempotency-Key header
+	IdempotencyFilterName = "IdempotencyFilter"
+
+	// IdempotencyKeyHeader is the header by which the clients identify the requests which may be repeated
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set to true in the responses which are replayed
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyFilter makes the creation requests sent with an Idempotency-Key header idempotent. The successful response
+// of the first request with a key is stored for the user and replayed when the request is repeated with the same key
+// until the key expires. The key cannot be reused for a different request and failed requests can be retried with it.
+// The key is reserved only for the lease while the request is processed, so that it can be retried with the key if the
+// Service Manager instance processing it is gone.
+type IdempotencyFilter struct {
+	store storage.IdempotencyStore
+	ttl   time.Duration
+	lease time.Duration
+
+	cleanupMutex sync.Mutex
+	lastCleanup  time.Time
+}
+
+// NewIdempotencyFilter creates a filter which keeps the responses for the given time and reserves the keys of the
+// requests being processed for the given lease
+func NewIdempotencyFilter(store storage.IdempotencyStore, ttl, lease time.Duration) *IdempotencyFilter {
+	return &IdempotencyFilter{
+		store: store,
+		ttl:   ttl,
+		lease: lease,
+	}
+}
+
+// Name implements the web.Filter interface and returns the identifier of the filter
+func (*IdempotencyFilter) Name() string {
+	return IdempotencyFilterName
+}
+
+// Run implements the web.Filter interface and replays the stored response if the request has already been sent with
+// the same idempotency key
+func (f *IdempotencyFilter) Run(req *web.Request, next web.Handler) (*web.Response, error) {
+	key := req.Header.Get(IdempotencyKeyHeader)
+	if len(key) == 0 {
+		return next.Handle(req)
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("%s header should not be longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	ctx := req.Context()
+	f.deleteExpiredKeys(ctx)
+
+	idempotencyKey := &types.IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash(req),
+		ExpiresAt:   time.Now().Add(f.lease),
+	}
+	if user, ok := web.UserFromContext(ctx); ok {
+		idempotencyKey.UserName = user.Name
+	}
+	storedKey, err := f.store.ReserveIdempotencyKey(ctx, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not reserve idempotency key %s: %s", key, err)
+	}
+	if storedKey != nil {
+		return replay(ctx, storedKey, idempotencyKey.RequestHash)
+	}
+
+	// the key is released if the request fails, so that it can be retried
+	completed := false
+	defer func() {
+		if !completed {
+			if err := f.store.ReleaseIdempotencyKey(context.Background(), idempotencyKey.UserName, key); err != nil {
+				log.C(ctx).Errorf("Could not release idempotency key %s: %s", key, err)
+			}
+		}
+	}()
+
+	resp, err := next.Handle(req)
+	if err != nil || resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp, err
+	}
+
+	idempotencyKey.ExpiresAt = time.Now().Add(f.ttl)
+	idempotencyKey.StatusCode = resp.StatusCode
+	idempotencyKey.Header = resp.Header
+	idempotencyKey.Body = resp.Body
+	// the response is stored even if the client is gone, as the request has already been processed
+	if err := f.store.CompleteIdempotencyKey(context.Background(), idempotencyKey); err != nil {
+		log.C(ctx).Errorf("Could not store the response of the request with idempotency key %s: %s", key, err)
+		return resp, nil
+	}
+	completed = true
+	return resp, nil
+}
+
+// FilterMatchers implements the web.Filter interface and returns the conditions on which the filter should be executed
+func (*IdempotencyFilter) FilterMatchers() []web.FilterMatcher {
+	return []web.FilterMatcher{
+		{
+			Matchers: []web.Matcher{
+				web.Path(
+					web.ServiceBrokersURL,
+					web.PlatformsURL,
+					web.VisibilitiesURL,
+					web.RolesURL,
+					web.LabelDefinitionsURL,
+					web.ServiceInstancesURL,
+					web.ServiceBindingsURL,
+					web.PlatformsURL+"/*"+web.PlatformCredentialsURL+"/rotate",
+				),
+				web.Methods(http.MethodPost),
+			},
+		},
+		{
+			Matchers: []web.Matcher{
+				web.Path(web.BrokerPlatformCredentialsURL),
+				web.Methods(http.MethodPut),
+			},
+		},
+	}
+}
+
+// deleteExpiredKeys deletes the expired idempotency keys at most once per minute
+func (f *IdempotencyFilter) deleteExpiredKeys(ctx context.Context) {
+	f.cleanupMutex.Lock()
+	defer f.cleanupMutex.Unlock()
+	now := time.Now()
+	if now.Sub(f.lastCleanup) < time.Minute {
+		return
+	}
+	f.lastCleanup = now
+	if err := f.store.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+		log.C(ctx).Errorf("Could not delete expired idempotency keys: %s", err)
+	}
+}
+
+// replay returns the stored response if the key has been used for the same request
+func replay(ctx context.Context, storedKey *types.IdempotencyKey, requestHash string) (*web.Response, error) {
+	if storedKey.RequestHash != requestHash {
+		return nil, &util.HTTPError{
+			ErrorType:   "UnprocessableEntity",
+			Description: fmt.Sprintf("%s %s has already been used for a different request", IdempotencyKeyHeader, storedKey.Key),
+			StatusCode:  http.StatusUnprocessableEntity,
+		}
+	}
+	if !storedKey.Completed {
+		return nil, &util.HTTPError{
+			ErrorType:   "Conflict",
+			Description: fmt.Sprintf("request with %s %s is still being processed", IdempotencyKeyHeader, storedKey.Key),
+			StatusCode:  http.StatusConflict,
+		}
+	}
+
+	log.C(ctx).Infof("Replaying the response of the request with %s %s", IdempotencyKeyHeader, storedKey.Key)
+	header := storedKey.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+	return &web.Response{
+		StatusCode: storedKey.StatusCode,
+		Header:     header,
+		Body:       storedKey.Body,
+	}, nil
+}
+
+// requestHash identifies the request by its method, path, query and body
+func requestHash(req *web.Request) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s?%s\n", req.Method, req.URL.Path, req.URL.RawQuery)
+	hash.Write(req.Body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
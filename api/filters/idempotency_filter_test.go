@@ -0,0 +1,194 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/api/filters"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/pkg/web/webfakes"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/memory"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotency filter", func() {
+	var filter *filters.IdempotencyFilter
+	var handler *webfakes.FakeHandler
+	var store *memory.Storage
+
+	idempotentRequest := func(key, userName, json string) *web.Request {
+		req, err := http.NewRequest(http.MethodPost, web.PlatformsURL, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		req.Header.Set(filters.IdempotencyKeyHeader, key)
+		req = req.WithContext(web.ContextWithUser(req.Context(), &web.UserContext{Name: userName}))
+		return &web.Request{Request: req, Body: []byte(json)}
+	}
+
+	expectHTTPError := func(err error, statusCode int) {
+		httpErr, ok := err.(*util.HTTPError)
+		Expect(ok).To(BeTrue())
+		Expect(httpErr.StatusCode).To(Equal(statusCode))
+	}
+
+	BeforeEach(func() {
+		settings := storage.DefaultSettings()
+		settings.URI = memory.URIScheme
+		settings.EncryptionKey = "ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"
+		store = &memory.Storage{}
+		Expect(store.Open(settings)).To(Succeed())
+
+		handler = &webfakes.FakeHandler{}
+		handler.HandleReturns(&web.Response{
+			StatusCode: http.StatusAccepted,
+			Header:     http.Header{"Location": []string{"/v1/platforms/1/operations/2"}},
+			Body:       []byte(`{}`),
+		}, nil)
+		filter = filters.NewIdempotencyFilter(store, time.Hour, time.Minute)
+	})
+
+	AfterEach(func() {
+		Expect(store.Close()).To(Succeed())
+	})
+
+	When("the request has no idempotency key", func() {
+		It("should call the next filter for each request", func() {
+			req := idempotentRequest("", "user", `{"name": "platform"}`)
+			_, err := filter.Run(req, handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = filter.Run(req, handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(handler.HandleCallCount()).To(Equal(2))
+		})
+	})
+
+	When("the request is repeated with the same idempotency key", func() {
+		It("should replay the original response", func() {
+			_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			resp, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(handler.HandleCallCount()).To(Equal(1))
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+			Expect(resp.Header.Get("Location")).To(Equal("/v1/platforms/1/operations/2"))
+			Expect(resp.Header.Get(filters.IdempotentReplayedHeader)).To(Equal("true"))
+			Expect(string(resp.Body)).To(Equal(`{}`))
+		})
+
+		It("should process the request of another user", func() {
+			_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = filter.Run(idempotentRequest("key", "other-user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(handler.HandleCallCount()).To(Equal(2))
+		})
+
+		It("should process the request again after the key expires", func() {
+			filter = filters.NewIdempotencyFilter(store, time.Millisecond, time.Millisecond)
+			_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			time.Sleep(10 * time.Millisecond)
+			_, err = filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(handler.HandleCallCount()).To(Equal(2))
+		})
+	})
+
+	When("the request with the idempotency key was not completed", func() {
+		It("should process the request again after the lease expires", func() {
+			filter = filters.NewIdempotencyFilter(store, time.Hour, time.Millisecond)
+			_, err := store.ReserveIdempotencyKey(context.Background(), &types.IdempotencyKey{
+				UserName:    "user",
+				Key:         "key",
+				RequestHash: "hash of a request which was never completed",
+				ExpiresAt:   time.Now().Add(time.Millisecond),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			time.Sleep(10 * time.Millisecond)
+
+			_, err = filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(handler.HandleCallCount()).To(Equal(1))
+		})
+
+		It("should keep the response for the TTL once the request is completed", func() {
+			filter = filters.NewIdempotencyFilter(store, time.Hour, time.Millisecond)
+			_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			time.Sleep(10 * time.Millisecond)
+
+			resp, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.Header.Get(filters.IdempotentReplayedHeader)).To(Equal("true"))
+			Expect(handler.HandleCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the idempotency key is reused for a different request", func() {
+		It("should return 422", func() {
+			_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = filter.Run(idempotentRequest("key", "user", `{"name": "other-platform"}`), handler)
+			expectHTTPError(err, http.StatusUnprocessableEntity)
+			Expect(handler.HandleCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the request with the idempotency key is still being processed", func() {
+		It("should return 409", func() {
+			handler.HandleStub = func(req *web.Request) (*web.Response, error) {
+				_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), &webfakes.FakeHandler{})
+				expectHTTPError(err, http.StatusConflict)
+				return &web.Response{StatusCode: http.StatusCreated}, nil
+			}
+			_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	When("the request fails", func() {
+		It("should process the request again", func() {
+			handler.HandleReturnsOnCall(0, nil, &util.HTTPError{StatusCode: http.StatusBadGateway})
+			_, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			expectHTTPError(err, http.StatusBadGateway)
+
+			resp, err := filter.Run(idempotentRequest("key", "user", `{"name": "platform"}`), handler)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+			Expect(handler.HandleCallCount()).To(Equal(2))
+		})
+	})
+
+	When("the idempotency key is too long", func() {
+		It("should return 400", func() {
+			key := make([]byte, 256)
+			for i := range key {
+				key[i] = 'k'
+			}
+			_, err := filter.Run(idempotentRequest(string(key), "user", `{}`), handler)
+			expectHTTPError(err, http.StatusBadRequest)
+		})
+	})
+})
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/tidwall/gjson"
+)
+
+const LabelDefinitionsFilterName = "LabelDefinitionsFilter"
+
+// LabelDefinitionsFilter validates the labels of the created and patched resources against the label definitions
+// of their type. The label queries of the requests for resources of this type are typed as defined, so that the
+// values of int labels are compared as numbers.
+//
+// The definitions are enforced only for the requests to the Service Manager API. The service instances provisioned
+// by the platforms through the OSB API (/v1/osb/**) are stored without labels and are not validated, so that
+// required labels do not reject provisioning requests the platforms cannot add labels to.
+type LabelDefinitionsFilter struct {
+	repository      storage.Repository
+	refreshInterval time.Duration
+
+	mutex       sync.Mutex
+	definitions []*types.LabelDefinition
+	loadedAt    time.Time
+}
+
+// NewLabelDefinitionsFilter creates new filter which enforces the label definitions. The definitions are loaded
+// from the repository at most once per refresh interval.
+func NewLabelDefinitionsFilter(repository storage.Repository, refreshInterval time.Duration) *LabelDefinitionsFilter {
+	return &LabelDefinitionsFilter{
+		repository:      repository,
+		refreshInterval: refreshInterval,
+	}
+}
+
+func (f *LabelDefinitionsFilter) Name() string {
+	return LabelDefinitionsFilterName
+}
+
+func (f *LabelDefinitionsFilter) Run(req *web.Request, next web.Handler) (*web.Response, error) {
+	ctx := req.Context()
+	objectType, subPath := resourcePath(req.URL.Path)
+	if objectType == "" {
+		return next.Handle(req)
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		criteria := append([]query.Criterion{}, query.CriteriaForContext(ctx)...)
+		if !hasLabelCriteria(criteria) {
+			break
+		}
+		definitions, err := f.definitionsFor(ctx, objectType)
+		if err != nil {
+			return nil, err
+		}
+		for i := range criteria {
+			if definition, found := definitions[criteria[i].LeftOp]; found && criteria[i].Type == query.LabelQuery {
+				criteria[i].ValueType = definition.ValueType
+			}
+		}
+		if ctx, err = query.ContextWithCriteria(ctx, criteria...); err != nil {
+			return nil, err
+		}
+		req.Request = req.WithContext(ctx)
+	case http.MethodPost:
+		if subPath != "" {
+			break
+		}
+		labels := types.Labels{}
+		if labelsJSON := gjson.GetBytes(req.Body, "labels").Raw; len(labelsJSON) != 0 {
+			if err := util.BytesToObject([]byte(labelsJSON), &labels); err != nil {
+				return nil, err
+			}
+		}
+		definitions, err := f.definitionsFor(ctx, objectType)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateLabels(labels, definitions); err != nil {
+			return nil, err
+		}
+	case http.MethodPatch:
+		resourceID := req.PathParams[web.PathParamResourceID]
+		if resourceID == "" || subPath != resourceID {
+			break
+		}
+		labelChanges, err := query.LabelChangesFromJSON(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(labelChanges) == 0 {
+			break
+		}
+		definitions, err := f.definitionsFor(ctx, objectType)
+		if err != nil {
+			return nil, err
+		}
+		if len(definitions) == 0 {
+			break
+		}
+		object, err := f.repository.Get(ctx, objectType, query.ByField(query.EqualsOperator, "id", resourceID))
+		if err != nil {
+			return nil, util.HandleStorageError(err, string(objectType))
+		}
+		labels, _, _ := query.ApplyLabelChangesToLabels(labelChanges, object.GetLabels())
+		if err := validateLabels(labels, definitions); err != nil {
+			return nil, err
+		}
+	}
+	return next.Handle(req)
+}
+
+func (f *LabelDefinitionsFilter) FilterMatchers() []web.FilterMatcher {
+	paths := make([]string, 0, len(types.LabelledTypes))
+	for _, labelledType := range types.LabelledTypes {
+		paths = append(paths, string(labelledType)+"/**")
+	}
+	return []web.FilterMatcher{
+		{
+			Matchers: []web.Matcher{
+				web.Path(paths...),
+				web.Methods(http.MethodGet, http.MethodPost, http.MethodPatch),
+			},
+		},
+	}
+}
+
+// definitionsFor returns the label definitions which apply to the object type by label key
+func (f *LabelDefinitionsFilter) definitionsFor(ctx context.Context, objectType types.ObjectType) (map[string]*types.LabelDefinition, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.definitions == nil || time.Since(f.loadedAt) >= f.refreshInterval {
+		objectList, err := f.repository.List(ctx, types.LabelDefinitionType)
+		if err != nil {
+			return nil, util.HandleStorageError(err, string(types.LabelDefinitionType))
+		}
+		storedDefinitions := make([]*types.LabelDefinition, 0, objectList.Len())
+		for i := 0; i < objectList.Len(); i++ {
+			storedDefinitions = append(storedDefinitions, objectList.ItemAt(i).(*types.LabelDefinition))
+		}
+		log.C(ctx).Debugf("Loaded %d label definitions from storage", len(storedDefinitions))
+		f.definitions = storedDefinitions
+		f.loadedAt = time.Now()
+	}
+
+	definitions := make(map[string]*types.LabelDefinition)
+	for _, definition := range f.definitions {
+		if definition.AppliesTo(objectType) {
+			definitions[definition.Key] = definition
+		}
+	}
+	return definitions, nil
+}
+
+func hasLabelCriteria(criteria []query.Criterion) bool {
+	for _, criterion := range criteria {
+		if criterion.Type == query.LabelQuery {
+			return true
+		}
+	}
+	return false
+}
+
+func validateLabels(labels types.Labels, definitions map[string]*types.LabelDefinition) error {
+	for key, definition := range definitions {
+		values, found := labels[key]
+		if definition.Required && (!found || len(values) == 0) {
+			return &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: fmt.Sprintf("label %s is required", key),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+		if err := definition.ValidateValues(values); err != nil {
+			return &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: err.Error(),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+	}
+	return nil
+}
+
+// resourcePath splits the request path into the type of the requested resources and the path relative to them
+func resourcePath(path string) (types.ObjectType, string) {
+	segments := strings.SplitN(strings.Trim(path, "/"), "/", 3)
+	if len(segments) < 2 || segments[1] == "" {
+		return "", ""
+	}
+	objectType := types.ObjectType("/" + segments[0] + "/" + segments[1])
+	if len(segments) == 2 {
+		return objectType, ""
+	}
+	return objectType, segments[2]
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/api/filters"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/pkg/web/webfakes"
+	"github.com/Peripli/service-manager/storage/storagefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Label definitions filter", func() {
+	var filter *filters.LabelDefinitionsFilter
+	var handler *webfakes.FakeHandler
+	var repository *storagefakes.FakeStorage
+
+	newRequest := func(method, path, json string) *web.Request {
+		req, err := http.NewRequest(method, path, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		return &web.Request{Request: req, Body: []byte(json), PathParams: map[string]string{}}
+	}
+
+	expectBadRequest := func(req *web.Request, description string) {
+		_, err := filter.Run(req, handler)
+		httpErr, ok := err.(*util.HTTPError)
+		Expect(ok).To(BeTrue())
+		Expect(httpErr.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(httpErr.Description).To(ContainSubstring(description))
+		Expect(handler.HandleCallCount()).To(Equal(0))
+	}
+
+	expectNextHandler := func(req *web.Request) {
+		_, err := filter.Run(req, handler)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(handler.HandleCallCount()).To(Equal(1))
+	}
+
+	BeforeEach(func() {
+		handler = &webfakes.FakeHandler{}
+		repository = &storagefakes.FakeStorage{}
+		repository.ListReturns(&types.LabelDefinitions{
+			LabelDefinitions: []*types.LabelDefinition{
+				{
+					Key:           "tier",
+					ResourceTypes: []types.ObjectType{types.PlatformType},
+					ValueType:     types.LabelValueInt,
+					Cardinality:   types.LabelCardinalitySingle,
+					Required:      true,
+				},
+				{
+					Key:           "env",
+					ResourceTypes: []types.ObjectType{types.PlatformType, types.ServiceBrokerType},
+					ValueType:     types.LabelValueEnum,
+					AllowedValues: []string{"dev", "prod"},
+				},
+			},
+		}, nil)
+		filter = filters.NewLabelDefinitionsFilter(repository, time.Hour)
+	})
+
+	Context("POST", func() {
+		When("the labels match the definitions", func() {
+			It("should call next filter in chain", func() {
+				expectNextHandler(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"tier": ["1"], "env": ["dev", "prod"], "other": ["value"]}}`))
+			})
+		})
+
+		When("a required label is missing", func() {
+			It("should return 400", func() {
+				expectBadRequest(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"env": ["dev"]}}`), "label tier is required")
+			})
+		})
+
+		When("a value does not match the type of the label", func() {
+			It("should return 400", func() {
+				expectBadRequest(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"tier": ["high"]}}`), `value "high" of label tier is not a valid int`)
+			})
+		})
+
+		When("a value is not allowed by an enum label", func() {
+			It("should return 400", func() {
+				expectBadRequest(newRequest(http.MethodPost, web.ServiceBrokersURL, `{"labels": {"env": ["test"]}}`), `value "test" of label env is not a valid enum`)
+			})
+		})
+
+		When("a single value label has multiple values", func() {
+			It("should return 400", func() {
+				expectBadRequest(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"tier": ["1", "2"]}}`), "label tier allows a single value")
+			})
+		})
+
+		When("no definition applies to the resource type", func() {
+			It("should call next filter in chain", func() {
+				expectNextHandler(newRequest(http.MethodPost, web.VisibilitiesURL, `{"labels": {"tier": ["high"]}}`))
+			})
+		})
+	})
+
+	Context("PATCH", func() {
+		var req *web.Request
+
+		patchRequest := func(json string) *web.Request {
+			req := newRequest(http.MethodPatch, web.PlatformsURL+"/platform-id", json)
+			req.PathParams[web.PathParamResourceID] = "platform-id"
+			return req
+		}
+
+		BeforeEach(func() {
+			repository.GetReturns(&types.Platform{
+				Base: types.Base{
+					ID:     "platform-id",
+					Labels: types.Labels{"tier": {"1"}},
+				},
+			}, nil)
+		})
+
+		When("the labels are valid after the changes", func() {
+			It("should call next filter in chain", func() {
+				req = patchRequest(`{"labels": [{"op": "add", "key": "env", "values": ["prod"]}]}`)
+				expectNextHandler(req)
+				_, _, criteria := repository.GetArgsForCall(0)
+				Expect(criteria).To(ConsistOf(query.ByField(query.EqualsOperator, "id", "platform-id")))
+			})
+		})
+
+		When("a required label is removed", func() {
+			It("should return 400", func() {
+				expectBadRequest(patchRequest(`{"labels": [{"op": "remove", "key": "tier"}]}`), "label tier is required")
+			})
+		})
+
+		When("a second value is added to a single value label", func() {
+			It("should return 400", func() {
+				expectBadRequest(patchRequest(`{"labels": [{"op": "add_values", "key": "tier", "values": ["2"]}]}`), "label tier allows a single value")
+			})
+		})
+
+		When("the resource cannot be fetched", func() {
+			It("should return the error without calling next filter", func() {
+				repository.GetReturns(nil, util.ErrNotFoundInStorage)
+				_, err := filter.Run(patchRequest(`{"labels": [{"op": "add", "key": "env", "values": ["prod"]}]}`), handler)
+				httpErr, ok := err.(*util.HTTPError)
+				Expect(ok).To(BeTrue())
+				Expect(httpErr.StatusCode).To(Equal(http.StatusNotFound))
+				Expect(handler.HandleCallCount()).To(Equal(0))
+			})
+		})
+
+		When("no labels are changed", func() {
+			It("should call next filter in chain without loading the definitions", func() {
+				expectNextHandler(patchRequest(`{"name": "new-name"}`))
+				Expect(repository.ListCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("GET", func() {
+		listRequest := func(path string, criteria ...query.Criterion) *web.Request {
+			req := newRequest(http.MethodGet, path, "")
+			ctx, err := query.AddCriteria(req.Context(), criteria...)
+			Expect(err).ShouldNot(HaveOccurred())
+			req.Request = req.WithContext(ctx)
+			return req
+		}
+
+		It("should set the value types of the defined labels in the label queries", func() {
+			req := listRequest(web.PlatformsURL,
+				query.ByLabel(query.GreaterThanOperator, "tier", "5"),
+				query.ByLabel(query.EqualsOperator, "other", "value"))
+			expectNextHandler(req)
+
+			nextReq := handler.HandleArgsForCall(0)
+			criteria := query.CriteriaForContext(nextReq.Context())
+			Expect(criteria).To(HaveLen(2))
+			Expect(criteria[0].ValueType).To(Equal(types.LabelValueInt))
+			Expect(criteria[1].ValueType).To(BeEmpty())
+		})
+
+		It("should return error if the right operand does not match the value type", func() {
+			_, err := filter.Run(listRequest(web.PlatformsURL, query.ByLabel(query.GreaterThanOperator, "tier", "5.5")), handler)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not an integer"))
+			Expect(handler.HandleCallCount()).To(Equal(0))
+		})
+
+		It("should not load the definitions if there are no label queries", func() {
+			expectNextHandler(listRequest(web.PlatformsURL, query.ByField(query.EqualsOperator, "name", "value")))
+			Expect(repository.ListCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("definitions cache", func() {
+		It("should load the definitions at most once per refresh interval", func() {
+			expectBadRequest(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"env": ["dev"]}}`), "label tier is required")
+			expectBadRequest(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"env": ["dev"]}}`), "label tier is required")
+			Expect(repository.ListCallCount()).To(Equal(1))
+		})
+
+		It("should reload the definitions after the refresh interval", func() {
+			filter = filters.NewLabelDefinitionsFilter(repository, time.Nanosecond)
+			expectBadRequest(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"env": ["dev"]}}`), "label tier is required")
+
+			repository.ListReturns(&types.LabelDefinitions{}, nil)
+			time.Sleep(time.Millisecond)
+			expectNextHandler(newRequest(http.MethodPost, web.PlatformsURL, `{"labels": {"env": ["dev"]}}`))
+			Expect(repository.ListCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("FilterMatchers", func() {
+		matches := func(path string) bool {
+			match, err := filter.FilterMatchers()[0].Matchers[0].Matches(web.Endpoint{Path: path, Method: http.MethodPatch})
+			Expect(err).ShouldNot(HaveOccurred())
+			return match
+		}
+
+		It("should match the labelled resources", func() {
+			Expect(matches(web.PlatformsURL)).To(BeTrue())
+			Expect(matches(web.ServiceInstancesURL + "/instance-id")).To(BeTrue())
+		})
+
+		It("should not match the other resources", func() {
+			Expect(matches(web.OperationsURL)).To(BeFalse())
+			Expect(matches(web.LabelDefinitionsURL + "/definition-id")).To(BeFalse())
+		})
+	})
+})
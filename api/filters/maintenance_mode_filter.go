@@ -0,0 +1,91 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/maintenance"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// MaintenanceModeFilterName is the name of the filter rejecting the changes during read-only maintenance mode
+const MaintenanceModeFilterName = "MaintenanceModeFilter"
+
+// MaintenanceModeFilter rejects the requests which change the state of the Service Manager while it is in read-only
+// maintenance mode. Only the maintenance mode itself can be changed, so that read-only mode can be switched off.
+type MaintenanceModeFilter struct {
+	state      *maintenance.State
+	retryAfter time.Duration
+}
+
+// NewMaintenanceModeFilter creates a filter which advises the clients to retry the rejected requests after the given time
+func NewMaintenanceModeFilter(state *maintenance.State, retryAfter time.Duration) *MaintenanceModeFilter {
+	return &MaintenanceModeFilter{
+		state:      state,
+		retryAfter: retryAfter,
+	}
+}
+
+// Name implements the web.Filter interface and returns the identifier of the filter
+func (*MaintenanceModeFilter) Name() string {
+	return MaintenanceModeFilterName
+}
+
+// Run implements the web.Filter interface and rejects the request if the Service Manager is in read-only mode
+func (f *MaintenanceModeFilter) Run(req *web.Request, next web.Handler) (*web.Response, error) {
+	if strings.TrimSuffix(req.URL.Path, "/") == web.MaintenanceConfigURL {
+		return next.Handle(req)
+	}
+	mode := f.state.Mode(req.Context())
+	if !mode.ReadOnly {
+		return next.Handle(req)
+	}
+
+	log.C(req.Context()).Infof("Rejecting %s %s as Service Manager is in read-only mode", req.Method, req.URL.Path)
+	description := "Service Manager is in read-only mode due to maintenance"
+	if len(mode.Reason) != 0 {
+		description += ": " + mode.Reason
+	}
+	resp, err := util.NewJSONResponse(http.StatusServiceUnavailable, &util.HTTPError{
+		ErrorType:   "ServiceUnavailable",
+		Description: description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(f.retryAfter.Seconds()))))
+	return resp, nil
+}
+
+// FilterMatchers implements the web.Filter interface and returns the conditions on which the filter should be executed
+func (*MaintenanceModeFilter) FilterMatchers() []web.FilterMatcher {
+	return []web.FilterMatcher{
+		{
+			Matchers: []web.Matcher{
+				web.Path("/**"),
+				web.Methods(http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete),
+			},
+		},
+	}
+}
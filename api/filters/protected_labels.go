@@ -19,6 +19,7 @@ package filters
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/Peripli/service-manager/pkg/types"
 
@@ -32,18 +33,26 @@ const ProtectedLabelsFilterName = "ProtectedLabelsFilter"
 
 // ProtectedLabelsFilter checks for forbidden labels being modified/added
 type ProtectedLabelsFilter struct {
+	mutex           sync.RWMutex
 	protectedLabels map[string]bool
 }
 
 // NewProtectedLabelsFilter creates new filter for forbidden labels
 func NewProtectedLabelsFilter(forbiddenLabels []string) *ProtectedLabelsFilter {
+	filter := &ProtectedLabelsFilter{}
+	filter.SetProtectedLabels(forbiddenLabels)
+	return filter
+}
+
+// SetProtectedLabels changes the labels which cannot be modified/added
+func (flo *ProtectedLabelsFilter) SetProtectedLabels(forbiddenLabels []string) {
 	forbiddenLabelsMap := make(map[string]bool)
 	for _, label := range forbiddenLabels {
 		forbiddenLabelsMap[label] = true
 	}
-	return &ProtectedLabelsFilter{
-		protectedLabels: forbiddenLabelsMap,
-	}
+	flo.mutex.Lock()
+	defer flo.mutex.Unlock()
+	flo.protectedLabels = forbiddenLabelsMap
 }
 
 func (flo *ProtectedLabelsFilter) Name() string {
@@ -51,7 +60,10 @@ func (flo *ProtectedLabelsFilter) Name() string {
 }
 
 func (flo *ProtectedLabelsFilter) Run(req *web.Request, next web.Handler) (*web.Response, error) {
-	if len(flo.protectedLabels) == 0 {
+	flo.mutex.RLock()
+	protectedLabels := flo.protectedLabels
+	flo.mutex.RUnlock()
+	if len(protectedLabels) == 0 {
 		return next.Handle(req)
 	}
 
@@ -71,7 +83,7 @@ func (flo *ProtectedLabelsFilter) Run(req *web.Request, next web.Handler) (*web.
 			return nil, err
 		}
 		for lKey := range labels {
-			_, found := flo.protectedLabels[lKey]
+			_, found := protectedLabels[lKey]
 			if !found {
 				continue
 			}
@@ -88,7 +100,7 @@ func (flo *ProtectedLabelsFilter) Run(req *web.Request, next web.Handler) (*web.
 			return nil, err
 		}
 		for _, lc := range labelChanges {
-			_, found := flo.protectedLabels[lc.Key]
+			_, found := protectedLabels[lc.Key]
 			if !found {
 				continue
 			}
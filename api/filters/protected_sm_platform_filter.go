@@ -39,5 +39,11 @@ func (f *ProtectedSMPlatformFilter) FilterMatchers() []web.FilterMatcher {
 				web.Methods(http.MethodPatch, http.MethodDelete),
 			},
 		},
+		{
+			Matchers: []web.Matcher{
+				web.Path(web.PlatformsURL + "/*" + web.PlatformCredentialsURL + "/**"),
+				web.Methods(http.MethodPost, http.MethodDelete),
+			},
+		},
 	}
 }
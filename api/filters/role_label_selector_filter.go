@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Peripli/service-manager/pkg/security/rbac"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/tidwall/gjson"
+)
+
+// RoleLabelSelectorFilterName is the name of RoleLabelSelectorFilter
+const RoleLabelSelectorFilterName = "RoleLabelSelectorFilter"
+
+// RoleLabelSelectorFilter checks that the label selectors of role permissions are valid label queries
+type RoleLabelSelectorFilter struct {
+}
+
+func (*RoleLabelSelectorFilter) Name() string {
+	return RoleLabelSelectorFilterName
+}
+
+func (*RoleLabelSelectorFilter) Run(req *web.Request, next web.Handler) (*web.Response, error) {
+	for _, selector := range gjson.GetBytes(req.Body, "permissions.#.label_selector").Array() {
+		if err := rbac.ValidateLabelSelector(selector.String()); err != nil {
+			return nil, &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: fmt.Sprintf("invalid label selector %s: %s", selector.String(), err),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+	}
+	return next.Handle(req)
+}
+
+func (*RoleLabelSelectorFilter) FilterMatchers() []web.FilterMatcher {
+	return []web.FilterMatcher{
+		{
+			Matchers: []web.Matcher{
+				web.Path(web.RolesURL + "/**"),
+				web.Methods(http.MethodPost, http.MethodPatch),
+			},
+		},
+	}
+}
@@ -49,6 +49,13 @@ func (l *SelectionCriteria) Run(req *web.Request, next web.Handler) (*web.Respon
 		}
 		criteria = append(criteria, queryCriteria...)
 	}
+	if req.Method == http.MethodGet {
+		orderCriteria, err := query.ParseOrderBy(req.URL.Query().Get(query.OrderBy))
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, orderCriteria...)
+	}
 	ctx, err := query.AddCriteria(ctx, criteria...)
 	if err != nil {
 		return nil, err
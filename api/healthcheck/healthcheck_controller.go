@@ -24,27 +24,39 @@ import (
 	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/pkg/web"
 	"net/http"
+	"sync"
 )
 
-// controller platform controller
-type controller struct {
+// Controller healthcheck controller
+type Controller struct {
+	mutex      sync.RWMutex
 	health     gohealth.IHealth
 	thresholds map[string]int64
 }
 
 // NewController returns a new healthcheck controller with the given health and thresholds
-func NewController(health gohealth.IHealth, thresholds map[string]int64) web.Controller {
-	return &controller{
+func NewController(health gohealth.IHealth, thresholds map[string]int64) *Controller {
+	return &Controller{
 		health:     health,
 		thresholds: thresholds,
 	}
 }
 
+// Configure replaces the health and the thresholds of the controller
+func (c *Controller) Configure(health gohealth.IHealth, thresholds map[string]int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.health = health
+	c.thresholds = thresholds
+}
+
 // healthCheck handler for GET /v1/monitor/health
-func (c *controller) healthCheck(r *web.Request) (*web.Response, error) {
+func (c *Controller) healthCheck(r *web.Request) (*web.Response, error) {
 	ctx := r.Context()
 	logger := log.C(ctx)
 	logger.Debugf("Performing health check...")
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 	healthState, _, _ := c.health.State()
 	healthResult := c.aggregate(ctx, healthState)
 	var status int
@@ -56,7 +68,7 @@ func (c *controller) healthCheck(r *web.Request) (*web.Response, error) {
 	return util.NewJSONResponse(status, healthResult)
 }
 
-func (c *controller) aggregate(ctx context.Context, healthState map[string]gohealth.State) *health.Health {
+func (c *Controller) aggregate(ctx context.Context, healthState map[string]gohealth.State) *health.Health {
 	if len(healthState) == 0 {
 		return health.New().WithStatus(health.StatusUp)
 	}
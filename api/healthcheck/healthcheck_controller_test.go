@@ -64,7 +64,7 @@ var _ = Describe("Healthcheck controller", func() {
 
 	Describe("aggregation", func() {
 		var ctx context.Context
-		var c *controller
+		var c *Controller
 		var healths map[string]h.State
 		var thresholds map[string]int64
 
@@ -79,7 +79,7 @@ var _ = Describe("Healthcheck controller", func() {
 				"test1": 3,
 				"test2": 3,
 			}
-			c = &controller{
+			c = &Controller{
 				health:     HealthFake{},
 				thresholds: thresholds,
 			}
@@ -150,7 +150,7 @@ var _ = Describe("Healthcheck controller", func() {
 	})
 })
 
-func createController(status health.Status) *controller {
+func createController(status health.Status) *Controller {
 	stringStatus := "ok"
 	var contiguousFailures int64 = 0
 	if status == health.StatusDown {
@@ -158,7 +158,7 @@ func createController(status health.Status) *controller {
 		contiguousFailures = 1
 	}
 
-	return &controller{
+	return &Controller{
 		health: HealthFake{
 			state: map[string]h.State{
 				"test1": {Status: stringStatus, Fatal: true, ContiguousFailures: contiguousFailures},
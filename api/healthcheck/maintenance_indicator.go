@@ -0,0 +1,56 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"context"
+
+	"github.com/Peripli/service-manager/pkg/health"
+	"github.com/Peripli/service-manager/pkg/maintenance"
+)
+
+// NewMaintenanceIndicator returns new health indicator for the maintenance mode. The read-only mode does not affect
+// the health status, as the Service Manager keeps serving the reads.
+func NewMaintenanceIndicator(ctx context.Context, state *maintenance.State) health.Indicator {
+	return &maintenanceIndicator{
+		ctx:   ctx,
+		state: state,
+	}
+}
+
+type maintenanceIndicator struct {
+	ctx   context.Context
+	state *maintenance.State
+}
+
+// Name returns the name of the indicator
+func (mi *maintenanceIndicator) Name() string {
+	return health.MaintenanceIndicatorName
+}
+
+// Status returns status of the health check
+func (mi *maintenanceIndicator) Status() (interface{}, error) {
+	mode := mi.state.Mode(mi.ctx)
+	details := map[string]interface{}{
+		"read_only": mode.ReadOnly,
+	}
+	if mode.ReadOnly {
+		details["reason"] = mode.Reason
+		details["since"] = mode.UpdatedAt
+	}
+	return details, nil
+}
@@ -26,7 +26,7 @@ import (
 )
 
 // NewPlatformIndicator returns new health indicator for platforms of given type
-func NewPlatformIndicator(ctx context.Context, repository storage.Repository, fatal func(*types.Platform) bool) health.Indicator {
+func NewPlatformIndicator(ctx context.Context, repository storage.Repository, lagStore storage.NotificationsLagStore, fatal func(*types.Platform) bool) health.Indicator {
 	if fatal == nil {
 		fatal = func(platform *types.Platform) bool {
 			return true
@@ -35,12 +35,14 @@ func NewPlatformIndicator(ctx context.Context, repository storage.Repository, fa
 	return &platformIndicator{
 		ctx:        ctx,
 		repository: repository,
+		lagStore:   lagStore,
 		fatal:      fatal,
 	}
 }
 
 type platformIndicator struct {
 	repository storage.Repository
+	lagStore   storage.NotificationsLagStore
 	ctx        context.Context
 	fatal      func(*types.Platform) bool
 }
@@ -58,6 +60,11 @@ func (pi *platformIndicator) Status() (interface{}, error) {
 	}
 	platforms := objList.(*types.Platforms).Platforms
 
+	lags, err := pi.lagStore.GetNotificationsLags(pi.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch notifications lags of platforms from storage: %v", err)
+	}
+
 	details := make(map[string]*health.Health)
 	inactivePlatforms := 0
 	fatalInactivePlatforms := 0
@@ -74,6 +81,10 @@ func (pi *platformIndicator) Status() (interface{}, error) {
 				fatalInactivePlatforms++
 			}
 		}
+
+		if lag, found := lags[platform.ID]; found {
+			details[platform.Name].WithDetail("notifications_lag", lag)
+		}
 	}
 
 	if fatalInactivePlatforms > 0 {
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"github.com/Peripli/service-manager/pkg/health"
 	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
 	storagefakes2 "github.com/Peripli/service-manager/storage/storagefakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -32,17 +33,25 @@ var _ = Describe("Platforms Indicator", func() {
 	var repository *storagefakes2.FakeStorage
 	var ctx context.Context
 	var platform *types.Platform
+	var lags map[string]int64
+	var lagsErr error
 
 	BeforeEach(func() {
 		ctx = context.TODO()
 		repository = &storagefakes2.FakeStorage{}
+		lags = map[string]int64{}
+		lagsErr = nil
 		platform = &types.Platform{
+			Base:       types.Base{ID: "test-platform-id"},
 			Name:       "test-platform",
 			Type:       "kubernetes",
 			Active:     false,
 			LastActive: time.Now(),
 		}
-		indicator = NewPlatformIndicator(ctx, repository, nil)
+		lagStore := storage.NotificationsLagsFunc(func(context.Context) (map[string]int64, error) {
+			return lags, lagsErr
+		})
+		indicator = NewPlatformIndicator(ctx, repository, lagStore, nil)
 	})
 
 	Context("Name", func() {
@@ -53,7 +62,7 @@ var _ = Describe("Platforms Indicator", func() {
 
 	Context("There are inactive platforms", func() {
 		BeforeEach(func() {
-			objectList := &types.Platforms{[]*types.Platform{platform}}
+			objectList := &types.Platforms{Platforms: []*types.Platform{platform}}
 			repository.ListReturns(objectList, nil)
 		})
 		It("should return error", func() {
@@ -80,7 +89,7 @@ var _ = Describe("Platforms Indicator", func() {
 	Context("All platforms are active", func() {
 		BeforeEach(func() {
 			platform.Active = true
-			objectList := &types.Platforms{[]*types.Platform{platform}}
+			objectList := &types.Platforms{Platforms: []*types.Platform{platform}}
 			repository.ListReturns(objectList, nil)
 		})
 		It("should not return error", func() {
@@ -88,4 +97,51 @@ var _ = Describe("Platforms Indicator", func() {
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 	})
+
+	Context("Platform acknowledges notifications", func() {
+		BeforeEach(func() {
+			platform.Active = true
+			objectList := &types.Platforms{Platforms: []*types.Platform{platform}}
+			repository.ListReturns(objectList, nil)
+			lags[platform.ID] = 3
+		})
+		It("should return the notifications lag", func() {
+			details, err := indicator.Status()
+			Expect(err).ShouldNot(HaveOccurred())
+			health := details.(map[string]*health.Health)[platform.Name]
+			Expect(health.Details["notifications_lag"]).To(Equal(int64(3)))
+		})
+		It("should not count the notifications of each platform separately", func() {
+			_, err := indicator.Status()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(repository.CountCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("Platform does not acknowledge notifications", func() {
+		BeforeEach(func() {
+			platform.Active = true
+			objectList := &types.Platforms{Platforms: []*types.Platform{platform}}
+			repository.ListReturns(objectList, nil)
+		})
+		It("should not return the notifications lag", func() {
+			details, err := indicator.Status()
+			Expect(err).ShouldNot(HaveOccurred())
+			health := details.(map[string]*health.Health)[platform.Name]
+			Expect(health.Details).ToNot(HaveKey("notifications_lag"))
+		})
+	})
+
+	Context("Notifications lags cannot be fetched", func() {
+		BeforeEach(func() {
+			objectList := &types.Platforms{Platforms: []*types.Platform{platform}}
+			repository.ListReturns(objectList, nil)
+			lagsErr = errors.New("lags err")
+		})
+		It("should return error", func() {
+			_, err := indicator.Status()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(lagsErr.Error()))
+		})
+	})
 })
@@ -23,6 +23,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
 	"github.com/gorilla/mux"
 )
 
@@ -99,6 +100,8 @@ func convertToWebRequest(request *http.Request, rw http.ResponseWriter) (*web.Re
 		}
 	}
 
+	// reads may be served by read replicas until the request writes to the storage
+	request = request.WithContext(storage.ContextWithWriteTracking(request.Context()))
 	webReq := &web.Request{
 		Request:    request,
 		PathParams: pathParams,
@@ -17,9 +17,11 @@
 package info
 
 import (
-	"github.com/Peripli/service-manager/pkg/util"
 	"net/http"
 
+	"github.com/Peripli/service-manager/pkg/maintenance"
+	"github.com/Peripli/service-manager/pkg/util"
+
 	"github.com/Peripli/service-manager/pkg/web"
 )
 
@@ -30,10 +32,23 @@ type Controller struct {
 	// TokenBasicAuth specifies if client credentials should be sent in the header
 	// as basic auth (true) or in the body (false)
 	TokenBasicAuth bool `json:"token_basic_auth"`
+
+	// MaintenanceState provides whether the Service Manager is in read-only maintenance mode
+	MaintenanceState *maintenance.State `json:"-"`
+}
+
+// info is the information about the Service Manager returned by the info endpoint
+type info struct {
+	*Controller
+	ReadOnly bool `json:"read_only"`
 }
 
 var _ web.Controller = &Controller{}
 
 func (c *Controller) getInfo(request *web.Request) (*web.Response, error) {
-	return util.NewJSONResponse(http.StatusOK, c)
+	result := &info{Controller: c}
+	if c.MaintenanceState != nil {
+		result.ReadOnly = c.MaintenanceState.ReadOnly(request.Context())
+	}
+	return util.NewJSONResponse(http.StatusOK, result)
 }
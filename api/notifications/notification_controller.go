@@ -19,6 +19,7 @@ package notifications
 import (
 	"context"
 	"net/http"
+	"sync"
 
 	"github.com/Peripli/service-manager/storage"
 
@@ -32,8 +33,9 @@ type Controller struct {
 	baseCtx    context.Context
 	repository storage.TransactionalRepository
 
-	wsSettings  *ws.Settings
-	notificator storage.Notificator
+	wsSettingsMutex sync.RWMutex
+	wsSettings      *ws.Settings
+	notificator     storage.Notificator
 }
 
 // Routes returns the routes for notifications
@@ -59,3 +61,16 @@ func NewController(baseCtx context.Context, repository storage.TransactionalRepo
 		notificator: notificator,
 	}
 }
+
+// SetWSSettings changes the timeouts of the websocket connections
+func (c *Controller) SetWSSettings(wsSettings *ws.Settings) {
+	c.wsSettingsMutex.Lock()
+	defer c.wsSettingsMutex.Unlock()
+	c.wsSettings = wsSettings
+}
+
+func (c *Controller) currentWSSettings() *ws.Settings {
+	c.wsSettingsMutex.RLock()
+	defer c.wsSettingsMutex.RUnlock()
+	return c.wsSettings
+}
@@ -2,16 +2,20 @@ package notifications
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Peripli/service-manager/pkg/query"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/interceptors"
 	"github.com/gorilla/websocket"
 
 	"github.com/Peripli/service-manager/pkg/log"
@@ -22,8 +26,26 @@ import (
 const (
 	LastKnownRevisionHeader     = "last_notification_revision"
 	LastKnownRevisionQueryParam = "last_notification_revision"
+
+	// SnapshotQueryParam allows proxies to request a snapshot of the current state instead of 410 Gone
+	// when they have no known revision or their revision is no longer available
+	SnapshotQueryParam = "snapshot"
+	// AckQueryParam enables the acknowledgement of notifications. Proxies acknowledge the processed notifications by
+	// sending an Acknowledgement message and receive the notifications after the acknowledged revision when they
+	// reconnect without specifying a revision.
+	AckQueryParam = "ack"
+	// ResourceTypesQueryParam is a comma separated list of the resource types whose notifications should be sent
+	ResourceTypesQueryParam = "resource_types"
+	// SnapshotHeader is returned when the first notifications sent on the connection are a snapshot of the current state.
+	// The snapshot notifications have the revision returned in the last known revision header.
+	SnapshotHeader = "notifications_snapshot"
 )
 
+// Acknowledgement is sent by proxies which acknowledge notifications after they have processed the notifications up to the revision
+type Acknowledgement struct {
+	Revision int64 `json:"revision"`
+}
+
 func (c *Controller) handleWS(req *web.Request) (*web.Response, error) {
 	ctx := req.Context()
 	logger := log.C(ctx)
@@ -43,6 +65,39 @@ func (c *Controller) handleWS(req *web.Request) (*web.Response, error) {
 		}
 	}
 
+	snapshotRequested := false
+	if snapshotStr := req.URL.Query().Get(SnapshotQueryParam); snapshotStr != "" {
+		var err error
+		snapshotRequested, err = strconv.ParseBool(snapshotStr)
+		if err != nil {
+			logger.Errorf("could not convert string %s to boolean: %v", snapshotStr, err)
+			return nil, &util.HTTPError{
+				StatusCode:  http.StatusBadRequest,
+				Description: fmt.Sprintf("invalid %s query parameter", SnapshotQueryParam),
+				ErrorType:   "BadRequest",
+			}
+		}
+	}
+
+	ackEnabled := false
+	if ackStr := req.URL.Query().Get(AckQueryParam); ackStr != "" {
+		var err error
+		ackEnabled, err = strconv.ParseBool(ackStr)
+		if err != nil {
+			logger.Errorf("could not convert string %s to boolean: %v", ackStr, err)
+			return nil, &util.HTTPError{
+				StatusCode:  http.StatusBadRequest,
+				Description: fmt.Sprintf("invalid %s query parameter", AckQueryParam),
+				ErrorType:   "BadRequest",
+			}
+		}
+	}
+
+	subscription, err := parseSubscription(req)
+	if err != nil {
+		return nil, err
+	}
+
 	user, ok := web.UserFromContext(req.Context())
 	if !ok {
 		return nil, errors.New("user details not found in request context")
@@ -52,7 +107,31 @@ func (c *Controller) handleWS(req *web.Request) (*web.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	notificationQueue, lastKnownToSMRevision, err := c.notificator.RegisterConsumer(platform, revisionKnownToProxy)
+
+	if ackEnabled && revisionKnownToProxyStr == "" {
+		acknowledgedRevision, err := c.acknowledgedRevision(ctx, platform.ID)
+		if err != nil {
+			return nil, err
+		}
+		if acknowledgedRevision > 0 {
+			logger.Infof("Sending notifications after acknowledged revision %d to platform %s", acknowledgedRevision, platform.ID)
+			revisionKnownToProxy = acknowledgedRevision
+		}
+	}
+
+	var notificationQueue storage.NotificationQueue
+	var lastKnownToSMRevision int64
+	isSnapshot := snapshotRequested && revisionKnownToProxy == types.InvalidRevision
+	if !isSnapshot {
+		notificationQueue, lastKnownToSMRevision, err = c.notificator.RegisterConsumer(platform, revisionKnownToProxy, subscription)
+		if err == util.ErrInvalidNotificationRevision && snapshotRequested {
+			logger.Infof("Revision %d is not known to SM. Sending snapshot to platform %s", revisionKnownToProxy, platform.ID)
+			isSnapshot = true
+		}
+	}
+	if isSnapshot {
+		notificationQueue, lastKnownToSMRevision, err = c.notificator.RegisterSnapshotConsumer(platform, c.notificationsSnapshot, subscription)
+	}
 	if err != nil {
 		if err == util.ErrInvalidNotificationRevision {
 			return util.NewJSONResponse(http.StatusGone, nil)
@@ -74,6 +153,9 @@ func (c *Controller) handleWS(req *web.Request) (*web.Response, error) {
 	if lastKnownToSMRevision != types.InvalidRevision {
 		responseHeaders.Add(LastKnownRevisionHeader, strconv.FormatInt(lastKnownToSMRevision, 10))
 	}
+	if isSnapshot {
+		responseHeaders.Add(SnapshotHeader, "true")
+	}
 
 	conn, err := c.upgrade(childCtx, c.repository, platform, rw, req.Request, responseHeaders)
 	if err != nil {
@@ -85,7 +167,7 @@ func (c *Controller) handleWS(req *web.Request) (*web.Response, error) {
 
 	go c.closeConn(childCtx, childCtxCancel, conn, done)
 	go c.writeLoop(childCtx, conn, notificationQueue, done)
-	go c.readLoop(childCtx, c.repository, platform, conn, done)
+	go c.readLoop(childCtx, c.repository, platform, conn, ackEnabled, done)
 
 	return &web.Response{}, nil
 }
@@ -122,7 +204,7 @@ func (c *Controller) writeLoop(ctx context.Context, conn *websocket.Conn, q stor
 	}
 }
 
-func (c *Controller) readLoop(ctx context.Context, repository storage.TransactionalRepository, platform *types.Platform, conn *websocket.Conn, done chan<- struct{}) {
+func (c *Controller) readLoop(ctx context.Context, repository storage.TransactionalRepository, platform *types.Platform, conn *websocket.Conn, ackEnabled bool, done chan<- struct{}) {
 	defer func() {
 		if err := recover(); err != nil {
 			log.C(ctx).Errorf("recovered from panic while reading from websocket connection: %s", err)
@@ -134,9 +216,9 @@ func (c *Controller) readLoop(ctx context.Context, repository storage.Transactio
 	}()
 
 	for {
-		// ReadMessage is needed only to receive ping/pong/close control messages
-		// currently we don't expect to receive something else from the proxies
-		_, _, err := conn.ReadMessage()
+		// ReadMessage is needed to receive ping/pong/close control messages
+		// and the acknowledgements of the proxies which acknowledge notifications
+		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			log.C(ctx).WithError(err).Error("ws: could not read")
 			if err = updatePlatformStatus(ctx, repository, platform.ID, false); err != nil {
@@ -144,11 +226,27 @@ func (c *Controller) readLoop(ctx context.Context, repository storage.Transactio
 			}
 			return
 		}
+		if !ackEnabled || messageType != websocket.TextMessage {
+			continue
+		}
+
+		ack := &Acknowledgement{}
+		if err := json.Unmarshal(message, ack); err != nil || ack.Revision <= 0 {
+			log.C(ctx).Warnf("ws: invalid acknowledgement %s received from platform %s", message, platform.ID)
+			continue
+		}
+		if err := acknowledgeNotifications(ctx, repository, platform.ID, ack.Revision); err != nil {
+			if httpErr, ok := err.(*util.HTTPError); ok && httpErr.StatusCode == http.StatusBadRequest {
+				log.C(ctx).Warnf("ws: invalid acknowledgement %s received from platform %s: %s", message, platform.ID, httpErr.Description)
+				continue
+			}
+			log.C(ctx).WithError(err).Errorf("could not store acknowledged revision %d of platform %s", ack.Revision, platform.ID)
+		}
 	}
 }
 
 func (c *Controller) sendWsMessage(ctx context.Context, conn *websocket.Conn, msg interface{}) bool {
-	if err := conn.SetWriteDeadline(time.Now().Add(c.wsSettings.WriteTimeout)); err != nil {
+	if err := conn.SetWriteDeadline(time.Now().Add(c.currentWSSettings().WriteTimeout)); err != nil {
 		log.C(ctx).WithError(err).Error("Could not set write deadline")
 	}
 
@@ -159,12 +257,81 @@ func (c *Controller) sendWsMessage(ctx context.Context, conn *websocket.Conn, ms
 	return true
 }
 
+// notificationsSnapshot reads the last revision and the state in a single transaction, so that the snapshot reflects
+// exactly the notifications up to the returned revision
+func (c *Controller) notificationsSnapshot(ctx context.Context, platform *types.Platform) ([]*types.Notification, int64, error) {
+	var snapshot []*types.Notification
+	revision := types.InvalidRevision
+	if err := c.repository.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+		var err error
+		if revision, err = storage.LastNotificationRevision(ctx, repository); err != nil {
+			return err
+		}
+		snapshot, err = interceptors.NotificationsSnapshot(ctx, repository, platform)
+		return err
+	}, storage.WithIsolationLevel(sql.LevelRepeatableRead), storage.WithReadOnly(), storage.WithRetries()); err != nil {
+		return nil, types.InvalidRevision, err
+	}
+	return snapshot, revision, nil
+}
+
+func (c *Controller) acknowledgedRevision(ctx context.Context, platformID string) (int64, error) {
+	obj, err := c.repository.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platformID))
+	if err != nil {
+		return types.InvalidRevision, util.HandleStorageError(err, types.PlatformType.String())
+	}
+	return obj.(*types.Platform).AcknowledgedNotificationRevision, nil
+}
+
 func (c *Controller) unregisterConsumer(ctx context.Context, q storage.NotificationQueue) {
 	if unregErr := c.notificator.UnregisterConsumer(q); unregErr != nil {
 		log.C(ctx).WithError(unregErr).Errorf("Could not unregister notification consumer")
 	}
 }
 
+// notificationResourceTypes are the types of the resources for which notifications are created
+var notificationResourceTypes = []types.ObjectType{types.ServiceBrokerType, types.VisibilityType}
+
+// parseSubscription builds the subscription of the consumer from the resource types and label query parameters
+func parseSubscription(req *web.Request) (*storage.NotificationSubscription, error) {
+	resourceTypesStr := req.URL.Query().Get(ResourceTypesQueryParam)
+	labelQuery := req.URL.Query().Get(string(query.LabelQuery))
+	if resourceTypesStr == "" && labelQuery == "" {
+		return nil, nil
+	}
+
+	subscription := &storage.NotificationSubscription{}
+	for _, resourceType := range strings.Split(resourceTypesStr, ",") {
+		resourceType = strings.TrimSpace(resourceType)
+		if resourceType == "" {
+			continue
+		}
+		if !isNotificationResourceType(types.ObjectType(resourceType)) {
+			return nil, &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: fmt.Sprintf("%s query parameter contains unknown resource type %s, notifications are sent for %v", ResourceTypesQueryParam, resourceType, notificationResourceTypes),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+		subscription.ResourceTypes = append(subscription.ResourceTypes, types.ObjectType(resourceType))
+	}
+	labelCriteria, err := query.Parse(query.LabelQuery, labelQuery)
+	if err != nil {
+		return nil, err
+	}
+	subscription.LabelCriteria = labelCriteria
+	return subscription, nil
+}
+
+func isNotificationResourceType(resourceType types.ObjectType) bool {
+	for _, notificationResourceType := range notificationResourceTypes {
+		if notificationResourceType == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
 func extractPlatformFromContext(userContext *web.UserContext) (*types.Platform, error) {
 	platform := &types.Platform{}
 	err := userContext.Data(platform)
@@ -191,7 +358,7 @@ func updatePlatformStatus(ctx context.Context, repository storage.TransactionalR
 			RightOp:  []string{platformID},
 			Type:     query.FieldQuery,
 		}
-		obj, err := storage.Get(ctx, types.PlatformType, idCriteria)
+		obj, err := storage.GetForUpdate(ctx, types.PlatformType, idCriteria)
 		if err != nil {
 			return err
 		}
@@ -209,8 +376,39 @@ func updatePlatformStatus(ctx context.Context, repository storage.TransactionalR
 			}
 		}
 		return nil
-	}); err != nil {
+	}, storage.WithRetries()); err != nil {
 		return err
 	}
 	return nil
 }
+
+// acknowledgeNotifications stores the revision acknowledged by the platform unless a later revision is already acknowledged.
+// Revisions after the last notification are rejected, as they would hide the notifications created later.
+func acknowledgeNotifications(ctx context.Context, repository storage.TransactionalRepository, platformID string, revision int64) error {
+	return repository.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+		lastRevision, err := storage.LastNotificationRevision(ctx, repository)
+		if err != nil {
+			return err
+		}
+		if revision > lastRevision {
+			return &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: fmt.Sprintf("acknowledged revision %d is greater than the last notification revision %d", revision, lastRevision),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+
+		obj, err := repository.GetForUpdate(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platformID))
+		if err != nil {
+			return err
+		}
+
+		platform := obj.(*types.Platform)
+		if revision <= platform.AcknowledgedNotificationRevision {
+			return nil
+		}
+		platform.AcknowledgedNotificationRevision = revision
+		_, err = repository.Update(ctx, platform, nil)
+		return err
+	}, storage.WithRetries())
+}
@@ -22,7 +22,7 @@ func (c *Controller) upgrade(ctx context.Context, repository storage.Transaction
 	if header == nil {
 		header = http.Header{}
 	}
-	header.Add(MaxPingPeriodHeader, c.wsSettings.PingTimeout.String())
+	header.Add(MaxPingPeriodHeader, c.currentWSSettings().PingTimeout.String())
 
 	upgrader := &websocket.Upgrader{
 		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
@@ -44,12 +44,12 @@ func (c *Controller) upgrade(ctx context.Context, repository storage.Transaction
 }
 
 func (c *Controller) configureConn(ctx context.Context, repository storage.TransactionalRepository, platform *types.Platform, conn *websocket.Conn) {
-	if err := conn.SetReadDeadline(time.Now().Add(c.wsSettings.PingTimeout)); err != nil {
+	if err := conn.SetReadDeadline(time.Now().Add(c.currentWSSettings().PingTimeout)); err != nil {
 		log.C(ctx).WithError(err).Error("Could not set read deadline")
 	}
 
 	conn.SetPingHandler(func(message string) error {
-		if err := conn.SetReadDeadline(time.Now().Add(c.wsSettings.PingTimeout)); err != nil {
+		if err := conn.SetReadDeadline(time.Now().Add(c.currentWSSettings().PingTimeout)); err != nil {
 			return err
 		}
 
@@ -57,7 +57,7 @@ func (c *Controller) configureConn(ctx context.Context, repository storage.Trans
 			return err
 		}
 
-		err := conn.WriteControl(websocket.PongMessage, []byte(message), time.Now().Add(c.wsSettings.WriteTimeout))
+		err := conn.WriteControl(websocket.PongMessage, []byte(message), time.Now().Add(c.currentWSSettings().WriteTimeout))
 		if err != nil {
 			if storageErr := updatePlatformStatus(ctx, repository, platform.ID, false); storageErr != nil {
 				return storageErr
@@ -95,7 +95,7 @@ func (c *Controller) closeConn(ctx context.Context, cancel context.CancelFunc, c
 
 func (c *Controller) sendClose(ctx context.Context, conn *websocket.Conn, closeCode int) error {
 	message := websocket.FormatCloseMessage(closeCode, "")
-	err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(c.wsSettings.WriteTimeout))
+	err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(c.currentWSSettings().WriteTimeout))
 	if err != nil && err != websocket.ErrCloseSent {
 		log.C(ctx).WithError(err).Error("Could not write websocket close message")
 		return err
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package openapi contains logic for generating the OpenAPI specification of the Service Manager API
+package openapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// Controller serves the OpenAPI document describing the routes of the API
+type Controller struct {
+	api *web.API
+
+	once     sync.Once
+	document *Document
+}
+
+var _ web.Controller = &Controller{}
+
+// NewController returns a controller which describes the controllers registered in the provided API.
+// The document is generated on the first request, so that it includes controllers registered after the controller was created.
+func NewController(api *web.API) *Controller {
+	return &Controller{
+		api: api,
+	}
+}
+
+// Routes returns the routes that serve the OpenAPI document
+func (c *Controller) Routes() []web.Route {
+	return []web.Route{
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.OpenAPIURL,
+			},
+			Handler: c.getDocument,
+		},
+	}
+}
+
+func (c *Controller) getDocument(_ *web.Request) (*web.Response, error) {
+	c.once.Do(func() {
+		c.document = Generate(c.api.Controllers)
+	})
+	return util.NewJSONResponse(http.StatusOK, c.document)
+}
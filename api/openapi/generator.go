@@ -0,0 +1,314 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Peripli/service-manager/api/filters"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const (
+	jsonContentType = "application/json"
+
+	errorSchemaName      = "Error"
+	operationSchemaName  = "Operation"
+	labelsPageSchemaName = "LabelsPage"
+)
+
+var pathParamRegex = regexp.MustCompile(`{([^}:]+)(:[^}]*)?}`)
+
+// Resource describes the resources managed by a controller
+type Resource struct {
+	// BaseURL is the path of the resources collection
+	BaseURL string
+	// Blueprint returns an empty resource
+	Blueprint func() types.Object
+	// SupportsAsync is true if the resources can be modified asynchronously
+	SupportsAsync bool
+}
+
+// ResourceController is implemented by controllers which manage a collection of resources. The routes of such
+// controllers are described using the schema of the managed resource.
+type ResourceController interface {
+	web.Controller
+
+	Resource() Resource
+}
+
+// Generate generates an OpenAPI document describing the routes of the provided controllers
+func Generate(controllers []web.Controller) *Document {
+	g := &generator{
+		document: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:   "Service Manager",
+				Version: "v1",
+			},
+			Paths: map[string]*PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{
+					errorSchemaName:      schemaOf(reflect.TypeOf(util.HTTPError{})),
+					operationSchemaName:  schemaOf(reflect.TypeOf(types.Operation{})),
+					labelsPageSchemaName: labelsPageSchema(),
+				},
+			},
+		},
+	}
+	for _, controller := range controllers {
+		var resource *Resource
+		if resourceController, ok := controller.(ResourceController); ok {
+			r := resourceController.Resource()
+			resource = &r
+			g.addResourceSchemas(resource)
+		}
+		for _, route := range controller.Routes() {
+			g.addRoute(route.Endpoint, resource)
+		}
+	}
+	return g.document
+}
+
+type generator struct {
+	document *Document
+}
+
+func (g *generator) addResourceSchemas(resource *Resource) {
+	name := schemaName(resource)
+	g.document.Components.Schemas[name] = schemaOf(reflect.TypeOf(resource.Blueprint()))
+	g.document.Components.Schemas[name+"Page"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"token":     {Type: "string"},
+			"num_items": {Type: "integer", Format: "int32"},
+			"items":     {Type: "array", Items: schemaRef(name)},
+		},
+	}
+}
+
+func (g *generator) addRoute(endpoint web.Endpoint, resource *Resource) {
+	path, pathParams := parsePath(endpoint.Path)
+	operation := &Operation{
+		Parameters: pathParams,
+		Responses: map[string]*Response{
+			"default": errorResponse(),
+		},
+	}
+
+	if resource != nil && strings.HasPrefix(path, resource.BaseURL) {
+		operation.Tags = []string{schemaName(resource)}
+		describeResourceOperation(operation, endpoint.Method, strings.TrimPrefix(path, resource.BaseURL), resource)
+	}
+	if len(operation.Responses) == 1 {
+		operation.Responses[strconv.Itoa(http.StatusOK)] = &Response{Description: "Successful response"}
+	}
+
+	pathItem, found := g.document.Paths[path]
+	if !found {
+		pathItem = &PathItem{}
+	}
+	if pathItem.setOperation(endpoint.Method, operation) {
+		g.document.Paths[path] = pathItem
+	}
+}
+
+func describeResourceOperation(operation *Operation, method, subPath string, resource *Resource) {
+	name := schemaName(resource)
+	collectionName := collectionName(resource)
+	singlePath := "/{" + web.PathParamResourceID + "}"
+	operationPath := singlePath + web.ResourceOperationsURL + "/{" + web.PathParamID + "}"
+
+	switch {
+	case subPath == "" && method == http.MethodGet:
+		operation.OperationID = "list" + collectionName
+		operation.Parameters = append(operation.Parameters, listParameters()...)
+		operation.Responses[strconv.Itoa(http.StatusOK)] = jsonResponse("List of resources", schemaRef(name+"Page"))
+	case subPath == "" && method == http.MethodPost:
+		operation.OperationID = "create" + name
+		operation.RequestBody = jsonRequestBody(schemaRef(name))
+		operation.Responses[strconv.Itoa(http.StatusCreated)] = jsonResponse("Created resource", schemaRef(name))
+		addAsyncResponse(operation, resource)
+	case subPath == "" && method == http.MethodDelete:
+		operation.OperationID = "delete" + collectionName
+		operation.Parameters = append(operation.Parameters, criteriaParameters()...)
+		operation.Responses[strconv.Itoa(http.StatusOK)] = jsonResponse("Resources deleted", &Schema{Type: "object"})
+	case subPath == singlePath && method == http.MethodGet:
+		operation.OperationID = "get" + name
+		operation.Parameters = append(operation.Parameters, queryParameter(filters.FieldsQueryKey, "comma separated list of the attributes to return"))
+		operation.Responses[strconv.Itoa(http.StatusOK)] = jsonResponse("Requested resource", schemaRef(name))
+	case subPath == singlePath && method == http.MethodPatch:
+		operation.OperationID = "update" + name
+		operation.RequestBody = jsonRequestBody(schemaRef(name))
+		operation.Responses[strconv.Itoa(http.StatusOK)] = jsonResponse("Updated resource", schemaRef(name))
+		addAsyncResponse(operation, resource)
+	case subPath == singlePath && method == http.MethodDelete:
+		operation.OperationID = "delete" + name
+		operation.Responses[strconv.Itoa(http.StatusOK)] = jsonResponse("Resource deleted", &Schema{Type: "object"})
+		addAsyncResponse(operation, resource)
+	case subPath == web.ResourceLabelsURL && method == http.MethodGet:
+		operation.OperationID = "list" + name + "Labels"
+		operation.Parameters = append(operation.Parameters, criteriaParameters()...)
+		operation.Responses[strconv.Itoa(http.StatusOK)] = jsonResponse("Label keys with the number of resources having each label value", schemaRef(labelsPageSchemaName))
+	case subPath == operationPath && method == http.MethodGet:
+		operation.OperationID = "get" + name + "Operation"
+		operation.Responses[strconv.Itoa(http.StatusOK)] = jsonResponse("Requested operation", schemaRef(operationSchemaName))
+	}
+}
+
+func addAsyncResponse(operation *Operation, resource *Resource) {
+	if !resource.SupportsAsync {
+		return
+	}
+	operation.Parameters = append(operation.Parameters, &Parameter{
+		Name:        web.QueryParamAsync,
+		In:          "query",
+		Description: "whether the request should be executed asynchronously",
+		Schema:      &Schema{Type: "boolean"},
+	})
+	operation.Responses[strconv.Itoa(http.StatusAccepted)] = &Response{
+		Description: "Request accepted for asynchronous execution",
+		Headers: map[string]*Header{
+			"Location": {
+				Description: "path of the operation which tracks the execution of the request",
+				Schema:      &Schema{Type: "string"},
+			},
+		},
+	}
+}
+
+func labelsPageSchema() *Schema {
+	labelValue := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"value": {Type: "string"},
+			"count": {Type: "integer", Format: "int32"},
+		},
+	}
+	labelKey := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"key":    {Type: "string"},
+			"values": {Type: "array", Items: labelValue},
+		},
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"num_items": {Type: "integer", Format: "int32"},
+			"items":     {Type: "array", Items: labelKey},
+		},
+	}
+}
+
+func listParameters() []*Parameter {
+	return append(criteriaParameters(),
+		&Parameter{
+			Name:        "max_items",
+			In:          "query",
+			Description: "maximum number of items to return",
+			Schema:      &Schema{Type: "integer", Format: "int32"},
+		},
+		queryParameter("token", "token of the page to return"),
+		queryParameter(query.OrderBy, "comma separated list of ordering rules in the form <field> [asc|desc]"),
+		queryParameter(filters.FieldsQueryKey, "comma separated list of the attributes to return"),
+	)
+}
+
+func criteriaParameters() []*Parameter {
+	return []*Parameter{
+		queryParameter(string(query.FieldQuery), "field query in the form <field> <operator> <value> [and ...]"),
+		queryParameter(string(query.LabelQuery), "label query in the form <label key> <operator> <value> [and ...]"),
+	}
+}
+
+func queryParameter(name, description string) *Parameter {
+	return &Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+func jsonRequestBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			jsonContentType: {Schema: schema},
+		},
+	}
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{
+		Description: description,
+		Content: map[string]*MediaType{
+			jsonContentType: {Schema: schema},
+		},
+	}
+}
+
+func errorResponse() *Response {
+	return jsonResponse("Error", schemaRef(errorSchemaName))
+}
+
+func schemaRef(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func schemaName(resource *Resource) string {
+	t := reflect.TypeOf(resource.Blueprint())
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func collectionName(resource *Resource) string {
+	segments := strings.Split(resource.BaseURL, "/")
+	words := strings.Split(segments[len(segments)-1], "_")
+	for i, word := range words {
+		if len(word) > 0 {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// parsePath converts the path of a route to an OpenAPI path and returns the parameters of the path
+func parsePath(routePath string) (string, []*Parameter) {
+	var params []*Parameter
+	path := pathParamRegex.ReplaceAllStringFunc(routePath, func(param string) string {
+		name := pathParamRegex.FindStringSubmatch(param)[1]
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+		return "{" + name + "}"
+	})
+	return path, params
+}
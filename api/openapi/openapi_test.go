@@ -0,0 +1,176 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package openapi_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Peripli/service-manager/api/openapi"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OpenAPI Suite")
+}
+
+type platformController struct {
+	async bool
+}
+
+func (c *platformController) Resource() openapi.Resource {
+	return openapi.Resource{
+		BaseURL: web.PlatformsURL,
+		Blueprint: func() types.Object {
+			return &types.Platform{}
+		},
+		SupportsAsync: c.async,
+	}
+}
+
+func (c *platformController) Routes() []web.Route {
+	singlePath := fmt.Sprintf("%s/{%s}", web.PlatformsURL, web.PathParamResourceID)
+	return []web.Route{
+		{Endpoint: web.Endpoint{Method: http.MethodGet, Path: web.PlatformsURL}},
+		{Endpoint: web.Endpoint{Method: http.MethodPost, Path: web.PlatformsURL}},
+		{Endpoint: web.Endpoint{Method: http.MethodGet, Path: singlePath}},
+		{Endpoint: web.Endpoint{Method: http.MethodPatch, Path: singlePath}},
+	}
+}
+
+type pingController struct{}
+
+func (c *pingController) Routes() []web.Route {
+	return []web.Route{
+		{Endpoint: web.Endpoint{Method: http.MethodGet, Path: "/v1/ping/{name:.*}"}},
+	}
+}
+
+var _ = Describe("OpenAPI", func() {
+	var controllers []web.Controller
+
+	generate := func() map[string]interface{} {
+		bytes, err := json.Marshal(openapi.Generate(controllers))
+		Expect(err).ToNot(HaveOccurred())
+		document := map[string]interface{}{}
+		Expect(json.Unmarshal(bytes, &document)).To(Succeed())
+		return document
+	}
+
+	path := func(document map[string]interface{}, keys ...string) interface{} {
+		var current interface{} = document
+		for _, key := range keys {
+			Expect(current).To(HaveKey(key))
+			current = current.(map[string]interface{})[key]
+		}
+		return current
+	}
+
+	BeforeEach(func() {
+		controllers = []web.Controller{&platformController{}, &pingController{}}
+	})
+
+	It("describes the document", func() {
+		document := generate()
+		Expect(document["openapi"]).To(Equal(openapi.Version))
+		Expect(path(document, "info", "title")).To(Equal("Service Manager"))
+	})
+
+	It("describes the resource schemas", func() {
+		document := generate()
+		properties := path(document, "components", "schemas", "Platform", "properties")
+		Expect(properties).To(HaveKey("id"))
+		Expect(properties).To(HaveKey("labels"))
+		Expect(path(document, "components", "schemas", "Platform", "properties", "created_at", "format")).To(Equal("date-time"))
+		Expect(path(document, "components", "schemas", "PlatformPage", "properties", "items", "items", "$ref")).To(Equal("#/components/schemas/Platform"))
+	})
+
+	It("describes the error model", func() {
+		document := generate()
+		Expect(path(document, "components", "schemas", "Error", "properties")).To(HaveKey("error"))
+		Expect(path(document, "components", "schemas", "Error", "properties")).To(HaveKey("description"))
+		Expect(path(document, "paths", web.PlatformsURL, "get", "responses", "default", "content", "application/json", "schema", "$ref")).
+			To(Equal("#/components/schemas/Error"))
+	})
+
+	It("describes the query parameters of list operations", func() {
+		document := generate()
+		parameters := path(document, "paths", web.PlatformsURL, "get", "parameters").([]interface{})
+		var names []interface{}
+		for _, parameter := range parameters {
+			names = append(names, parameter.(map[string]interface{})["name"])
+		}
+		Expect(names).To(ContainElement("fieldQuery"))
+		Expect(names).To(ContainElement("labelQuery"))
+		Expect(names).To(ContainElement("max_items"))
+		Expect(names).To(ContainElement("token"))
+	})
+
+	It("describes the path parameters", func() {
+		document := generate()
+		parameters := path(document, "paths", web.PlatformsURL+"/{resource_id}", "get", "parameters").([]interface{})
+		Expect(parameters[0]).To(HaveKeyWithValue("name", "resource_id"))
+		Expect(parameters[0]).To(HaveKeyWithValue("in", "path"))
+
+		Expect(document["paths"]).To(HaveKey("/v1/ping/{name}"))
+	})
+
+	Context("when the resource does not support async requests", func() {
+		It("does not describe async responses", func() {
+			document := generate()
+			Expect(path(document, "paths", web.PlatformsURL, "post", "responses")).To(HaveKey("201"))
+			Expect(path(document, "paths", web.PlatformsURL, "post", "responses")).ToNot(HaveKey("202"))
+		})
+	})
+
+	Context("when the resource supports async requests", func() {
+		BeforeEach(func() {
+			controllers = []web.Controller{&platformController{async: true}}
+		})
+
+		It("describes the Location header of async responses", func() {
+			document := generate()
+			Expect(path(document, "paths", web.PlatformsURL, "post", "responses", "202", "headers")).To(HaveKey("Location"))
+			Expect(path(document, "paths", web.PlatformsURL+"/{resource_id}", "patch", "responses", "202", "headers")).To(HaveKey("Location"))
+		})
+	})
+
+	Context("controller", func() {
+		It("serves the document of the registered controllers", func() {
+			api := &web.API{Controllers: controllers}
+			controller := openapi.NewController(api)
+			api.RegisterControllers(controller)
+
+			routes := controller.Routes()
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Endpoint.Path).To(Equal(web.OpenAPIURL))
+
+			resp, err := routes[0].Handler(&web.Request{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			document := map[string]interface{}{}
+			Expect(json.Unmarshal(resp.Body, &document)).To(Succeed())
+			Expect(document["paths"]).To(HaveKey(web.OpenAPIURL))
+		})
+	})
+})
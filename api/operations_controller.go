@@ -31,6 +31,13 @@ func (c *OperationsController) Routes() []web.Route {
 			},
 			Handler: c.ListObjects,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.OperationsURL + web.ResourceLabelsURL,
+			},
+			Handler: c.ListLabels,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodDelete,
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+const pagingSequenceField = "paging_sequence"
+
+// keysetPageToken is the page token used when the result is ordered by client specified fields. It holds the values
+// of the order by fields and the paging sequence of the last item of the previous page.
+type keysetPageToken struct {
+	OrderBy []string `json:"order_by"`
+	Values  []string `json:"values"`
+}
+
+// orderCriteria returns the order by criteria requested by the client
+func orderCriteria(criteria []query.Criterion) []query.Criterion {
+	result := make([]query.Criterion, 0)
+	for _, criterion := range criteria {
+		if criterion.Type == query.ResultQuery && criterion.LeftOp == query.OrderBy {
+			result = append(result, criterion)
+		}
+	}
+	return result
+}
+
+// validateOrderFields verifies that the objects can be ordered by the requested fields. Whether the fields are
+// columns of the entity is verified by the storage.
+func validateOrderFields(obj types.Object, orderBy []query.Criterion) error {
+	for _, criterion := range orderBy {
+		if _, ok := sortableFieldValue(obj, criterion.RightOp[0]); !ok {
+			return &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field for order by: %s", criterion.RightOp[0])}
+		}
+	}
+	return nil
+}
+
+// parseKeysetPageToken returns the values of the order by fields and the paging sequence after which the page starts
+func parseKeysetPageToken(ctx context.Context, token string, orderBy []query.Criterion) ([]string, error) {
+	invalidTokenErr := &util.HTTPError{
+		ErrorType:   "TokenInvalid",
+		Description: "Invalid token provided.",
+		StatusCode:  http.StatusBadRequest,
+	}
+
+	tokenBytes, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		log.C(ctx).Infof("Invalid token provided: %v", err)
+		return nil, invalidTokenErr
+	}
+	pageToken := &keysetPageToken{}
+	if err := json.Unmarshal(tokenBytes, pageToken); err != nil {
+		log.C(ctx).Infof("Invalid token provided: %v", err)
+		return nil, invalidTokenErr
+	}
+	if !reflect.DeepEqual(pageToken.OrderBy, orderRules(orderBy)) || len(pageToken.Values) != len(orderBy)+1 {
+		log.C(ctx).Infof("Invalid token provided: token was issued for a different order")
+		return nil, invalidTokenErr
+	}
+	return pageToken.Values, nil
+}
+
+func generateKeysetTokenForItem(obj types.Object, orderBy []query.Criterion) string {
+	values := make([]string, 0, len(orderBy)+1)
+	for _, criterion := range orderBy {
+		value, _ := sortableFieldValue(obj, criterion.RightOp[0])
+		values = append(values, value)
+	}
+	values = append(values, strconv.FormatInt(obj.GetPagingSequence(), 10))
+
+	tokenBytes, _ := json.Marshal(&keysetPageToken{
+		OrderBy: orderRules(orderBy),
+		Values:  values,
+	})
+	return base64.StdEncoding.EncodeToString(tokenBytes)
+}
+
+func orderRules(orderBy []query.Criterion) []string {
+	rules := make([]string, 0, len(orderBy))
+	for _, criterion := range orderBy {
+		rules = append(rules, strings.Join(criterion.RightOp, " "))
+	}
+	return rules
+}
+
+// sortableFieldValue returns the value of the scalar field with the specified json name. Nil values are returned as empty strings.
+func sortableFieldValue(obj interface{}, field string) (string, bool) {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return "", false
+	}
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			if fieldValue, ok := sortableFieldValue(value.Field(i).Interface(), field); ok {
+				return fieldValue, true
+			}
+			continue
+		}
+		if strings.Split(structField.Tag.Get("json"), ",")[0] == field {
+			return formatSortableValue(value.Field(i))
+		}
+	}
+	return "", false
+}
+
+func formatSortableValue(value reflect.Value) (string, bool) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			_, ok := formatSortableValue(reflect.Zero(value.Type().Elem()))
+			return "", ok
+		}
+		value = value.Elem()
+	}
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano), true
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
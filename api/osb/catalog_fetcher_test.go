@@ -128,6 +128,45 @@ var _ = Describe("Catalog CatalogFetcher", func() {
 		},
 	}
 
+	testBrokerCA := types.ServiceBroker{
+		Base: types.Base{
+			ID:     id,
+			Labels: map[string][]string{},
+			Ready:  true,
+		},
+		Name:      name,
+		BrokerURL: url,
+		Credentials: &types.Credentials{
+			Basic: &types.Basic{
+				Username: username,
+				Password: password,
+			},
+			TLS: &types.TLS{
+				CACertificate: cert,
+				ServerName:    "broker.example.com",
+			},
+		},
+	}
+
+	testBrokerCAInvalid := types.ServiceBroker{
+		Base: types.Base{
+			ID:     id,
+			Labels: map[string][]string{},
+			Ready:  true,
+		},
+		Name:      name,
+		BrokerURL: url,
+		Credentials: &types.Credentials{
+			Basic: &types.Basic{
+				Username: username,
+				Password: password,
+			},
+			TLS: &types.TLS{
+				CACertificate: "ca",
+			},
+		},
+	}
+
 	type testCase struct {
 		expectations     *common.HTTPExpectations
 		reaction         *common.HTTPReaction
@@ -224,6 +263,34 @@ var _ = Describe("Catalog CatalogFetcher", func() {
 			},
 			expectedErr: nil,
 		}),
+		Entry("returns error if invalid ca certificate is passed", testCase{
+			broker: testBrokerCAInvalid,
+			expectations: &common.HTTPExpectations{
+				URL:     url,
+				Headers: expectedHeaders,
+			},
+			reaction: &common.HTTPReaction{
+				Status: http.StatusOK,
+			},
+			expectedErr: &util.HTTPError{
+				ErrorType:   "ServiceBrokerErr",
+				Description: fmt.Sprintf("failed to find any PEM certificates in ca_certificate input"),
+				StatusCode:  http.StatusBadGateway,
+			},
+		}),
+		Entry("successfully fetches the catalog if ca certificate and server name are passed", testCase{
+			broker: testBrokerCA,
+			expectations: &common.HTTPExpectations{
+				URL:     url,
+				Headers: expectedHeaders,
+			},
+			reaction: &common.HTTPReaction{
+				Status: http.StatusOK,
+				Body:   simpleCatalog,
+			},
+			expectedErr:      nil,
+			expectedResponse: []byte(simpleCatalog),
+		}),
 	}
 
 	DescribeTable("Fetch", func(t testCase) {
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// PlatformController implements api.Controller by providing platforms API logic
+type PlatformController struct {
+	*BaseController
+
+	transactionalRepository storage.TransactionalRepository
+	notificationsLags       storage.NotificationsLagStore
+	credentialsGracePeriod  time.Duration
+}
+
+// NewPlatformController returns a new controller for the platforms API
+func NewPlatformController(ctx context.Context, options *Options) *PlatformController {
+	return &PlatformController{
+		BaseController: NewController(ctx, options, web.PlatformsURL, types.PlatformType, func() types.Object {
+			return &types.Platform{}
+		}),
+		transactionalRepository: options.Repository,
+		notificationsLags:       options.NotificationsLagStore,
+		credentialsGracePeriod:  options.APISettings.PlatformCredentialsGracePeriod,
+	}
+}
+
+// Routes returns the platform routes together with the routes managing the platform credentials
+func (c *PlatformController) Routes() []web.Route {
+	routes := c.BaseController.Routes()
+	for i, route := range routes {
+		if route.Endpoint.Method == http.MethodGet &&
+			(route.Endpoint.Path == c.resourceBaseURL || route.Endpoint.Path == fmt.Sprintf("%s/{%s}", c.resourceBaseURL, web.PathParamResourceID)) {
+			routes[i].Handler = c.withNotificationsLag(route.Handler)
+		}
+	}
+
+	credentialsURL := fmt.Sprintf("%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.PlatformCredentialsURL)
+	return append(routes,
+		web.Route{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   credentialsURL,
+			},
+			Handler: c.getCredentialsStatus,
+		},
+		web.Route{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   credentialsURL + "/rotate",
+			},
+			Handler: c.rotateCredentials,
+		},
+		web.Route{
+			Endpoint: web.Endpoint{
+				Method: http.MethodDelete,
+				Path:   credentialsURL + "/old",
+			},
+			Handler: c.revokeOldCredentials,
+		},
+	)
+}
+
+// getCredentialsStatus returns when the platform credentials were last used and until when the credentials
+// replaced by the last rotation remain valid
+func (c *PlatformController) getCredentialsStatus(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	criteria, err := c.platformCriteria(r)
+	if err != nil {
+		return nil, err
+	}
+	platform, err := c.repository.Get(ctx, types.PlatformType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.PlatformType.String())
+	}
+	return util.NewJSONResponse(http.StatusOK, platform.(*types.Platform).CredentialsStatus())
+}
+
+// rotateCredentials generates new credentials for the platform. The current credentials remain valid for the
+// configured grace period, so that the platform can switch to the new credentials without interruption.
+func (c *PlatformController) rotateCredentials(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	criteria, err := c.platformCriteria(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var status *types.PlatformCredentialsStatus
+	if err := c.transactionalRepository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
+		obj, err := storage.GetForUpdate(ctx, types.PlatformType, criteria...)
+		if err != nil {
+			return util.HandleStorageError(err, types.PlatformType.String())
+		}
+		platform := obj.(*types.Platform)
+		// a rotation would invalidate the old credentials which may still be in use, so they have to be revoked first
+		if platform.HasValidOldCredentials() {
+			return &util.HTTPError{
+				ErrorType:   "Conflict",
+				Description: fmt.Sprintf("old credentials of platform %s are still valid until %s and have to be revoked before the next rotation", platform.ID, platform.OldCredentialsExpireAt.Format(time.RFC3339)),
+				StatusCode:  http.StatusConflict,
+			}
+		}
+
+		credentials, err := types.GenerateCredentials()
+		if err != nil {
+			return fmt.Errorf("could not generate credentials for platform %s: %s", platform.ID, err)
+		}
+		platform.OldCredentials = platform.Credentials
+		platform.OldCredentialsExpireAt = time.Now().Add(c.credentialsGracePeriod)
+		platform.OldCredentialsLastUsedAt = platform.CredentialsLastUsedAt
+		platform.Credentials = credentials
+		platform.CredentialsLastUsedAt = time.Time{}
+
+		// the platform is encrypted in place during the update, so the new credentials are taken from the result
+		updatedObj, err := storage.Update(ctx, platform, types.LabelChanges{})
+		if err != nil {
+			return util.HandleStorageError(err, types.PlatformType.String())
+		}
+		updatedPlatform := updatedObj.(*types.Platform)
+		status = updatedPlatform.CredentialsStatus()
+		status.Credentials = updatedPlatform.Credentials
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	log.C(ctx).Infof("Rotated credentials of platform %s, old credentials expire at %v", r.PathParams[web.PathParamResourceID], status.OldCredentialsExpireAt)
+	return util.NewJSONResponse(http.StatusOK, status)
+}
+
+// revokeOldCredentials revokes the credentials replaced by the last rotation before their grace period expires
+func (c *PlatformController) revokeOldCredentials(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	criteria, err := c.platformCriteria(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.transactionalRepository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
+		obj, err := storage.GetForUpdate(ctx, types.PlatformType, criteria...)
+		if err != nil {
+			return util.HandleStorageError(err, types.PlatformType.String())
+		}
+		platform := obj.(*types.Platform)
+		if platform.OldCredentials == nil {
+			return nil
+		}
+
+		platform.OldCredentials = nil
+		platform.OldCredentialsExpireAt = time.Time{}
+		platform.OldCredentialsLastUsedAt = time.Time{}
+		if _, err := storage.Update(ctx, platform, types.LabelChanges{}); err != nil {
+			return util.HandleStorageError(err, types.PlatformType.String())
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	log.C(ctx).Infof("Revoked old credentials of platform %s", r.PathParams[web.PathParamResourceID])
+	return util.NewJSONResponse(http.StatusOK, map[string]string{})
+}
+
+// withNotificationsLag adds the notifications lag of the platforms which acknowledge notifications to the response
+func (c *PlatformController) withNotificationsLag(handler web.HandlerFunc) web.HandlerFunc {
+	return func(r *web.Request) (*web.Response, error) {
+		resp, err := handler(r)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return resp, err
+		}
+
+		body := gjson.ParseBytes(resp.Body)
+		paths := make(map[string]string)
+		if items := body.Get("items"); items.IsArray() {
+			for i, item := range items.Array() {
+				if id := item.Get("id").String(); id != "" {
+					paths[id] = fmt.Sprintf("items.%d.notifications_lag", i)
+				}
+			}
+		} else if id := body.Get("id").String(); id != "" {
+			paths[id] = "notifications_lag"
+		}
+		if len(paths) == 0 {
+			return resp, nil
+		}
+
+		lags, err := c.notificationsLags.GetNotificationsLags(r.Context())
+		if err != nil {
+			return nil, util.HandleStorageError(err, types.NotificationType.String())
+		}
+		for id, path := range paths {
+			lag, found := lags[id]
+			if !found {
+				continue
+			}
+			if resp.Body, err = sjson.SetBytes(resp.Body, path, lag); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
+	}
+}
+
+// platformCriteria returns the criteria selecting the requested platform together with the criteria added by the filters
+func (c *PlatformController) platformCriteria(r *web.Request) ([]query.Criterion, error) {
+	byID := query.ByField(query.EqualsOperator, "id", r.PathParams[web.PathParamResourceID])
+	ctx, err := query.AddCriteria(r.Context(), byID)
+	if err != nil {
+		return nil, err
+	}
+	return query.CriteriaForContext(ctx), nil
+}
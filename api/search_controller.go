@@ -0,0 +1,315 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	searchTextQueryKey  = "q"
+	searchTypesQueryKey = "types"
+)
+
+// searchController implements web.Controller by providing a full-text search across the searchable resources
+type searchController struct {
+	api        *web.API
+	repository storage.Repository
+
+	pageSizesMutex  sync.RWMutex
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// NewSearchController returns a controller which searches the resources. The resources of each type are restricted
+// by the filters of the list endpoint of the type, which are taken from the API.
+func NewSearchController(options *Options, api *web.API) web.Controller {
+	return &searchController{
+		api:             api,
+		repository:      options.Repository,
+		defaultPageSize: options.APISettings.DefaultPageSize,
+		maxPageSize:     options.APISettings.MaxPageSize,
+	}
+}
+
+// SetPageSizes changes the default and the maximum number of resources returned in a single page
+func (c *searchController) SetPageSizes(defaultPageSize, maxPageSize int) {
+	c.pageSizesMutex.Lock()
+	defer c.pageSizesMutex.Unlock()
+	c.defaultPageSize = defaultPageSize
+	c.maxPageSize = maxPageSize
+}
+
+// Routes provides the endpoint for the search
+func (c *searchController) Routes() []web.Route {
+	return []web.Route{
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.SearchURL,
+			},
+			Handler: c.search,
+		},
+	}
+}
+
+// searchResult is a resource matching the search together with the rank of the match
+type searchResult struct {
+	Type     types.ObjectType `json:"type"`
+	Rank     float64          `json:"rank"`
+	Resource types.Object     `json:"resource"`
+}
+
+// searchPage is a page of the search results ordered by rank
+type searchPage struct {
+	Token string          `json:"token,omitempty"`
+	Items []*searchResult `json:"items"`
+}
+
+// typedSearchMatch is a search match of a resource of particular type
+type typedSearchMatch struct {
+	*types.SearchMatch
+	objectType types.ObjectType
+}
+
+func (c *searchController) search(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+
+	text := strings.TrimSpace(r.URL.Query().Get(searchTextQueryKey))
+	if text == "" {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("query parameter %s is required", searchTextQueryKey),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	objectTypes, explicitTypes, err := parseSearchTypes(r.URL.Query().Get(searchTypesQueryKey))
+	if err != nil {
+		return nil, err
+	}
+	c.pageSizesMutex.RLock()
+	limit, err := parseMaxItems(r.URL.Query().Get("max_items"), c.defaultPageSize, c.maxPageSize)
+	c.pageSizesMutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	offset, err := parseSearchPageToken(ctx, r.URL.Query().Get("token"))
+	if err != nil {
+		return nil, err
+	}
+
+	// the page is taken from the matches of all types, so each type contributes at most as many matches as the page ends with
+	matches := make([]*typedSearchMatch, 0)
+	for _, objectType := range objectTypes {
+		criteria, listed, err := scopeCriteria(c.api, r, objectType)
+		if err != nil {
+			if explicitTypes {
+				return nil, err
+			}
+			log.C(ctx).Debugf("Skipping %s in search as they cannot be listed: %s", objectType, err)
+			continue
+		}
+		if !listed {
+			log.C(ctx).Debugf("Skipping %s in search as none of them is visible", objectType)
+			continue
+		}
+
+		criteria = append(criteria, query.LimitResultBy(offset+limit+pagingLimitOffset))
+		typeMatches, err := c.repository.Search(ctx, objectType, text, criteria...)
+		if err != nil {
+			return nil, util.HandleStorageError(err, objectType.String())
+		}
+		for _, match := range typeMatches {
+			matches = append(matches, &typedSearchMatch{SearchMatch: match, objectType: objectType})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Rank != matches[j].Rank {
+			return matches[i].Rank > matches[j].Rank
+		}
+		if matches[i].objectType != matches[j].objectType {
+			return matches[i].objectType < matches[j].objectType
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	page := &searchPage{Items: make([]*searchResult, 0)}
+	if limit > 0 && len(matches) > offset+limit {
+		page.Token = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset + limit)))
+		matches = matches[:offset+limit]
+	}
+	if len(matches) > offset {
+		if page.Items, err = c.searchResults(ctx, matches[offset:]); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := util.NewJSONResponse(http.StatusOK, page)
+	if err != nil {
+		return nil, err
+	}
+	if page.Token != "" {
+		nextPageUrl := r.URL
+		q := nextPageUrl.Query()
+		q.Set("token", page.Token)
+		nextPageUrl.RawQuery = q.Encode()
+		resp.Header.Add("Link", fmt.Sprintf(`<%s>; rel="next"`, nextPageUrl))
+	}
+	return resp, nil
+}
+
+// scopeCriteria returns the criteria which restrict the resources of the type to the ones that the user can list. They
+// are collected by running the filters of the list endpoint of the type in the API, so the request is authorized for
+// the type and the tenant and visibility restrictions of the type are applied. If the filters respond without reaching
+// the listing, none of the resources is visible.
+func scopeCriteria(api *web.API, r *web.Request, objectType types.ObjectType) ([]query.Criterion, bool, error) {
+	ctx := web.ContextWithoutAuthorization(r.Context())
+	if user, found := web.UserFromContext(ctx); found {
+		// the authorization of the list request sets the access level of the user for the type
+		typeUser := *user
+		ctx = web.ContextWithUser(ctx, &typeUser)
+	}
+	ctx, err := query.ContextWithCriteria(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	listURL := *r.URL
+	listURL.Path = objectType.String()
+	listURL.RawQuery = ""
+	listRequest := &web.Request{
+		Request:    r.WithContext(ctx),
+		PathParams: map[string]string{},
+	}
+	listRequest.URL = &listURL
+
+	var criteria []query.Criterion
+	listed := false
+	lister := web.HandlerFunc(func(req *web.Request) (*web.Response, error) {
+		criteria = query.CriteriaForContext(req.Context())
+		listed = true
+		return &web.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+	})
+	endpoint := web.Endpoint{
+		Method: http.MethodGet,
+		Path:   objectType.String(),
+	}
+	if _, err := web.Filters(api.Filters).Matching(endpoint).Chain(lister).Handle(listRequest); err != nil {
+		return nil, false, err
+	}
+	return criteria, listed, nil
+}
+
+// searchResults fetches the resources of the matches in the order of the matches. Resources which have been deleted
+// since they matched are omitted.
+func (c *searchController) searchResults(ctx context.Context, matches []*typedSearchMatch) ([]*searchResult, error) {
+	idsByType := make(map[types.ObjectType][]string)
+	for _, match := range matches {
+		idsByType[match.objectType] = append(idsByType[match.objectType], match.ID)
+	}
+	objects := make(map[types.ObjectType]map[string]types.Object)
+	for objectType, ids := range idsByType {
+		objectList, err := c.repository.List(ctx, objectType, query.ByField(query.InOperator, "id", ids...))
+		if err != nil {
+			return nil, util.HandleStorageError(err, objectType.String())
+		}
+		objects[objectType] = make(map[string]types.Object, objectList.Len())
+		for i := 0; i < objectList.Len(); i++ {
+			obj := objectList.ItemAt(i)
+			cleanObject(obj)
+			objects[objectType][obj.GetID()] = obj
+		}
+	}
+
+	results := make([]*searchResult, 0, len(matches))
+	for _, match := range matches {
+		if obj, found := objects[match.objectType][match.ID]; found {
+			results = append(results, &searchResult{
+				Type:     match.objectType,
+				Rank:     match.Rank,
+				Resource: obj,
+			})
+		}
+	}
+	return results, nil
+}
+
+// parseSearchTypes returns the searched types specified by the names of their resources, or all searchable types if none
+// is specified
+func parseSearchTypes(typeNames string) ([]types.ObjectType, bool, error) {
+	if typeNames == "" {
+		return types.SearchableTypes, false, nil
+	}
+	result := make([]types.ObjectType, 0)
+	for _, typeName := range strings.Split(typeNames, ",") {
+		typeName = strings.TrimSpace(typeName)
+		found := false
+		for _, objectType := range types.SearchableTypes {
+			if path.Base(objectType.String()) == typeName {
+				result = append(result, objectType)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false, &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: fmt.Sprintf("resources of type %s cannot be searched", typeName),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+	}
+	return result, true, nil
+}
+
+// parseSearchPageToken returns the number of search results preceding the page
+func parseSearchPageToken(ctx context.Context, token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	invalidTokenErr := &util.HTTPError{
+		ErrorType:   "TokenInvalid",
+		Description: "Invalid token provided.",
+		StatusCode:  http.StatusBadRequest,
+	}
+	tokenBytes, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		log.C(ctx).Infof("Invalid token provided: %v", err)
+		return 0, invalidTokenErr
+	}
+	offset, err := strconv.Atoi(string(tokenBytes))
+	if err != nil || offset < 0 {
+		log.C(ctx).Infof("Invalid token provided: %s", tokenBytes)
+		return 0, invalidTokenErr
+	}
+	return offset, nil
+}
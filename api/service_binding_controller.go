@@ -47,6 +47,13 @@ func (c *ServiceBindingController) Routes() []web.Route {
 			},
 			Handler: c.CreateObject,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   c.resourceBaseURL + web.ResourceLabelsURL,
+			},
+			Handler: c.ListLabels,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
@@ -39,6 +39,13 @@ func NewServiceOfferingController(ctx context.Context, options *Options) *Servic
 }
 func (c *ServiceOfferingController) Routes() []web.Route {
 	return []web.Route{
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.ServiceOfferingsURL + web.ResourceLabelsURL,
+			},
+			Handler: c.ListLabels,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
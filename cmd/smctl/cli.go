@@ -0,0 +1,470 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+
+	"github.com/Peripli/service-manager/pkg/smclient"
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+
+	envPrefix = "SM_"
+)
+
+const usage = `smctl is a command-line tool for administrating the Service Manager.
+
+Usage:
+  smctl [flags] list <resource>
+  smctl [flags] get <resource> <id>
+  smctl [flags] create <resource> (-f <file> | -d <data>)
+  smctl [flags] update <resource> <id> (-f <file> | -d <data>)
+  smctl [flags] delete <resource> <id>
+  smctl [flags] label <resource> <id> <add|add_values|remove|remove_values> <key> [values...]
+  smctl [flags] credentials platform <id> <status|rotate|revoke-old>
+
+Resources:
+  %s
+
+The connection flags can also be provided as environment variables with prefix %s, for example %sURL.
+Bodies of create and update requests are read as JSON or YAML from a file, from stdin if the file is "-", or inline.
+
+Flags:
+%s`
+
+var errUsage = errors.New("invalid usage")
+
+// cli executes a single smctl command
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	flags    *pflag.FlagSet
+	settings *smclient.Settings
+	client   *smclient.Client
+
+	output     string
+	fieldQuery string
+	labelQuery string
+	orderBy    string
+	fields     string
+	expand     string
+	file       string
+	data       string
+	async      bool
+	noWait     bool
+}
+
+func newCLI(stdin io.Reader, stdout, stderr io.Writer) *cli {
+	c := &cli{
+		stdin:    stdin,
+		stdout:   stdout,
+		stderr:   stderr,
+		settings: smclient.DefaultSettings(),
+	}
+
+	flags := pflag.NewFlagSet("smctl", pflag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.StringVar(&c.settings.URL, "url", "", "address of the Service Manager")
+	flags.StringVarP(&c.settings.User, "user", "u", "", "user for basic authentication or the OAuth2 password grant")
+	flags.StringVarP(&c.settings.Password, "password", "p", "", "password for basic authentication or the OAuth2 password grant")
+	flags.StringVar(&c.settings.ClientID, "client-id", "", "OAuth2 client id")
+	flags.StringVar(&c.settings.ClientSecret, "client-secret", "", "OAuth2 client secret")
+	flags.StringVar(&c.settings.TokenURL, "token-url", "", "OAuth2 token endpoint, discovered from the Service Manager if not set")
+	flags.StringVar(&c.settings.Token, "token", "", "bearer token to authenticate with")
+	flags.BoolVar(&c.settings.SkipSSLValidation, "skip-ssl-validation", false, "skip the validation of the server certificates")
+	flags.DurationVar(&c.settings.Timeout, "timeout", c.settings.Timeout, "timeout of the requests to the Service Manager")
+	flags.DurationVar(&c.settings.PollInterval, "poll-interval", c.settings.PollInterval, "interval in which asynchronous operations are polled")
+	flags.StringVarP(&c.output, "output", "o", outputTable, "output format: "+strings.Join(outputFormats, ", "))
+	flags.StringVar(&c.fieldQuery, "field-query", "", "field query of list requests, for example \"name eq 'my-broker'\"")
+	flags.StringVar(&c.labelQuery, "label-query", "", "label query of list requests, for example \"env in ('dev','test')\"")
+	flags.StringVar(&c.orderBy, "order-by", "", "order of list results, for example \"created_at desc\"")
+	flags.StringVar(&c.fields, "fields", "", "comma separated attributes of the returned resources")
+	flags.StringVar(&c.expand, "expand", "", "comma separated related resources to embed in the returned resources")
+	flags.StringVarP(&c.file, "file", "f", "", "file containing the request body, - for stdin")
+	flags.StringVarP(&c.data, "data", "d", "", "request body")
+	flags.BoolVar(&c.async, "async", false, "request asynchronous execution of the modification")
+	flags.BoolVar(&c.noWait, "no-wait", false, "do not wait for asynchronous operations to complete")
+	c.flags = flags
+	return c
+}
+
+// run executes the command specified by the arguments and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCLI(stdin, stdout, stderr)
+	if err := c.execute(ctx, args); err != nil {
+		if err == errUsage || err == pflag.ErrHelp {
+			fmt.Fprintf(stderr, usage, strings.Join(resourceNames(), ", "), envPrefix, envPrefix, c.flags.FlagUsages())
+			if err == pflag.ErrHelp {
+				return exitOK
+			}
+			return exitUsage
+		}
+		fmt.Fprintf(stderr, "Error: %s\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func (c *cli) execute(ctx context.Context, args []string) error {
+	if err := c.flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Error: %s\n", err)
+		return errUsage
+	}
+	c.applyEnvironment()
+
+	args = c.flags.Args()
+	if len(args) < 2 {
+		return errUsage
+	}
+	if err := validateOutputFormat(c.output); err != nil {
+		return err
+	}
+	command := args[0]
+	r, err := findResource(args[1])
+	if err != nil {
+		return err
+	}
+	args = args[2:]
+
+	client, err := smclient.NewClient(ctx, c.settings)
+	if err != nil {
+		return err
+	}
+	c.client = client
+
+	switch command {
+	case "list":
+		return c.list(ctx, r, args)
+	case "get":
+		return c.get(ctx, r, args)
+	case "create":
+		return c.create(ctx, r, args)
+	case "update":
+		return c.update(ctx, r, args)
+	case "delete":
+		return c.delete(ctx, r, args)
+	case "label":
+		return c.label(ctx, r, args)
+	case "credentials":
+		return c.credentials(ctx, r, args)
+	default:
+		fmt.Fprintf(c.stderr, "Error: unknown command %q\n", command)
+		return errUsage
+	}
+}
+
+// applyEnvironment sets the connection flags which are not provided on the command line from the environment
+func (c *cli) applyEnvironment() {
+	for _, name := range []string{"url", "user", "password", "client-id", "client-secret", "token-url", "token", "skip-ssl-validation"} {
+		if c.flags.Changed(name) {
+			continue
+		}
+		envName := envPrefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+		if value, ok := os.LookupEnv(envName); ok {
+			// the values are of the flag types, so setting them cannot fail for valid values
+			if err := c.flags.Set(name, value); err != nil {
+				fmt.Fprintf(c.stderr, "Warning: ignoring invalid value of %s: %s\n", envName, err)
+			}
+		}
+	}
+}
+
+func (c *cli) queryOptions() []smclient.Option {
+	var opts []smclient.Option
+	if len(c.fieldQuery) != 0 {
+		opts = append(opts, smclient.WithParam("fieldQuery", c.fieldQuery))
+	}
+	if len(c.labelQuery) != 0 {
+		opts = append(opts, smclient.WithParam("labelQuery", c.labelQuery))
+	}
+	if len(c.orderBy) != 0 {
+		opts = append(opts, smclient.WithParam("orderBy", c.orderBy))
+	}
+	return append(opts, c.getOptions()...)
+}
+
+func (c *cli) getOptions() []smclient.Option {
+	var opts []smclient.Option
+	if len(c.fields) != 0 {
+		opts = append(opts, smclient.WithFields(c.fields))
+	}
+	if len(c.expand) != 0 {
+		opts = append(opts, smclient.WithExpand(c.expand))
+	}
+	return opts
+}
+
+func (c *cli) modificationOptions() []smclient.Option {
+	if c.flags.Changed("async") {
+		return []smclient.Option{smclient.WithAsync(c.async)}
+	}
+	return nil
+}
+
+func (c *cli) list(ctx context.Context, r *resource, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	items, err := r.list(ctx, c.client, c.queryOptions()...)
+	if err != nil {
+		return err
+	}
+	return printList(c.stdout, c.output, r.columns, items)
+}
+
+func (c *cli) get(ctx context.Context, r *resource, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	object, err := r.get(ctx, c.client, args[0], c.getOptions()...)
+	if err != nil {
+		return err
+	}
+	return printObject(c.stdout, c.output, object)
+}
+
+func (c *cli) create(ctx context.Context, r *resource, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	if r.create == nil {
+		return fmt.Errorf("%s resources cannot be created", r.name)
+	}
+	body, err := c.readBody()
+	if err != nil {
+		return err
+	}
+	object, location, err := r.create(ctx, c.client, body, c.modificationOptions()...)
+	if err != nil {
+		return err
+	}
+	return c.printResult(ctx, r, object, location)
+}
+
+func (c *cli) update(ctx context.Context, r *resource, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if r.update == nil {
+		return fmt.Errorf("%s resources cannot be updated", r.name)
+	}
+	body, err := c.readBody()
+	if err != nil {
+		return err
+	}
+	object, location, err := r.update(ctx, c.client, args[0], body, c.modificationOptions()...)
+	if err != nil {
+		return err
+	}
+	return c.printResult(ctx, r, object, location)
+}
+
+func (c *cli) delete(ctx context.Context, r *resource, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if r.delete == nil {
+		return fmt.Errorf("%s resources cannot be deleted", r.name)
+	}
+	location, err := r.delete(ctx, c.client, args[0], c.modificationOptions()...)
+	if err != nil {
+		return err
+	}
+	if len(location) != 0 {
+		if c.noWait {
+			fmt.Fprintf(c.stdout, "Deletion of %s %s accepted, operation: %s\n", r.name, args[0], location)
+			return nil
+		}
+		if _, err := c.waitForOperation(ctx, location); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(c.stdout, "Deleted %s %s\n", r.name, args[0])
+	return nil
+}
+
+func (c *cli) label(ctx context.Context, r *resource, args []string) error {
+	if len(args) < 3 {
+		return errUsage
+	}
+	id := args[0]
+	change := &types.LabelChange{
+		Operation: types.LabelOperation(args[1]),
+		Key:       args[2],
+		Values:    args[3:],
+	}
+	if err := change.Validate(); err != nil {
+		return err
+	}
+	if err := c.client.UpdateLabels(ctx, r.objType, id, change); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Updated labels of %s %s\n", r.name, id)
+	return nil
+}
+
+// credentials shows, rotates or revokes the credentials of a platform
+func (c *cli) credentials(ctx context.Context, r *resource, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	if r.objType != types.PlatformType {
+		return fmt.Errorf("only platform credentials can be managed")
+	}
+	id := args[0]
+	switch args[1] {
+	case "status":
+		status, err := c.client.GetPlatformCredentialsStatus(ctx, id)
+		if err != nil {
+			return err
+		}
+		return printObject(c.stdout, c.output, status)
+	case "rotate":
+		status, err := c.client.RotatePlatformCredentials(ctx, id)
+		if err != nil {
+			return err
+		}
+		return printObject(c.stdout, c.output, status)
+	case "revoke-old":
+		if err := c.client.RevokeOldPlatformCredentials(ctx, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "Revoked old credentials of platform %s\n", id)
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+// printResult prints the result of a modification. If the modification is executed asynchronously, the operation is
+// awaited and the modified resource is printed afterwards unless waiting is disabled.
+func (c *cli) printResult(ctx context.Context, r *resource, object interface{}, location string) error {
+	if len(location) == 0 {
+		return printObject(c.stdout, c.output, object)
+	}
+	if c.noWait {
+		fmt.Fprintf(c.stdout, "Request accepted, operation: %s\n", location)
+		return nil
+	}
+	operation, err := c.waitForOperation(ctx, location)
+	if err != nil {
+		return err
+	}
+	object, err = r.get(ctx, c.client, operation.ResourceID)
+	if err != nil {
+		return err
+	}
+	return printObject(c.stdout, c.output, object)
+}
+
+// waitForOperation waits for the operation to complete while printing progress to stderr
+func (c *cli) waitForOperation(ctx context.Context, location string) (*types.Operation, error) {
+	fmt.Fprintf(c.stderr, "Waiting for operation %s", location)
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(c.settings.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Fprint(c.stderr, ".")
+			}
+		}
+	}()
+
+	operation, err := c.client.WaitForOperation(ctx, location)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		fmt.Fprintln(c.stderr, " failed")
+		return nil, err
+	}
+	fmt.Fprintln(c.stderr, " "+strings.ToLower(string(operation.State)))
+	return operation, nil
+}
+
+// readBody reads the request body from the file or inline data and converts it to JSON
+func (c *cli) readBody() ([]byte, error) {
+	var content []byte
+	var err error
+	switch {
+	case len(c.data) != 0:
+		content = []byte(c.data)
+	case c.file == "-":
+		content, err = ioutil.ReadAll(c.stdin)
+	case len(c.file) != 0:
+		content, err = ioutil.ReadFile(c.file)
+	default:
+		return nil, errors.New("request body missing, use --file or --data")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %s", err)
+	}
+
+	// YAML is a superset of JSON, so both are parsed as YAML
+	var value interface{}
+	if err := yaml.Unmarshal(content, &value); err != nil {
+		return nil, fmt.Errorf("could not parse request body: %s", err)
+	}
+	return json.Marshal(convertYAML(value))
+}
+
+// convertYAML converts the maps of parsed YAML to maps with string keys, so that they can be marshalled as JSON
+func convertYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = convertYAML(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
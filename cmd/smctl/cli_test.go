@@ -0,0 +1,233 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+var _ = Describe("smctl", func() {
+	var (
+		server *httptest.Server
+		mux    *http.ServeMux
+		stdin  *bytes.Buffer
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+
+		mutex    sync.Mutex
+		requests []*http.Request
+		bodies   []string
+	)
+
+	writeJSON := func(rw http.ResponseWriter, status int, body string) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(status)
+		rw.Write([]byte(body)) // nolint: errcheck
+	}
+
+	lastRequest := func() (*http.Request, string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests[len(requests)-1], bodies[len(bodies)-1]
+	}
+
+	smctl := func(args ...string) int {
+		args = append([]string{"--url", server.URL, "--poll-interval", "10ms"}, args...)
+		return run(context.Background(), args, stdin, stdout, stderr)
+	}
+
+	BeforeEach(func() {
+		requests = nil
+		bodies = nil
+		stdin = &bytes.Buffer{}
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+		mux = http.NewServeMux()
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			body, _ := ioutil.ReadAll(req.Body)
+			mutex.Lock()
+			requests = append(requests, req)
+			bodies = append(bodies, string(body))
+			mutex.Unlock()
+			mux.ServeHTTP(rw, req)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("with invalid usage", func() {
+		It("prints the usage", func() {
+			Expect(smctl("list")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("Usage:"))
+		})
+
+		It("fails for unknown resources", func() {
+			Expect(smctl("list", "unknown")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring(`unknown resource "unknown"`))
+		})
+
+		It("fails for unsupported output formats", func() {
+			Expect(smctl("list", "platforms", "-o", "xml")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring(`unsupported output format "xml"`))
+		})
+	})
+
+	Context("list", func() {
+		BeforeEach(func() {
+			mux.HandleFunc(web.PlatformsURL, func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, `{"items":[{"id":"1","name":"cf-eu","type":"cloudfoundry"},{"id":"2","name":"k8s-us","type":"kubernetes","created_at":"2020-01-02T03:04:05Z"}]}`)
+			})
+		})
+
+		It("prints the resources as a table", func() {
+			Expect(smctl("list", "platforms", "--label-query", "env eq 'dev'")).To(Equal(exitOK))
+
+			lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(strings.Fields(lines[0])).To(Equal([]string{"ID", "NAME", "TYPE", "DESCRIPTION", "CREATED"}))
+			Expect(strings.Fields(lines[2])).To(Equal([]string{"2", "k8s-us", "kubernetes", "2020-01-02T03:04:05Z"}))
+
+			req, _ := lastRequest()
+			Expect(req.URL.Query().Get("labelQuery")).To(Equal("env eq 'dev'"))
+		})
+
+		It("prints the resources as YAML", func() {
+			Expect(smctl("list", "platforms", "-o", "yaml")).To(Equal(exitOK))
+			Expect(stdout.String()).To(ContainSubstring("- description: \"\"\n  id: \"1\"\n"))
+			Expect(stdout.String()).To(ContainSubstring("  name: k8s-us"))
+		})
+	})
+
+	Context("create", func() {
+		It("sends the YAML body as JSON", func() {
+			mux.HandleFunc(web.PlatformsURL, func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusCreated, `{"id":"1","name":"cf-eu","type":"cloudfoundry","credentials":{"basic":{"username":"user","password":"pass"}}}`)
+			})
+			stdin.WriteString("name: cf-eu\ntype: cloudfoundry\nlabels:\n  env: [dev]\n")
+
+			Expect(smctl("create", "platform", "-f", "-")).To(Equal(exitOK))
+
+			_, body := lastRequest()
+			platform := &types.Platform{}
+			Expect(json.Unmarshal([]byte(body), platform)).To(Succeed())
+			Expect(platform.Name).To(Equal("cf-eu"))
+			Expect(platform.Labels).To(Equal(types.Labels{"env": {"dev"}}))
+			Expect(stdout.String()).To(ContainSubstring(`{"basic":{"username":"user","password":"pass"}}`))
+		})
+
+		It("waits for asynchronous operations", func() {
+			polls := 0
+			mux.HandleFunc(web.ServiceInstancesURL, func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Location", web.ServiceInstancesURL+"/1/operations/2")
+				writeJSON(rw, http.StatusAccepted, `{}`)
+			})
+			mux.HandleFunc(web.ServiceInstancesURL+"/1/operations/2", func(rw http.ResponseWriter, req *http.Request) {
+				mutex.Lock()
+				polls++
+				state := types.IN_PROGRESS
+				if polls > 1 {
+					state = types.SUCCEEDED
+				}
+				mutex.Unlock()
+				writeJSON(rw, http.StatusOK, `{"id":"2","state":"`+string(state)+`","resource_id":"1"}`)
+			})
+			mux.HandleFunc(web.ServiceInstancesURL+"/1", func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, `{"id":"1","name":"my-instance","ready":true}`)
+			})
+
+			Expect(smctl("create", "instance", "-d", `{"name":"my-instance"}`, "--async", "-o", "json")).To(Equal(exitOK))
+
+			Expect(stderr.String()).To(HavePrefix("Waiting for operation " + web.ServiceInstancesURL + "/1/operations/2"))
+			Expect(stderr.String()).To(HaveSuffix(" succeeded\n"))
+			Expect(stdout.String()).To(ContainSubstring(`"name": "my-instance"`))
+		})
+
+		It("fails if the operation fails", func() {
+			mux.HandleFunc(web.ServiceBrokersURL, func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Location", web.ServiceBrokersURL+"/1/operations/2")
+				writeJSON(rw, http.StatusAccepted, `{}`)
+			})
+			mux.HandleFunc(web.ServiceBrokersURL+"/1/operations/2", func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, `{"id":"2","state":"failed","errors":{"description":"catalog unreachable"}}`)
+			})
+
+			Expect(smctl("create", "broker", "-d", `{"name":"my-broker"}`)).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring("catalog unreachable"))
+		})
+	})
+
+	Context("label", func() {
+		It("sends the label change", func() {
+			mux.HandleFunc(web.ServiceInstancesURL+"/1", func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, `{"id":"1"}`)
+			})
+
+			Expect(smctl("label", "instance", "1", "add", "env", "dev", "test")).To(Equal(exitOK))
+
+			req, body := lastRequest()
+			Expect(req.Method).To(Equal(http.MethodPatch))
+			Expect(body).To(MatchJSON(`{"labels":[{"op":"add","key":"env","values":["dev","test"]}]}`))
+		})
+	})
+
+	Context("credentials", func() {
+		It("rotates the platform credentials", func() {
+			mux.HandleFunc(web.PlatformsURL+"/1"+web.PlatformCredentialsURL+"/rotate", func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, `{"credentials":{"basic":{"username":"user","password":"pass"}},"old_credentials_expire_at":"2020-01-02T03:04:05Z"}`)
+			})
+
+			Expect(smctl("credentials", "platform", "1", "rotate")).To(Equal(exitOK))
+
+			req, body := lastRequest()
+			Expect(req.Method).To(Equal(http.MethodPost))
+			Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(body).To(MatchJSON(`{}`))
+			Expect(stdout.String()).To(ContainSubstring(`{"basic":{"username":"user","password":"pass"}}`))
+			Expect(stdout.String()).To(ContainSubstring("2020-01-02T03:04:05Z"))
+		})
+
+		It("fails for other resources", func() {
+			Expect(smctl("credentials", "broker", "1", "rotate")).To(Equal(exitError))
+		})
+	})
+
+	Context("when the request fails", func() {
+		It("prints the error", func() {
+			mux.HandleFunc(web.ServiceBrokersURL+"/missing", func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusNotFound, `{"error":"NotFound","description":"broker not found"}`)
+			})
+
+			Expect(smctl("get", "broker", "missing")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring("broker not found"))
+		})
+	})
+})
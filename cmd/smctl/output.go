@@ -0,0 +1,112 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML}
+
+// printList prints the resources in the specified format. Tables contain the columns of the resource.
+func printList(out io.Writer, format string, columns []column, items interface{}) error {
+	if format != outputTable {
+		return printObject(out, format, items)
+	}
+
+	bytes, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	headers := make([]string, 0, len(columns))
+	for _, c := range columns {
+		headers = append(headers, c.header)
+	}
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	for _, item := range gjson.ParseBytes(bytes).Array() {
+		values := make([]string, 0, len(columns))
+		for _, c := range columns {
+			values = append(values, item.Get(c.path).String())
+		}
+		fmt.Fprintln(writer, strings.Join(values, "\t"))
+	}
+	return writer.Flush()
+}
+
+// printObject prints a single resource in the specified format. In table format the attributes are printed
+// one per line, nested attributes are printed as JSON.
+func printObject(out io.Writer, format string, object interface{}) error {
+	bytes, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case outputJSON:
+		bytes, err = json.MarshalIndent(object, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(bytes))
+		return err
+	case outputYAML:
+		var value interface{}
+		if err := json.Unmarshal(bytes, &value); err != nil {
+			return err
+		}
+		bytes, err = yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(bytes)
+		return err
+	default:
+		writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		gjson.ParseBytes(bytes).ForEach(func(key, value gjson.Result) bool {
+			text := value.String()
+			if value.IsObject() || value.IsArray() {
+				text = value.Raw
+			}
+			fmt.Fprintf(writer, "%s:\t%s\n", key.String(), text)
+			return true
+		})
+		return writer.Flush()
+	}
+}
+
+func validateOutputFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, supported formats are: %s", format, strings.Join(outputFormats, ", "))
+}
@@ -0,0 +1,264 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/smclient"
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// column is a column of the table output. The value is selected from the JSON representation of the resource.
+type column struct {
+	header string
+	path   string
+}
+
+// resource describes how smctl manages a resource type. Operations which are not supported for the resource are nil.
+type resource struct {
+	name    string
+	aliases []string
+	objType types.ObjectType
+	columns []column
+
+	list   func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error)
+	get    func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error)
+	create func(ctx context.Context, client *smclient.Client, body []byte, opts ...smclient.Option) (interface{}, string, error)
+	update func(ctx context.Context, client *smclient.Client, id string, changes json.RawMessage, opts ...smclient.Option) (interface{}, string, error)
+	delete func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (string, error)
+}
+
+var (
+	idColumn        = column{header: "ID", path: "id"}
+	nameColumn      = column{header: "NAME", path: "name"}
+	readyColumn     = column{header: "READY", path: "ready"}
+	createdAtColumn = column{header: "CREATED", path: "created_at"}
+)
+
+var resources = []*resource{
+	{
+		name:    "broker",
+		aliases: []string{"brokers", "service-broker", "service-brokers"},
+		objType: types.ServiceBrokerType,
+		columns: []column{idColumn, nameColumn, {header: "URL", path: "broker_url"}, {header: "DESCRIPTION", path: "description"}, readyColumn, createdAtColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListServiceBrokers(ctx, opts...)
+		},
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			return client.GetServiceBroker(ctx, id, opts...)
+		},
+		create: func(ctx context.Context, client *smclient.Client, body []byte, opts ...smclient.Option) (interface{}, string, error) {
+			broker := &types.ServiceBroker{}
+			if err := json.Unmarshal(body, broker); err != nil {
+				return nil, "", err
+			}
+			return client.CreateServiceBroker(ctx, broker, opts...)
+		},
+		update: func(ctx context.Context, client *smclient.Client, id string, changes json.RawMessage, opts ...smclient.Option) (interface{}, string, error) {
+			return client.UpdateServiceBroker(ctx, id, changes, opts...)
+		},
+		delete: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (string, error) {
+			return client.DeleteServiceBroker(ctx, id, opts...)
+		},
+	},
+	{
+		name:    "platform",
+		aliases: []string{"platforms"},
+		objType: types.PlatformType,
+		columns: []column{idColumn, nameColumn, {header: "TYPE", path: "type"}, {header: "DESCRIPTION", path: "description"}, createdAtColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListPlatforms(ctx, opts...)
+		},
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			return client.GetPlatform(ctx, id, opts...)
+		},
+		create: func(ctx context.Context, client *smclient.Client, body []byte, opts ...smclient.Option) (interface{}, string, error) {
+			platform := &types.Platform{}
+			if err := json.Unmarshal(body, platform); err != nil {
+				return nil, "", err
+			}
+			result, err := client.CreatePlatform(ctx, platform, opts...)
+			return result, "", err
+		},
+		update: func(ctx context.Context, client *smclient.Client, id string, changes json.RawMessage, opts ...smclient.Option) (interface{}, string, error) {
+			result, err := client.UpdatePlatform(ctx, id, changes, opts...)
+			return result, "", err
+		},
+		delete: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (string, error) {
+			return "", client.DeletePlatform(ctx, id, opts...)
+		},
+	},
+	{
+		name:    "visibility",
+		aliases: []string{"visibilities"},
+		objType: types.VisibilityType,
+		columns: []column{idColumn, {header: "PLAN ID", path: "service_plan_id"}, {header: "PLATFORM ID", path: "platform_id"}, createdAtColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListVisibilities(ctx, opts...)
+		},
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			return client.GetVisibility(ctx, id, opts...)
+		},
+		create: func(ctx context.Context, client *smclient.Client, body []byte, opts ...smclient.Option) (interface{}, string, error) {
+			visibility := &types.Visibility{}
+			if err := json.Unmarshal(body, visibility); err != nil {
+				return nil, "", err
+			}
+			result, err := client.CreateVisibility(ctx, visibility, opts...)
+			return result, "", err
+		},
+		update: func(ctx context.Context, client *smclient.Client, id string, changes json.RawMessage, opts ...smclient.Option) (interface{}, string, error) {
+			result, err := client.UpdateVisibility(ctx, id, changes, opts...)
+			return result, "", err
+		},
+		delete: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (string, error) {
+			return "", client.DeleteVisibility(ctx, id, opts...)
+		},
+	},
+	{
+		name:    "offering",
+		aliases: []string{"offerings", "service-offering", "service-offerings"},
+		objType: types.ServiceOfferingType,
+		columns: []column{idColumn, nameColumn, {header: "BROKER ID", path: "broker_id"}, {header: "DESCRIPTION", path: "description"}, readyColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListServiceOfferings(ctx, opts...)
+		},
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			return client.GetServiceOffering(ctx, id, opts...)
+		},
+		update: func(ctx context.Context, client *smclient.Client, id string, changes json.RawMessage, opts ...smclient.Option) (interface{}, string, error) {
+			result, err := client.UpdateServiceOffering(ctx, id, changes, opts...)
+			return result, "", err
+		},
+	},
+	{
+		name:    "plan",
+		aliases: []string{"plans", "service-plan", "service-plans"},
+		objType: types.ServicePlanType,
+		columns: []column{idColumn, nameColumn, {header: "OFFERING ID", path: "service_offering_id"}, {header: "FREE", path: "free"}, readyColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListServicePlans(ctx, opts...)
+		},
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			return client.GetServicePlan(ctx, id, opts...)
+		},
+		update: func(ctx context.Context, client *smclient.Client, id string, changes json.RawMessage, opts ...smclient.Option) (interface{}, string, error) {
+			result, err := client.UpdateServicePlan(ctx, id, changes, opts...)
+			return result, "", err
+		},
+	},
+	{
+		name:    "instance",
+		aliases: []string{"instances", "service-instance", "service-instances"},
+		objType: types.ServiceInstanceType,
+		columns: []column{idColumn, nameColumn, {header: "PLAN ID", path: "service_plan_id"}, {header: "PLATFORM ID", path: "platform_id"}, readyColumn, {header: "USABLE", path: "usable"}, createdAtColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListServiceInstances(ctx, opts...)
+		},
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			return client.GetServiceInstance(ctx, id, opts...)
+		},
+		create: func(ctx context.Context, client *smclient.Client, body []byte, opts ...smclient.Option) (interface{}, string, error) {
+			instance := &types.ServiceInstance{}
+			if err := json.Unmarshal(body, instance); err != nil {
+				return nil, "", err
+			}
+			return client.CreateServiceInstance(ctx, instance, opts...)
+		},
+		update: func(ctx context.Context, client *smclient.Client, id string, changes json.RawMessage, opts ...smclient.Option) (interface{}, string, error) {
+			return client.UpdateServiceInstance(ctx, id, changes, opts...)
+		},
+		delete: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (string, error) {
+			return client.DeleteServiceInstance(ctx, id, opts...)
+		},
+	},
+	{
+		name:    "binding",
+		aliases: []string{"bindings", "service-binding", "service-bindings"},
+		objType: types.ServiceBindingType,
+		columns: []column{idColumn, nameColumn, {header: "INSTANCE ID", path: "service_instance_id"}, readyColumn, createdAtColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListServiceBindings(ctx, opts...)
+		},
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			return client.GetServiceBinding(ctx, id, opts...)
+		},
+		create: func(ctx context.Context, client *smclient.Client, body []byte, opts ...smclient.Option) (interface{}, string, error) {
+			binding := &types.ServiceBinding{}
+			if err := json.Unmarshal(body, binding); err != nil {
+				return nil, "", err
+			}
+			return client.CreateServiceBinding(ctx, binding, opts...)
+		},
+		delete: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (string, error) {
+			return client.DeleteServiceBinding(ctx, id, opts...)
+		},
+	},
+	{
+		name:    "operation",
+		aliases: []string{"operations"},
+		objType: types.OperationType,
+		columns: []column{idColumn, {header: "TYPE", path: "type"}, {header: "STATE", path: "state"}, {header: "RESOURCE TYPE", path: "resource_type"}, {header: "RESOURCE ID", path: "resource_id"}, createdAtColumn},
+		list: func(ctx context.Context, client *smclient.Client, opts ...smclient.Option) (interface{}, error) {
+			return client.ListOperations(ctx, opts...)
+		},
+		// operations are fetched by id through the list endpoint, since they are not necessarily bound to a resource
+		get: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (interface{}, error) {
+			opts = append(opts, smclient.WithParam("fieldQuery", fmt.Sprintf("id eq '%s'", strings.Replace(id, "'", "''", -1))))
+			operations, err := client.ListOperations(ctx, opts...)
+			if err != nil {
+				return nil, err
+			}
+			if len(operations) == 0 {
+				return nil, fmt.Errorf("operation %s not found", id)
+			}
+			return operations[0], nil
+		},
+		delete: func(ctx context.Context, client *smclient.Client, id string, opts ...smclient.Option) (string, error) {
+			return "", client.DeleteOperation(ctx, id, opts...)
+		},
+	},
+}
+
+// findResource returns the resource with the specified name or alias
+func findResource(name string) (*resource, error) {
+	for _, r := range resources {
+		if r.name == name {
+			return r, nil
+		}
+		for _, alias := range r.aliases {
+			if alias == name {
+				return r, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown resource %q, supported resources are: %s", name, strings.Join(resourceNames(), ", "))
+}
+
+func resourceNames() []string {
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -22,57 +22,28 @@ package {{.PackageName}}
 
 import (
 	"github.com/Peripli/service-manager/pkg/types"
-	"github.com/Peripli/service-manager/storage"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 {{if .StoragePackageImport}}
 	{{.StoragePackageImport}}
+{{end}}{{if .ApiPackageImport}}
 {{end}}{{if .ApiPackageImport}}
 	{{.ApiPackageImport}}
 {{end}}
-	"database/sql"
-	"time"
 )
 
-var _ {{.StoragePackage}}PostgresEntity = &{{.Type}}{}
+var _ {{.StoragePackage}}LabeledEntity = &{{.Type}}{}
 
 const {{.Type}}Table = "{{.TableName}}"
 
-func (*{{.Type}}) LabelEntity() {{.StoragePackage}}PostgresLabel {
-	return &{{.Type}}Label{}
-}
-
 func (*{{.Type}}) TableName() string {
 	return {{.Type}}Table
 }
 
-func (e *{{.Type}}) NewLabel(id, key, value string) storage.Label {
-	now := pq.NullTime{
-		Time:  time.Now(),
-		Valid: true,
-	}
-	return &{{.Type}}Label{
-		BaseLabelEntity: BaseLabelEntity{
-			ID:        sql.NullString{String: id, Valid: id != ""},
-			Key:       sql.NullString{String: key, Valid: key != ""},
-			Val:       sql.NullString{String: value, Valid: value != ""},
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		{{.Type}}ID: sql.NullString{String: e.ID, Valid: e.ID != ""},
-	}
-}
-
 func (e *{{.Type}}) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
-	rowCreator := func() EntityLabelRow {
-		return &struct {
-			*{{.Type}}
-			{{.Type}}Label ` + "`db:\"{{.TypeLowerSnakeCase}}_labels\"`" + `
-		}{}
-	}
-	result := &{{.ApiPackage}}{{.ApiTypePlural}}{
-		{{.ApiTypePlural}}: make([]*{{.ApiPackage}}{{.ApiType}}, 0),
+	rowCreator := func() {{.StoragePackage}}PostgresEntity {
+		return &{{.Type}}{}
 	}
+	result := e.NewList()
 	err := rowsToList(rows, rowCreator, result)
 	if err != nil {
 		return nil, err
@@ -80,16 +51,9 @@ func (e *{{.Type}}) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
 	return result, nil
 }
 
-type {{.Type}}Label struct {
-	BaseLabelEntity
-	{{.Type}}ID sql.NullString ` + "`db:\"{{.TypeLowerSnakeCase}}_id\"`" + `
-}
-
-func (el {{.Type}}Label) LabelsTableName() string {
-	return "{{.TypeLowerSnakeCase}}_labels"
-}
-
-func (el {{.Type}}Label) ReferenceColumn() string {
-	return "{{.TypeLowerSnakeCase}}_id"
+func (*{{.Type}}) NewList() types.ObjectList {
+	return &{{.ApiPackage}}{{.ApiTypePlural}}{
+		{{.ApiTypePlural}}: make([]*{{.ApiPackage}}{{.ApiType}}, 0),
+	}
 }
 `
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/Peripli/service-manager/pkg/multitenancy"
+	"github.com/Peripli/service-manager/pkg/security/rbac"
 
 	"github.com/Peripli/service-manager/operations"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/env"
 	"github.com/Peripli/service-manager/pkg/health"
 	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/maintenance"
 	"github.com/Peripli/service-manager/pkg/server"
 	"github.com/Peripli/service-manager/pkg/ws"
 	"github.com/Peripli/service-manager/storage"
@@ -46,6 +48,8 @@ type Settings struct {
 	HTTPClient   *httpclient.Settings
 	Health       *health.Settings
 	Multitenancy *multitenancy.Settings
+	RBAC         *rbac.Settings
+	Maintenance  *maintenance.Settings
 }
 
 // AddPFlags adds the SM config flags to the provided flag set
@@ -66,6 +70,8 @@ func DefaultSettings() *Settings {
 		HTTPClient:   httpclient.DefaultSettings(),
 		Health:       health.DefaultSettings(),
 		Multitenancy: multitenancy.DefaultSettings(),
+		RBAC:         rbac.DefaultSettings(),
+		Maintenance:  maintenance.DefaultSettings(),
 	}
 }
 
@@ -83,7 +89,7 @@ func New(env env.Environment) (*Settings, error) {
 func (c *Settings) Validate() error {
 	validatable := []interface {
 		Validate() error
-	}{c.Server, c.Storage, c.Log, c.Health, c.API, c.Operations, c.WebSocket, c.Multitenancy}
+	}{c.Server, c.Storage, c.Log, c.Health, c.API, c.Operations, c.WebSocket, c.Multitenancy, c.RBAC, c.Maintenance}
 
 	for _, item := range validatable {
 		if err := item.Validate(); err != nil {
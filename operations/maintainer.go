@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/maintenance"
 	"github.com/Peripli/service-manager/pkg/query"
 	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/Peripli/service-manager/pkg/util"
@@ -43,43 +44,62 @@ type maintainerFunctor struct {
 // Maintainer ensures that operations old enough are deleted
 // and that no orphan operations are left in the DB due to crashes/restarts of SM
 type Maintainer struct {
-	smCtx      context.Context
-	repository storage.Repository
-	scheduler  *Scheduler
+	smCtx       context.Context
+	repository  storage.Repository
+	partitioner storage.Partitioner
+	maintenance *maintenance.State
+	scheduler   *Scheduler
 
-	settings *Settings
-	wg       *sync.WaitGroup
+	settingsMutex sync.RWMutex
+	settings      *Settings
+	wg            *sync.WaitGroup
 
 	functors         []maintainerFunctor
 	operationLockers map[string]storage.Locker
 }
 
-// NewMaintainer constructs a Maintainer
-func NewMaintainer(smCtx context.Context, repository storage.TransactionalRepository, lockerCreatorFunc storage.LockerCreatorFunc, options *Settings, wg *sync.WaitGroup) *Maintainer {
+// NewMaintainer constructs a Maintainer. If a partitioner is provided, the partitions of the operations are created in
+// advance and the old operations are dropped with their partitions instead of being deleted one by one. If a maintenance state is provided, the maintainer is paused while the
+// Service Manager is in read-only maintenance mode.
+func NewMaintainer(smCtx context.Context, repository storage.TransactionalRepository, partitioner storage.Partitioner, maintenanceState *maintenance.State, lockerCreatorFunc storage.LockerCreatorFunc, options *Settings, wg *sync.WaitGroup) *Maintainer {
 	maintainer := &Maintainer{
-		smCtx:      smCtx,
-		repository: repository,
-		scheduler:  NewScheduler(smCtx, repository, options, options.DefaultPoolSize, wg),
-		settings:   options,
-		wg:         wg,
+		smCtx:       smCtx,
+		repository:  repository,
+		partitioner: partitioner,
+		maintenance: maintenanceState,
+		scheduler:   NewScheduler(smCtx, repository, options, options.DefaultPoolSize, wg),
+		settings:    options,
+		wg:          wg,
 	}
 
-	maintainer.functors = []maintainerFunctor{
-		{
-			name:     "cleanupExternalOperations",
-			execute:  maintainer.cleanupExternalOperations,
-			interval: options.CleanupInterval,
-		},
-		{
-			name:     "cleanupInternalSuccessfulOperations",
-			execute:  maintainer.cleanupInternalSuccessfulOperations,
-			interval: options.CleanupInterval,
-		},
-		{
-			name:     "cleanupInternalFailedOperations",
-			execute:  maintainer.cleanupInternalFailedOperations,
-			interval: options.CleanupInterval,
-		},
+	if partitioner != nil {
+		maintainer.functors = []maintainerFunctor{
+			{
+				name:     "maintainOperationPartitions",
+				execute:  maintainer.maintainOperationPartitions,
+				interval: options.CleanupInterval,
+			},
+		}
+	} else {
+		maintainer.functors = []maintainerFunctor{
+			{
+				name:     "cleanupExternalOperations",
+				execute:  maintainer.cleanupExternalOperations,
+				interval: options.CleanupInterval,
+			},
+			{
+				name:     "cleanupInternalSuccessfulOperations",
+				execute:  maintainer.cleanupInternalSuccessfulOperations,
+				interval: options.CleanupInterval,
+			},
+			{
+				name:     "cleanupInternalFailedOperations",
+				execute:  maintainer.cleanupInternalFailedOperations,
+				interval: options.CleanupInterval,
+			},
+		}
+	}
+	maintainer.functors = append(maintainer.functors, []maintainerFunctor{
 		{
 			name:     "markOrphanOperationsFailed",
 			execute:  maintainer.markOrphanOperationsFailed,
@@ -95,7 +115,7 @@ func NewMaintainer(smCtx context.Context, repository storage.TransactionalReposi
 			execute:  maintainer.rescheduleOrphanMitigationOperations,
 			interval: options.ActionTimeout / 2,
 		},
-	}
+	}...)
 
 	operationLockers := make(map[string]storage.Locker)
 	advisoryLockStartIndex := initialOperationsLockIndex
@@ -109,12 +129,31 @@ func NewMaintainer(smCtx context.Context, repository storage.TransactionalReposi
 	return maintainer
 }
 
+// Configure changes the timeouts and the lifespan of the operations handled by the maintainer. The intervals in which
+// the maintainer runs are not changed.
+func (om *Maintainer) Configure(settings *Settings) {
+	om.settingsMutex.Lock()
+	defer om.settingsMutex.Unlock()
+	om.settings = settings
+	om.scheduler.Configure(settings, settings.DefaultPoolSize)
+}
+
+func (om *Maintainer) currentSettings() *Settings {
+	om.settingsMutex.RLock()
+	defer om.settingsMutex.RUnlock()
+	return om.settings
+}
+
 // Run starts the two recurring jobs responsible for cleaning up operations which are too old
 // and deleting orphan operations
 func (om *Maintainer) Run() {
 	for _, functor := range om.functors {
 		functor := functor
 		maintainerFunc := func() {
+			if om.maintenance != nil && om.maintenance.ReadOnly(om.smCtx) {
+				log.C(om.smCtx).Infof("Skipping maintainer functor (%s) as Service Manager is in read-only mode", functor.name)
+				return
+			}
 			log.C(om.smCtx).Infof("Attempting to retrieve lock for maintainer functor (%s)", functor.name)
 			err := om.operationLockers[functor.name].TryLock(om.smCtx)
 			if err != nil {
@@ -163,7 +202,7 @@ func (om *Maintainer) cleanupExternalOperations() {
 	criteria := []query.Criterion{
 		query.ByField(query.NotEqualsOperator, "platform_id", types.SMPlatform),
 		// check if operation hasn't been updated for the operation's maximum allowed time to live in DB
-		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.Lifespan))),
+		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().Lifespan))),
 	}
 
 	if err := om.repository.Delete(om.smCtx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
@@ -180,7 +219,7 @@ func (om *Maintainer) cleanupInternalSuccessfulOperations() {
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
 		query.ByField(query.EqualsOperator, "state", string(types.SUCCEEDED)),
 		// check if operation hasn't been updated for the operation's maximum allowed time to live in DB
-		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.Lifespan))),
+		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().Lifespan))),
 	}
 
 	if err := om.repository.Delete(om.smCtx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
@@ -199,7 +238,7 @@ func (om *Maintainer) cleanupInternalFailedOperations() {
 		query.ByField(query.EqualsOperator, "reschedule", "false"),
 		query.ByField(query.EqualsOperator, "deletion_scheduled", ZeroTime),
 		// check if operation hasn't been updated for the operation's maximum allowed time to live in DB
-		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.Lifespan))),
+		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().Lifespan))),
 	}
 
 	if err := om.repository.Delete(om.smCtx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
@@ -209,6 +248,21 @@ func (om *Maintainer) cleanupInternalFailedOperations() {
 	log.C(om.smCtx).Debug("Finished cleaning up failed internal operations")
 }
 
+// maintainOperationPartitions creates the partitions of the operations which will be created until after the next run
+// and drops the partitions of the operations older than their lifespan. It replaces the cleanup of the operations, so
+// the partitioner keeps the same operations as the cleanup does, e.g. the internal operations which are in progress.
+func (om *Maintainer) maintainOperationPartitions() {
+	currentTime := time.Now()
+	if err := om.partitioner.CreatePartitions(om.smCtx, types.OperationType, currentTime, currentTime.Add(2*om.currentSettings().CleanupInterval)); err != nil {
+		log.C(om.smCtx).Warnf("Failed to create operation partitions: %s", err)
+	}
+	if err := om.partitioner.DropPartitions(om.smCtx, types.OperationType, currentTime.Add(-om.currentSettings().Lifespan)); err != nil {
+		log.C(om.smCtx).Warnf("Failed to drop old operation partitions: %s", err)
+		return
+	}
+	log.C(om.smCtx).Debug("Finished maintaining operation partitions")
+}
+
 // rescheduleUnprocessedOperations reschedules IN_PROGRESS operations which are reschedulable, not scheduled for deletion and no goroutine is processing at the moment
 func (om *Maintainer) rescheduleUnprocessedOperations() {
 	currentTime := time.Now()
@@ -218,9 +272,9 @@ func (om *Maintainer) rescheduleUnprocessedOperations() {
 		query.ByField(query.EqualsOperator, "reschedule", "true"),
 		query.ByField(query.EqualsOperator, "deletion_scheduled", ZeroTime),
 		// check if operation hasn't been updated for the operation's maximum allowed time to execute
-		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ActionTimeout))),
+		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().ActionTimeout))),
 		// check if operation is still eligible for processing
-		query.ByField(query.GreaterThanOperator, "created_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ReconciliationOperationTimeout))),
+		query.ByField(query.GreaterThanOperator, "created_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().ReconciliationOperationTimeout))),
 	}
 
 	objectList, err := om.repository.List(om.smCtx, types.OperationType, criteria...)
@@ -289,9 +343,9 @@ func (om *Maintainer) rescheduleOrphanMitigationOperations() {
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
 		query.ByField(query.NotEqualsOperator, "deletion_scheduled", ZeroTime),
 		// check if operation hasn't been updated for the operation's maximum allowed time to execute
-		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ActionTimeout))),
+		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().ActionTimeout))),
 		// check if operation is still eligible for processing
-		query.ByField(query.GreaterThanOperator, "created_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ReconciliationOperationTimeout))),
+		query.ByField(query.GreaterThanOperator, "created_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().ReconciliationOperationTimeout))),
 	}
 
 	objectList, err := om.repository.List(om.smCtx, types.OperationType, criteria...)
@@ -335,7 +389,7 @@ func (om *Maintainer) markOrphanOperationsFailed() {
 		query.ByField(query.EqualsOperator, "reschedule", "false"),
 		query.ByField(query.EqualsOperator, "deletion_scheduled", ZeroTime),
 		// check if operation hasn't been updated for the operation's maximum allowed time to execute
-		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ActionTimeout))),
+		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.currentSettings().ActionTimeout))),
 	}
 
 	objectList, err := om.repository.List(om.smCtx, types.OperationType, criteria...)
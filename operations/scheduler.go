@@ -39,28 +39,69 @@ type storageAction func(ctx context.Context, repository storage.Repository) (typ
 // Scheduler is responsible for storing Operation entities in the DB
 // and also for spawning goroutines to execute the respective DB transaction asynchronously
 type Scheduler struct {
-	smCtx                          context.Context
-	repository                     storage.TransactionalRepository
-	workers                        chan struct{}
+	smCtx       context.Context
+	repository  storage.TransactionalRepository
+	mutex       sync.Mutex
+	settings    schedulerSettings
+	busyWorkers int
+	wg          *sync.WaitGroup
+}
+
+// schedulerSettings are the settings of a Scheduler which can be changed at runtime
+type schedulerSettings struct {
+	poolSize                       int
 	actionTimeout                  time.Duration
 	reconciliationOperationTimeout time.Duration
 	reschedulingDelay              time.Duration
-	wg                             *sync.WaitGroup
 }
 
 // NewScheduler constructs a Scheduler
 func NewScheduler(smCtx context.Context, repository storage.TransactionalRepository, settings *Settings, poolSize int, wg *sync.WaitGroup) *Scheduler {
-	return &Scheduler{
-		smCtx:                          smCtx,
-		repository:                     repository,
-		workers:                        make(chan struct{}, poolSize),
+	scheduler := &Scheduler{
+		smCtx:      smCtx,
+		repository: repository,
+		wg:         wg,
+	}
+	scheduler.Configure(settings, poolSize)
+	return scheduler
+}
+
+// Configure changes the timeouts and the worker pool size of the scheduler. Shrinking the pool does not interrupt
+// the jobs which are already running.
+func (s *Scheduler) Configure(settings *Settings, poolSize int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settings = schedulerSettings{
+		poolSize:                       poolSize,
 		actionTimeout:                  settings.ActionTimeout,
 		reconciliationOperationTimeout: settings.ReconciliationOperationTimeout,
 		reschedulingDelay:              settings.ReschedulingInterval,
-		wg:                             wg,
 	}
 }
 
+func (s *Scheduler) currentSettings() schedulerSettings {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.settings
+}
+
+// acquireWorker reserves a worker of the pool and returns false if all workers are busy
+func (s *Scheduler) acquireWorker() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.busyWorkers >= s.settings.poolSize {
+		return false
+	}
+	s.busyWorkers++
+	return true
+}
+
+func (s *Scheduler) releaseWorker() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.busyWorkers--
+}
+
 // ScheduleSyncStorageAction stores the job's Operation entity in DB and synchronously executes the CREATE/UPDATE/DELETE DB transaction
 func (s *Scheduler) ScheduleSyncStorageAction(ctx context.Context, operation *types.Operation, action storageAction) (types.Object, error) {
 	initialLogMessage(ctx, operation, false)
@@ -88,74 +129,73 @@ func (s *Scheduler) ScheduleSyncStorageAction(ctx context.Context, operation *ty
 
 // ScheduleAsyncStorageAction stores the job's Operation entity in DB asynchronously executes the CREATE/UPDATE/DELETE DB transaction in a goroutine
 func (s *Scheduler) ScheduleAsyncStorageAction(ctx context.Context, operation *types.Operation, action storageAction) error {
-	select {
-	case s.workers <- struct{}{}:
-		initialLogMessage(ctx, operation, true)
-		if err := s.executeOperationPreconditions(ctx, operation); err != nil {
-			<-s.workers
-			return err
+	if !s.acquireWorker() {
+		log.C(ctx).Infof("Failed to schedule %s operation with id %s - all workers are busy.", operation.Type, operation.ID)
+		return &util.HTTPError{
+			ErrorType:   "ServiceUnavailable",
+			Description: "Failed to schedule job. Server is busy - try again in a few minutes.",
+			StatusCode:  http.StatusServiceUnavailable,
 		}
+	}
 
-		s.wg.Add(1)
-		stateCtx := util.StateContext{Context: ctx}
-		go func(operation *types.Operation) {
-			defer func() {
-				if panicErr := recover(); panicErr != nil {
-					errMessage := fmt.Errorf("job panicked while executing: %s", panicErr)
-					op, opErr := s.refetchOperation(stateCtx, operation)
-					if opErr != nil {
-						errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
-					}
-
-					if opErr := updateOperationState(stateCtx, s.repository, op, types.FAILED, &util.HTTPError{
-						ErrorType:   "InternalServerError",
-						Description: "job interrupted",
-						StatusCode:  http.StatusInternalServerError,
-					}); opErr != nil {
-						errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
-					}
-					log.C(stateCtx).Errorf("panic error: %s", errMessage)
-					debug.PrintStack()
-				}
-				<-s.workers
-				s.wg.Done()
-			}()
+	initialLogMessage(ctx, operation, true)
+	if err := s.executeOperationPreconditions(ctx, operation); err != nil {
+		s.releaseWorker()
+		return err
+	}
 
-			stateCtxWithOp, err := s.addOperationToContext(stateCtx, operation)
-			if err != nil {
-				log.C(stateCtx).Error(err)
-				return
-			}
+	s.wg.Add(1)
+	stateCtx := util.StateContext{Context: ctx}
+	go func(operation *types.Operation) {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				errMessage := fmt.Errorf("job panicked while executing: %s", panicErr)
+				op, opErr := s.refetchOperation(stateCtx, operation)
+				if opErr != nil {
+					errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
+				}
 
-			stateCtxWithOpAndTimeout, timeoutCtxCancel := context.WithTimeout(stateCtxWithOp, s.actionTimeout)
-			defer timeoutCtxCancel()
-			go func() {
-				select {
-				case <-s.smCtx.Done():
-					timeoutCtxCancel()
-				case <-stateCtxWithOpAndTimeout.Done():
+				if opErr := updateOperationState(stateCtx, s.repository, op, types.FAILED, &util.HTTPError{
+					ErrorType:   "InternalServerError",
+					Description: "job interrupted",
+					StatusCode:  http.StatusInternalServerError,
+				}); opErr != nil {
+					errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
 				}
+				log.C(stateCtx).Errorf("panic error: %s", errMessage)
+				debug.PrintStack()
+			}
+			s.releaseWorker()
+			s.wg.Done()
+		}()
 
-			}()
+		stateCtxWithOp, err := s.addOperationToContext(stateCtx, operation)
+		if err != nil {
+			log.C(stateCtx).Error(err)
+			return
+		}
 
-			var actionErr error
-			var objectAfterAction types.Object
-			if objectAfterAction, actionErr = action(stateCtxWithOpAndTimeout, s.repository); actionErr != nil {
-				log.C(stateCtx).Errorf("failed to execute action for %s operation with id %s for %s entity with id %s: %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID, actionErr)
+		stateCtxWithOpAndTimeout, timeoutCtxCancel := context.WithTimeout(stateCtxWithOp, s.currentSettings().actionTimeout)
+		defer timeoutCtxCancel()
+		go func() {
+			select {
+			case <-s.smCtx.Done():
+				timeoutCtxCancel()
+			case <-stateCtxWithOpAndTimeout.Done():
 			}
 
-			if _, err := s.handleActionResponse(stateCtx, objectAfterAction, actionErr, operation); err != nil {
-				log.C(stateCtx).Error(err)
-			}
-		}(operation)
-	default:
-		log.C(ctx).Infof("Failed to schedule %s operation with id %s - all workers are busy.", operation.Type, operation.ID)
-		return &util.HTTPError{
-			ErrorType:   "ServiceUnavailable",
-			Description: "Failed to schedule job. Server is busy - try again in a few minutes.",
-			StatusCode:  http.StatusServiceUnavailable,
+		}()
+
+		var actionErr error
+		var objectAfterAction types.Object
+		if objectAfterAction, actionErr = action(stateCtxWithOpAndTimeout, s.repository); actionErr != nil {
+			log.C(stateCtx).Errorf("failed to execute action for %s operation with id %s for %s entity with id %s: %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID, actionErr)
 		}
-	}
+
+		if _, err := s.handleActionResponse(stateCtx, objectAfterAction, actionErr, operation); err != nil {
+			log.C(stateCtx).Error(err)
+		}
+	}(operation)
 
 	return nil
 }
@@ -182,7 +222,7 @@ func (s *Scheduler) checkForConcurrentOperations(ctx context.Context, operation
 
 	// for the outside world job timeout would have expired if the last update happened > job timeout time ago (this is worst case)
 	// an "old" updated_at means that for a while nobody was processing this operation
-	isLastOpInProgress := lastOperation.State == types.IN_PROGRESS && time.Now().Before(lastOperation.UpdatedAt.Add(s.actionTimeout))
+	isLastOpInProgress := lastOperation.State == types.IN_PROGRESS && time.Now().Before(lastOperation.UpdatedAt.Add(s.currentSettings().actionTimeout))
 
 	isAReschedule := lastOperation.Reschedule && operation.Reschedule
 
@@ -448,7 +488,7 @@ func (s *Scheduler) handleActionResponseFailure(ctx context.Context, actionError
 
 	// we want to schedule deletion if the operation is marked for deletion and the deletion timeout is not yet reached
 	isDeleteRescheduleRequired := !opAfterJob.DeletionScheduled.IsZero() &&
-		time.Now().UTC().Before(opAfterJob.DeletionScheduled.Add(s.reconciliationOperationTimeout)) &&
+		time.Now().UTC().Before(opAfterJob.DeletionScheduled.Add(s.currentSettings().reconciliationOperationTimeout)) &&
 		opAfterJob.State != types.SUCCEEDED
 
 	if isDeleteRescheduleRequired {
@@ -467,7 +507,7 @@ func (s *Scheduler) handleActionResponseFailure(ctx context.Context, actionError
 		log.C(ctx).Infof("Scheduling of required delete operation after actual operation with id %s failed", opAfterJob.ID)
 		// if deletion timestamp was set on the op, reschedule the same op with delete action and wait for reschedulingDelay time
 		// so that we don't DOS the broker
-		reschedulingDelayTimeout := time.After(s.reschedulingDelay)
+		reschedulingDelayTimeout := time.After(s.currentSettings().reschedulingDelay)
 		select {
 		case <-s.smCtx.Done():
 			return fmt.Errorf("sm context canceled: %s", s.smCtx.Err())
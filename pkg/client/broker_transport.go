@@ -25,8 +25,11 @@ func GetTransportWithTLS(tlsConfig *tls.Config) *http.Transport {
 	transport := http.Transport{}
 	httpclient.ConfigureTransport(&transport)
 	transport.TLSClientConfig.Certificates = tlsConfig.Certificates
+	transport.TLSClientConfig.RootCAs = tlsConfig.RootCAs
+	transport.TLSClientConfig.ServerName = tlsConfig.ServerName
 
 	//prevents keeping idle connections when accessing to different broker hosts
+	//and reusing connections established with a different broker TLS configuration
 	transport.DisableKeepAlives = true
 	return &transport
 }
@@ -137,6 +137,15 @@ func (v *ViperEnv) Unmarshal(value interface{}) error {
 	return v.Viper.Unmarshal(value)
 }
 
+// Decode decodes the settings values into the value in the same way in which the environment unmarshals its settings
+func Decode(values map[string]interface{}, value interface{}) error {
+	v := viper.New()
+	for key, val := range values {
+		v.Set(key, val)
+	}
+	return v.Unmarshal(value)
+}
+
 func (v *ViperEnv) setupConfigFile(ctx context.Context, onConfigChangeHandlers ...func(env Environment) func(op fsnotify.Event)) error {
 	cfg := struct{ File File }{File: File{}}
 	if err := v.Unmarshal(&cfg); err != nil {
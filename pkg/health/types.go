@@ -35,6 +35,9 @@ const StorageIndicatorName = "storage"
 // PlatformsIndicatorName is the name of platforms indicator
 const PlatformsIndicatorName = "platforms"
 
+// MaintenanceIndicatorName is the name of maintenance mode indicator
+const MaintenanceIndicatorName = "maintenance"
+
 // indicatorNames is a list of names of indicators which will be registered with default settings
 // as part of default health settings, this will allow binding them as part of environment.
 // If an indicator is registered but not specified in this list, it will be configured with
@@ -44,6 +47,7 @@ const PlatformsIndicatorName = "platforms"
 var indicatorNames = [...]string{
 	StorageIndicatorName,
 	PlatformsIndicatorName,
+	MaintenanceIndicatorName,
 }
 
 // Settings type to be loaded from the environment
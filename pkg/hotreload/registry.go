@@ -0,0 +1,272 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package hotreload allows changing sections of the Service Manager configuration at runtime. The changes are stored,
+// so that they are applied by all Service Manager instances and survive restarts.
+package hotreload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// Settings are the settings loaded from a configuration section
+type Settings interface {
+	Validate() error
+}
+
+// Listener is called with the new settings of a configuration section whenever the section is changed. Listeners
+// are called one at a time and must not change the configuration.
+type Listener func(settings Settings)
+
+type section struct {
+	newSettings func() Settings
+	keys        map[string]bool
+	changes     map[string]interface{}
+	listeners   []Listener
+}
+
+// Registry holds the configuration sections which can be changed at runtime
+type Registry struct {
+	environment env.Environment
+	store       storage.ConfigurationStore
+
+	mutex    sync.Mutex
+	sections map[string]*section
+}
+
+// NewRegistry returns a registry of the sections of the environment which stores the changes in the store
+func NewRegistry(environment env.Environment, store storage.ConfigurationStore) *Registry {
+	return &Registry{
+		environment: environment,
+		store:       store,
+		sections:    make(map[string]*section),
+	}
+}
+
+// Register allows changing the keys of the configuration section at runtime. newSettings returns settings with
+// default values into which the section is loaded.
+func (r *Registry) Register(name string, newSettings func() Settings, keys ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s := r.section(name)
+	s.newSettings = newSettings
+	for _, key := range keys {
+		s.keys[key] = true
+	}
+}
+
+// Subscribe adds a listener which is called with the new settings whenever the configuration section is changed
+func (r *Registry) Subscribe(name string, listener Listener) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s := r.section(name)
+	s.listeners = append(s.listeners, listener)
+}
+
+// AllSettings returns the settings of the environment with the changes made at runtime
+func (r *Registry) AllSettings() map[string]interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	settings := make(map[string]interface{})
+	for name, values := range r.environment.AllSettings() {
+		settings[name] = values
+	}
+	for name, s := range r.sections {
+		if len(s.changes) == 0 {
+			continue
+		}
+		values, _ := settings[name].(map[string]interface{})
+		settings[name] = merge(values, s.changes)
+	}
+	return settings
+}
+
+// Change changes configuration sections at runtime. The changes hold the new values of the changed keys by section.
+// A null value reverts the key to its value in the environment. The changed sections are validated before the
+// changes are stored. The changes of all sections are stored at once and applied only after they have been stored.
+func (r *Registry) Change(ctx context.Context, changes map[string]map[string]interface{}) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	newChanges := make(map[string]map[string]interface{}, len(changes))
+	newSettings := make(map[string]Settings, len(changes))
+	if err := r.store.UpdateConfigurationChanges(ctx, func(stored map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+		storedChanges, err := decodeChanges(stored)
+		if err != nil {
+			return nil, err
+		}
+		updated := make(map[string]json.RawMessage, len(changes))
+		for name, sectionChanges := range changes {
+			s, found := r.sections[name]
+			if !found || s.newSettings == nil {
+				return nil, badRequest("configuration section %s cannot be changed at runtime", name)
+			}
+			for key := range sectionChanges {
+				if !s.keys[key] {
+					return nil, badRequest("%s.%s cannot be changed at runtime", name, key)
+				}
+			}
+			newChanges[name] = merge(storedChanges[name], sectionChanges)
+			if newSettings[name], err = r.settings(name, newChanges[name]); err != nil {
+				return nil, badRequest("invalid configuration section %s: %s", name, err)
+			}
+			if updated[name], err = json.Marshal(newChanges[name]); err != nil {
+				return nil, err
+			}
+		}
+		return updated, nil
+	}); err != nil {
+		return err
+	}
+
+	for name, sectionChanges := range newChanges {
+		log.C(ctx).Infof("Changed configuration section %s: %v", name, sectionChanges)
+		r.apply(name, sectionChanges, newSettings[name])
+	}
+	return nil
+}
+
+// Start applies the stored changes and keeps applying the changes made by any Service Manager instance until the
+// context is done
+func (r *Registry) Start(ctx context.Context, wg *sync.WaitGroup) error {
+	if err := r.store.ListenConfigurationChanges(ctx, wg, func(section string) {
+		if err := r.reload(ctx); err != nil {
+			log.C(ctx).WithError(err).Error("Could not reload the configuration changes")
+		}
+	}); err != nil {
+		return fmt.Errorf("could not listen for configuration changes: %s", err)
+	}
+	return r.reload(ctx)
+}
+
+// reload applies the stored changes of the sections which differ from the applied ones
+func (r *Registry) reload(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	storedChanges, err := r.storedChanges(ctx)
+	if err != nil {
+		return err
+	}
+	for name, s := range r.sections {
+		sectionChanges := storedChanges[name]
+		if s.newSettings == nil || (len(sectionChanges) == 0 && len(s.changes) == 0) || reflect.DeepEqual(sectionChanges, s.changes) {
+			continue
+		}
+		settings, err := r.settings(name, sectionChanges)
+		if err != nil {
+			log.C(ctx).WithError(err).Errorf("Could not apply the stored changes of configuration section %s", name)
+			continue
+		}
+		log.C(ctx).Infof("Applying the stored changes of configuration section %s", name)
+		r.apply(name, sectionChanges, settings)
+	}
+	return nil
+}
+
+func (r *Registry) storedChanges(ctx context.Context) (map[string]map[string]interface{}, error) {
+	stored, err := r.store.GetConfigurationChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return decodeChanges(stored)
+}
+
+func decodeChanges(stored map[string]json.RawMessage) (map[string]map[string]interface{}, error) {
+	changes := make(map[string]map[string]interface{}, len(stored))
+	for name, bytes := range stored {
+		var sectionChanges map[string]interface{}
+		if err := json.Unmarshal(bytes, &sectionChanges); err != nil {
+			return nil, fmt.Errorf("could not decode the changes of configuration section %s: %s", name, err)
+		}
+		changes[name] = sectionChanges
+	}
+	return changes, nil
+}
+
+// settings loads the settings of the section from the environment with the changes applied
+func (r *Registry) settings(name string, changes map[string]interface{}) (Settings, error) {
+	values, _ := r.environment.AllSettings()[name].(map[string]interface{})
+	settings := r.sections[name].newSettings()
+	if err := env.Decode(merge(values, changes), settings); err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *Registry) apply(name string, changes map[string]interface{}, settings Settings) {
+	s := r.sections[name]
+	s.changes = changes
+	for _, listener := range s.listeners {
+		listener(settings)
+	}
+}
+
+func (r *Registry) section(name string) *section {
+	s, found := r.sections[name]
+	if !found {
+		s = &section{
+			keys: make(map[string]bool),
+		}
+		r.sections[name] = s
+	}
+	return s
+}
+
+// merge returns the values with the changes applied as a JSON merge patch
+func merge(values, changes map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(values)+len(changes))
+	for key, value := range values {
+		merged[key] = value
+	}
+	for key, change := range changes {
+		if change == nil {
+			delete(merged, key)
+			continue
+		}
+		if changedValues, ok := change.(map[string]interface{}); ok {
+			currentValues, _ := merged[key].(map[string]interface{})
+			merged[key] = merge(currentValues, changedValues)
+			continue
+		}
+		merged[key] = change
+	}
+	return merged
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &util.HTTPError{
+		ErrorType:   "BadRequest",
+		Description: fmt.Sprintf(format, args...),
+		StatusCode:  http.StatusBadRequest,
+	}
+}
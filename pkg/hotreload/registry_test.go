@@ -0,0 +1,192 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hotreload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/env/envfakes"
+	"github.com/Peripli/service-manager/pkg/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testSettings struct {
+	PageSize int           `mapstructure:"page_size"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Name     string        `mapstructure:"name"`
+}
+
+func (s *testSettings) Validate() error {
+	if s.PageSize <= 0 {
+		return fmt.Errorf("PageSize should be > 0")
+	}
+	return nil
+}
+
+type fakeConfigurationStore struct {
+	changes   map[string]json.RawMessage
+	onChange  func(section string)
+	commitErr error
+}
+
+func (s *fakeConfigurationStore) GetConfigurationChanges(ctx context.Context) (map[string]json.RawMessage, error) {
+	changes := make(map[string]json.RawMessage)
+	for section, sectionChanges := range s.changes {
+		changes[section] = sectionChanges
+	}
+	return changes, nil
+}
+
+func (s *fakeConfigurationStore) UpdateConfigurationChanges(ctx context.Context, update func(changes map[string]json.RawMessage) (map[string]json.RawMessage, error)) error {
+	changes, _ := s.GetConfigurationChanges(ctx)
+	updatedChanges, err := update(changes)
+	if err != nil {
+		return err
+	}
+	if s.commitErr != nil {
+		return s.commitErr
+	}
+	for section, sectionChanges := range updatedChanges {
+		s.changes[section] = sectionChanges
+	}
+	return nil
+}
+
+func (s *fakeConfigurationStore) ListenConfigurationChanges(ctx context.Context, wg *sync.WaitGroup, onChange func(section string)) error {
+	s.onChange = onChange
+	return nil
+}
+
+var _ = Describe("Registry", func() {
+	var (
+		store    *fakeConfigurationStore
+		registry *Registry
+		applied  []*testSettings
+	)
+
+	BeforeEach(func() {
+		store = &fakeConfigurationStore{changes: make(map[string]json.RawMessage)}
+		environment := &envfakes.FakeEnvironment{}
+		environment.AllSettingsStub = func() map[string]interface{} {
+			return map[string]interface{}{
+				"test": map[string]interface{}{
+					"page_size": 50,
+					"timeout":   "1m",
+					"name":      "initial",
+				},
+			}
+		}
+		registry = NewRegistry(environment, store)
+		registry.Register("test", func() Settings { return &testSettings{} }, "page_size", "timeout")
+		applied = nil
+		registry.Subscribe("test", func(settings Settings) {
+			applied = append(applied, settings.(*testSettings))
+		})
+	})
+
+	expectBadRequest := func(err error) {
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*util.HTTPError).StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(applied).To(BeEmpty())
+		Expect(store.changes).To(BeEmpty())
+	}
+
+	Describe("Change", func() {
+		It("stores and applies the changes", func() {
+			err := registry.Change(context.Background(), map[string]map[string]interface{}{
+				"test": {"page_size": float64(10), "timeout": "2m"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(Equal([]*testSettings{{PageSize: 10, Timeout: 2 * time.Minute, Name: "initial"}}))
+			Expect(store.changes["test"]).To(MatchJSON(`{"page_size": 10, "timeout": "2m"}`))
+			Expect(registry.AllSettings()["test"]).To(Equal(map[string]interface{}{
+				"page_size": float64(10),
+				"timeout":   "2m",
+				"name":      "initial",
+			}))
+		})
+
+		It("reverts the keys changed to null", func() {
+			store.changes["test"] = json.RawMessage(`{"page_size": 10, "timeout": "2m"}`)
+			err := registry.Change(context.Background(), map[string]map[string]interface{}{
+				"test": {"page_size": nil},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(Equal([]*testSettings{{PageSize: 50, Timeout: 2 * time.Minute, Name: "initial"}}))
+			Expect(store.changes["test"]).To(MatchJSON(`{"timeout": "2m"}`))
+		})
+
+		It("does not apply the changes if they cannot be stored", func() {
+			store.commitErr = errors.New("could not commit")
+			err := registry.Change(context.Background(), map[string]map[string]interface{}{
+				"test": {"page_size": float64(10)},
+			})
+			Expect(err).To(Equal(store.commitErr))
+			Expect(applied).To(BeEmpty())
+			Expect(registry.AllSettings()["test"]).To(HaveKeyWithValue("page_size", 50))
+		})
+
+		It("does not store any section if one of them is invalid", func() {
+			registry.Register("other", func() Settings { return &testSettings{} }, "page_size")
+			expectBadRequest(registry.Change(context.Background(), map[string]map[string]interface{}{
+				"test":  {"page_size": float64(10)},
+				"other": {"page_size": float64(0)},
+			}))
+		})
+
+		It("rejects sections which cannot be changed", func() {
+			expectBadRequest(registry.Change(context.Background(), map[string]map[string]interface{}{
+				"other": {"page_size": float64(10)},
+			}))
+		})
+
+		It("rejects keys which cannot be changed", func() {
+			expectBadRequest(registry.Change(context.Background(), map[string]map[string]interface{}{
+				"test": {"name": "changed"},
+			}))
+		})
+
+		It("rejects invalid settings", func() {
+			expectBadRequest(registry.Change(context.Background(), map[string]map[string]interface{}{
+				"test": {"page_size": float64(0)},
+			}))
+		})
+	})
+
+	Describe("Start", func() {
+		It("applies the stored changes and the changes made by other instances", func() {
+			store.changes["test"] = json.RawMessage(`{"page_size": 10}`)
+			Expect(registry.Start(context.Background(), &sync.WaitGroup{})).To(Succeed())
+			Expect(applied).To(Equal([]*testSettings{{PageSize: 10, Timeout: time.Minute, Name: "initial"}}))
+
+			store.onChange("test")
+			Expect(applied).To(HaveLen(1))
+
+			store.changes["test"] = json.RawMessage(`{"page_size": 20}`)
+			store.onChange("test")
+			Expect(applied).To(HaveLen(2))
+			Expect(applied[1]).To(Equal(&testSettings{PageSize: 20, Timeout: time.Minute, Name: "initial"}))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package maintenance contains logic around the maintenance mode which is shared by all Service Manager instances
+package maintenance
+
+import (
+	"fmt"
+	"time"
+)
+
+// Settings type to be loaded from the environment
+type Settings struct {
+	ReadOnly        bool          `mapstructure:"read_only" description:"whether to switch all Service Manager instances into read-only mode on startup if the maintenance mode has never been set, afterwards it is changed only through the configuration API"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" description:"how often the maintenance mode is reloaded from storage"`
+	RetryAfter      time.Duration `mapstructure:"retry_after" description:"time after which the clients are advised to retry the requests rejected in read-only mode"`
+}
+
+// DefaultSettings returns the default values for the maintenance mode
+func DefaultSettings() *Settings {
+	return &Settings{
+		ReadOnly:        false,
+		RefreshInterval: 5 * time.Second,
+		RetryAfter:      time.Minute,
+	}
+}
+
+// Validate validates the maintenance mode settings
+func (s *Settings) Validate() error {
+	if s.RefreshInterval <= 0 {
+		return fmt.Errorf("validate maintenance settings: refresh_interval should be > 0")
+	}
+	if s.RetryAfter < 0 {
+		return fmt.Errorf("validate maintenance settings: retry_after should be >= 0")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// AdminScope is the scope which the token of a user must contain to change the maintenance mode. Scopes of other
+// names can be mapped to it through the scope mappings of the token issuers.
+const AdminScope = "maintenance.admin"
+
+// State provides the maintenance mode which is held in the storage, so that it is shared by all Service Manager instances
+type State struct {
+	store           storage.MaintenanceModeStore
+	refreshInterval time.Duration
+
+	mutex      sync.Mutex
+	mode       types.MaintenanceMode
+	loadedAt   time.Time
+	refreshing bool
+	generation int64
+}
+
+// NewState returns the maintenance mode state which reloads the mode from the store after the refresh interval
+func NewState(store storage.MaintenanceModeStore, refreshInterval time.Duration) *State {
+	return &State{
+		store:           store,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// Mode returns the maintenance mode. The mode is reloaded from the storage by a single caller at a time, the other
+// callers are served the last loaded mode meanwhile. If the mode cannot be reloaded from the storage, e.g. because
+// the storage is under maintenance, the last loaded mode is returned.
+func (s *State) Mode(ctx context.Context) types.MaintenanceMode {
+	s.mutex.Lock()
+	if s.refreshing || time.Since(s.loadedAt) < s.refreshInterval {
+		defer s.mutex.Unlock()
+		return s.mode
+	}
+	s.refreshing = true
+	generation := s.generation
+	s.mutex.Unlock()
+
+	mode, err := s.store.GetMaintenanceMode(ctx)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.refreshing = false
+	s.loadedAt = time.Now()
+	if err != nil {
+		log.C(ctx).WithError(err).Warn("Could not load maintenance mode from storage")
+	} else if generation == s.generation {
+		// the mode is not replaced if it was set while being loaded, as the loaded mode may be outdated
+		s.mode = *mode
+	}
+	return s.mode
+}
+
+// ReadOnly returns whether the requests changing the state of the Service Manager are rejected
+func (s *State) ReadOnly(ctx context.Context) bool {
+	return s.Mode(ctx).ReadOnly
+}
+
+// SetMode stores the maintenance mode, so that all Service Manager instances switch to it after their refresh interval
+func (s *State) SetMode(ctx context.Context, mode types.MaintenanceMode) (types.MaintenanceMode, error) {
+	mode.UpdatedAt = time.Now().UTC()
+	if err := s.store.SetMaintenanceMode(ctx, &mode); err != nil {
+		return types.MaintenanceMode{}, err
+	}
+	log.C(ctx).Infof("Maintenance mode set to read only: %t, reason: %s", mode.ReadOnly, mode.Reason)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.mode = mode
+	s.loadedAt = time.Now()
+	s.generation++
+	return mode, nil
+}
+
+// SetInitialMode stores the maintenance mode only if no mode has been stored yet, so that a mode changed
+// through the configuration API is not overridden when the Service Manager instances are restarted
+func (s *State) SetInitialMode(ctx context.Context, mode types.MaintenanceMode) error {
+	storedMode, err := s.store.GetMaintenanceMode(ctx)
+	if err != nil {
+		return err
+	}
+	if !storedMode.UpdatedAt.IsZero() {
+		log.C(ctx).Infof("Maintenance mode is already set to read only: %t, reason: %s", storedMode.ReadOnly, storedMode.Reason)
+		return nil
+	}
+	_, err = s.SetMode(ctx, mode)
+	return err
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeModeStore struct {
+	mode     types.MaintenanceMode
+	err      error
+	getCalls int
+	loading  chan struct{}
+	loaded   chan struct{}
+}
+
+func (s *fakeModeStore) GetMaintenanceMode(ctx context.Context) (*types.MaintenanceMode, error) {
+	s.getCalls++
+	mode, err := s.mode, s.err
+	if s.loading != nil {
+		s.loading <- struct{}{}
+		<-s.loaded
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mode, nil
+}
+
+func (s *fakeModeStore) SetMaintenanceMode(ctx context.Context, mode *types.MaintenanceMode) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.mode = *mode
+	return nil
+}
+
+var _ = Describe("State", func() {
+	var store *fakeModeStore
+
+	BeforeEach(func() {
+		store = &fakeModeStore{}
+	})
+
+	It("reloads the mode only after the refresh interval", func() {
+		state := NewState(store, time.Hour)
+		Expect(state.ReadOnly(context.Background())).To(BeFalse())
+		store.mode.ReadOnly = true
+		Expect(state.ReadOnly(context.Background())).To(BeFalse())
+		Expect(store.getCalls).To(Equal(1))
+
+		state = NewState(store, time.Nanosecond)
+		Expect(state.ReadOnly(context.Background())).To(BeTrue())
+		store.mode.ReadOnly = false
+		time.Sleep(time.Millisecond)
+		Expect(state.ReadOnly(context.Background())).To(BeFalse())
+		Expect(store.getCalls).To(Equal(3))
+	})
+
+	It("keeps the last loaded mode if the mode cannot be reloaded", func() {
+		store.mode = types.MaintenanceMode{ReadOnly: true, Reason: "upgrade"}
+		state := NewState(store, time.Nanosecond)
+		Expect(state.Mode(context.Background()).Reason).To(Equal("upgrade"))
+
+		store.err = errors.New("storage unavailable")
+		time.Sleep(time.Millisecond)
+		Expect(state.Mode(context.Background())).To(Equal(types.MaintenanceMode{ReadOnly: true, Reason: "upgrade"}))
+	})
+
+	It("stores the set mode", func() {
+		state := NewState(store, time.Hour)
+		mode, err := state.SetMode(context.Background(), types.MaintenanceMode{ReadOnly: true, Reason: "upgrade"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mode.UpdatedAt).To(BeTemporally("~", time.Now(), time.Second))
+		Expect(store.mode).To(Equal(mode))
+		Expect(state.Mode(context.Background())).To(Equal(mode))
+		Expect(store.getCalls).To(Equal(0))
+	})
+
+	Context("when the mode is being reloaded", func() {
+		var (
+			state *State
+			done  chan types.MaintenanceMode
+		)
+
+		BeforeEach(func() {
+			store.mode = types.MaintenanceMode{ReadOnly: true, Reason: "upgrade"}
+			state = NewState(store, time.Nanosecond)
+			state.Mode(context.Background())
+			time.Sleep(time.Millisecond)
+
+			store.mode = types.MaintenanceMode{}
+			store.loading = make(chan struct{})
+			store.loaded = make(chan struct{})
+			done = make(chan types.MaintenanceMode)
+			go func() {
+				done <- state.Mode(context.Background())
+			}()
+			<-store.loading
+		})
+
+		It("serves the last loaded mode without waiting for the storage", func() {
+			Expect(state.Mode(context.Background())).To(Equal(types.MaintenanceMode{ReadOnly: true, Reason: "upgrade"}))
+
+			close(store.loaded)
+			Expect(<-done).To(Equal(types.MaintenanceMode{}))
+			Expect(store.getCalls).To(Equal(2))
+		})
+
+		It("keeps the mode set meanwhile", func() {
+			store.loading = nil
+			mode, err := state.SetMode(context.Background(), types.MaintenanceMode{Reason: "done"})
+			Expect(err).ToNot(HaveOccurred())
+
+			close(store.loaded)
+			Expect(<-done).To(Equal(mode))
+		})
+	})
+
+	Describe("SetInitialMode", func() {
+		It("stores the mode if no mode has been stored yet", func() {
+			state := NewState(store, time.Hour)
+			Expect(state.SetInitialMode(context.Background(), types.MaintenanceMode{ReadOnly: true})).To(Succeed())
+			Expect(store.mode.ReadOnly).To(BeTrue())
+			Expect(state.ReadOnly(context.Background())).To(BeTrue())
+		})
+
+		It("keeps the stored mode", func() {
+			store.mode = types.MaintenanceMode{UpdatedAt: time.Now()}
+			state := NewState(store, time.Hour)
+			Expect(state.SetInitialMode(context.Background(), types.MaintenanceMode{ReadOnly: true})).To(Succeed())
+			Expect(store.mode.ReadOnly).To(BeFalse())
+		})
+
+		It("fails if the mode cannot be loaded", func() {
+			store.err = errors.New("storage unavailable")
+			state := NewState(store, time.Hour)
+			Expect(state.SetInitialMode(context.Background(), types.MaintenanceMode{ReadOnly: true})).To(MatchError("storage unavailable"))
+		})
+	})
+})
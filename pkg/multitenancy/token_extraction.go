@@ -45,3 +45,44 @@ func ExtractTenantFromTokenWrapperFunc(tenantTokenClaim string) func(request *we
 		return delimiterClaimValue, nil
 	}
 }
+
+// ExtractTenantFromIssuerTokenWrapperFunc returns function which extracts tenant from JWT token using the claim configured
+// for the issuer of the token. The specified tenantTokenClaims map the token issuers to the claims that contain the
+// tenant identifier value. The tenant of the other requests is extracted by the specified extractTenantFunc. If one is
+// not provided, the other requests are not associated with a tenant
+func ExtractTenantFromIssuerTokenWrapperFunc(tenantTokenClaims map[string]string, extractTenantFunc func(request *web.Request) (string, error)) func(request *web.Request) (string, error) {
+	return func(request *web.Request) (string, error) {
+		ctx := request.Context()
+		logger := log.C(ctx)
+
+		otherwise := func(reason string) (string, error) {
+			if extractTenantFunc != nil {
+				return extractTenantFunc(request)
+			}
+			logger.Infof("%s. Proceeding with empty tenant ID value...", reason)
+			return "", nil
+		}
+
+		user, ok := web.UserFromContext(ctx)
+		if !ok {
+			return otherwise("No user found in user context")
+		}
+
+		if user.AuthenticationType != web.Bearer {
+			return otherwise("Authentication type is not Bearer")
+		}
+
+		var userData json.RawMessage
+		if err := user.Data(&userData); err != nil {
+			return "", fmt.Errorf("could not unmarshal claims from token: %s", err)
+		}
+
+		issuer := gjson.GetBytes([]byte(userData), "iss").String()
+		tenantTokenClaim, found := tenantTokenClaims[issuer]
+		if !found {
+			return otherwise(fmt.Sprintf("No tenant claim configured for token issuer %s", issuer))
+		}
+
+		return ExtractTenantFromTokenWrapperFunc(tenantTokenClaim)(request)
+	}
+}
@@ -122,3 +122,63 @@ var _ = Describe("ExtractTenantFromToken", func() {
 		})
 	})
 })
+
+var _ = Describe("ExtractTenantFromIssuerToken", func() {
+	var (
+		fakeRequest  *web.Request
+		tenantClaims map[string]string
+	)
+
+	withClaims := func(claims string) {
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		Expect(err).ToNot(HaveOccurred())
+		fakeRequest = &web.Request{
+			Request: req.WithContext(web.ContextWithUser(context.TODO(), &web.UserContext{
+				Data: func(data interface{}) error {
+					return json.Unmarshal([]byte(claims), data)
+				},
+				AuthenticationType: web.Bearer,
+				Name:               "test-user",
+			})),
+		}
+	}
+
+	BeforeEach(func() {
+		tenantClaims = map[string]string{
+			"https://tenants.example.com": "zid",
+		}
+	})
+
+	When("the token issuer has a tenant claim", func() {
+		It("should extract tenant from the claim", func() {
+			withClaims(`{"iss":"https://tenants.example.com","zid":"tenantID"}`)
+			extractedTenant, err := multitenancy.ExtractTenantFromIssuerTokenWrapperFunc(tenantClaims, nil)(fakeRequest)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(extractedTenant).To(Equal("tenantID"))
+		})
+
+		It("should return an error if the claim is missing", func() {
+			withClaims(`{"iss":"https://tenants.example.com"}`)
+			_, err := multitenancy.ExtractTenantFromIssuerTokenWrapperFunc(tenantClaims, nil)(fakeRequest)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	When("the token issuer has no tenant claim", func() {
+		It("should return empty tenant", func() {
+			withClaims(`{"iss":"https://operators.example.com","zid":"tenantID"}`)
+			extractedTenant, err := multitenancy.ExtractTenantFromIssuerTokenWrapperFunc(tenantClaims, nil)(fakeRequest)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(extractedTenant).To(Equal(""))
+		})
+
+		It("should extract tenant with the provided function", func() {
+			withClaims(`{"iss":"https://operators.example.com","zid":"tenantID"}`)
+			extractedTenant, err := multitenancy.ExtractTenantFromIssuerTokenWrapperFunc(tenantClaims, func(request *web.Request) (string, error) {
+				return "operator-tenant", nil
+			})(fakeRequest)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(extractedTenant).To(Equal("operator-tenant"))
+		})
+	})
+})
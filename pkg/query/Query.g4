@@ -2,14 +2,16 @@ grammar Query ;
 
 expression: criterions EOF ;
 criterions: criterion (Concat criterions)? ;
-criterion: multivariate | univariate  ;
+criterion: multivariate | univariate | unary ;
 multivariate: Key Whitespace MultiOp Whitespace multiValues ;
 univariate: Key Whitespace UniOp Whitespace Value ;
 multiValues: OpenBracket manyValues? CloseBracket ;
 manyValues: Value (ValueSeparator manyValues)? ;
+unary: Key Whitespace UnaryOp ;
 
 MultiOp:  'in' | 'notin' ;
 UniOp: 'eq' | 'ne' | 'gt' | 'lt' | 'ge' | 'le' | 'en' ;
+UnaryOp: 'exists' | 'notexists' ;
 Concat: Whitespace 'and' Whitespace ;
 Value: STRING | NUMBER | BOOLEAN | DATETIME ;
 ValueSeparator: ',' | ', ' ;
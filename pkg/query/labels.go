@@ -0,0 +1,152 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"strconv"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// MatchesLabels returns whether the labels match all label criteria. Field and result criteria are ignored.
+// As in the storage, a label criterion matches if any of the values of the label satisfies it. The unary
+// criteria match on the presence of the label key regardless of its values.
+func MatchesLabels(labels types.Labels, criteria ...Criterion) bool {
+	for _, criterion := range criteria {
+		if criterion.Type != LabelQuery {
+			continue
+		}
+		values, found := labels[criterion.LeftOp]
+		switch criterion.Operator {
+		case ExistsOperator:
+			if !found {
+				return false
+			}
+			continue
+		case NotExistsOperator:
+			if found {
+				return false
+			}
+			continue
+		}
+		if !found {
+			if criterion.Operator.IsNullable() {
+				continue
+			}
+			return false
+		}
+		if !matchesAnyValue(criterion, values) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAnyValue(criterion Criterion, values []string) bool {
+	for _, value := range values {
+		if matchesValue(criterion, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesValue(criterion Criterion, value string) bool {
+	if len(criterion.RightOp) == 0 {
+		return false
+	}
+	right := criterion.RightOp[0]
+	switch criterion.Operator {
+	case EqualsOperator, EqualsOrNilOperator:
+		return value == right
+	case NotEqualsOperator:
+		return value != right
+	case InOperator:
+		return containsValue(criterion.RightOp, value)
+	case NotInOperator:
+		return !containsValue(criterion.RightOp, value)
+	}
+	if criterion.ValueType == types.LabelValueInt {
+		return matchesIntValue(criterion.Operator, value, right)
+	}
+	switch criterion.Operator {
+	case GreaterThanOperator:
+		return compareValues(value, right) > 0
+	case GreaterThanOrEqualOperator:
+		return compareValues(value, right) >= 0
+	case LessThanOperator:
+		return compareValues(value, right) < 0
+	case LessThanOrEqualOperator:
+		return compareValues(value, right) <= 0
+	default:
+		return false
+	}
+}
+
+// matchesIntValue compares the values of int labels as integers. Values which are not integers match no comparison.
+func matchesIntValue(operator Operator, value, right string) bool {
+	leftNumber, leftErr := strconv.ParseInt(value, 10, 64)
+	rightNumber, rightErr := strconv.ParseInt(right, 10, 64)
+	if leftErr != nil || rightErr != nil {
+		return false
+	}
+	switch operator {
+	case GreaterThanOperator:
+		return leftNumber > rightNumber
+	case GreaterThanOrEqualOperator:
+		return leftNumber >= rightNumber
+	case LessThanOperator:
+		return leftNumber < rightNumber
+	case LessThanOrEqualOperator:
+		return leftNumber <= rightNumber
+	default:
+		return false
+	}
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// compareValues compares the values as numbers if both are numeric and as strings otherwise
+func compareValues(left, right string) int {
+	leftNumber, leftErr := strconv.ParseFloat(left, 64)
+	rightNumber, rightErr := strconv.ParseFloat(right, 64)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case leftNumber < rightNumber:
+			return -1
+		case leftNumber > rightNumber:
+			return 1
+		default:
+			return 0
+		}
+	}
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query_test
+
+import (
+	. "github.com/Peripli/service-manager/pkg/query"
+	. "github.com/onsi/ginkgo/extensions/table"
+
+	"github.com/Peripli/service-manager/pkg/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MatchesLabels", func() {
+	labels := types.Labels{
+		"env":  {"dev", "test"},
+		"tier": {"10"},
+	}
+
+	DescribeTable("label criteria",
+		func(criterion Criterion, expected bool) {
+			Expect(MatchesLabels(labels, criterion)).To(Equal(expected))
+		},
+		Entry("eq matching any value", ByLabel(EqualsOperator, "env", "test"), true),
+		Entry("eq not matching", ByLabel(EqualsOperator, "env", "prod"), false),
+		Entry("eq on missing label", ByLabel(EqualsOperator, "region", "eu"), false),
+		Entry("ne", ByLabel(NotEqualsOperator, "env", "dev"), true),
+		Entry("in", ByLabel(InOperator, "env", "prod", "dev"), true),
+		Entry("notin", ByLabel(NotInOperator, "tier", "10", "20"), false),
+		Entry("en on missing label", ByLabel(EqualsOrNilOperator, "region", "eu"), true),
+		Entry("gt compares numbers", ByLabel(GreaterThanOperator, "tier", "9"), true),
+		Entry("le compares numbers", ByLabel(LessThanOrEqualOperator, "tier", "9"), false),
+		Entry("exists on present label", ByLabel(ExistsOperator, "env"), true),
+		Entry("exists on missing label", ByLabel(ExistsOperator, "region"), false),
+		Entry("notexists on present label", ByLabel(NotExistsOperator, "env"), false),
+		Entry("notexists on missing label", ByLabel(NotExistsOperator, "region"), true),
+	)
+
+	It("compares the values of int labels as integers", func() {
+		criterion := ByLabel(GreaterThanOperator, "tier", "9")
+		criterion.ValueType = types.LabelValueInt
+		Expect(MatchesLabels(labels, criterion)).To(BeTrue())
+		Expect(MatchesLabels(types.Labels{"tier": {"high"}}, criterion)).To(BeFalse())
+		Expect(MatchesLabels(types.Labels{"tier": {"9.5"}}, criterion)).To(BeFalse())
+	})
+
+	It("requires all label criteria to match", func() {
+		Expect(MatchesLabels(labels, ByLabel(EqualsOperator, "env", "dev"), ByLabel(EqualsOperator, "tier", "20"))).To(BeFalse())
+		Expect(MatchesLabels(labels, ByLabel(EqualsOperator, "env", "dev"), ByLabel(EqualsOperator, "tier", "10"))).To(BeTrue())
+	})
+
+	It("ignores field criteria", func() {
+		Expect(MatchesLabels(labels, ByField(EqualsOperator, "name", "other"))).To(BeTrue())
+	})
+})
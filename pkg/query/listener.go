@@ -48,6 +48,18 @@ func (s *queryListener) ExitUnivariate(ctx *parser.UnivariateContext) {
 	s.err = s.storeCriterion()
 }
 
+// ExitUnary is called when production unary is exited.
+func (s *queryListener) ExitUnary(ctx *parser.UnaryContext) {
+	if s.err != nil {
+		return
+	}
+	leftOp, operator, _ := getCriterionFields(ctx.Key(), ctx.UnaryOp(), nil)
+	s.leftOp = leftOp
+	s.op = operator
+	s.rightOp = nil
+	s.err = s.storeCriterion()
+}
+
 // ExitManyValues is called when production manyValues is exited.
 func (s *queryListener) ExitManyValues(ctx *parser.ManyValuesContext) {
 	if s.err != nil {
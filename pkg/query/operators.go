@@ -35,6 +35,10 @@ const (
 	NotInOperator notInOperator = "notin"
 	// EqualsOrNilOperator takes two operands and tests if the left is equal to the right, or if the left is nil
 	EqualsOrNilOperator enOperator = "en"
+	// ExistsOperator takes one operand and tests if it is present
+	ExistsOperator existsOperator = "exists"
+	// NotExistsOperator takes one operand and tests if it is not present
+	NotExistsOperator notExistsOperator = "notexists"
 
 	NoOperator noOperator = "nop"
 )
@@ -201,6 +205,42 @@ func (leOperator) IsNumeric() bool {
 	return true
 }
 
+type existsOperator string
+
+func (o existsOperator) String() string {
+	return string(o)
+}
+
+func (existsOperator) Type() OperatorType {
+	return UnaryOperator
+}
+
+func (existsOperator) IsNullable() bool {
+	return false
+}
+
+func (existsOperator) IsNumeric() bool {
+	return false
+}
+
+type notExistsOperator string
+
+func (o notExistsOperator) String() string {
+	return string(o)
+}
+
+func (notExistsOperator) Type() OperatorType {
+	return UnaryOperator
+}
+
+func (notExistsOperator) IsNullable() bool {
+	return false
+}
+
+func (notExistsOperator) IsNumeric() bool {
+	return false
+}
+
 type noOperator string
 
 func (o noOperator) String() string {
@@ -60,3 +60,9 @@ func (s *BaseQueryListener) EnterManyValues(ctx *ManyValuesContext) {}
 
 // ExitManyValues is called when production manyValues is exited.
 func (s *BaseQueryListener) ExitManyValues(ctx *ManyValuesContext) {}
+
+// EnterUnary is called when production unary is entered.
+func (s *BaseQueryListener) EnterUnary(ctx *UnaryContext) {}
+
+// ExitUnary is called when production unary is exited.
+func (s *BaseQueryListener) ExitUnary(ctx *UnaryContext) {}
@@ -12,109 +12,121 @@ var _ = fmt.Printf
 var _ = unicode.IsLetter
 
 var serializedLexerAtn = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 2, 12, 238,
-	8, 1, 4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7,
-	9, 7, 4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12,
-	4, 13, 9, 13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4,
-	18, 9, 18, 4, 19, 9, 19, 4, 20, 9, 20, 4, 21, 9, 21, 4, 22, 9, 22, 4, 23,
-	9, 23, 4, 24, 9, 24, 4, 25, 9, 25, 4, 26, 9, 26, 4, 27, 9, 27, 4, 28, 9,
-	28, 4, 29, 9, 29, 4, 30, 9, 30, 4, 31, 9, 31, 4, 32, 9, 32, 4, 33, 9, 33,
-	4, 34, 9, 34, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 5, 2, 77, 10, 2,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 5, 3, 93, 10, 3, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4,
-	3, 5, 3, 5, 3, 5, 3, 5, 5, 5, 106, 10, 5, 3, 6, 3, 6, 3, 6, 5, 6, 111,
-	10, 6, 3, 7, 6, 7, 114, 10, 7, 13, 7, 14, 7, 115, 3, 8, 3, 8, 3, 9, 3,
-	9, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 5, 10,
-	131, 10, 10, 3, 11, 3, 11, 3, 11, 3, 11, 3, 11, 3, 11, 7, 11, 139, 10,
-	11, 12, 11, 14, 11, 142, 11, 11, 3, 11, 3, 11, 3, 12, 3, 12, 3, 12, 3,
-	12, 3, 12, 3, 13, 3, 13, 3, 13, 3, 14, 3, 14, 3, 14, 3, 15, 3, 15, 3, 16,
-	3, 16, 3, 16, 3, 17, 3, 17, 3, 17, 3, 18, 3, 18, 3, 18, 3, 19, 3, 19, 6,
-	19, 170, 10, 19, 13, 19, 14, 19, 171, 3, 20, 3, 20, 3, 20, 3, 20, 3, 20,
-	3, 21, 3, 21, 5, 21, 181, 10, 21, 3, 22, 3, 22, 3, 22, 3, 22, 3, 22, 3,
-	22, 5, 22, 189, 10, 22, 3, 23, 3, 23, 3, 23, 3, 23, 3, 23, 3, 23, 3, 24,
-	3, 24, 3, 24, 3, 25, 3, 25, 3, 25, 3, 25, 3, 26, 3, 26, 3, 26, 3, 27, 3,
-	27, 3, 27, 3, 28, 3, 28, 3, 28, 3, 29, 5, 29, 214, 10, 29, 3, 29, 3, 29,
-	3, 29, 5, 29, 219, 10, 29, 3, 30, 3, 30, 3, 31, 6, 31, 224, 10, 31, 13,
-	31, 14, 31, 225, 3, 32, 3, 32, 3, 33, 3, 33, 3, 34, 6, 34, 233, 10, 34,
-	13, 34, 14, 34, 234, 3, 34, 3, 34, 2, 2, 35, 3, 3, 5, 4, 7, 5, 9, 6, 11,
-	7, 13, 8, 15, 9, 17, 10, 19, 2, 21, 2, 23, 2, 25, 2, 27, 2, 29, 2, 31,
-	2, 33, 2, 35, 2, 37, 2, 39, 2, 41, 2, 43, 2, 45, 2, 47, 2, 49, 2, 51, 2,
-	53, 2, 55, 2, 57, 2, 59, 2, 61, 2, 63, 2, 65, 11, 67, 12, 3, 2, 8, 8, 2,
-	47, 47, 49, 59, 67, 92, 94, 94, 97, 97, 99, 124, 4, 2, 41, 41, 94, 94,
-	4, 2, 86, 86, 118, 118, 4, 2, 45, 45, 47, 47, 3, 2, 50, 59, 5, 2, 11, 12,
-	15, 15, 34, 34, 2, 237, 2, 3, 3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 7, 3, 2,
-	2, 2, 2, 9, 3, 2, 2, 2, 2, 11, 3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2, 15, 3,
-	2, 2, 2, 2, 17, 3, 2, 2, 2, 2, 65, 3, 2, 2, 2, 2, 67, 3, 2, 2, 2, 3, 76,
-	3, 2, 2, 2, 5, 92, 3, 2, 2, 2, 7, 94, 3, 2, 2, 2, 9, 105, 3, 2, 2, 2, 11,
-	110, 3, 2, 2, 2, 13, 113, 3, 2, 2, 2, 15, 117, 3, 2, 2, 2, 17, 119, 3,
-	2, 2, 2, 19, 130, 3, 2, 2, 2, 21, 132, 3, 2, 2, 2, 23, 145, 3, 2, 2, 2,
-	25, 150, 3, 2, 2, 2, 27, 153, 3, 2, 2, 2, 29, 156, 3, 2, 2, 2, 31, 158,
-	3, 2, 2, 2, 33, 161, 3, 2, 2, 2, 35, 164, 3, 2, 2, 2, 37, 167, 3, 2, 2,
-	2, 39, 173, 3, 2, 2, 2, 41, 180, 3, 2, 2, 2, 43, 182, 3, 2, 2, 2, 45, 190,
-	3, 2, 2, 2, 47, 196, 3, 2, 2, 2, 49, 199, 3, 2, 2, 2, 51, 203, 3, 2, 2,
-	2, 53, 206, 3, 2, 2, 2, 55, 209, 3, 2, 2, 2, 57, 213, 3, 2, 2, 2, 59, 220,
-	3, 2, 2, 2, 61, 223, 3, 2, 2, 2, 63, 227, 3, 2, 2, 2, 65, 229, 3, 2, 2,
-	2, 67, 232, 3, 2, 2, 2, 69, 70, 7, 107, 2, 2, 70, 77, 7, 112, 2, 2, 71,
-	72, 7, 112, 2, 2, 72, 73, 7, 113, 2, 2, 73, 74, 7, 118, 2, 2, 74, 75, 7,
-	107, 2, 2, 75, 77, 7, 112, 2, 2, 76, 69, 3, 2, 2, 2, 76, 71, 3, 2, 2, 2,
-	77, 4, 3, 2, 2, 2, 78, 79, 7, 103, 2, 2, 79, 93, 7, 115, 2, 2, 80, 81,
-	7, 112, 2, 2, 81, 93, 7, 103, 2, 2, 82, 83, 7, 105, 2, 2, 83, 93, 7, 118,
-	2, 2, 84, 85, 7, 110, 2, 2, 85, 93, 7, 118, 2, 2, 86, 87, 7, 105, 2, 2,
-	87, 93, 7, 103, 2, 2, 88, 89, 7, 110, 2, 2, 89, 93, 7, 103, 2, 2, 90, 91,
-	7, 103, 2, 2, 91, 93, 7, 112, 2, 2, 92, 78, 3, 2, 2, 2, 92, 80, 3, 2, 2,
-	2, 92, 82, 3, 2, 2, 2, 92, 84, 3, 2, 2, 2, 92, 86, 3, 2, 2, 2, 92, 88,
-	3, 2, 2, 2, 92, 90, 3, 2, 2, 2, 93, 6, 3, 2, 2, 2, 94, 95, 5, 65, 33, 2,
-	95, 96, 7, 99, 2, 2, 96, 97, 7, 112, 2, 2, 97, 98, 7, 102, 2, 2, 98, 99,
-	3, 2, 2, 2, 99, 100, 5, 65, 33, 2, 100, 8, 3, 2, 2, 2, 101, 106, 5, 21,
-	11, 2, 102, 106, 5, 57, 29, 2, 103, 106, 5, 19, 10, 2, 104, 106, 5, 49,
-	25, 2, 105, 101, 3, 2, 2, 2, 105, 102, 3, 2, 2, 2, 105, 103, 3, 2, 2, 2,
-	105, 104, 3, 2, 2, 2, 106, 10, 3, 2, 2, 2, 107, 111, 7, 46, 2, 2, 108,
-	109, 7, 46, 2, 2, 109, 111, 7, 34, 2, 2, 110, 107, 3, 2, 2, 2, 110, 108,
-	3, 2, 2, 2, 111, 12, 3, 2, 2, 2, 112, 114, 9, 2, 2, 2, 113, 112, 3, 2,
-	2, 2, 114, 115, 3, 2, 2, 2, 115, 113, 3, 2, 2, 2, 115, 116, 3, 2, 2, 2,
-	116, 14, 3, 2, 2, 2, 117, 118, 7, 42, 2, 2, 118, 16, 3, 2, 2, 2, 119, 120,
-	7, 43, 2, 2, 120, 18, 3, 2, 2, 2, 121, 122, 7, 118, 2, 2, 122, 123, 7,
-	116, 2, 2, 123, 124, 7, 119, 2, 2, 124, 131, 7, 103, 2, 2, 125, 126, 7,
-	104, 2, 2, 126, 127, 7, 99, 2, 2, 127, 128, 7, 110, 2, 2, 128, 129, 7,
-	117, 2, 2, 129, 131, 7, 103, 2, 2, 130, 121, 3, 2, 2, 2, 130, 125, 3, 2,
-	2, 2, 131, 20, 3, 2, 2, 2, 132, 140, 7, 41, 2, 2, 133, 134, 7, 94, 2, 2,
-	134, 139, 11, 2, 2, 2, 135, 136, 7, 41, 2, 2, 136, 139, 7, 41, 2, 2, 137,
-	139, 10, 3, 2, 2, 138, 133, 3, 2, 2, 2, 138, 135, 3, 2, 2, 2, 138, 137,
-	3, 2, 2, 2, 139, 142, 3, 2, 2, 2, 140, 138, 3, 2, 2, 2, 140, 141, 3, 2,
-	2, 2, 141, 143, 3, 2, 2, 2, 142, 140, 3, 2, 2, 2, 143, 144, 7, 41, 2, 2,
-	144, 22, 3, 2, 2, 2, 145, 146, 5, 61, 31, 2, 146, 147, 5, 61, 31, 2, 147,
-	148, 5, 61, 31, 2, 148, 149, 5, 61, 31, 2, 149, 24, 3, 2, 2, 2, 150, 151,
-	5, 61, 31, 2, 151, 152, 5, 61, 31, 2, 152, 26, 3, 2, 2, 2, 153, 154, 5,
-	61, 31, 2, 154, 155, 5, 61, 31, 2, 155, 28, 3, 2, 2, 2, 156, 157, 9, 4,
-	2, 2, 157, 30, 3, 2, 2, 2, 158, 159, 5, 61, 31, 2, 159, 160, 5, 61, 31,
-	2, 160, 32, 3, 2, 2, 2, 161, 162, 5, 61, 31, 2, 162, 163, 5, 61, 31, 2,
-	163, 34, 3, 2, 2, 2, 164, 165, 5, 61, 31, 2, 165, 166, 5, 61, 31, 2, 166,
-	36, 3, 2, 2, 2, 167, 169, 7, 48, 2, 2, 168, 170, 5, 61, 31, 2, 169, 168,
-	3, 2, 2, 2, 170, 171, 3, 2, 2, 2, 171, 169, 3, 2, 2, 2, 171, 172, 3, 2,
-	2, 2, 172, 38, 3, 2, 2, 2, 173, 174, 9, 5, 2, 2, 174, 175, 5, 31, 16, 2,
-	175, 176, 7, 60, 2, 2, 176, 177, 5, 33, 17, 2, 177, 40, 3, 2, 2, 2, 178,
-	181, 7, 92, 2, 2, 179, 181, 5, 39, 20, 2, 180, 178, 3, 2, 2, 2, 180, 179,
-	3, 2, 2, 2, 181, 42, 3, 2, 2, 2, 182, 183, 5, 31, 16, 2, 183, 184, 7, 60,
-	2, 2, 184, 185, 5, 33, 17, 2, 185, 186, 7, 60, 2, 2, 186, 188, 5, 35, 18,
-	2, 187, 189, 5, 37, 19, 2, 188, 187, 3, 2, 2, 2, 188, 189, 3, 2, 2, 2,
-	189, 44, 3, 2, 2, 2, 190, 191, 5, 23, 12, 2, 191, 192, 7, 47, 2, 2, 192,
-	193, 5, 25, 13, 2, 193, 194, 7, 47, 2, 2, 194, 195, 5, 27, 14, 2, 195,
-	46, 3, 2, 2, 2, 196, 197, 5, 43, 22, 2, 197, 198, 5, 41, 21, 2, 198, 48,
-	3, 2, 2, 2, 199, 200, 5, 45, 23, 2, 200, 201, 5, 29, 15, 2, 201, 202, 5,
-	47, 24, 2, 202, 50, 3, 2, 2, 2, 203, 204, 5, 53, 27, 2, 204, 205, 5, 61,
-	31, 2, 205, 52, 3, 2, 2, 2, 206, 207, 5, 55, 28, 2, 207, 208, 5, 55, 28,
-	2, 208, 54, 3, 2, 2, 2, 209, 210, 5, 61, 31, 2, 210, 211, 5, 61, 31, 2,
-	211, 56, 3, 2, 2, 2, 212, 214, 5, 59, 30, 2, 213, 212, 3, 2, 2, 2, 213,
-	214, 3, 2, 2, 2, 214, 215, 3, 2, 2, 2, 215, 218, 5, 61, 31, 2, 216, 217,
-	7, 48, 2, 2, 217, 219, 5, 61, 31, 2, 218, 216, 3, 2, 2, 2, 218, 219, 3,
-	2, 2, 2, 219, 58, 3, 2, 2, 2, 220, 221, 9, 5, 2, 2, 221, 60, 3, 2, 2, 2,
-	222, 224, 5, 63, 32, 2, 223, 222, 3, 2, 2, 2, 224, 225, 3, 2, 2, 2, 225,
-	223, 3, 2, 2, 2, 225, 226, 3, 2, 2, 2, 226, 62, 3, 2, 2, 2, 227, 228, 9,
-	6, 2, 2, 228, 64, 3, 2, 2, 2, 229, 230, 7, 34, 2, 2, 230, 66, 3, 2, 2,
-	2, 231, 233, 9, 7, 2, 2, 232, 231, 3, 2, 2, 2, 233, 234, 3, 2, 2, 2, 234,
-	232, 3, 2, 2, 2, 234, 235, 3, 2, 2, 2, 235, 236, 3, 2, 2, 2, 236, 237,
-	8, 34, 2, 2, 237, 68, 3, 2, 2, 2, 18, 2, 76, 92, 105, 110, 115, 130, 138,
-	140, 171, 180, 188, 213, 218, 225, 234, 3, 8, 2, 2,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 2, 13, 257,
+	8, 2, 4, 2, 9, 2, 4, 3, 9, 3, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7, 4, 8,
+	9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12, 4, 13, 9,
+	13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4, 18, 9,
+	18, 4, 19, 9, 19, 4, 20, 9, 20, 4, 21, 9, 21, 4, 22, 9, 22, 4, 23, 9,
+	23, 4, 24, 9, 24, 4, 25, 9, 25, 4, 26, 9, 26, 4, 27, 9, 27, 4, 28, 9,
+	28, 4, 29, 9, 29, 4, 30, 9, 30, 4, 31, 9, 31, 4, 32, 9, 32, 4, 33, 9,
+	33, 4, 34, 9, 34, 4, 35, 9, 35, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3,
+	2, 5, 2, 77, 10, 2, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5, 3, 93, 10, 3, 3, 5, 3, 5, 3, 5, 3,
+	5, 3, 5, 3, 5, 3, 5, 3, 6, 3, 6, 3, 6, 3, 6, 5, 6, 106, 10, 6, 3, 7, 3,
+	7, 3, 7, 5, 7, 111, 10, 7, 3, 8, 6, 8, 114, 10, 8, 13, 8, 14, 8, 115,
+	3, 9, 3, 9, 3, 10, 3, 10, 3, 11, 3, 11, 3, 11, 3, 11, 3, 11, 3, 11, 3,
+	11, 3, 11, 3, 11, 5, 11, 131, 10, 11, 3, 12, 3, 12, 3, 12, 3, 12, 3,
+	12, 3, 12, 7, 12, 139, 10, 12, 12, 12, 14, 12, 142, 11, 12, 3, 12, 3,
+	12, 3, 13, 3, 13, 3, 13, 3, 13, 3, 13, 3, 14, 3, 14, 3, 14, 3, 15, 3,
+	15, 3, 15, 3, 16, 3, 16, 3, 17, 3, 17, 3, 17, 3, 18, 3, 18, 3, 18, 3,
+	19, 3, 19, 3, 19, 3, 20, 3, 20, 6, 20, 170, 10, 20, 13, 20, 14, 20,
+	171, 3, 21, 3, 21, 3, 21, 3, 21, 3, 21, 3, 22, 3, 22, 5, 22, 181, 10,
+	22, 3, 23, 3, 23, 3, 23, 3, 23, 3, 23, 3, 23, 5, 23, 189, 10, 23, 3,
+	24, 3, 24, 3, 24, 3, 24, 3, 24, 3, 24, 3, 25, 3, 25, 3, 25, 3, 26, 3,
+	26, 3, 26, 3, 26, 3, 27, 3, 27, 3, 27, 3, 28, 3, 28, 3, 28, 3, 29, 3,
+	29, 3, 29, 3, 30, 5, 30, 214, 10, 30, 3, 30, 3, 30, 3, 30, 5, 30, 219,
+	10, 30, 3, 31, 3, 31, 3, 32, 6, 32, 224, 10, 32, 13, 32, 14, 32, 225,
+	3, 33, 3, 33, 3, 34, 3, 34, 3, 35, 6, 35, 233, 10, 35, 13, 35, 14, 35,
+	234, 3, 35, 3, 35, 4, 4, 9, 4, 5, 4, 241, 10, 4, 3, 4, 3, 4, 3, 4, 3,
+	4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 2,
+	2, 36, 3, 3, 5, 4, 238, 5, 7, 6, 9, 7, 11, 8, 13, 9, 15, 10, 17, 11,
+	19, 2, 21, 2, 23, 2, 25, 2, 27, 2, 29, 2, 31, 2, 33, 2, 35, 2, 37, 2,
+	39, 2, 41, 2, 43, 2, 45, 2, 47, 2, 49, 2, 51, 2, 53, 2, 55, 2, 57, 2,
+	59, 2, 61, 2, 63, 2, 65, 12, 67, 13, 3, 2, 8, 8, 2, 47, 47, 49, 59, 67,
+	92, 94, 94, 97, 97, 99, 124, 4, 2, 41, 41, 94, 94, 4, 2, 86, 86, 118,
+	118, 4, 2, 45, 45, 47, 47, 3, 2, 50, 59, 5, 2, 11, 12, 15, 15, 34, 34,
+	2, 257, 2, 3, 3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 238, 3, 2, 2, 2, 2, 7,
+	3, 2, 2, 2, 2, 9, 3, 2, 2, 2, 2, 11, 3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2,
+	15, 3, 2, 2, 2, 2, 17, 3, 2, 2, 2, 2, 65, 3, 2, 2, 2, 2, 67, 3, 2, 2,
+	2, 3, 76, 3, 2, 2, 2, 5, 92, 3, 2, 2, 2, 7, 94, 3, 2, 2, 2, 9, 105, 3,
+	2, 2, 2, 11, 110, 3, 2, 2, 2, 13, 113, 3, 2, 2, 2, 15, 117, 3, 2, 2, 2,
+	17, 119, 3, 2, 2, 2, 19, 130, 3, 2, 2, 2, 21, 132, 3, 2, 2, 2, 23, 145,
+	3, 2, 2, 2, 25, 150, 3, 2, 2, 2, 27, 153, 3, 2, 2, 2, 29, 156, 3, 2, 2,
+	2, 31, 158, 3, 2, 2, 2, 33, 161, 3, 2, 2, 2, 35, 164, 3, 2, 2, 2, 37,
+	167, 3, 2, 2, 2, 39, 173, 3, 2, 2, 2, 41, 180, 3, 2, 2, 2, 43, 182, 3,
+	2, 2, 2, 45, 190, 3, 2, 2, 2, 47, 196, 3, 2, 2, 2, 49, 199, 3, 2, 2, 2,
+	51, 203, 3, 2, 2, 2, 53, 206, 3, 2, 2, 2, 55, 209, 3, 2, 2, 2, 57, 213,
+	3, 2, 2, 2, 59, 220, 3, 2, 2, 2, 61, 223, 3, 2, 2, 2, 63, 227, 3, 2, 2,
+	2, 65, 229, 3, 2, 2, 2, 67, 232, 3, 2, 2, 2, 69, 70, 7, 107, 2, 2, 70,
+	77, 7, 112, 2, 2, 71, 72, 7, 112, 2, 2, 72, 73, 7, 113, 2, 2, 73, 74,
+	7, 118, 2, 2, 74, 75, 7, 107, 2, 2, 75, 77, 7, 112, 2, 2, 76, 69, 3, 2,
+	2, 2, 76, 71, 3, 2, 2, 2, 77, 4, 3, 2, 2, 2, 78, 79, 7, 103, 2, 2, 79,
+	93, 7, 115, 2, 2, 80, 81, 7, 112, 2, 2, 81, 93, 7, 103, 2, 2, 82, 83,
+	7, 105, 2, 2, 83, 93, 7, 118, 2, 2, 84, 85, 7, 110, 2, 2, 85, 93, 7,
+	118, 2, 2, 86, 87, 7, 105, 2, 2, 87, 93, 7, 103, 2, 2, 88, 89, 7, 110,
+	2, 2, 89, 93, 7, 103, 2, 2, 90, 91, 7, 103, 2, 2, 91, 93, 7, 112, 2, 2,
+	92, 78, 3, 2, 2, 2, 92, 80, 3, 2, 2, 2, 92, 82, 3, 2, 2, 2, 92, 84, 3,
+	2, 2, 2, 92, 86, 3, 2, 2, 2, 92, 88, 3, 2, 2, 2, 92, 90, 3, 2, 2, 2,
+	93, 6, 3, 2, 2, 2, 94, 95, 5, 65, 34, 2, 95, 96, 7, 99, 2, 2, 96, 97,
+	7, 112, 2, 2, 97, 98, 7, 102, 2, 2, 98, 99, 3, 2, 2, 2, 99, 100, 5, 65,
+	34, 2, 100, 8, 3, 2, 2, 2, 101, 106, 5, 21, 12, 2, 102, 106, 5, 57, 30,
+	2, 103, 106, 5, 19, 11, 2, 104, 106, 5, 49, 26, 2, 105, 101, 3, 2, 2,
+	2, 105, 102, 3, 2, 2, 2, 105, 103, 3, 2, 2, 2, 105, 104, 3, 2, 2, 2,
+	106, 10, 3, 2, 2, 2, 107, 111, 7, 46, 2, 2, 108, 109, 7, 46, 2, 2, 109,
+	111, 7, 34, 2, 2, 110, 107, 3, 2, 2, 2, 110, 108, 3, 2, 2, 2, 111, 12,
+	3, 2, 2, 2, 112, 114, 9, 2, 2, 2, 113, 112, 3, 2, 2, 2, 114, 115, 3, 2,
+	2, 2, 115, 113, 3, 2, 2, 2, 115, 116, 3, 2, 2, 2, 116, 14, 3, 2, 2, 2,
+	117, 118, 7, 42, 2, 2, 118, 16, 3, 2, 2, 2, 119, 120, 7, 43, 2, 2, 120,
+	18, 3, 2, 2, 2, 121, 122, 7, 118, 2, 2, 122, 123, 7, 116, 2, 2, 123,
+	124, 7, 119, 2, 2, 124, 131, 7, 103, 2, 2, 125, 126, 7, 104, 2, 2, 126,
+	127, 7, 99, 2, 2, 127, 128, 7, 110, 2, 2, 128, 129, 7, 117, 2, 2, 129,
+	131, 7, 103, 2, 2, 130, 121, 3, 2, 2, 2, 130, 125, 3, 2, 2, 2, 131, 20,
+	3, 2, 2, 2, 132, 140, 7, 41, 2, 2, 133, 134, 7, 94, 2, 2, 134, 139, 11,
+	2, 2, 2, 135, 136, 7, 41, 2, 2, 136, 139, 7, 41, 2, 2, 137, 139, 10, 3,
+	2, 2, 138, 133, 3, 2, 2, 2, 138, 135, 3, 2, 2, 2, 138, 137, 3, 2, 2, 2,
+	139, 142, 3, 2, 2, 2, 140, 138, 3, 2, 2, 2, 140, 141, 3, 2, 2, 2, 141,
+	143, 3, 2, 2, 2, 142, 140, 3, 2, 2, 2, 143, 144, 7, 41, 2, 2, 144, 22,
+	3, 2, 2, 2, 145, 146, 5, 61, 32, 2, 146, 147, 5, 61, 32, 2, 147, 148,
+	5, 61, 32, 2, 148, 149, 5, 61, 32, 2, 149, 24, 3, 2, 2, 2, 150, 151, 5,
+	61, 32, 2, 151, 152, 5, 61, 32, 2, 152, 26, 3, 2, 2, 2, 153, 154, 5,
+	61, 32, 2, 154, 155, 5, 61, 32, 2, 155, 28, 3, 2, 2, 2, 156, 157, 9, 4,
+	2, 2, 157, 30, 3, 2, 2, 2, 158, 159, 5, 61, 32, 2, 159, 160, 5, 61, 32,
+	2, 160, 32, 3, 2, 2, 2, 161, 162, 5, 61, 32, 2, 162, 163, 5, 61, 32, 2,
+	163, 34, 3, 2, 2, 2, 164, 165, 5, 61, 32, 2, 165, 166, 5, 61, 32, 2,
+	166, 36, 3, 2, 2, 2, 167, 169, 7, 48, 2, 2, 168, 170, 5, 61, 32, 2,
+	169, 168, 3, 2, 2, 2, 170, 171, 3, 2, 2, 2, 171, 169, 3, 2, 2, 2, 171,
+	172, 3, 2, 2, 2, 172, 38, 3, 2, 2, 2, 173, 174, 9, 5, 2, 2, 174, 175,
+	5, 31, 17, 2, 175, 176, 7, 60, 2, 2, 176, 177, 5, 33, 18, 2, 177, 40,
+	3, 2, 2, 2, 178, 181, 7, 92, 2, 2, 179, 181, 5, 39, 21, 2, 180, 178, 3,
+	2, 2, 2, 180, 179, 3, 2, 2, 2, 181, 42, 3, 2, 2, 2, 182, 183, 5, 31,
+	17, 2, 183, 184, 7, 60, 2, 2, 184, 185, 5, 33, 18, 2, 185, 186, 7, 60,
+	2, 2, 186, 188, 5, 35, 19, 2, 187, 189, 5, 37, 20, 2, 188, 187, 3, 2,
+	2, 2, 188, 189, 3, 2, 2, 2, 189, 44, 3, 2, 2, 2, 190, 191, 5, 23, 13,
+	2, 191, 192, 7, 47, 2, 2, 192, 193, 5, 25, 14, 2, 193, 194, 7, 47, 2,
+	2, 194, 195, 5, 27, 15, 2, 195, 46, 3, 2, 2, 2, 196, 197, 5, 43, 23, 2,
+	197, 198, 5, 41, 22, 2, 198, 48, 3, 2, 2, 2, 199, 200, 5, 45, 24, 2,
+	200, 201, 5, 29, 16, 2, 201, 202, 5, 47, 25, 2, 202, 50, 3, 2, 2, 2,
+	203, 204, 5, 53, 28, 2, 204, 205, 5, 61, 32, 2, 205, 52, 3, 2, 2, 2,
+	206, 207, 5, 55, 29, 2, 207, 208, 5, 55, 29, 2, 208, 54, 3, 2, 2, 2,
+	209, 210, 5, 61, 32, 2, 210, 211, 5, 61, 32, 2, 211, 56, 3, 2, 2, 2,
+	212, 214, 5, 59, 31, 2, 213, 212, 3, 2, 2, 2, 213, 214, 3, 2, 2, 2,
+	214, 215, 3, 2, 2, 2, 215, 218, 5, 61, 32, 2, 216, 217, 7, 48, 2, 2,
+	217, 219, 5, 61, 32, 2, 218, 216, 3, 2, 2, 2, 218, 219, 3, 2, 2, 2,
+	219, 58, 3, 2, 2, 2, 220, 221, 9, 5, 2, 2, 221, 60, 3, 2, 2, 2, 222,
+	224, 5, 63, 33, 2, 223, 222, 3, 2, 2, 2, 224, 225, 3, 2, 2, 2, 225,
+	223, 3, 2, 2, 2, 225, 226, 3, 2, 2, 2, 226, 62, 3, 2, 2, 2, 227, 228,
+	9, 6, 2, 2, 228, 64, 3, 2, 2, 2, 229, 230, 7, 34, 2, 2, 230, 66, 3, 2,
+	2, 2, 231, 233, 9, 7, 2, 2, 232, 231, 3, 2, 2, 2, 233, 234, 3, 2, 2, 2,
+	234, 232, 3, 2, 2, 2, 234, 235, 3, 2, 2, 2, 235, 236, 3, 2, 2, 2, 236,
+	237, 8, 35, 2, 2, 237, 68, 3, 2, 2, 2, 238, 240, 3, 2, 2, 2, 240, 242,
+	3, 2, 2, 2, 242, 243, 7, 103, 2, 2, 243, 244, 7, 122, 2, 2, 244, 245,
+	7, 107, 2, 2, 245, 246, 7, 117, 2, 2, 246, 247, 7, 118, 2, 2, 247, 241,
+	7, 117, 2, 2, 240, 248, 3, 2, 2, 2, 248, 249, 7, 112, 2, 2, 249, 250,
+	7, 113, 2, 2, 250, 251, 7, 118, 2, 2, 251, 252, 7, 103, 2, 2, 252, 253,
+	7, 122, 2, 2, 253, 254, 7, 107, 2, 2, 254, 255, 7, 117, 2, 2, 255, 256,
+	7, 118, 2, 2, 256, 241, 7, 117, 2, 2, 241, 239, 3, 2, 2, 2, 19, 2, 76,
+	92, 105, 110, 115, 130, 138, 140, 171, 180, 188, 213, 218, 225, 234,
+	240, 3, 8, 2, 2,
 }
 
 var lexerDeserializer = antlr.NewATNDeserializer(nil)
@@ -129,20 +141,20 @@ var lexerModeNames = []string{
 }
 
 var lexerLiteralNames = []string{
-	"", "", "", "", "", "", "", "'('", "')'", "' '",
+	"", "", "", "", "", "", "", "", "'('", "')'", "' '",
 }
 
 var lexerSymbolicNames = []string{
-	"", "MultiOp", "UniOp", "Concat", "Value", "ValueSeparator", "Key", "OpenBracket",
-	"CloseBracket", "Whitespace", "WS",
+	"", "MultiOp", "UniOp", "UnaryOp", "Concat", "Value", "ValueSeparator", "Key",
+	"OpenBracket", "CloseBracket", "Whitespace", "WS",
 }
 
 var lexerRuleNames = []string{
-	"MultiOp", "UniOp", "Concat", "Value", "ValueSeparator", "Key", "OpenBracket",
-	"CloseBracket", "BOOLEAN", "STRING", "YEAR", "MONTH", "DAY", "DELIM", "HOUR",
-	"MINUTE", "SECOND", "SECFRAC", "NUMOFFSET", "OFFSET", "PARTIAL_TIME", "FULL_DATE",
-	"FULL_TIME", "DATETIME", "FIVE_DIGITS", "FOUR_DIGITS", "TWO_DIGITS", "NUMBER",
-	"SIGN", "DIGIT", "INTEGER", "Whitespace", "WS",
+	"MultiOp", "UniOp", "UnaryOp", "Concat", "Value", "ValueSeparator", "Key",
+	"OpenBracket", "CloseBracket", "BOOLEAN", "STRING", "YEAR", "MONTH", "DAY",
+	"DELIM", "HOUR", "MINUTE", "SECOND", "SECFRAC", "NUMOFFSET", "OFFSET", "PARTIAL_TIME",
+	"FULL_DATE", "FULL_TIME", "DATETIME", "FIVE_DIGITS", "FOUR_DIGITS", "TWO_DIGITS",
+	"NUMBER", "SIGN", "DIGIT", "INTEGER", "Whitespace", "WS",
 }
 
 type QueryLexer struct {
@@ -182,12 +194,13 @@ func NewQueryLexer(input antlr.CharStream) *QueryLexer {
 const (
 	QueryLexerMultiOp        = 1
 	QueryLexerUniOp          = 2
-	QueryLexerConcat         = 3
-	QueryLexerValue          = 4
-	QueryLexerValueSeparator = 5
-	QueryLexerKey            = 6
-	QueryLexerOpenBracket    = 7
-	QueryLexerCloseBracket   = 8
-	QueryLexerWhitespace     = 9
-	QueryLexerWS             = 10
+	QueryLexerUnaryOp        = 3
+	QueryLexerConcat         = 4
+	QueryLexerValue          = 5
+	QueryLexerValueSeparator = 6
+	QueryLexerKey            = 7
+	QueryLexerOpenBracket    = 8
+	QueryLexerCloseBracket   = 9
+	QueryLexerWhitespace     = 10
+	QueryLexerWS             = 11
 )
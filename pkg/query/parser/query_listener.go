@@ -27,6 +27,9 @@ type QueryListener interface {
 	// EnterManyValues is called when entering the manyValues production.
 	EnterManyValues(c *ManyValuesContext)
 
+	// EnterUnary is called when entering the unary production.
+	EnterUnary(c *UnaryContext)
+
 	// ExitExpression is called when exiting the expression production.
 	ExitExpression(c *ExpressionContext)
 
@@ -47,4 +50,7 @@ type QueryListener interface {
 
 	// ExitManyValues is called when exiting the manyValues production.
 	ExitManyValues(c *ManyValuesContext)
+
+	// ExitUnary is called when exiting the unary production.
+	ExitUnary(c *UnaryContext)
 }
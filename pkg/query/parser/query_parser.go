@@ -14,41 +14,44 @@ var _ = reflect.Copy
 var _ = strconv.Itoa
 
 var parserATN = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 12, 52, 4,
-	2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7, 4,
-	8, 9, 8, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 3, 5, 3, 23, 10, 3, 3, 4, 3,
-	4, 5, 4, 27, 10, 4, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3, 6, 3, 6, 3,
-	6, 3, 6, 3, 6, 3, 6, 3, 7, 3, 7, 5, 7, 43, 10, 7, 3, 7, 3, 7, 3, 8, 3,
-	8, 3, 8, 5, 8, 50, 10, 8, 3, 8, 2, 2, 9, 2, 4, 6, 8, 10, 12, 14, 2, 2,
-	2, 48, 2, 16, 3, 2, 2, 2, 4, 19, 3, 2, 2, 2, 6, 26, 3, 2, 2, 2, 8, 28,
-	3, 2, 2, 2, 10, 34, 3, 2, 2, 2, 12, 40, 3, 2, 2, 2, 14, 46, 3, 2, 2, 2,
-	16, 17, 5, 4, 3, 2, 17, 18, 7, 2, 2, 3, 18, 3, 3, 2, 2, 2, 19, 22, 5, 6,
-	4, 2, 20, 21, 7, 5, 2, 2, 21, 23, 5, 4, 3, 2, 22, 20, 3, 2, 2, 2, 22, 23,
-	3, 2, 2, 2, 23, 5, 3, 2, 2, 2, 24, 27, 5, 8, 5, 2, 25, 27, 5, 10, 6, 2,
-	26, 24, 3, 2, 2, 2, 26, 25, 3, 2, 2, 2, 27, 7, 3, 2, 2, 2, 28, 29, 7, 8,
-	2, 2, 29, 30, 7, 11, 2, 2, 30, 31, 7, 3, 2, 2, 31, 32, 7, 11, 2, 2, 32,
-	33, 5, 12, 7, 2, 33, 9, 3, 2, 2, 2, 34, 35, 7, 8, 2, 2, 35, 36, 7, 11,
-	2, 2, 36, 37, 7, 4, 2, 2, 37, 38, 7, 11, 2, 2, 38, 39, 7, 6, 2, 2, 39,
-	11, 3, 2, 2, 2, 40, 42, 7, 9, 2, 2, 41, 43, 5, 14, 8, 2, 42, 41, 3, 2,
-	2, 2, 42, 43, 3, 2, 2, 2, 43, 44, 3, 2, 2, 2, 44, 45, 7, 10, 2, 2, 45,
-	13, 3, 2, 2, 2, 46, 49, 7, 6, 2, 2, 47, 48, 7, 7, 2, 2, 48, 50, 5, 14,
-	8, 2, 49, 47, 3, 2, 2, 2, 49, 50, 3, 2, 2, 2, 50, 15, 3, 2, 2, 2, 6, 22,
-	26, 42, 49,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 13, 59,
+	4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7,
+	4, 8, 9, 8, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 3, 5, 3, 23, 10, 3, 3, 4,
+	3, 4, 5, 4, 27, 10, 4, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3, 6, 3, 6,
+	3, 6, 3, 6, 3, 6, 3, 6, 3, 7, 3, 7, 5, 7, 43, 10, 7, 3, 7, 3, 7, 3, 8,
+	3, 8, 3, 8, 5, 8, 50, 10, 8, 3, 8, 3, 4, 4, 9, 9, 9, 3, 9, 3, 9, 3, 9,
+	3, 9, 2, 2, 10, 2, 4, 6, 8, 10, 12, 14, 53, 2, 2, 2, 55, 2, 16, 3, 2,
+	2, 2, 4, 19, 3, 2, 2, 2, 6, 26, 3, 2, 2, 2, 8, 28, 3, 2, 2, 2, 10, 34,
+	3, 2, 2, 2, 12, 40, 3, 2, 2, 2, 14, 46, 3, 2, 2, 2, 16, 17, 5, 4, 3, 2,
+	17, 18, 7, 2, 2, 3, 18, 3, 3, 2, 2, 2, 19, 22, 5, 6, 4, 2, 20, 21, 7,
+	6, 2, 2, 21, 23, 5, 4, 3, 2, 22, 20, 3, 2, 2, 2, 22, 23, 3, 2, 2, 2,
+	23, 5, 3, 2, 2, 2, 24, 27, 5, 8, 5, 2, 25, 27, 5, 10, 6, 2, 26, 24, 3,
+	2, 2, 2, 26, 25, 3, 2, 2, 2, 27, 7, 3, 2, 2, 2, 28, 29, 7, 9, 2, 2, 29,
+	30, 7, 12, 2, 2, 30, 31, 7, 3, 2, 2, 31, 32, 7, 12, 2, 2, 32, 33, 5,
+	12, 7, 2, 33, 9, 3, 2, 2, 2, 34, 35, 7, 9, 2, 2, 35, 36, 7, 12, 2, 2,
+	36, 37, 7, 4, 2, 2, 37, 38, 7, 12, 2, 2, 38, 39, 7, 7, 2, 2, 39, 11, 3,
+	2, 2, 2, 40, 42, 7, 10, 2, 2, 41, 43, 5, 14, 8, 2, 42, 41, 3, 2, 2, 2,
+	42, 43, 3, 2, 2, 2, 43, 44, 3, 2, 2, 2, 44, 45, 7, 11, 2, 2, 45, 13, 3,
+	2, 2, 2, 46, 49, 7, 7, 2, 2, 47, 48, 7, 8, 2, 2, 48, 50, 5, 14, 8, 2,
+	49, 47, 3, 2, 2, 2, 49, 50, 3, 2, 2, 2, 50, 15, 3, 2, 2, 2, 26, 52, 3,
+	2, 2, 2, 52, 27, 5, 53, 9, 2, 53, 55, 3, 2, 2, 2, 55, 56, 7, 9, 2, 2,
+	56, 57, 7, 12, 2, 2, 57, 58, 7, 5, 2, 2, 58, 54, 3, 2, 2, 2, 6, 22, 26,
+	42, 49,
 }
 var deserializer = antlr.NewATNDeserializer(nil)
 var deserializedATN = deserializer.DeserializeFromUInt16(parserATN)
 
 var literalNames = []string{
-	"", "", "", "", "", "", "", "'('", "')'", "' '",
+	"", "", "", "", "", "", "", "", "'('", "')'", "' '",
 }
 var symbolicNames = []string{
-	"", "MultiOp", "UniOp", "Concat", "Value", "ValueSeparator", "Key", "OpenBracket",
-	"CloseBracket", "Whitespace", "WS",
+	"", "MultiOp", "UniOp", "UnaryOp", "Concat", "Value", "ValueSeparator", "Key",
+	"OpenBracket", "CloseBracket", "Whitespace", "WS",
 }
 
 var ruleNames = []string{
 	"expression", "criterions", "criterion", "multivariate", "univariate",
-	"multiValues", "manyValues",
+	"multiValues", "manyValues", "unary",
 }
 var decisionToDFA = make([]*antlr.DFA, len(deserializedATN.DecisionToState))
 
@@ -81,14 +84,15 @@ const (
 	QueryParserEOF            = antlr.TokenEOF
 	QueryParserMultiOp        = 1
 	QueryParserUniOp          = 2
-	QueryParserConcat         = 3
-	QueryParserValue          = 4
-	QueryParserValueSeparator = 5
-	QueryParserKey            = 6
-	QueryParserOpenBracket    = 7
-	QueryParserCloseBracket   = 8
-	QueryParserWhitespace     = 9
-	QueryParserWS             = 10
+	QueryParserUnaryOp        = 3
+	QueryParserConcat         = 4
+	QueryParserValue          = 5
+	QueryParserValueSeparator = 6
+	QueryParserKey            = 7
+	QueryParserOpenBracket    = 8
+	QueryParserCloseBracket   = 9
+	QueryParserWhitespace     = 10
+	QueryParserWS             = 11
 )
 
 // QueryParser rules.
@@ -100,6 +104,7 @@ const (
 	QueryParserRULE_univariate   = 4
 	QueryParserRULE_multiValues  = 5
 	QueryParserRULE_manyValues   = 6
+	QueryParserRULE_unary        = 7
 )
 
 // IExpressionContext is an interface to support dynamic dispatch.
@@ -392,6 +397,16 @@ func (s *CriterionContext) Univariate() IUnivariateContext {
 	return t.(IUnivariateContext)
 }
 
+func (s *CriterionContext) Unary() IUnaryContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IUnaryContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IUnaryContext)
+}
+
 func (s *CriterionContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
@@ -449,6 +464,13 @@ func (p *QueryParser) Criterion() (localctx ICriterionContext) {
 			p.Univariate()
 		}
 
+	case 3:
+		p.EnterOuterAlt(localctx, 3)
+		{
+			p.SetState(50)
+			p.Unary()
+		}
+
 	}
 
 	return localctx
@@ -947,3 +969,110 @@ func (p *QueryParser) ManyValues() (localctx IManyValuesContext) {
 
 	return localctx
 }
+
+// IUnaryContext is an interface to support dynamic dispatch.
+type IUnaryContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// IsUnaryContext differentiates from other interfaces.
+	IsUnaryContext()
+}
+
+type UnaryContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+}
+
+func NewEmptyUnaryContext() *UnaryContext {
+	var p = new(UnaryContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = QueryParserRULE_unary
+	return p
+}
+
+func (*UnaryContext) IsUnaryContext() {}
+
+func NewUnaryContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *UnaryContext {
+	var p = new(UnaryContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = QueryParserRULE_unary
+
+	return p
+}
+
+func (s *UnaryContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *UnaryContext) Key() antlr.TerminalNode {
+	return s.GetToken(QueryParserKey, 0)
+}
+
+func (s *UnaryContext) Whitespace() antlr.TerminalNode {
+	return s.GetToken(QueryParserWhitespace, 0)
+}
+
+func (s *UnaryContext) UnaryOp() antlr.TerminalNode {
+	return s.GetToken(QueryParserUnaryOp, 0)
+}
+
+func (s *UnaryContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *UnaryContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *UnaryContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(QueryListener); ok {
+		listenerT.EnterUnary(s)
+	}
+}
+
+func (s *UnaryContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(QueryListener); ok {
+		listenerT.ExitUnary(s)
+	}
+}
+
+func (p *QueryParser) Unary() (localctx IUnaryContext) {
+	localctx = NewUnaryContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 51, QueryParserRULE_unary)
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(53)
+		p.Match(QueryParserKey)
+	}
+	{
+		p.SetState(54)
+		p.Match(QueryParserWhitespace)
+	}
+	{
+		p.SetState(55)
+		p.Match(QueryParserUnaryOp)
+	}
+
+	return localctx
+}
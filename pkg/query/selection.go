@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/query/parser"
+	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 
 	"github.com/Peripli/service-manager/pkg/util"
@@ -56,6 +57,8 @@ const (
 	UnivariateOperator OperatorType = "univariate"
 	// MultivariateOperator denotes that the operator expects more than one variable on the right side
 	MultivariateOperator OperatorType = "multivariate"
+	// UnaryOperator denotes that the operator expects no variables on the right side
+	UnaryOperator OperatorType = "unary"
 )
 
 // OrderType is the type of the order in which result is presented
@@ -73,6 +76,8 @@ const (
 	OrderBy string = "orderBy"
 	// Limit should be used as a left operand in Criterion to signify the
 	Limit string = "limit"
+	// PageAfter should be used as a left operand in Criterion to select the results which are ordered after the specified values
+	PageAfter string = "pageAfter"
 )
 
 var (
@@ -82,6 +87,7 @@ var (
 		GreaterThanOperator, LessThanOperator,
 		GreaterThanOrEqualOperator, LessThanOrEqualOperator,
 		InOperator, NotInOperator, EqualsOrNilOperator,
+		ExistsOperator, NotExistsOperator,
 	}
 	// CriteriaTypes returns the supported query criteria types
 	CriteriaTypes = []CriterionType{FieldQuery, LabelQuery}
@@ -109,6 +115,9 @@ type Criterion struct {
 	RightOp []string
 	// Type is the type of the query
 	Type CriterionType
+	// ValueType is the declared type of the values of the label in a label query. The comparisons of the values
+	// of int labels are numeric.
+	ValueType types.LabelValueType
 }
 
 // ByField constructs a new criterion for field querying
@@ -132,12 +141,22 @@ func LimitResultBy(limit int) Criterion {
 	return NewCriterion(Limit, NoOperator, []string{limitString}, ResultQuery)
 }
 
+// ResultAfter constructs a new criterion which selects the results ordered after the specified values of the
+// order by fields. The values are matched with the order by criteria in the order in which they are specified.
+func ResultAfter(values ...string) Criterion {
+	return NewCriterion(PageAfter, NoOperator, values, ResultQuery)
+}
+
 func NewCriterion(leftOp string, operator Operator, rightOp []string, criteriaType CriterionType) Criterion {
 	return Criterion{LeftOp: leftOp, Operator: operator, RightOp: rightOp, Type: criteriaType}
 }
 
 // Validate the criterion fields
 func (c Criterion) Validate() error {
+	if c.Operator != nil && c.Operator.Type() == UnaryOperator {
+		return c.validateUnary()
+	}
+
 	if len(c.RightOp) == 0 {
 		return errors.New("missing right operand")
 	}
@@ -171,6 +190,14 @@ func (c Criterion) Validate() error {
 	if c.Operator.IsNumeric() && !isNumeric(c.RightOp[0]) && !isDateTime(c.RightOp[0]) {
 		return &util.UnsupportedQueryError{Message: fmt.Sprintf("%s is numeric operator, but the right operand %s is not numeric or datetime", c.Operator, c.RightOp[0])}
 	}
+	if c.Operator.IsNumeric() && c.ValueType != "" {
+		if c.ValueType != types.LabelValueInt {
+			return &util.UnsupportedQueryError{Message: fmt.Sprintf("%s is numeric operator, but label %s is of type %s", c.Operator, c.LeftOp, c.ValueType)}
+		}
+		if _, err := strconv.ParseInt(c.RightOp[0], 10, 64); err != nil {
+			return &util.UnsupportedQueryError{Message: fmt.Sprintf("label %s is of type %s, but the right operand %s is not an integer", c.LeftOp, c.ValueType, c.RightOp[0])}
+		}
+	}
 	if strings.Contains(c.LeftOp, Separator) {
 		return &util.UnsupportedQueryError{Message: fmt.Sprintf("separator %s is not allowed in %s with left operand \"%s\".", Separator, c.Type, c.LeftOp)}
 	}
@@ -182,6 +209,19 @@ func (c Criterion) Validate() error {
 	return nil
 }
 
+func (c Criterion) validateUnary() error {
+	if c.Type != LabelQuery {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("unary operator %s is supported only for label queries", c.Operator)}
+	}
+	if len(c.RightOp) != 0 {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("unary operator %s does not expect right operand but received %s", c.Operator, c.RightOp)}
+	}
+	if strings.Contains(c.LeftOp, Separator) {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("separator %s is not allowed in %s with left operand \"%s\".", Separator, c.Type, c.LeftOp)}
+	}
+	return nil
+}
+
 func validateCriteria(criteria []Criterion) error {
 	fieldQueryLeftOperands := make(map[string]int)
 	labelQueryLeftOperands := make(map[string]int)
@@ -268,6 +308,73 @@ func Parse(criterionType CriterionType, expression string) ([]Criterion, error)
 	return criteria, nil
 }
 
+// ParseOrderBy parses an order expression such as "name asc,created_at desc" and builds order by criteria.
+// The order type is optional and defaults to ascending order.
+func ParseOrderBy(expression string) ([]Criterion, error) {
+	criteria := make([]Criterion, 0)
+	if strings.TrimSpace(expression) == "" {
+		return criteria, nil
+	}
+
+	fields := make(map[string]bool)
+	for _, rule := range strings.Split(expression, ",") {
+		parts := strings.Fields(rule)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("invalid order by rule \"%s\": expected <field> [asc|desc]", strings.TrimSpace(rule))}
+		}
+		orderType := AscOrder
+		if len(parts) == 2 {
+			orderType = OrderType(strings.ToUpper(parts[1]))
+			if orderType != AscOrder && orderType != DescOrder {
+				return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported order type: %s", parts[1])}
+			}
+		}
+		if fields[parts[0]] {
+			return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("duplicate order by field: %s", parts[0])}
+		}
+		fields[parts[0]] = true
+		criteria = append(criteria, OrderResultBy(parts[0], orderType))
+	}
+	return criteria, nil
+}
+
+// Encode builds the query expression of the provided type from the criteria. It is the inverse of Parse -
+// criteria of other types are skipped.
+func Encode(criterionType CriterionType, criteria ...Criterion) string {
+	expressions := make([]string, 0, len(criteria))
+	for _, criterion := range criteria {
+		if criterion.Type != criterionType {
+			continue
+		}
+		values := make([]string, 0, len(criterion.RightOp))
+		for _, value := range criterion.RightOp {
+			values = append(values, "'"+strings.Replace(value, "'", "''", -1)+"'")
+		}
+		if criterion.Operator.Type() == UnaryOperator {
+			expressions = append(expressions, fmt.Sprintf("%s %s", criterion.LeftOp, criterion.Operator))
+			continue
+		}
+		right := strings.Join(values, ",")
+		if criterion.Operator.Type() == MultivariateOperator {
+			right = "(" + right + ")"
+		}
+		expressions = append(expressions, fmt.Sprintf("%s %s %s", criterion.LeftOp, criterion.Operator, right))
+	}
+	return strings.Join(expressions, " and ")
+}
+
+// EncodeOrderBy builds the order expression from the order by criteria. It is the inverse of ParseOrderBy.
+func EncodeOrderBy(criteria ...Criterion) string {
+	rules := make([]string, 0, len(criteria))
+	for _, criterion := range criteria {
+		if criterion.Type != ResultQuery || criterion.LeftOp != OrderBy {
+			continue
+		}
+		rules = append(rules, fmt.Sprintf("%s %s", criterion.RightOp[0], strings.ToLower(criterion.RightOp[1])))
+	}
+	return strings.Join(rules, ",")
+}
+
 // RetrieveFromCriteria searches for the value (rightOp) of a given key (leftOp) in a set of criteria
 func RetrieveFromCriteria(key string, criteria ...Criterion) string {
 	for _, criterion := range criteria {
@@ -26,6 +26,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
 )
 
 var _ = Describe("Selection", func() {
@@ -62,6 +63,22 @@ new line`))
 			Specify("Left operand with query separator", func() {
 				addInvalidCriterion(ByField(EqualsOperator, "leftop and more", "value"))
 			})
+			Specify("Unary operator applied to field query", func() {
+				addInvalidCriterion(ByField(ExistsOperator, "leftOp"))
+			})
+			Specify("Unary operator with right operand", func() {
+				addInvalidCriterion(ByLabel(NotExistsOperator, "leftOp", "value"))
+			})
+			Specify("Numeric operator applied to label which is not int", func() {
+				criterion := ByLabel(GreaterThanOperator, "leftOp", "5")
+				criterion.ValueType = types.LabelValueBool
+				addInvalidCriterion(criterion)
+			})
+			Specify("Numeric operator applied to int label with right operand which is not integer", func() {
+				criterion := ByLabel(LessThanOperator, "leftOp", "5.5")
+				criterion.ValueType = types.LabelValueInt
+				addInvalidCriterion(criterion)
+			})
 			Specify("Multiple limit criteria", func() {
 				var err error
 				ctx, err = AddCriteria(ctx, LimitResultBy(10))
@@ -80,8 +97,16 @@ new line`))
 				Expect(err).ToNot(HaveOccurred())
 			})
 			for _, op := range Operators {
-				Specify("With valid operator parameters", func() {
-					_, err := AddCriteria(ctx, ByField(op, "leftOp", "rightop"))
+				op := op
+				Specify(fmt.Sprintf("With valid %s operator parameters", op), func() {
+					criterion := ByField(op, "leftOp", "rightop")
+					if op.IsNumeric() {
+						criterion = ByField(op, "leftOp", "5")
+					}
+					if op.Type() == UnaryOperator {
+						criterion = ByLabel(op, "leftOp")
+					}
+					_, err := AddCriteria(ctx, criterion)
 					Expect(err).ToNot(HaveOccurred())
 				})
 			}
@@ -122,6 +147,75 @@ new line`))
 		})
 	})
 
+	Describe("Parse order by", func() {
+		It("builds order by criteria with ascending order by default", func() {
+			criteria, err := ParseOrderBy("name, created_at desc")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(criteria).To(Equal([]Criterion{
+				OrderResultBy("name", AscOrder),
+				OrderResultBy("created_at", DescOrder),
+			}))
+		})
+
+		It("returns no criteria for an empty expression", func() {
+			criteria, err := ParseOrderBy("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(criteria).To(BeEmpty())
+		})
+
+		DescribeTable("invalid expressions",
+			func(expression, expectedErrorMessage string) {
+				_, err := ParseOrderBy(expression)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expectedErrorMessage))
+			},
+			Entry("unknown order type", "name up", "unsupported order type: up"),
+			Entry("too many parts", "name asc desc", "expected <field> [asc|desc]"),
+			Entry("empty rule", "name,,created_at", "expected <field> [asc|desc]"),
+			Entry("duplicate field", "name asc,name desc", "duplicate order by field: name"),
+		)
+	})
+
+	Describe("Encode", func() {
+		It("builds an expression which parses back to the same criteria", func() {
+			criteria := []Criterion{
+				ByField(InOperator, "id", "1", "2"),
+				ByField(EqualsOperator, "name", "it's"),
+				ByLabel(EqualsOperator, "env", "dev"),
+			}
+			expression := Encode(FieldQuery, criteria...)
+			Expect(expression).To(Equal("id in ('1','2') and name eq 'it''s'"))
+
+			parsed, err := Parse(FieldQuery, expression)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(HaveLen(2))
+			Expect(parsed[0].LeftOp).To(Equal("id"))
+			Expect(parsed[0].Operator).To(Equal(InOperator))
+			Expect(parsed[0].RightOp).To(ConsistOf("1", "2"))
+			Expect(parsed[1]).To(Equal(criteria[1]))
+		})
+
+		It("builds unary expressions without right operand", func() {
+			criteria := []Criterion{ByLabel(ExistsOperator, "env"), ByLabel(NotExistsOperator, "tier")}
+			expression := Encode(LabelQuery, criteria...)
+			Expect(expression).To(Equal("env exists and tier notexists"))
+
+			parsed, err := Parse(LabelQuery, expression)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(ConsistOf(criteria))
+		})
+
+		It("builds an order expression which parses back to the same criteria", func() {
+			criteria := []Criterion{OrderResultBy("name", AscOrder), OrderResultBy("created_at", DescOrder)}
+			expression := EncodeOrderBy(criteria...)
+			Expect(expression).To(Equal("name asc,created_at desc"))
+
+			parsed, err := ParseOrderBy(expression)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(Equal(criteria))
+		})
+	})
+
 	Describe("Parse query", func() {
 		for _, queryType := range CriteriaTypes {
 			Context("With no query", func() {
@@ -239,8 +333,12 @@ new line`))
 								stringParam = fmt.Sprintf("('%s')", strings.Join(rightOp, "','"))
 							}
 							query := fmt.Sprintf("%s %s %s", leftOp, op, stringParam)
+							if op.Type() == UnaryOperator {
+								rightOp = []string{}
+								query = fmt.Sprintf("%s %s", leftOp, op)
+							}
 							criteria, err := Parse(queryType, query)
-							if op.IsNullable() && queryType == LabelQuery {
+							if (op.IsNullable() && queryType == LabelQuery) || (op.Type() == UnaryOperator && queryType == FieldQuery) {
 								Expect(err).To(HaveOccurred())
 								Expect(criteria).To(BeNil())
 							} else {
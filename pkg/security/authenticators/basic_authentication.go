@@ -17,8 +17,11 @@
 package authenticators
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
 	"github.com/Peripli/service-manager/api/osb"
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/pkg/query"
@@ -38,6 +41,9 @@ type BasicAuthenticatorFunc func(request *web.Request, repository storage.Reposi
 type Basic struct {
 	Repository             storage.Repository
 	BasicAuthenticatorFunc BasicAuthenticatorFunc
+	// CredentialsUsageStore records the usage of the platform credentials with which the requests are authenticated.
+	// The usage is not recorded if it is nil.
+	CredentialsUsageStore storage.CredentialsUsageStore
 }
 
 // Authenticate authenticates by using the provided Basic credentials
@@ -47,10 +53,32 @@ func (a *Basic) Authenticate(request *web.Request) (*web.UserContext, httpsec.De
 		return nil, httpsec.Abstain, nil
 	}
 
-	return a.BasicAuthenticatorFunc(request, a.Repository, username, password)
+	user, decision, err := a.BasicAuthenticatorFunc(request, a.Repository, username, password)
+	if decision == httpsec.Allow && a.CredentialsUsageStore != nil {
+		a.recordCredentialsUsage(request.Context(), user)
+	}
+	return user, decision, err
+}
+
+// credentialsUsageRecordInterval is the minimum interval in which the usage of platform credentials is recorded,
+// so that not every authenticated request results in a write to the storage
+const credentialsUsageRecordInterval = time.Minute
+
+// recordCredentialsUsage records the time in which the current or old credentials of the authenticated platform were
+// last used, so that it is known when the old credentials are no longer in use and can be revoked. Credentials which
+// do not belong to the platform, such as the broker platform credentials, are not recorded.
+func (a *Basic) recordCredentialsUsage(ctx context.Context, user *web.UserContext) {
+	platform := &types.Platform{}
+	if err := user.Data(platform); err != nil || platform.ID == "" {
+		return
+	}
+	if err := a.CredentialsUsageStore.RecordCredentialsUsage(ctx, platform.ID, user.Name, time.Now(), credentialsUsageRecordInterval); err != nil {
+		log.C(ctx).WithError(err).Warnf("Could not record usage of the credentials of platform %s", platform.ID)
+	}
 }
 
-//BasicPlatformAuthenticator attempts to authenticate basic auth requests with provided platform credentials
+//BasicPlatformAuthenticator attempts to authenticate basic auth requests with provided platform credentials.
+//The credentials replaced by a rotation are accepted until their grace period expires.
 func BasicPlatformAuthenticator(request *web.Request, repository storage.Repository, username, password string) (*web.UserContext, httpsec.Decision, error) {
 	ctx := request.Context()
 	log.C(ctx).Debugf("Attempting to authenticate platform credentials")
@@ -61,15 +89,37 @@ func BasicPlatformAuthenticator(request *web.Request, repository storage.Reposit
 		return nil, httpsec.Abstain, fmt.Errorf("could not get credentials entity from storage: %s", err)
 	}
 
+	useOldCredentials := false
 	if platformList.Len() != 1 {
-		return nil, httpsec.Deny, fmt.Errorf("provided credentials are invalid")
+		log.C(ctx).Debugf("Authenticating platform credentials failed - will try to find old credentials")
+
+		byUsername.LeftOp = "old_username"
+		platformList, err = repository.List(ctx, types.PlatformType, byUsername)
+		if err != nil {
+			return nil, httpsec.Abstain, fmt.Errorf("could not get credentials entity from storage: %s", err)
+		}
+		if platformList.Len() != 1 {
+			return nil, httpsec.Deny, fmt.Errorf("provided credentials are invalid")
+		}
+		useOldCredentials = true
 	}
 
 	platform := platformList.ItemAt(0).(*types.Platform)
-	if platform.Credentials.Basic.Password != password {
+	expectedPassword := platform.Credentials.Basic.Password
+	if useOldCredentials {
+		if !platform.HasValidOldCredentials() {
+			log.C(ctx).Infof("Rejecting expired old credentials of platform %s", platform.ID)
+			return nil, httpsec.Deny, fmt.Errorf("provided credentials are invalid")
+		}
+		expectedPassword = platform.OldCredentials.Basic.Password
+	}
+	if expectedPassword != password {
 		return nil, httpsec.Deny, fmt.Errorf("provided credentials are invalid")
 	}
 
+	if useOldCredentials {
+		log.C(ctx).Infof("Platform %s authenticated with old credentials which expire at %s", platform.ID, platform.OldCredentialsExpireAt)
+	}
 	return buildResponse(username, platform)
 }
 
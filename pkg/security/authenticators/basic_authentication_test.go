@@ -17,12 +17,15 @@
 package authenticators_test
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"github.com/Peripli/service-manager/api/osb"
 	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
 	"golang.org/x/crypto/bcrypt"
 	"net/http"
+	"time"
 
 	"github.com/Peripli/service-manager/storage/storagefakes"
 
@@ -201,6 +204,121 @@ var _ = Describe("Basic Authenticator", func() {
 						Expect(decision).To(Equal(httpsec.Allow))
 					})
 				})
+
+				Context("credentials usage", func() {
+					type usage struct {
+						platformID string
+						username   string
+						usedAt     time.Time
+						interval   time.Duration
+					}
+					var usages []usage
+					var recordErr error
+
+					BeforeEach(func() {
+						usages = nil
+						recordErr = nil
+						authenticator.CredentialsUsageStore = storage.CredentialsUsageFunc(func(ctx context.Context, platformID, username string, usedAt time.Time, interval time.Duration) error {
+							usages = append(usages, usage{platformID: platformID, username: username, usedAt: usedAt, interval: interval})
+							return recordErr
+						})
+						fakeRepository.ListReturns(&types.Platforms{Platforms: []*types.Platform{{
+							Base: types.Base{
+								ID: "id1",
+							},
+							Credentials: &types.Credentials{
+								Basic: &types.Basic{
+									Username: "username",
+									Password: "password",
+								},
+							},
+						}}}, nil)
+					})
+
+					It("records the usage of the credentials without updating the platform", func() {
+						_, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+						Expect(err).ToNot(HaveOccurred())
+						Expect(decision).To(Equal(httpsec.Allow))
+
+						Expect(usages).To(HaveLen(1))
+						Expect(usages[0].platformID).To(Equal("id1"))
+						Expect(usages[0].username).To(Equal("username"))
+						Expect(usages[0].usedAt).To(BeTemporally("~", time.Now(), time.Second))
+						Expect(usages[0].interval).To(BeNumerically(">", 0))
+						Expect(fakeRepository.UpdateCallCount()).To(Equal(0))
+					})
+
+					It("does not record the usage of denied credentials", func() {
+						fakeRepository.ListReturns(&types.Platforms{}, nil)
+
+						_, decision, _ := authenticator.Authenticate(&web.Request{Request: request})
+						Expect(decision).To(Equal(httpsec.Deny))
+						Expect(usages).To(BeEmpty())
+					})
+
+					It("allows the request if recording the usage fails", func() {
+						recordErr = fmt.Errorf("error")
+
+						_, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+						Expect(err).ToNot(HaveOccurred())
+						Expect(decision).To(Equal(httpsec.Allow))
+					})
+				})
+
+				Context("When old credentials are used", func() {
+					var platform *types.Platform
+
+					BeforeEach(func() {
+						platform = &types.Platform{
+							Base: types.Base{
+								ID: "id1",
+							},
+							Credentials: &types.Credentials{
+								Basic: &types.Basic{
+									Username: "new-username",
+									Password: "new-password",
+								},
+							},
+							OldCredentials: &types.Credentials{
+								Basic: &types.Basic{
+									Username: "username",
+									Password: "password",
+								},
+							},
+							OldCredentialsExpireAt: time.Now().Add(time.Hour),
+						}
+						fakeRepository.ListReturnsOnCall(0, &types.Platforms{}, nil)
+						fakeRepository.ListReturnsOnCall(1, &types.Platforms{Platforms: []*types.Platform{platform}}, nil)
+					})
+
+					It("Should allow them within the grace period", func() {
+						user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+						Expect(err).ToNot(HaveOccurred())
+						Expect(user).To(Not(BeNil()))
+						Expect(decision).To(Equal(httpsec.Allow))
+
+						_, _, criteria := fakeRepository.ListArgsForCall(1)
+						Expect(criteria[0].LeftOp).To(Equal("old_username"))
+					})
+
+					It("Should deny them after the grace period", func() {
+						platform.OldCredentialsExpireAt = time.Now().Add(-time.Second)
+
+						user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+						Expect(err).To(HaveOccurred())
+						Expect(user).To(BeNil())
+						Expect(decision).To(Equal(httpsec.Deny))
+					})
+
+					It("Should deny them if the password does not match", func() {
+						platform.OldCredentials.Basic.Password = "not-matching-password"
+
+						user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+						Expect(err).To(HaveOccurred())
+						Expect(user).To(BeNil())
+						Expect(decision).To(Equal(httpsec.Deny))
+					})
+				})
 			})
 
 			Context("broker platform credentials", func() {
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authenticators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+
+	httpsec "github.com/Peripli/service-manager/pkg/security/http"
+
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	platformSubjectMapping = "platform"
+	userSubjectMapping     = "user"
+)
+
+// CertificateSubjectMapping maps the subject of a client certificate to the platform or the user it authenticates
+type CertificateSubjectMapping struct {
+	Subject    string
+	PlatformID string
+	Username   string
+}
+
+// ParseCertificateSubjectMappings parses mappings in the form <platform|user>:<platform id|user name>:<certificate subject>
+// where the certificate subject is in the RFC 2253 form, e.g. platform:cf-platform-id:CN=cf,O=Example
+func ParseCertificateSubjectMappings(mappings []string) ([]*CertificateSubjectMapping, error) {
+	result := make([]*CertificateSubjectMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, ":", 3)
+		if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid client certificate subject mapping %s: expected <platform|user>:<platform id|user name>:<certificate subject>", mapping)
+		}
+
+		subjectMapping := &CertificateSubjectMapping{Subject: parts[2]}
+		switch parts[0] {
+		case platformSubjectMapping:
+			subjectMapping.PlatformID = parts[1]
+		case userSubjectMapping:
+			subjectMapping.Username = parts[1]
+		default:
+			return nil, fmt.Errorf("invalid client certificate subject mapping %s: unknown kind %s", mapping, parts[0])
+		}
+		result = append(result, subjectMapping)
+	}
+
+	return result, nil
+}
+
+// ClientCertificate for client certificate security
+type ClientCertificate struct {
+	Repository storage.Repository
+	Mappings   []*CertificateSubjectMapping
+}
+
+// Authenticate authenticates by using the subject of the verified client certificate of the request.
+// Platforms authenticated this way are treated in the same way as platforms authenticated with basic credentials.
+func (a *ClientCertificate) Authenticate(request *web.Request) (*web.UserContext, httpsec.Decision, error) {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+		return nil, httpsec.Abstain, nil
+	}
+
+	ctx := request.Context()
+	subject := request.TLS.VerifiedChains[0][0].Subject.String()
+	for _, mapping := range a.Mappings {
+		if mapping.Subject != subject {
+			continue
+		}
+
+		if mapping.PlatformID == "" {
+			log.C(ctx).Debugf("Client certificate with subject %s authenticated as user %s", subject, mapping.Username)
+			return buildClientCertificateResponse(mapping)
+		}
+
+		log.C(ctx).Debugf("Client certificate with subject %s authenticated as platform with ID %s", subject, mapping.PlatformID)
+		platform, err := a.Repository.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", mapping.PlatformID))
+		if err != nil {
+			if err == util.ErrNotFoundInStorage {
+				return nil, httpsec.Deny, fmt.Errorf("platform with ID %s mapped to client certificate subject %s not found", mapping.PlatformID, subject)
+			}
+			return nil, httpsec.Abstain, fmt.Errorf("could not get platform entity from storage: %s", err)
+		}
+
+		return buildResponse(subject, platform)
+	}
+
+	log.C(ctx).Debugf("No platform or user is mapped to client certificate subject %s", subject)
+	return nil, httpsec.Abstain, nil
+}
+
+func buildClientCertificateResponse(mapping *CertificateSubjectMapping) (*web.UserContext, httpsec.Decision, error) {
+	bytes, err := json.Marshal(map[string]string{
+		"subject": mapping.Subject,
+	})
+	if err != nil {
+		return nil, httpsec.Abstain, err
+	}
+
+	return &web.UserContext{
+		Data: func(v interface{}) error {
+			return json.Unmarshal(bytes, v)
+		},
+		AuthenticationType: web.ClientCertificate,
+		Name:               mapping.Username,
+		AccessLevel:        web.NoAccess,
+	}, httpsec.Allow, nil
+}
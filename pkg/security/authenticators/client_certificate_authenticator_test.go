@@ -0,0 +1,181 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authenticators_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/http"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+
+	"github.com/Peripli/service-manager/storage/storagefakes"
+
+	"github.com/Peripli/service-manager/pkg/security/authenticators"
+	httpsec "github.com/Peripli/service-manager/pkg/security/http"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client Certificate Authenticator", func() {
+	const (
+		platformSubject = "CN=cf-platform,O=Example"
+		userSubject     = "CN=operator,O=Example"
+	)
+
+	var request *http.Request
+	var fakeRepository *storagefakes.FakeStorage
+	var authenticator *authenticators.ClientCertificate
+
+	withClientCertificate := func(subject pkix.Name) {
+		request.TLS = &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{
+				{
+					{Subject: subject},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		request, err = http.NewRequest(http.MethodGet, "https://example.com/v1/service_brokers", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		fakeRepository = &storagefakes.FakeStorage{}
+
+		mappings, err := authenticators.ParseCertificateSubjectMappings([]string{
+			"platform:platform-id:" + platformSubject,
+			"user:operator:" + userSubject,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		authenticator = &authenticators.ClientCertificate{
+			Repository: fakeRepository,
+			Mappings:   mappings,
+		}
+	})
+
+	Describe("ParseCertificateSubjectMappings", func() {
+		Context("when the mapping kind is unknown", func() {
+			It("should return an error", func() {
+				_, err := authenticators.ParseCertificateSubjectMappings([]string{"group:admins:CN=admin"})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the certificate subject is missing", func() {
+			It("should return an error", func() {
+				_, err := authenticators.ParseCertificateSubjectMappings([]string{"user:admin"})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the certificate subject contains colons", func() {
+			It("should keep the whole subject", func() {
+				mappings, err := authenticators.ParseCertificateSubjectMappings([]string{"user:admin:CN=admin,OU=a:b"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mappings[0].Username).To(Equal("admin"))
+				Expect(mappings[0].Subject).To(Equal("CN=admin,OU=a:b"))
+			})
+		})
+	})
+
+	Describe("Authenticate", func() {
+		Context("when the request has no verified client certificate", func() {
+			It("should abstain", func() {
+				user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(user).To(BeNil())
+				Expect(decision).To(Equal(httpsec.Abstain))
+			})
+		})
+
+		Context("when no mapping matches the certificate subject", func() {
+			It("should abstain", func() {
+				withClientCertificate(pkix.Name{CommonName: "unknown", Organization: []string{"Example"}})
+				user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(user).To(BeNil())
+				Expect(decision).To(Equal(httpsec.Abstain))
+			})
+		})
+
+		Context("when the certificate subject is mapped to a user", func() {
+			It("should allow", func() {
+				withClientCertificate(pkix.Name{CommonName: "operator", Organization: []string{"Example"}})
+				user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decision).To(Equal(httpsec.Allow))
+				Expect(user.Name).To(Equal("operator"))
+				Expect(user.AuthenticationType).To(Equal(web.ClientCertificate))
+			})
+		})
+
+		Context("when the certificate subject is mapped to a platform", func() {
+			BeforeEach(func() {
+				withClientCertificate(pkix.Name{CommonName: "cf-platform", Organization: []string{"Example"}})
+			})
+
+			Context("and the platform exists", func() {
+				It("should allow as platform", func() {
+					fakeRepository.GetReturns(&types.Platform{
+						Base: types.Base{
+							ID: "platform-id",
+						},
+					}, nil)
+
+					user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(decision).To(Equal(httpsec.Allow))
+					Expect(user.AuthenticationType).To(Equal(web.Basic))
+
+					platform := &types.Platform{}
+					Expect(user.Data(platform)).To(Succeed())
+					Expect(platform.ID).To(Equal("platform-id"))
+				})
+			})
+
+			Context("and the platform does not exist", func() {
+				It("should deny", func() {
+					fakeRepository.GetReturns(nil, util.ErrNotFoundInStorage)
+
+					user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+					Expect(err).To(HaveOccurred())
+					Expect(user).To(BeNil())
+					Expect(decision).To(Equal(httpsec.Deny))
+				})
+			})
+
+			Context("and getting the platform from storage fails", func() {
+				It("should abstain with error", func() {
+					fakeRepository.GetReturns(nil, fmt.Errorf("error"))
+
+					user, decision, err := authenticator.Authenticate(&web.Request{Request: request})
+					Expect(err).To(HaveOccurred())
+					Expect(user).To(BeNil())
+					Expect(decision).To(Equal(httpsec.Abstain))
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authenticators
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	httpsec "github.com/Peripli/service-manager/pkg/security/http"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// MultiIssuerAuthenticator authenticates bearer tokens issued by any of several trusted token issuers.
+// The token is verified by the authenticator of the issuer named in its iss claim. Each issuer has its
+// own key set which is cached and refreshed when a token signed with an unknown key is presented, so
+// that key rotation of one issuer does not affect the others.
+type MultiIssuerAuthenticator struct {
+	Authenticators map[string]httpsec.Authenticator
+	// TenantClaims maps the issuers advertised by the token issuers to the claims which hold the tenant of the user
+	TenantClaims map[string]string
+}
+
+// NewMultiIssuerOIDCAuthenticator returns an authenticator which trusts the tokens of all the specified issuers
+func NewMultiIssuerOIDCAuthenticator(ctx context.Context, options []*OIDCOptions) (*MultiIssuerAuthenticator, error) {
+	if len(options) == 0 {
+		return nil, errors.New("at least one token issuer should be provided")
+	}
+
+	authenticators := make(map[string]httpsec.Authenticator, len(options))
+	tenantClaims := make(map[string]string)
+	for _, issuerOptions := range options {
+		authenticator, issuer, err := NewOIDCAuthenticator(ctx, issuerOptions)
+		if err != nil {
+			return nil, fmt.Errorf("could not setup authenticator for token issuer %s: %s", issuerOptions.IssuerURL, err)
+		}
+		if _, found := authenticators[issuer]; found {
+			return nil, fmt.Errorf("token issuer %s is configured more than once", issuer)
+		}
+		log.C(ctx).Infof("Trusting tokens issued by %s", issuer)
+		authenticators[issuer] = authenticator
+		if len(issuerOptions.TenantClaim) != 0 {
+			tenantClaims[issuer] = issuerOptions.TenantClaim
+		}
+	}
+
+	return &MultiIssuerAuthenticator{
+		Authenticators: authenticators,
+		TenantClaims:   tenantClaims,
+	}, nil
+}
+
+// Authenticate selects the authenticator of the token issuer and delegates to it
+func (a *MultiIssuerAuthenticator) Authenticate(request *web.Request) (*web.UserContext, httpsec.Decision, error) {
+	authorizationHeader := request.Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(strings.ToLower(authorizationHeader), "bearer ") {
+		return nil, httpsec.Abstain, nil
+	}
+	token := strings.TrimSpace(authorizationHeader[len("Bearer "):])
+	if token == "" {
+		return nil, httpsec.Deny, nil
+	}
+
+	issuer, err := tokenIssuer(token)
+	if err != nil {
+		return nil, httpsec.Deny, err
+	}
+	authenticator, found := a.Authenticators[issuer]
+	if !found {
+		return nil, httpsec.Deny, fmt.Errorf("token issuer %s is not trusted", issuer)
+	}
+	return authenticator.Authenticate(request)
+}
+
+// ParseScopeMappings parses scope mappings in the form <token scope>=<mapped scope>
+func ParseScopeMappings(mappings []string) (map[string]string, error) {
+	result := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid scope mapping %s: expected format is <token scope>=<mapped scope>", mapping)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
+
+// tokenIssuer extracts the iss claim of the token without verifying it. The claim is only used to select
+// the authenticator which verifies the token.
+func tokenIssuer(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token: expected 3 parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", fmt.Errorf("malformed token payload: %s", err)
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("malformed token claims: %s", err)
+	}
+	if claims.Issuer == "" {
+		return "", errors.New("token has no issuer")
+	}
+	return claims.Issuer, nil
+}
+
+// mapScopes wraps the claims of a token so that the scopes with a mapping are replaced by the mapped scopes
+func mapScopes(claimsFunc func(interface{}) error, mappings map[string]string) func(interface{}) error {
+	return func(data interface{}) error {
+		var claims map[string]interface{}
+		if err := claimsFunc(&claims); err != nil {
+			return err
+		}
+		switch scopes := claims["scope"].(type) {
+		case []interface{}:
+			mappedScopes := make([]interface{}, 0, len(scopes))
+			for _, scope := range scopes {
+				if mappedScope, found := mappings[fmt.Sprint(scope)]; found {
+					scope = mappedScope
+				}
+				mappedScopes = append(mappedScopes, scope)
+			}
+			claims["scope"] = mappedScopes
+		case string:
+			// RFC 8693 represents scopes as a space-delimited string
+			mappedScopes := strings.Fields(scopes)
+			for i, scope := range mappedScopes {
+				if mappedScope, found := mappings[scope]; found {
+					mappedScopes[i] = mappedScope
+				}
+			}
+			claims["scope"] = strings.Join(mappedScopes, " ")
+		}
+		bytes, err := json.Marshal(claims)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(bytes, data)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authenticators
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	httpsec "github.com/Peripli/service-manager/pkg/security/http"
+	"github.com/Peripli/service-manager/pkg/security/http/httpfakes"
+	"github.com/Peripli/service-manager/pkg/web"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multi issuer authenticator", func() {
+	var (
+		operatorAuthenticator *httpfakes.FakeAuthenticator
+		tenantAuthenticator   *httpfakes.FakeAuthenticator
+		authenticator         *MultiIssuerAuthenticator
+	)
+
+	tokenFor := func(claims string) string {
+		return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+	}
+
+	newRequest := func(authorization string) *web.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return &web.Request{Request: req}
+	}
+
+	BeforeEach(func() {
+		operatorAuthenticator = &httpfakes.FakeAuthenticator{}
+		operatorAuthenticator.AuthenticateReturns(&web.UserContext{Name: "operator"}, httpsec.Allow, nil)
+		tenantAuthenticator = &httpfakes.FakeAuthenticator{}
+		tenantAuthenticator.AuthenticateReturns(&web.UserContext{Name: "tenant"}, httpsec.Allow, nil)
+
+		authenticator = &MultiIssuerAuthenticator{
+			Authenticators: map[string]httpsec.Authenticator{
+				"https://operators.example.com": operatorAuthenticator,
+				"https://tenants.example.com":   tenantAuthenticator,
+			},
+		}
+	})
+
+	It("abstains when there is no bearer token", func() {
+		_, decision, err := authenticator.Authenticate(newRequest("Basic dXNlcjpwYXNz"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decision).To(Equal(httpsec.Abstain))
+	})
+
+	It("delegates to the authenticator of the token issuer", func() {
+		user, decision, err := authenticator.Authenticate(newRequest("Bearer " + tokenFor(`{"iss":"https://tenants.example.com"}`)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decision).To(Equal(httpsec.Allow))
+		Expect(user.Name).To(Equal("tenant"))
+		Expect(operatorAuthenticator.AuthenticateCallCount()).To(Equal(0))
+	})
+
+	It("denies tokens of untrusted issuers", func() {
+		_, decision, err := authenticator.Authenticate(newRequest("Bearer " + tokenFor(`{"iss":"https://evil.example.com"}`)))
+		Expect(err).To(MatchError("token issuer https://evil.example.com is not trusted"))
+		Expect(decision).To(Equal(httpsec.Deny))
+	})
+
+	It("denies malformed tokens", func() {
+		_, decision, err := authenticator.Authenticate(newRequest("Bearer not-a-token"))
+		Expect(err).To(HaveOccurred())
+		Expect(decision).To(Equal(httpsec.Deny))
+	})
+
+	It("denies tokens without issuer", func() {
+		_, decision, err := authenticator.Authenticate(newRequest("Bearer " + tokenFor(`{"sub":"user"}`)))
+		Expect(err).To(MatchError("token has no issuer"))
+		Expect(decision).To(Equal(httpsec.Deny))
+	})
+
+	Describe("ParseScopeMappings", func() {
+		It("parses valid mappings", func() {
+			mappings, err := ParseScopeMappings([]string{"admin=sm.admin", "api://sm/read=sm.read"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mappings).To(Equal(map[string]string{"admin": "sm.admin", "api://sm/read": "sm.read"}))
+		})
+
+		It("fails for invalid mappings", func() {
+			_, err := ParseScopeMappings([]string{"admin"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("mapScopes", func() {
+		claimsFunc := func(claims string) func(interface{}) error {
+			return func(data interface{}) error {
+				return json.Unmarshal([]byte(claims), data)
+			}
+		}
+
+		It("maps scopes given as an array", func() {
+			var claims struct {
+				Scopes []string `json:"scope"`
+				Zone   string   `json:"zid"`
+			}
+			err := mapScopes(claimsFunc(`{"scope":["admin","other"],"zid":"zone"}`), map[string]string{"admin": "sm.admin"})(&claims)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claims.Scopes).To(Equal([]string{"sm.admin", "other"}))
+			Expect(claims.Zone).To(Equal("zone"))
+		})
+
+		It("maps scopes given as a space-delimited string", func() {
+			var claims struct {
+				Scopes string `json:"scope"`
+			}
+			err := mapScopes(claimsFunc(`{"scope":"admin other"}`), map[string]string{"admin": "sm.admin"})(&claims)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claims.Scopes).To(Equal("sm.admin other"))
+		})
+	})
+})
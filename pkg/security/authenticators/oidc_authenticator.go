@@ -55,6 +55,15 @@ type OIDCOptions struct {
 	// ClientID is the id of the oauth client used to verify the tokens
 	ClientID string
 
+	// Issuer is the expected issuer of the tokens. If one is not provided, the issuer advertised by the token issuer is used
+	Issuer string
+
+	// TenantClaim is the claim of the tokens which holds the tenant of the user. It is empty if the tokens are not issued for tenants
+	TenantClaim string
+
+	// ScopeMappings maps scopes of the tokens to the scopes which are presented to the authorizers
+	ScopeMappings map[string]string
+
 	// ReadConfigurationFunc is the function used to call the token issuer. If one is not provided, http.DefaultClient.Do will be used
 	ReadConfigurationFunc util.DoRequestFunc
 }
@@ -70,7 +79,8 @@ func (v *oidcVerifier) Verify(ctx context.Context, idToken string) (httpsec.Toke
 
 // OauthAuthenticator is the OpenID implementation of security.Authenticator
 type OauthAuthenticator struct {
-	Verifier httpsec.TokenVerifier
+	Verifier      httpsec.TokenVerifier
+	ScopeMappings map[string]string
 }
 
 // NewOIDCAuthenticator returns a new OpenID authenticator or an error if one couldn't be configured
@@ -91,11 +101,17 @@ func NewOIDCAuthenticator(ctx context.Context, options *OIDCOptions) (httpsec.Au
 	if err = util.BodyToObject(resp.Body, &p); err != nil {
 		return nil, "", fmt.Errorf("error decoding body of response with status %s: %s", resp.Status, err.Error())
 	}
+	if options.Issuer != "" && options.Issuer != p.Issuer {
+		return nil, "", fmt.Errorf("token issuer %s advertises issuer %s instead of the expected %s", options.IssuerURL, p.Issuer, options.Issuer)
+	}
 
 	keySet := goidc.NewRemoteKeySet(ctx, p.JWKSURL)
-	return &OauthAuthenticator{Verifier: &oidcVerifier{
-		IDTokenVerifier: goidc.NewVerifier(p.Issuer, keySet, newOIDCConfig(options)),
-	}}, p.Issuer, nil
+	return &OauthAuthenticator{
+		Verifier: &oidcVerifier{
+			IDTokenVerifier: goidc.NewVerifier(p.Issuer, keySet, newOIDCConfig(options)),
+		},
+		ScopeMappings: options.ScopeMappings,
+	}, p.Issuer, nil
 }
 
 func newOIDCConfig(options *OIDCOptions) *goidc.Config {
@@ -126,8 +142,12 @@ func (a *OauthAuthenticator) Authenticate(request *web.Request) (*web.UserContex
 	if err := idToken.Claims(claims); err != nil {
 		return nil, httpsec.Deny, err
 	}
+	data := idToken.Claims
+	if len(a.ScopeMappings) != 0 {
+		data = mapScopes(idToken.Claims, a.ScopeMappings)
+	}
 	return &web.UserContext{
-		Data:               idToken.Claims,
+		Data:               data,
 		AuthenticationType: web.Bearer,
 		Name:               claims.Username,
 		AccessLevel:        web.NoAccess,
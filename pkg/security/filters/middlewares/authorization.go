@@ -48,18 +48,21 @@ func (m *Authorization) Run(request *web.Request, next web.Handler) (*web.Respon
 	}
 
 	if decision == http.Allow {
+		// the authorizer may have extended the request context, e.g. with criteria restricting the accessible resources
+		ctx = request.Context()
 		userContext, found := web.UserFromContext(ctx)
 		if !found {
 			return nil, fmt.Errorf("authorization failed due to missing user context")
 		}
 		userContext.AccessLevel = accessLevel
-		request.Request = request.WithContext(web.ContextWithUser(ctx, userContext))
+		ctx = web.ContextWithUser(ctx, userContext)
 		if accessLevel == web.NoAccess {
 			return nil, fmt.Errorf("authorization failed due to missing access level. Authorizer that allows access should also specify the access level")
 		}
 		if !web.IsAuthorized(ctx) {
-			request.Request = request.WithContext(web.ContextWithAuthorization(ctx))
+			ctx = web.ContextWithAuthorization(ctx)
 		}
+		request.Request = request.WithContext(ctx)
 	}
 
 	return next.Handle(request)
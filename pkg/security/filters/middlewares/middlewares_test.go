@@ -24,6 +24,7 @@ import (
 
 	httpsec "github.com/Peripli/service-manager/pkg/security/http"
 
+	"github.com/Peripli/service-manager/pkg/query"
 	"github.com/Peripli/service-manager/pkg/security"
 	"github.com/Peripli/service-manager/pkg/security/http/httpfakes"
 	"github.com/Peripli/service-manager/pkg/web"
@@ -148,6 +149,34 @@ var _ = Describe("Middlewares", func() {
 								testCase(web.NoAccess, web.AllTenantAccess, web.AllTenantAccess, 1, expectedErrorMessage, true)
 							})
 						})
+
+						Context("when the authorizer extends the request context", func() {
+							It("should pass the extended context to the next handler", func() {
+								req.Request = req.WithContext(web.ContextWithUser(context.Background(), &web.UserContext{
+									Name:               "test-user",
+									AuthenticationType: web.Bearer,
+								}))
+								criterion := query.ByLabel(query.EqualsOperator, "team", "a")
+								authorizer.AuthorizeStub = func(request *web.Request) (httpsec.Decision, web.AccessLevel, error) {
+									ctx, err := query.AddCriteria(request.Context(), criterion)
+									Expect(err).ToNot(HaveOccurred())
+									request.Request = request.WithContext(ctx)
+									return httpsec.Allow, web.GlobalAccess, nil
+								}
+								authzFilter := Authorization{
+									Authorizer: authorizer,
+								}
+								_, err := authzFilter.Run(req, handler)
+								Expect(err).ToNot(HaveOccurred())
+
+								req := handler.HandleArgsForCall(0)
+								Expect(query.CriteriaForContext(req.Context())).To(ConsistOf(criterion))
+								Expect(web.IsAuthorized(req.Context())).To(BeTrue())
+								userContext, found := web.UserFromContext(req.Context())
+								Expect(found).To(BeTrue())
+								Expect(userContext.AccessLevel).To(Equal(web.GlobalAccess))
+							})
+						})
 					})
 				})
 			})
@@ -0,0 +1,339 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	httpsec "github.com/Peripli/service-manager/pkg/security/http"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/tidwall/gjson"
+)
+
+const apiPathPrefix = "/v1/"
+
+// adminResourceTypes are the resource types which can be modified only through roles with global access,
+// as their changes affect all tenants
+var adminResourceTypes = []types.ObjectType{types.ObjectType(web.ConfigURL)}
+
+// unlabeledResourceTypes are the resource types which have no labels, so permissions restricted by a
+// label selector cannot grant access to them
+var unlabeledResourceTypes = []types.ObjectType{types.ObjectType(web.ConfigURL), types.ObjectType(web.ProfileURL)}
+
+// RoleProvider provides the roles evaluated by the RBAC authorizer
+type RoleProvider interface {
+	Roles(ctx context.Context) ([]*types.Role, error)
+}
+
+// RoleProviderFunc is an adapter that allows the use of ordinary functions as RoleProvider
+type RoleProviderFunc func(ctx context.Context) ([]*types.Role, error)
+
+// Roles implements RoleProvider
+func (f RoleProviderFunc) Roles(ctx context.Context) ([]*types.Role, error) {
+	return f(ctx)
+}
+
+// NewRBACAuthorizer returns an authorizer that grants access to the users which are bound to a role
+// with a permission for the requested resource type and action. Permissions restricted by a label selector
+// narrow down the resources which the request can see or modify.
+//
+// Roles are bound to token claims, so only requests authenticated with a bearer token are evaluated. Requests
+// authenticated by other means (platform credentials, client certificates) are governed by the authentication
+// that admitted them and are allowed without widening the access level already decided for them, so that they
+// remain scoped to their tenant.
+func NewRBACAuthorizer(provider RoleProvider) httpsec.Authorizer {
+	return &rbacAuthorizer{
+		provider: provider,
+	}
+}
+
+type rbacAuthorizer struct {
+	provider RoleProvider
+}
+
+// Authorize implements httpsec.Authorizer
+func (a *rbacAuthorizer) Authorize(request *web.Request) (httpsec.Decision, web.AccessLevel, error) {
+	ctx := request.Context()
+	logger := log.C(ctx)
+
+	user, ok := web.UserFromContext(ctx)
+	if !ok {
+		return httpsec.Abstain, web.NoAccess, nil
+	}
+
+	if user.AuthenticationType != web.Bearer {
+		if user.AccessLevel == web.NoAccess {
+			return httpsec.Allow, web.TenantAccess, nil
+		}
+		return httpsec.Allow, user.AccessLevel, nil
+	}
+
+	resourceType, action, subresource, ok := resolveAction(request)
+	if !ok {
+		return httpsec.Abstain, web.NoAccess, nil
+	}
+
+	var claims json.RawMessage
+	if err := user.Data(&claims); err != nil {
+		return httpsec.Deny, web.NoAccess, fmt.Errorf("could not extract claims from token: %v", err)
+	}
+
+	roles, err := a.provider.Roles(ctx)
+	if err != nil {
+		return httpsec.Abstain, web.NoAccess, fmt.Errorf("could not fetch roles: %v", err)
+	}
+
+	level := web.NoAccess
+	restricted := true
+	selectors := make([][]query.Criterion, 0)
+	for _, role := range roles {
+		if !isBound(role, claims) {
+			continue
+		}
+		for _, permission := range role.Permissions {
+			if !permission.Grants(resourceType, action) {
+				continue
+			}
+			if permission.LabelSelector != "" && containsType(unlabeledResourceTypes, resourceType) {
+				continue
+			}
+			if roleLevel := roleAccessLevel(role); roleLevel > level {
+				level = roleLevel
+			}
+			if permission.LabelSelector == "" {
+				restricted = false
+				continue
+			}
+			criteria, err := query.Parse(query.LabelQuery, permission.LabelSelector)
+			if err != nil {
+				return httpsec.Deny, web.NoAccess, fmt.Errorf("invalid label selector in role %s: %v", role.Name, err)
+			}
+			selectors = append(selectors, criteria)
+		}
+	}
+
+	if level == web.NoAccess {
+		return httpsec.Deny, web.NoAccess, fmt.Errorf("none of the roles of user %s grants %s on %s", user.Name, action, resourceType)
+	}
+
+	if level != web.GlobalAccess && isWrite(action) && containsType(adminResourceTypes, resourceType) {
+		return httpsec.Deny, web.NoAccess, fmt.Errorf("%s on %s requires a role with global access", action, resourceType)
+	}
+
+	if !restricted {
+		logger.Debugf("User %s is granted %s on %s", user.Name, action, resourceType)
+		return httpsec.Allow, level, nil
+	}
+
+	if subresource {
+		return httpsec.Deny, web.NoAccess, fmt.Errorf("the roles of user %s grant %s on %s only for resources matching a label selector", user.Name, action, resourceType)
+	}
+
+	criteria := mergeSelectors(selectors)
+	if action == types.ActionCreate {
+		if !labelsSatisfy(request.Body, criteria) {
+			return httpsec.Deny, web.NoAccess, fmt.Errorf("the roles of user %s do not grant %s on %s with the requested labels", user.Name, action, resourceType)
+		}
+		return httpsec.Allow, level, nil
+	}
+
+	ctx, err = query.AddCriteria(ctx, criteria...)
+	if err != nil {
+		return httpsec.Deny, web.NoAccess, fmt.Errorf("could not apply label selector of the roles of user %s: %v", user.Name, err)
+	}
+	// updates are checked against the selector once the label changes are applied, so that they cannot move
+	// resources out of the scope of the user
+	ctx = context.WithValue(ctx, labelSelectorKey{}, criteria)
+	request.Request = request.WithContext(ctx)
+
+	logger.Debugf("User %s is granted %s on %s restricted by %v", user.Name, action, resourceType, criteria)
+	return httpsec.Allow, level, nil
+}
+
+// resolveAction maps the request to the resource type and action it performs. Paths deeper than the
+// resource itself (for example resource operations) are reported as subresource requests.
+func resolveAction(request *web.Request) (types.ObjectType, types.Action, bool, bool) {
+	if !strings.HasPrefix(request.URL.Path, apiPathPrefix) {
+		return "", "", false, false
+	}
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(request.URL.Path, apiPathPrefix), "/"), "/")
+	if segments[0] == "" {
+		return "", "", false, false
+	}
+	resourceType := types.ObjectType(apiPathPrefix + segments[0])
+
+	var action types.Action
+	switch request.Method {
+	case http.MethodGet:
+		action = types.ActionGet
+		if len(segments) == 1 {
+			action = types.ActionList
+		}
+	case http.MethodPost:
+		action = types.ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		action = types.ActionUpdate
+	case http.MethodDelete:
+		action = types.ActionDelete
+	default:
+		return "", "", false, false
+	}
+
+	return resourceType, action, len(segments) > 2, true
+}
+
+// isBound checks whether any of the role bindings matches the token claims
+func isBound(role *types.Role, claims json.RawMessage) bool {
+	for _, binding := range role.Bindings {
+		claim := gjson.GetBytes(claims, binding.Claim)
+		if claim.IsArray() {
+			for _, value := range claim.Array() {
+				if value.String() == binding.Value {
+					return true
+				}
+			}
+			continue
+		}
+		if claim.Exists() && claim.String() == binding.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func isWrite(action types.Action) bool {
+	return action != types.ActionGet && action != types.ActionList
+}
+
+func containsType(resourceTypes []types.ObjectType, resourceType types.ObjectType) bool {
+	for _, t := range resourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+func roleAccessLevel(role *types.Role) web.AccessLevel {
+	switch role.AccessLevel {
+	case types.RoleGlobalAccess:
+		return web.GlobalAccess
+	case types.RoleAllTenantsAccess:
+		return web.AllTenantAccess
+	default:
+		return web.TenantAccess
+	}
+}
+
+// mergeSelectors combines the label selectors of several matching permissions. Selectors on the same label
+// using eq or in are merged into a single in criterion. As criteria can only be conjunctive, other combinations
+// fall back to the selector of the first matching permission, which may be more restrictive than necessary
+// but never grants more than a single permission does.
+func mergeSelectors(selectors [][]query.Criterion) []query.Criterion {
+	first := selectors[0]
+	if len(first) != 1 || !isEqualityCriterion(first[0]) {
+		return first
+	}
+
+	key := first[0].LeftOp
+	values := make([]string, 0)
+	for _, selector := range selectors {
+		if len(selector) != 1 || !isEqualityCriterion(selector[0]) || selector[0].LeftOp != key {
+			return first
+		}
+		values = appendMissing(values, selector[0].RightOp...)
+	}
+	return []query.Criterion{query.ByLabel(query.InOperator, key, values...)}
+}
+
+func isEqualityCriterion(criterion query.Criterion) bool {
+	return criterion.Operator == query.EqualsOperator || criterion.Operator == query.InOperator
+}
+
+func appendMissing(values []string, newValues ...string) []string {
+	for _, newValue := range newValues {
+		found := false
+		for _, value := range values {
+			if value == newValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, newValue)
+		}
+	}
+	return values
+}
+
+type labelSelectorKey struct{}
+
+// LabelSelectorFromContext returns the label criteria to which the roles of the user restrict the requested
+// resources, if the roles restrict them
+func LabelSelectorFromContext(ctx context.Context) ([]query.Criterion, bool) {
+	criteria, ok := ctx.Value(labelSelectorKey{}).([]query.Criterion)
+	return criteria, ok
+}
+
+// LabelsSatisfy checks whether the labels match the label criteria of a label selector
+func LabelsSatisfy(labels types.Labels, criteria []query.Criterion) bool {
+	for _, criterion := range criteria {
+		values := labels[criterion.LeftOp]
+		switch criterion.Operator {
+		case query.EqualsOperator, query.InOperator:
+			if !containsAny(values, criterion.RightOp) {
+				return false
+			}
+		case query.NotEqualsOperator, query.NotInOperator:
+			if containsAny(values, criterion.RightOp) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// labelsSatisfy checks whether the labels in the request body match the label criteria
+func labelsSatisfy(body []byte, criteria []query.Criterion) bool {
+	labels := types.Labels{}
+	for key, values := range gjson.GetBytes(body, "labels").Map() {
+		for _, value := range values.Array() {
+			labels[key] = append(labels[key], value.String())
+		}
+	}
+	return LabelsSatisfy(labels, criteria)
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
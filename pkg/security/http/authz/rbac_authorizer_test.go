@@ -0,0 +1,213 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	httpsec "github.com/Peripli/service-manager/pkg/security/http"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RBAC Authorizer", func() {
+	var (
+		roles       []*types.Role
+		providerErr error
+		authorizer  httpsec.Authorizer
+	)
+
+	newRequest := func(method, path, body, claims string, authenticationType web.AuthenticationType) *web.Request {
+		req := httptest.NewRequest(method, path, nil)
+		user := &web.UserContext{
+			Data: func(data interface{}) error {
+				return json.Unmarshal([]byte(claims), data)
+			},
+			AuthenticationType: authenticationType,
+			Name:               "test",
+		}
+		return &web.Request{
+			Request: req.WithContext(web.ContextWithUser(req.Context(), user)),
+			Body:    []byte(body),
+		}
+	}
+
+	BeforeEach(func() {
+		providerErr = nil
+		roles = []*types.Role{
+			{
+				Name: "instance-admin",
+				Permissions: []*types.Permission{
+					{ResourceType: types.ServiceInstanceType, Actions: []types.Action{types.ActionAll}},
+				},
+				Bindings: []*types.RoleBinding{{Claim: "groups", Value: "admins"}},
+			},
+			{
+				Name:        "global-viewer",
+				AccessLevel: types.RoleGlobalAccess,
+				Permissions: []*types.Permission{
+					{ResourceType: types.AnyResourceType, Actions: []types.Action{types.ActionGet, types.ActionList}},
+				},
+				Bindings: []*types.RoleBinding{{Claim: "user_name", Value: "auditor"}},
+			},
+			{
+				Name: "team-a",
+				Permissions: []*types.Permission{
+					{ResourceType: types.ServiceInstanceType, Actions: []types.Action{types.ActionList, types.ActionCreate, types.ActionUpdate}, LabelSelector: "team eq 'a'"},
+				},
+				Bindings: []*types.RoleBinding{{Claim: "groups", Value: "team-a"}},
+			},
+			{
+				Name: "team-b",
+				Permissions: []*types.Permission{
+					{ResourceType: types.ServiceInstanceType, Actions: []types.Action{types.ActionList}, LabelSelector: "team in ('b', 'c')"},
+				},
+				Bindings: []*types.RoleBinding{{Claim: "groups", Value: "team-b"}},
+			},
+		}
+		authorizer = NewRBACAuthorizer(RoleProviderFunc(func(ctx context.Context) ([]*types.Role, error) {
+			return roles, providerErr
+		}))
+	})
+
+	It("abstains if no user is authenticated", func() {
+		req := httptest.NewRequest(http.MethodGet, web.ServiceInstancesURL, nil)
+		assertAuthorizer(authorizer, &web.Request{Request: req}, "", httpsec.Abstain, web.NoAccess)
+	})
+
+	It("allows users which are not authenticated with a bearer token without widening their access level", func() {
+		req := newRequest(http.MethodDelete, web.ServiceInstancesURL+"/id", "", "", web.Basic)
+		assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+
+		req = newRequest(http.MethodDelete, web.ServiceInstancesURL+"/id", "", "", web.ClientCertificate)
+		user, _ := web.UserFromContext(req.Context())
+		user.AccessLevel = web.AllTenantAccess
+		req.Request = req.WithContext(web.ContextWithUser(req.Context(), user))
+		assertAuthorizer(authorizer, req, "", httpsec.Allow, web.AllTenantAccess)
+	})
+
+	It("fails if roles cannot be fetched", func() {
+		providerErr = errors.New("storage error")
+		req := newRequest(http.MethodGet, web.ServiceInstancesURL, "", `{"groups":["admins"]}`, web.Bearer)
+		assertAuthorizer(authorizer, req, "could not fetch roles", httpsec.Abstain, web.NoAccess)
+	})
+
+	It("denies users which are not bound to any role", func() {
+		req := newRequest(http.MethodGet, web.ServiceInstancesURL, "", `{"groups":["developers"]}`, web.Bearer)
+		assertAuthorizer(authorizer, req, "none of the roles of user test grants list on /v1/service_instances", httpsec.Deny, web.NoAccess)
+	})
+
+	It("denies actions which are not granted", func() {
+		req := newRequest(http.MethodDelete, web.ServiceBrokersURL+"/id", "", `{"user_name":"auditor"}`, web.Bearer)
+		assertAuthorizer(authorizer, req, "grants delete on /v1/service_brokers", httpsec.Deny, web.NoAccess)
+	})
+
+	It("allows actions granted through an array claim", func() {
+		req := newRequest(http.MethodDelete, web.ServiceInstancesURL+"/id", "", `{"groups":["developers","admins"]}`, web.Bearer)
+		assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+		Expect(query.CriteriaForContext(req.Context())).To(BeEmpty())
+	})
+
+	It("allows actions granted on any resource type with the access level of the role", func() {
+		req := newRequest(http.MethodGet, web.PlatformsURL+"/id", "", `{"user_name":"auditor"}`, web.Bearer)
+		assertAuthorizer(authorizer, req, "", httpsec.Allow, web.GlobalAccess)
+	})
+
+	Context("when the resource type affects all tenants", func() {
+		BeforeEach(func() {
+			roles = append(roles, &types.Role{
+				Name:        "operator",
+				AccessLevel: types.RoleGlobalAccess,
+				Permissions: []*types.Permission{
+					{ResourceType: types.ObjectType(web.ConfigURL), Actions: []types.Action{types.ActionAll}},
+				},
+				Bindings: []*types.RoleBinding{{Claim: "groups", Value: "operators"}},
+			}, &types.Role{
+				Name: "config-editor",
+				Permissions: []*types.Permission{
+					{ResourceType: types.ObjectType(web.ConfigURL), Actions: []types.Action{types.ActionAll}},
+				},
+				Bindings: []*types.RoleBinding{{Claim: "groups", Value: "editors"}},
+			}, &types.Role{
+				Name: "team-a-config",
+				Permissions: []*types.Permission{
+					{ResourceType: types.ObjectType(web.ConfigURL), Actions: []types.Action{types.ActionAll}, LabelSelector: "team eq 'a'"},
+				},
+				Bindings: []*types.RoleBinding{{Claim: "groups", Value: "team-a"}},
+			})
+		})
+
+		It("allows changes through roles with global access", func() {
+			req := newRequest(http.MethodPut, web.MaintenanceConfigURL, "", `{"groups":["operators"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "", httpsec.Allow, web.GlobalAccess)
+		})
+
+		It("denies changes through roles without global access", func() {
+			req := newRequest(http.MethodPut, web.MaintenanceConfigURL, "", `{"groups":["editors"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "update on /v1/config requires a role with global access", httpsec.Deny, web.NoAccess)
+		})
+
+		It("allows reads through roles without global access", func() {
+			req := newRequest(http.MethodGet, web.MaintenanceConfigURL, "", `{"groups":["editors"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+		})
+
+		It("ignores permissions restricted by a label selector", func() {
+			req := newRequest(http.MethodGet, web.ConfigURL, "", `{"groups":["team-a"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "none of the roles of user test grants list on /v1/config", httpsec.Deny, web.NoAccess)
+		})
+	})
+
+	Context("when the permission has a label selector", func() {
+		It("restricts the request to the matching resources", func() {
+			req := newRequest(http.MethodGet, web.ServiceInstancesURL, "", `{"groups":["team-a"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+			Expect(query.CriteriaForContext(req.Context())).To(ConsistOf(query.ByLabel(query.InOperator, "team", "a")))
+		})
+
+		It("merges the selectors of several roles", func() {
+			req := newRequest(http.MethodGet, web.ServiceInstancesURL, "", `{"groups":["team-a","team-b"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+			criteria := query.CriteriaForContext(req.Context())
+			Expect(criteria).To(HaveLen(1))
+			Expect(criteria[0].LeftOp).To(Equal("team"))
+			Expect(criteria[0].Operator).To(Equal(query.InOperator))
+			Expect(criteria[0].RightOp).To(ConsistOf("a", "b", "c"))
+		})
+
+		It("does not restrict the request if another permission has no selector", func() {
+			req := newRequest(http.MethodGet, web.ServiceInstancesURL, "", `{"groups":["team-a","admins"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+			Expect(query.CriteriaForContext(req.Context())).To(BeEmpty())
+		})
+
+		It("allows creation of resources with matching labels", func() {
+			req := newRequest(http.MethodPost, web.ServiceInstancesURL, `{"labels":{"team":["a"]}}`, `{"groups":["team-a"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+		})
+
+		It("denies creation of resources without matching labels", func() {
+			req := newRequest(http.MethodPost, web.ServiceInstancesURL, `{"labels":{"team":["b"]}}`, `{"groups":["team-a"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "with the requested labels", httpsec.Deny, web.NoAccess)
+		})
+
+		It("keeps the selector for checking the result of updates", func() {
+			req := newRequest(http.MethodPatch, web.ServiceInstancesURL+"/id", `{}`, `{"groups":["team-a"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "", httpsec.Allow, web.TenantAccess)
+			selector, restricted := LabelSelectorFromContext(req.Context())
+			Expect(restricted).To(BeTrue())
+			Expect(LabelsSatisfy(types.Labels{"team": {"a"}}, selector)).To(BeTrue())
+			Expect(LabelsSatisfy(types.Labels{"team": {"b"}}, selector)).To(BeFalse())
+		})
+
+		It("denies requests for subresources", func() {
+			req := newRequest(http.MethodPost, web.ServiceInstancesURL+"/id/operations/op", "", `{"groups":["team-a"]}`, web.Bearer)
+			assertAuthorizer(authorizer, req, "only for resources matching a label selector", httpsec.Deny, web.NoAccess)
+		})
+	})
+})
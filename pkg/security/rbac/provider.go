@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rbac
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/security/http/authz"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// NewRoleProvider returns a role provider which combines the static roles with the roles managed through
+// the API. The latter are loaded from the repository at most once per refresh interval.
+func NewRoleProvider(repository storage.Repository, staticRoles []*types.Role, refreshInterval time.Duration) authz.RoleProvider {
+	return &roleProvider{
+		repository:      repository,
+		staticRoles:     staticRoles,
+		refreshInterval: refreshInterval,
+	}
+}
+
+type roleProvider struct {
+	repository      storage.Repository
+	staticRoles     []*types.Role
+	refreshInterval time.Duration
+
+	mutex       sync.Mutex
+	storedRoles []*types.Role
+	loadedAt    time.Time
+}
+
+// Roles implements authz.RoleProvider
+func (p *roleProvider) Roles(ctx context.Context) ([]*types.Role, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.storedRoles == nil || time.Since(p.loadedAt) >= p.refreshInterval {
+		objectList, err := p.repository.List(ctx, types.RoleType)
+		if err != nil {
+			return nil, err
+		}
+		storedRoles := make([]*types.Role, 0, objectList.Len())
+		for i := 0; i < objectList.Len(); i++ {
+			storedRoles = append(storedRoles, objectList.ItemAt(i).(*types.Role))
+		}
+		log.C(ctx).Debugf("Loaded %d roles from storage", len(storedRoles))
+		p.storedRoles = storedRoles
+		p.loadedAt = time.Now()
+	}
+
+	roles := make([]*types.Role, 0, len(p.staticRoles)+len(p.storedRoles))
+	roles = append(roles, p.staticRoles...)
+	return append(roles, p.storedRoles...), nil
+}
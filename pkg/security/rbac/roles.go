@@ -0,0 +1,139 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rbac
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+type permissionDefinition struct {
+	ResourceType  string   `yaml:"resource_type"`
+	Actions       []string `yaml:"actions"`
+	LabelSelector string   `yaml:"label_selector"`
+}
+
+type bindingDefinition struct {
+	Claim string `yaml:"claim"`
+	Value string `yaml:"value"`
+}
+
+type roleDefinition struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	AccessLevel string                 `yaml:"access_level"`
+	Permissions []permissionDefinition `yaml:"permissions"`
+	Bindings    []bindingDefinition    `yaml:"bindings"`
+}
+
+// LoadRoles reads the roles defined in the YAML file with the given path. The file contains a list of roles
+// under the roles key, for example:
+//
+//	roles:
+//	- name: team-a-developer
+//	  access_level: tenant
+//	  permissions:
+//	  - resource_type: /v1/service_instances
+//	    actions: [get, list, create, update, delete]
+//	    label_selector: team eq 'a'
+//	  bindings:
+//	  - claim: groups
+//	    value: team-a
+func LoadRoles(path string) ([]*types.Role, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read roles file %s: %s", path, err)
+	}
+
+	var file struct {
+		Roles []roleDefinition `yaml:"roles"`
+	}
+	if err := yaml.UnmarshalStrict(bytes, &file); err != nil {
+		return nil, fmt.Errorf("could not parse roles file %s: %s", path, err)
+	}
+
+	roles := make([]*types.Role, 0, len(file.Roles))
+	names := make(map[string]bool)
+	for _, definition := range file.Roles {
+		role := definition.toRole()
+		if err := ValidateRole(role); err != nil {
+			return nil, fmt.Errorf("invalid role in roles file %s: %s", path, err)
+		}
+		if names[role.Name] {
+			return nil, fmt.Errorf("duplicate role %s in roles file %s", role.Name, path)
+		}
+		names[role.Name] = true
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// ValidateRole verifies that the role is valid and that its label selectors can be parsed
+func ValidateRole(role *types.Role) error {
+	if err := role.Validate(); err != nil {
+		return err
+	}
+	for _, permission := range role.Permissions {
+		if err := ValidateLabelSelector(permission.LabelSelector); err != nil {
+			return fmt.Errorf("invalid label selector for %s in role %s: %s", permission.ResourceType, role.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateLabelSelector verifies that the label selector is a valid label query
+func ValidateLabelSelector(selector string) error {
+	_, err := query.Parse(query.LabelQuery, selector)
+	return err
+}
+
+func (d roleDefinition) toRole() *types.Role {
+	role := &types.Role{
+		Base: types.Base{
+			ID:     d.Name,
+			Labels: types.Labels{},
+			Ready:  true,
+		},
+		Name:        d.Name,
+		Description: d.Description,
+		AccessLevel: d.AccessLevel,
+		Permissions: make([]*types.Permission, 0, len(d.Permissions)),
+		Bindings:    make([]*types.RoleBinding, 0, len(d.Bindings)),
+	}
+	for _, permission := range d.Permissions {
+		actions := make([]types.Action, 0, len(permission.Actions))
+		for _, action := range permission.Actions {
+			actions = append(actions, types.Action(action))
+		}
+		role.Permissions = append(role.Permissions, &types.Permission{
+			ResourceType:  types.ObjectType(permission.ResourceType),
+			Actions:       actions,
+			LabelSelector: permission.LabelSelector,
+		})
+	}
+	for _, binding := range d.Bindings {
+		role.Bindings = append(role.Bindings, &types.RoleBinding{
+			Claim: binding.Claim,
+			Value: binding.Value,
+		})
+	}
+	return role
+}
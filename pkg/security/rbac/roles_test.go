@@ -0,0 +1,170 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rbac
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage/storagefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Roles", func() {
+	Describe("LoadRoles", func() {
+		var path string
+
+		writeRoles := func(content string) {
+			f, err := ioutil.TempFile("", "roles")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = f.WriteString(content)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+			path = f.Name()
+		}
+
+		AfterEach(func() {
+			os.Remove(path)
+		})
+
+		It("loads the roles defined in the file", func() {
+			writeRoles(`
+roles:
+- name: team-a-developer
+  access_level: tenant
+  permissions:
+  - resource_type: /v1/service_instances
+    actions: [get, list]
+    label_selector: team eq 'a'
+  bindings:
+  - claim: groups
+    value: team-a
+`)
+			roles, err := LoadRoles(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roles).To(HaveLen(1))
+			Expect(roles[0].Name).To(Equal("team-a-developer"))
+			Expect(roles[0].AccessLevel).To(Equal(types.RoleTenantAccess))
+			Expect(roles[0].Permissions).To(ConsistOf(&types.Permission{
+				ResourceType:  types.ServiceInstanceType,
+				Actions:       []types.Action{types.ActionGet, types.ActionList},
+				LabelSelector: "team eq 'a'",
+			}))
+			Expect(roles[0].Bindings).To(ConsistOf(&types.RoleBinding{Claim: "groups", Value: "team-a"}))
+		})
+
+		It("fails if the file does not exist", func() {
+			path = "missing-roles.yml"
+			_, err := LoadRoles(path)
+			Expect(err).To(MatchError(ContainSubstring("could not read roles file")))
+		})
+
+		It("fails if the file contains unknown properties", func() {
+			writeRoles(`
+roles:
+- name: viewer
+  permission: []
+`)
+			_, err := LoadRoles(path)
+			Expect(err).To(MatchError(ContainSubstring("could not parse roles file")))
+		})
+
+		It("fails if a role has an unsupported action", func() {
+			writeRoles(`
+roles:
+- name: viewer
+  permissions:
+  - resource_type: /v1/platforms
+    actions: [read]
+`)
+			_, err := LoadRoles(path)
+			Expect(err).To(MatchError(ContainSubstring("unsupported action read")))
+		})
+
+		It("fails if a role has an invalid label selector", func() {
+			writeRoles(`
+roles:
+- name: viewer
+  permissions:
+  - resource_type: /v1/platforms
+    actions: [get]
+    label_selector: team eqq 'a'
+`)
+			_, err := LoadRoles(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid label selector for /v1/platforms in role viewer")))
+		})
+
+		It("fails if a role is defined twice", func() {
+			writeRoles(`
+roles:
+- name: viewer
+  permissions:
+  - resource_type: /v1/platforms
+    actions: [get]
+- name: viewer
+  permissions:
+  - resource_type: /v1/platforms
+    actions: [list]
+`)
+			_, err := LoadRoles(path)
+			Expect(err).To(MatchError(ContainSubstring("duplicate role viewer")))
+		})
+	})
+
+	Describe("RoleProvider", func() {
+		var (
+			repository  *storagefakes.FakeStorage
+			staticRole  *types.Role
+			storedRoles *types.Roles
+		)
+
+		BeforeEach(func() {
+			repository = &storagefakes.FakeStorage{}
+			staticRole = &types.Role{Name: "static"}
+			storedRoles = &types.Roles{Roles: []*types.Role{{Name: "stored"}}}
+			repository.ListReturns(storedRoles, nil)
+		})
+
+		It("returns the static and the stored roles", func() {
+			provider := NewRoleProvider(repository, []*types.Role{staticRole}, time.Hour)
+			roles, err := provider.Roles(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roles).To(Equal([]*types.Role{staticRole, storedRoles.Roles[0]}))
+		})
+
+		It("reloads the stored roles only after the refresh interval", func() {
+			provider := NewRoleProvider(repository, nil, time.Hour)
+			_, err := provider.Roles(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = provider.Roles(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.ListCallCount()).To(Equal(1))
+
+			provider = NewRoleProvider(repository, nil, time.Nanosecond)
+			_, err = provider.Roles(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			time.Sleep(time.Millisecond)
+			_, err = provider.Roles(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository.ListCallCount()).To(Equal(3))
+		})
+	})
+})
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rbac
+
+import (
+	"fmt"
+	"time"
+)
+
+// Settings type to be loaded from the environment
+type Settings struct {
+	Enabled         bool          `mapstructure:"enabled" description:"whether requests authenticated with a bearer token are authorized against roles"`
+	RolesFile       string        `mapstructure:"roles_file" description:"path to a YAML file with roles which are always in effect in addition to the roles managed through the API"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" description:"how often the roles managed through the API are reloaded from storage"`
+}
+
+// DefaultSettings returns the default values for RBAC
+func DefaultSettings() *Settings {
+	return &Settings{
+		Enabled:         false,
+		RolesFile:       "",
+		RefreshInterval: 30 * time.Second,
+	}
+}
+
+// Validate validates the RBAC settings
+func (s *Settings) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.RefreshInterval <= 0 {
+		return fmt.Errorf("validate rbac settings: refresh_interval should be > 0")
+	}
+	if len(s.RolesFile) != 0 {
+		if _, err := LoadRoles(s.RolesFile); err != nil {
+			return fmt.Errorf("validate rbac settings: %s", err)
+		}
+	}
+	return nil
+}
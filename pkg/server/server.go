@@ -45,6 +45,7 @@ type Settings struct {
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" description:"time to wait for the server to shutdown"`
 	MaxBodyBytes    int           `mapstructure:"max_body_bytes" description:"maximum bytes size of incoming body"`
 	MaxHeaderBytes  int           `mapstructure:"max_header_bytes" description:"the maximum number of bytes the server will read parsing the request header"`
+	TLS             *TLSSettings  `mapstructure:"tls"`
 }
 
 // DefaultSettings returns the default values for configuring the Service Manager
@@ -55,6 +56,7 @@ func DefaultSettings() *Settings {
 		ShutdownTimeout: time.Second * 3,
 		MaxBodyBytes:    mb,
 		MaxHeaderBytes:  kb,
+		TLS:             DefaultTLSSettings(),
 	}
 }
 
@@ -69,6 +71,9 @@ func (s *Settings) Validate() error {
 	if s.ShutdownTimeout == 0 {
 		return fmt.Errorf("validate Settings: ShutdownTimeout missing")
 	}
+	if s.TLS != nil {
+		return s.TLS.Validate()
+	}
 
 	return nil
 }
@@ -136,6 +141,16 @@ func (s *Server) Run(ctx context.Context, wg *sync.WaitGroup) {
 		ReadTimeout:    s.Config.RequestTimeout,
 		MaxHeaderBytes: s.Config.MaxHeaderBytes,
 	}
+	if s.Config.TLS.Enabled() {
+		reloader, err := newCertificateReloader(s.Config.TLS)
+		if err != nil {
+			panic(fmt.Sprintf("invalid server TLS config: %s", err))
+		}
+		if err := reloader.Watch(ctx); err != nil {
+			panic(fmt.Sprintf("could not watch server TLS files: %s", err))
+		}
+		handler.TLSConfig = reloader.TLSConfig()
+	}
 	startServer(ctx, handler, s.Config.ShutdownTimeout, wg)
 }
 
@@ -143,9 +158,16 @@ func startServer(ctx context.Context, server *http.Server, shutdownTimeout time.
 	wg.Add(1)
 	go gracefulShutdown(ctx, server, shutdownTimeout, wg)
 
-	log.C(ctx).Infof("Server listening on %s...", server.Addr)
+	var err error
+	if server.TLSConfig != nil {
+		log.C(ctx).Infof("Server listening for HTTPS on %s...", server.Addr)
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		log.C(ctx).Infof("Server listening on %s...", server.Addr)
+		err = server.ListenAndServe()
+	}
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err != nil && err != http.ErrServerClosed {
 		log.C(ctx).Fatal(err)
 	}
 }
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/fsnotify/fsnotify"
+)
+
+// TLSSettings type to be loaded from the environment
+type TLSSettings struct {
+	CertFile                 string `mapstructure:"cert_file" description:"path to the PEM encoded server certificate; if set the server accepts only HTTPS requests"`
+	KeyFile                  string `mapstructure:"key_file" description:"path to the PEM encoded private key of the server certificate"`
+	ClientCAFile             string `mapstructure:"client_ca_file" description:"path to a PEM encoded CA bundle used to verify client certificates"`
+	RequireClientCertificate bool   `mapstructure:"require_client_certificate" description:"whether clients must present a certificate signed by the client CA"`
+}
+
+// DefaultTLSSettings returns the default values for the server TLS settings
+func DefaultTLSSettings() *TLSSettings {
+	return &TLSSettings{
+		CertFile:                 "",
+		KeyFile:                  "",
+		ClientCAFile:             "",
+		RequireClientCertificate: false,
+	}
+}
+
+// Enabled returns true if the server should listen for HTTPS requests
+func (s *TLSSettings) Enabled() bool {
+	return s != nil && s.CertFile != ""
+}
+
+// Validate validates the server TLS settings
+func (s *TLSSettings) Validate() error {
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		return fmt.Errorf("validate Settings: both TLS cert_file and key_file must be provided")
+	}
+	if s.ClientCAFile != "" && s.CertFile == "" {
+		return fmt.Errorf("validate Settings: TLS client_ca_file requires cert_file and key_file")
+	}
+	if s.RequireClientCertificate && s.ClientCAFile == "" {
+		return fmt.Errorf("validate Settings: TLS require_client_certificate requires client_ca_file")
+	}
+	return nil
+}
+
+func (s *TLSSettings) clientAuth() tls.ClientAuthType {
+	if s.ClientCAFile == "" {
+		return tls.NoClientCert
+	}
+	if s.RequireClientCertificate {
+		return tls.RequireAndVerifyClientCert
+	}
+	return tls.VerifyClientCertIfGiven
+}
+
+// certificateReloader keeps the server certificate and the client CA bundle up to date with the files they are loaded from
+type certificateReloader struct {
+	settings *TLSSettings
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+}
+
+func newCertificateReloader(settings *TLSSettings) (*certificateReloader, error) {
+	reloader := &certificateReloader{
+		settings: settings,
+	}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+func (r *certificateReloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.settings.CertFile, r.settings.KeyFile)
+	if err != nil {
+		return fmt.Errorf("could not load server certificate: %s", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.settings.ClientCAFile != "" {
+		caBytes, err := ioutil.ReadFile(r.settings.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("could not read client CA bundle: %s", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caBytes) {
+			return fmt.Errorf("could not find any PEM certificates in client CA bundle %s", r.settings.ClientCAFile)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	return nil
+}
+
+// Watch reloads the certificates each time a change in the directories containing the TLS files is detected.
+// Directories are watched instead of the files themselves so that atomic replacements (e.g. of mounted secrets) are detected as well.
+func (r *certificateReloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	dirs := make(map[string]bool)
+	for _, file := range []string{r.settings.CertFile, r.settings.KeyFile, r.settings.ClientCAFile} {
+		if file != "" {
+			dirs[filepath.Dir(file)] = true
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				log.C(ctx).Debugf("Detected change %s of %s, reloading server TLS files", event.Op, event.Name)
+				if err := r.reload(); err != nil {
+					log.C(ctx).WithError(err).Error("Could not reload server TLS files, keeping the previously loaded ones")
+					continue
+				}
+				log.C(ctx).Info("Reloaded server TLS files")
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.C(ctx).WithError(err).Error("Error while watching server TLS files")
+			}
+		}
+	}()
+	return nil
+}
+
+// TLSConfig returns a TLS configuration which always uses the most recently loaded certificates
+func (r *certificateReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mutex.RLock()
+			defer r.mutex.RUnlock()
+			return r.certificate, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mutex.RLock()
+			defer r.mutex.RUnlock()
+			// the configuration replaces the one of the server, so it has to offer the protocols the server supports
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.certificate},
+				ClientCAs:    r.clientCAs,
+				ClientAuth:   r.settings.clientAuth(),
+				NextProtos:   []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TLS", func() {
+	var dir string
+	var settings *TLSSettings
+
+	writeCertificate := func(commonName string) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: commonName},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		Expect(ioutil.WriteFile(settings.KeyFile, keyPEM, 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(settings.CertFile, certPEM, 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(settings.ClientCAFile, certPEM, 0600)).To(Succeed())
+	}
+
+	currentCommonName := func(config *tls.Config) string {
+		clientConfig, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+		Expect(err).ToNot(HaveOccurred())
+		certificate, err := x509.ParseCertificate(clientConfig.Certificates[0].Certificate[0])
+		Expect(err).ToNot(HaveOccurred())
+		return certificate.Subject.CommonName
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "sm-tls")
+		Expect(err).ToNot(HaveOccurred())
+
+		settings = &TLSSettings{
+			CertFile:     filepath.Join(dir, "tls.crt"),
+			KeyFile:      filepath.Join(dir, "tls.key"),
+			ClientCAFile: filepath.Join(dir, "ca.crt"),
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("Validate", func() {
+		It("fails when only the certificate file is provided", func() {
+			settings.KeyFile = ""
+			Expect(settings.Validate()).To(HaveOccurred())
+		})
+
+		It("fails when a client CA is provided without a server certificate", func() {
+			settings.CertFile = ""
+			settings.KeyFile = ""
+			Expect(settings.Validate()).To(HaveOccurred())
+		})
+
+		It("fails when client certificates are required without a client CA", func() {
+			settings.ClientCAFile = ""
+			settings.RequireClientCertificate = true
+			Expect(settings.Validate()).To(HaveOccurred())
+		})
+
+		It("succeeds when TLS is disabled", func() {
+			Expect(DefaultTLSSettings().Validate()).ToNot(HaveOccurred())
+			Expect(DefaultTLSSettings().Enabled()).To(BeFalse())
+		})
+	})
+
+	Describe("certificate reloader", func() {
+		It("fails when the certificate files cannot be loaded", func() {
+			_, err := newCertificateReloader(settings)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("verifies client certificates if given", func() {
+			writeCertificate("first")
+			reloader, err := newCertificateReloader(settings)
+			Expect(err).ToNot(HaveOccurred())
+
+			clientConfig, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clientConfig.ClientAuth).To(Equal(tls.VerifyClientCertIfGiven))
+			Expect(clientConfig.ClientCAs).ToNot(BeNil())
+		})
+
+		It("negotiates HTTP/2 with the clients", func() {
+			writeCertificate("first")
+			reloader, err := newCertificateReloader(settings)
+			Expect(err).ToNot(HaveOccurred())
+
+			clientConfig, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clientConfig.NextProtos).To(Equal([]string{"h2", "http/1.1"}))
+		})
+
+		It("reloads the certificate when the files change", func() {
+			writeCertificate("first")
+			reloader, err := newCertificateReloader(settings)
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Expect(reloader.Watch(ctx)).To(Succeed())
+
+			config := reloader.TLSConfig()
+			Expect(currentCommonName(config)).To(Equal("first"))
+
+			writeCertificate("second")
+			Eventually(func() string {
+				return currentCommonName(config)
+			}).Should(Equal("second"))
+		})
+	})
+})
@@ -18,7 +18,6 @@ package sm
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"math"
@@ -33,6 +32,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/env"
 
 	"github.com/Peripli/service-manager/pkg/health"
+	"github.com/Peripli/service-manager/pkg/hotreload"
 
 	"github.com/Peripli/service-manager/pkg/httpclient"
 
@@ -49,13 +49,16 @@ import (
 	"github.com/Peripli/service-manager/api/healthcheck"
 	"github.com/Peripli/service-manager/config"
 	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/maintenance"
+	"github.com/Peripli/service-manager/pkg/multitenancy"
 	"github.com/Peripli/service-manager/pkg/server"
 	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/storage"
-	"github.com/Peripli/service-manager/storage/postgres"
+	"github.com/Peripli/service-manager/storage/secretstore"
 
 	"github.com/Peripli/service-manager/api/filters"
 	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/pkg/ws"
 	osbc "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
 )
 
@@ -64,16 +67,20 @@ import (
 type ServiceManagerBuilder struct {
 	*web.API
 
-	Storage              *storage.InterceptableTransactionalRepository
-	Notificator          storage.Notificator
-	NotificationCleaner  *storage.NotificationCleaner
-	OperationMaintainer  *operations.Maintainer
-	OSBClientProvider    osbc.CreateFunc
-	ctx                  context.Context
-	wg                   *sync.WaitGroup
-	cfg                  *config.Settings
-	securityBuilder      *SecurityBuilder
-	encryptingRepository storage.TransactionalRepository
+	Storage             *storage.InterceptableTransactionalRepository
+	Notificator         storage.Notificator
+	NotificationCleaner *storage.NotificationCleaner
+	OperationMaintainer *operations.Maintainer
+	OSBClientProvider   osbc.CreateFunc
+	// CredentialsUsageStore records the usage of the platform credentials unless the Service Manager is read-only
+	CredentialsUsageStore storage.CredentialsUsageStore
+	ctx                   context.Context
+	wg                    *sync.WaitGroup
+	cfg                   *config.Settings
+	securityBuilder       *SecurityBuilder
+	encryptingRepository  storage.TransactionalRepository
+	configuration         *hotreload.Registry
+	tenantClaims          map[string]string
 }
 
 // ServiceManager  struct
@@ -105,41 +112,76 @@ func New(ctx context.Context, cancel context.CancelFunc, e env.Environment, cfg
 
 	// Setup storage
 	log.C(ctx).Info("Setting up Service Manager storage...")
-	smStorage := &postgres.Storage{
-		ConnectFunc: func(driver string, url string) (*sql.DB, error) {
-			return sql.Open(driver, url)
-		},
+	smStorage := newStorageBackend(cfg.Storage)
+
+	secretStore, err := secretstore.New(cfg.Storage.SecretStore)
+	if err != nil {
+		return nil, fmt.Errorf("error creating secret store: %s", err)
 	}
 
 	// Decorate the storage with credentials encryption/decryption
-	encryptingDecorator := storage.EncryptingDecorator(ctx, &security.AESEncrypter{}, smStorage, postgres.EncryptingLocker(smStorage))
+	encrypter := &security.AESEncrypter{}
+	encryptingDecorator := storage.EncryptingDecorator(ctx, encrypter, smStorage, smStorage.encryptingLocker, secretStore)
 	integrityDecorator := storage.DataIntegrityDecorator(cfg.Storage.IntegrityProcessor)
 
 	// Initialize the storage with graceful termination
 	var transactionalRepository storage.TransactionalRepository
 	waitGroup := &sync.WaitGroup{}
-	if transactionalRepository, err = storage.InitializeWithSafeTermination(ctx, smStorage, cfg.Storage, waitGroup, integrityDecorator, encryptingDecorator); err != nil {
+	if transactionalRepository, err = storage.InitializeWithSafeTermination(ctx, smStorage.Storage, cfg.Storage, waitGroup, integrityDecorator, encryptingDecorator); err != nil {
 		return nil, fmt.Errorf("error opening storage: %s", err)
 	}
 
+	// The responses replayed for repeated requests may hold credentials, so they are encrypted as well
+	encryptionKey, err := smStorage.GetEncryptionKey(ctx, encrypter.Decrypt)
+	if err != nil {
+		return nil, fmt.Errorf("could not get encryption key: %s", err)
+	}
+	idempotencyStore := storage.NewEncryptingIdempotencyStore(smStorage, encrypter, encryptionKey)
+
 	// Wrap the repository with logic that runs interceptors
 	interceptableRepository := storage.NewInterceptableTransactionalRepository(transactionalRepository)
 
 	// Setup core API
 	log.C(ctx).Info("Setting up Service Manager core API...")
 
-	pgNotificator, err := postgres.NewNotificator(smStorage, cfg.Storage)
+	notificator, err := smStorage.newNotificator(cfg.Storage)
 	if err != nil {
 		return nil, fmt.Errorf("could not create notificator: %v", err)
 	}
 
+	maintenanceState := maintenance.NewState(smStorage, cfg.Maintenance.RefreshInterval)
+	if cfg.Maintenance.ReadOnly {
+		if err := maintenanceState.SetInitialMode(ctx, types.MaintenanceMode{
+			ReadOnly: true,
+			Reason:   "read-only mode switched on by configuration",
+		}); err != nil {
+			return nil, fmt.Errorf("could not switch to read-only maintenance mode: %s", err)
+		}
+	}
+
+	configuration := hotreload.NewRegistry(e, smStorage)
+	configuration.Register("api", func() hotreload.Settings { return api.DefaultSettings() },
+		"default_page_size", "max_page_size", "protected_labels")
+	configuration.Register("operations", func() hotreload.Settings { return operations.DefaultSettings() },
+		"action_timeout", "reconciliation_operation_timeout", "default_pool_size", "pools")
+	configuration.Register("websocket", func() hotreload.Settings { return ws.DefaultSettings() },
+		"ping_timeout", "write_timeout")
+	configuration.Register("health", func() hotreload.Settings { return health.DefaultSettings() },
+		"indicators")
+
 	apiOptions := &api.Options{
-		Repository:        interceptableRepository,
-		APISettings:       cfg.API,
-		OperationSettings: cfg.Operations,
-		WSSettings:        cfg.WebSocket,
-		Notificator:       pgNotificator,
-		WaitGroup:         waitGroup,
+		Repository:            interceptableRepository,
+		APISettings:           cfg.API,
+		OperationSettings:     cfg.Operations,
+		WSSettings:            cfg.WebSocket,
+		MaintenanceSettings:   cfg.Maintenance,
+		MaintenanceState:      maintenanceState,
+		Configuration:         configuration,
+		IdempotencyStore:      idempotencyStore,
+		NotificationsLagStore: smStorage,
+		RequestTimeout:        cfg.Server.RequestTimeout,
+		Notificator:           notificator,
+		WaitGroup:             waitGroup,
 	}
 	API, err := api.New(ctx, e, apiOptions)
 	if err != nil {
@@ -155,38 +197,46 @@ func New(ctx context.Context, cancel context.CancelFunc, e env.Environment, cfg
 	}
 
 	API.SetIndicator(storageHealthIndicator)
-	API.SetIndicator(healthcheck.NewPlatformIndicator(ctx, interceptableRepository, nil))
+	API.SetIndicator(healthcheck.NewPlatformIndicator(ctx, interceptableRepository, smStorage, nil))
+	API.SetIndicator(healthcheck.NewMaintenanceIndicator(ctx, maintenanceState))
 
 	notificationCleaner := &storage.NotificationCleaner{
-		Storage:  interceptableRepository,
-		Settings: *cfg.Storage,
-	}
-
-	postgresLockerCreatorFunc := func(advisoryIndex int) storage.Locker {
-		return &postgres.Locker{Storage: smStorage, AdvisoryIndex: advisoryIndex}
+		Storage:     interceptableRepository,
+		Partitioner: smStorage.partitioner,
+		Settings:    *cfg.Storage,
 	}
 
-	operationMaintainer := operations.NewMaintainer(ctx, interceptableRepository, postgresLockerCreatorFunc, cfg.Operations, waitGroup)
+	operationMaintainer := operations.NewMaintainer(ctx, interceptableRepository, smStorage.partitioner, maintenanceState, smStorage.lockerCreator, cfg.Operations, waitGroup)
+	configuration.Subscribe("operations", func(settings hotreload.Settings) {
+		operationMaintainer.Configure(settings.(*operations.Settings))
+	})
 	osbClientTimeout := math.Min(float64(cfg.HTTPClient.Timeout), float64(cfg.Server.RequestTimeout))
 	osbClientTimeoutDuration := time.Duration(osbClientTimeout)
 	osbClientProvider := osb.NewBrokerClientProvider(cfg.HTTPClient.SkipSSLValidation, int(osbClientTimeoutDuration.Seconds()))
 
-	encryptingRepository, err := encryptingDecorator(smStorage)
+	encryptingRepository, err := encryptingDecorator(smStorage.Storage)
 	if err != nil {
 		return nil, fmt.Errorf("error decorating storage with encryption: %s", err)
 	}
 	smb := &ServiceManagerBuilder{
-		API:                  API,
-		Storage:              interceptableRepository,
-		Notificator:          pgNotificator,
-		NotificationCleaner:  notificationCleaner,
-		OperationMaintainer:  operationMaintainer,
-		ctx:                  ctx,
-		wg:                   waitGroup,
-		cfg:                  cfg,
-		securityBuilder:      securityBuilder,
-		OSBClientProvider:    osbClientProvider,
+		API:                 API,
+		Storage:             interceptableRepository,
+		Notificator:         notificator,
+		NotificationCleaner: notificationCleaner,
+		OperationMaintainer: operationMaintainer,
+		ctx:                 ctx,
+		wg:                  waitGroup,
+		cfg:                 cfg,
+		securityBuilder:     securityBuilder,
+		OSBClientProvider:   osbClientProvider,
+		CredentialsUsageStore: storage.CredentialsUsageFunc(func(ctx context.Context, platformID, username string, usedAt time.Time, interval time.Duration) error {
+			if maintenanceState.ReadOnly(ctx) {
+				return nil
+			}
+			return smStorage.RecordCredentialsUsage(ctx, platformID, username, usedAt, interval)
+		}),
 		encryptingRepository: encryptingRepository,
+		configuration:        configuration,
 	}
 
 	smb.RegisterPlugins(osb.NewCatalogFilterByVisibilityPlugin(interceptableRepository))
@@ -271,6 +321,12 @@ func (smb *ServiceManagerBuilder) Build() *ServiceManager {
 		log.C(smb.ctx).Panic(err)
 	}
 
+	if smb.cfg.Storage.SecretStore.MigrateExisting {
+		if err := smb.migrateSecrets(); err != nil {
+			log.C(smb.ctx).Panic(err)
+		}
+	}
+
 	// start the operation maintainer
 	smb.OperationMaintainer.Run()
 
@@ -278,6 +334,11 @@ func (smb *ServiceManagerBuilder) Build() *ServiceManager {
 		log.C(smb.ctx).Panic(err)
 	}
 
+	// apply the configuration changes made at runtime once all their listeners are subscribed
+	if err := smb.configuration.Start(smb.ctx, smb.wg); err != nil {
+		log.C(smb.ctx).Panic(err)
+	}
+
 	return &ServiceManager{
 		ctx:                 smb.ctx,
 		wg:                  smb.wg,
@@ -315,15 +376,38 @@ func (smb *ServiceManagerBuilder) installHealth() error {
 		return err
 	}
 
-	smb.RegisterControllers(healthcheck.NewController(healthz, thresholds))
+	controller := healthcheck.NewController(healthz, thresholds)
+	smb.RegisterControllers(controller)
 
 	if err := healthz.Start(); err != nil {
 		return err
 	}
 
+	var healthMutex sync.Mutex
+	smb.configuration.Subscribe("health", func(settings hotreload.Settings) {
+		healthMutex.Lock()
+		defer healthMutex.Unlock()
+		newHealthz, newThresholds, err := health.Configure(smb.ctx, smb.HealthIndicators, settings.(*health.Settings))
+		if err != nil {
+			log.C(smb.ctx).WithError(err).Error("Could not reconfigure health checks")
+			return
+		}
+		if err := newHealthz.Start(); err != nil {
+			log.C(smb.ctx).WithError(err).Error("Could not start reconfigured health checks")
+			return
+		}
+		controller.Configure(newHealthz, newThresholds)
+		if err := healthz.Stop(); err != nil {
+			log.C(smb.ctx).Error(err)
+		}
+		healthz = newHealthz
+	})
+
 	util.StartInWaitGroupWithContext(smb.ctx, func(c context.Context) {
 		<-c.Done()
 		log.C(c).Debug("Context cancelled. Stopping health checks...")
+		healthMutex.Lock()
+		defer healthMutex.Unlock()
 		if err := healthz.Stop(); err != nil {
 			log.C(c).Error(err)
 		}
@@ -512,7 +596,16 @@ func (smb *ServiceManagerBuilder) WithDeleteInterceptorProvider(objectType types
 	}
 }
 
-// EnableMultitenancy enables multitenancy resources for Service Manager by labeling them with appropriate tenant value
+// WithTenantClaims sets the claims which hold the tenant of the user by the issuer of the token. The tenant of the users
+// authenticated with tokens of these issuers is extracted from the claims once multitenancy is enabled.
+func (smb *ServiceManagerBuilder) WithTenantClaims(tenantClaims map[string]string) *ServiceManagerBuilder {
+	smb.tenantClaims = tenantClaims
+	return smb
+}
+
+// EnableMultitenancy enables multitenancy resources for Service Manager by labeling them with appropriate tenant value.
+// The tenant of the users authenticated with tokens of issuers with a tenant claim is extracted from the claim, while
+// the tenant of the other users is extracted by the specified extractTenantFunc
 func (smb *ServiceManagerBuilder) EnableMultitenancy(labelKey string, extractTenantFunc func(*web.Request) (string, error)) (*ServiceManagerBuilder, error) {
 	if len(labelKey) == 0 {
 		log.D().Panic("labelKey should be provided")
@@ -521,7 +614,10 @@ func (smb *ServiceManagerBuilder) EnableMultitenancy(labelKey string, extractTen
 		log.D().Panic("extractTenantFunc should be provided")
 	}
 
-	multitenancyFilters, err := filters.NewMultitenancyFilters(labelKey, extractTenantFunc)
+	// the tenant claims are looked up per request as they may be set after multitenancy is enabled
+	multitenancyFilters, err := filters.NewMultitenancyFilters(labelKey, func(request *web.Request) (string, error) {
+		return multitenancy.ExtractTenantFromIssuerTokenWrapperFunc(smb.tenantClaims, extractTenantFunc)(request)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -548,6 +644,14 @@ func (smb *ServiceManagerBuilder) Security() *SecurityBuilder {
 	return smb.securityBuilder.Reset()
 }
 
+func (smb *ServiceManagerBuilder) migrateSecrets() error {
+	encryptingRepository, ok := smb.encryptingRepository.(*storage.TransactionalEncryptingRepository)
+	if !ok {
+		return nil
+	}
+	return encryptingRepository.MigrateSecrets(smb.ctx)
+}
+
 func (smb *ServiceManagerBuilder) calculateIntegrity() error {
 	return smb.encryptingRepository.InTransaction(smb.ctx, func(ctx context.Context, storage storage.Repository) error {
 		objectTypesWithIntegrity := []types.ObjectType{types.PlatformType, types.ServiceBrokerType, types.ServiceBindingType, types.BrokerPlatformCredentialType}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sm
+
+import (
+	"database/sql"
+
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/memory"
+	"github.com/Peripli/service-manager/storage/postgres"
+)
+
+// storageBackend is the storage selected by the storage URI together with the locks, the notificator and the
+// partitioner which have to be built on top of the same storage
+type storageBackend struct {
+	storage.Storage
+	storage.KeyStore
+	storage.MaintenanceModeStore
+	storage.ConfigurationStore
+	storage.IdempotencyStore
+	storage.NotificationsLagStore
+	storage.CredentialsUsageStore
+
+	// partitioner is nil if the storage does not partition the operations and the notifications
+	partitioner storage.Partitioner
+
+	encryptingLocker storage.Locker
+	lockerCreator    storage.LockerCreatorFunc
+	newNotificator   func(settings *storage.Settings) (storage.Notificator, error)
+}
+
+// newStorageBackend returns the in-memory storage for memory:// storage URIs and the PostgreSQL storage otherwise
+func newStorageBackend(settings *storage.Settings) *storageBackend {
+	if memory.IsMemoryURI(settings.URI) {
+		memoryStorage := &memory.Storage{}
+		return &storageBackend{
+			Storage:               memoryStorage,
+			KeyStore:              memoryStorage,
+			MaintenanceModeStore:  memoryStorage,
+			ConfigurationStore:    memoryStorage,
+			IdempotencyStore:      memoryStorage,
+			NotificationsLagStore: memoryStorage,
+			CredentialsUsageStore: memoryStorage,
+			encryptingLocker:      memory.EncryptingLocker(memoryStorage),
+			lockerCreator: func(advisoryIndex int) storage.Locker {
+				return &memory.Locker{Storage: memoryStorage, AdvisoryIndex: advisoryIndex}
+			},
+			newNotificator: func(settings *storage.Settings) (storage.Notificator, error) {
+				return memory.NewNotificator(memoryStorage, settings)
+			},
+		}
+	}
+
+	pgStorage := &postgres.Storage{
+		ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+			return sql.Open(driver, url)
+		},
+	}
+	return &storageBackend{
+		Storage:               pgStorage,
+		KeyStore:              pgStorage,
+		MaintenanceModeStore:  pgStorage,
+		ConfigurationStore:    pgStorage,
+		IdempotencyStore:      pgStorage,
+		NotificationsLagStore: pgStorage,
+		CredentialsUsageStore: pgStorage,
+		partitioner:           pgStorage,
+		encryptingLocker:      postgres.EncryptingLocker(pgStorage),
+		lockerCreator: func(advisoryIndex int) storage.Locker {
+			return &postgres.Locker{Storage: pgStorage, AdvisoryIndex: advisoryIndex}
+		},
+		newNotificator: func(settings *storage.Settings) (storage.Notificator, error) {
+			return postgres.NewNotificator(pgStorage, settings)
+		},
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package smclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// authenticator sets the authorization header of the requests to the Service Manager
+type authenticator interface {
+	authorize(header http.Header) error
+}
+
+type noAuthenticator struct{}
+
+func (noAuthenticator) authorize(http.Header) error {
+	return nil
+}
+
+type basicAuthenticator struct {
+	user     string
+	password string
+}
+
+func (a *basicAuthenticator) authorize(header http.Header) error {
+	req := &http.Request{Header: header}
+	req.SetBasicAuth(a.user, a.password)
+	return nil
+}
+
+type tokenAuthenticator struct {
+	tokenSource oauth2.TokenSource
+}
+
+func (a *tokenAuthenticator) authorize(header http.Header) error {
+	token, err := a.tokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("could not fetch token: %s", err)
+	}
+	token.SetAuthHeader(&http.Request{Header: header})
+	return nil
+}
+
+// authTransport authorizes the requests before sending them with the base transport
+type authTransport struct {
+	auth authenticator
+	base http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a round tripper should not modify the request
+	authorizedRequest := req.WithContext(req.Context())
+	authorizedRequest.Header = make(http.Header, len(req.Header))
+	for key, values := range req.Header {
+		authorizedRequest.Header[key] = values
+	}
+	if err := t.auth.authorize(authorizedRequest.Header); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(authorizedRequest)
+}
+
+func newAuthenticator(ctx context.Context, baseURL string, settings *Settings, httpClient *http.Client) (authenticator, error) {
+	if len(settings.Token) != 0 {
+		return &tokenAuthenticator{
+			tokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: settings.Token, TokenType: "Bearer"}),
+		}, nil
+	}
+	if len(settings.ClientID) == 0 {
+		if len(settings.User) == 0 {
+			return noAuthenticator{}, nil
+		}
+		return &basicAuthenticator{user: settings.User, password: settings.Password}, nil
+	}
+
+	tokenURL := settings.TokenURL
+	if len(tokenURL) == 0 {
+		var err error
+		if tokenURL, err = discoverTokenURL(ctx, baseURL, httpClient); err != nil {
+			return nil, err
+		}
+	}
+
+	// the token requests are sent with the http client from the context. The token sources keep the context
+	// for refreshing the tokens, so it should not be bound to the context of the client creation.
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+	if len(settings.User) == 0 {
+		config := &clientcredentials.Config{
+			ClientID:     settings.ClientID,
+			ClientSecret: settings.ClientSecret,
+			TokenURL:     tokenURL,
+		}
+		return &tokenAuthenticator{tokenSource: config.TokenSource(tokenCtx)}, nil
+	}
+
+	config := &oauth2.Config{
+		ClientID:     settings.ClientID,
+		ClientSecret: settings.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			TokenURL: tokenURL,
+		},
+	}
+	token, err := config.PasswordCredentialsToken(context.WithValue(ctx, oauth2.HTTPClient, httpClient), settings.User, settings.Password)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch token: %s", err)
+	}
+	return &tokenAuthenticator{tokenSource: config.TokenSource(tokenCtx, token)}, nil
+}
+
+// discoverTokenURL discovers the token endpoint of the token issuer from the info endpoint of the Service Manager
+func discoverTokenURL(ctx context.Context, baseURL string, httpClient *http.Client) (string, error) {
+	info := struct {
+		TokenIssuerURL string `json:"token_issuer_url"`
+	}{}
+	if err := getJSON(ctx, httpClient, baseURL+web.InfoURL, &info); err != nil {
+		return "", fmt.Errorf("could not discover token issuer: %s", err)
+	}
+	if len(info.TokenIssuerURL) == 0 {
+		return "", fmt.Errorf("could not discover token issuer: %s returned no token issuer", web.InfoURL)
+	}
+
+	configuration := struct {
+		TokenEndpoint string `json:"token_endpoint"`
+	}{}
+	configurationURL := strings.TrimSuffix(info.TokenIssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, httpClient, configurationURL, &configuration); err != nil {
+		return "", fmt.Errorf("could not discover token endpoint: %s", err)
+	}
+	if len(configuration.TokenEndpoint) == 0 {
+		return "", fmt.Errorf("could not discover token endpoint: %s returned no token endpoint", configurationURL)
+	}
+	return configuration.TokenEndpoint, nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, url string, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return util.BodyToObject(resp.Body, result)
+}
@@ -0,0 +1,284 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package smclient contains a typed client for the Service Manager REST API
+package smclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// Settings configures the Service Manager client
+type Settings struct {
+	// URL is the address of the Service Manager
+	URL string
+
+	// User and Password are used for basic authentication if no ClientID is provided and
+	// for the OAuth2 password grant otherwise
+	User     string
+	Password string
+
+	// ClientID and ClientSecret are used to fetch OAuth2 tokens. The client credentials grant is used if no User is provided
+	ClientID     string
+	ClientSecret string
+
+	// TokenURL is the OAuth2 token endpoint. If not set, it is discovered from the token issuer of the Service Manager
+	TokenURL string
+
+	// Token is a bearer token to authenticate with instead of fetching one
+	Token string
+
+	// SkipSSLValidation disables the validation of the server certificates
+	SkipSSLValidation bool
+
+	// Timeout is the timeout of the requests to the Service Manager
+	Timeout time.Duration
+
+	// PollInterval is the interval in which asynchronous operations are polled and notification connections are reestablished
+	PollInterval time.Duration
+}
+
+// DefaultSettings returns default values for the client settings
+func DefaultSettings() *Settings {
+	return &Settings{
+		Timeout:      30 * time.Second,
+		PollInterval: 2 * time.Second,
+	}
+}
+
+// Validate validates the client settings
+func (s *Settings) Validate() error {
+	if len(s.URL) == 0 {
+		return errors.New("validate Settings: URL missing")
+	}
+	if _, err := url.Parse(s.URL); err != nil {
+		return fmt.Errorf("validate Settings: invalid URL: %s", err)
+	}
+	if s.Timeout <= 0 {
+		return errors.New("validate Settings: Timeout should be > 0")
+	}
+	if s.PollInterval <= 0 {
+		return errors.New("validate Settings: PollInterval should be > 0")
+	}
+	return nil
+}
+
+// Client is a client for the Service Manager REST API
+type Client struct {
+	settings   *Settings
+	baseURL    string
+	httpClient *http.Client
+	auth       authenticator
+}
+
+// NewClient creates a new Service Manager client. Depending on the settings the client authenticates using
+// basic authentication, a bearer token or OAuth2 tokens fetched with the client credentials or password grant.
+func NewClient(ctx context.Context, settings *Settings) (*Client, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: settings.SkipSSLValidation},
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	plainClient := &http.Client{
+		Transport: transport,
+		Timeout:   settings.Timeout,
+	}
+
+	client := &Client{
+		settings: settings,
+		baseURL:  strings.TrimSuffix(settings.URL, "/"),
+	}
+	auth, err := newAuthenticator(ctx, client.baseURL, settings, plainClient)
+	if err != nil {
+		return nil, err
+	}
+	client.auth = auth
+	client.httpClient = &http.Client{
+		Transport: &authTransport{auth: auth, base: transport},
+		Timeout:   settings.Timeout,
+	}
+	return client, nil
+}
+
+// request sends a request to the specified path and returns the response if it has one of the expected status codes.
+// The response bodies with unexpected status codes are returned as *util.HTTPError.
+func (c *Client) request(ctx context.Context, method, path string, params url.Values, body interface{}, expectedStatusCodes ...int) (*http.Response, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	requestURL := c.baseURL + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(method, requestURL, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request %s %s: %s", method, requestURL, err)
+	}
+	for _, statusCode := range expectedStatusCodes {
+		if resp.StatusCode == statusCode {
+			return resp, nil
+		}
+	}
+	return nil, responseError(resp)
+}
+
+// call sends a request and decodes the response body into the result if it is not nil
+func (c *Client) call(ctx context.Context, method, path string, params url.Values, body, result interface{}, expectedStatusCodes ...int) (*http.Response, error) {
+	resp, err := c.request(ctx, method, path, params, body, expectedStatusCodes...)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || resp.StatusCode == http.StatusAccepted {
+		if _, err := util.BodyToBytes(resp.Body); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	responseBody, err := util.BodyToBytes(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// the responses are not validated as input, since the Service Manager may return incomplete resources
+	if err := json.Unmarshal(responseBody, result); err != nil {
+		return nil, fmt.Errorf("error decoding response of request %s %s: %s", method, path, err)
+	}
+	return resp, nil
+}
+
+type listResponse struct {
+	Token string          `json:"token"`
+	Items json.RawMessage `json:"items"`
+}
+
+// list loads all pages of the resources at the specified path into items which should be a pointer to a slice
+func (c *Client) list(ctx context.Context, path string, items interface{}, opts ...Option) error {
+	itemsValue := reflect.ValueOf(items)
+	if itemsValue.Kind() != reflect.Ptr || itemsValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("items should be a pointer to a slice, but got %T", items)
+	}
+
+	params := newRequestOptions(opts...).params
+	allItems := reflect.MakeSlice(itemsValue.Elem().Type(), 0, 0)
+	for {
+		page := listResponse{}
+		if _, err := c.call(ctx, http.MethodGet, path, params, nil, &page, http.StatusOK); err != nil {
+			return err
+		}
+		pageItems := reflect.New(itemsValue.Elem().Type())
+		if len(page.Items) > 0 {
+			if err := json.Unmarshal(page.Items, pageItems.Interface()); err != nil {
+				return fmt.Errorf("error decoding items of %s: %s", path, err)
+			}
+		}
+		allItems = reflect.AppendSlice(allItems, pageItems.Elem())
+		if len(page.Token) == 0 {
+			break
+		}
+		params.Set(tokenQueryParam, page.Token)
+	}
+	itemsValue.Elem().Set(allItems)
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path, id string, result interface{}, opts ...Option) error {
+	_, err := c.call(ctx, http.MethodGet, path+"/"+url.PathEscape(id), newRequestOptions(opts...).params, nil, result, http.StatusOK)
+	return err
+}
+
+// create creates a resource and returns the location of the operation if the request was accepted for asynchronous execution
+func (c *Client) create(ctx context.Context, path string, body, result interface{}, opts ...Option) (string, error) {
+	resp, err := c.call(ctx, http.MethodPost, path, newRequestOptions(opts...).params, body, result, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Location"), nil
+}
+
+// update updates a resource and returns the location of the operation if the request was accepted for asynchronous execution
+func (c *Client) update(ctx context.Context, path, id string, body, result interface{}, opts ...Option) (string, error) {
+	resp, err := c.call(ctx, http.MethodPatch, path+"/"+url.PathEscape(id), newRequestOptions(opts...).params, body, result, http.StatusOK, http.StatusAccepted)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Location"), nil
+}
+
+// delete deletes a resource and returns the location of the operation if the request was accepted for asynchronous execution
+func (c *Client) delete(ctx context.Context, path, id string, opts ...Option) (string, error) {
+	resp, err := c.call(ctx, http.MethodDelete, path+"/"+url.PathEscape(id), newRequestOptions(opts...).params, nil, nil, http.StatusOK, http.StatusAccepted)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Location"), nil
+}
+
+func responseError(resp *http.Response) error {
+	body, err := util.BodyToBytes(resp.Body)
+	httpErr := &util.HTTPError{
+		StatusCode: resp.StatusCode,
+	}
+	if err != nil || json.Unmarshal(body, httpErr) != nil || len(httpErr.ErrorType) == 0 {
+		httpErr.ErrorType = http.StatusText(resp.StatusCode)
+		httpErr.Description = strings.TrimSpace(string(body))
+	}
+	if len(httpErr.Description) == 0 {
+		httpErr.Description = "request failed with status code " + strconv.Itoa(resp.StatusCode)
+	}
+	return httpErr
+}
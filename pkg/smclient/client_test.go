@@ -0,0 +1,318 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package smclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/smclient"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		mux      *http.ServeMux
+		settings *smclient.Settings
+		client   *smclient.Client
+
+		mutex    sync.Mutex
+		requests []*http.Request
+	)
+
+	writeJSON := func(rw http.ResponseWriter, status int, body interface{}) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(status)
+		Expect(json.NewEncoder(rw).Encode(body)).To(Succeed())
+	}
+
+	recordedRequests := func() []*http.Request {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests
+	}
+
+	newClient := func() *smclient.Client {
+		c, err := smclient.NewClient(ctx, settings)
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests = nil
+		mux = http.NewServeMux()
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			requests = append(requests, req)
+			mutex.Unlock()
+			mux.ServeHTTP(rw, req)
+		}))
+		settings = smclient.DefaultSettings()
+		settings.URL = server.URL
+		settings.User = "admin"
+		settings.Password = "secret"
+		settings.PollInterval = 10 * time.Millisecond
+		client = newClient()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("settings", func() {
+		It("requires an URL", func() {
+			settings.URL = ""
+			_, err := smclient.NewClient(ctx, settings)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("list", func() {
+		BeforeEach(func() {
+			mux.HandleFunc(web.PlatformsURL, func(rw http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("token") == "" {
+					writeJSON(rw, http.StatusOK, map[string]interface{}{
+						"token": "next", "num_items": 2, "items": []*types.Platform{{Base: types.Base{ID: "1"}}},
+					})
+					return
+				}
+				writeJSON(rw, http.StatusOK, map[string]interface{}{
+					"num_items": 2, "items": []*types.Platform{{Base: types.Base{ID: "2"}}},
+				})
+			})
+		})
+
+		It("loads all pages", func() {
+			platforms, err := client.ListPlatforms(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platforms).To(HaveLen(2))
+			Expect(platforms[0].ID).To(Equal("1"))
+			Expect(platforms[1].ID).To(Equal("2"))
+			Expect(recordedRequests()[1].URL.Query().Get("token")).To(Equal("next"))
+		})
+
+		It("sends the query criteria and options", func() {
+			_, err := client.ListPlatforms(ctx,
+				smclient.WithQuery(
+					query.ByField(query.EqualsOperator, "type", "kubernetes"),
+					query.ByLabel(query.InOperator, "env", "dev"),
+					query.OrderResultBy("name", query.DescOrder),
+				),
+				smclient.WithFields("name"),
+				smclient.WithPageSize(10))
+			Expect(err).ToNot(HaveOccurred())
+
+			params := recordedRequests()[0].URL.Query()
+			Expect(params.Get("fieldQuery")).To(Equal("type eq 'kubernetes'"))
+			Expect(params.Get("labelQuery")).To(Equal("env in ('dev')"))
+			Expect(params.Get("orderBy")).To(Equal("name desc"))
+			Expect(params.Get("fields")).To(Equal("name"))
+			Expect(params.Get("max_items")).To(Equal("10"))
+		})
+
+		It("authenticates with basic credentials", func() {
+			_, err := client.ListPlatforms(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			user, password, ok := recordedRequests()[0].BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(user).To(Equal("admin"))
+			Expect(password).To(Equal("secret"))
+		})
+	})
+
+	Describe("errors", func() {
+		It("returns the error of the Service Manager", func() {
+			mux.HandleFunc(web.PlatformsURL+"/missing", func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusNotFound, util.HTTPError{ErrorType: "NotFound", Description: "platform not found"})
+			})
+
+			_, err := client.GetPlatform(ctx, "missing")
+			Expect(err).To(HaveOccurred())
+			httpErr, ok := err.(*util.HTTPError)
+			Expect(ok).To(BeTrue())
+			Expect(httpErr.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(httpErr.ErrorType).To(Equal("NotFound"))
+			Expect(httpErr.Description).To(Equal("platform not found"))
+		})
+	})
+
+	Describe("async operations", func() {
+		const location = web.ServiceInstancesURL + "/instance/operations/operation"
+		var polls int
+
+		BeforeEach(func() {
+			polls = 0
+			mux.HandleFunc(web.ServiceInstancesURL, func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Query().Get(web.QueryParamAsync)).To(Equal("true"))
+				rw.Header().Set("Location", location)
+				writeJSON(rw, http.StatusAccepted, map[string]interface{}{})
+			})
+		})
+
+		It("waits for the operation to succeed", func() {
+			mux.HandleFunc(location, func(rw http.ResponseWriter, req *http.Request) {
+				polls++
+				state := types.IN_PROGRESS
+				if polls == 3 {
+					state = types.SUCCEEDED
+				}
+				writeJSON(rw, http.StatusOK, &types.Operation{Base: types.Base{ID: "operation"}, State: state})
+			})
+
+			instance, loc, err := client.CreateServiceInstance(ctx, &types.ServiceInstance{Name: "instance"}, smclient.WithAsync(true))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instance).To(BeNil())
+			Expect(loc).To(Equal(location))
+
+			operation, err := client.WaitForOperation(ctx, loc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(operation.State).To(Equal(types.SUCCEEDED))
+			Expect(polls).To(Equal(3))
+		})
+
+		It("returns an error if the operation fails", func() {
+			mux.HandleFunc(location, func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, &types.Operation{
+					Base:   types.Base{ID: "operation"},
+					State:  types.FAILED,
+					Errors: json.RawMessage(`{"description":"broker error"}`),
+				})
+			})
+
+			_, err := client.WaitForOperation(ctx, location)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&smclient.OperationFailedError{}))
+			Expect(err.Error()).To(ContainSubstring("broker error"))
+		})
+	})
+
+	Describe("oauth", func() {
+		BeforeEach(func() {
+			mux.HandleFunc(web.InfoURL, func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, map[string]interface{}{"token_issuer_url": server.URL + "/issuer"})
+			})
+			mux.HandleFunc("/issuer/.well-known/openid-configuration", func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, map[string]interface{}{"token_endpoint": server.URL + "/issuer/token"})
+			})
+			mux.HandleFunc("/issuer/token", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.ParseForm()).To(Succeed())
+				Expect(req.Form.Get("grant_type")).To(Equal("client_credentials"))
+				writeJSON(rw, http.StatusOK, map[string]interface{}{"access_token": "access-token", "token_type": "bearer", "expires_in": 3600})
+			})
+			mux.HandleFunc(web.RolesURL, func(rw http.ResponseWriter, req *http.Request) {
+				writeJSON(rw, http.StatusOK, map[string]interface{}{"num_items": 0, "items": []interface{}{}})
+			})
+		})
+
+		It("discovers the token endpoint and uses client credentials", func() {
+			settings.User = ""
+			settings.Password = ""
+			settings.ClientID = "client"
+			settings.ClientSecret = "client-secret"
+			client = newClient()
+
+			roles, err := client.ListRoles(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roles).To(BeEmpty())
+
+			reqs := recordedRequests()
+			Expect(reqs[len(reqs)-1].Header.Get("Authorization")).To(Equal("Bearer access-token"))
+		})
+	})
+
+	Describe("notifications", func() {
+		var (
+			upgrader    websocket.Upgrader
+			connections int
+			revisions   []string
+		)
+
+		BeforeEach(func() {
+			connections = 0
+			revisions = nil
+			mux.HandleFunc(web.NotificationsURL, func(rw http.ResponseWriter, req *http.Request) {
+				mutex.Lock()
+				connections++
+				connection := connections
+				revisions = append(revisions, req.URL.Query().Get("last_notification_revision"))
+				mutex.Unlock()
+
+				if req.URL.Query().Get("last_notification_revision") == "1" {
+					writeJSON(rw, http.StatusGone, map[string]interface{}{})
+					return
+				}
+				header := http.Header{}
+				header.Set("last_notification_revision", "10")
+				conn, err := upgrader.Upgrade(rw, req, header)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				// the handler may outlive the test, so failures surface as missing notifications in the test
+				// the first connection is dropped after a notification to test that consuming resumes
+				if conn.WriteJSON(&types.Notification{Revision: int64(10 + connection)}) != nil || connection == 1 {
+					return
+				}
+				if conn.WriteJSON(&types.Notification{Revision: int64(20 + connection)}) != nil {
+					return
+				}
+				// wait for the client to close the connection
+				conn.ReadMessage() // nolint: errcheck
+			})
+		})
+
+		It("resumes consuming after the last handled notification", func() {
+			var handled []int64
+			errStop := errors.New("stop")
+			err := client.ConsumeNotifications(ctx, types.InvalidRevision, func(ctx context.Context, notification *types.Notification) error {
+				handled = append(handled, notification.Revision)
+				if len(handled) == 3 {
+					return errStop
+				}
+				return nil
+			})
+			Expect(err).To(Equal(errStop))
+			Expect(handled).To(Equal([]int64{11, 12, 22}))
+			mutex.Lock()
+			defer mutex.Unlock()
+			Expect(revisions).To(Equal([]string{"", "11"}))
+		})
+
+		It("returns an error if the revision is no longer available", func() {
+			err := client.ConsumeNotifications(ctx, 1, func(ctx context.Context, notification *types.Notification) error {
+				return fmt.Errorf("unexpected notification %d", notification.Revision)
+			})
+			Expect(err).To(Equal(smclient.ErrNotificationRevisionGone))
+		})
+	})
+})
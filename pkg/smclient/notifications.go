@@ -0,0 +1,148 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package smclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const lastNotificationRevisionParam = "last_notification_revision"
+
+// ErrNotificationRevisionGone is returned when the notifications after the requested revision are no longer available.
+// The consumer should resynchronize its state and consume the notifications starting with new ones.
+var ErrNotificationRevisionGone = errors.New("notification revision is no longer available")
+
+// NotificationHandler handles a notification. Consuming the notifications stops if the handler returns an error.
+type NotificationHandler func(ctx context.Context, notification *types.Notification) error
+
+type notificationHandlerError struct {
+	err error
+}
+
+func (e *notificationHandlerError) Error() string {
+	return e.err.Error()
+}
+
+// ConsumeNotifications consumes the notifications for the authenticated platform which come after the specified revision.
+// If the revision is types.InvalidRevision only new notifications are consumed. When the connection is lost, it is
+// reestablished starting after the revision of the last handled notification.
+// ConsumeNotifications blocks until the context is done, the handler returns an error or the revision is no longer
+// available in which case ErrNotificationRevisionGone is returned.
+func (c *Client) ConsumeNotifications(ctx context.Context, revision int64, handler NotificationHandler) error {
+	for {
+		var conn *websocket.Conn
+		var err error
+		conn, revision, err = c.connectNotifications(ctx, revision)
+		if err == nil {
+			revision, err = c.readNotifications(ctx, conn, revision, handler)
+		}
+		if handlerErr, ok := err.(*notificationHandlerError); ok {
+			return handlerErr.err
+		}
+		if err == ErrNotificationRevisionGone {
+			return err
+		}
+		if httpErr, ok := err.(*util.HTTPError); ok && httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.C(ctx).Warnf("Notifications connection lost: %s. Reconnecting in %s...", err, c.settings.PollInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.settings.PollInterval):
+		}
+	}
+}
+
+// connectNotifications connects to the notifications endpoint. If no revision is specified, the revision known to the
+// Service Manager at the time of the connection is returned, so that reconnecting does not miss notifications.
+func (c *Client) connectNotifications(ctx context.Context, revision int64) (*websocket.Conn, int64, error) {
+	wsURL := "ws" + strings.TrimPrefix(c.baseURL, "http") + web.NotificationsURL
+	if revision != types.InvalidRevision {
+		wsURL += "?" + lastNotificationRevisionParam + "=" + strconv.FormatInt(revision, 10)
+	}
+
+	header := http.Header{}
+	if err := c.auth.authorize(header); err != nil {
+		return nil, revision, err
+	}
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: c.settings.Timeout,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: c.settings.SkipSSLValidation},
+	}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		if resp == nil {
+			return nil, revision, err
+		}
+		if resp.StatusCode == http.StatusGone {
+			return nil, revision, ErrNotificationRevisionGone
+		}
+		return nil, revision, responseError(resp)
+	}
+	if revision == types.InvalidRevision {
+		if knownRevision, err := strconv.ParseInt(resp.Header.Get(lastNotificationRevisionParam), 10, 64); err == nil {
+			revision = knownRevision
+		}
+	}
+	return conn, revision, nil
+}
+
+// readNotifications passes the notifications to the handler until the connection is closed and returns the revision of the last handled notification
+func (c *Client) readNotifications(ctx context.Context, conn *websocket.Conn, revision int64, handler NotificationHandler) (int64, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		if err := conn.Close(); err != nil {
+			log.C(ctx).Debugf("Could not close notifications connection: %s", err)
+		}
+	}()
+
+	for {
+		notification := &types.Notification{}
+		if err := conn.ReadJSON(notification); err != nil {
+			return revision, fmt.Errorf("could not read notification: %s", err)
+		}
+		if err := handler(ctx, notification); err != nil {
+			return revision, &notificationHandlerError{err: err}
+		}
+		revision = notification.Revision
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package smclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// OperationFailedError is returned when an awaited operation fails
+type OperationFailedError struct {
+	Operation *types.Operation
+}
+
+func (e *OperationFailedError) Error() string {
+	return fmt.Sprintf("%s operation %s of %s %s failed: %s",
+		e.Operation.Type, e.Operation.ID, e.Operation.ResourceType, e.Operation.ResourceID, string(e.Operation.Errors))
+}
+
+// GetResourceOperation returns the operation with the specified id of a resource
+func (c *Client) GetResourceOperation(ctx context.Context, resourceType types.ObjectType, resourceID, operationID string) (*types.Operation, error) {
+	return c.getOperation(ctx, fmt.Sprintf("%s/%s%s/%s",
+		resourceType, url.PathEscape(resourceID), web.ResourceOperationsURL, url.PathEscape(operationID)))
+}
+
+// WaitForOperation polls the operation at the location returned for asynchronous requests until it is completed.
+// The operation is returned if it succeeded, an *OperationFailedError if it failed.
+func (c *Client) WaitForOperation(ctx context.Context, location string) (*types.Operation, error) {
+	path := location
+	if locationURL, err := url.Parse(location); err == nil && locationURL.IsAbs() {
+		path = locationURL.RequestURI()
+	}
+
+	ticker := time.NewTicker(c.settings.PollInterval)
+	defer ticker.Stop()
+	for {
+		operation, err := c.getOperation(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		switch operation.State {
+		case types.SUCCEEDED:
+			return operation, nil
+		case types.FAILED:
+			return nil, &OperationFailedError{Operation: operation}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for operation %s: %s", operation.ID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// UpdateLabels applies the label changes to the resource of the specified type with the specified id
+func (c *Client) UpdateLabels(ctx context.Context, resourceType types.ObjectType, id string, changes ...*types.LabelChange) error {
+	body := struct {
+		Labels []*types.LabelChange `json:"labels"`
+	}{
+		Labels: changes,
+	}
+	_, err := c.update(ctx, string(resourceType), id, body, nil)
+	return err
+}
+
+func (c *Client) getOperation(ctx context.Context, path string) (*types.Operation, error) {
+	operation := &types.Operation{}
+	if _, err := c.call(ctx, http.MethodGet, path, nil, nil, operation, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
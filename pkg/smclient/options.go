@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package smclient
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const (
+	tokenQueryParam    = "token"
+	maxItemsQueryParam = "max_items"
+	fieldsQueryParam   = "fields"
+	expandQueryParam   = "expand"
+)
+
+// Option customizes a request to the Service Manager
+type Option func(options *requestOptions)
+
+type requestOptions struct {
+	params url.Values
+}
+
+func newRequestOptions(opts ...Option) *requestOptions {
+	options := &requestOptions{
+		params: url.Values{},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithQuery selects the resources matching the criteria. Field and label criteria are sent as fieldQuery and
+// labelQuery, order by criteria as orderBy. The criteria are built using the query package, for example
+// query.ByField(query.EqualsOperator, "name", "my-instance") or query.ByLabel(query.InOperator, "env", "dev", "test").
+func WithQuery(criteria ...query.Criterion) Option {
+	return func(options *requestOptions) {
+		for _, criterionType := range query.CriteriaTypes {
+			appendExpression(options.params, string(criterionType), query.Encode(criterionType, criteria...), " and ")
+		}
+		appendExpression(options.params, query.OrderBy, query.EncodeOrderBy(criteria...), ",")
+	}
+}
+
+// WithFields restricts the attributes of the returned resources
+func WithFields(fields ...string) Option {
+	return func(options *requestOptions) {
+		appendExpression(options.params, fieldsQueryParam, strings.Join(fields, ","), ",")
+	}
+}
+
+// WithExpand embeds the related resources in the returned resources, for example "service_plan.service_offering"
+func WithExpand(relations ...string) Option {
+	return func(options *requestOptions) {
+		appendExpression(options.params, expandQueryParam, strings.Join(relations, ","), ",")
+	}
+}
+
+// WithPageSize sets the number of resources loaded with a single request when listing resources
+func WithPageSize(size int) Option {
+	return WithParam(maxItemsQueryParam, strconv.Itoa(size))
+}
+
+// WithAsync requests synchronous or asynchronous execution of a modification
+func WithAsync(async bool) Option {
+	return WithParam(web.QueryParamAsync, strconv.FormatBool(async))
+}
+
+// WithParam sets a query parameter of the request
+func WithParam(key, value string) Option {
+	return func(options *requestOptions) {
+		options.params.Set(key, value)
+	}
+}
+
+func appendExpression(params url.Values, key, expression, separator string) {
+	if len(expression) == 0 {
+		return
+	}
+	if existing := params.Get(key); len(existing) != 0 {
+		expression = existing + separator + expression
+	}
+	params.Set(key, expression)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package smclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// RotatePlatformCredentials generates new credentials for the platform with the specified id. The returned status
+// contains the new credentials and the time until which the replaced credentials remain valid.
+func (c *Client) RotatePlatformCredentials(ctx context.Context, id string) (*types.PlatformCredentialsStatus, error) {
+	status := &types.PlatformCredentialsStatus{}
+	// the Service Manager accepts POST requests only with a JSON body
+	if _, err := c.call(ctx, http.MethodPost, platformCredentialsPath(id)+"/rotate", nil, struct{}{}, status, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetPlatformCredentialsStatus returns when the credentials of the platform with the specified id were last used
+// and until when the credentials replaced by the last rotation remain valid
+func (c *Client) GetPlatformCredentialsStatus(ctx context.Context, id string) (*types.PlatformCredentialsStatus, error) {
+	status := &types.PlatformCredentialsStatus{}
+	if _, err := c.call(ctx, http.MethodGet, platformCredentialsPath(id), nil, nil, status, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// RevokeOldPlatformCredentials revokes the credentials replaced by the last rotation of the platform with the specified id
+func (c *Client) RevokeOldPlatformCredentials(ctx context.Context, id string) error {
+	_, err := c.call(ctx, http.MethodDelete, platformCredentialsPath(id)+"/old", nil, nil, nil, http.StatusOK)
+	return err
+}
+
+func platformCredentialsPath(id string) string {
+	return web.PlatformsURL + "/" + url.PathEscape(id) + web.PlatformCredentialsURL
+}
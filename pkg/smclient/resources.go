@@ -0,0 +1,396 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package smclient
+
+import (
+	"context"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// ListServiceBrokers returns all service brokers matching the options. All pages are loaded.
+func (c *Client) ListServiceBrokers(ctx context.Context, opts ...Option) ([]*types.ServiceBroker, error) {
+	var serviceBrokers []*types.ServiceBroker
+	if err := c.list(ctx, web.ServiceBrokersURL, &serviceBrokers, opts...); err != nil {
+		return nil, err
+	}
+	return serviceBrokers, nil
+}
+
+// GetServiceBroker returns the service broker with the specified id
+func (c *Client) GetServiceBroker(ctx context.Context, id string, opts ...Option) (*types.ServiceBroker, error) {
+	serviceBroker := &types.ServiceBroker{}
+	if err := c.get(ctx, web.ServiceBrokersURL, id, serviceBroker, opts...); err != nil {
+		return nil, err
+	}
+	return serviceBroker, nil
+}
+
+// CreateServiceBroker creates a service broker. If the request is executed asynchronously no service broker is returned, but the
+// location of the operation which can be awaited with WaitForOperation.
+func (c *Client) CreateServiceBroker(ctx context.Context, serviceBroker *types.ServiceBroker, opts ...Option) (*types.ServiceBroker, string, error) {
+	result := &types.ServiceBroker{}
+	location, err := c.create(ctx, web.ServiceBrokersURL, serviceBroker, result, opts...)
+	if err != nil || len(location) != 0 {
+		return nil, location, err
+	}
+	return result, "", nil
+}
+
+// UpdateServiceBroker applies the changes to the service broker with the specified id. The changes are a JSON object with the
+// attributes to modify. If the request is executed asynchronously no service broker is returned, but the location of the
+// operation which can be awaited with WaitForOperation.
+func (c *Client) UpdateServiceBroker(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.ServiceBroker, string, error) {
+	result := &types.ServiceBroker{}
+	location, err := c.update(ctx, web.ServiceBrokersURL, id, changes, result, opts...)
+	if err != nil || len(location) != 0 {
+		return nil, location, err
+	}
+	return result, "", nil
+}
+
+// DeleteServiceBroker deletes the service broker with the specified id. If the request is executed asynchronously the location
+// of the operation which can be awaited with WaitForOperation is returned.
+func (c *Client) DeleteServiceBroker(ctx context.Context, id string, opts ...Option) (string, error) {
+	return c.delete(ctx, web.ServiceBrokersURL, id, opts...)
+}
+
+// ListPlatforms returns all platforms matching the options. All pages are loaded.
+func (c *Client) ListPlatforms(ctx context.Context, opts ...Option) ([]*types.Platform, error) {
+	var platforms []*types.Platform
+	if err := c.list(ctx, web.PlatformsURL, &platforms, opts...); err != nil {
+		return nil, err
+	}
+	return platforms, nil
+}
+
+// GetPlatform returns the platform with the specified id
+func (c *Client) GetPlatform(ctx context.Context, id string, opts ...Option) (*types.Platform, error) {
+	platform := &types.Platform{}
+	if err := c.get(ctx, web.PlatformsURL, id, platform, opts...); err != nil {
+		return nil, err
+	}
+	return platform, nil
+}
+
+// CreatePlatform creates a platform
+func (c *Client) CreatePlatform(ctx context.Context, platform *types.Platform, opts ...Option) (*types.Platform, error) {
+	result := &types.Platform{}
+	if _, err := c.create(ctx, web.PlatformsURL, platform, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdatePlatform applies the changes to the platform with the specified id. The changes are a JSON object with the
+// attributes to modify.
+func (c *Client) UpdatePlatform(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.Platform, error) {
+	result := &types.Platform{}
+	if _, err := c.update(ctx, web.PlatformsURL, id, changes, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeletePlatform deletes the platform with the specified id
+func (c *Client) DeletePlatform(ctx context.Context, id string, opts ...Option) error {
+	_, err := c.delete(ctx, web.PlatformsURL, id, opts...)
+	return err
+}
+
+// ListVisibilities returns all visibilities matching the options. All pages are loaded.
+func (c *Client) ListVisibilities(ctx context.Context, opts ...Option) ([]*types.Visibility, error) {
+	var visibilities []*types.Visibility
+	if err := c.list(ctx, web.VisibilitiesURL, &visibilities, opts...); err != nil {
+		return nil, err
+	}
+	return visibilities, nil
+}
+
+// GetVisibility returns the visibility with the specified id
+func (c *Client) GetVisibility(ctx context.Context, id string, opts ...Option) (*types.Visibility, error) {
+	visibility := &types.Visibility{}
+	if err := c.get(ctx, web.VisibilitiesURL, id, visibility, opts...); err != nil {
+		return nil, err
+	}
+	return visibility, nil
+}
+
+// CreateVisibility creates a visibility
+func (c *Client) CreateVisibility(ctx context.Context, visibility *types.Visibility, opts ...Option) (*types.Visibility, error) {
+	result := &types.Visibility{}
+	if _, err := c.create(ctx, web.VisibilitiesURL, visibility, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateVisibility applies the changes to the visibility with the specified id. The changes are a JSON object with the
+// attributes to modify.
+func (c *Client) UpdateVisibility(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.Visibility, error) {
+	result := &types.Visibility{}
+	if _, err := c.update(ctx, web.VisibilitiesURL, id, changes, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteVisibility deletes the visibility with the specified id
+func (c *Client) DeleteVisibility(ctx context.Context, id string, opts ...Option) error {
+	_, err := c.delete(ctx, web.VisibilitiesURL, id, opts...)
+	return err
+}
+
+// ListServiceOfferings returns all service offerings matching the options. All pages are loaded.
+func (c *Client) ListServiceOfferings(ctx context.Context, opts ...Option) ([]*types.ServiceOffering, error) {
+	var serviceOfferings []*types.ServiceOffering
+	if err := c.list(ctx, web.ServiceOfferingsURL, &serviceOfferings, opts...); err != nil {
+		return nil, err
+	}
+	return serviceOfferings, nil
+}
+
+// GetServiceOffering returns the service offering with the specified id
+func (c *Client) GetServiceOffering(ctx context.Context, id string, opts ...Option) (*types.ServiceOffering, error) {
+	serviceOffering := &types.ServiceOffering{}
+	if err := c.get(ctx, web.ServiceOfferingsURL, id, serviceOffering, opts...); err != nil {
+		return nil, err
+	}
+	return serviceOffering, nil
+}
+
+// UpdateServiceOffering applies the changes to the service offering with the specified id. The changes are a JSON object with the
+// attributes to modify.
+func (c *Client) UpdateServiceOffering(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.ServiceOffering, error) {
+	result := &types.ServiceOffering{}
+	if _, err := c.update(ctx, web.ServiceOfferingsURL, id, changes, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListServicePlans returns all service plans matching the options. All pages are loaded.
+func (c *Client) ListServicePlans(ctx context.Context, opts ...Option) ([]*types.ServicePlan, error) {
+	var servicePlans []*types.ServicePlan
+	if err := c.list(ctx, web.ServicePlansURL, &servicePlans, opts...); err != nil {
+		return nil, err
+	}
+	return servicePlans, nil
+}
+
+// GetServicePlan returns the service plan with the specified id
+func (c *Client) GetServicePlan(ctx context.Context, id string, opts ...Option) (*types.ServicePlan, error) {
+	servicePlan := &types.ServicePlan{}
+	if err := c.get(ctx, web.ServicePlansURL, id, servicePlan, opts...); err != nil {
+		return nil, err
+	}
+	return servicePlan, nil
+}
+
+// UpdateServicePlan applies the changes to the service plan with the specified id. The changes are a JSON object with the
+// attributes to modify.
+func (c *Client) UpdateServicePlan(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.ServicePlan, error) {
+	result := &types.ServicePlan{}
+	if _, err := c.update(ctx, web.ServicePlansURL, id, changes, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListServiceInstances returns all service instances matching the options. All pages are loaded.
+func (c *Client) ListServiceInstances(ctx context.Context, opts ...Option) ([]*types.ServiceInstance, error) {
+	var serviceInstances []*types.ServiceInstance
+	if err := c.list(ctx, web.ServiceInstancesURL, &serviceInstances, opts...); err != nil {
+		return nil, err
+	}
+	return serviceInstances, nil
+}
+
+// GetServiceInstance returns the service instance with the specified id
+func (c *Client) GetServiceInstance(ctx context.Context, id string, opts ...Option) (*types.ServiceInstance, error) {
+	serviceInstance := &types.ServiceInstance{}
+	if err := c.get(ctx, web.ServiceInstancesURL, id, serviceInstance, opts...); err != nil {
+		return nil, err
+	}
+	return serviceInstance, nil
+}
+
+// CreateServiceInstance creates a service instance. If the request is executed asynchronously no service instance is returned, but the
+// location of the operation which can be awaited with WaitForOperation.
+func (c *Client) CreateServiceInstance(ctx context.Context, serviceInstance *types.ServiceInstance, opts ...Option) (*types.ServiceInstance, string, error) {
+	result := &types.ServiceInstance{}
+	location, err := c.create(ctx, web.ServiceInstancesURL, serviceInstance, result, opts...)
+	if err != nil || len(location) != 0 {
+		return nil, location, err
+	}
+	return result, "", nil
+}
+
+// UpdateServiceInstance applies the changes to the service instance with the specified id. The changes are a JSON object with the
+// attributes to modify. If the request is executed asynchronously no service instance is returned, but the location of the
+// operation which can be awaited with WaitForOperation.
+func (c *Client) UpdateServiceInstance(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.ServiceInstance, string, error) {
+	result := &types.ServiceInstance{}
+	location, err := c.update(ctx, web.ServiceInstancesURL, id, changes, result, opts...)
+	if err != nil || len(location) != 0 {
+		return nil, location, err
+	}
+	return result, "", nil
+}
+
+// DeleteServiceInstance deletes the service instance with the specified id. If the request is executed asynchronously the location
+// of the operation which can be awaited with WaitForOperation is returned.
+func (c *Client) DeleteServiceInstance(ctx context.Context, id string, opts ...Option) (string, error) {
+	return c.delete(ctx, web.ServiceInstancesURL, id, opts...)
+}
+
+// ListServiceBindings returns all service bindings matching the options. All pages are loaded.
+func (c *Client) ListServiceBindings(ctx context.Context, opts ...Option) ([]*types.ServiceBinding, error) {
+	var serviceBindings []*types.ServiceBinding
+	if err := c.list(ctx, web.ServiceBindingsURL, &serviceBindings, opts...); err != nil {
+		return nil, err
+	}
+	return serviceBindings, nil
+}
+
+// GetServiceBinding returns the service binding with the specified id
+func (c *Client) GetServiceBinding(ctx context.Context, id string, opts ...Option) (*types.ServiceBinding, error) {
+	serviceBinding := &types.ServiceBinding{}
+	if err := c.get(ctx, web.ServiceBindingsURL, id, serviceBinding, opts...); err != nil {
+		return nil, err
+	}
+	return serviceBinding, nil
+}
+
+// CreateServiceBinding creates a service binding. If the request is executed asynchronously no service binding is returned, but the
+// location of the operation which can be awaited with WaitForOperation.
+func (c *Client) CreateServiceBinding(ctx context.Context, serviceBinding *types.ServiceBinding, opts ...Option) (*types.ServiceBinding, string, error) {
+	result := &types.ServiceBinding{}
+	location, err := c.create(ctx, web.ServiceBindingsURL, serviceBinding, result, opts...)
+	if err != nil || len(location) != 0 {
+		return nil, location, err
+	}
+	return result, "", nil
+}
+
+// DeleteServiceBinding deletes the service binding with the specified id. If the request is executed asynchronously the location
+// of the operation which can be awaited with WaitForOperation is returned.
+func (c *Client) DeleteServiceBinding(ctx context.Context, id string, opts ...Option) (string, error) {
+	return c.delete(ctx, web.ServiceBindingsURL, id, opts...)
+}
+
+// ListRoles returns all roles matching the options. All pages are loaded.
+func (c *Client) ListRoles(ctx context.Context, opts ...Option) ([]*types.Role, error) {
+	var roles []*types.Role
+	if err := c.list(ctx, web.RolesURL, &roles, opts...); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetRole returns the role with the specified id
+func (c *Client) GetRole(ctx context.Context, id string, opts ...Option) (*types.Role, error) {
+	role := &types.Role{}
+	if err := c.get(ctx, web.RolesURL, id, role, opts...); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// CreateRole creates a role
+func (c *Client) CreateRole(ctx context.Context, role *types.Role, opts ...Option) (*types.Role, error) {
+	result := &types.Role{}
+	if _, err := c.create(ctx, web.RolesURL, role, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateRole applies the changes to the role with the specified id. The changes are a JSON object with the
+// attributes to modify.
+func (c *Client) UpdateRole(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.Role, error) {
+	result := &types.Role{}
+	if _, err := c.update(ctx, web.RolesURL, id, changes, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteRole deletes the role with the specified id
+func (c *Client) DeleteRole(ctx context.Context, id string, opts ...Option) error {
+	_, err := c.delete(ctx, web.RolesURL, id, opts...)
+	return err
+}
+
+// ListLabelDefinitions returns all label definitions matching the options. All pages are loaded.
+func (c *Client) ListLabelDefinitions(ctx context.Context, opts ...Option) ([]*types.LabelDefinition, error) {
+	var definitions []*types.LabelDefinition
+	if err := c.list(ctx, web.LabelDefinitionsURL, &definitions, opts...); err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// GetLabelDefinition returns the label definition with the specified id
+func (c *Client) GetLabelDefinition(ctx context.Context, id string, opts ...Option) (*types.LabelDefinition, error) {
+	definition := &types.LabelDefinition{}
+	if err := c.get(ctx, web.LabelDefinitionsURL, id, definition, opts...); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// CreateLabelDefinition creates a label definition
+func (c *Client) CreateLabelDefinition(ctx context.Context, definition *types.LabelDefinition, opts ...Option) (*types.LabelDefinition, error) {
+	result := &types.LabelDefinition{}
+	if _, err := c.create(ctx, web.LabelDefinitionsURL, definition, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateLabelDefinition applies the changes to the label definition with the specified id. The changes are a JSON object with the
+// attributes to modify.
+func (c *Client) UpdateLabelDefinition(ctx context.Context, id string, changes interface{}, opts ...Option) (*types.LabelDefinition, error) {
+	result := &types.LabelDefinition{}
+	if _, err := c.update(ctx, web.LabelDefinitionsURL, id, changes, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteLabelDefinition deletes the label definition with the specified id
+func (c *Client) DeleteLabelDefinition(ctx context.Context, id string, opts ...Option) error {
+	_, err := c.delete(ctx, web.LabelDefinitionsURL, id, opts...)
+	return err
+}
+
+// ListOperations returns all operations matching the options. All pages are loaded.
+func (c *Client) ListOperations(ctx context.Context, opts ...Option) ([]*types.Operation, error) {
+	var operations []*types.Operation
+	if err := c.list(ctx, web.OperationsURL, &operations, opts...); err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+// DeleteOperation deletes the operation with the specified id
+func (c *Client) DeleteOperation(ctx context.Context, id string, opts ...Option) error {
+	_, err := c.delete(ctx, web.OperationsURL, id, opts...)
+	return err
+}
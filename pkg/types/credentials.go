@@ -19,9 +19,11 @@ package types
 import (
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"strings"
 )
 
 // Basic basic credentials
@@ -31,8 +33,48 @@ type Basic struct {
 }
 
 type TLS struct {
-	Certificate string `json:"client_certificate,omitempty"`
-	Key         string `json:"client_key,omitempty"`
+	Certificate   string `json:"client_certificate,omitempty"`
+	Key           string `json:"client_key,omitempty"`
+	CACertificate string `json:"ca_certificate,omitempty"`
+	ServerName    string `json:"server_name,omitempty"`
+}
+
+// HasClientCertificate returns true if both a client certificate and a client key are provided
+func (t *TLS) HasClientCertificate() bool {
+	return t.Certificate != "" && t.Key != ""
+}
+
+// HasServerVerification returns true if a CA bundle or a server name is provided for verifying the broker
+func (t *TLS) HasServerVerification() bool {
+	return t.CACertificate != "" || t.ServerName != ""
+}
+
+// CertPool returns a certificate pool containing the provided CA bundle or nil if no CA bundle is provided
+func (t *TLS) CertPool() (*x509.CertPool, error) {
+	if t.CACertificate == "" {
+		return nil, nil
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM([]byte(t.CACertificate)) {
+		return nil, errors.New("failed to find any PEM certificates in ca_certificate input")
+	}
+	return certPool, nil
+}
+
+// Validate implements InputValidator and verifies the client certificate, CA bundle and server name are valid
+func (t *TLS) Validate() error {
+	if t.Certificate != "" || t.Key != "" || !t.HasServerVerification() {
+		if _, err := tls.X509KeyPair([]byte(t.Certificate), []byte(t.Key)); err != nil {
+			return errors.New("invalidate TLS configuration: " + err.Error())
+		}
+	}
+	if _, err := t.CertPool(); err != nil {
+		return errors.New("invalidate TLS configuration: " + err.Error())
+	}
+	if strings.ContainsAny(t.ServerName, "/: ") {
+		return errors.New("invalidate TLS configuration: server_name must be a host name without scheme or port")
+	}
+	return nil
 }
 
 // Credentials credentials
@@ -49,7 +91,7 @@ func (c *Credentials) MarshalJSON() ([]byte, error) {
 		toMarshal.Basic = nil
 	}
 
-	if toMarshal.TLS == nil || toMarshal.TLS.Certificate == "" || toMarshal.TLS.Key == "" {
+	if toMarshal.TLS == nil || (!toMarshal.TLS.HasClientCertificate() && !toMarshal.TLS.HasServerVerification()) {
 		toMarshal.TLS = nil
 	}
 
@@ -68,13 +110,12 @@ func (c *Credentials) Validate() error {
 	}
 
 	if c.TLS != nil {
-		_, err := tls.X509KeyPair([]byte(c.TLS.Certificate), []byte(c.TLS.Key))
-		if err != nil {
-			return errors.New("invalidate TLS configuration: " + err.Error())
+		if err := c.TLS.Validate(); err != nil {
+			return err
 		}
 	}
 
-	if c.TLS == nil && c.Basic == nil {
+	if c.Basic == nil && (c.TLS == nil || !c.TLS.HasClientCertificate()) {
 		return errors.New("missing broker credentials, basic or tls credentials are required")
 	}
 
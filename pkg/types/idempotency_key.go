@@ -0,0 +1,38 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey holds the response of a request sent with an Idempotency-Key header, so that the response can be
+// replayed when the request is repeated with the same key
+type IdempotencyKey struct {
+	// UserName is the name of the user who sent the request. The keys of different users do not clash.
+	UserName string
+	Key      string
+	// RequestHash identifies the request for which the key has been used
+	RequestHash string
+	ExpiresAt   time.Time
+	// Completed is false while the request for which the key has been used is being processed
+	Completed  bool
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
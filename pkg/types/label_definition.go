@@ -0,0 +1,191 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// LabelValueType is the type of the values of a label
+type LabelValueType string
+
+const (
+	// LabelValueString allows any value
+	LabelValueString LabelValueType = "string"
+	// LabelValueInt allows integer values. Label queries comparing such labels compare the values as numbers
+	LabelValueInt LabelValueType = "int"
+	// LabelValueBool allows the values true and false
+	LabelValueBool LabelValueType = "bool"
+	// LabelValueEnum allows only the values listed in the definition
+	LabelValueEnum LabelValueType = "enum"
+	// LabelValueRegex allows the values which match entirely the pattern of the definition
+	LabelValueRegex LabelValueType = "regex"
+)
+
+const (
+	// LabelCardinalitySingle allows at most one value of the label
+	LabelCardinalitySingle = "single"
+	// LabelCardinalityMultiple allows any number of values of the label
+	LabelCardinalityMultiple = "multiple"
+)
+
+// LabelledTypes are the types of the resources whose labels can be defined by label definitions
+var LabelledTypes = []ObjectType{PlatformType, ServiceBrokerType, ServiceOfferingType, ServicePlanType, VisibilityType, ServiceInstanceType, ServiceBindingType}
+
+//go:generate smgen api LabelDefinition
+// LabelDefinition declares the key, the type of the values and the resource types of a label.
+// The labels of the resources of these types are validated against the definition.
+type LabelDefinition struct {
+	Base
+	Key           string         `json:"key"`
+	Description   string         `json:"description"`
+	ResourceTypes []ObjectType   `json:"resource_types"`
+	ValueType     LabelValueType `json:"value_type"`
+	AllowedValues []string       `json:"allowed_values,omitempty"`
+	Pattern       string         `json:"pattern,omitempty"`
+	Cardinality   string         `json:"cardinality,omitempty"`
+	Required      bool           `json:"required"`
+}
+
+func (e *LabelDefinition) Equals(obj Object) bool {
+	if !Equals(e, obj) {
+		return false
+	}
+
+	definition := obj.(*LabelDefinition)
+	if e.Key != definition.Key ||
+		e.Description != definition.Description ||
+		e.ValueType != definition.ValueType ||
+		e.Pattern != definition.Pattern ||
+		e.Cardinality != definition.Cardinality ||
+		e.Required != definition.Required ||
+		!reflect.DeepEqual(e.ResourceTypes, definition.ResourceTypes) ||
+		!reflect.DeepEqual(e.AllowedValues, definition.AllowedValues) {
+		return false
+	}
+
+	return true
+}
+
+// Validate implements InputValidator and verifies all mandatory fields are populated
+func (e *LabelDefinition) Validate() error {
+	if util.HasRFC3986ReservedSymbols(e.ID) {
+		return fmt.Errorf("%s contains invalid character(s)", e.ID)
+	}
+	if e.Key == "" {
+		return errors.New("missing label definition key")
+	}
+	if strings.ContainsAny(e.Key, " \n") {
+		return fmt.Errorf("label key \"%s\" cannot contain whitespaces", e.Key)
+	}
+	if len(e.ResourceTypes) == 0 {
+		return fmt.Errorf("label definition %s has no resource types", e.Key)
+	}
+	for _, resourceType := range e.ResourceTypes {
+		if !isLabelledType(resourceType) {
+			return fmt.Errorf("unsupported resource type %s for label definition %s", resourceType, e.Key)
+		}
+	}
+	switch e.ValueType {
+	case LabelValueString, LabelValueInt, LabelValueBool:
+	case LabelValueEnum:
+		if len(e.AllowedValues) == 0 {
+			return fmt.Errorf("enum label definition %s has no allowed values", e.Key)
+		}
+	case LabelValueRegex:
+		if _, err := regexp.Compile(e.Pattern); err != nil || e.Pattern == "" {
+			return fmt.Errorf("regex label definition %s has invalid pattern %s", e.Key, e.Pattern)
+		}
+	default:
+		return fmt.Errorf("unsupported value type %s for label definition %s", e.ValueType, e.Key)
+	}
+	switch e.Cardinality {
+	case "", LabelCardinalitySingle, LabelCardinalityMultiple:
+	default:
+		return fmt.Errorf("unsupported cardinality %s for label definition %s", e.Cardinality, e.Key)
+	}
+	if err := e.Labels.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppliesTo checks whether the labels of the resources of the type are validated against the definition
+func (e *LabelDefinition) AppliesTo(objectType ObjectType) bool {
+	for _, resourceType := range e.ResourceTypes {
+		if resourceType == objectType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateValues verifies that the values of the label match the type and the cardinality of the definition
+func (e *LabelDefinition) ValidateValues(values []string) error {
+	if e.Cardinality == LabelCardinalitySingle && len(values) > 1 {
+		return fmt.Errorf("label %s allows a single value but received %s", e.Key, values)
+	}
+	for _, value := range values {
+		if err := e.ValidateValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateValue verifies that the value matches the type of the definition
+func (e *LabelDefinition) ValidateValue(value string) error {
+	valid := true
+	switch e.ValueType {
+	case LabelValueInt:
+		_, err := strconv.ParseInt(value, 10, 64)
+		valid = err == nil
+	case LabelValueBool:
+		valid = value == "true" || value == "false"
+	case LabelValueEnum:
+		valid = false
+		for _, allowedValue := range e.AllowedValues {
+			if allowedValue == value {
+				valid = true
+				break
+			}
+		}
+	case LabelValueRegex:
+		pattern, err := regexp.Compile("^(?:" + e.Pattern + ")$")
+		valid = err == nil && pattern.MatchString(value)
+	}
+	if !valid {
+		return fmt.Errorf("value \"%s\" of label %s is not a valid %s", value, e.Key, e.ValueType)
+	}
+	return nil
+}
+
+func isLabelledType(objectType ObjectType) bool {
+	for _, labelledType := range LabelledTypes {
+		if labelledType == objectType {
+			return true
+		}
+	}
+	return false
+}
@@ -39,6 +39,13 @@ func (l Labels) Validate() error {
 	return nil
 }
 
+// LabelValueCount is the number of objects which have a label with the specified value
+type LabelValueCount struct {
+	Key   string `json:"key" db:"key"`
+	Value string `json:"value" db:"value"`
+	Count int    `json:"count" db:"count"`
+}
+
 // LabelOperation is an operation to be performed on labels
 type LabelOperation string
 
@@ -0,0 +1,27 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import "time"
+
+// MaintenanceMode is the mode in which all Service Manager instances serve the requests during maintenance
+type MaintenanceMode struct {
+	// ReadOnly is whether the requests changing the state of the Service Manager are rejected
+	ReadOnly  bool      `json:"read_only" db:"read_only"`
+	Reason    string    `json:"reason,omitempty" db:"reason"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
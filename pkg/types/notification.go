@@ -37,6 +37,9 @@ const (
 	// DELETED represents a notification type for deleting a resource
 	DELETED NotificationOperation = "DELETED"
 
+	// SNAPSHOT_END represents a notification type marking the end of a snapshot of the current state
+	SNAPSHOT_END NotificationOperation = "SNAPSHOT_END"
+
 	// InvalidRevision revision with invalid value
 	InvalidRevision int64 = -1
 )
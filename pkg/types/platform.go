@@ -42,6 +42,43 @@ type Platform struct {
 	Credentials *Credentials `json:"credentials,omitempty"`
 	Active      bool         `json:"-"`
 	LastActive  time.Time    `json:"-"`
+
+	// OldCredentials are the credentials replaced by the last rotation which remain valid until OldCredentialsExpireAt
+	OldCredentials           *Credentials `json:"-"`
+	OldCredentialsExpireAt   time.Time    `json:"-"`
+	OldCredentialsLastUsedAt time.Time    `json:"-"`
+	CredentialsLastUsedAt    time.Time    `json:"-"`
+
+	// AcknowledgedNotificationRevision is the revision of the last notification acknowledged by the platform, 0 if
+	// the platform does not acknowledge notifications
+	AcknowledgedNotificationRevision int64 `json:"-"`
+	// NotificationsLag is the number of notifications for the platform after the acknowledged revision.
+	// It is calculated when the platform is fetched and only for platforms which acknowledge notifications.
+	NotificationsLag *int64 `json:"notifications_lag,omitempty"`
+}
+
+// PlatformCredentialsStatus describes the credentials of a platform and the credentials replaced by the last rotation.
+// The credentials are only returned when they are generated.
+type PlatformCredentialsStatus struct {
+	Credentials              *Credentials `json:"credentials,omitempty"`
+	CredentialsLastUsedAt    *time.Time   `json:"credentials_last_used_at,omitempty"`
+	OldCredentialsExpireAt   *time.Time   `json:"old_credentials_expire_at,omitempty"`
+	OldCredentialsLastUsedAt *time.Time   `json:"old_credentials_last_used_at,omitempty"`
+}
+
+// CredentialsStatus returns the status of the credentials of the platform without the credentials themselves
+func (e *Platform) CredentialsStatus() *PlatformCredentialsStatus {
+	status := &PlatformCredentialsStatus{}
+	if !e.CredentialsLastUsedAt.IsZero() {
+		status.CredentialsLastUsedAt = &e.CredentialsLastUsedAt
+	}
+	if e.HasValidOldCredentials() {
+		status.OldCredentialsExpireAt = &e.OldCredentialsExpireAt
+		if !e.OldCredentialsLastUsedAt.IsZero() {
+			status.OldCredentialsLastUsedAt = &e.OldCredentialsLastUsedAt
+		}
+	}
+	return status
 }
 
 func (e *Platform) Equals(obj Object) bool {
@@ -55,7 +92,9 @@ func (e *Platform) Equals(obj Object) bool {
 		e.Name != platform.Name ||
 		e.Active != platform.Active ||
 		!e.LastActive.Equal(platform.LastActive) ||
-		!reflect.DeepEqual(e.Credentials, platform.Credentials) {
+		!reflect.DeepEqual(e.Credentials, platform.Credentials) ||
+		!reflect.DeepEqual(e.OldCredentials, platform.OldCredentials) ||
+		!e.OldCredentialsExpireAt.Equal(platform.OldCredentialsExpireAt) {
 		return false
 	}
 
@@ -64,6 +103,13 @@ func (e *Platform) Equals(obj Object) bool {
 
 func (e *Platform) Sanitize() {
 	e.Credentials = nil
+	e.OldCredentials = nil
+}
+
+// HasValidOldCredentials returns true if the credentials replaced by the last rotation are still valid
+func (e *Platform) HasValidOldCredentials() bool {
+	return e.OldCredentials != nil && e.OldCredentials.Basic != nil && e.OldCredentials.Basic.Username != "" &&
+		time.Now().Before(e.OldCredentialsExpireAt)
 }
 
 func (e *Platform) Encrypt(ctx context.Context, encryptionFunc func(context.Context, []byte) ([]byte, error)) error {
@@ -75,19 +121,25 @@ func (e *Platform) Decrypt(ctx context.Context, decryptionFunc func(context.Cont
 }
 
 func (e *Platform) transform(ctx context.Context, transformationFunc func(context.Context, []byte) ([]byte, error)) error {
-	if e.Credentials == nil || e.Credentials.Basic == nil {
-		return nil
+	for _, credentials := range []*Credentials{e.Credentials, e.OldCredentials} {
+		if credentials == nil || credentials.Basic == nil || credentials.Basic.Password == "" {
+			continue
+		}
+		transformedPassword, err := transformationFunc(ctx, []byte(credentials.Basic.Password))
+		if err != nil {
+			return err
+		}
+		credentials.Basic.Password = string(transformedPassword)
 	}
-	transformedPassword, err := transformationFunc(ctx, []byte(e.Credentials.Basic.Password))
-	if err != nil {
-		return err
-	}
-	e.Credentials.Basic.Password = string(transformedPassword)
 	return nil
 }
 
 func (e *Platform) IntegralData() []byte {
-	return []byte(fmt.Sprintf("%s:%s", e.Credentials.Basic.Username, e.Credentials.Basic.Password))
+	data := fmt.Sprintf("%s:%s", e.Credentials.Basic.Username, e.Credentials.Basic.Password)
+	if e.OldCredentials != nil && e.OldCredentials.Basic != nil && e.OldCredentials.Basic.Username != "" {
+		data += fmt.Sprintf(":%s:%s:%d", e.OldCredentials.Basic.Username, e.OldCredentials.Basic.Password, e.OldCredentialsExpireAt.Unix())
+	}
+	return []byte(data)
 }
 
 func (e *Platform) SetIntegrity(integrity []byte) {
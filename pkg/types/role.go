@@ -0,0 +1,174 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// Action is an operation that a permission grants on a resource type
+type Action string
+
+const (
+	// ActionGet grants fetching a single resource
+	ActionGet Action = "get"
+	// ActionList grants listing resources
+	ActionList Action = "list"
+	// ActionCreate grants creating resources
+	ActionCreate Action = "create"
+	// ActionUpdate grants updating resources
+	ActionUpdate Action = "update"
+	// ActionDelete grants deleting resources
+	ActionDelete Action = "delete"
+	// ActionAll grants all actions
+	ActionAll Action = "*"
+)
+
+// AnyResourceType is used in permissions which apply to all resource types
+const AnyResourceType ObjectType = "*"
+
+var supportedActions = []Action{ActionGet, ActionList, ActionCreate, ActionUpdate, ActionDelete, ActionAll}
+
+const (
+	// RoleTenantAccess is the access level of roles which operate on the resources of a single tenant
+	RoleTenantAccess = "tenant"
+	// RoleAllTenantsAccess is the access level of roles which operate on the resources of all tenants
+	RoleAllTenantsAccess = "all_tenants"
+	// RoleGlobalAccess is the access level of roles which operate on global resources
+	RoleGlobalAccess = "global"
+)
+
+// Permission grants a set of actions on a resource type. If a label selector is specified, the permission
+// applies only to the resources matching it
+type Permission struct {
+	ResourceType  ObjectType `json:"resource_type"`
+	Actions       []Action   `json:"actions"`
+	LabelSelector string     `json:"label_selector,omitempty"`
+}
+
+// Grants checks whether the permission grants the action on the resource type
+func (p *Permission) Grants(resourceType ObjectType, action Action) bool {
+	if p.ResourceType != AnyResourceType && p.ResourceType != resourceType {
+		return false
+	}
+	for _, a := range p.Actions {
+		if a == ActionAll || a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate verifies that the permission specifies a resource type and supported actions
+func (p *Permission) Validate() error {
+	if p.ResourceType == "" {
+		return errors.New("missing permission resource type")
+	}
+	if len(p.Actions) == 0 {
+		return fmt.Errorf("permission for %s has no actions", p.ResourceType)
+	}
+	for _, action := range p.Actions {
+		if !isSupportedAction(action) {
+			return fmt.Errorf("unsupported action %s in permission for %s", action, p.ResourceType)
+		}
+	}
+	return nil
+}
+
+// RoleBinding assigns a role to the users whose token claim has the specified value.
+// If the claim is an array (for example groups), it is enough that one of its elements matches
+type RoleBinding struct {
+	Claim string `json:"claim"`
+	Value string `json:"value"`
+}
+
+//go:generate smgen api Role
+// Role struct
+type Role struct {
+	Base
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	AccessLevel string         `json:"access_level,omitempty"`
+	Permissions []*Permission  `json:"permissions"`
+	Bindings    []*RoleBinding `json:"bindings"`
+}
+
+func (e *Role) Equals(obj Object) bool {
+	if !Equals(e, obj) {
+		return false
+	}
+
+	role := obj.(*Role)
+	if e.Name != role.Name ||
+		e.Description != role.Description ||
+		e.AccessLevel != role.AccessLevel ||
+		!reflect.DeepEqual(e.Permissions, role.Permissions) ||
+		!reflect.DeepEqual(e.Bindings, role.Bindings) {
+		return false
+	}
+
+	return true
+}
+
+// Validate implements InputValidator and verifies all mandatory fields are populated
+func (e *Role) Validate() error {
+	if util.HasRFC3986ReservedSymbols(e.ID) {
+		return fmt.Errorf("%s contains invalid character(s)", e.ID)
+	}
+	if e.Name == "" {
+		return errors.New("missing role name")
+	}
+	switch e.AccessLevel {
+	case "", RoleTenantAccess, RoleAllTenantsAccess, RoleGlobalAccess:
+	default:
+		return fmt.Errorf("unsupported access level %s for role %s", e.AccessLevel, e.Name)
+	}
+	if len(e.Permissions) == 0 {
+		return fmt.Errorf("role %s has no permissions", e.Name)
+	}
+	for _, permission := range e.Permissions {
+		if permission == nil {
+			return fmt.Errorf("role %s contains an empty permission", e.Name)
+		}
+		if err := permission.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, binding := range e.Bindings {
+		if binding == nil || strings.TrimSpace(binding.Claim) == "" || binding.Value == "" {
+			return fmt.Errorf("role %s contains a binding without claim or value", e.Name)
+		}
+	}
+	if err := e.Labels.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func isSupportedAction(action Action) bool {
+	for _, supported := range supportedActions {
+		if supported == action {
+			return true
+		}
+	}
+	return false
+}
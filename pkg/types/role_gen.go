@@ -0,0 +1,65 @@
+// GENERATED. DO NOT MODIFY!
+
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const RoleType ObjectType = web.RolesURL
+
+type Roles struct {
+	Roles []*Role `json:"roles"`
+}
+
+func (e *Roles) Add(object Object) {
+	e.Roles = append(e.Roles, object.(*Role))
+}
+
+func (e *Roles) ItemAt(index int) Object {
+	return e.Roles[index]
+}
+
+func (e *Roles) Len() int {
+	return len(e.Roles)
+}
+
+func (e *Role) GetType() ObjectType {
+	return RoleType
+}
+
+// MarshalJSON override json serialization for http response
+func (e *Role) MarshalJSON() ([]byte, error) {
+	type E Role
+	toMarshal := struct {
+		*E
+		CreatedAt *string `json:"created_at,omitempty"`
+		UpdatedAt *string `json:"updated_at,omitempty"`
+		Labels    Labels  `json:"labels,omitempty"`
+	}{
+		E:      (*E)(e),
+		Labels: e.Labels,
+	}
+	if !e.CreatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.CreatedAt)
+		toMarshal.CreatedAt = &str
+	}
+	if !e.UpdatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.UpdatedAt)
+		toMarshal.UpdatedAt = &str
+	}
+	hasNoLabels := true
+	for key, values := range e.Labels {
+		if key != "" && len(values) != 0 {
+			hasNoLabels = false
+			break
+		}
+	}
+	if hasNoLabels {
+		toMarshal.Labels = nil
+	}
+	return json.Marshal(toMarshal)
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+// SearchableTypes are the types of the objects which are matched by the full-text search
+var SearchableTypes = []ObjectType{ServiceBrokerType, ServiceOfferingType, ServicePlanType, ServiceInstanceType, PlatformType}
+
+// IsSearchable returns whether the objects of the type are matched by the full-text search
+func IsSearchable(objectType ObjectType) bool {
+	for _, searchableType := range SearchableTypes {
+		if searchableType == objectType {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchMatch is an object matching a full-text search together with the rank of the match
+type SearchMatch struct {
+	ID   string  `json:"id" db:"id"`
+	Rank float64 `json:"rank" db:"rank"`
+}
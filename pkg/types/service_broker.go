@@ -44,18 +44,30 @@ type ServiceBroker struct {
 	Services    []*ServiceOffering `json:"-"`
 }
 
+// GetTLSConfig returns the TLS configuration which should be used when calling the broker or nil if the broker
+// has no client certificate, CA bundle or server name configured
 func (e *ServiceBroker) GetTLSConfig() (*tls.Config, error) {
-	if e.Credentials.TLS != nil && e.Credentials.TLS.Certificate != "" && e.Credentials.TLS.Key != "" {
-		var tlsConfig tls.Config
+	if e.Credentials.TLS == nil || (!e.Credentials.TLS.HasClientCertificate() && !e.Credentials.TLS.HasServerVerification()) {
+		return nil, nil
+	}
+
+	var tlsConfig tls.Config
+	if e.Credentials.TLS.HasClientCertificate() {
 		cert, err := tls.X509KeyPair([]byte(e.Credentials.TLS.Certificate), []byte(e.Credentials.TLS.Key))
 		if err != nil {
 			return nil, err
 		}
 		tlsConfig.Certificates = []tls.Certificate{cert}
-		return &tlsConfig, nil
 	}
 
-	return nil, nil
+	rootCAs, err := e.Credentials.TLS.CertPool()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.RootCAs = rootCAs
+	tlsConfig.ServerName = e.Credentials.TLS.ServerName
+
+	return &tlsConfig, nil
 }
 
 func (e *ServiceBroker) Sanitize() {
@@ -73,10 +85,14 @@ func (e *ServiceBroker) Decrypt(ctx context.Context, decryptionFunc func(context
 func (e *ServiceBroker) IntegralData() []byte {
 	var integrity []string
 
-	if e.Credentials.TLS != nil && e.Credentials.TLS.Certificate != "" && e.Credentials.TLS.Key != "" {
+	if e.Credentials.TLS != nil && e.Credentials.TLS.HasClientCertificate() {
 		integrity = append(integrity, e.Credentials.TLS.Certificate, e.Credentials.TLS.Key)
 	}
 
+	if e.Credentials.TLS != nil && e.Credentials.TLS.HasServerVerification() {
+		integrity = append(integrity, e.Credentials.TLS.CACertificate, e.Credentials.TLS.ServerName)
+	}
+
 	if e.Credentials.Basic != nil && e.Credentials.Basic.Username != "" && e.Credentials.Basic.Password != "" {
 		integrity = append(integrity, e.Credentials.Basic.Username, e.Credentials.Basic.Password)
 	}
@@ -102,7 +118,7 @@ func (e *ServiceBroker) transform(ctx context.Context, transformationFunc func(c
 		e.Credentials.Basic.Password = string(transformedPassword)
 	}
 
-	if e.Credentials != nil && e.Credentials.TLS != nil {
+	if e.Credentials != nil && e.Credentials.TLS != nil && e.Credentials.TLS.Key != "" {
 		transformedPrivateKey, err := transformationFunc(ctx, []byte(e.Credentials.TLS.Key))
 		if err != nil {
 			return err
@@ -36,6 +36,13 @@ func ContextWithAuthorization(ctx context.Context) context.Context {
 	return context.WithValue(ctx, isAuthorizedKey, true)
 }
 
+// ContextWithoutAuthorization clears the authorization of the request and its errors from the context, so that a request
+// derived from it can be authorized anew
+func ContextWithoutAuthorization(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, isAuthorizedKey, nil)
+	return context.WithValue(ctx, authorizationErrorKey, nil)
+}
+
 func ContextWithAuthenticationError(ctx context.Context, authNError error) context.Context {
 	return context.WithValue(ctx, authenticationErrorKey, authNError)
 }
@@ -36,15 +36,27 @@ const (
 	// InfoURL is the path of the info endpoint
 	InfoURL = "/" + apiVersion + "/info"
 
+	// OpenAPIURL is the path of the OpenAPI specification of the API
+	OpenAPIURL = "/" + apiVersion + "/openapi.json"
+
 	// ConfigURL is the Configuration API base URL path
 	ConfigURL = "/" + apiVersion + "/config"
 
 	// LoggingConfigURL is the Logging Configuration API URL path
 	LoggingConfigURL = ConfigURL + "/logging"
 
+	// MaintenanceConfigURL is the URL path of the maintenance mode shared by all Service Manager instances
+	MaintenanceConfigURL = ConfigURL + "/maintenance"
+
 	// ResourceOperationsURL is the URL path fetch operations for a resource
 	ResourceOperationsURL = "/operations"
 
+	// ResourceLabelsURL is the URL path to fetch the label keys and values of a resource type
+	ResourceLabelsURL = "/labels"
+
+	// PlatformCredentialsURL is the URL path to manage the credentials of a platform relative to the platform
+	PlatformCredentialsURL = "/credentials"
+
 	// OperationsURL is the operations API base URL path
 	OperationsURL = "/" + apiVersion + "/operations"
 
@@ -53,4 +65,13 @@ const (
 
 	// ProfileURL is the Configuration API base URL path
 	ProfileURL = "/" + apiVersion + "/profile"
+
+	// RolesURL is the URL path to manage authorization roles
+	RolesURL = "/" + apiVersion + "/roles"
+
+	// LabelDefinitionsURL is the URL path to manage the definitions of labels
+	LabelDefinitionsURL = "/" + apiVersion + "/label_definitions"
+
+	// SearchURL is the URL path to search the resources by text
+	SearchURL = "/" + apiVersion + "/search"
 )
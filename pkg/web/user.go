@@ -19,11 +19,12 @@ package web
 // AuthenticationType specifies the authentication type that is stored in the user context
 type AuthenticationType int
 
-var authenticationTypes = []string{"Basic", "Bearer"}
+var authenticationTypes = []string{"Basic", "Bearer", "ClientCertificate"}
 
 const (
 	Basic AuthenticationType = iota
 	Bearer
+	ClientCertificate
 )
 
 // String implements Stringer and converts the decision to human-readable value
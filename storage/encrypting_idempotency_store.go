@@ -0,0 +1,68 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/security"
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// NewEncryptingIdempotencyStore returns an IdempotencyStore which encrypts the stored responses with the specified
+// encryption key, as the responses may hold credentials
+func NewEncryptingIdempotencyStore(store IdempotencyStore, encrypter security.Encrypter, key []byte) IdempotencyStore {
+	return &encryptingIdempotencyStore{
+		IdempotencyStore: store,
+		encrypter:        encrypter,
+		encryptionKey:    key,
+	}
+}
+
+type encryptingIdempotencyStore struct {
+	IdempotencyStore
+	encrypter     security.Encrypter
+	encryptionKey []byte
+}
+
+// ReserveIdempotencyKey reserves the idempotency key and decrypts the response of the already stored key
+func (s *encryptingIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) (*types.IdempotencyKey, error) {
+	storedKey, err := s.IdempotencyStore.ReserveIdempotencyKey(ctx, key)
+	if err != nil || storedKey == nil || len(storedKey.Body) == 0 {
+		return storedKey, err
+	}
+	body, err := s.encrypter.Decrypt(ctx, storedKey.Body, s.encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt the response stored with idempotency key %s: %s", storedKey.Key, err)
+	}
+	storedKey.Body = body
+	return storedKey, nil
+}
+
+// CompleteIdempotencyKey encrypts the response and stores it
+func (s *encryptingIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) error {
+	encryptedKey := *key
+	if len(key.Body) != 0 {
+		body, err := s.encrypter.Encrypt(ctx, key.Body, s.encryptionKey)
+		if err != nil {
+			return fmt.Errorf("could not encrypt the response of the request with idempotency key %s: %s", key.Key, err)
+		}
+		encryptedKey.Body = body
+	}
+	return s.IdempotencyStore.CompleteIdempotencyKey(ctx, &encryptedKey)
+}
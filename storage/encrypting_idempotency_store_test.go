@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/security"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/memory"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encrypting idempotency store", func() {
+	var memoryStorage *memory.Storage
+	var store storage.IdempotencyStore
+
+	newKey := func() *types.IdempotencyKey {
+		return &types.IdempotencyKey{
+			UserName:    "user",
+			Key:         "key",
+			RequestHash: "hash",
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+	}
+
+	BeforeEach(func() {
+		settings := storage.DefaultSettings()
+		settings.URI = memory.URIScheme
+		settings.EncryptionKey = "ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"
+		memoryStorage = &memory.Storage{}
+		Expect(memoryStorage.Open(settings)).To(Succeed())
+		store = storage.NewEncryptingIdempotencyStore(memoryStorage, &security.AESEncrypter{}, []byte("ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"))
+	})
+
+	AfterEach(func() {
+		Expect(memoryStorage.Close()).To(Succeed())
+	})
+
+	It("stores the responses encrypted and replays them decrypted", func() {
+		ctx := context.Background()
+		storedKey, err := store.ReserveIdempotencyKey(ctx, newKey())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(storedKey).To(BeNil())
+
+		completedKey := newKey()
+		completedKey.StatusCode = http.StatusCreated
+		completedKey.Body = []byte(`{"credentials": {"basic": {"password": "secret"}}}`)
+		Expect(store.CompleteIdempotencyKey(ctx, completedKey)).To(Succeed())
+
+		rawKey, err := memoryStorage.ReserveIdempotencyKey(ctx, newKey())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(rawKey.Body)).ToNot(ContainSubstring("secret"))
+
+		storedKey, err = store.ReserveIdempotencyKey(ctx, newKey())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(storedKey.Body).To(MatchJSON(`{"credentials": {"basic": {"password": "secret"}}}`))
+	})
+})
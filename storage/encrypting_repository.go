@@ -3,9 +3,16 @@ package storage
 import (
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/gofrs/uuid"
+
 	"github.com/Peripli/service-manager/pkg/log"
 
 	"github.com/Peripli/service-manager/pkg/security"
@@ -38,8 +45,15 @@ type KeyStore interface {
 	SetEncryptionKey(ctx context.Context, key []byte, transformationFunc func(context.Context, []byte, []byte) ([]byte, error)) error
 }
 
-// EncryptingDecorator creates a TransactionalRepositoryDecorator that can be used to add encrypting/decrypting logic to a TransactionalRepository
-func EncryptingDecorator(ctx context.Context, encrypter security.Encrypter, keyStore KeyStore, locker Locker) TransactionalRepositoryDecorator {
+// secretReferencePrefix marks the values of secret fields which reference a secret in the secret store
+const secretReferencePrefix = "secretref:"
+
+// securedObjectTypes are the object types which have secret fields
+var securedObjectTypes = []types.ObjectType{types.PlatformType, types.ServiceBrokerType, types.ServiceBindingType, types.BrokerPlatformCredentialType}
+
+// EncryptingDecorator creates a TransactionalRepositoryDecorator that can be used to add encrypting/decrypting logic to a TransactionalRepository.
+// If a secret store is provided the encrypted secret fields are kept in it and the database holds only references to them.
+func EncryptingDecorator(ctx context.Context, encrypter security.Encrypter, keyStore KeyStore, locker Locker, secretStore SecretStore) TransactionalRepositoryDecorator {
 	return func(next TransactionalRepository) (TransactionalRepository, error) {
 		ctx, cancelFunc := context.WithTimeout(ctx, 2*time.Second)
 		defer cancelFunc()
@@ -73,17 +87,24 @@ func EncryptingDecorator(ctx context.Context, encrypter security.Encrypter, keyS
 			logger.Info("Successfully generated new encryption key")
 		}
 
-		return NewEncryptingRepository(next, encrypter, encryptionKey)
+		return NewSecretStoreEncryptingRepository(next, encrypter, encryptionKey, secretStore)
 	}
 }
 
 //NewEncryptingRepository creates a new TransactionalEncryptingRepository using the specified encrypter and encryption key
 func NewEncryptingRepository(repository TransactionalRepository, encrypter security.Encrypter, key []byte) (*TransactionalEncryptingRepository, error) {
+	return NewSecretStoreEncryptingRepository(repository, encrypter, key, nil)
+}
+
+// NewSecretStoreEncryptingRepository creates a new TransactionalEncryptingRepository which keeps the encrypted secret fields
+// in the specified secret store. If the secret store is nil the encrypted secret fields are kept in the database.
+func NewSecretStoreEncryptingRepository(repository TransactionalRepository, encrypter security.Encrypter, key []byte, secretStore SecretStore) (*TransactionalEncryptingRepository, error) {
 	encryptingRepository := &TransactionalEncryptingRepository{
 		encryptingRepository: &encryptingRepository{
 			repository:    repository,
 			encrypter:     encrypter,
 			encryptionKey: key,
+			secretStore:   secretStore,
 		},
 		repository: repository,
 	}
@@ -92,10 +113,20 @@ func NewEncryptingRepository(repository TransactionalRepository, encrypter secur
 }
 
 type encryptingRepository struct {
-	repository Repository
-	encrypter  security.Encrypter
+	repository  Repository
+	encrypter   security.Encrypter
+	secretStore SecretStore
 
 	encryptionKey []byte
+
+	// secretChanges collects the secrets written and replaced during a transaction, nil outside of transactions
+	secretChanges *secretChanges
+}
+
+// secretChanges are the secret store changes which have to be completed once it is known whether a transaction is committed
+type secretChanges struct {
+	created  []string
+	obsolete []string
 }
 
 //TransactionalEncryptingRepository is a TransactionalRepository with that also encrypts credentials of Secured objects
@@ -107,14 +138,17 @@ type TransactionalEncryptingRepository struct {
 }
 
 func (er *encryptingRepository) Create(ctx context.Context, obj types.Object) (types.Object, error) {
-	if err := er.encrypt(ctx, obj); err != nil {
+	secretPath, err := er.encrypt(ctx, obj)
+	if err != nil {
 		return nil, err
 	}
 
 	newObj, err := er.repository.Create(ctx, obj)
 	if err != nil {
+		er.deleteSecrets(ctx, secretPath)
 		return nil, err
 	}
+	er.secretsCreated(secretPath)
 
 	if err := er.decrypt(ctx, newObj); err != nil {
 		return nil, err
@@ -184,15 +218,32 @@ func (er *encryptingRepository) Count(ctx context.Context, objectType types.Obje
 	return er.repository.Count(ctx, objectType, criteria...)
 }
 
+func (er *encryptingRepository) CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error) {
+	return er.repository.CountLabelValues(ctx, objectType, criteria...)
+}
+
+func (er *encryptingRepository) Search(ctx context.Context, objectType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error) {
+	return er.repository.Search(ctx, objectType, text, criteria...)
+}
+
 func (er *encryptingRepository) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, _ ...query.Criterion) (types.Object, error) {
-	if err := er.encrypt(ctx, obj); err != nil {
+	obsoleteSecretPaths, err := er.storedSecretPaths(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	secretPath, err := er.encrypt(ctx, obj)
+	if err != nil {
 		return nil, err
 	}
 
 	updatedObj, err := er.repository.Update(ctx, obj, labelChanges)
 	if err != nil {
+		er.deleteSecrets(ctx, secretPath)
 		return nil, err
 	}
+	er.secretsCreated(secretPath)
+	er.secretsObsolete(ctx, obsoleteSecretPaths...)
 
 	if err := er.decrypt(ctx, updatedObj); err != nil {
 		return nil, err
@@ -208,7 +259,9 @@ func (er *encryptingRepository) DeleteReturning(ctx context.Context, objectType
 	}
 
 	for i := 0; i < objList.Len(); i++ {
-		if err := er.decrypt(ctx, objList.ItemAt(i)); err != nil {
+		obj := objList.ItemAt(i)
+		er.secretsObsolete(ctx, secretPaths(ctx, obj)...)
+		if err := er.decrypt(ctx, obj); err != nil {
 			return nil, err
 		}
 	}
@@ -217,6 +270,18 @@ func (er *encryptingRepository) DeleteReturning(ctx context.Context, objectType
 }
 
 func (er *encryptingRepository) Delete(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) error {
+	if er.secretStore != nil && isSecuredObjectType(objectType) {
+		// the deleted objects are needed to find the secrets they reference
+		objList, err := er.repository.DeleteReturning(ctx, objectType, criteria...)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < objList.Len(); i++ {
+			er.secretsObsolete(ctx, secretPaths(ctx, objList.ItemAt(i))...)
+		}
+		return nil
+	}
+
 	if err := er.repository.Delete(ctx, objectType, criteria...); err != nil {
 		return err
 	}
@@ -224,31 +289,279 @@ func (er *encryptingRepository) Delete(ctx context.Context, objectType types.Obj
 	return nil
 }
 
-func (er *encryptingRepository) encrypt(ctx context.Context, obj types.Object) error {
-	if securedObject, isSecured := obj.(types.Secured); isSecured {
-		return securedObject.Encrypt(ctx, func(ctx context.Context, bytes []byte) ([]byte, error) {
+// encrypt encrypts the secret fields of the object. If a secret store is configured, the encrypted fields are
+// stored as a single secret and replaced by references to it. The path of the stored secret is returned.
+func (er *encryptingRepository) encrypt(ctx context.Context, obj types.Object) (string, error) {
+	securedObject, isSecured := obj.(types.Secured)
+	if !isSecured {
+		return "", nil
+	}
+
+	if er.secretStore == nil {
+		return "", securedObject.Encrypt(ctx, func(ctx context.Context, bytes []byte) ([]byte, error) {
 			return er.encrypter.Encrypt(ctx, bytes, er.encryptionKey)
 		})
 	}
-	return nil
+
+	secretPath, err := newSecretPath(obj)
+	if err != nil {
+		return "", err
+	}
+	secrets := make([][]byte, 0)
+	if err := securedObject.Encrypt(ctx, func(ctx context.Context, bytes []byte) ([]byte, error) {
+		encrypted, err := er.encrypter.Encrypt(ctx, bytes, er.encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, encrypted)
+		return []byte(fmt.Sprintf("%s%s#%d", secretReferencePrefix, secretPath, len(secrets)-1)), nil
+	}); err != nil {
+		return "", err
+	}
+	if len(secrets) == 0 {
+		return "", nil
+	}
+
+	secret, err := json.Marshal(secrets)
+	if err != nil {
+		return "", err
+	}
+	if err := er.secretStore.Put(ctx, secretPath, secret); err != nil {
+		return "", fmt.Errorf("could not store secrets of %s %s: %s", obj.GetType(), obj.GetID(), err)
+	}
+	return secretPath, nil
 }
 
+// decrypt decrypts the secret fields of the object. Fields referencing a secret in the secret store are resolved
+// from it, while fields still holding the encrypted value in the database are decrypted directly.
 func (er *encryptingRepository) decrypt(ctx context.Context, obj types.Object) error {
-	if securedObject, isSecured := obj.(types.Secured); isSecured {
-		return securedObject.Decrypt(ctx, func(ctx context.Context, bytes []byte) ([]byte, error) {
+	securedObject, isSecured := obj.(types.Secured)
+	if !isSecured {
+		return nil
+	}
+
+	secrets := make(map[string][][]byte)
+	return securedObject.Decrypt(ctx, func(ctx context.Context, bytes []byte) ([]byte, error) {
+		secretPath, index, isReference := parseSecretReference(bytes)
+		if !isReference {
 			return er.encrypter.Decrypt(ctx, bytes, er.encryptionKey)
-		})
+		}
+		if er.secretStore == nil {
+			return nil, fmt.Errorf("%s %s references secret %s but no secret store is configured", obj.GetType(), obj.GetID(), secretPath)
+		}
+
+		if _, found := secrets[secretPath]; !found {
+			secret, err := er.secretStore.Get(ctx, secretPath)
+			if err != nil {
+				return nil, fmt.Errorf("could not fetch secrets of %s %s: %s", obj.GetType(), obj.GetID(), err)
+			}
+			var storedSecrets [][]byte
+			if err := json.Unmarshal(secret, &storedSecrets); err != nil {
+				return nil, fmt.Errorf("could not decode secrets of %s %s: %s", obj.GetType(), obj.GetID(), err)
+			}
+			secrets[secretPath] = storedSecrets
+		}
+		if index >= len(secrets[secretPath]) {
+			return nil, fmt.Errorf("secret %s of %s %s has no value at index %d", secretPath, obj.GetType(), obj.GetID(), index)
+		}
+		return er.encrypter.Decrypt(ctx, secrets[secretPath][index], er.encryptionKey)
+	})
+}
+
+// storedSecretPaths returns the paths of the secrets referenced by the currently stored version of the object
+func (er *encryptingRepository) storedSecretPaths(ctx context.Context, obj types.Object) ([]string, error) {
+	if er.secretStore == nil {
+		return nil, nil
+	}
+	if _, isSecured := obj.(types.Secured); !isSecured {
+		return nil, nil
+	}
+
+	storedObj, err := er.repository.Get(ctx, obj.GetType(), query.ByField(query.EqualsOperator, "id", obj.GetID()))
+	if err != nil {
+		if err == util.ErrNotFoundInStorage {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return secretPaths(ctx, storedObj), nil
+}
+
+// secretsCreated records a secret written to the secret store, so that it can be removed if the transaction is rolled back
+func (er *encryptingRepository) secretsCreated(secretPath string) {
+	if er.secretChanges != nil && len(secretPath) != 0 {
+		er.secretChanges.created = append(er.secretChanges.created, secretPath)
+	}
+}
+
+// secretsObsolete deletes secrets which are no longer referenced. Within a transaction they are deleted only after it is committed.
+func (er *encryptingRepository) secretsObsolete(ctx context.Context, secretPaths ...string) {
+	if er.secretChanges != nil {
+		er.secretChanges.obsolete = append(er.secretChanges.obsolete, secretPaths...)
+		return
+	}
+	er.deleteSecrets(ctx, secretPaths...)
+}
+
+// deleteSecrets deletes secrets from the secret store. Failures are only logged, as they leave behind secrets
+// which are not referenced anymore but do not affect the stored objects.
+func (er *encryptingRepository) deleteSecrets(ctx context.Context, secretPaths ...string) {
+	if er.secretStore == nil {
+		return
+	}
+	for _, secretPath := range secretPaths {
+		if len(secretPath) == 0 {
+			continue
+		}
+		if err := er.secretStore.Delete(ctx, secretPath); err != nil {
+			log.C(ctx).WithError(err).Errorf("could not delete secret %s from the secret store", secretPath)
+		}
 	}
-	return nil
 }
 
 // InTransaction wraps repository passed in the transaction to also encypt/decrypt credentials
-func (er *TransactionalEncryptingRepository) InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error) error {
-	return er.repository.InTransaction(ctx, func(ctx context.Context, storage Repository) error {
+func (er *TransactionalEncryptingRepository) InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error, opts ...TransactionOption) error {
+	changes := &secretChanges{}
+	err := er.repository.InTransaction(ctx, func(ctx context.Context, storage Repository) error {
+		// the transaction is retried, so the secrets created by the previous attempt which was rolled back are not referenced
+		if er.secretStore != nil && len(changes.created) != 0 {
+			er.deleteSecrets(ctx, changes.created...)
+		}
+		*changes = secretChanges{}
 		return f(ctx, &encryptingRepository{
 			repository:    storage,
 			encrypter:     er.encrypter,
 			encryptionKey: er.encryptionKey,
+			secretStore:   er.secretStore,
+			secretChanges: changes,
 		})
+	}, opts...)
+	if er.secretStore == nil {
+		return err
+	}
+
+	if err != nil {
+		er.deleteSecrets(ctx, changes.created...)
+		return err
+	}
+	er.deleteSecrets(ctx, changes.obsolete...)
+	return nil
+}
+
+// MigrateSecrets moves the secret fields which are still kept in the database to the secret store
+func (er *TransactionalEncryptingRepository) MigrateSecrets(ctx context.Context) error {
+	if er.secretStore == nil {
+		return nil
+	}
+
+	return er.InTransaction(ctx, func(ctx context.Context, storage Repository) error {
+		encryptingStorage := storage.(*encryptingRepository)
+		for _, objectType := range securedObjectTypes {
+			objects, err := encryptingStorage.repository.List(ctx, objectType)
+			if err != nil {
+				return err
+			}
+			migrated := 0
+			for i := 0; i < objects.Len(); i++ {
+				obj := objects.ItemAt(i)
+				if !hasDatabaseSecrets(ctx, obj) {
+					continue
+				}
+				if err := encryptingStorage.decrypt(ctx, obj); err != nil {
+					return err
+				}
+				if _, err := encryptingStorage.Update(ctx, obj, types.LabelChanges{}); err != nil {
+					return err
+				}
+				migrated++
+			}
+			log.C(ctx).Infof("Moved secrets of %d objects of type %s to the secret store", migrated, objectType)
+		}
+		return nil
+	})
+}
+
+func newSecretPath(obj types.Object) (string, error) {
+	version, err := uuid.NewV4()
+	if err != nil {
+		return "", fmt.Errorf("could not generate secret version: %s", err)
+	}
+	segments := []string{path.Base(string(obj.GetType()))}
+	if len(obj.GetID()) != 0 {
+		segments = append(segments, obj.GetID())
+	}
+	return strings.Join(append(segments, version.String()), "/"), nil
+}
+
+func parseSecretReference(value []byte) (string, int, bool) {
+	reference := string(value)
+	if !strings.HasPrefix(reference, secretReferencePrefix) {
+		return "", 0, false
+	}
+	reference = strings.TrimPrefix(reference, secretReferencePrefix)
+	separator := strings.LastIndex(reference, "#")
+	if separator < 0 {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(reference[separator+1:])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return reference[:separator], index, true
+}
+
+// secretPaths returns the paths of the secrets referenced by the secret fields of a stored object
+func secretPaths(ctx context.Context, obj types.Object) []string {
+	securedObject, isSecured := obj.(types.Secured)
+	if !isSecured {
+		return nil
+	}
+	paths := make([]string, 0)
+	inspectSecretFields(ctx, securedObject, func(value []byte) {
+		if secretPath, _, isReference := parseSecretReference(value); isReference && !containsString(paths, secretPath) {
+			paths = append(paths, secretPath)
+		}
 	})
+	return paths
+}
+
+// hasDatabaseSecrets checks whether any secret field of a stored object holds the encrypted value instead of a reference
+func hasDatabaseSecrets(ctx context.Context, obj types.Object) bool {
+	securedObject, isSecured := obj.(types.Secured)
+	if !isSecured {
+		return false
+	}
+	found := false
+	inspectSecretFields(ctx, securedObject, func(value []byte) {
+		if _, _, isReference := parseSecretReference(value); !isReference {
+			found = true
+		}
+	})
+	return found
+}
+
+// inspectSecretFields visits the values of the secret fields without modifying them
+func inspectSecretFields(ctx context.Context, securedObject types.Secured, visit func(value []byte)) {
+	_ = securedObject.Decrypt(ctx, func(_ context.Context, bytes []byte) ([]byte, error) {
+		visit(bytes)
+		return bytes, nil
+	})
+}
+
+func isSecuredObjectType(objectType types.ObjectType) bool {
+	for _, securedType := range securedObjectTypes {
+		if securedType == objectType {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/lib/pq"
+
 	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/util"
 
 	"github.com/Peripli/service-manager/pkg/types"
 
@@ -465,4 +468,215 @@ var _ = Describe("Encrypting Repository", func() {
 			})
 		})
 	})
+
+	Describe("with a secret store", func() {
+		var secretStore *inMemorySecretStore
+		var storedBroker *types.ServiceBroker
+		var persistedPasswords []string
+
+		brokerWithPassword := func(password string) *types.ServiceBroker {
+			return &types.ServiceBroker{
+				Base: types.Base{
+					ID:    "id",
+					Ready: true,
+				},
+				Credentials: &types.Credentials{
+					Basic: &types.Basic{
+						Username: "admin",
+						Password: password,
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			secretStore = &inMemorySecretStore{secrets: make(map[string][]byte)}
+			secretStore.secrets["service_brokers/id/old"] = []byte(`["ZW5jcnlwdG9sZA=="]`)
+			storedBroker = brokerWithPassword("secretref:service_brokers/id/old#0")
+
+			persistedPasswords = make([]string, 0)
+			fakeRepository.CreateStub = func(ctx context.Context, obj types.Object) (types.Object, error) {
+				persistedPasswords = append(persistedPasswords, obj.(*types.ServiceBroker).Credentials.Basic.Password)
+				return obj, nil
+			}
+			fakeRepository.UpdateStub = func(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, criteria ...query.Criterion) (types.Object, error) {
+				persistedPasswords = append(persistedPasswords, obj.(*types.ServiceBroker).Credentials.Basic.Password)
+				return obj, nil
+			}
+			fakeRepository.GetStub = func(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.Object, error) {
+				return storedBroker, nil
+			}
+			fakeRepository.InTransactionStub = func(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error, _ ...storage.TransactionOption) error {
+				return f(ctx, fakeRepository)
+			}
+
+			repository, err = storage.NewSecretStoreEncryptingRepository(fakeRepository, fakeEncrypter, []byte{}, secretStore)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("keeps the encrypted credentials in the secret store and references to them in the database", func() {
+			returnedObj, err := repository.Create(ctx, brokerWithPassword("admin"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(returnedObj.(*types.ServiceBroker).Credentials.Basic.Password).To(Equal("admin"))
+
+			Expect(persistedPasswords).To(HaveLen(1))
+			Expect(persistedPasswords[0]).To(HavePrefix("secretref:service_brokers/id/"))
+			Expect(secretStore.secrets).To(HaveLen(2))
+		})
+
+		It("resolves referenced credentials from the secret store", func() {
+			returnedObj, err := repository.Get(ctx, types.ServiceBrokerType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(returnedObj.(*types.ServiceBroker).Credentials.Basic.Password).To(Equal("old"))
+		})
+
+		It("decrypts credentials which are still kept in the database", func() {
+			storedBroker = brokerWithPassword("encryptadmin")
+
+			returnedObj, err := repository.Get(ctx, types.ServiceBrokerType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(returnedObj.(*types.ServiceBroker).Credentials.Basic.Password).To(Equal("admin"))
+		})
+
+		It("fails if a referenced secret is missing", func() {
+			storedBroker = brokerWithPassword("secretref:service_brokers/id/missing#0")
+
+			_, err := repository.Get(ctx, types.ServiceBrokerType)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("deletes the replaced secret on update", func() {
+			_, err := repository.Update(ctx, brokerWithPassword("new"), types.LabelChanges{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(secretStore.secrets).To(HaveLen(1))
+			Expect(secretStore.secrets).ToNot(HaveKey("service_brokers/id/old"))
+		})
+
+		It("deletes the new secret if the update fails", func() {
+			fakeRepository.UpdateStub = nil
+			fakeRepository.UpdateReturns(nil, fmt.Errorf("error"))
+
+			_, err := repository.Update(ctx, brokerWithPassword("new"), types.LabelChanges{})
+			Expect(err).To(HaveOccurred())
+
+			Expect(secretStore.secrets).To(HaveLen(1))
+			Expect(secretStore.secrets).To(HaveKey("service_brokers/id/old"))
+		})
+
+		It("deletes the secrets of deleted objects", func() {
+			fakeRepository.DeleteReturningReturns(&types.ServiceBrokers{
+				ServiceBrokers: []*types.ServiceBroker{storedBroker},
+			}, nil)
+
+			Expect(repository.Delete(ctx, types.ServiceBrokerType)).To(Succeed())
+			Expect(fakeRepository.DeleteCallCount()).To(Equal(0))
+			Expect(secretStore.secrets).To(BeEmpty())
+		})
+
+		It("keeps the replaced secret until the transaction is committed", func() {
+			err := repository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
+				if _, err := storage.Update(ctx, brokerWithPassword("new"), types.LabelChanges{}); err != nil {
+					return err
+				}
+				Expect(secretStore.secrets).To(HaveLen(2))
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secretStore.secrets).To(HaveLen(1))
+			Expect(secretStore.secrets).ToNot(HaveKey("service_brokers/id/old"))
+		})
+
+		It("deletes the secrets written in a transaction which is rolled back", func() {
+			err := repository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
+				if _, err := storage.Update(ctx, brokerWithPassword("new"), types.LabelChanges{}); err != nil {
+					return err
+				}
+				return fmt.Errorf("error")
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(secretStore.secrets).To(HaveLen(1))
+			Expect(secretStore.secrets).To(HaveKey("service_brokers/id/old"))
+		})
+
+		It("moves credentials which are still kept in the database to the secret store", func() {
+			storedBroker = brokerWithPassword("encryptadmin")
+			fakeRepository.ListStub = func(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+				if objectType == types.ServiceBrokerType {
+					return &types.ServiceBrokers{
+						ServiceBrokers: []*types.ServiceBroker{
+							storedBroker,
+							brokerWithPassword("secretref:service_brokers/id/old#0"),
+						},
+					}, nil
+				}
+				return &types.Platforms{}, nil
+			}
+
+			Expect(repository.MigrateSecrets(ctx)).To(Succeed())
+			Expect(persistedPasswords).To(HaveLen(1))
+			Expect(persistedPasswords[0]).To(HavePrefix("secretref:"))
+			Expect(secretStore.secrets).To(HaveLen(2))
+		})
+	})
+
+	Describe("Create through the interceptable repository", func() {
+		It("stores decryptable credentials if the transaction is retried after a serialization failure", func() {
+			var storedPasswords []string
+			fakeRepository.InTransactionStub = func(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error, opts ...storage.TransactionOption) error {
+				Expect(storage.NewTransactionOptions(opts...).Retries).To(BeTrue())
+				isRetriable := func(err error) bool {
+					pqErr, ok := err.(*pq.Error)
+					return ok && pqErr.Code == "40001"
+				}
+				return storage.RetryTransaction(ctx, 1, 0, isRetriable, func() error {
+					return f(ctx, fakeRepository)
+				})
+			}
+			fakeRepository.CreateStub = func(ctx context.Context, obj types.Object) (types.Object, error) {
+				storedPasswords = append(storedPasswords, obj.(*types.Platform).Credentials.Basic.Password)
+				if len(storedPasswords) == 1 {
+					return nil, &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+				}
+				return obj, nil
+			}
+
+			platform := &types.Platform{
+				Base: types.Base{ID: "id"},
+				Credentials: &types.Credentials{
+					Basic: &types.Basic{Username: "admin", Password: "admin"},
+				},
+			}
+			interceptableRepository := storage.NewInterceptableTransactionalRepository(repository)
+			_, err := interceptableRepository.Create(ctx, platform)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(storedPasswords).To(Equal([]string{"encryptadmin", "encryptadmin"}))
+			decrypted, err := fakeEncrypter.Decrypt(ctx, []byte(storedPasswords[1]), []byte{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(decrypted)).To(Equal("admin"))
+		})
+	})
 })
+
+type inMemorySecretStore struct {
+	secrets map[string][]byte
+}
+
+func (s *inMemorySecretStore) Put(_ context.Context, path string, secret []byte) error {
+	s.secrets[path] = secret
+	return nil
+}
+
+func (s *inMemorySecretStore) Get(_ context.Context, path string) ([]byte, error) {
+	secret, found := s.secrets[path]
+	if !found {
+		return nil, util.ErrNotFoundInStorage
+	}
+	return secret, nil
+}
+
+func (s *inMemorySecretStore) Delete(_ context.Context, path string) error {
+	delete(s.secrets, path)
+	return nil
+}
@@ -126,6 +126,14 @@ func (cr *integrityRepository) Count(ctx context.Context, objectType types.Objec
 	return cr.repository.Count(ctx, objectType, criteria...)
 }
 
+func (cr *integrityRepository) CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error) {
+	return cr.repository.CountLabelValues(ctx, objectType, criteria...)
+}
+
+func (cr *integrityRepository) Search(ctx context.Context, objectType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error) {
+	return cr.repository.Search(ctx, objectType, text, criteria...)
+}
+
 func (cr *integrityRepository) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
 	return cr.repository.DeleteReturning(ctx, objectType, criteria...)
 }
@@ -134,13 +142,13 @@ func (cr *integrityRepository) Delete(ctx context.Context, objectType types.Obje
 	return cr.repository.Delete(ctx, objectType, criteria...)
 }
 
-func (cr *TransactionalIntegrityRepository) InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error) error {
+func (cr *TransactionalIntegrityRepository) InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error, opts ...TransactionOption) error {
 	return cr.repository.InTransaction(ctx, func(ctx context.Context, storage Repository) error {
 		return f(ctx, &integrityRepository{
 			repository:         storage,
 			integrityProcessor: cr.integrityProcessor,
 		})
-	})
+	}, opts...)
 }
 
 func (cr *integrityRepository) setIntegrity(obj types.Object) error {
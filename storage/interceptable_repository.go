@@ -207,6 +207,14 @@ func (ir *queryScopedInterceptableRepository) Count(ctx context.Context, objectT
 	return ir.repositoryInTransaction.Count(ctx, objectType, criteria...)
 }
 
+func (ir *queryScopedInterceptableRepository) CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error) {
+	return ir.repositoryInTransaction.CountLabelValues(ctx, objectType, criteria...)
+}
+
+func (ir *queryScopedInterceptableRepository) Search(ctx context.Context, objectType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error) {
+	return ir.repositoryInTransaction.Search(ctx, objectType, text, criteria...)
+}
+
 func (ir *queryScopedInterceptableRepository) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
 	var resultList types.ObjectList
 	deleteObjectFunc := func(ctx context.Context, _ Repository, _ types.ObjectList, deletionCriteria ...query.Criterion) error {
@@ -340,7 +348,7 @@ func (ir *queryScopedInterceptableRepository) Update(ctx context.Context, obj ty
 	return updatedObj, nil
 }
 
-func (itr *InterceptableTransactionalRepository) InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error) error {
+func (itr *InterceptableTransactionalRepository) InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error, opts ...TransactionOption) error {
 	createOnTxInterceptors, updateOnTxInterceptors, deleteOnTxInterceptors := itr.provideOnTxInterceptors()
 
 	fWrapper := func(ctx context.Context, storage Repository) error {
@@ -348,7 +356,7 @@ func (itr *InterceptableTransactionalRepository) InTransaction(ctx context.Conte
 		return f(ctx, wrappedStorage)
 	}
 
-	return itr.RawRepository.InTransaction(ctx, fWrapper)
+	return itr.RawRepository.InTransaction(ctx, fWrapper, opts...)
 }
 
 func (itr *InterceptableTransactionalRepository) AddCreateAroundTxInterceptorProvider(objectType types.ObjectType, provider CreateAroundTxInterceptorProvider, order InterceptorOrder) {
@@ -469,15 +477,16 @@ func (itr *InterceptableTransactionalRepository) Create(ctx context.Context, obj
 		var createdObj types.Object
 		var err error
 
+		// the object is modified in place by the interceptors and the encryption, so each retry creates a copy of it
 		if err := itr.RawRepository.InTransaction(ctx, func(ctx context.Context, txStorage Repository) error {
 			interceptableRepository := newScopedRepositoryWithInterceptors(txStorage, providedCreateInterceptors, providedUpdateInterceptors, providedDeleteInterceptors)
-			createdObj, err = interceptableRepository.Create(ctx, obj)
+			createdObj, err = interceptableRepository.Create(ctx, DeepCopy(obj).(types.Object))
 			if err != nil {
 				return err
 			}
 
 			return nil
-		}); err != nil {
+		}, WithRetries()); err != nil {
 			return nil, err
 		}
 
@@ -545,6 +554,14 @@ func (itr *InterceptableTransactionalRepository) Count(ctx context.Context, obje
 	return itr.RawRepository.Count(ctx, objectType, criteria...)
 }
 
+func (itr *InterceptableTransactionalRepository) CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error) {
+	return itr.RawRepository.CountLabelValues(ctx, objectType, criteria...)
+}
+
+func (itr *InterceptableTransactionalRepository) Search(ctx context.Context, objectType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error) {
+	return itr.RawRepository.Search(ctx, objectType, text, criteria...)
+}
+
 func (itr *InterceptableTransactionalRepository) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
 	providedCreateInterceptors, providedUpdateInterceptors, providedDeleteInterceptors := itr.provideInterceptors()
 
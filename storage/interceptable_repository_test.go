@@ -167,7 +167,7 @@ var _ = Describe("Interceptable TransactionalRepository", func() {
 		fakeDeleteInterceptorProvider.ProvideReturns(fakeDeleteInterceptor)
 
 		fakeStorage = &storagefakes.FakeStorage{}
-		fakeStorage.InTransactionCalls(func(context context.Context, f func(ctx context.Context, storage storage.Repository) error) error {
+		fakeStorage.InTransactionCalls(func(context context.Context, f func(ctx context.Context, storage storage.Repository) error, _ ...storage.TransactionOption) error {
 			return f(context, fakeStorage)
 		})
 
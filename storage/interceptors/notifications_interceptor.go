@@ -228,29 +228,44 @@ func (ni *NotificationsInterceptor) OnTxDelete(h storage.InterceptDeleteOnTxFunc
 }
 
 func CreateNotification(ctx context.Context, repository storage.Repository, op types.NotificationOperation, resource types.ObjectType, platformID string, payload *Payload) error {
+	notification, err := newNotification(ctx, op, resource, platformID, payload)
+	if err != nil {
+		return err
+	}
+
+	createdNotification, err := repository.Create(ctx, notification)
+	if err != nil {
+		return err
+	}
+	log.C(ctx).Debugf("Successfully created notification with id %s of type %s for resource type %s", createdNotification.GetID(), notification.Type, notification.Resource)
+
+	return nil
+}
+
+func newNotification(ctx context.Context, op types.NotificationOperation, resource types.ObjectType, platformID string, payload *Payload) (*types.Notification, error) {
 	UUID, err := uuid.NewV4()
 	if err != nil {
-		return fmt.Errorf("could not generate GUID for notification of type %s for resource of type %s: %s", op, resource, err)
+		return nil, fmt.Errorf("could not generate GUID for notification of type %s for resource of type %s: %s", op, resource, err)
 	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	payloadBytes, err = sjson.DeleteBytes(payloadBytes, "old.resource.credentials")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	payloadBytes, err = sjson.DeleteBytes(payloadBytes, "new.resource.credentials")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	currentTime := time.Now()
 
-	notification := &types.Notification{
+	return &types.Notification{
 		Base: types.Base{
 			ID:        UUID.String(),
 			CreatedAt: currentTime,
@@ -263,15 +278,7 @@ func CreateNotification(ctx context.Context, repository storage.Repository, op t
 		PlatformID:    platformID,
 		Payload:       payloadBytes,
 		CorrelationID: log.CorrelationIDFromContext(ctx),
-	}
-
-	createdNotification, err := repository.Create(ctx, notification)
-	if err != nil {
-		return err
-	}
-	log.C(ctx).Debugf("Successfully created notification with id %s of type %s for resource type %s", createdNotification.GetID(), notification.Type, notification.Resource)
-
-	return nil
+	}, nil
 }
 
 func determinePlatformIDs(oldPlatformIDs, updatedPlatformIDs []string) ([]string, []string, []string) {
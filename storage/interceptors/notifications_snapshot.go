@@ -0,0 +1,117 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/catalog"
+)
+
+// NotificationsSnapshot returns CREATED notifications for all brokers and visibilities which are visible to the given platform.
+// The notifications have the same payloads as the ones created by the broker and visibility notification interceptors,
+// so that a platform which applies them ends up in the same state as if it had received all notifications so far.
+func NotificationsSnapshot(ctx context.Context, repository storage.Repository, platform *types.Platform) ([]*types.Notification, error) {
+	brokerNotifications, err := brokersSnapshot(ctx, repository, platform)
+	if err != nil {
+		return nil, err
+	}
+	visibilityNotifications, err := visibilitiesSnapshot(ctx, repository, platform)
+	if err != nil {
+		return nil, err
+	}
+	return append(brokerNotifications, visibilityNotifications...), nil
+}
+
+func brokersSnapshot(ctx context.Context, repository storage.Repository, platform *types.Platform) ([]*types.Notification, error) {
+	if platform.Type == types.SMPlatform {
+		return nil, nil
+	}
+
+	objList, err := repository.List(ctx, types.ServiceBrokerType)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]*types.Notification, 0, objList.Len())
+	for _, broker := range objList.(*types.ServiceBrokers).ServiceBrokers {
+		if !broker.GetReady() {
+			continue
+		}
+
+		serviceOfferings, err := catalog.Load(ctx, broker.ID, repository)
+		if err != nil {
+			return nil, err
+		}
+
+		plans := make([]*types.ServicePlan, 0)
+		for _, svc := range serviceOfferings.ServiceOfferings {
+			plans = append(plans, svc.Plans...)
+		}
+		if !isPlatformSupported(platform, getSupportedPlatformsForPlans(plans)) {
+			continue
+		}
+
+		notification, err := newNotification(ctx, types.CREATED, types.ServiceBrokerType, platform.ID, &Payload{
+			New: &ObjectPayload{
+				Resource: broker,
+				Additional: &BrokerAdditional{
+					Services: serviceOfferings.ServiceOfferings,
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+func visibilitiesSnapshot(ctx context.Context, repository storage.Repository, platform *types.Platform) ([]*types.Notification, error) {
+	objList, err := repository.List(ctx, types.VisibilityType,
+		query.ByField(query.EqualsOrNilOperator, "platform_id", platform.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	visibilities := &types.Visibilities{}
+	for _, visibility := range objList.(*types.Visibilities).Visibilities {
+		if visibility.GetReady() {
+			visibilities.Add(visibility)
+		}
+	}
+
+	details, err := NewVisibilityNotificationsInterceptor().AdditionalDetailsFunc(ctx, visibilities, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]*types.Notification, 0, visibilities.Len())
+	for _, visibility := range visibilities.Visibilities {
+		notification, err := newNotification(ctx, types.CREATED, types.VisibilityType, visibility.PlatformID, &Payload{
+			New: &ObjectPayload{
+				Resource:   visibility,
+				Additional: details[visibility.ID],
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+func isPlatformSupported(platform *types.Platform, supportedPlatforms []string) bool {
+	if len(supportedPlatforms) == 0 {
+		return true
+	}
+	for _, platformType := range supportedPlatforms {
+		if platformType == platform.Type {
+			return true
+		}
+	}
+	return false
+}
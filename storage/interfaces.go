@@ -20,6 +20,7 @@ package storage
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path"
@@ -38,13 +39,6 @@ type Entity interface {
 	GetID() string
 	ToObject() (types.Object, error)
 	FromObject(object types.Object) (Entity, error)
-	BuildLabels(labels types.Labels, newLabel func(id, key, value string) Label) ([]Label, error)
-	NewLabel(id, key, value string) Label
-}
-
-type Label interface {
-	GetKey() string
-	GetValue() string
 }
 
 var (
@@ -54,24 +48,32 @@ var (
 
 // Settings type to be loaded from the environment
 type Settings struct {
-	URI                string                `mapstructure:"uri" description:"URI of the storage"`
-	MigrationsURL      string                `mapstructure:"migrations_url" description:"location of a directory containing sql migrations scripts"`
-	EncryptionKey      string                `mapstructure:"encryption_key" description:"key to use for encrypting database entries"`
-	SkipSSLValidation  bool                  `mapstructure:"skip_ssl_validation" description:"whether to skip ssl verification when connecting to the storage"`
-	MaxIdleConnections int                   `mapstructure:"max_idle_connections" description:"sets the maximum number of connections in the idle connection pool"`
-	Notification       *NotificationSettings `mapstructure:"notification"`
-	IntegrityProcessor security.IntegrityProcessor
+	URI                      string                `mapstructure:"uri" description:"URI of the storage"`
+	ReadURIs                 []string              `mapstructure:"read_uri" description:"URIs of read replicas of the storage which serve the list, get and count queries of requests outside of transactions"`
+	MigrationsURL            string                `mapstructure:"migrations_url" description:"location of a directory containing sql migrations scripts"`
+	EncryptionKey            string                `mapstructure:"encryption_key" description:"key to use for encrypting database entries"`
+	SkipSSLValidation        bool                  `mapstructure:"skip_ssl_validation" description:"whether to skip ssl verification when connecting to the storage"`
+	MaxIdleConnections       int                   `mapstructure:"max_idle_connections" description:"sets the maximum number of connections in the idle connection pool"`
+	TransactionRetries       int                   `mapstructure:"transaction_retries" description:"maximum number of times a transaction which requests retries is retried after a serialization failure or a deadlock"`
+	TransactionRetryInterval time.Duration         `mapstructure:"transaction_retry_interval" description:"interval before the first retry of a transaction which is doubled before each subsequent retry"`
+	Notification             *NotificationSettings `mapstructure:"notification"`
+	SecretStore              *SecretStoreSettings  `mapstructure:"secret_store"`
+	IntegrityProcessor       security.IntegrityProcessor
 }
 
 // DefaultSettings returns default values for storage settings
 func DefaultSettings() *Settings {
 	return &Settings{
-		URI:                "",
-		MigrationsURL:      fmt.Sprintf("file://%s/postgres/migrations", basepath),
-		EncryptionKey:      "",
-		SkipSSLValidation:  false,
-		MaxIdleConnections: 5,
-		Notification:       DefaultNotificationSettings(),
+		URI:                      "",
+		ReadURIs:                 []string{},
+		MigrationsURL:            fmt.Sprintf("file://%s/postgres/migrations", basepath),
+		EncryptionKey:            "",
+		SkipSSLValidation:        false,
+		MaxIdleConnections:       5,
+		TransactionRetries:       3,
+		TransactionRetryInterval: 50 * time.Millisecond,
+		Notification:             DefaultNotificationSettings(),
+		SecretStore:              DefaultSecretStoreSettings(),
 		IntegrityProcessor: &security.HashingIntegrityProcessor{
 			HashingFunc: func(data []byte) []byte {
 				hash := sha256.Sum256(data)
@@ -92,9 +94,18 @@ func (s *Settings) Validate() error {
 	if len(s.EncryptionKey) != 32 {
 		return fmt.Errorf("validate Settings: StorageEncryptionKey must be exactly 32 symbols long but was %d symbols long", len(s.EncryptionKey))
 	}
+	if s.TransactionRetries < 0 {
+		return fmt.Errorf("validate Settings: StorageTransactionRetries must not be negative")
+	}
+	if s.TransactionRetryInterval < 0 {
+		return fmt.Errorf("validate Settings: StorageTransactionRetryInterval must not be negative")
+	}
 	if s.IntegrityProcessor == nil {
 		return fmt.Errorf("validate Settings: StorageIntegrityProcessor must not be nil")
 	}
+	if err := s.SecretStore.Validate(); err != nil {
+		return err
+	}
 	return s.Notification.Validate()
 }
 
@@ -149,6 +160,7 @@ type OpenCloser interface {
 }
 
 // Pinger allows pinging the storage to check liveliness
+//
 //go:generate counterfeiter . Pinger
 type Pinger interface {
 	// PingContext verifies a connection to the database is still alive, establishing a connection if necessary.
@@ -182,6 +194,14 @@ type Repository interface {
 	// Count retrieves number of objects of particular type in SM DB
 	Count(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (int, error)
 
+	// CountLabelValues retrieves the number of objects of particular type in SM DB having each of the label values,
+	// ordered by label key and value
+	CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error)
+
+	// Search retrieves the ids of the objects of particular type in SM DB matching the full-text search text,
+	// ordered by the rank of the match
+	Search(ctx context.Context, objectType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error)
+
 	// DeleteReturning deletes objects from SM DB
 	DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error)
 
@@ -196,14 +216,16 @@ type Repository interface {
 type TransactionalRepository interface {
 	Repository
 
-	// InTransaction initiates a transaction and allows passing a function to be executed within the transaction
-	InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error) error
+	// InTransaction initiates a transaction and allows passing a function to be executed within the transaction.
+	// The function may be executed more than once if the transaction is retried after a serialization failure.
+	InTransaction(ctx context.Context, f func(ctx context.Context, storage Repository) error, opts ...TransactionOption) error
 }
 
 // TransactionalRepositoryDecorator allows decorating a TransactionalRepository
 type TransactionalRepositoryDecorator func(TransactionalRepository) (TransactionalRepository, error)
 
 // Storage interface provides entity-specific storages
+//
 //go:generate counterfeiter . Storage
 type Storage interface {
 	OpenCloser
@@ -213,6 +235,94 @@ type Storage interface {
 	Introduce(entity Entity)
 }
 
+// Partitioner maintains the partitions of the storage which hold the objects of particular type by their creation time,
+// so that the expired objects are dropped with their partitions instead of being deleted one by one
+//
+//go:generate counterfeiter . Partitioner
+type Partitioner interface {
+	// CreatePartitions creates the missing partitions which hold the objects of the type created in the given period
+	CreatePartitions(ctx context.Context, objectType types.ObjectType, from, to time.Time) error
+
+	// DropPartitions drops the partitions which hold only objects of the type created before the given time. The objects
+	// created before the given time which are not held by any of the partitions are deleted. Objects which are kept
+	// depending on their state, e.g. operations in progress, are kept when their partitions are dropped.
+	DropPartitions(ctx context.Context, objectType types.ObjectType, before time.Time) error
+}
+
+// MaintenanceModeStore stores the maintenance mode which is shared by all Service Manager instances
+type MaintenanceModeStore interface {
+	// GetMaintenanceMode returns the maintenance mode from the storage
+	GetMaintenanceMode(ctx context.Context) (*types.MaintenanceMode, error)
+
+	// SetMaintenanceMode sets the maintenance mode in the storage
+	SetMaintenanceMode(ctx context.Context, mode *types.MaintenanceMode) error
+}
+
+// ConfigurationStore stores the changes of the configuration made at runtime and notifies all Service Manager
+// instances about them
+type ConfigurationStore interface {
+	// GetConfigurationChanges returns the changes of the configuration sections by the names of the sections
+	GetConfigurationChanges(ctx context.Context) (map[string]json.RawMessage, error)
+
+	// UpdateConfigurationChanges calls update with the stored changes of the configuration sections and stores the
+	// changes of the sections it returns. Concurrent updates are serialized, so that no update is lost. All Service
+	// Manager instances are notified about the updated sections once they are stored.
+	UpdateConfigurationChanges(ctx context.Context, update func(changes map[string]json.RawMessage) (map[string]json.RawMessage, error)) error
+
+	// ListenConfigurationChanges calls onChange with the name of each configuration section changed by any Service
+	// Manager instance until the context is done. onChange is called with an empty name if changes may have been missed.
+	ListenConfigurationChanges(ctx context.Context, wg *sync.WaitGroup, onChange func(section string)) error
+}
+
+// NotificationsLagStore counts the notifications which the platforms have not acknowledged yet
+type NotificationsLagStore interface {
+	// GetNotificationsLags returns the number of notifications for each platform after the revision it has acknowledged
+	// by the ids of the platforms. Platforms which do not acknowledge notifications are left out.
+	GetNotificationsLags(ctx context.Context) (map[string]int64, error)
+}
+
+// NotificationsLagsFunc is an adapter that allows to use regular functions as NotificationsLagStore
+type NotificationsLagsFunc func(context.Context) (map[string]int64, error)
+
+// GetNotificationsLags allows NotificationsLagsFunc to act as a NotificationsLagStore
+func (f NotificationsLagsFunc) GetNotificationsLags(ctx context.Context) (map[string]int64, error) {
+	return f(ctx)
+}
+
+// CredentialsUsageStore records when the current and the old credentials of the platforms were last used
+type CredentialsUsageStore interface {
+	// RecordCredentialsUsage records that the platform has authenticated with the current or the old credentials with
+	// the given username at the given time, unless their usage has been recorded within the given interval. The usage
+	// is recorded without updating the platform, so that it changes neither its updated_at nor creates notifications.
+	RecordCredentialsUsage(ctx context.Context, platformID, username string, usedAt time.Time, interval time.Duration) error
+}
+
+// CredentialsUsageFunc is an adapter that allows to use regular functions as CredentialsUsageStore
+type CredentialsUsageFunc func(ctx context.Context, platformID, username string, usedAt time.Time, interval time.Duration) error
+
+// RecordCredentialsUsage allows CredentialsUsageFunc to act as a CredentialsUsageStore
+func (f CredentialsUsageFunc) RecordCredentialsUsage(ctx context.Context, platformID, username string, usedAt time.Time, interval time.Duration) error {
+	return f(ctx, platformID, username, usedAt, interval)
+}
+
+// IdempotencyStore stores the responses of the requests sent with idempotency keys, so that they can be replayed when
+// the requests are repeated
+type IdempotencyStore interface {
+	// ReserveIdempotencyKey stores the idempotency key for the request unless the user has already used the key and it
+	// has not expired yet. It returns nil if the key has been reserved and the already stored key otherwise.
+	ReserveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) (*types.IdempotencyKey, error)
+
+	// CompleteIdempotencyKey stores the response of the request for which the idempotency key has been reserved and
+	// keeps the key until the expiration time of the given key
+	CompleteIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) error
+
+	// ReleaseIdempotencyKey deletes the idempotency key of the user, so that the request can be retried with it
+	ReleaseIdempotencyKey(ctx context.Context, userName, key string) error
+
+	// DeleteExpiredIdempotencyKeys deletes the idempotency keys which expired before the given time
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error
+}
+
 // ErrQueueClosed error stating that the queue is closed
 var ErrQueueClosed = errors.New("queue closed")
 
@@ -220,6 +330,7 @@ var ErrQueueClosed = errors.New("queue closed")
 var ErrQueueFull = errors.New("queue is full")
 
 // NotificationQueue is used for receiving notifications
+//
 //go:generate counterfeiter . NotificationQueue
 type NotificationQueue interface {
 	// Enqueue adds a new notification for processing.
@@ -236,6 +347,7 @@ type NotificationQueue interface {
 }
 
 // Notificator is used for receiving notifications for SM events
+//
 //go:generate counterfeiter . Notificator
 type Notificator interface {
 	// Start starts the Notificator
@@ -244,8 +356,16 @@ type Notificator interface {
 	// RegisterConsumer returns notification queue, last_known_revision and error if any.
 	// Notifications after lastKnownRevision will be added to the queue.
 	// If lastKnownRevision is -1 no previous notifications will be sent.
+	// Only notifications which match the subscription are added to the queue, a nil subscription matches all notifications.
+	// When consumer wants to stop listening for notifications it must unregister the notification queue.
+	RegisterConsumer(consumer *types.Platform, lastKnownRevision int64, subscription *NotificationSubscription) (NotificationQueue, int64, error)
+
+	// RegisterSnapshotConsumer returns notification queue, the revision at which the snapshot was taken and error if any.
+	// The notifications returned by the snapshot function are added to the queue first, followed by a SNAPSHOT_END
+	// notification with the snapshot revision and the notifications after the snapshot revision. It is used by consumers
+	// which are too far behind to receive the missed notifications.
 	// When consumer wants to stop listening for notifications it must unregister the notification queue.
-	RegisterConsumer(consumer *types.Platform, lastKnownRevision int64) (NotificationQueue, int64, error)
+	RegisterSnapshotConsumer(consumer *types.Platform, snapshot NotificationsSnapshotFunc, subscription *NotificationSubscription) (NotificationQueue, int64, error)
 
 	// UnregisterConsumer must be called to stop receiving notifications in the queue
 	UnregisterConsumer(queue NotificationQueue) error
@@ -254,5 +374,9 @@ type Notificator interface {
 	RegisterFilter(f ReceiversFilterFunc)
 }
 
+// NotificationsSnapshotFunc returns notifications which recreate the current state visible to the given platform
+// together with the revision of the last notification reflected in that state
+type NotificationsSnapshotFunc func(ctx context.Context, platform *types.Platform) ([]*types.Notification, int64, error)
+
 // ReceiversFilterFunc filters recipients for a given notifications
 type ReceiversFilterFunc func(recipients []*types.Platform, notification *types.Notification) (filteredRecipients []*types.Platform)
@@ -0,0 +1,246 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/storage/postgres"
+	"github.com/lib/pq"
+)
+
+type valueKind int
+
+const (
+	textKind valueKind = iota
+	numberKind
+	timeKind
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	nullTimeType  = reflect.TypeOf(pq.NullTime{})
+	nullStrType   = reflect.TypeOf(sql.NullString{})
+	nullBoolType  = reflect.TypeOf(sql.NullBool{})
+	nullIntType   = reflect.TypeOf(sql.NullInt64{})
+	nullFloatType = reflect.TypeOf(sql.NullFloat64{})
+
+	timeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999Z07",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+	}
+)
+
+// value is the value of a column as it is compared by the storage. NULL values are never equal to other values.
+type value struct {
+	null   bool
+	text   string
+	number float64
+	time   time.Time
+}
+
+// column describes a field of an entity which is tagged with a db tag in the same way as the PostgreSQL columns
+type column struct {
+	name          string
+	index         []int
+	kind          valueKind
+	nullable      bool
+	autoIncrement bool
+}
+
+// columnsOf returns the columns of the entity struct type by their names
+func columnsOf(entityType reflect.Type) map[string]*column {
+	columns := make(map[string]*column)
+	addColumns(entityType, nil, columns)
+	return columns
+}
+
+func addColumns(structType reflect.Type, index []int, columns map[string]*column) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addColumns(field.Type, fieldIndex, columns)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		tagValues := strings.Split(tag, ",")
+		name := tagValues[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == postgres.LabelsColumn {
+			// the labels are stored together with the entity in its row
+			continue
+		}
+		kind, nullable := kindOf(field.Type)
+		columns[name] = &column{
+			name:          name,
+			index:         fieldIndex,
+			kind:          kind,
+			nullable:      nullable,
+			autoIncrement: strings.Contains(tag, "auto_increment"),
+		}
+	}
+}
+
+func kindOf(fieldType reflect.Type) (valueKind, bool) {
+	switch fieldType {
+	case timeType:
+		return timeKind, false
+	case nullTimeType:
+		return timeKind, true
+	case nullStrType, nullBoolType:
+		return textKind, true
+	case nullIntType, nullFloatType:
+		return numberKind, true
+	}
+	switch fieldType.Kind() {
+	case reflect.Ptr:
+		kind, _ := kindOf(fieldType.Elem())
+		return kind, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return numberKind, false
+	default:
+		return textKind, false
+	}
+}
+
+// valueOf returns the value of the column in the entity, entity must be a pointer to a struct
+func (c *column) valueOf(entity interface{}) value {
+	return fieldValue(reflect.ValueOf(entity).Elem().FieldByIndex(c.index))
+}
+
+func fieldValue(field reflect.Value) value {
+	switch v := field.Interface().(type) {
+	case time.Time:
+		return value{time: v}
+	case pq.NullTime:
+		return value{null: !v.Valid, time: v.Time}
+	case sql.NullString:
+		return value{null: !v.Valid, text: v.String}
+	case sql.NullBool:
+		return value{null: !v.Valid, text: strconv.FormatBool(v.Bool)}
+	case sql.NullInt64:
+		return value{null: !v.Valid, number: float64(v.Int64)}
+	case sql.NullFloat64:
+		return value{null: !v.Valid, number: v.Float64}
+	}
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() {
+			return value{null: true}
+		}
+		return fieldValue(field.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value{number: float64(field.Int())}
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value{number: float64(field.Uint())}
+	case reflect.Float32, reflect.Float64:
+		return value{number: field.Float()}
+	case reflect.Bool:
+		return value{text: strconv.FormatBool(field.Bool())}
+	case reflect.String:
+		return value{text: field.String()}
+	case reflect.Slice:
+		if field.IsNil() {
+			return value{null: true}
+		}
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			return value{text: string(field.Bytes())}
+		}
+	}
+	return value{text: fmt.Sprint(field.Interface())}
+}
+
+// parseValue converts a query operand to a value of the column. An empty operand is NULL for nullable columns
+// if emptyIsNull is set.
+func (c *column) parseValue(operand string, emptyIsNull bool) (value, error) {
+	if emptyIsNull && operand == "" && c.nullable {
+		return value{null: true}, nil
+	}
+	switch c.kind {
+	case numberKind:
+		number, err := strconv.ParseFloat(operand, 64)
+		if err != nil {
+			return value{}, fmt.Errorf("invalid input syntax for type numeric: %q", operand)
+		}
+		return value{number: number}, nil
+	case timeKind:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, operand); err == nil {
+				return value{time: t}, nil
+			}
+		}
+		return value{}, fmt.Errorf("invalid input syntax for type timestamp: %q", operand)
+	default:
+		return value{text: operand}, nil
+	}
+}
+
+// compare compares two non NULL values of the column
+func (c *column) compare(left, right value) int {
+	switch c.kind {
+	case numberKind:
+		switch {
+		case left.number < right.number:
+			return -1
+		case left.number > right.number:
+			return 1
+		}
+		return 0
+	case timeKind:
+		switch {
+		case left.time.Before(right.time):
+			return -1
+		case left.time.After(right.time):
+			return 1
+		}
+		return 0
+	default:
+		return strings.Compare(left.text, right.text)
+	}
+}
+
+// compareForOrder compares the values as PostgreSQL orders them - NULL values are greater than all other values
+func (c *column) compareForOrder(left, right value) int {
+	switch {
+	case left.null && right.null:
+		return 0
+	case left.null:
+		return 1
+	case right.null:
+		return -1
+	}
+	return c.compare(left, right)
+}
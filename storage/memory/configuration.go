@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/Peripli/service-manager/storage/postgres"
+)
+
+const configurationChangesChannel = "configuration_changes"
+
+// GetConfigurationChanges returns the changes of the configuration sections made at runtime
+func (s *Storage) GetConfigurationChanges(ctx context.Context) (map[string]json.RawMessage, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	changes := make(map[string]json.RawMessage, len(s.configurationChanges))
+	for section, sectionChanges := range s.configurationChanges {
+		changes[section] = sectionChanges
+	}
+	return changes, nil
+}
+
+// UpdateConfigurationChanges stores the changes returned by update for the stored changes and notifies the
+// connections listening for configuration changes
+func (s *Storage) UpdateConfigurationChanges(ctx context.Context, update func(changes map[string]json.RawMessage) (map[string]json.RawMessage, error)) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	changes := make(map[string]json.RawMessage, len(s.configurationChanges))
+	for section, sectionChanges := range s.configurationChanges {
+		changes[section] = sectionChanges
+	}
+	updatedChanges, err := update(changes)
+	if err != nil {
+		s.mutex.Unlock()
+		return err
+	}
+	sections := make([]string, 0, len(updatedChanges))
+	for section, sectionChanges := range updatedChanges {
+		s.configurationChanges[section] = append(json.RawMessage{}, sectionChanges...)
+		sections = append(sections, section)
+	}
+	s.mutex.Unlock()
+
+	s.notify(configurationChangesChannel, sections)
+	return nil
+}
+
+// ListenConfigurationChanges listens for the configuration changes made through the storage
+func (s *Storage) ListenConfigurationChanges(ctx context.Context, wg *sync.WaitGroup, onChange func(section string)) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	return postgres.ListenConfigurationChangesWithConnection(ctx, wg, s.newNotificationConnection, onChange)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// uniqueConstraints are the unique constraints of the PostgreSQL schema besides the primary keys
+var uniqueConstraints = map[types.ObjectType][][]string{
+	types.PlatformType:                 {{"name"}, {"username"}},
+	types.ServiceBrokerType:            {{"name"}},
+	types.ServiceOfferingType:          {{"broker_id", "name"}, {"broker_id", "catalog_id"}, {"broker_id", "catalog_name"}},
+	types.ServicePlanType:              {{"service_offering_id", "name"}, {"service_offering_id", "catalog_id"}, {"service_offering_id", "catalog_name"}},
+	types.VisibilityType:               {{"platform_id", "service_plan_id"}},
+	types.BrokerPlatformCredentialType: {{"platform_id", "broker_id"}, {"broker_id", "username"}},
+	types.RoleType:                     {{"name"}},
+	types.LabelDefinitionType:          {{"key"}},
+}
+
+type foreignKey struct {
+	objectType types.ObjectType
+	column     string
+	references types.ObjectType
+	cascade    bool
+}
+
+// foreignKeys are the foreign keys of the PostgreSQL schema. The referenced rows are deleted together with the
+// referencing ones if cascade is set and cannot be deleted while referenced otherwise.
+var foreignKeys = []foreignKey{
+	{objectType: types.ServiceOfferingType, column: "broker_id", references: types.ServiceBrokerType, cascade: true},
+	{objectType: types.ServicePlanType, column: "service_offering_id", references: types.ServiceOfferingType, cascade: true},
+	{objectType: types.VisibilityType, column: "platform_id", references: types.PlatformType, cascade: true},
+	{objectType: types.VisibilityType, column: "service_plan_id", references: types.ServicePlanType, cascade: true},
+	{objectType: types.NotificationType, column: "platform_id", references: types.PlatformType, cascade: true},
+	{objectType: types.ServiceInstanceType, column: "service_plan_id", references: types.ServicePlanType},
+	{objectType: types.ServiceInstanceType, column: "platform_id", references: types.PlatformType},
+	{objectType: types.ServiceBindingType, column: "service_instance_id", references: types.ServiceInstanceType},
+	{objectType: types.BrokerPlatformCredentialType, column: "platform_id", references: types.PlatformType, cascade: true},
+	{objectType: types.BrokerPlatformCredentialType, column: "broker_id", references: types.ServiceBrokerType, cascade: true},
+}
+
+// rowReader returns the rows of a type as seen by a transaction
+type rowReader interface {
+	rows(objectType types.ObjectType) []*row
+	row(objectType types.ObjectType, id string) *row
+}
+
+// checkConstraints checks the unique, foreign key and check constraints of the schema for the new state of the row
+func checkConstraints(reader rowReader, et *entityType, r *row) error {
+	id := r.entity.GetID()
+	for _, other := range reader.rows(et.objectType) {
+		if other.entity.GetID() == id {
+			continue
+		}
+		for _, columns := range uniqueConstraints[et.objectType] {
+			if conflicts(et, r, other, columns) {
+				return util.ErrAlreadyExistsInStorage
+			}
+		}
+	}
+
+	for _, fk := range foreignKeys {
+		if fk.objectType != et.objectType {
+			continue
+		}
+		v := et.columns[fk.column].valueOf(r.entity)
+		if !v.null && reader.row(fk.references, v.text) == nil {
+			return &util.ErrBadRequestStorage{Cause: fmt.Errorf("insert or update on %s violates foreign key constraint on column %s: %s %s does not exist", et.objectType, fk.column, fk.references, v.text)}
+		}
+	}
+
+	if et.objectType == types.VisibilityType {
+		return checkUniquePublicPlan(reader, et, r)
+	}
+	return nil
+}
+
+// conflicts returns whether the rows have the same values in the columns. As in PostgreSQL, NULL values never conflict.
+func conflicts(et *entityType, r *row, other *row, columns []string) bool {
+	for _, name := range columns {
+		c := et.columns[name]
+		v, otherValue := c.valueOf(r.entity), c.valueOf(other.entity)
+		if v.null || otherValue.null || c.compare(v, otherValue) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkUniquePublicPlan mirrors the check_unique_public_plan constraint - a plan which has a public visibility
+// cannot have other visibilities
+func checkUniquePublicPlan(reader rowReader, et *entityType, r *row) error {
+	id := r.entity.GetID()
+	planID := et.columns["service_plan_id"].valueOf(r.entity).text
+	isPublic := et.columns["platform_id"].valueOf(r.entity).null
+	for _, other := range reader.rows(et.objectType) {
+		if other.entity.GetID() == id || et.columns["service_plan_id"].valueOf(other.entity).text != planID {
+			continue
+		}
+		if isPublic || et.columns["platform_id"].valueOf(other.entity).null {
+			return &util.ErrBadRequestStorage{Cause: fmt.Errorf("new row for relation visibilities violates check constraint \"unique_public_plan_visibility\"")}
+		}
+	}
+	return nil
+}
+
+// referencing returns the foreign keys which reference the type
+func referencing(objectType types.ObjectType) []foreignKey {
+	result := make([]foreignKey, 0)
+	for _, fk := range foreignKeys {
+		if fk.references == objectType {
+			result = append(result, fk)
+		}
+	}
+	return result
+}
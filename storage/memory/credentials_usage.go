@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage/postgres"
+)
+
+// RecordCredentialsUsage records the time in which the current or the old credentials of the platform were last used
+func (s *Storage) RecordCredentialsUsage(ctx context.Context, platformID, username string, usedAt time.Time, interval time.Duration) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	entityType, err := s.entityType(types.PlatformType)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r, found := s.tables[types.PlatformType][platformID]
+	if !found {
+		return nil
+	}
+	platform := cloneEntity(r.entity).(*postgres.Platform)
+	notAfter := usedAt.Add(-interval)
+	switch {
+	case platform.Username == username && platform.CredentialsLastUsedAt.Before(notAfter):
+		platform.CredentialsLastUsedAt = usedAt
+	case platform.Username != username && platform.OldUsername == username && platform.OldCredentialsLastUsedAt.Before(notAfter):
+		platform.OldCredentialsLastUsedAt = usedAt
+	default:
+		return nil
+	}
+	normalizeTimes(platform, entityType.columns)
+	// rows are never modified once stored, so the recorded usage is stored in a new row
+	s.tables[types.PlatformType][platformID] = &row{entity: platform, labels: r.labels}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// idempotencyKeyID identifies an idempotency key of a user
+type idempotencyKeyID struct {
+	userName string
+	key      string
+}
+
+// ReserveIdempotencyKey stores the idempotency key for the request unless the user has already used the key and it
+// has not expired yet. It returns nil if the key has been reserved and the already stored key otherwise.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) (*types.IdempotencyKey, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := idempotencyKeyID{userName: key.UserName, key: key.Key}
+	if stored, found := s.idempotencyKeys[id]; found && !stored.ExpiresAt.Before(time.Now()) {
+		return copyIdempotencyKey(stored), nil
+	}
+	s.idempotencyKeys[id] = types.IdempotencyKey{
+		UserName:    key.UserName,
+		Key:         key.Key,
+		RequestHash: key.RequestHash,
+		ExpiresAt:   key.ExpiresAt,
+	}
+	return nil, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request for which the idempotency key has been reserved and keeps
+// the key until the given expiration time
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := idempotencyKeyID{userName: key.UserName, key: key.Key}
+	stored, found := s.idempotencyKeys[id]
+	if !found || stored.Completed {
+		return nil
+	}
+	stored.ExpiresAt = key.ExpiresAt
+	stored.Completed = true
+	stored.StatusCode = key.StatusCode
+	stored.Header = key.Header
+	stored.Body = key.Body
+	s.idempotencyKeys[id] = *copyIdempotencyKey(stored)
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the idempotency key of the user, so that the request can be retried with it
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, userName, key string) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.idempotencyKeys, idempotencyKeyID{userName: userName, key: key})
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the idempotency keys which expired before the given time
+func (s *Storage) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, key := range s.idempotencyKeys {
+		if key.ExpiresAt.Before(before) {
+			delete(s.idempotencyKeys, id)
+		}
+	}
+	return nil
+}
+
+// copyIdempotencyKey copies the key, so that the stored response is not shared with the callers
+func copyIdempotencyKey(key types.IdempotencyKey) *types.IdempotencyKey {
+	if key.Header != nil {
+		header := make(http.Header, len(key.Header))
+		for name, values := range key.Header {
+			header[name] = append([]string{}, values...)
+		}
+		key.Header = header
+	}
+	if key.Body != nil {
+		key.Body = append([]byte{}, key.Body...)
+	}
+	return &key
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+)
+
+// GetEncryptionKey returns the encryption key used to encrypt the credentials for brokers
+func (s *Storage) GetEncryptionKey(ctx context.Context, transformationFunc func(context.Context, []byte, []byte) ([]byte, error)) ([]byte, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	encryptedKey := append([]byte{}, s.encryptionKey...)
+	s.mutex.RUnlock()
+	if len(encryptedKey) == 0 {
+		return []byte{}, nil
+	}
+
+	return transformationFunc(ctx, encryptedKey, s.layerOneEncryptionKey)
+}
+
+// SetEncryptionKey Sets the encryption key by encrypting it beforehand with the encryption key in the environment
+func (s *Storage) SetEncryptionKey(ctx context.Context, key []byte, transformationFunc func(context.Context, []byte, []byte) ([]byte, error)) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	bytes, err := transformationFunc(ctx, key, s.layerOneEncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.encryptionKey = bytes
+	return nil
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lib/pq"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/postgres"
+)
+
+const securityLockIndex = 111
+
+// errDeadlockDetected has the same code as the PostgreSQL error so that it can be handled in the same way
+var errDeadlockDetected = &pq.Error{Code: "40P01", Message: "deadlock detected"}
+
+// rowLocks are the exclusive row locks which are held by transactions until they are committed or rolled back
+type rowLocks struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	owners  map[rowKey]*transaction
+	waiting map[*transaction]rowKey
+}
+
+func newRowLocks() *rowLocks {
+	l := &rowLocks{
+		owners:  make(map[rowKey]*transaction),
+		waiting: make(map[*transaction]rowKey),
+	}
+	l.cond = sync.NewCond(&l.mutex)
+	return l
+}
+
+// lock waits until the row is not locked by other transactions and locks it for the transaction.
+// It fails if the context is done or if waiting would cause a deadlock.
+func (l *rowLocks) lock(ctx context.Context, tx *transaction, key rowKey) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.mutex.Lock()
+			l.cond.Broadcast()
+			l.mutex.Unlock()
+		case <-done:
+		}
+	}()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	defer delete(l.waiting, tx)
+	for {
+		owner, locked := l.owners[key]
+		if !locked || owner == tx {
+			l.owners[key] = tx
+			tx.lockedRows[key] = true
+			return nil
+		}
+		if l.waitsFor(owner, tx) {
+			return errDeadlockDetected
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		l.waiting[tx] = key
+		l.cond.Wait()
+	}
+}
+
+// waitsFor returns whether the transaction waits directly or transitively for the other transaction
+func (l *rowLocks) waitsFor(tx *transaction, other *transaction) bool {
+	visited := make(map[*transaction]bool)
+	for tx != nil && !visited[tx] {
+		visited[tx] = true
+		key, waiting := l.waiting[tx]
+		if !waiting {
+			return false
+		}
+		tx = l.owners[key]
+		if tx == other {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *rowLocks) unlockAll(tx *transaction) {
+	if len(tx.lockedRows) == 0 {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for key := range tx.lockedRows {
+		if l.owners[key] == tx {
+			delete(l.owners, key)
+		}
+	}
+	tx.lockedRows = make(map[rowKey]bool)
+	l.cond.Broadcast()
+}
+
+// advisoryLocks are the in-memory equivalent of the PostgreSQL advisory locks
+type advisoryLocks struct {
+	mutex sync.Mutex
+	locks map[int]chan struct{}
+}
+
+func newAdvisoryLocks() *advisoryLocks {
+	return &advisoryLocks{
+		locks: make(map[int]chan struct{}),
+	}
+}
+
+func (a *advisoryLocks) get(index int) chan struct{} {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	lock, exists := a.locks[index]
+	if !exists {
+		lock = make(chan struct{}, 1)
+		a.locks[index] = lock
+	}
+	return lock
+}
+
+// EncryptingLocker builds an encrypting storage.Locker with the pre-defined lock index
+func EncryptingLocker(storage *Storage) storage.Locker {
+	return &Locker{Storage: storage, AdvisoryIndex: securityLockIndex}
+}
+
+// Locker is a storage.Locker which locks an in-memory advisory lock with the specified index
+type Locker struct {
+	*Storage
+	AdvisoryIndex int
+
+	mutex    sync.Mutex
+	isLocked bool
+}
+
+// Lock acquires the lock so that only one process can manipulate the resource it guards.
+// Returns an error if the process has already acquired the lock
+func (l *Locker) Lock(ctx context.Context) error {
+	log.C(ctx).Infof("Attempting to lock advisory lock with index (%d)", l.AdvisoryIndex)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.isLocked {
+		log.C(ctx).Infof("Locker with advisory index (%d) is locked, so no attempt to lock it", l.AdvisoryIndex)
+		return fmt.Errorf("lock is already acquired")
+	}
+
+	select {
+	case l.advisoryLocks.get(l.AdvisoryIndex) <- struct{}{}:
+	case <-ctx.Done():
+		log.C(ctx).Infof("Failed to lock locker with advisory index (%d)", l.AdvisoryIndex)
+		return ctx.Err()
+	}
+	l.isLocked = true
+
+	log.C(ctx).Infof("Successfully locked locker with advisory index (%d)", l.AdvisoryIndex)
+	return nil
+}
+
+// TryLock tries to acquire the lock and returns an error without waiting if it is already acquired
+func (l *Locker) TryLock(ctx context.Context) error {
+	log.C(ctx).Infof("Attempting to try_lock advisory lock with index (%d)", l.AdvisoryIndex)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.isLocked {
+		log.C(ctx).Infof("Locker with advisory index (%d) is locked, so no attempt to try_lock it", l.AdvisoryIndex)
+		return fmt.Errorf("try_lock is already acquired")
+	}
+
+	select {
+	case l.advisoryLocks.get(l.AdvisoryIndex) <- struct{}{}:
+	default:
+		log.C(ctx).Infof("Failed to try_lock locker with advisory index (%d) - already locked", l.AdvisoryIndex)
+		return postgres.ErrLockAcquisition
+	}
+	l.isLocked = true
+
+	log.C(ctx).Infof("Successfully try_locked locker with advisory index (%d)", l.AdvisoryIndex)
+	return nil
+}
+
+// Unlock releases the lock
+func (l *Locker) Unlock(ctx context.Context) error {
+	log.C(ctx).Infof("Attempting to unlock advisory lock with index (%d)", l.AdvisoryIndex)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.isLocked {
+		log.C(ctx).Infof("Locker with advisory index (%d) is not locked, so no attempt to unlock it", l.AdvisoryIndex)
+		return nil
+	}
+
+	<-l.advisoryLocks.get(l.AdvisoryIndex)
+	l.isLocked = false
+
+	log.C(ctx).Infof("Successfully unlocked locker with advisory index (%d)", l.AdvisoryIndex)
+	return nil
+}
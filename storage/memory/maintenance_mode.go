@@ -0,0 +1,47 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// GetMaintenanceMode returns the maintenance mode of the Service Manager
+func (s *Storage) GetMaintenanceMode(ctx context.Context) (*types.MaintenanceMode, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	mode := s.maintenanceMode
+	return &mode, nil
+}
+
+// SetMaintenanceMode sets the maintenance mode of the Service Manager
+func (s *Storage) SetMaintenanceMode(ctx context.Context, mode *types.MaintenanceMode) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.maintenanceMode = *mode
+	return nil
+}
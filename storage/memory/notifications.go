@@ -0,0 +1,183 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/lib/pq"
+
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/postgres"
+	notificationConnection "github.com/Peripli/service-manager/storage/postgres/notification_connection"
+)
+
+const notificationsChannel = "notifications"
+
+// notificationEvent is the payload of the events which the PostgreSQL notifications trigger sends
+type notificationEvent struct {
+	NotificationID string  `json:"notification_id"`
+	PlatformID     *string `json:"platform_id"`
+	Revision       int64   `json:"revision"`
+}
+
+// NewNotificator returns a Notificator which receives the notification events of the in-memory storage
+func NewNotificator(s *Storage, settings *storage.Settings) (*postgres.Notificator, error) {
+	return postgres.NewNotificatorWithConnection(s, settings, s.newNotificationConnection)
+}
+
+func (s *Storage) newNotificationConnection(eventCallback func(isRunning bool, err error)) notificationConnection.NotificationConnection {
+	connection := &notificationListener{
+		storage:       s,
+		channels:      make(map[string]bool),
+		notifications: make(chan *pq.Notification),
+		wakeup:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	s.connectionsMutex.Lock()
+	s.connections[connection] = true
+	s.connectionsMutex.Unlock()
+
+	go connection.pump()
+	eventCallback(true, nil)
+	return connection
+}
+
+// notify sends the events to the connections which listen to the channel
+func (s *Storage) notify(channel string, events []string) {
+	if len(events) == 0 {
+		return
+	}
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+	for connection := range s.connections {
+		connection.enqueue(channel, events)
+	}
+}
+
+// notificationListener delivers the events in the order in which they are sent without blocking the senders
+type notificationListener struct {
+	storage *Storage
+
+	mutex    sync.Mutex
+	channels map[string]bool
+	pending  []*pq.Notification
+	closed   bool
+
+	notifications chan *pq.Notification
+	wakeup        chan struct{}
+	done          chan struct{}
+}
+
+func (c *notificationListener) enqueue(channel string, events []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed || !c.channels[channel] {
+		return
+	}
+	for _, event := range events {
+		c.pending = append(c.pending, &pq.Notification{Channel: channel, Extra: event})
+	}
+	select {
+	case c.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (c *notificationListener) pump() {
+	defer close(c.notifications)
+	for {
+		c.mutex.Lock()
+		if len(c.pending) == 0 {
+			c.mutex.Unlock()
+			select {
+			case <-c.wakeup:
+				continue
+			case <-c.done:
+				return
+			}
+		}
+		notification := c.pending[0]
+		c.pending = c.pending[1:]
+		c.mutex.Unlock()
+
+		select {
+		case c.notifications <- notification:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *notificationListener) Listen(channel string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return errConnectionClosed
+	}
+	if c.channels[channel] {
+		return pq.ErrChannelAlreadyOpen
+	}
+	c.channels[channel] = true
+	return nil
+}
+
+func (c *notificationListener) Unlisten(channel string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return errConnectionClosed
+	}
+	if !c.channels[channel] {
+		return pq.ErrChannelNotOpen
+	}
+	delete(c.channels, channel)
+	return nil
+}
+
+func (c *notificationListener) Close() error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.pending = nil
+	close(c.done)
+	c.mutex.Unlock()
+
+	c.storage.connectionsMutex.Lock()
+	delete(c.storage.connections, c)
+	c.storage.connectionsMutex.Unlock()
+	return nil
+}
+
+func (c *notificationListener) Ping() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return errConnectionClosed
+	}
+	return nil
+}
+
+func (c *notificationListener) NotificationChannel() <-chan *pq.Notification {
+	return c.notifications
+}
+
+var errConnectionClosed = errors.New("notification connection is closed")
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage/postgres"
+)
+
+// GetNotificationsLags returns the number of notifications after the acknowledged revision by the ids of the platforms
+// which acknowledge notifications
+func (s *Storage) GetNotificationsLags(ctx context.Context) (map[string]int64, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	acknowledgedRevisions := make(map[string]int64)
+	for id, r := range s.tables[types.PlatformType] {
+		platform, ok := r.entity.(*postgres.Platform)
+		if ok && platform.AcknowledgedNotificationRevision > 0 {
+			acknowledgedRevisions[id] = platform.AcknowledgedNotificationRevision
+		}
+	}
+
+	lags := make(map[string]int64, len(acknowledgedRevisions))
+	for platformID, revision := range acknowledgedRevisions {
+		lags[platformID] = 0
+		for _, r := range s.tables[types.NotificationType] {
+			notification, ok := r.entity.(*postgres.Notification)
+			if !ok || notification.Revision <= revision {
+				continue
+			}
+			if !notification.PlatformID.Valid || notification.PlatformID.String == platformID {
+				lags[platformID]++
+			}
+		}
+	}
+	return lags, nil
+}
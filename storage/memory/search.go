@@ -0,0 +1,91 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// searchColumn is a column of the entities which is part of their search document
+type searchColumn struct {
+	name   string
+	weight float64
+}
+
+// searchColumns are the columns of the search document with the weights of their ranks as defined by the
+// search_document function of the PostgreSQL storage and the default weights of ts_rank
+var searchColumns = []searchColumn{
+	{name: "name", weight: 1.0},
+	{name: "catalog_name", weight: 1.0},
+	{name: "description", weight: 0.4},
+	{name: "metadata", weight: 0.2},
+}
+
+// searchTerms splits the text into lower case words as the simple text search configuration of PostgreSQL does
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchRank ranks the row for the search terms. As with plainto_tsquery, each of the terms has to be contained in the
+// search document of the row, otherwise the rank is zero. Each term is ranked by the weight of the highest ranked
+// column which contains it.
+func searchRank(et *entityType, r *row, terms []string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	documentWeights := make(map[string]float64)
+	for _, searchColumn := range searchColumns {
+		column, found := et.columns[searchColumn.name]
+		if !found {
+			continue
+		}
+		value := column.valueOf(r.entity)
+		if value.null {
+			continue
+		}
+		for _, word := range searchTerms(value.text) {
+			if documentWeights[word] < searchColumn.weight {
+				documentWeights[word] = searchColumn.weight
+			}
+		}
+	}
+	var rank float64
+	for _, term := range terms {
+		weight, found := documentWeights[term]
+		if !found {
+			return 0
+		}
+		rank += weight
+	}
+	return rank / float64(len(terms))
+}
+
+// sortSearchMatches orders the matches by rank as the PostgreSQL storage does
+func sortSearchMatches(matches []*types.SearchMatch) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Rank != matches[j].Rank {
+			return matches[i].Rank > matches[j].Rank
+		}
+		return matches[i].ID < matches[j].ID
+	})
+}
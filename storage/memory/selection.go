@@ -0,0 +1,240 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+const pagingSequenceColumn = "paging_sequence"
+
+type orderRule struct {
+	column    *column
+	orderType query.OrderType
+}
+
+// selection evaluates query criteria in the same way as the queries of the PostgreSQL storage
+type selection struct {
+	entityType    *entityType
+	fieldCriteria []query.Criterion
+	labelCriteria []query.Criterion
+	orderBy       []orderRule
+	limit         int
+	pageAfter     []value
+}
+
+func newSelection(et *entityType, criteria ...query.Criterion) (*selection, error) {
+	s := &selection{
+		entityType: et,
+		limit:      -1,
+	}
+	var pageAfter []string
+	for _, criterion := range criteria {
+		if err := criterion.Validate(); err != nil {
+			return nil, err
+		}
+		switch criterion.Type {
+		case query.FieldQuery:
+			if et.columns[criterion.LeftOp] == nil {
+				return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field query key: %s", criterion.LeftOp)}
+			}
+			s.fieldCriteria = append(s.fieldCriteria, criterion)
+		case query.LabelQuery:
+			s.labelCriteria = append(s.labelCriteria, criterion)
+		case query.ResultQuery:
+			switch criterion.LeftOp {
+			case query.OrderBy:
+				orderType := query.OrderType(criterion.RightOp[1])
+				if orderType != query.AscOrder && orderType != query.DescOrder {
+					return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported order type: %s", orderType)}
+				}
+				c := et.columns[criterion.RightOp[0]]
+				if c == nil {
+					return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported entity field for order by: %s", criterion.RightOp[0])}
+				}
+				s.orderBy = append(s.orderBy, orderRule{column: c, orderType: orderType})
+			case query.Limit:
+				if s.limit >= 0 {
+					return nil, fmt.Errorf("zero/one limit expected but multiple provided")
+				}
+				limit, err := strconv.Atoi(criterion.RightOp[0])
+				if err != nil {
+					return nil, err
+				}
+				s.limit = limit
+			case query.PageAfter:
+				if pageAfter != nil {
+					return nil, fmt.Errorf("zero/one page after criterion expected but multiple provided")
+				}
+				pageAfter = criterion.RightOp
+			}
+		}
+	}
+	if err := s.parsePageAfter(pageAfter); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *selection) parsePageAfter(values []string) error {
+	if values == nil {
+		return nil
+	}
+	if len(values) != len(s.orderBy) {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("page after expects %d values but %d were provided", len(s.orderBy), len(values))}
+	}
+	s.pageAfter = make([]value, 0, len(values))
+	for i, rule := range s.orderBy {
+		v, err := rule.column.parseValue(values[i], true)
+		if err != nil {
+			return err
+		}
+		s.pageAfter = append(s.pageAfter, v)
+	}
+	return nil
+}
+
+func (s *selection) hasLabelCriteria() bool {
+	return len(s.labelCriteria) != 0
+}
+
+// matches returns whether the row satisfies the field, label and page after criteria
+func (s *selection) matches(r *row) (bool, error) {
+	for _, criterion := range s.fieldCriteria {
+		c := s.entityType.columns[criterion.LeftOp]
+		matches, err := matchesCriterion(c, c.valueOf(r.entity), criterion)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+	if !query.MatchesLabels(r.labels, s.labelCriteria...) {
+		return false, nil
+	}
+	return s.isAfterPage(r), nil
+}
+
+func matchesCriterion(c *column, v value, criterion query.Criterion) (bool, error) {
+	if v.null {
+		return criterion.Operator == query.EqualsOrNilOperator, nil
+	}
+	operands := make([]value, 0, len(criterion.RightOp))
+	for _, operand := range criterion.RightOp {
+		parsed, err := c.parseValue(operand, false)
+		if err != nil {
+			return false, err
+		}
+		operands = append(operands, parsed)
+	}
+
+	switch criterion.Operator {
+	case query.EqualsOperator, query.EqualsOrNilOperator:
+		return c.compare(v, operands[0]) == 0, nil
+	case query.NotEqualsOperator:
+		return c.compare(v, operands[0]) != 0, nil
+	case query.GreaterThanOperator:
+		return c.compare(v, operands[0]) > 0, nil
+	case query.GreaterThanOrEqualOperator:
+		return c.compare(v, operands[0]) >= 0, nil
+	case query.LessThanOperator:
+		return c.compare(v, operands[0]) < 0, nil
+	case query.LessThanOrEqualOperator:
+		return c.compare(v, operands[0]) <= 0, nil
+	case query.InOperator, query.NotInOperator:
+		found := false
+		for _, operand := range operands {
+			if c.compare(v, operand) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (criterion.Operator == query.InOperator), nil
+	default:
+		return false, fmt.Errorf("unsupported operator %s", criterion.Operator)
+	}
+}
+
+// isAfterPage returns whether the row is ordered after the page after values
+func (s *selection) isAfterPage(r *row) bool {
+	if s.pageAfter == nil {
+		return true
+	}
+	for i, rule := range s.orderBy {
+		cmp := rule.column.compareForOrder(rule.column.valueOf(r.entity), s.pageAfter[i])
+		if rule.orderType == query.DescOrder {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp > 0
+		}
+	}
+	return false
+}
+
+// filter returns the matching rows without applying the order and the limit
+func (s *selection) filter(rows []*row) ([]*row, error) {
+	result := make([]*row, 0, len(rows))
+	for _, r := range rows {
+		matches, err := s.matches(r)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// apply returns the matching rows in the requested order limited to the requested count. The rows are ordered by
+// their paging sequence if no order is requested or if the requested order does not distinguish them.
+func (s *selection) apply(rows []*row) ([]*row, error) {
+	result, err := s.filter(rows)
+	if err != nil {
+		return nil, err
+	}
+	rules := append(append([]orderRule{}, s.orderBy...), orderRule{
+		column:    s.entityType.columns[pagingSequenceColumn],
+		orderType: query.AscOrder,
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		for _, rule := range rules {
+			if rule.column == nil {
+				continue
+			}
+			cmp := rule.column.compareForOrder(rule.column.valueOf(result[i].entity), rule.column.valueOf(result[j].entity))
+			if rule.orderType == query.DescOrder {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	if s.limit >= 0 && len(result) > s.limit {
+		result = result[:s.limit]
+	}
+	return result, nil
+}
+
+var errLabelQueriesNotSupported = errors.New("label queries are not supported by ListNoLabels")
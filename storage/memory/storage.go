@@ -0,0 +1,452 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/postgres"
+)
+
+// URIScheme is the scheme of the storage URI which selects the in-memory storage
+const URIScheme = "memory://"
+
+var errStorageClosed = errors.New("in-memory storage is closed")
+
+// IsMemoryURI returns whether the storage URI selects the in-memory storage
+func IsMemoryURI(uri string) bool {
+	return strings.HasPrefix(uri, URIScheme)
+}
+
+// listProvider is implemented by the entities which can be stored in the in-memory storage
+type listProvider interface {
+	NewList() types.ObjectList
+}
+
+// Storage is an in-memory implementation of storage.Storage. It stores the entities of the PostgreSQL storage and
+// mirrors its behaviour - queries, labels, paging, constraints, transactions with read committed isolation, row locks
+// and the notification events. The data is kept only in the memory of the process.
+type Storage struct {
+	mutex                 sync.RWMutex
+	isOpen                bool
+	isClosed              bool
+	entityTypes           map[types.ObjectType]*entityType
+	tables                map[types.ObjectType]map[string]*row
+	layerOneEncryptionKey []byte
+	encryptionKey         []byte
+	maintenanceMode       types.MaintenanceMode
+	configurationChanges  map[string]json.RawMessage
+	idempotencyKeys       map[idempotencyKeyID]types.IdempotencyKey
+
+	sequenceMutex sync.Mutex
+	sequences     map[string]int64
+
+	rowLocks      *rowLocks
+	advisoryLocks *advisoryLocks
+
+	transactionRetries       int
+	transactionRetryInterval time.Duration
+
+	connectionsMutex sync.Mutex
+	connections      map[*notificationListener]bool
+}
+
+type entityType struct {
+	objectType types.ObjectType
+	prototype  storage.Entity
+	columns    map[string]*column
+	newList    func() types.ObjectList
+}
+
+func (et *entityType) fromObject(object types.Object) (storage.Entity, error) {
+	entity, err := et.prototype.FromObject(object)
+	if err != nil {
+		return nil, fmt.Errorf("registered entity cannot convert object from type %s: %s", object.GetType(), err)
+	}
+	if reflect.TypeOf(entity) != reflect.TypeOf(et.prototype) {
+		return nil, fmt.Errorf("no entity is introduced for object of type %s", object.GetType())
+	}
+	return cloneEntity(entity), nil
+}
+
+// columnsOnly returns a copy of the entity in which only the columns are set. As in PostgreSQL the fields which
+// are not stored (e.g. tagged with db:"-") are not returned when the entity is read.
+func (et *entityType) columnsOnly(entity storage.Entity) storage.Entity {
+	source := reflect.ValueOf(entity).Elem()
+	result := reflect.New(source.Type())
+	for _, c := range et.columns {
+		result.Elem().FieldByIndex(c.index).Set(source.FieldByIndex(c.index))
+	}
+	return result.Interface().(storage.Entity)
+}
+
+// row is a stored entity together with its labels. Rows are never modified once they are stored in a table.
+type row struct {
+	entity storage.Entity
+	labels types.Labels
+}
+
+func (r *row) toObject(withLabels bool) (types.Object, error) {
+	object, err := cloneEntity(r.entity).ToObject()
+	if err != nil {
+		return nil, fmt.Errorf("could not convert entity to object: %s", err)
+	}
+	if withLabels && len(r.labels) != 0 {
+		object.SetLabels(cloneLabels(r.labels))
+	}
+	return object, nil
+}
+
+// Introduce registers the entity which is used to store objects of its type
+func (s *Storage) Introduce(entity storage.Entity) {
+	t := reflect.TypeOf(entity)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("All entities must be pointers to structs.")
+	}
+	provider, ok := entity.(listProvider)
+	if !ok {
+		panic(fmt.Sprintf("Entity %s must provide lists to be stored in memory", t))
+	}
+	obj, err := entity.ToObject()
+	if err != nil {
+		panic(fmt.Errorf("could not introduce entity: %s", err))
+	}
+	objectType := obj.GetType()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.init()
+	if _, exists := s.entityTypes[objectType]; exists {
+		panic(fmt.Sprintf("Entity for object with type %s has already been introduced", objectType))
+	}
+	s.entityTypes[objectType] = &entityType{
+		objectType: objectType,
+		prototype:  entity,
+		columns:    columnsOf(t.Elem()),
+		newList:    provider.NewList,
+	}
+	s.tables[objectType] = make(map[string]*row)
+}
+
+func (s *Storage) init() {
+	if s.entityTypes != nil {
+		return
+	}
+	s.entityTypes = make(map[types.ObjectType]*entityType)
+	s.tables = make(map[types.ObjectType]map[string]*row)
+	s.sequences = make(map[string]int64)
+	s.configurationChanges = make(map[string]json.RawMessage)
+	s.idempotencyKeys = make(map[idempotencyKeyID]types.IdempotencyKey)
+	s.rowLocks = newRowLocks()
+	s.advisoryLocks = newAdvisoryLocks()
+	s.connections = make(map[*notificationListener]bool)
+}
+
+// Open opens the storage. The same entities as in the PostgreSQL storage are introduced.
+func (s *Storage) Open(settings *storage.Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if !IsMemoryURI(settings.URI) {
+		return fmt.Errorf("in-memory storage requires a storage URI with scheme %s", URIScheme)
+	}
+
+	s.mutex.Lock()
+	if s.isOpen {
+		s.isClosed = false
+		s.mutex.Unlock()
+		return nil
+	}
+	s.init()
+	s.layerOneEncryptionKey = []byte(settings.EncryptionKey)
+	s.transactionRetries = settings.TransactionRetries
+	s.transactionRetryInterval = settings.TransactionRetryInterval
+	s.isOpen = true
+	s.mutex.Unlock()
+
+	log.D().Info("Using in-memory storage, the data will be lost when Service Manager stops")
+	if len(settings.ReadURIs) != 0 {
+		log.D().Warn("Read replicas are not supported by the in-memory storage, all reads are served by it")
+	}
+	s.Introduce(&postgres.Broker{})
+	s.Introduce(&postgres.Platform{})
+	s.Introduce(&postgres.ServiceOffering{})
+	s.Introduce(&postgres.ServicePlan{})
+	s.Introduce(&postgres.Visibility{})
+	s.Introduce(&postgres.Notification{})
+	s.Introduce(&postgres.Operation{})
+	s.Introduce(&postgres.ServiceInstance{})
+	s.Introduce(&postgres.ServiceBinding{})
+	s.Introduce(&postgres.BrokerPlatformCredential{})
+	s.Introduce(&postgres.Role{})
+	s.Introduce(&postgres.LabelDefinition{})
+
+	return nil
+}
+
+// Close closes the storage and its notification connections
+func (s *Storage) Close() error {
+	s.connectionsMutex.Lock()
+	connections := make([]*notificationListener, 0, len(s.connections))
+	for connection := range s.connections {
+		connections = append(connections, connection)
+	}
+	s.connectionsMutex.Unlock()
+	for _, connection := range connections {
+		if err := connection.Close(); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.isClosed = true
+	return nil
+}
+
+// checkOpen panics if the storage has never been opened and returns an error if it has been closed. As in
+// PostgreSQL the statements executed after the storage is closed fail.
+func (s *Storage) checkOpen() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if !s.isOpen {
+		log.D().Panicln("Storage is not yet open")
+	}
+	if s.isClosed {
+		return errStorageClosed
+	}
+	return nil
+}
+
+// PingContext returns an error if the storage is closed
+func (s *Storage) PingContext(ctx context.Context) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if !s.isOpen || s.isClosed {
+		return errStorageClosed
+	}
+	return nil
+}
+
+func (s *Storage) entityType(objectType types.ObjectType) (*entityType, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	et, exists := s.entityTypes[objectType]
+	if !exists {
+		return nil, fmt.Errorf("no entity is introduced for object of type %s", objectType)
+	}
+	return et, nil
+}
+
+// nextSequence returns the next value of an auto increment column. As in PostgreSQL the sequences are not
+// transactional - the values are not reused if a transaction is rolled back.
+func (s *Storage) nextSequence(objectType types.ObjectType, columnName string) int64 {
+	s.sequenceMutex.Lock()
+	defer s.sequenceMutex.Unlock()
+	key := string(objectType) + "." + columnName
+	s.sequences[key]++
+	return s.sequences[key]
+}
+
+func (s *Storage) Create(ctx context.Context, obj types.Object) (types.Object, error) {
+	var result types.Object
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.Create(ctx, obj)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) Get(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.Object, error) {
+	var result types.Object
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.Get(ctx, objectType, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) GetForUpdate(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.Object, error) {
+	var result types.Object
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.GetForUpdate(ctx, objectType, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) List(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+	var result types.ObjectList
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.List(ctx, objectType, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) ListNoLabels(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+	var result types.ObjectList
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.ListNoLabels(ctx, objectType, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) Count(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (int, error) {
+	var result int
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.Count(ctx, objectType, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error) {
+	var result []*types.LabelValueCount
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.CountLabelValues(ctx, objectType, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) Search(ctx context.Context, objectType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error) {
+	var result []*types.SearchMatch
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.Search(ctx, objectType, text, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+	var result types.ObjectList
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.DeleteReturning(ctx, objectType, criteria...)
+		return
+	})
+	return result, err
+}
+
+func (s *Storage) Delete(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) error {
+	return s.autoCommit(ctx, func(tx *transaction) error {
+		return tx.Delete(ctx, objectType, criteria...)
+	})
+}
+
+func (s *Storage) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, criteria ...query.Criterion) (types.Object, error) {
+	var result types.Object
+	err := s.autoCommit(ctx, func(tx *transaction) (err error) {
+		result, err = tx.Update(ctx, obj, labelChanges, criteria...)
+		return
+	})
+	return result, err
+}
+
+// InTransaction runs the function in a transaction which is committed if the function returns no error and is
+// rolled back otherwise. The changes of the transaction are visible to others only after it is committed. The
+// transaction is always read committed, the other options are applied as in PostgreSQL. It is retried if it fails
+// due to a deadlock only if retries are requested with the options.
+func (s *Storage) InTransaction(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error, opts ...storage.TransactionOption) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	options := storage.NewTransactionOptions(opts...)
+	if !options.Retries {
+		return s.inTransaction(ctx, f, options)
+	}
+	return storage.RetryTransaction(ctx, s.transactionRetries, s.transactionRetryInterval, postgres.IsRetriableTransactionError, func() error {
+		return s.inTransaction(ctx, f, options)
+	})
+}
+
+func (s *Storage) inTransaction(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error, options *storage.TransactionOptions) error {
+	tx := s.begin(true, options)
+	ok := false
+	defer func() {
+		if !ok {
+			tx.rollback()
+		}
+	}()
+
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := tx.commit(ctx); err != nil {
+		return err
+	}
+	ok = true
+	return nil
+}
+
+// autoCommit runs a single statement in its own transaction
+func (s *Storage) autoCommit(ctx context.Context, statement func(tx *transaction) error) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	tx := s.begin(false, storage.NewTransactionOptions())
+	defer tx.rollback()
+
+	if err := statement(tx); err != nil {
+		return err
+	}
+	return tx.commit(ctx)
+}
+
+func cloneLabels(labels types.Labels) types.Labels {
+	if labels == nil {
+		return nil
+	}
+	result := make(types.Labels, len(labels))
+	for key, values := range labels {
+		if len(values) != 0 {
+			result[key] = append([]string{}, values...)
+		}
+	}
+	return result
+}
+
+// normalizeTimes truncates the timestamps of the entity to the precision in which PostgreSQL stores them
+func normalizeTimes(entity storage.Entity, columns map[string]*column) {
+	value := reflect.ValueOf(entity).Elem()
+	for _, c := range columns {
+		if c.kind != timeKind {
+			continue
+		}
+		field := value.FieldByIndex(c.index)
+		if t, ok := field.Interface().(time.Time); ok {
+			field.Set(reflect.ValueOf(t.Truncate(time.Microsecond)))
+		}
+	}
+}
+
+var _ storage.Storage = &Storage{}
@@ -0,0 +1,597 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory Storage", func() {
+	var (
+		ctx      context.Context
+		memStore *Storage
+	)
+
+	newPlatform := func(name string, labels types.Labels) *types.Platform {
+		return &types.Platform{
+			Base: types.Base{
+				ID:        name + "-id",
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				Labels:    labels,
+				Ready:     true,
+			},
+			Name: name,
+			Type: "cloudfoundry",
+			Credentials: &types.Credentials{
+				Basic: &types.Basic{Username: name + "-user", Password: "password"},
+			},
+		}
+	}
+
+	createPlatform := func(name string, labels types.Labels) *types.Platform {
+		obj, err := memStore.Create(ctx, newPlatform(name, labels))
+		Expect(err).ToNot(HaveOccurred())
+		return obj.(*types.Platform)
+	}
+
+	createPlan := func() *types.ServicePlan {
+		broker := &types.ServiceBroker{
+			Base:      types.Base{ID: "broker-id", CreatedAt: time.Now(), UpdatedAt: time.Now(), Ready: true},
+			Name:      "broker",
+			BrokerURL: "https://broker.example.com",
+			Credentials: &types.Credentials{
+				Basic: &types.Basic{Username: "user", Password: "password"},
+			},
+		}
+		_, err := memStore.Create(ctx, broker)
+		Expect(err).ToNot(HaveOccurred())
+
+		offering := &types.ServiceOffering{
+			Base:        types.Base{ID: "offering-id", CreatedAt: time.Now(), UpdatedAt: time.Now(), Ready: true},
+			Name:        "offering",
+			CatalogID:   "offering-catalog-id",
+			CatalogName: "offering",
+			BrokerID:    broker.ID,
+		}
+		_, err = memStore.Create(ctx, offering)
+		Expect(err).ToNot(HaveOccurred())
+
+		plan := &types.ServicePlan{
+			Base:              types.Base{ID: "plan-id", CreatedAt: time.Now(), UpdatedAt: time.Now(), Ready: true},
+			Name:              "plan",
+			CatalogID:         "plan-catalog-id",
+			CatalogName:       "plan",
+			ServiceOfferingID: offering.ID,
+		}
+		obj, err := memStore.Create(ctx, plan)
+		Expect(err).ToNot(HaveOccurred())
+		return obj.(*types.ServicePlan)
+	}
+
+	platformNames := func(list types.ObjectList) []string {
+		names := make([]string, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			names = append(names, list.ItemAt(i).(*types.Platform).Name)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		settings := storage.DefaultSettings()
+		settings.URI = URIScheme
+		settings.EncryptionKey = "ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"
+		memStore = &Storage{}
+		Expect(memStore.Open(settings)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(memStore.Close()).To(Succeed())
+	})
+
+	Describe("Open", func() {
+		It("fails for URIs of other storages", func() {
+			settings := storage.DefaultSettings()
+			settings.URI = "postgres://localhost:5432/postgres"
+			settings.EncryptionKey = "ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"
+			Expect((&Storage{}).Open(settings)).To(HaveOccurred())
+		})
+	})
+
+	Describe("PingContext", func() {
+		It("fails when the storage is closed", func() {
+			Expect(memStore.PingContext(ctx)).To(Succeed())
+			Expect(memStore.Close()).To(Succeed())
+			Expect(memStore.PingContext(ctx)).To(HaveOccurred())
+		})
+	})
+
+	Describe("Close", func() {
+		It("fails the statements executed after the storage is closed", func() {
+			Expect(memStore.Close()).To(Succeed())
+			_, err := memStore.List(ctx, types.PlatformType)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("panics if the storage has never been opened", func() {
+			Expect(func() { (&Storage{}).List(ctx, types.PlatformType) }).To(Panic())
+		})
+	})
+
+	Describe("Create", func() {
+		It("stores the object with its labels", func() {
+			createPlatform("cf", types.Labels{"env": {"dev"}})
+
+			obj, err := memStore.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", "cf-id"))
+			Expect(err).ToNot(HaveOccurred())
+			platform := obj.(*types.Platform)
+			Expect(platform.Name).To(Equal("cf"))
+			Expect(platform.Credentials.Basic.Username).To(Equal("cf-user"))
+			Expect(platform.Labels).To(Equal(types.Labels{"env": {"dev"}}))
+		})
+
+		It("does not store the fields which are not columns", func() {
+			plan := createPlan()
+			obj, err := memStore.Get(ctx, types.ServiceOfferingType, query.ByField(query.EqualsOperator, "id", plan.ServiceOfferingID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj.(*types.ServiceOffering).Plans).To(BeEmpty())
+		})
+
+		It("does not store later modifications of the object", func() {
+			platform := newPlatform("cf", nil)
+			_, err := memStore.Create(ctx, platform)
+			Expect(err).ToNot(HaveOccurred())
+			platform.Name = "modified"
+
+			obj, err := memStore.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", "cf-id"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj.(*types.Platform).Name).To(Equal("cf"))
+		})
+
+		It("fails if a unique constraint is violated", func() {
+			createPlatform("cf", nil)
+			platform := newPlatform("other", nil)
+			platform.Name = "cf"
+			_, err := memStore.Create(ctx, platform)
+			Expect(err).To(Equal(util.ErrAlreadyExistsInStorage))
+		})
+
+		It("fails if the referenced entity does not exist", func() {
+			_, err := memStore.Create(ctx, &types.Visibility{
+				Base:          types.Base{ID: "visibility-id", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+				ServicePlanID: "missing",
+			})
+			Expect(err).To(BeAssignableToTypeOf(&util.ErrBadRequestStorage{}))
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns not found if no entity matches", func() {
+			_, err := memStore.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", "missing"))
+			Expect(err).To(Equal(util.ErrNotFoundInStorage))
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			createPlatform("a", types.Labels{"env": {"dev"}})
+			createPlatform("b", types.Labels{"env": {"prod"}, "team": {"x"}})
+			createPlatform("c", types.Labels{"env": {"dev", "prod"}})
+		})
+
+		It("selects by field criteria", func() {
+			list, err := memStore.List(ctx, types.PlatformType, query.ByField(query.InOperator, "name", "a", "c"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platformNames(list)).To(ConsistOf("a", "c"))
+		})
+
+		It("selects by label criteria", func() {
+			list, err := memStore.List(ctx, types.PlatformType, query.ByLabel(query.EqualsOperator, "env", "prod"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platformNames(list)).To(ConsistOf("b", "c"))
+
+			// as in PostgreSQL a label matches if any of its values matches
+			list, err = memStore.List(ctx, types.PlatformType, query.ByLabel(query.NotEqualsOperator, "env", "dev"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platformNames(list)).To(ConsistOf("b", "c"))
+		})
+
+		It("orders and limits the results", func() {
+			list, err := memStore.List(ctx, types.PlatformType,
+				query.OrderResultBy("name", query.DescOrder), query.LimitResultBy(2))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platformNames(list)).To(Equal([]string{"c", "b"}))
+		})
+
+		It("fails for unknown fields", func() {
+			_, err := memStore.List(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "unknown", "a"))
+			Expect(err).To(BeAssignableToTypeOf(&util.UnsupportedQueryError{}))
+		})
+
+		It("does not return the labels with ListNoLabels", func() {
+			list, err := memStore.ListNoLabels(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "name", "a"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(list.Len()).To(Equal(1))
+			Expect(list.ItemAt(0).GetLabels()).To(BeEmpty())
+		})
+
+		It("counts the matching entities", func() {
+			count, err := memStore.Count(ctx, types.PlatformType, query.ByLabel(query.EqualsOperator, "env", "dev"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+	})
+
+	Describe("Search", func() {
+		BeforeEach(func() {
+			createPlatform("cf-dev", types.Labels{"env": {"dev"}})
+			prod := newPlatform("cf-prod", types.Labels{"env": {"prod"}})
+			prod.Description = "production landscape"
+			_, err := memStore.Create(ctx, prod)
+			Expect(err).ToNot(HaveOccurred())
+			other := newPlatform("k8s", nil)
+			other.Description = "cf-dev mirror"
+			_, err = memStore.Create(ctx, other)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("ranks matches of the names above matches of the descriptions", func() {
+			matches, err := memStore.Search(ctx, types.PlatformType, "CF dev")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(HaveLen(2))
+			Expect(matches[0].ID).To(Equal("cf-dev-id"))
+			Expect(matches[1].ID).To(Equal("k8s-id"))
+			Expect(matches[0].Rank).To(BeNumerically(">", matches[1].Rank))
+		})
+
+		It("requires all of the words of the text to match", func() {
+			matches, err := memStore.Search(ctx, types.PlatformType, "production dev")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(BeEmpty())
+		})
+
+		It("applies the criteria and the limit", func() {
+			matches, err := memStore.Search(ctx, types.PlatformType, "cf", query.ByLabel(query.EqualsOperator, "env", "prod"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(HaveLen(1))
+			Expect(matches[0].ID).To(Equal("cf-prod-id"))
+
+			matches, err = memStore.Search(ctx, types.PlatformType, "cf", query.LimitResultBy(1))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(HaveLen(1))
+		})
+
+		It("fails for types which are not searchable", func() {
+			_, err := memStore.Search(ctx, types.VisibilityType, "cf")
+			Expect(err).To(BeAssignableToTypeOf(&util.UnsupportedQueryError{}))
+		})
+	})
+
+	Describe("Update", func() {
+		It("updates the fields and applies the label changes", func() {
+			platform := createPlatform("cf", types.Labels{"env": {"dev"}, "team": {"x"}})
+			platform.Description = "updated"
+
+			_, err := memStore.Update(ctx, platform, types.LabelChanges{
+				{Operation: types.AddLabelValuesOperation, Key: "env", Values: []string{"prod"}},
+				{Operation: types.RemoveLabelOperation, Key: "team"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			obj, err := memStore.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj.(*types.Platform).Description).To(Equal("updated"))
+			Expect(obj.GetLabels()).To(Equal(types.Labels{"env": {"dev", "prod"}}))
+		})
+
+		It("returns the fields which are not columns but does not store them", func() {
+			plan := createPlan()
+			obj, err := memStore.Get(ctx, types.ServiceOfferingType, query.ByField(query.EqualsOperator, "id", plan.ServiceOfferingID))
+			Expect(err).ToNot(HaveOccurred())
+			offering := obj.(*types.ServiceOffering)
+			offering.Plans = []*types.ServicePlan{plan}
+
+			obj, err = memStore.Update(ctx, offering, types.LabelChanges{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj.(*types.ServiceOffering).Plans).To(HaveLen(1))
+
+			obj, err = memStore.Get(ctx, types.ServiceOfferingType, query.ByField(query.EqualsOperator, "id", offering.ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj.(*types.ServiceOffering).Plans).To(BeEmpty())
+		})
+
+		It("returns not found if the entity does not exist", func() {
+			_, err := memStore.Update(ctx, newPlatform("cf", nil), types.LabelChanges{})
+			Expect(err).To(Equal(util.ErrNotFoundInStorage))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the referencing entities of cascading foreign keys", func() {
+			platform := createPlatform("cf", nil)
+			plan := createPlan()
+			_, err := memStore.Create(ctx, &types.Visibility{
+				Base:          types.Base{ID: "visibility-id", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+				PlatformID:    platform.ID,
+				ServicePlanID: plan.ID,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(memStore.Delete(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))).To(Succeed())
+			count, err := memStore.Count(ctx, types.VisibilityType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(0))
+		})
+
+		It("fails if the entity is referenced by a foreign key without cascade", func() {
+			platform := createPlatform("cf", nil)
+			plan := createPlan()
+			_, err := memStore.Create(ctx, &types.ServiceInstance{
+				Base:          types.Base{ID: "instance-id", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+				Name:          "instance",
+				ServicePlanID: plan.ID,
+				PlatformID:    platform.ID,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = memStore.Delete(ctx, types.ServicePlanType, query.ByField(query.EqualsOperator, "id", plan.ID))
+			Expect(err).To(BeAssignableToTypeOf(&util.ErrForeignKeyViolation{}))
+		})
+
+		It("returns not found if no entity matches", func() {
+			err := memStore.Delete(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", "missing"))
+			Expect(err).To(Equal(util.ErrNotFoundInStorage))
+		})
+	})
+
+	Describe("GetNotificationsLags", func() {
+		createNotification := func(platformID string) *types.Notification {
+			obj, err := memStore.Create(ctx, &types.Notification{
+				Base:       types.Base{ID: platformID + time.Now().String(), CreatedAt: time.Now(), UpdatedAt: time.Now()},
+				Resource:   types.PlatformType,
+				Type:       types.CREATED,
+				PlatformID: platformID,
+				Payload:    []byte("{}"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			return obj.(*types.Notification)
+		}
+
+		It("counts the notifications of each acknowledging platform after the acknowledged revision", func() {
+			createPlatform("silent", nil)
+			platform := newPlatform("cf", nil)
+			_, err := memStore.Create(ctx, platform)
+			Expect(err).ToNot(HaveOccurred())
+			other := newPlatform("k8s", nil)
+			_, err = memStore.Create(ctx, other)
+			Expect(err).ToNot(HaveOccurred())
+
+			acknowledged := createNotification(platform.ID)
+			createNotification(platform.ID)
+			createNotification("")
+			createNotification(other.ID)
+
+			platform.AcknowledgedNotificationRevision = acknowledged.Revision
+			_, err = memStore.Update(ctx, platform, nil)
+			Expect(err).ToNot(HaveOccurred())
+			other.AcknowledgedNotificationRevision = acknowledged.Revision + 3
+			_, err = memStore.Update(ctx, other, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			lags, err := memStore.GetNotificationsLags(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lags).To(Equal(map[string]int64{platform.ID: 2, other.ID: 0}))
+		})
+	})
+
+	Describe("RecordCredentialsUsage", func() {
+		getPlatform := func(id string) *types.Platform {
+			obj, err := memStore.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", id))
+			Expect(err).ToNot(HaveOccurred())
+			return obj.(*types.Platform)
+		}
+
+		It("records the usage of the current and the old credentials without updating the platform", func() {
+			platform := newPlatform("cf", nil)
+			platform.OldCredentials = &types.Credentials{
+				Basic: &types.Basic{Username: "old-user", Password: "password"},
+			}
+			_, err := memStore.Create(ctx, platform)
+			Expect(err).ToNot(HaveOccurred())
+			updatedAt := getPlatform(platform.ID).UpdatedAt
+
+			usedAt := time.Now()
+			Expect(memStore.RecordCredentialsUsage(ctx, platform.ID, "cf-user", usedAt, time.Minute)).To(Succeed())
+			Expect(memStore.RecordCredentialsUsage(ctx, platform.ID, "old-user", usedAt, time.Minute)).To(Succeed())
+
+			stored := getPlatform(platform.ID)
+			Expect(stored.CredentialsLastUsedAt).To(BeTemporally("~", usedAt, time.Millisecond))
+			Expect(stored.OldCredentialsLastUsedAt).To(BeTemporally("~", usedAt, time.Millisecond))
+			Expect(stored.UpdatedAt).To(Equal(updatedAt))
+		})
+
+		It("does not record the usage again within the interval", func() {
+			platform := createPlatform("cf", nil)
+			usedAt := time.Now()
+			Expect(memStore.RecordCredentialsUsage(ctx, platform.ID, "cf-user", usedAt, time.Minute)).To(Succeed())
+			Expect(memStore.RecordCredentialsUsage(ctx, platform.ID, "cf-user", usedAt.Add(time.Second), time.Minute)).To(Succeed())
+
+			Expect(getPlatform(platform.ID).CredentialsLastUsedAt).To(BeTemporally("~", usedAt, time.Millisecond))
+		})
+
+		It("ignores usernames which are not credentials of the platform", func() {
+			platform := createPlatform("cf", nil)
+			Expect(memStore.RecordCredentialsUsage(ctx, platform.ID, "other-user", time.Now(), time.Minute)).To(Succeed())
+			Expect(memStore.RecordCredentialsUsage(ctx, "unknown-id", "cf-user", time.Now(), time.Minute)).To(Succeed())
+
+			Expect(getPlatform(platform.ID).CredentialsLastUsedAt.IsZero()).To(BeTrue())
+		})
+	})
+
+	Describe("InTransaction", func() {
+		It("rolls back the changes if the function fails", func() {
+			err := memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+				_, err := repository.Create(ctx, newPlatform("cf", nil))
+				Expect(err).ToNot(HaveOccurred())
+				return errors.New("expected")
+			})
+			Expect(err).To(MatchError("expected"))
+
+			count, err := memStore.Count(ctx, types.PlatformType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(0))
+		})
+
+		It("does not show the changes to others before they are committed", func() {
+			err := memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+				if _, err := repository.Create(ctx, newPlatform("cf", nil)); err != nil {
+					return err
+				}
+				count, err := repository.Count(ctx, types.PlatformType)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(count).To(Equal(1))
+
+				count, err = memStore.Count(ctx, types.PlatformType)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(count).To(Equal(0))
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			count, err := memStore.Count(ctx, types.PlatformType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+
+		It("blocks updates of rows locked with GetForUpdate until the transaction completes", func() {
+			platform := createPlatform("cf", nil)
+			locked := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				done <- memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+					_, err := repository.GetForUpdate(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+					Expect(err).ToNot(HaveOccurred())
+					close(locked)
+					<-release
+					return nil
+				})
+			}()
+			<-locked
+
+			updated := make(chan error, 1)
+			go func() {
+				platform.Description = "updated"
+				_, err := memStore.Update(ctx, platform, types.LabelChanges{})
+				updated <- err
+			}()
+			Consistently(updated, 100*time.Millisecond).ShouldNot(Receive())
+
+			close(release)
+			Eventually(done).Should(Receive(BeNil()))
+			Eventually(updated).Should(Receive(BeNil()))
+		})
+
+		It("rejects changes in read-only transactions", func() {
+			platform := createPlatform("cf", nil)
+			err := memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+				_, err := repository.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = repository.Create(ctx, newPlatform("k8s", nil))
+				Expect(err).To(Equal(errReadOnlyTransaction))
+				_, err = repository.GetForUpdate(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+				Expect(err).To(Equal(errReadOnlyTransaction))
+				err = repository.Delete(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+				Expect(err).To(Equal(errReadOnlyTransaction))
+				return nil
+			}, storage.WithReadOnly())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails statements which wait for a row lock longer than the statement timeout", func() {
+			platform := createPlatform("cf", nil)
+			byID := query.ByField(query.EqualsOperator, "id", platform.ID)
+			err := memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+				_, err := repository.GetForUpdate(ctx, types.PlatformType, byID)
+				Expect(err).ToNot(HaveOccurred())
+
+				return memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+					_, err := repository.GetForUpdate(ctx, types.PlatformType, byID)
+					return err
+				}, storage.WithStatementTimeout(50*time.Millisecond))
+			})
+			Expect(err).To(Equal(errStatementTimeout))
+		})
+
+		It("retries the transaction which fails due to a deadlock if retries are requested", func() {
+			first := createPlatform("cf", nil)
+			second := createPlatform("k8s", nil)
+			lockPlatform := func(ctx context.Context, repository storage.Repository, platform *types.Platform) error {
+				_, err := repository.GetForUpdate(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+				return err
+			}
+			isWaiting := func() bool {
+				memStore.rowLocks.mutex.Lock()
+				defer memStore.rowLocks.mutex.Unlock()
+				return len(memStore.rowLocks.waiting) != 0
+			}
+
+			firstLocked := make(chan struct{})
+			secondLocked := make(chan struct{})
+			done := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				done <- memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+					Expect(lockPlatform(ctx, repository, first)).To(Succeed())
+					close(firstLocked)
+					<-secondLocked
+					return lockPlatform(ctx, repository, second)
+				})
+			}()
+			<-firstLocked
+
+			attempts := 0
+			err := memStore.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+				attempts++
+				if err := lockPlatform(ctx, repository, second); err != nil {
+					return err
+				}
+				if attempts == 1 {
+					close(secondLocked)
+					Eventually(isWaiting).Should(BeTrue())
+				}
+				return lockPlatform(ctx, repository, first)
+			}, storage.WithRetries())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(attempts).To(Equal(2))
+			Eventually(done).Should(Receive(BeNil()))
+		})
+	})
+})
@@ -0,0 +1,706 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/postgres"
+)
+
+var errTransactionFinished = errors.New("transaction has already been committed or rolled back")
+
+// errReadOnlyTransaction and errStatementTimeout have the same codes as the PostgreSQL errors
+var (
+	errReadOnlyTransaction = &pq.Error{Code: "25006", Message: "cannot execute statement in a read-only transaction"}
+	errStatementTimeout    = &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}
+)
+
+type rowKey struct {
+	objectType types.ObjectType
+	id         string
+}
+
+// transaction is a storage.Repository which keeps its changes private until it is committed. Statements read the
+// last committed rows overlaid with the changes of the transaction. The rows which are updated, deleted or selected
+// for update in an explicit transaction are locked until the transaction finishes.
+type transaction struct {
+	storage    *Storage
+	explicit   bool
+	finished   bool
+	options    *storage.TransactionOptions
+	changes    map[types.ObjectType]map[string]*row
+	created    []rowKey
+	lockedRows map[rowKey]bool
+}
+
+func (s *Storage) begin(explicit bool, options *storage.TransactionOptions) *transaction {
+	return &transaction{
+		storage:    s,
+		explicit:   explicit,
+		options:    options,
+		changes:    make(map[types.ObjectType]map[string]*row),
+		lockedRows: make(map[rowKey]bool),
+	}
+}
+
+// rows returns the rows of the type. The caller must hold the storage mutex.
+func (tx *transaction) rows(objectType types.ObjectType) []*row {
+	table := tx.storage.tables[objectType]
+	changes := tx.changes[objectType]
+	result := make([]*row, 0, len(table)+len(changes))
+	for id, r := range table {
+		if _, changed := changes[id]; !changed {
+			result = append(result, r)
+		}
+	}
+	for _, r := range changes {
+		if r != nil {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// row returns the row of the type with the id or nil if there is no such row. The caller must hold the storage mutex.
+func (tx *transaction) row(objectType types.ObjectType, id string) *row {
+	if r, changed := tx.changes[objectType][id]; changed {
+		return r
+	}
+	return tx.storage.tables[objectType][id]
+}
+
+// latest returns the row of the type with the id as last committed or changed by the transaction
+func (tx *transaction) latest(objectType types.ObjectType, id string) *row {
+	tx.storage.mutex.RLock()
+	defer tx.storage.mutex.RUnlock()
+	return tx.row(objectType, id)
+}
+
+// put changes the row of the type with the id, a nil row deletes it
+func (tx *transaction) put(objectType types.ObjectType, id string, r *row) {
+	changes, exists := tx.changes[objectType]
+	if !exists {
+		changes = make(map[string]*row)
+		tx.changes[objectType] = changes
+	}
+	changes[id] = r
+}
+
+// lock locks the row for the transaction. As the statements of the in-memory storage wait only for row locks, the
+// statement timeout of the transaction limits the waiting.
+func (tx *transaction) lock(ctx context.Context, key rowKey) error {
+	if tx.options.StatementTimeout <= 0 {
+		return tx.storage.rowLocks.lock(ctx, tx, key)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, tx.options.StatementTimeout)
+	defer cancel()
+	err := tx.storage.rowLocks.lock(timeoutCtx, tx, key)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return errStatementTimeout
+	}
+	return err
+}
+
+func (tx *transaction) prepare(objectType types.ObjectType) (*entityType, error) {
+	if tx.finished {
+		return nil, errTransactionFinished
+	}
+	return tx.storage.entityType(objectType)
+}
+
+// prepareWrite is called by the statements which modify or lock rows
+func (tx *transaction) prepareWrite(objectType types.ObjectType) (*entityType, error) {
+	if tx.options.ReadOnly {
+		return nil, errReadOnlyTransaction
+	}
+	return tx.prepare(objectType)
+}
+
+func (tx *transaction) Create(ctx context.Context, obj types.Object) (types.Object, error) {
+	et, err := tx.prepareWrite(obj.GetType())
+	if err != nil {
+		return nil, err
+	}
+	entity, err := et.fromObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLabels(obj.GetLabels()); err != nil {
+		return nil, err
+	}
+	for _, c := range et.columns {
+		if c.autoIncrement {
+			reflect.ValueOf(entity).Elem().FieldByIndex(c.index).SetInt(tx.storage.nextSequence(et.objectType, c.name))
+		}
+	}
+	normalizeTimes(entity, et.columns)
+	r := &row{entity: et.columnsOnly(entity), labels: cloneLabels(obj.GetLabels())}
+
+	tx.storage.mutex.RLock()
+	if tx.row(et.objectType, entity.GetID()) != nil {
+		err = util.ErrAlreadyExistsInStorage
+	} else {
+		err = checkConstraints(tx, et, r)
+	}
+	tx.storage.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	tx.put(et.objectType, entity.GetID(), r)
+	tx.created = append(tx.created, rowKey{objectType: et.objectType, id: entity.GetID()})
+
+	createdObj, err := r.toObject(false)
+	if err != nil {
+		return nil, err
+	}
+	createdObj.SetLabels(obj.GetLabels())
+	return createdObj, nil
+}
+
+func (tx *transaction) Get(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.Object, error) {
+	result, err := tx.List(ctx, objectType, criteria...)
+	if err != nil {
+		return nil, err
+	}
+	if result.Len() == 0 {
+		return nil, util.ErrNotFoundInStorage
+	}
+	return result.ItemAt(0), nil
+}
+
+func (tx *transaction) GetForUpdate(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.Object, error) {
+	result, err := tx.list(ctx, objectType, true, true, criteria...)
+	if err != nil {
+		return nil, err
+	}
+	if result.Len() == 0 {
+		return nil, util.ErrNotFoundInStorage
+	}
+	return result.ItemAt(0), nil
+}
+
+func (tx *transaction) List(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+	return tx.list(ctx, objectType, false, true, criteria...)
+}
+
+func (tx *transaction) ListNoLabels(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+	return tx.list(ctx, objectType, false, false, criteria...)
+}
+
+func (tx *transaction) list(ctx context.Context, objectType types.ObjectType, forUpdate, withLabels bool, criteria ...query.Criterion) (types.ObjectList, error) {
+	prepare := tx.prepare
+	if forUpdate {
+		prepare = tx.prepareWrite
+	}
+	et, err := prepare(objectType)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := newSelection(et, criteria...)
+	if err != nil {
+		return nil, err
+	}
+	if !withLabels && sel.hasLabelCriteria() {
+		return nil, errLabelQueriesNotSupported
+	}
+
+	tx.storage.mutex.RLock()
+	rows, err := sel.apply(tx.rows(objectType))
+	tx.storage.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	// as in PostgreSQL the rows are locked only in transactions and are read again once they are locked
+	if forUpdate && tx.explicit {
+		if rows, err = tx.lockRows(ctx, sel, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	result := et.newList()
+	for _, r := range rows {
+		obj, err := r.toObject(withLabels)
+		if err != nil {
+			return nil, err
+		}
+		result.Add(obj)
+	}
+	return result, nil
+}
+
+// lockRows locks the rows and returns their latest state, skipping the rows which no longer match the selection
+func (tx *transaction) lockRows(ctx context.Context, sel *selection, rows []*row) ([]*row, error) {
+	objectType := sel.entityType.objectType
+	result := make([]*row, 0, len(rows))
+	for _, r := range rows {
+		id := r.entity.GetID()
+		if err := tx.lock(ctx, rowKey{objectType: objectType, id: id}); err != nil {
+			return nil, err
+		}
+		latest := tx.latest(objectType, id)
+		if latest == nil {
+			continue
+		}
+		matches, err := sel.matches(latest)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			result = append(result, latest)
+		}
+	}
+	return result, nil
+}
+
+func (tx *transaction) Count(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (int, error) {
+	et, err := tx.prepare(objectType)
+	if err != nil {
+		return 0, err
+	}
+	sel, err := newSelection(et, criteria...)
+	if err != nil {
+		return 0, err
+	}
+
+	tx.storage.mutex.RLock()
+	defer tx.storage.mutex.RUnlock()
+	rows, err := sel.filter(tx.rows(objectType))
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func (tx *transaction) CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error) {
+	et, err := tx.prepare(objectType)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := newSelection(et, criteria...)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.storage.mutex.RLock()
+	defer tx.storage.mutex.RUnlock()
+	rows, err := sel.filter(tx.rows(objectType))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[types.LabelValueCount]int)
+	for _, r := range rows {
+		for key, values := range r.labels {
+			for _, value := range values {
+				counts[types.LabelValueCount{Key: key, Value: value}]++
+			}
+		}
+	}
+	result := make([]*types.LabelValueCount, 0, len(counts))
+	for labelValue, count := range counts {
+		labelValue := labelValue
+		labelValue.Count = count
+		result = append(result, &labelValue)
+	}
+	// as in PostgreSQL the label values are ordered by key and value
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Key != result[j].Key {
+			return result[i].Key < result[j].Key
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result, nil
+}
+
+func (tx *transaction) Search(ctx context.Context, objectType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error) {
+	if !types.IsSearchable(objectType) {
+		return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("search is not supported for %s", objectType)}
+	}
+	et, err := tx.prepare(objectType)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := newSelection(et, criteria...)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.storage.mutex.RLock()
+	defer tx.storage.mutex.RUnlock()
+	rows, err := sel.filter(tx.rows(objectType))
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(text)
+	result := make([]*types.SearchMatch, 0)
+	for _, r := range rows {
+		if rank := searchRank(et, r, terms); rank > 0 {
+			result = append(result, &types.SearchMatch{ID: r.entity.GetID(), Rank: rank})
+		}
+	}
+	sortSearchMatches(result)
+	if sel.limit >= 0 && len(result) > sel.limit {
+		result = result[:sel.limit]
+	}
+	return result, nil
+}
+
+func (tx *transaction) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+	et, err := tx.prepareWrite(objectType)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.delete(ctx, et, criteria...)
+	if err != nil {
+		return nil, err
+	}
+	result := et.newList()
+	for _, r := range rows {
+		obj, err := r.toObject(false)
+		if err != nil {
+			return nil, err
+		}
+		result.Add(obj)
+	}
+	return result, nil
+}
+
+func (tx *transaction) Delete(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) error {
+	et, err := tx.prepareWrite(objectType)
+	if err != nil {
+		return err
+	}
+	_, err = tx.delete(ctx, et, criteria...)
+	return err
+}
+
+// delete deletes the matching rows together with the rows which reference them through cascading foreign keys.
+// It fails if any of the rows is referenced through a restricting foreign key.
+func (tx *transaction) delete(ctx context.Context, et *entityType, criteria ...query.Criterion) ([]*row, error) {
+	sel, err := newSelection(et, criteria...)
+	if err != nil {
+		return nil, err
+	}
+	tx.storage.mutex.RLock()
+	rows, err := sel.filter(tx.rows(et.objectType))
+	tx.storage.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if rows, err = tx.lockRows(ctx, sel, rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, util.ErrNotFoundInStorage
+	}
+
+	deleted := make(map[rowKey]bool)
+	pending := make([]rowKey, 0, len(rows))
+	for _, r := range rows {
+		key := rowKey{objectType: et.objectType, id: r.entity.GetID()}
+		deleted[key] = true
+		pending = append(pending, key)
+	}
+	for len(pending) != 0 {
+		key := pending[0]
+		pending = pending[1:]
+		for _, fk := range referencing(key.objectType) {
+			tx.storage.mutex.RLock()
+			children := tx.referencingRows(fk, key.id)
+			tx.storage.mutex.RUnlock()
+			for _, child := range children {
+				childKey := rowKey{objectType: fk.objectType, id: child.entity.GetID()}
+				if deleted[childKey] {
+					continue
+				}
+				if !fk.cascade {
+					return nil, &util.ErrForeignKeyViolation{
+						Entity:          et.objectType.String(),
+						ReferenceEntity: fk.objectType.String(),
+					}
+				}
+				if err := tx.lock(ctx, childKey); err != nil {
+					return nil, err
+				}
+				deleted[childKey] = true
+				pending = append(pending, childKey)
+			}
+		}
+	}
+
+	for key := range deleted {
+		tx.put(key.objectType, key.id, nil)
+	}
+	return rows, nil
+}
+
+// referencingRows returns the rows which reference the id through the foreign key. The caller must hold the storage mutex.
+func (tx *transaction) referencingRows(fk foreignKey, id string) []*row {
+	et, exists := tx.storage.entityTypes[fk.objectType]
+	if !exists {
+		return nil
+	}
+	c := et.columns[fk.column]
+	result := make([]*row, 0)
+	for _, r := range tx.rows(fk.objectType) {
+		if v := c.valueOf(r.entity); !v.null && v.text == id {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (tx *transaction) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, _ ...query.Criterion) (types.Object, error) {
+	obj.SetUpdatedAt(time.Now().UTC())
+
+	et, err := tx.prepareWrite(obj.GetType())
+	if err != nil {
+		return nil, err
+	}
+	entity, err := et.fromObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	key := rowKey{objectType: et.objectType, id: entity.GetID()}
+	if err := tx.lock(ctx, key); err != nil {
+		return nil, err
+	}
+
+	tx.storage.mutex.RLock()
+	current := tx.row(key.objectType, key.id)
+	if current == nil {
+		tx.storage.mutex.RUnlock()
+		return nil, util.ErrNotFoundInStorage
+	}
+	for _, c := range et.columns {
+		if c.autoIncrement {
+			field := reflect.ValueOf(entity).Elem().FieldByIndex(c.index)
+			field.Set(reflect.ValueOf(current.entity).Elem().FieldByIndex(c.index))
+		}
+	}
+	normalizeTimes(entity, et.columns)
+	r := &row{entity: et.columnsOnly(entity), labels: applyLabelChanges(current.labels, labelChanges)}
+	err = checkConstraints(tx, et, r)
+	tx.storage.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	tx.put(key.objectType, key.id, r)
+
+	// as in PostgreSQL the updated object is converted from the entity, including the fields which are not stored
+	result, err := cloneEntity(entity).ToObject()
+	if err != nil {
+		return nil, fmt.Errorf("could not convert updated entity to object: %s", err)
+	}
+	return result, nil
+}
+
+// commit checks the constraints against the rows committed by others in the meantime and publishes the changes
+func (tx *transaction) commit(ctx context.Context) error {
+	if tx.finished {
+		return errTransactionFinished
+	}
+	defer tx.finish()
+
+	s := tx.storage
+	s.mutex.Lock()
+	if err := tx.checkCommit(); err != nil {
+		s.mutex.Unlock()
+		return err
+	}
+	for objectType, changes := range tx.changes {
+		table := s.tables[objectType]
+		for id, r := range changes {
+			if r == nil {
+				delete(table, id)
+			} else {
+				table[id] = r
+			}
+		}
+	}
+	events := tx.notificationEvents()
+	s.mutex.Unlock()
+
+	s.notify(notificationsChannel, events)
+	return nil
+}
+
+// checkCommit checks the changes against the rows committed by other transactions after the changes were made.
+// The rows which reference deleted rows through cascading foreign keys are deleted as well. The caller must hold
+// the storage mutex.
+func (tx *transaction) checkCommit() error {
+	pending := make([]rowKey, 0)
+	for _, key := range tx.created {
+		if r, created := tx.changes[key.objectType][key.id]; created && r != nil {
+			if _, committed := tx.storage.tables[key.objectType][key.id]; committed {
+				return util.ErrAlreadyExistsInStorage
+			}
+		}
+	}
+	for objectType, changes := range tx.changes {
+		et := tx.storage.entityTypes[objectType]
+		for id, r := range changes {
+			if r == nil {
+				pending = append(pending, rowKey{objectType: objectType, id: id})
+				continue
+			}
+			if err := checkConstraints(tx, et, r); err != nil {
+				return err
+			}
+		}
+	}
+	for len(pending) != 0 {
+		key := pending[0]
+		pending = pending[1:]
+		for _, fk := range referencing(key.objectType) {
+			for _, child := range tx.referencingRows(fk, key.id) {
+				if !fk.cascade {
+					return &util.ErrForeignKeyViolation{
+						Entity:          key.objectType.String(),
+						ReferenceEntity: fk.objectType.String(),
+					}
+				}
+				childKey := rowKey{objectType: fk.objectType, id: child.entity.GetID()}
+				tx.put(childKey.objectType, childKey.id, nil)
+				pending = append(pending, childKey)
+			}
+		}
+	}
+	return nil
+}
+
+// notificationEvents returns the events which the notifications trigger sends for the created notifications.
+// The caller must hold the storage mutex.
+func (tx *transaction) notificationEvents() []string {
+	events := make([]string, 0)
+	for _, key := range tx.created {
+		if key.objectType != types.NotificationType {
+			continue
+		}
+		r := tx.changes[key.objectType][key.id]
+		if r == nil {
+			continue
+		}
+		notification, ok := r.entity.(*postgres.Notification)
+		if !ok {
+			continue
+		}
+		event := &notificationEvent{
+			NotificationID: notification.ID,
+			Revision:       notification.Revision,
+		}
+		if notification.PlatformID.Valid {
+			event.PlatformID = &notification.PlatformID.String
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			panic(fmt.Sprintf("could not marshal notification event: %s", err))
+		}
+		events = append(events, string(data))
+	}
+	return events
+}
+
+func (tx *transaction) finish() {
+	if tx.finished {
+		return
+	}
+	tx.finished = true
+	tx.changes = nil
+	tx.created = nil
+	tx.storage.rowLocks.unlockAll(tx)
+}
+
+func (tx *transaction) rollback() {
+	tx.finish()
+}
+
+func validateLabels(labels types.Labels) error {
+	for key, values := range labels {
+		pairs := make(map[string]bool, len(values))
+		for _, value := range values {
+			if pairs[value] {
+				return fmt.Errorf("duplicate label with key %s and value %s", key, value)
+			}
+			pairs[value] = true
+		}
+	}
+	return nil
+}
+
+// applyLabelChanges returns the labels after the changes. As in PostgreSQL existing values are not added again
+// and removing missing keys or values has no effect.
+func applyLabelChanges(labels types.Labels, changes types.LabelChanges) types.Labels {
+	result := cloneLabels(labels)
+	if result == nil {
+		result = make(types.Labels)
+	}
+	for _, change := range changes {
+		switch change.Operation {
+		case types.AddLabelOperation, types.AddLabelValuesOperation:
+			for _, value := range change.Values {
+				if !containsValue(result[change.Key], value) {
+					result[change.Key] = append(result[change.Key], value)
+				}
+			}
+		case types.RemoveLabelOperation, types.RemoveLabelValuesOperation:
+			if len(change.Values) == 0 {
+				delete(result, change.Key)
+				continue
+			}
+			values := make([]string, 0, len(result[change.Key]))
+			for _, value := range result[change.Key] {
+				if !containsValue(change.Values, value) {
+					values = append(values, value)
+				}
+			}
+			if len(values) == 0 {
+				delete(result, change.Key)
+			} else {
+				result[change.Key] = values
+			}
+		}
+	}
+	return result
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var _ storage.Repository = &transaction{}
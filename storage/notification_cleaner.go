@@ -29,12 +29,15 @@ import (
 	"github.com/Peripli/service-manager/pkg/types"
 )
 
-// NotificationCleaner schedules a go routine which cleans old notifications
+// NotificationCleaner schedules a go routine which cleans old notifications. If the storage keeps the notifications in
+// partitions, the cleaner creates the partitions in advance and drops the old partitions instead of deleting the old
+// notifications.
 type NotificationCleaner struct {
 	started bool
 
-	Storage  Repository
-	Settings Settings
+	Storage     Repository
+	Partitioner Partitioner
+	Settings    Settings
 }
 
 // Start schedules the cleaner. It cannot be used concurrently.
@@ -51,6 +54,9 @@ func (nc *NotificationCleaner) Start(ctx context.Context, group *sync.WaitGroup)
 		}()
 		cleanInterval := nc.Settings.Notification.CleanInterval
 		log.C(ctx).Infof("Scheduling notification cleaning every %s", cleanInterval.String())
+		if nc.Partitioner != nil {
+			nc.createPartitions(ctx)
+		}
 		for {
 			select {
 			case <-ctx.Done():
@@ -64,6 +70,12 @@ func (nc *NotificationCleaner) Start(ctx context.Context, group *sync.WaitGroup)
 }
 
 func (nc *NotificationCleaner) clean(ctx context.Context) {
+	if nc.Partitioner != nil {
+		nc.createPartitions(ctx)
+		nc.dropPartitions(ctx)
+		return
+	}
+
 	cleanTimestamp := util.ToRFCNanoFormat(time.Now().Add(-nc.Settings.Notification.KeepFor))
 	log.C(ctx).Infof("Deleting notifications created before %s", cleanTimestamp)
 
@@ -79,3 +91,21 @@ func (nc *NotificationCleaner) clean(ctx context.Context) {
 
 	}
 }
+
+// createPartitions creates the partitions of the notifications which will be created until after the next cleaning
+func (nc *NotificationCleaner) createPartitions(ctx context.Context) {
+	now := time.Now()
+	until := now.Add(2 * nc.Settings.Notification.CleanInterval)
+	if err := nc.Partitioner.CreatePartitions(ctx, types.NotificationType, now, until); err != nil {
+		log.C(ctx).WithError(err).Error("could not create notification partitions")
+	}
+}
+
+// dropPartitions drops the partitions of the notifications which have been kept for long enough
+func (nc *NotificationCleaner) dropPartitions(ctx context.Context) {
+	cleanTime := time.Now().Add(-nc.Settings.Notification.KeepFor)
+	log.C(ctx).Infof("Dropping notifications created before %s", util.ToRFCNanoFormat(cleanTime))
+	if err := nc.Partitioner.DropPartitions(ctx, types.NotificationType, cleanTime); err != nil {
+		log.C(ctx).WithError(err).Error("could not drop old notification partitions")
+	}
+}
@@ -99,6 +99,31 @@ var _ = Describe("Notification cleaner", func() {
 			})
 		})
 
+		Context("When the notifications are partitioned", func() {
+			It("Should drop the old partitions instead of deleting the notifications", func() {
+				fakePartitioner := &storagefakes.FakePartitioner{}
+				fakePartitioner.DropPartitionsStub = func(ctx context.Context, objectType types.ObjectType, before time.Time) error {
+					cancel() // stop notification cleaner
+					return nil
+				}
+				nc.Partitioner = fakePartitioner
+				nc.Settings.Notification.CleanInterval = 0
+				err := nc.Start(ctx, wg)
+				Expect(err).ToNot(HaveOccurred())
+				wg.Wait()
+				Expect(fakeStorage.DeleteCallCount()).To(Equal(0))
+
+				Expect(fakePartitioner.CreatePartitionsCallCount()).To(BeNumerically(">=", 2))
+				_, objType, from, _ := fakePartitioner.CreatePartitionsArgsForCall(0)
+				Expect(objType).To(Equal(types.NotificationType))
+				Expect(from).To(BeTemporally("~", time.Now(), time.Second))
+
+				_, objType, before := fakePartitioner.DropPartitionsArgsForCall(0)
+				Expect(objType).To(Equal(types.NotificationType))
+				Expect(before).To(BeTemporally("~", time.Now().Add(-nc.Settings.Notification.KeepFor), time.Second))
+			})
+		})
+
 		checkCleanerNotStopped := func(storageError error) {
 			nc.Settings.Notification.CleanInterval = 0
 			called := false
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// LastNotificationRevision returns the revision of the last notification in the repository or InvalidRevision if
+// there are no notifications
+func LastNotificationRevision(ctx context.Context, repository Repository) (int64, error) {
+	objectList, err := repository.ListNoLabels(ctx, types.NotificationType,
+		query.OrderResultBy("revision", query.DescOrder), query.LimitResultBy(1))
+	if err != nil {
+		return types.InvalidRevision, fmt.Errorf("could not get last notification revision from storage %v", err)
+	}
+	if objectList.Len() == 0 {
+		return types.InvalidRevision, nil
+	}
+	return objectList.(*types.Notifications).Notifications[0].Revision, nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// NotificationSubscription specifies the notifications a consumer is interested in.
+// A nil subscription matches all notifications targeted at the platform of the consumer.
+type NotificationSubscription struct {
+	// ResourceTypes are the resource types of the notifications, all resource types match if empty
+	ResourceTypes []types.ObjectType
+	// LabelCriteria are the criteria the labels of the resource in the notification must match
+	LabelCriteria []query.Criterion
+}
+
+// Matches returns whether the notification matches the subscription. The label criteria are not applied to notifications
+// which do not carry the labels of their resource or which change them, so that consumers do not miss resources
+// entering or leaving the selection.
+func (s *NotificationSubscription) Matches(notification *types.Notification) bool {
+	if s == nil {
+		return true
+	}
+	if len(s.ResourceTypes) != 0 && !containsResourceType(s.ResourceTypes, notification.Resource) {
+		return false
+	}
+	if len(s.LabelCriteria) == 0 {
+		return true
+	}
+
+	payload := gjson.ParseBytes(notification.Payload)
+	if labelChanges := payload.Get("label_changes"); labelChanges.Exists() && len(labelChanges.Array()) != 0 {
+		return true
+	}
+	resource := payload.Get("new.resource")
+	if !resource.Exists() {
+		resource = payload.Get("old.resource")
+	}
+	if !resource.Exists() {
+		return true
+	}
+	labels := types.Labels{}
+	if labelsResult := resource.Get("labels"); labelsResult.Exists() {
+		if err := json.Unmarshal([]byte(labelsResult.Raw), &labels); err != nil {
+			return true
+		}
+	}
+	return query.MatchesLabels(labels, s.LabelCriteria...)
+}
+
+func containsResourceType(resourceTypes []types.ObjectType, resourceType types.ObjectType) bool {
+	for _, t := range resourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
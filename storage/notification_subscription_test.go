@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NotificationSubscription", func() {
+	var subscription *storage.NotificationSubscription
+
+	newNotification := func(resource types.ObjectType, payload string) *types.Notification {
+		return &types.Notification{
+			Resource: resource,
+			Type:     types.CREATED,
+			Payload:  []byte(payload),
+		}
+	}
+
+	BeforeEach(func() {
+		subscription = &storage.NotificationSubscription{
+			ResourceTypes: []types.ObjectType{types.VisibilityType},
+			LabelCriteria: []query.Criterion{query.ByLabel(query.EqualsOperator, "organization_guid", "org1")},
+		}
+	})
+
+	It("matches all notifications when nil", func() {
+		subscription = nil
+		Expect(subscription.Matches(newNotification(types.ServiceBrokerType, `{}`))).To(BeTrue())
+	})
+
+	It("does not match other resource types", func() {
+		Expect(subscription.Matches(newNotification(types.ServiceBrokerType, `{"new":{"resource":{"labels":{"organization_guid":["org1"]}}}}`))).To(BeFalse())
+	})
+
+	It("matches the labels of the new resource", func() {
+		Expect(subscription.Matches(newNotification(types.VisibilityType, `{"new":{"resource":{"labels":{"organization_guid":["org1"]}}}}`))).To(BeTrue())
+		Expect(subscription.Matches(newNotification(types.VisibilityType, `{"new":{"resource":{"labels":{"organization_guid":["org2"]}}}}`))).To(BeFalse())
+	})
+
+	It("matches the labels of the old resource of deleted resources", func() {
+		Expect(subscription.Matches(newNotification(types.VisibilityType, `{"old":{"resource":{"labels":{"organization_guid":["org1"]}}}}`))).To(BeTrue())
+	})
+
+	It("does not match resources without labels", func() {
+		Expect(subscription.Matches(newNotification(types.VisibilityType, `{"new":{"resource":{"id":"1"}}}`))).To(BeFalse())
+	})
+
+	It("matches notifications which change labels", func() {
+		notification := newNotification(types.VisibilityType, `{"new":{"resource":{"id":"1"}},"label_changes":[{"op":"remove","key":"organization_guid","values":["org1"]}]}`)
+		Expect(subscription.Matches(notification)).To(BeTrue())
+	})
+})
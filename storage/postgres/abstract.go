@@ -119,6 +119,12 @@ func isAutoIncrementable(tagValue string) bool {
 	return strings.Contains(tagValue, "auto_increment")
 }
 
+// isUpdatedSeparately states that the column is not updated together with the other columns of the entity.
+// The labels are updated by applying the label changes to them.
+func isUpdatedSeparately(tagValue string) bool {
+	return isAutoIncrementable(tagValue) || tagValue == LabelsColumn
+}
+
 type tagType struct {
 	Tag  string
 	Type reflect.Type
@@ -167,7 +173,7 @@ func getTags(fields []*structs.Field, set *[]tagType, predicate func(string) boo
 }
 
 func updateQuery(tableName string, structure interface{}) string {
-	dbTags := getDBTags(structure, isAutoIncrementable)
+	dbTags := getDBTags(structure, isUpdatedSeparately)
 	set := make([]string, 0, len(dbTags))
 	for _, dbTag := range dbTags {
 		set = append(set, fmt.Sprintf("%s = :%s", dbTag.Tag, dbTag.Tag))
@@ -17,59 +17,62 @@
 package postgres
 
 import (
-	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/types"
-	"github.com/Peripli/service-manager/storage"
-	"github.com/gofrs/uuid"
-	"github.com/lib/pq"
+	sqlxtypes "github.com/jmoiron/sqlx/types"
 )
 
+// LabelsColumn is the name of the JSONB column in which the labels of the entities are stored
+const LabelsColumn = "labels"
+
 type BaseEntity struct {
-	ID             string    `db:"id"`
-	CreatedAt      time.Time `db:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"`
-	PagingSequence int64     `db:"paging_sequence,auto_increment"`
-	Ready          bool      `db:"ready"`
+	ID             string             `db:"id"`
+	CreatedAt      time.Time          `db:"created_at"`
+	UpdatedAt      time.Time          `db:"updated_at"`
+	PagingSequence int64              `db:"paging_sequence,auto_increment"`
+	Ready          bool               `db:"ready"`
+	Labels         sqlxtypes.JSONText `db:"labels"`
 }
 
 func (e *BaseEntity) GetID() string {
 	return e.ID
 }
 
-func (e *BaseEntity) BuildLabels(labels types.Labels, newLabel func(id, key, value string) storage.Label) ([]storage.Label, error) {
-	var result []storage.Label
-	for key, values := range labels {
-		for _, labelValue := range values {
-			UUID, err := uuid.NewV4()
-			if err != nil {
-				return nil, fmt.Errorf("could not generate GUID for broker label: %s", err)
-			}
-			result = append(result, newLabel(UUID.String(), key, labelValue))
-		}
+// GetLabels decodes the labels of the entity. Entities which are read without their labels have no labels.
+func (e *BaseEntity) GetLabels() (types.Labels, error) {
+	if len(e.Labels) == 0 {
+		return nil, nil
 	}
-
-	return result, nil
-}
-
-type BaseLabelEntity struct {
-	ID        sql.NullString `db:"id"`
-	Key       sql.NullString `db:"key"`
-	Val       sql.NullString `db:"val"`
-	CreatedAt pq.NullTime    `db:"created_at"`
-	UpdatedAt pq.NullTime    `db:"updated_at"`
-}
-
-func (el BaseLabelEntity) GetKey() string {
-	return el.Key.String
+	labels := types.Labels{}
+	if err := json.Unmarshal(e.Labels, &labels); err != nil {
+		return nil, fmt.Errorf("could not decode labels of entity with id %s: %s", e.ID, err)
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return labels, nil
 }
 
-func (el BaseLabelEntity) GetValue() string {
-	return el.Val.String
+// SetLabels encodes the labels of the entity
+func (e *BaseEntity) SetLabels(labels types.Labels) error {
+	labelsJSON, err := marshalLabels(labels)
+	if err != nil {
+		return err
+	}
+	e.Labels = labelsJSON
+	return nil
 }
 
-func (el BaseLabelEntity) LabelsPrimaryColumn() string {
-	return "id"
+func marshalLabels(labels types.Labels) (sqlxtypes.JSONText, error) {
+	if labels == nil {
+		labels = types.Labels{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode labels: %s", err)
+	}
+	return labelsJSON, nil
 }
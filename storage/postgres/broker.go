@@ -38,6 +38,8 @@ type Broker struct {
 	Integrity            []byte             `db:"integrity"`
 	TlsClientKey         string             `db:"tls_client_key"`
 	TlsClientCertificate string             `db:"tls_client_certificate"`
+	TlsCACertificate     string             `db:"tls_ca_certificate"`
+	TlsServerName        string             `db:"tls_server_name"`
 	Catalog              sqlxtypes.JSONText `db:"catalog"`
 
 	Services []*ServiceOffering `db:"-"`
@@ -54,8 +56,13 @@ func (e *Broker) ToObject() (types.Object, error) {
 	}
 
 	var tls *types.TLS
-	if e.TlsClientCertificate != "" || e.TlsClientKey != "" {
-		tls = &types.TLS{Certificate: e.TlsClientCertificate, Key: e.TlsClientKey}
+	if e.TlsClientCertificate != "" || e.TlsClientKey != "" || e.TlsCACertificate != "" || e.TlsServerName != "" {
+		tls = &types.TLS{
+			Certificate:   e.TlsClientCertificate,
+			Key:           e.TlsClientKey,
+			CACertificate: e.TlsCACertificate,
+			ServerName:    e.TlsServerName,
+		}
 	}
 
 	var basic *types.Basic
@@ -129,6 +136,8 @@ func (*Broker) FromObject(object types.Object) (storage.Entity, error) {
 		if broker.Credentials.TLS != nil {
 			b.TlsClientCertificate = broker.Credentials.TLS.Certificate
 			b.TlsClientKey = broker.Credentials.TLS.Key
+			b.TlsCACertificate = broker.Credentials.TLS.CACertificate
+			b.TlsServerName = broker.Credentials.TLS.ServerName
 		}
 	}
 	return b, nil
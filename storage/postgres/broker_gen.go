@@ -4,53 +4,22 @@ package postgres
 
 import (
 	"github.com/Peripli/service-manager/pkg/types"
-	"github.com/Peripli/service-manager/storage"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
-
-	"database/sql"
-	"time"
 )
 
-var _ PostgresEntity = &Broker{}
+var _ LabeledEntity = &Broker{}
 
 const BrokerTable = "brokers"
 
-func (*Broker) LabelEntity() PostgresLabel {
-	return &BrokerLabel{}
-}
-
 func (*Broker) TableName() string {
 	return BrokerTable
 }
 
-func (e *Broker) NewLabel(id, key, value string) storage.Label {
-	now := pq.NullTime{
-		Time:  time.Now(),
-		Valid: true,
-	}
-	return &BrokerLabel{
-		BaseLabelEntity: BaseLabelEntity{
-			ID:        sql.NullString{String: id, Valid: id != ""},
-			Key:       sql.NullString{String: key, Valid: key != ""},
-			Val:       sql.NullString{String: value, Valid: value != ""},
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		BrokerID: sql.NullString{String: e.ID, Valid: e.ID != ""},
-	}
-}
-
 func (e *Broker) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
-	rowCreator := func() EntityLabelRow {
-		return &struct {
-			*Broker
-			BrokerLabel `db:"broker_labels"`
-		}{}
-	}
-	result := &types.ServiceBrokers{
-		ServiceBrokers: make([]*types.ServiceBroker, 0),
+	rowCreator := func() PostgresEntity {
+		return &Broker{}
 	}
+	result := e.NewList()
 	err := rowsToList(rows, rowCreator, result)
 	if err != nil {
 		return nil, err
@@ -58,15 +27,8 @@ func (e *Broker) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
 	return result, nil
 }
 
-type BrokerLabel struct {
-	BaseLabelEntity
-	BrokerID sql.NullString `db:"broker_id"`
-}
-
-func (el BrokerLabel) LabelsTableName() string {
-	return "broker_labels"
-}
-
-func (el BrokerLabel) ReferenceColumn() string {
-	return "broker_id"
+func (*Broker) NewList() types.ObjectList {
+	return &types.ServiceBrokers{
+		ServiceBrokers: make([]*types.ServiceBroker, 0),
+	}
 }
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/jmoiron/sqlx"
+)
+
+// ConfigurationChangesTable is the table holding the changes of the configuration made at runtime
+const ConfigurationChangesTable = "configuration_changes"
+
+// configurationChangesChannel is the channel on which the names of the changed configuration sections are notified
+const configurationChangesChannel = "configuration_changes"
+
+// configurationLockIndex is the index of the advisory lock which serializes the updates of the configuration changes
+const configurationLockIndex = 113
+
+// GetConfigurationChanges returns the changes of the configuration sections made at runtime
+func (ps *Storage) GetConfigurationChanges(ctx context.Context) (map[string]json.RawMessage, error) {
+	ps.checkOpen()
+
+	return getConfigurationChanges(ctx, ps.db)
+}
+
+// UpdateConfigurationChanges stores the changes returned by update for the stored changes. The update is serialized
+// with the updates of all Service Manager instances by an advisory lock, as the sections which have not been changed
+// yet have no rows to lock. The instances listening for configuration changes are notified once the changes are committed.
+func (ps *Storage) UpdateConfigurationChanges(ctx context.Context, update func(changes map[string]json.RawMessage) (map[string]json.RawMessage, error)) error {
+	ps.checkOpen()
+
+	return ps.inLockedTransaction(ctx, configurationLockIndex, func(tx *sqlx.Tx) error {
+		changes, err := getConfigurationChanges(ctx, tx)
+		if err != nil {
+			return err
+		}
+		updatedChanges, err := update(changes)
+		if err != nil {
+			return err
+		}
+		for section, sectionChanges := range updatedChanges {
+			if _, err := tx.ExecContext(ctx, "WITH changed AS (INSERT INTO "+ConfigurationChangesTable+" (section, changes, updated_at) VALUES ($1, $2, CURRENT_TIMESTAMP) "+
+				"ON CONFLICT (section) DO UPDATE SET changes = EXCLUDED.changes, updated_at = EXCLUDED.updated_at RETURNING section) "+
+				"SELECT pg_notify($3, section) FROM changed", section, string(sectionChanges), configurationChangesChannel); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func getConfigurationChanges(ctx context.Context, db sqlx.QueryerContext) (map[string]json.RawMessage, error) {
+	var rows []struct {
+		Section string `db:"section"`
+		Changes string `db:"changes"`
+	}
+	if err := sqlx.SelectContext(ctx, db, &rows, "SELECT section, changes FROM "+ConfigurationChangesTable); err != nil {
+		return nil, err
+	}
+	changes := make(map[string]json.RawMessage, len(rows))
+	for _, row := range rows {
+		changes[row.Section] = json.RawMessage(row.Changes)
+	}
+	return changes, nil
+}
+
+// ListenConfigurationChanges listens for the configuration changes made by any Service Manager instance
+func (ps *Storage) ListenConfigurationChanges(ctx context.Context, wg *sync.WaitGroup, onChange func(section string)) error {
+	ps.checkOpen()
+	return ListenConfigurationChangesWithConnection(ctx, wg, ps.connectionCreator.NewConnection, onChange)
+}
+
+// ListenConfigurationChangesWithConnection listens for the configuration changes through a connection created by the
+// connection creator. The connection is closed when the context is done.
+func ListenConfigurationChangesWithConnection(ctx context.Context, wg *sync.WaitGroup, connectionCreator NotificationConnectionCreatorFunc, onChange func(section string)) error {
+	connection := connectionCreator(func(isRunning bool, err error) {
+		if err != nil {
+			log.C(ctx).WithError(err).Warnf("Connection listening for configuration changes is running: %t", isRunning)
+		}
+	})
+	if err := connection.Listen(configurationChangesChannel); err != nil {
+		if closeErr := connection.Close(); closeErr != nil {
+			log.C(ctx).WithError(closeErr).Error("Could not close connection listening for configuration changes")
+		}
+		return err
+	}
+
+	util.StartInWaitGroup(func() {
+		defer func() {
+			if err := connection.Close(); err != nil {
+				log.C(ctx).WithError(err).Error("Could not close connection listening for configuration changes")
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notification, ok := <-connection.NotificationChannel():
+				if !ok {
+					return
+				}
+				if notification == nil {
+					// the connection was re-established, the notifications sent meanwhile are lost
+					onChange("")
+					continue
+				}
+				onChange(notification.Extra)
+			}
+		}
+	}, wg)
+	return nil
+}
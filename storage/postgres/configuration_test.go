@@ -0,0 +1,148 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sync"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/Peripli/service-manager/storage"
+	notificationConnection "github.com/Peripli/service-manager/storage/postgres/notification_connection"
+	"github.com/Peripli/service-manager/storage/postgres/notification_connection/notification_connectionfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Configuration changes", func() {
+	var s *Storage
+	var mock sqlmock.Sqlmock
+
+	BeforeEach(func() {
+		var mockdb *sql.DB
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20200624100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		encryptionKey := make([]byte, 32)
+		_, err = rand.Read(encryptionKey)
+		Expect(err).ToNot(HaveOccurred())
+		settings := storage.DefaultSettings()
+		settings.EncryptionKey = string(encryptionKey)
+		settings.URI = "sqlmock://sqlmock"
+		Expect(s.Open(settings)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		s.Close()
+	})
+
+	Describe("GetConfigurationChanges", func() {
+		It("returns the changes by section", func() {
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT section, changes FROM configuration_changes")).
+				WillReturnRows(sqlmock.NewRows([]string{"section", "changes"}).
+					AddRow("api", `{"max_page_size": 100}`).
+					AddRow("websocket", `{"ping_timeout": "1m"}`))
+
+			changes, err := s.GetConfigurationChanges(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(HaveLen(2))
+			Expect(changes["api"]).To(MatchJSON(`{"max_page_size": 100}`))
+			Expect(changes["websocket"]).To(MatchJSON(`{"ping_timeout": "1m"}`))
+		})
+	})
+
+	Describe("UpdateConfigurationChanges", func() {
+		expectLockedSelect := func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WithArgs(configurationLockIndex).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT section, changes FROM configuration_changes")).
+				WillReturnRows(sqlmock.NewRows([]string{"section", "changes"}).AddRow("api", `{"max_page_size": 100}`))
+		}
+
+		It("stores the updated changes and notifies the listening instances in the transaction holding the lock", func() {
+			expectLockedSelect()
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_notify($3, section) FROM changed")).
+				WithArgs("api", `{"max_page_size":100,"default_page_size":10}`, configurationChangesChannel).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			Expect(s.UpdateConfigurationChanges(context.Background(), func(changes map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+				Expect(changes["api"]).To(MatchJSON(`{"max_page_size": 100}`))
+				return map[string]json.RawMessage{"api": json.RawMessage(`{"max_page_size":100,"default_page_size":10}`)}, nil
+			})).To(Succeed())
+		})
+
+		It("rolls back if the update fails", func() {
+			expectLockedSelect()
+			mock.ExpectRollback()
+
+			updateErr := errors.New("invalid changes")
+			Expect(s.UpdateConfigurationChanges(context.Background(), func(changes map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+				return nil, updateErr
+			})).To(Equal(updateErr))
+		})
+	})
+})
+
+var _ = Describe("ListenConfigurationChangesWithConnection", func() {
+	It("notifies the changed sections until the context is done", func() {
+		notifications := make(chan *pq.Notification)
+		connection := &notification_connectionfakes.FakeNotificationConnection{}
+		connection.NotificationChannelReturns(notifications)
+		ctx, cancel := context.WithCancel(context.Background())
+		wg := &sync.WaitGroup{}
+
+		changes := make(chan string, 2)
+		err := ListenConfigurationChangesWithConnection(ctx, wg, func(eventCallback func(isRunning bool, err error)) notificationConnection.NotificationConnection {
+			return connection
+		}, func(section string) {
+			changes <- section
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connection.ListenArgsForCall(0)).To(Equal(configurationChangesChannel))
+
+		notifications <- &pq.Notification{Channel: configurationChangesChannel, Extra: "api"}
+		Eventually(changes).Should(Receive(Equal("api")))
+		notifications <- nil
+		Eventually(changes).Should(Receive(Equal("")))
+
+		cancel()
+		wg.Wait()
+		Expect(connection.CloseCallCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// recordCredentialsUsageQuery sets the last usage of the current or the old credentials with the given username
+// without changing updated_at, unless it has been recorded after the given time
+var recordCredentialsUsageQuery = fmt.Sprintf(`
+UPDATE %s SET
+  credentials_last_used_at = CASE WHEN username = $3 THEN $1 ELSE credentials_last_used_at END,
+  old_credentials_last_used_at = CASE WHEN username <> $3 THEN $1 ELSE old_credentials_last_used_at END
+WHERE id = $2
+  AND ((username = $3 AND credentials_last_used_at < $4) OR (old_username = $3 AND old_credentials_last_used_at < $4))`, PlatformTable)
+
+// RecordCredentialsUsage records the time in which the current or the old credentials of the platform were last used
+func (ps *Storage) RecordCredentialsUsage(ctx context.Context, platformID, username string, usedAt time.Time, interval time.Duration) error {
+	ps.checkOpen()
+
+	if _, err := ps.db.ExecContext(ctx, recordCredentialsUsageQuery, usedAt, platformID, username, usedAt.Add(-interval)); err != nil {
+		return fmt.Errorf("could not record usage of the credentials of platform %s: %v", platformID, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Credentials usage", func() {
+	var s *Storage
+	var mock sqlmock.Sqlmock
+
+	BeforeEach(func() {
+		var mockdb *sql.DB
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20200624100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		encryptionKey := make([]byte, 32)
+		_, err = rand.Read(encryptionKey)
+		Expect(err).ToNot(HaveOccurred())
+		settings := storage.DefaultSettings()
+		settings.EncryptionKey = string(encryptionKey)
+		settings.URI = "sqlmock://sqlmock"
+		Expect(s.Open(settings)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		s.Close()
+	})
+
+	Describe("RecordCredentialsUsage", func() {
+		It("sets only the last usage of the credentials unless it has been recorded within the interval", func() {
+			usedAt := time.Now()
+			mock.ExpectExec(regexp.QuoteMeta(recordCredentialsUsageQuery)).
+				WithArgs(usedAt, "platform-id", "user", usedAt.Add(-time.Minute)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			Expect(s.RecordCredentialsUsage(context.Background(), "platform-id", "user", usedAt, time.Minute)).To(Succeed())
+			Expect(recordCredentialsUsageQuery).ToNot(ContainSubstring("updated_at"))
+		})
+
+		It("returns the error of the update", func() {
+			mock.ExpectExec(regexp.QuoteMeta(recordCredentialsUsageQuery)).WillReturnError(errors.New("error"))
+
+			Expect(s.RecordCredentialsUsage(context.Background(), "platform-id", "user", time.Now(), time.Minute)).To(HaveOccurred())
+		})
+	})
+})
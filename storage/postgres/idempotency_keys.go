@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// IdempotencyKeysTable is the table holding the responses of the requests sent with idempotency keys
+const IdempotencyKeysTable = "idempotency_keys"
+
+// idempotencyKeyRow is a row of the idempotency keys table
+type idempotencyKeyRow struct {
+	UserName    string    `db:"user_name"`
+	Key         string    `db:"idempotency_key"`
+	RequestHash string    `db:"request_hash"`
+	Completed   bool      `db:"completed"`
+	StatusCode  int       `db:"status_code"`
+	Headers     []byte    `db:"headers"`
+	Body        []byte    `db:"body"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
+
+// ReserveIdempotencyKey stores the idempotency key for the request unless the user has already used the key and it
+// has not expired yet. It returns nil if the key has been reserved and the already stored key otherwise.
+func (ps *Storage) ReserveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) (*types.IdempotencyKey, error) {
+	ps.checkOpen()
+
+	// the key may be released by the request holding it between the insert and the select, so the insert is retried once
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := ps.db.ExecContext(ctx, "INSERT INTO "+IdempotencyKeysTable+" (user_name, idempotency_key, request_hash, expires_at) "+
+			"VALUES ($1, $2, $3, $4) ON CONFLICT (user_name, idempotency_key) DO UPDATE SET request_hash = EXCLUDED.request_hash, "+
+			"completed = '0', status_code = 0, headers = '{}', body = NULL, expires_at = EXCLUDED.expires_at "+
+			"WHERE "+IdempotencyKeysTable+".expires_at < CURRENT_TIMESTAMP",
+			key.UserName, key.Key, key.RequestHash, key.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if rowsAffected != 0 {
+			return nil, nil
+		}
+
+		row := &idempotencyKeyRow{}
+		if err := ps.db.GetContext(ctx, row, "SELECT user_name, idempotency_key, request_hash, completed, status_code, headers, body, expires_at "+
+			"FROM "+IdempotencyKeysTable+" WHERE user_name = $1 AND idempotency_key = $2", key.UserName, key.Key); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, err
+		}
+		return row.toIdempotencyKey()
+	}
+	return nil, fmt.Errorf("could not reserve idempotency key %s", key.Key)
+}
+
+// CompleteIdempotencyKey stores the response of the request for which the idempotency key has been reserved and keeps
+// the key until the given expiration time
+func (ps *Storage) CompleteIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) error {
+	ps.checkOpen()
+
+	headers, err := json.Marshal(key.Header)
+	if err != nil {
+		return err
+	}
+	_, err = ps.db.ExecContext(ctx, "UPDATE "+IdempotencyKeysTable+" SET completed = '1', status_code = $1, headers = $2, body = $3, expires_at = $4 "+
+		"WHERE user_name = $5 AND idempotency_key = $6 AND NOT completed", key.StatusCode, string(headers), key.Body, key.ExpiresAt, key.UserName, key.Key)
+	return err
+}
+
+// ReleaseIdempotencyKey deletes the idempotency key of the user, so that the request can be retried with it
+func (ps *Storage) ReleaseIdempotencyKey(ctx context.Context, userName, key string) error {
+	ps.checkOpen()
+
+	_, err := ps.db.ExecContext(ctx, "DELETE FROM "+IdempotencyKeysTable+" WHERE user_name = $1 AND idempotency_key = $2", userName, key)
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys deletes the idempotency keys which expired before the given time
+func (ps *Storage) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error {
+	ps.checkOpen()
+
+	_, err := ps.db.ExecContext(ctx, "DELETE FROM "+IdempotencyKeysTable+" WHERE expires_at < $1", before)
+	return err
+}
+
+func (row *idempotencyKeyRow) toIdempotencyKey() (*types.IdempotencyKey, error) {
+	header := http.Header{}
+	if err := json.Unmarshal(row.Headers, &header); err != nil {
+		return nil, fmt.Errorf("could not unmarshal headers of idempotency key %s: %s", row.Key, err)
+	}
+	return &types.IdempotencyKey{
+		UserName:    row.UserName,
+		Key:         row.Key,
+		RequestHash: row.RequestHash,
+		ExpiresAt:   row.ExpiresAt,
+		Completed:   row.Completed,
+		StatusCode:  row.StatusCode,
+		Header:      header,
+		Body:        row.Body,
+	}, nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotency keys", func() {
+	var s *Storage
+	var mock sqlmock.Sqlmock
+	var key *types.IdempotencyKey
+
+	BeforeEach(func() {
+		var mockdb *sql.DB
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20200624100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		encryptionKey := make([]byte, 32)
+		_, err = rand.Read(encryptionKey)
+		Expect(err).ToNot(HaveOccurred())
+		settings := storage.DefaultSettings()
+		settings.EncryptionKey = string(encryptionKey)
+		settings.URI = "sqlmock://sqlmock"
+		Expect(s.Open(settings)).To(Succeed())
+
+		key = &types.IdempotencyKey{
+			UserName:    "user",
+			Key:         "key",
+			RequestHash: "hash",
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		s.Close()
+	})
+
+	Describe("ReserveIdempotencyKey", func() {
+		It("reserves the key which has not been used", func() {
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO idempotency_keys")).
+				WithArgs("user", "key", "hash", key.ExpiresAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			storedKey, err := s.ReserveIdempotencyKey(context.Background(), key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedKey).To(BeNil())
+		})
+
+		It("returns the key which has already been used", func() {
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO idempotency_keys")).
+				WithArgs("user", "key", "hash", key.ExpiresAt).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(regexp.QuoteMeta("FROM idempotency_keys WHERE user_name = $1 AND idempotency_key = $2")).
+				WithArgs("user", "key").
+				WillReturnRows(sqlmock.NewRows([]string{"user_name", "idempotency_key", "request_hash", "completed", "status_code", "headers", "body", "expires_at"}).
+					AddRow("user", "key", "hash", true, http.StatusCreated, []byte(`{"Location": ["/v1/platforms/1"]}`), []byte(`{}`), key.ExpiresAt))
+
+			storedKey, err := s.ReserveIdempotencyKey(context.Background(), key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedKey.Completed).To(BeTrue())
+			Expect(storedKey.StatusCode).To(Equal(http.StatusCreated))
+			Expect(storedKey.Header.Get("Location")).To(Equal("/v1/platforms/1"))
+			Expect(string(storedKey.Body)).To(Equal(`{}`))
+		})
+	})
+
+	Describe("CompleteIdempotencyKey", func() {
+		It("stores the response", func() {
+			key.StatusCode = http.StatusCreated
+			key.Header = http.Header{"Location": []string{"/v1/platforms/1"}}
+			key.Body = []byte(`{}`)
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE idempotency_keys SET completed = '1'")).
+				WithArgs(http.StatusCreated, `{"Location":["/v1/platforms/1"]}`, []byte(`{}`), key.ExpiresAt, "user", "key").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			Expect(s.CompleteIdempotencyKey(context.Background(), key)).To(Succeed())
+		})
+	})
+})
@@ -22,73 +22,76 @@ import (
 	"github.com/Peripli/service-manager/storage"
 
 	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/pkg/util/slice"
 	"github.com/jmoiron/sqlx"
 )
 
+// maxLabelLength is the maximum length of the keys and values of the labels
+const maxLabelLength = 255
+
 type PostgresEntity interface {
 	storage.Entity
 	TableName() string
 	RowsToList(rows *sqlx.Rows) (types.ObjectList, error)
-	LabelEntity() PostgresLabel
 }
 
-type PostgresLabel interface {
-	storage.Label
-	LabelsTableName() string
-	LabelsPrimaryColumn() string
-	ReferenceColumn() string
+// LabeledEntity is a postgres entity which stores its labels in the labels column of its table
+type LabeledEntity interface {
+	PostgresEntity
+	GetLabels() (types.Labels, error)
+	SetLabels(labels types.Labels) error
 }
 
-type EntityLabelRowCreator func() EntityLabelRow
+type EntityRowCreator func() PostgresEntity
 
-type EntityLabelRow interface {
-	PostgresEntity
-	PostgresLabel
+func validateLabels(labels types.Labels) error {
+	for key, values := range labels {
+		if err := validateLabel("key", key); err != nil {
+			return err
+		}
+		pairs := make([]string, 0, len(values))
+		for _, value := range values {
+			if err := validateLabel("value", value); err != nil {
+				return err
+			}
+			if slice.StringsAnyEquals(pairs, value) {
+				return &util.ErrBadRequestStorage{Cause: fmt.Errorf("duplicate label with key %s and value %s", key, value)}
+			}
+			pairs = append(pairs, value)
+		}
+	}
+	return nil
 }
 
-func validateLabels(entities []storage.Label) error {
-	pairs := make(map[string][]string)
-	for _, bl := range entities {
-		newKey := bl.GetKey()
-		newValue := bl.GetValue()
-		val, exists := pairs[newKey]
-		if exists && slice.StringsAnyEquals(val, newValue) {
-			return fmt.Errorf("duplicate label with key %s and value %s", newKey, newValue)
-		}
-		pairs[newKey] = append(pairs[newKey], newValue)
+func validateLabel(part, value string) error {
+	if len(value) == 0 {
+		return &util.ErrBadRequestStorage{Cause: fmt.Errorf("label %s cannot be empty", part)}
+	}
+	if len(value) > maxLabelLength {
+		return &util.ErrBadRequestStorage{Cause: fmt.Errorf("label %s %s is longer than %d characters", part, value, maxLabelLength)}
 	}
 	return nil
 }
 
-func rowsToList(rows *sqlx.Rows, rowCreator EntityLabelRowCreator, result types.ObjectList) error {
-	entities := make(map[string]types.Object)
-	labels := make(map[string]map[string][]string)
+func rowsToList(rows *sqlx.Rows, rowCreator EntityRowCreator, result types.ObjectList) error {
 	for rows.Next() {
 		row := rowCreator()
 		if err := rows.StructScan(row); err != nil {
 			return err
 		}
-		entity, ok := entities[row.GetID()]
-		if !ok {
-			var err error
-			entity, err = row.ToObject()
-			if err != nil {
-				return fmt.Errorf("error converting pg rows to list: %s", err)
-			}
-			entities[row.GetID()] = entity
-			result.Add(entity)
+		entity, err := row.ToObject()
+		if err != nil {
+			return fmt.Errorf("error converting pg rows to list: %s", err)
 		}
-		if row.GetKey() != "" {
-			if labels[entity.GetID()] == nil {
-				labels[entity.GetID()] = make(map[string][]string)
+		if labeledRow, ok := row.(LabeledEntity); ok {
+			labels, err := labeledRow.GetLabels()
+			if err != nil {
+				return err
 			}
-			labels[entity.GetID()][row.GetKey()] = append(labels[entity.GetID()][row.GetKey()], row.GetValue())
+			entity.SetLabels(labels)
 		}
-	}
-	for i := 0; i < result.Len(); i++ {
-		b := result.ItemAt(i)
-		b.SetLabels(labels[b.GetID()])
+		result.Add(entity)
 	}
 	return nil
 }
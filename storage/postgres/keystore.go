@@ -56,14 +56,6 @@ func (s *Safe) FromObject(object types.Object) (storage.Entity, error) {
 	return nil, nil
 }
 
-func (s *Safe) BuildLabels(labels types.Labels, newLabel func(id, key, value string) storage.Label) ([]storage.Label, error) {
-	return nil, nil
-}
-
-func (s *Safe) NewLabel(id, key, value string) storage.Label {
-	return nil
-}
-
 func (s *Safe) TableName() string {
 	return SafeTable
 }
@@ -72,10 +64,6 @@ func (s *Safe) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
 	return nil, nil
 }
 
-func (s *Safe) LabelEntity() PostgresLabel {
-	return nil
-}
-
 // GetEncryptionKey returns the encryption key used to encrypt the credentials for brokers
 func (s *Storage) GetEncryptionKey(ctx context.Context, transformationFunc func(context.Context, []byte, []byte) ([]byte, error)) ([]byte, error) {
 	s.checkOpen()
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+	sqlxtypes "github.com/jmoiron/sqlx/types"
+)
+
+// LabelDefinition entity
+//go:generate smgen storage LabelDefinition github.com/Peripli/service-manager/pkg/types
+type LabelDefinition struct {
+	BaseEntity
+	Key           string             `db:"key"`
+	Description   sql.NullString     `db:"description"`
+	ResourceTypes sqlxtypes.JSONText `db:"resource_types"`
+	ValueType     string             `db:"value_type"`
+	AllowedValues sqlxtypes.JSONText `db:"allowed_values"`
+	Pattern       sql.NullString     `db:"pattern"`
+	Cardinality   sql.NullString     `db:"cardinality"`
+	Required      bool               `db:"required"`
+}
+
+func (ld *LabelDefinition) ToObject() (types.Object, error) {
+	resourceTypes := make([]types.ObjectType, 0)
+	if ld.ResourceTypes.String() != "" {
+		if err := util.BytesToObject(getJSONRawMessage(ld.ResourceTypes), &resourceTypes); err != nil {
+			return nil, err
+		}
+	}
+	allowedValues := make([]string, 0)
+	if ld.AllowedValues.String() != "" {
+		if err := util.BytesToObject(getJSONRawMessage(ld.AllowedValues), &allowedValues); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.LabelDefinition{
+		Base: types.Base{
+			ID:             ld.ID,
+			CreatedAt:      ld.CreatedAt,
+			UpdatedAt:      ld.UpdatedAt,
+			Labels:         make(map[string][]string),
+			PagingSequence: ld.PagingSequence,
+			Ready:          ld.Ready,
+		},
+		Key:           ld.Key,
+		Description:   ld.Description.String,
+		ResourceTypes: resourceTypes,
+		ValueType:     types.LabelValueType(ld.ValueType),
+		AllowedValues: allowedValues,
+		Pattern:       ld.Pattern.String,
+		Cardinality:   ld.Cardinality.String,
+		Required:      ld.Required,
+	}, nil
+}
+
+func (*LabelDefinition) FromObject(object types.Object) (storage.Entity, error) {
+	definition, ok := object.(*types.LabelDefinition)
+	if !ok {
+		return nil, fmt.Errorf("object is not of type LabelDefinition")
+	}
+	if definition.ResourceTypes == nil {
+		definition.ResourceTypes = make([]types.ObjectType, 0)
+	}
+	if definition.AllowedValues == nil {
+		definition.AllowedValues = make([]string, 0)
+	}
+	resourceTypesBytes, err := json.Marshal(definition.ResourceTypes)
+	if err != nil {
+		return nil, err
+	}
+	allowedValuesBytes, err := json.Marshal(definition.AllowedValues)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LabelDefinition{
+		BaseEntity: BaseEntity{
+			ID:             definition.ID,
+			CreatedAt:      definition.CreatedAt,
+			UpdatedAt:      definition.UpdatedAt,
+			PagingSequence: definition.PagingSequence,
+			Ready:          definition.Ready,
+		},
+		Key:           definition.Key,
+		Description:   toNullString(definition.Description),
+		ResourceTypes: getJSONText(resourceTypesBytes),
+		ValueType:     string(definition.ValueType),
+		AllowedValues: getJSONText(allowedValuesBytes),
+		Pattern:       toNullString(definition.Pattern),
+		Cardinality:   toNullString(definition.Cardinality),
+		Required:      definition.Required,
+	}, nil
+}
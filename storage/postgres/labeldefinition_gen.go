@@ -0,0 +1,34 @@
+// GENERATED. DO NOT MODIFY!
+
+package postgres
+
+import (
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/jmoiron/sqlx"
+)
+
+var _ LabeledEntity = &LabelDefinition{}
+
+const LabelDefinitionTable = "label_definitions"
+
+func (*LabelDefinition) TableName() string {
+	return LabelDefinitionTable
+}
+
+func (e *LabelDefinition) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
+	rowCreator := func() PostgresEntity {
+		return &LabelDefinition{}
+	}
+	result := e.NewList()
+	err := rowsToList(rows, rowCreator, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (*LabelDefinition) NewList() types.ObjectList {
+	return &types.LabelDefinitions{
+		LabelDefinitions: make([]*types.LabelDefinition, 0),
+	}
+}
@@ -18,6 +18,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
@@ -25,100 +26,44 @@ import (
 
 	"github.com/Peripli/service-manager/pkg/types"
 
-	"github.com/Peripli/service-manager/pkg/util"
-
 	"github.com/Peripli/service-manager/pkg/query"
 
-	"github.com/gofrs/uuid"
-
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/jmoiron/sqlx"
 )
 
-func updateLabelsAbstract(ctx context.Context, newLabelFunc func(labelID string, labelKey string, labelValue string) (PostgresLabel, error), pgDB pgDB, referenceID string, updateActions []*types.LabelChange) error {
-	for _, action := range updateActions {
-		switch action.Operation {
-		case types.AddLabelOperation:
-			fallthrough
-		case types.AddLabelValuesOperation:
-			for _, labelValue := range action.Values {
-				if err := addLabel(ctx, newLabelFunc, pgDB, action.Key, labelValue, referenceID); err != nil {
-					return err
-				}
-			}
-		case types.RemoveLabelOperation:
-			fallthrough
-		case types.RemoveLabelValuesOperation:
-			pgLabel, err := newLabelFunc("", "", "")
-			if err != nil {
-				return err
-			}
-			if err := removeLabel(ctx, pgDB, pgLabel, referenceID, action.Key, action.Values...); err != nil {
-				return err
-			}
-		}
+// updateLabels applies the label changes to the labels stored in the labels column of the entity. The row of the
+// entity is locked while the changes are applied so that concurrent label changes do not override each other.
+func updateLabels(ctx context.Context, db pgDB, tableName, entityID string, labelChanges types.LabelChanges) error {
+	if len(labelChanges) == 0 {
+		return nil
 	}
-	return nil
-}
-func addLabel(ctx context.Context, newLabelFunc func(labelID string, labelKey string, labelValue string) (PostgresLabel, error), db pgDB, key string, value string, referenceID string) error {
-	uuids, err := uuid.NewV4()
-	if err != nil {
-		return fmt.Errorf("could not generate id for new label: %v", err)
+
+	entity := &BaseEntity{ID: entityID}
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1 FOR UPDATE", LabelsColumn, tableName, PrimaryKeyColumn)
+	log.C(ctx).Debugf("Executing query %s", selectQuery)
+	if err := db.GetContext(ctx, &entity.Labels, selectQuery, entityID); err != nil {
+		return checkSQLNoRows(err)
 	}
-	labelID := uuids.String()
-	newLabel, err := newLabelFunc(labelID, key, value)
+	labels, err := entity.GetLabels()
 	if err != nil {
 		return err
 	}
-	labelTable := newLabel.LabelsTableName()
-	referenceColumnName := newLabel.ReferenceColumn()
-
-	query := fmt.Sprintf("SELECT * FROM %s WHERE key=$1 and val=$2 and %s=$3", labelTable, referenceColumnName)
-	log.C(ctx).Debugf("Executing query %s", query)
-
-	err = db.GetContext(ctx, newLabel, query, key, value, referenceID)
-	if checkSQLNoRows(err) == util.ErrNotFoundInStorage {
-		if err := create(ctx, db, labelTable, newLabel, newLabel); err != nil {
-			return err
-		}
-	} else {
-		log.C(ctx).Debugf("Nothing to create. Label with key=%s value=%s %s=%s already exists in table %s", key, value, referenceColumnName, referenceID, labelTable)
-	}
-	return nil
-}
-
-func removeLabel(ctx context.Context, execer sqlx.ExtContext, label PostgresLabel, referenceID, labelKey string, labelValues ...string) error {
-	labelTableName := label.LabelsTableName()
-	referenceColumnName := label.ReferenceColumn()
-	baseQuery := fmt.Sprintf("DELETE FROM %s WHERE key=? AND %s=?", labelTableName, referenceColumnName)
-	args := []interface{}{labelKey, referenceID}
-	// remove all labels with this key
-	if len(labelValues) == 0 {
-		if err := executeNew(ctx, execer, baseQuery, args); err != nil {
-			if err == util.ErrNotFoundInStorage {
-				log.C(ctx).Debugf("Nothing to delete. Label with key=%s %s=%s not found in table %s", labelKey, referenceColumnName, referenceID, labelTableName)
-				return nil
-			}
-			return err
-		}
-		return nil
+	labels, _, _ = query.ApplyLabelChangesToLabels(labelChanges, labels)
+	if err := validateLabels(labels); err != nil {
+		return err
 	}
-	// remove labels with a specific key and a value which is in the provided list
-	args = append(args, labelValues)
-	baseQuery += " AND val IN (?)"
-	sqlQuery, queryParams, err := sqlx.In(baseQuery, args...)
-	if err != nil {
+	if err := entity.SetLabels(labels); err != nil {
 		return err
 	}
 
-	if err := executeNew(ctx, execer, sqlQuery, queryParams); err != nil {
-		if err == util.ErrNotFoundInStorage {
-			log.C(ctx).Debugf("Nothing to delete. Label with key=%s values in %s %s=%s not found in table %s", labelKey, labelValues, referenceColumnName, referenceID, labelTableName)
-			return nil
-		}
+	updateQuery := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2", tableName, LabelsColumn, PrimaryKeyColumn)
+	log.C(ctx).Debugf("Executing query %s", updateQuery)
+	result, err := db.ExecContext(ctx, updateQuery, entity.Labels, entityID)
+	if err != nil {
 		return err
 	}
-	return nil
+	return checkRowsAffected(ctx, result)
 }
 
 func findTagType(tags []tagType, tagName string) reflect.Type {
@@ -135,8 +80,23 @@ var (
 	int64Type     = reflect.TypeOf(int64(1))
 	timeType      = reflect.TypeOf(time.Time{})
 	byteSliceType = reflect.TypeOf([]byte{})
+
+	nullableTypes = map[reflect.Type]bool{
+		reflect.TypeOf(sql.NullString{}):  true,
+		reflect.TypeOf(sql.NullBool{}):    true,
+		reflect.TypeOf(sql.NullInt64{}):   true,
+		reflect.TypeOf(sql.NullFloat64{}): true,
+	}
 )
 
+// isNullableType checks whether a column of the specified type can hold NULL values
+func isNullableType(tagType reflect.Type) bool {
+	if tagType == nil {
+		return false
+	}
+	return tagType.Kind() == reflect.Ptr || nullableTypes[tagType]
+}
+
 func determineCastByType(tagType reflect.Type) string {
 	dbCast := ""
 	switch tagType {
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// MaintenanceModeTable is the table holding the maintenance mode
+const MaintenanceModeTable = "maintenance_mode"
+
+// GetMaintenanceMode returns the maintenance mode shared by all Service Manager instances
+func (ps *Storage) GetMaintenanceMode(ctx context.Context) (*types.MaintenanceMode, error) {
+	ps.checkOpen()
+
+	mode := &types.MaintenanceMode{}
+	if err := ps.db.GetContext(ctx, mode, "SELECT read_only, reason, updated_at FROM "+MaintenanceModeTable); err != nil {
+		if err == sql.ErrNoRows {
+			return mode, nil
+		}
+		return nil, err
+	}
+	return mode, nil
+}
+
+// SetMaintenanceMode sets the maintenance mode shared by all Service Manager instances
+func (ps *Storage) SetMaintenanceMode(ctx context.Context, mode *types.MaintenanceMode) error {
+	ps.checkOpen()
+
+	_, err := ps.db.ExecContext(ctx, "INSERT INTO "+MaintenanceModeTable+" (id, read_only, reason, updated_at) VALUES (1, $1, $2, $3) "+
+		"ON CONFLICT (id) DO UPDATE SET read_only = EXCLUDED.read_only, reason = EXCLUDED.reason, updated_at = EXCLUDED.updated_at",
+		mode.ReadOnly, mode.Reason, mode.UpdatedAt)
+	return err
+}
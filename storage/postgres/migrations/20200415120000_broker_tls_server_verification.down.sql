@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE brokers DROP COLUMN tls_server_name;
+ALTER TABLE brokers DROP COLUMN tls_ca_certificate;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE brokers ADD COLUMN tls_ca_certificate text NOT NULL DEFAULT '';
+ALTER TABLE brokers ADD COLUMN tls_server_name varchar(255) NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP index IF EXISTS roles_paging_sequence_uindex;
+DROP TABLE IF EXISTS role_labels;
+DROP TABLE IF EXISTS roles;
+
+COMMIT;
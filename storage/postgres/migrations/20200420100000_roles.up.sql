@@ -0,0 +1,31 @@
+BEGIN;
+
+CREATE TABLE roles
+(
+  id              varchar(100) PRIMARY KEY,
+  name            varchar(255) NOT NULL UNIQUE,
+  description     text,
+  access_level    varchar(100),
+  permissions     json NOT NULL DEFAULT '[]',
+  bindings        json NOT NULL DEFAULT '[]',
+  created_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  paging_sequence BIGSERIAL,
+  ready           boolean NOT NULL
+);
+
+CREATE TABLE role_labels
+(
+  id         varchar(100) PRIMARY KEY,
+  key        varchar(255) NOT NULL CHECK (key <> ''),
+  val        varchar(255) NOT NULL CHECK (val <> ''),
+  role_id    varchar(100) NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, role_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS roles_paging_sequence_uindex
+  on roles (paging_sequence);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS platforms_old_username_index;
+
+ALTER TABLE platforms DROP COLUMN IF EXISTS credentials_last_used_at;
+ALTER TABLE platforms DROP COLUMN IF EXISTS old_credentials_last_used_at;
+ALTER TABLE platforms DROP COLUMN IF EXISTS old_credentials_expire_at;
+ALTER TABLE platforms DROP COLUMN IF EXISTS old_password;
+ALTER TABLE platforms DROP COLUMN IF EXISTS old_username;
+
+COMMIT;
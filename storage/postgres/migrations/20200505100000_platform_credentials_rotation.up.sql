@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE platforms ADD COLUMN old_username varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE platforms ADD COLUMN old_password varchar(500) NOT NULL DEFAULT '';
+ALTER TABLE platforms ADD COLUMN old_credentials_expire_at timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+ALTER TABLE platforms ADD COLUMN old_credentials_last_used_at timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+ALTER TABLE platforms ADD COLUMN credentials_last_used_at timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+
+CREATE INDEX IF NOT EXISTS platforms_old_username_index ON platforms (old_username);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE platforms DROP COLUMN IF EXISTS acknowledged_notification_revision;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE platforms ADD COLUMN acknowledged_notification_revision bigint NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,201 @@
+BEGIN;
+
+CREATE TABLE visibility_labels
+(
+  id            varchar(100) PRIMARY KEY,
+  key           varchar(255) NOT NULL CHECK (key <> ''),
+  val           varchar(255) NOT NULL CHECK (val <> ''),
+  visibility_id varchar(100) NOT NULL REFERENCES visibilities (id) ON DELETE CASCADE,
+  created_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, visibility_id)
+);
+INSERT INTO visibility_labels (id, key, val, visibility_id)
+SELECT md5(visibilities.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, visibilities.id
+FROM visibilities,
+     jsonb_each(visibilities.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS visibilities_labels_index;
+ALTER TABLE visibilities DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE broker_labels
+(
+  id         varchar(100) PRIMARY KEY,
+  key        varchar(255) NOT NULL CHECK (key <> ''),
+  val        varchar(255) NOT NULL CHECK (val <> ''),
+  broker_id  varchar(100) NOT NULL REFERENCES brokers (id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, broker_id)
+);
+INSERT INTO broker_labels (id, key, val, broker_id)
+SELECT md5(brokers.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, brokers.id
+FROM brokers,
+     jsonb_each(brokers.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS brokers_labels_index;
+ALTER TABLE brokers DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE platform_labels
+(
+  id          varchar(100) PRIMARY KEY,
+  key         varchar(255) NOT NULL CHECK (key <> ''),
+  val         varchar(255) NOT NULL CHECK (val <> ''),
+  platform_id varchar(100) NOT NULL REFERENCES platforms (id) ON DELETE CASCADE,
+  created_at  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, platform_id)
+);
+INSERT INTO platform_labels (id, key, val, platform_id)
+SELECT md5(platforms.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, platforms.id
+FROM platforms,
+     jsonb_each(platforms.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS platforms_labels_index;
+ALTER TABLE platforms DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE service_offering_labels
+(
+  id                  varchar(100) PRIMARY KEY,
+  key                 varchar(255) NOT NULL CHECK (key <> ''),
+  val                 varchar(255) NOT NULL CHECK (val <> ''),
+  service_offering_id varchar(100) NOT NULL REFERENCES service_offerings (id) ON DELETE CASCADE,
+  created_at          timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at          timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, service_offering_id)
+);
+INSERT INTO service_offering_labels (id, key, val, service_offering_id)
+SELECT md5(service_offerings.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, service_offerings.id
+FROM service_offerings,
+     jsonb_each(service_offerings.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS service_offerings_labels_index;
+ALTER TABLE service_offerings DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE service_plan_labels
+(
+  id              varchar(100) PRIMARY KEY,
+  key             varchar(255) NOT NULL CHECK (key <> ''),
+  val             varchar(255) NOT NULL CHECK (val <> ''),
+  service_plan_id varchar(100) NOT NULL REFERENCES service_plans (id) ON DELETE CASCADE,
+  created_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, service_plan_id)
+);
+INSERT INTO service_plan_labels (id, key, val, service_plan_id)
+SELECT md5(service_plans.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, service_plans.id
+FROM service_plans,
+     jsonb_each(service_plans.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS service_plans_labels_index;
+ALTER TABLE service_plans DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE notification_labels
+(
+  id              varchar(100) PRIMARY KEY,
+  key             varchar(255) NOT NULL CHECK (key <> ''),
+  val             varchar(255) NOT NULL CHECK (val <> ''),
+  notification_id varchar(100) NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+  created_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, notification_id)
+);
+INSERT INTO notification_labels (id, key, val, notification_id)
+SELECT md5(notifications.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, notifications.id
+FROM notifications,
+     jsonb_each(notifications.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS notifications_labels_index;
+ALTER TABLE notifications DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE operation_labels
+(
+  id           varchar(100) PRIMARY KEY,
+  key          varchar(255) NOT NULL CHECK (key <> ''),
+  val          varchar(255) NOT NULL CHECK (val <> ''),
+  operation_id varchar(100) NOT NULL REFERENCES operations (id) ON DELETE CASCADE,
+  created_at   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, operation_id)
+);
+INSERT INTO operation_labels (id, key, val, operation_id)
+SELECT md5(operations.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, operations.id
+FROM operations,
+     jsonb_each(operations.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS operations_labels_index;
+ALTER TABLE operations DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE service_instance_labels
+(
+  id                  varchar(100) PRIMARY KEY,
+  key                 varchar(255) NOT NULL CHECK (key <> ''),
+  val                 varchar(255) NOT NULL CHECK (val <> ''),
+  service_instance_id varchar(100) NOT NULL REFERENCES service_instances (id) ON DELETE CASCADE,
+  created_at          timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at          timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, service_instance_id)
+);
+INSERT INTO service_instance_labels (id, key, val, service_instance_id)
+SELECT md5(service_instances.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, service_instances.id
+FROM service_instances,
+     jsonb_each(service_instances.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS service_instances_labels_index;
+ALTER TABLE service_instances DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE service_binding_labels
+(
+  id                 varchar(100) PRIMARY KEY,
+  key                varchar(255) NOT NULL CHECK (key <> ''),
+  val                varchar(255) NOT NULL CHECK (val <> ''),
+  service_binding_id varchar(100) NOT NULL REFERENCES service_bindings (id) ON DELETE CASCADE,
+  created_at         timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at         timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, service_binding_id)
+);
+INSERT INTO service_binding_labels (id, key, val, service_binding_id)
+SELECT md5(service_bindings.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, service_bindings.id
+FROM service_bindings,
+     jsonb_each(service_bindings.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS service_bindings_labels_index;
+ALTER TABLE service_bindings DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE broker_platform_credential_labels
+(
+  id                            varchar(100) PRIMARY KEY,
+  key                           varchar(255) NOT NULL CHECK (key <> ''),
+  val                           varchar(255) NOT NULL CHECK (val <> ''),
+  broker_platform_credential_id varchar(100) NOT NULL REFERENCES broker_platform_credentials (id) ON DELETE CASCADE,
+  created_at                    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at                    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, broker_platform_credential_id)
+);
+INSERT INTO broker_platform_credential_labels (id, key, val, broker_platform_credential_id)
+SELECT md5(broker_platform_credentials.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, broker_platform_credentials.id
+FROM broker_platform_credentials,
+     jsonb_each(broker_platform_credentials.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS broker_platform_credentials_labels_index;
+ALTER TABLE broker_platform_credentials DROP COLUMN IF EXISTS labels;
+
+CREATE TABLE role_labels
+(
+  id         varchar(100) PRIMARY KEY,
+  key        varchar(255) NOT NULL CHECK (key <> ''),
+  val        varchar(255) NOT NULL CHECK (val <> ''),
+  role_id    varchar(100) NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, role_id)
+);
+INSERT INTO role_labels (id, key, val, role_id)
+SELECT md5(roles.id || ':' || label.key || '=' || label_value.val), label.key, label_value.val, roles.id
+FROM roles,
+     jsonb_each(roles.labels) AS label,
+     jsonb_array_elements_text(label.value) AS label_value(val);
+DROP INDEX IF EXISTS roles_labels_index;
+ALTER TABLE roles DROP COLUMN IF EXISTS labels;
+
+COMMIT;
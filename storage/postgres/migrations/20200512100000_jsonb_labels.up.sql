@@ -0,0 +1,124 @@
+BEGIN;
+
+ALTER TABLE visibilities ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE visibilities SET labels = entity_labels.labels
+FROM (SELECT visibility_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT visibility_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM visibility_labels
+            GROUP BY visibility_id, key) label_values
+      GROUP BY visibility_id) entity_labels
+WHERE visibilities.id = entity_labels.visibility_id;
+CREATE INDEX IF NOT EXISTS visibilities_labels_index ON visibilities USING GIN (labels);
+DROP TABLE IF EXISTS visibility_labels;
+
+ALTER TABLE brokers ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE brokers SET labels = entity_labels.labels
+FROM (SELECT broker_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT broker_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM broker_labels
+            GROUP BY broker_id, key) label_values
+      GROUP BY broker_id) entity_labels
+WHERE brokers.id = entity_labels.broker_id;
+CREATE INDEX IF NOT EXISTS brokers_labels_index ON brokers USING GIN (labels);
+DROP TABLE IF EXISTS broker_labels;
+
+ALTER TABLE platforms ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE platforms SET labels = entity_labels.labels
+FROM (SELECT platform_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT platform_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM platform_labels
+            GROUP BY platform_id, key) label_values
+      GROUP BY platform_id) entity_labels
+WHERE platforms.id = entity_labels.platform_id;
+CREATE INDEX IF NOT EXISTS platforms_labels_index ON platforms USING GIN (labels);
+DROP TABLE IF EXISTS platform_labels;
+
+ALTER TABLE service_offerings ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE service_offerings SET labels = entity_labels.labels
+FROM (SELECT service_offering_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT service_offering_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM service_offering_labels
+            GROUP BY service_offering_id, key) label_values
+      GROUP BY service_offering_id) entity_labels
+WHERE service_offerings.id = entity_labels.service_offering_id;
+CREATE INDEX IF NOT EXISTS service_offerings_labels_index ON service_offerings USING GIN (labels);
+DROP TABLE IF EXISTS service_offering_labels;
+
+ALTER TABLE service_plans ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE service_plans SET labels = entity_labels.labels
+FROM (SELECT service_plan_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT service_plan_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM service_plan_labels
+            GROUP BY service_plan_id, key) label_values
+      GROUP BY service_plan_id) entity_labels
+WHERE service_plans.id = entity_labels.service_plan_id;
+CREATE INDEX IF NOT EXISTS service_plans_labels_index ON service_plans USING GIN (labels);
+DROP TABLE IF EXISTS service_plan_labels;
+
+ALTER TABLE notifications ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE notifications SET labels = entity_labels.labels
+FROM (SELECT notification_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT notification_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM notification_labels
+            GROUP BY notification_id, key) label_values
+      GROUP BY notification_id) entity_labels
+WHERE notifications.id = entity_labels.notification_id;
+CREATE INDEX IF NOT EXISTS notifications_labels_index ON notifications USING GIN (labels);
+DROP TABLE IF EXISTS notification_labels;
+
+ALTER TABLE operations ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE operations SET labels = entity_labels.labels
+FROM (SELECT operation_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT operation_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM operation_labels
+            GROUP BY operation_id, key) label_values
+      GROUP BY operation_id) entity_labels
+WHERE operations.id = entity_labels.operation_id;
+CREATE INDEX IF NOT EXISTS operations_labels_index ON operations USING GIN (labels);
+DROP TABLE IF EXISTS operation_labels;
+
+ALTER TABLE service_instances ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE service_instances SET labels = entity_labels.labels
+FROM (SELECT service_instance_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT service_instance_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM service_instance_labels
+            GROUP BY service_instance_id, key) label_values
+      GROUP BY service_instance_id) entity_labels
+WHERE service_instances.id = entity_labels.service_instance_id;
+CREATE INDEX IF NOT EXISTS service_instances_labels_index ON service_instances USING GIN (labels);
+DROP TABLE IF EXISTS service_instance_labels;
+
+ALTER TABLE service_bindings ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE service_bindings SET labels = entity_labels.labels
+FROM (SELECT service_binding_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT service_binding_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM service_binding_labels
+            GROUP BY service_binding_id, key) label_values
+      GROUP BY service_binding_id) entity_labels
+WHERE service_bindings.id = entity_labels.service_binding_id;
+CREATE INDEX IF NOT EXISTS service_bindings_labels_index ON service_bindings USING GIN (labels);
+DROP TABLE IF EXISTS service_binding_labels;
+
+ALTER TABLE broker_platform_credentials ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE broker_platform_credentials SET labels = entity_labels.labels
+FROM (SELECT broker_platform_credential_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT broker_platform_credential_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM broker_platform_credential_labels
+            GROUP BY broker_platform_credential_id, key) label_values
+      GROUP BY broker_platform_credential_id) entity_labels
+WHERE broker_platform_credentials.id = entity_labels.broker_platform_credential_id;
+CREATE INDEX IF NOT EXISTS broker_platform_credentials_labels_index ON broker_platform_credentials USING GIN (labels);
+DROP TABLE IF EXISTS broker_platform_credential_labels;
+
+ALTER TABLE roles ADD COLUMN labels jsonb NOT NULL DEFAULT '{}';
+UPDATE roles SET labels = entity_labels.labels
+FROM (SELECT role_id, jsonb_object_agg(key, vals) AS labels
+      FROM (SELECT role_id, key, jsonb_agg(val ORDER BY created_at, val) AS vals
+            FROM role_labels
+            GROUP BY role_id, key) label_values
+      GROUP BY role_id) entity_labels
+WHERE roles.id = entity_labels.role_id;
+CREATE INDEX IF NOT EXISTS roles_labels_index ON roles USING GIN (labels);
+DROP TABLE IF EXISTS role_labels;
+
+COMMIT;
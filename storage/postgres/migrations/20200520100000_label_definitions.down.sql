@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP index IF EXISTS label_definitions_labels_index;
+DROP index IF EXISTS label_definitions_paging_sequence_uindex;
+DROP TABLE IF EXISTS label_definitions;
+
+COMMIT;
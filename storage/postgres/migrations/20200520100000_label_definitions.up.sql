@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE label_definitions
+(
+  id              varchar(100) PRIMARY KEY,
+  key             varchar(255) NOT NULL UNIQUE,
+  description     text,
+  resource_types  json NOT NULL DEFAULT '[]',
+  value_type      varchar(100) NOT NULL,
+  allowed_values  json NOT NULL DEFAULT '[]',
+  pattern         text,
+  cardinality     varchar(100),
+  required        boolean NOT NULL DEFAULT false,
+  labels          jsonb NOT NULL DEFAULT '{}',
+  created_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  paging_sequence BIGSERIAL,
+  ready           boolean NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS label_definitions_paging_sequence_uindex
+  on label_definitions (paging_sequence);
+CREATE INDEX IF NOT EXISTS label_definitions_labels_index ON label_definitions USING GIN (labels);
+
+COMMIT;
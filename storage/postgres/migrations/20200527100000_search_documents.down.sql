@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS platforms_search_document ON platforms;
+DROP TRIGGER IF EXISTS service_instances_search_document ON service_instances;
+DROP TRIGGER IF EXISTS service_plans_search_document ON service_plans;
+DROP TRIGGER IF EXISTS service_offerings_search_document ON service_offerings;
+DROP TRIGGER IF EXISTS brokers_search_document ON brokers;
+DROP FUNCTION IF EXISTS update_search_document();
+DROP FUNCTION IF EXISTS search_document(jsonb);
+DROP index IF EXISTS search_documents_document_index;
+DROP TABLE IF EXISTS search_documents;
+
+COMMIT;
@@ -0,0 +1,68 @@
+BEGIN;
+
+-- search_documents holds the full-text search document of each searchable resource, keyed by the table of the resource
+CREATE TABLE search_documents
+(
+  resource_type varchar(100) NOT NULL,
+  resource_id   varchar(100) NOT NULL,
+  document      tsvector     NOT NULL,
+  PRIMARY KEY (resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS search_documents_document_index ON search_documents USING GIN (document);
+
+-- the names are ranked highest, followed by the descriptions and the catalog metadata
+CREATE OR REPLACE FUNCTION search_document(resource jsonb) RETURNS tsvector AS $$
+  SELECT setweight(to_tsvector('simple', coalesce(resource ->> 'name', '') || ' ' || coalesce(resource ->> 'catalog_name', '')), 'A') ||
+         setweight(to_tsvector('simple', coalesce(resource ->> 'description', '')), 'B') ||
+         setweight(to_tsvector('simple', coalesce(resource ->> 'metadata', '')), 'C');
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION update_search_document() RETURNS TRIGGER AS $$
+  BEGIN
+    IF TG_OP = 'DELETE' THEN
+      DELETE FROM search_documents WHERE resource_type = TG_TABLE_NAME AND resource_id = OLD.id;
+      RETURN NULL;
+    END IF;
+
+    INSERT INTO search_documents (resource_type, resource_id, document)
+    VALUES (TG_TABLE_NAME, NEW.id, search_document(to_jsonb(NEW)))
+    ON CONFLICT (resource_type, resource_id) DO UPDATE SET document = EXCLUDED.document;
+
+    -- Result is ignored since this is an AFTER trigger
+    RETURN NULL;
+  END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER brokers_search_document
+  AFTER INSERT OR DELETE OR UPDATE OF name, description ON brokers
+  FOR EACH ROW EXECUTE PROCEDURE update_search_document();
+
+CREATE TRIGGER service_offerings_search_document
+  AFTER INSERT OR DELETE OR UPDATE OF name, catalog_name, description, metadata ON service_offerings
+  FOR EACH ROW EXECUTE PROCEDURE update_search_document();
+
+CREATE TRIGGER service_plans_search_document
+  AFTER INSERT OR DELETE OR UPDATE OF name, catalog_name, description, metadata ON service_plans
+  FOR EACH ROW EXECUTE PROCEDURE update_search_document();
+
+CREATE TRIGGER service_instances_search_document
+  AFTER INSERT OR DELETE OR UPDATE OF name ON service_instances
+  FOR EACH ROW EXECUTE PROCEDURE update_search_document();
+
+CREATE TRIGGER platforms_search_document
+  AFTER INSERT OR DELETE OR UPDATE OF name, description ON platforms
+  FOR EACH ROW EXECUTE PROCEDURE update_search_document();
+
+INSERT INTO search_documents (resource_type, resource_id, document)
+SELECT 'brokers', id, search_document(to_jsonb(brokers)) FROM brokers;
+INSERT INTO search_documents (resource_type, resource_id, document)
+SELECT 'service_offerings', id, search_document(to_jsonb(service_offerings)) FROM service_offerings;
+INSERT INTO search_documents (resource_type, resource_id, document)
+SELECT 'service_plans', id, search_document(to_jsonb(service_plans)) FROM service_plans;
+INSERT INTO search_documents (resource_type, resource_id, document)
+SELECT 'service_instances', id, search_document(to_jsonb(service_instances)) FROM service_instances;
+INSERT INTO search_documents (resource_type, resource_id, document)
+SELECT 'platforms', id, search_document(to_jsonb(platforms)) FROM platforms;
+
+COMMIT;
@@ -0,0 +1,85 @@
+BEGIN;
+
+ALTER TABLE operations RENAME TO operations_partitioned;
+ALTER TABLE operations_partitioned RENAME CONSTRAINT operations_pkey TO operations_partitioned_pkey;
+DROP INDEX IF EXISTS operations_paging_sequence_uindex;
+DROP INDEX IF EXISTS operations_labels_index;
+
+CREATE TABLE operations
+(
+  id                   varchar(100) PRIMARY KEY,
+  correlation_id       varchar(100),
+  description          varchar(255),
+  external_id          varchar(10000),
+  type                 operation_type  NOT NULL,
+  state                operation_state NOT NULL,
+  resource_type        varchar(100)    NOT NULL,
+  resource_id          varchar(100),
+  created_at           timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at           timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  errors               json            DEFAULT '{}',
+  paging_sequence      bigint          NOT NULL DEFAULT nextval('operations_paging_sequence_seq'),
+  platform_id          varchar(100)    NOT NULL DEFAULT '',
+  reschedule           boolean         NOT NULL DEFAULT '0',
+  deletion_scheduled   timestamp       NOT NULL DEFAULT '0001-01-01 00:00:00+00',
+  ready                boolean         NOT NULL DEFAULT '1',
+  transitive_resources json            DEFAULT '[]',
+  reschedule_timestamp timestamptz     NOT NULL DEFAULT '0001-01-01 00:00:00+00',
+  labels               jsonb           NOT NULL DEFAULT '{}'
+);
+
+INSERT INTO operations (id, correlation_id, description, external_id, type, state, resource_type, resource_id,
+                        created_at, updated_at, errors, paging_sequence, platform_id, reschedule, deletion_scheduled,
+                        ready, transitive_resources, reschedule_timestamp, labels)
+SELECT id, correlation_id, description, external_id, type, state, resource_type, resource_id,
+       created_at, updated_at, errors, paging_sequence, platform_id, reschedule, deletion_scheduled,
+       ready, transitive_resources, reschedule_timestamp, labels
+FROM operations_partitioned;
+
+CREATE UNIQUE INDEX operations_paging_sequence_uindex ON operations (paging_sequence);
+CREATE INDEX operations_labels_index ON operations USING GIN (labels);
+
+ALTER SEQUENCE operations_paging_sequence_seq OWNED BY operations.paging_sequence;
+DROP TABLE operations_partitioned;
+
+ALTER TABLE notifications RENAME TO notifications_partitioned;
+ALTER TABLE notifications_partitioned RENAME CONSTRAINT notifications_pkey TO notifications_partitioned_pkey;
+DROP TRIGGER IF EXISTS notifications_broadcast ON notifications_partitioned;
+DROP INDEX IF EXISTS notifications_paging_sequence_uindex;
+DROP INDEX IF EXISTS notifications_revision_index;
+DROP INDEX IF EXISTS notifications_labels_index;
+
+CREATE TABLE notifications
+(
+  id              varchar(100) PRIMARY KEY,
+  resource        varchar(100)      NOT NULL,
+  type            notification_type NOT NULL,
+  platform_id     varchar(100) REFERENCES platforms (id) ON DELETE CASCADE, -- value is platform_id from platforms table or null
+  revision        bigint            NOT NULL DEFAULT nextval('notifications_revision_seq'),
+  created_at      timestamptz       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  payload         json              NOT NULL, -- json with the notification payload
+  correlation_id  varchar(40),
+  paging_sequence bigint            NOT NULL DEFAULT nextval('notifications_paging_sequence_seq'),
+  ready           boolean           NOT NULL DEFAULT '1',
+  labels          jsonb             NOT NULL DEFAULT '{}'
+);
+
+INSERT INTO notifications (id, resource, type, platform_id, revision, created_at, updated_at, payload, correlation_id,
+                           paging_sequence, ready, labels)
+SELECT id, resource, type, platform_id, revision, created_at, updated_at, payload, correlation_id,
+       paging_sequence, ready, labels
+FROM notifications_partitioned;
+
+CREATE UNIQUE INDEX notifications_paging_sequence_uindex ON notifications (paging_sequence);
+CREATE INDEX notifications_labels_index ON notifications USING GIN (labels);
+
+ALTER SEQUENCE notifications_revision_seq OWNED BY notifications.revision;
+ALTER SEQUENCE notifications_paging_sequence_seq OWNED BY notifications.paging_sequence;
+DROP TABLE notifications_partitioned;
+
+CREATE TRIGGER notifications_broadcast
+  AFTER INSERT ON notifications
+  FOR EACH ROW EXECUTE PROCEDURE notify_sm();
+
+COMMIT;
@@ -0,0 +1,123 @@
+BEGIN;
+
+-- operations and notifications are partitioned by their creation time, so that the expired ones are dropped with their
+-- partitions instead of being deleted; the rows outside of the created partitions are kept in the default partitions
+
+-- the primary keys of partitioned tables have to include the partition key, so they are (id, created_at) and the ids
+-- of operations and notifications are not unique by constraint anymore; they are random UUIDs generated by the
+-- Service Manager, which is what keeps them unique
+
+ALTER TABLE operations RENAME TO operations_unpartitioned;
+ALTER TABLE operations_unpartitioned RENAME CONSTRAINT operations_pkey TO operations_unpartitioned_pkey;
+DROP INDEX IF EXISTS operations_paging_sequence_uindex;
+DROP INDEX IF EXISTS operations_labels_index;
+
+CREATE TABLE operations
+(
+  id                   varchar(100)    NOT NULL,
+  correlation_id       varchar(100),
+  description          varchar(255),
+  external_id          varchar(10000),
+  type                 operation_type  NOT NULL,
+  state                operation_state NOT NULL,
+  resource_type        varchar(100)    NOT NULL,
+  resource_id          varchar(100),
+  created_at           timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at           timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  errors               json            DEFAULT '{}',
+  paging_sequence      bigint          NOT NULL DEFAULT nextval('operations_paging_sequence_seq'),
+  platform_id          varchar(100)    NOT NULL DEFAULT '',
+  reschedule           boolean         NOT NULL DEFAULT '0',
+  deletion_scheduled   timestamp       NOT NULL DEFAULT '0001-01-01 00:00:00+00',
+  ready                boolean         NOT NULL DEFAULT '1',
+  transitive_resources json            DEFAULT '[]',
+  reschedule_timestamp timestamptz     NOT NULL DEFAULT '0001-01-01 00:00:00+00',
+  labels               jsonb           NOT NULL DEFAULT '{}',
+  PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE UNIQUE INDEX operations_paging_sequence_uindex ON operations (paging_sequence, created_at);
+CREATE INDEX operations_labels_index ON operations USING GIN (labels);
+
+CREATE TABLE operations_default PARTITION OF operations DEFAULT;
+
+DO $$
+DECLARE
+  partition_start timestamptz := date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+BEGIN
+  FOR i IN 0..1 LOOP
+    EXECUTE format('CREATE TABLE %I PARTITION OF operations FOR VALUES FROM (%L) TO (%L)',
+                   'operations_p' || to_char(partition_start AT TIME ZONE 'UTC', 'YYYYMMDD'),
+                   partition_start, partition_start + interval '1 day');
+    partition_start := partition_start + interval '1 day';
+  END LOOP;
+END $$;
+
+INSERT INTO operations (id, correlation_id, description, external_id, type, state, resource_type, resource_id,
+                        created_at, updated_at, errors, paging_sequence, platform_id, reschedule, deletion_scheduled,
+                        ready, transitive_resources, reschedule_timestamp, labels)
+SELECT id, correlation_id, description, external_id, type, state, resource_type, resource_id,
+       created_at, updated_at, errors, paging_sequence, platform_id, reschedule, deletion_scheduled,
+       ready, transitive_resources, reschedule_timestamp, labels
+FROM operations_unpartitioned;
+
+ALTER SEQUENCE operations_paging_sequence_seq OWNED BY operations.paging_sequence;
+DROP TABLE operations_unpartitioned;
+
+ALTER TABLE notifications RENAME TO notifications_unpartitioned;
+ALTER TABLE notifications_unpartitioned RENAME CONSTRAINT notifications_pkey TO notifications_unpartitioned_pkey;
+DROP TRIGGER IF EXISTS notifications_broadcast ON notifications_unpartitioned;
+DROP INDEX IF EXISTS notifications_paging_sequence_uindex;
+DROP INDEX IF EXISTS notifications_labels_index;
+
+CREATE TABLE notifications
+(
+  id              varchar(100)      NOT NULL,
+  resource        varchar(100)      NOT NULL,
+  type            notification_type NOT NULL,
+  platform_id     varchar(100) REFERENCES platforms (id) ON DELETE CASCADE, -- value is platform_id from platforms table or null
+  revision        bigint            NOT NULL DEFAULT nextval('notifications_revision_seq'),
+  created_at      timestamptz       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  payload         json              NOT NULL, -- json with the notification payload
+  correlation_id  varchar(40),
+  paging_sequence bigint            NOT NULL DEFAULT nextval('notifications_paging_sequence_seq'),
+  ready           boolean           NOT NULL DEFAULT '1',
+  labels          jsonb             NOT NULL DEFAULT '{}',
+  PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE UNIQUE INDEX notifications_paging_sequence_uindex ON notifications (paging_sequence, created_at);
+CREATE INDEX notifications_revision_index ON notifications (revision);
+CREATE INDEX notifications_labels_index ON notifications USING GIN (labels);
+
+CREATE TABLE notifications_default PARTITION OF notifications DEFAULT;
+
+DO $$
+DECLARE
+  partition_start timestamptz := date_trunc('hour', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+BEGIN
+  FOR i IN 0..2 LOOP
+    EXECUTE format('CREATE TABLE %I PARTITION OF notifications FOR VALUES FROM (%L) TO (%L)',
+                   'notifications_p' || to_char(partition_start AT TIME ZONE 'UTC', 'YYYYMMDDHH24'),
+                   partition_start, partition_start + interval '1 hour');
+    partition_start := partition_start + interval '1 hour';
+  END LOOP;
+END $$;
+
+INSERT INTO notifications (id, resource, type, platform_id, revision, created_at, updated_at, payload, correlation_id,
+                           paging_sequence, ready, labels)
+SELECT id, resource, type, platform_id, revision, created_at, updated_at, payload, correlation_id,
+       paging_sequence, ready, labels
+FROM notifications_unpartitioned;
+
+ALTER SEQUENCE notifications_revision_seq OWNED BY notifications.revision;
+ALTER SEQUENCE notifications_paging_sequence_seq OWNED BY notifications.paging_sequence;
+DROP TABLE notifications_unpartitioned;
+
+-- the notifications are broadcast after the existing ones have been moved
+CREATE TRIGGER notifications_broadcast
+  AFTER INSERT ON notifications
+  FOR EACH ROW EXECUTE PROCEDURE notify_sm();
+
+COMMIT;
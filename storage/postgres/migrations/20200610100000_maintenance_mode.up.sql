@@ -0,0 +1,14 @@
+BEGIN;
+
+-- the maintenance mode shared by all Service Manager instances is held in a single row
+CREATE TABLE maintenance_mode
+(
+  id         smallint    PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+  read_only  boolean     NOT NULL DEFAULT '0',
+  reason     text        NOT NULL DEFAULT '',
+  updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO maintenance_mode (id) VALUES (1);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- the changes of the configuration made at runtime hold the changed keys of each configuration section
+CREATE TABLE configuration_changes
+(
+  section    varchar(100) PRIMARY KEY,
+  changes    jsonb        NOT NULL DEFAULT '{}',
+  updated_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- the responses of the requests sent with an Idempotency-Key header are kept until the keys expire
+CREATE TABLE idempotency_keys
+(
+  user_name       varchar(255) NOT NULL,
+  idempotency_key varchar(255) NOT NULL,
+  request_hash    varchar(64)  NOT NULL,
+  completed       boolean      NOT NULL DEFAULT '0',
+  status_code     integer      NOT NULL DEFAULT 0,
+  headers         jsonb        NOT NULL DEFAULT '{}',
+  body            bytea,
+  expires_at      timestamptz  NOT NULL,
+  PRIMARY KEY (user_name, idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+COMMIT;
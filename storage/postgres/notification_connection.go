@@ -30,6 +30,14 @@ type notificationConnectionCreator interface {
 	NewConnection(eventCallback func(isRunning bool, err error)) notificationConnection.NotificationConnection
 }
 
+// NotificationConnectionCreatorFunc is an adapter that allows to use regular functions as notification connection creators
+type NotificationConnectionCreatorFunc func(eventCallback func(isRunning bool, err error)) notificationConnection.NotificationConnection
+
+// NewConnection allows NotificationConnectionCreatorFunc to act as a notification connection creator
+func (f NotificationConnectionCreatorFunc) NewConnection(eventCallback func(isRunning bool, err error)) notificationConnection.NotificationConnection {
+	return f(eventCallback)
+}
+
 type notificationConnectionCreatorImpl struct {
 	storageURI           string
 	skipSSLValidation    bool
@@ -44,24 +44,28 @@ type notificationStorage interface {
 }
 
 // NewNotificationStorage returns new notification storage
-func NewNotificationStorage(st storage.Storage) (*notificationStorageImpl, error) {
-	pgStorage, ok := st.(*Storage)
-	if !ok {
-		return nil, errors.New("expected notification storage to be Postgres")
+func NewNotificationStorage(st storage.Repository) (*notificationStorageImpl, error) {
+	if st == nil {
+		return nil, errors.New("notification storage requires a repository")
 	}
 	return &notificationStorageImpl{
-		storage: pgStorage,
+		storage: st,
 	}, nil
 }
 
 type notificationStorageImpl struct {
-	storage *Storage
+	storage storage.Repository
 }
 
 func (ns *notificationStorageImpl) GetLastRevision(ctx context.Context) (int64, error) {
+	pgStorage, ok := ns.storage.(*Storage)
+	if !ok {
+		// repositories which are not backed by PostgreSQL
+		return storage.LastNotificationRevision(ctx, ns.storage)
+	}
 	result := make([]*Notification, 0, 1)
 	sqlString := fmt.Sprintf("SELECT revision FROM %s ORDER BY revision DESC LIMIT 1", NotificationTable)
-	err := ns.storage.SelectContext(ctx, &result, sqlString)
+	err := pgStorage.SelectContext(ctx, &result, sqlString)
 	if err != nil {
 		return 0, fmt.Errorf("could not get last notification revision from db %v", err)
 	}
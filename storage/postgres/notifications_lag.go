@@ -0,0 +1,54 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// notificationsLagsQuery counts in one pass the notifications of each acknowledging platform, including the
+// notifications for all platforms, after the revision acknowledged by the platform
+var notificationsLagsQuery = fmt.Sprintf(`
+SELECT %[1]s.id AS platform_id, COUNT(%[2]s.revision) AS notifications_lag
+FROM %[1]s
+LEFT JOIN %[2]s
+  ON (%[2]s.platform_id = %[1]s.id OR %[2]s.platform_id IS NULL)
+  AND %[2]s.revision > %[1]s.acknowledged_notification_revision
+WHERE %[1]s.acknowledged_notification_revision > 0
+GROUP BY %[1]s.id`, PlatformTable, NotificationTable)
+
+type notificationsLag struct {
+	PlatformID string `db:"platform_id"`
+	Lag        int64  `db:"notifications_lag"`
+}
+
+// GetNotificationsLags returns the number of notifications after the acknowledged revision by the ids of the platforms
+// which acknowledge notifications
+func (ps *Storage) GetNotificationsLags(ctx context.Context) (map[string]int64, error) {
+	ps.checkOpen()
+
+	var rows []*notificationsLag
+	if err := ps.db.SelectContext(ctx, &rows, notificationsLagsQuery); err != nil {
+		return nil, fmt.Errorf("could not count notifications lags: %v", err)
+	}
+	lags := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		lags[row.PlatformID] = row.Lag
+	}
+	return lags, nil
+}
@@ -25,6 +25,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/gofrs/uuid"
+
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/Peripli/service-manager/pkg/util"
@@ -78,20 +80,36 @@ func NewNotificator(st storage.Storage, settings *storage.Settings) (*Notificato
 		return nil, err
 	}
 
+	return newNotificator(ns, connectionCreator, settings), nil
+}
+
+// NewNotificatorWithConnection returns new Notificator which receives the notification events through the connections
+// created by the given function instead of listening to PostgreSQL. It allows storages which emit the same events as
+// the notifications trigger to reuse the Notificator.
+func NewNotificatorWithConnection(repository storage.Repository, settings *storage.Settings, connectionCreator NotificationConnectionCreatorFunc) (*Notificator, error) {
+	ns, err := NewNotificationStorage(repository)
+	if err != nil {
+		return nil, err
+	}
+	return newNotificator(ns, connectionCreator, settings), nil
+}
+
+func newNotificator(ns notificationStorage, connectionCreator notificationConnectionCreator, settings *storage.Settings) *Notificator {
 	return &Notificator{
 		queueSize:       settings.Notification.QueuesSize,
 		connectionMutex: &sync.Mutex{},
 		consumersMutex:  &sync.Mutex{},
 		consumers: &consumers{
-			queues:    make(map[string][]storage.NotificationQueue),
-			platforms: make([]*types.Platform, 0),
+			queues:        make(map[string][]storage.NotificationQueue),
+			platforms:     make([]*types.Platform, 0),
+			subscriptions: make(map[string]*storage.NotificationSubscription),
 		},
 		storage:           ns,
 		connectionCreator: connectionCreator,
 		stopProcessing:    func() {},
 		lastKnownRevision: types.InvalidRevision,
 		dbPingInterval:    dbPingInterval,
-	}, nil
+	}
 }
 
 // Start starts the Notificator. It must not be called concurrently.
@@ -118,7 +136,7 @@ func (n *Notificator) Start(ctx context.Context, group *sync.WaitGroup) error {
 	return nil
 }
 
-func (n *Notificator) addConsumer(platform *types.Platform, queue storage.NotificationQueue) (int64, error) {
+func (n *Notificator) addConsumer(platform *types.Platform, queue storage.NotificationQueue, subscription *storage.NotificationSubscription) (int64, error) {
 	// must listen and add consumer under connectionMutex lock as UnregisterConsumer
 	// might stop notification processing if no other consumers are present
 	n.connectionMutex.Lock()
@@ -146,11 +164,11 @@ func (n *Notificator) addConsumer(platform *types.Platform, queue storage.Notifi
 	}
 	n.consumersMutex.Lock()
 	defer n.consumersMutex.Unlock()
-	n.consumers.Add(platform, queue)
+	n.consumers.Add(platform, queue, subscription)
 	return atomic.LoadInt64(&n.lastKnownRevision), nil
 }
 
-func (n *Notificator) RegisterConsumer(consumer *types.Platform, lastKnownRevision int64) (storage.NotificationQueue, int64, error) {
+func (n *Notificator) RegisterConsumer(consumer *types.Platform, lastKnownRevision int64, subscription *storage.NotificationSubscription) (storage.NotificationQueue, int64, error) {
 	if atomic.LoadInt32(&n.isConnected) == aFalse {
 		return nil, types.InvalidRevision, errors.New("cannot register consumer - Notificator is not running")
 	}
@@ -160,7 +178,7 @@ func (n *Notificator) RegisterConsumer(consumer *types.Platform, lastKnownRevisi
 	}
 
 	var lastKnownRevisionToSM int64
-	lastKnownRevisionToSM, err = n.addConsumer(consumer, queue)
+	lastKnownRevisionToSM, err = n.addConsumer(consumer, queue, subscription)
 	if err != nil {
 		return nil, types.InvalidRevision, err
 	}
@@ -180,13 +198,29 @@ func (n *Notificator) RegisterConsumer(consumer *types.Platform, lastKnownRevisi
 		return nil, types.InvalidRevision, err
 	}
 	var queueWithMissedNotifications storage.NotificationQueue
-	queueWithMissedNotifications, err = n.replaceQueueWithMissingNotificationsQueue(queue, lastKnownRevision, lastKnownRevisionToSM, consumer)
+	queueWithMissedNotifications, err = n.replaceQueueWithMissingNotificationsQueue(queue, lastKnownRevision, lastKnownRevisionToSM, consumer, subscription)
 	if err != nil {
 		return nil, types.InvalidRevision, err
 	}
 	return queueWithMissedNotifications, lastKnownRevisionToSM, nil
 }
 
+func (n *Notificator) RegisterSnapshotConsumer(consumer *types.Platform, snapshot storage.NotificationsSnapshotFunc, subscription *storage.NotificationSubscription) (storage.NotificationQueue, int64, error) {
+	queue, _, err := n.RegisterConsumer(consumer, types.InvalidRevision, subscription)
+	if err != nil {
+		return nil, types.InvalidRevision, err
+	}
+
+	queueWithSnapshot, snapshotRevision, err := n.replaceQueueWithSnapshotQueue(queue, consumer, snapshot, subscription)
+	if err != nil {
+		if errUnregisterConsumer := n.UnregisterConsumer(queue); errUnregisterConsumer != nil {
+			log.C(n.ctx).WithError(errUnregisterConsumer).Errorf("Could not unregister notification consumer %s", queue.ID())
+		}
+		return nil, types.InvalidRevision, err
+	}
+	return queueWithSnapshot, snapshotRevision, nil
+}
+
 func (n *Notificator) filterRecipients(recipients []*types.Platform, notification *types.Notification) []*types.Platform {
 	for _, filter := range n.notificationFilters {
 		recipients = filter(recipients, notification)
@@ -197,7 +231,7 @@ func (n *Notificator) filterRecipients(recipients []*types.Platform, notificatio
 	return recipients
 }
 
-func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.NotificationQueue, lastKnownRevision, lastKnownRevisionToSM int64, platform *types.Platform) (storage.NotificationQueue, error) {
+func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.NotificationQueue, lastKnownRevision, lastKnownRevisionToSM int64, platform *types.Platform, subscription *storage.NotificationSubscription) (storage.NotificationQueue, error) {
 	if _, err := n.storage.GetNotificationByRevision(n.ctx, lastKnownRevision); err != nil {
 		if err == util.ErrNotFoundInStorage {
 			log.C(n.ctx).WithError(err).Debugf("Notification with revision %d not found in storage", lastKnownRevision)
@@ -212,6 +246,9 @@ func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.No
 	}
 	filteredMissedNotification := make([]*types.Notification, 0, len(missedNotifications))
 	for _, notification := range missedNotifications {
+		if !subscription.Matches(notification) {
+			continue
+		}
 		recipients := n.filterRecipients([]*types.Platform{platform}, notification)
 		if len(recipients) != 0 {
 			filteredMissedNotification = append(filteredMissedNotification, notification)
@@ -253,6 +290,63 @@ func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.No
 	}
 }
 
+func (n *Notificator) replaceQueueWithSnapshotQueue(queue storage.NotificationQueue, platform *types.Platform, snapshot storage.NotificationsSnapshotFunc, subscription *storage.NotificationSubscription) (storage.NotificationQueue, int64, error) {
+	// the snapshot reflects exactly the notifications up to its revision, so only the later ones are sent after it
+	snapshotNotifications, snapshotRevision, err := snapshot(n.ctx, platform)
+	if err != nil {
+		return nil, types.InvalidRevision, fmt.Errorf("could not take notifications snapshot for platform %s: %v", platform.ID, err)
+	}
+	filteredSnapshotNotifications := make([]*types.Notification, 0, len(snapshotNotifications))
+	for _, notification := range snapshotNotifications {
+		if !subscription.Matches(notification) {
+			continue
+		}
+		recipients := n.filterRecipients([]*types.Platform{platform}, notification)
+		if len(recipients) != 0 {
+			notification.Revision = snapshotRevision
+			filteredSnapshotNotifications = append(filteredSnapshotNotifications, notification)
+		}
+	}
+	log.C(n.ctx).Debugf("Sending snapshot of %d notifications at revision %d to platform %s", len(filteredSnapshotNotifications), snapshotRevision, platform.ID)
+
+	snapshotEnd, err := newSnapshotEndNotification(platform.ID, snapshotRevision)
+	if err != nil {
+		return nil, types.InvalidRevision, err
+	}
+	notificationQueue, err := storage.NewNotificationQueue(len(filteredSnapshotNotifications) + 1 + n.queueSize)
+	if err != nil {
+		return nil, types.InvalidRevision, err
+	}
+	for _, notification := range append(filteredSnapshotNotifications, snapshotEnd) {
+		if err = notificationQueue.Enqueue(notification); err != nil {
+			return nil, types.InvalidRevision, err
+		}
+	}
+	queueWithSnapshot := &snapshotQueue{
+		NotificationQueue: notificationQueue,
+		revision:          snapshotRevision,
+	}
+
+	n.consumersMutex.Lock()
+	defer n.consumersMutex.Unlock()
+	for {
+		select {
+		case notification, ok := <-queue.Channel():
+			if !ok {
+				return nil, types.InvalidRevision, errors.New("notification queue has been closed")
+			}
+			if err = queueWithSnapshot.Enqueue(notification); err != nil {
+				return nil, types.InvalidRevision, err
+			}
+		default:
+			if err = n.consumers.ReplaceQueue(queue.ID(), queueWithSnapshot); err != nil {
+				return nil, types.InvalidRevision, err
+			}
+			return queueWithSnapshot, snapshotRevision, nil
+		}
+	}
+}
+
 func (n *Notificator) UnregisterConsumer(queue storage.NotificationQueue) error {
 	n.consumersMutex.Lock()
 	defer n.consumersMutex.Unlock()
@@ -401,6 +495,9 @@ func (n *Notificator) getRecipients(platformID string) []*types.Platform {
 func (n *Notificator) sendNotificationToPlatformConsumers(platformID string, platformConsumers []storage.NotificationQueue, notification *types.Notification) {
 	log.C(n.ctx).Debugf("Sending notification %s to %d consumers for platform %s", notification.ID, len(platformConsumers), platformID)
 	for _, consumer := range platformConsumers {
+		if !n.consumers.GetSubscription(consumer.ID()).Matches(notification) {
+			continue
+		}
 		if err := consumer.Enqueue(notification); err != nil {
 			log.C(n.ctx).WithError(err).Infof("Consumer %s notification queue returned error %v", consumer.ID(), err)
 			consumer.Close()
@@ -419,9 +516,42 @@ func (n *Notificator) stopConnection() {
 	}
 }
 
+// newSnapshotEndNotification returns the notification which tells the consumer that all notifications of the snapshot
+// have been sent
+func newSnapshotEndNotification(platformID string, revision int64) (*types.Notification, error) {
+	UUID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate GUID for notification: %s", err)
+	}
+	return &types.Notification{
+		Base: types.Base{
+			ID:    UUID.String(),
+			Ready: true,
+		},
+		Resource:   types.NotificationType,
+		Type:       types.SNAPSHOT_END,
+		PlatformID: platformID,
+		Revision:   revision,
+	}, nil
+}
+
+// snapshotQueue skips the notifications which are already reflected in the snapshot sent to the consumer
+type snapshotQueue struct {
+	storage.NotificationQueue
+	revision int64
+}
+
+func (q *snapshotQueue) Enqueue(notification *types.Notification) error {
+	if notification.Revision <= q.revision {
+		return nil
+	}
+	return q.NotificationQueue.Enqueue(notification)
+}
+
 type consumers struct {
-	queues    map[string][]storage.NotificationQueue
-	platforms []*types.Platform
+	queues        map[string][]storage.NotificationQueue
+	platforms     []*types.Platform
+	subscriptions map[string]*storage.NotificationSubscription
 }
 
 func (c *consumers) find(queueID string) (string, int) {
@@ -441,6 +571,10 @@ func (c *consumers) ReplaceQueue(queueID string, newQueue storage.NotificationQu
 		return fmt.Errorf("could not find consumer with id %s", queueID)
 	}
 	c.queues[platformID][queueIndex] = newQueue
+	if subscription, found := c.subscriptions[queueID]; found {
+		delete(c.subscriptions, queueID)
+		c.subscriptions[newQueue.ID()] = subscription
+	}
 	return nil
 }
 
@@ -450,6 +584,7 @@ func (c *consumers) Delete(queue storage.NotificationQueue) {
 		return
 	}
 	platformConsumers := c.queues[platformIDToDelete]
+	delete(c.subscriptions, queue.ID())
 	c.queues[platformIDToDelete] = append(platformConsumers[:queueIndex], platformConsumers[queueIndex+1:]...)
 
 	if len(c.queues[platformIDToDelete]) == 0 {
@@ -463,17 +598,21 @@ func (c *consumers) Delete(queue storage.NotificationQueue) {
 	}
 }
 
-func (c *consumers) Add(platform *types.Platform, queue storage.NotificationQueue) {
+func (c *consumers) Add(platform *types.Platform, queue storage.NotificationQueue, subscription *storage.NotificationSubscription) {
 	if len(c.queues[platform.ID]) == 0 {
 		c.platforms = append(c.platforms, platform)
 	}
 	c.queues[platform.ID] = append(c.queues[platform.ID], queue)
+	if subscription != nil {
+		c.subscriptions[queue.ID()] = subscription
+	}
 }
 
 func (c *consumers) Clear() map[string][]storage.NotificationQueue {
 	allQueues := c.queues
 	c.queues = make(map[string][]storage.NotificationQueue)
 	c.platforms = make([]*types.Platform, 0)
+	c.subscriptions = make(map[string]*storage.NotificationSubscription)
 	return allQueues
 }
 
@@ -490,6 +629,10 @@ func (c *consumers) GetPlatform(platformID string) *types.Platform {
 	return nil
 }
 
+func (c *consumers) GetSubscription(queueID string) *storage.NotificationSubscription {
+	return c.subscriptions[queueID]
+}
+
 func (c *consumers) GetQueuesForPlatform(platformID string) []storage.NotificationQueue {
 	return c.queues[platformID]
 }
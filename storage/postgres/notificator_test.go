@@ -65,7 +65,7 @@ var _ = Describe("Notificator", func() {
 	expectedError := errors.New("*Expected*")
 
 	expectRegisterConsumerFail := func(errorMessage string, revision int64) {
-		q, smRevision, err := testNotificator.RegisterConsumer(defaultPlatform, revision)
+		q, smRevision, err := testNotificator.RegisterConsumer(defaultPlatform, revision, nil)
 		Expect(q).To(BeNil())
 		Expect(smRevision).To(Equal(types.InvalidRevision))
 		Expect(err).To(HaveOccurred())
@@ -73,7 +73,7 @@ var _ = Describe("Notificator", func() {
 	}
 
 	expectRegisterConsumerSuccess := func(platform *types.Platform, revision int64) storage.NotificationQueue {
-		q, smRevision, err := testNotificator.RegisterConsumer(platform, revision)
+		q, smRevision, err := testNotificator.RegisterConsumer(platform, revision, nil)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(smRevision).To(Equal(defaultLastRevision))
 		Expect(q).ToNot(BeNil())
@@ -95,8 +95,9 @@ var _ = Describe("Notificator", func() {
 			connectionMutex: &sync.Mutex{},
 			consumersMutex:  &sync.Mutex{},
 			consumers: &consumers{
-				queues:    make(map[string][]storage.NotificationQueue),
-				platforms: make([]*types.Platform, 0),
+				queues:        make(map[string][]storage.NotificationQueue),
+				platforms:     make([]*types.Platform, 0),
+				subscriptions: make(map[string]*storage.NotificationSubscription),
 			},
 			storage:           fakeNotificationStorage,
 			connectionCreator: fakeConnectionCreator,
@@ -468,6 +469,107 @@ var _ = Describe("Notificator", func() {
 
 	})
 
+	Describe("RegisterSnapshotConsumer", func() {
+		var snapshotNotifications []*types.Notification
+		var snapshotErr error
+
+		snapshot := func(ctx context.Context, platform *types.Platform) ([]*types.Notification, int64, error) {
+			Expect(platform).To(Equal(defaultPlatform))
+			return snapshotNotifications, defaultLastRevision, snapshotErr
+		}
+
+		expectSnapshotEnd := func(queueChannel <-chan *types.Notification) {
+			snapshotEnd := <-queueChannel
+			Expect(snapshotEnd.Type).To(Equal(types.SNAPSHOT_END))
+			Expect(snapshotEnd.PlatformID).To(Equal(defaultPlatform.ID))
+			Expect(snapshotEnd.Revision).To(Equal(defaultLastRevision))
+		}
+
+		BeforeEach(func() {
+			snapshotNotifications = []*types.Notification{createNotification(defaultPlatform.ID), createNotification("")}
+			snapshotErr = nil
+			Expect(testNotificator.Start(ctx, wg)).ToNot(HaveOccurred())
+			runningFunc(true, nil)
+		})
+
+		Context("When snapshot fails", func() {
+			It("Should return error and unregister the consumer", func() {
+				snapshotErr = expectedError
+				q, smRevision, err := testNotificator.RegisterSnapshotConsumer(defaultPlatform, snapshot, nil)
+				Expect(q).To(BeNil())
+				Expect(smRevision).To(Equal(types.InvalidRevision))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expectedError.Error()))
+				notificator := testNotificator.(*Notificator)
+				notificator.consumersMutex.Lock()
+				defer notificator.consumersMutex.Unlock()
+				Expect(notificator.consumers.Len()).To(Equal(0))
+			})
+		})
+
+		Context("When snapshot is taken", func() {
+			It("Should send the snapshot with the snapshot revision followed by its end and the new notifications", func() {
+				queue, smRevision, err := testNotificator.RegisterSnapshotConsumer(defaultPlatform, snapshot, nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(smRevision).To(Equal(defaultLastRevision))
+
+				queueChannel := queue.Channel()
+				for _, expectedNotification := range snapshotNotifications {
+					receivedNotification := <-queueChannel
+					Expect(receivedNotification).To(Equal(expectedNotification))
+					Expect(receivedNotification.Revision).To(Equal(defaultLastRevision))
+				}
+				expectSnapshotEnd(queueChannel)
+
+				n := createNotification(defaultPlatform.ID)
+				fakeNotificationStorage.GetNotificationReturns(n, nil)
+				notificationChannel <- &pq.Notification{
+					Extra: createNotificationPayload(defaultPlatform.ID, n.ID),
+				}
+				Expect(<-queueChannel).To(Equal(n))
+			})
+
+			It("Should skip the notifications included in the snapshot", func() {
+				queue, _, err := testNotificator.RegisterSnapshotConsumer(defaultPlatform, snapshot, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				oldNotification := createNotification(defaultPlatform.ID)
+				oldNotification.Revision = defaultLastRevision
+				newNotification := createNotification(defaultPlatform.ID)
+				fakeNotificationStorage.GetNotificationReturnsOnCall(0, oldNotification, nil)
+				fakeNotificationStorage.GetNotificationReturnsOnCall(1, newNotification, nil)
+				notificationChannel <- &pq.Notification{
+					Extra: createNotificationPayload(defaultPlatform.ID, oldNotification.ID),
+				}
+				notificationChannel <- &pq.Notification{
+					Extra: createNotificationPayload(defaultPlatform.ID, newNotification.ID),
+				}
+
+				queueChannel := queue.Channel()
+				for range snapshotNotifications {
+					<-queueChannel
+				}
+				expectSnapshotEnd(queueChannel)
+				Expect(<-queueChannel).To(Equal(newNotification))
+			})
+
+			It("Should apply the registered filters to the snapshot", func() {
+				testNotificator.RegisterFilter(func(recipients []*types.Platform, notification *types.Notification) []*types.Platform {
+					if notification.PlatformID == "" {
+						return nil
+					}
+					return recipients
+				})
+				queue, _, err := testNotificator.RegisterSnapshotConsumer(defaultPlatform, snapshot, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(<-queue.Channel()).To(Equal(snapshotNotifications[0]))
+				expectSnapshotEnd(queue.Channel())
+				Consistently(queue.Channel()).ShouldNot(Receive())
+			})
+		})
+	})
+
 	Describe("Process notifications", func() {
 
 		BeforeEach(func() {
@@ -487,6 +589,32 @@ var _ = Describe("Notificator", func() {
 			})
 		})
 
+		Context("When consumer is subscribed to a resource type", func() {
+			It("Should receive only notifications for this resource type", func() {
+				subscribedQueue, _, err := testNotificator.RegisterConsumer(defaultPlatform, types.InvalidRevision, &storage.NotificationSubscription{
+					ResourceTypes: []types.ObjectType{types.VisibilityType},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				brokerNotification := createNotification(defaultPlatform.ID)
+				brokerNotification.Resource = types.ServiceBrokerType
+				visibilityNotification := createNotification(defaultPlatform.ID)
+				visibilityNotification.Resource = types.VisibilityType
+				fakeNotificationStorage.GetNotificationReturnsOnCall(0, brokerNotification, nil)
+				fakeNotificationStorage.GetNotificationReturnsOnCall(1, visibilityNotification, nil)
+				notificationChannel <- &pq.Notification{
+					Extra: createNotificationPayload(defaultPlatform.ID, brokerNotification.ID),
+				}
+				expectReceivedNotification(brokerNotification, queue)
+
+				notificationChannel <- &pq.Notification{
+					Extra: createNotificationPayload(defaultPlatform.ID, visibilityNotification.ID),
+				}
+				expectReceivedNotification(visibilityNotification, queue)
+				expectReceivedNotification(visibilityNotification, subscribedQueue)
+			})
+		})
+
 		Context("When notification cannot be fetched from db", func() {
 			fetchNotificationFromDBFail := func(platformID string) {
 				fakeNotificationStorage.GetNotificationReturns(nil, expectedError)
@@ -0,0 +1,222 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// partitionsLockIndex is the index of the advisory lock which serializes the changes of the partitions
+const partitionsLockIndex = 112
+
+// timePartitioning describes the partitioning of a table by the creation time of its rows
+type timePartitioning struct {
+	// interval is the period of creation time of the rows held by each partition
+	interval time.Duration
+	// nameLayout is the layout of the start of the period in the name of the partition
+	nameLayout string
+	// keep is the SQL condition matching the rows which are kept beyond the expiry time, given as $1, depending on
+	// their state. The kept rows of the dropped partitions are moved to the default partition.
+	keep string
+}
+
+// timePartitionedTables are the tables partitioned by the creation time of their rows. Each of them has a default
+// partition holding the rows which are not held by any of the other partitions. Their primary key is (id, created_at),
+// as it has to include the partition key, so the uniqueness of their ids is not enforced by the database. The ids are
+// random UUIDs generated by the Service Manager, so the objects of these tables cannot be created with chosen ids.
+var timePartitionedTables = map[string]timePartitioning{
+	OperationTable:    {interval: 24 * time.Hour, nameLayout: "20060102", keep: keptOperationsCondition},
+	NotificationTable: {interval: time.Hour, nameLayout: "2006010215"},
+}
+
+// keptOperationsCondition matches the operations which have been updated after the expiry time and the internal
+// operations which are still processed, i.e. the ones which have neither succeeded nor failed without being rescheduled
+// or scheduled for deletion
+var keptOperationsCondition = fmt.Sprintf("updated_at >= $1 OR (platform_id = %s AND state <> %s AND (state <> %s OR reschedule OR deletion_scheduled <> %s))",
+	pq.QuoteLiteral(types.SMPlatform), pq.QuoteLiteral(string(types.SUCCEEDED)), pq.QuoteLiteral(string(types.FAILED)),
+	pq.QuoteLiteral("0001-01-01 00:00:00+00"))
+
+func partitionNamePrefix(table string) string {
+	return table + "_p"
+}
+
+func defaultPartitionName(table string) string {
+	return table + "_default"
+}
+
+// CreatePartitions creates the missing partitions which hold the objects of the type created in the given period. The
+// objects of the period which have been stored in the default partition are moved to the created partitions.
+func (ps *Storage) CreatePartitions(ctx context.Context, objectType types.ObjectType, from, to time.Time) error {
+	ps.checkOpen()
+	table, partitioning, err := ps.partitionedTable(objectType)
+	if err != nil {
+		return err
+	}
+	for start := from.UTC().Truncate(partitioning.interval); start.Before(to); start = start.Add(partitioning.interval) {
+		if err := ps.createPartition(ctx, table, partitioning, start); err != nil {
+			return fmt.Errorf("could not create partition of %s from %s: %s", table, start, err)
+		}
+	}
+	return nil
+}
+
+// DropPartitions drops the partitions which hold only objects of the type created before the given time. The objects
+// created before the given time are deleted from the default partition. The objects which are kept depending on their
+// state are moved from the dropped partitions to the default partition, from which they are deleted once they expire.
+func (ps *Storage) DropPartitions(ctx context.Context, objectType types.ObjectType, before time.Time) error {
+	ps.checkOpen()
+	table, partitioning, err := ps.partitionedTable(objectType)
+	if err != nil {
+		return err
+	}
+	return ps.inLockedTransaction(ctx, partitionsLockIndex, func(tx *sqlx.Tx) error {
+		var partitions []string
+		if err := tx.SelectContext(ctx, &partitions, `SELECT child.relname FROM pg_inherits
+			JOIN pg_class parent ON pg_inherits.inhparent = parent.oid
+			JOIN pg_class child ON pg_inherits.inhrelid = child.oid
+			WHERE parent.relname = $1`, table); err != nil {
+			return err
+		}
+		prefix := partitionNamePrefix(table)
+		for _, partition := range partitions {
+			if !strings.HasPrefix(partition, prefix) {
+				continue
+			}
+			start, err := time.Parse(partitioning.nameLayout, strings.TrimPrefix(partition, prefix))
+			if err != nil || start.Add(partitioning.interval).After(before) {
+				continue
+			}
+			log.C(ctx).Infof("Dropping partition %s of %s holding the rows created before %s", partition, table, start.Add(partitioning.interval))
+			if len(partitioning.keep) != 0 {
+				if err := keepRows(ctx, tx, table, partition, partitioning.keep, before); err != nil {
+					return fmt.Errorf("could not keep rows of partition %s of %s: %s", partition, table, err)
+				}
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", pq.QuoteIdentifier(partition))); err != nil {
+				return fmt.Errorf("could not drop partition %s of %s: %s", partition, table, err)
+			}
+		}
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE created_at < $1", pq.QuoteIdentifier(defaultPartitionName(table)))
+		if len(partitioning.keep) != 0 {
+			deleteQuery += fmt.Sprintf(" AND NOT (%s)", partitioning.keep)
+		}
+		if _, err := tx.ExecContext(ctx, deleteQuery, before); err != nil {
+			return fmt.Errorf("could not delete rows of %s created before %s: %s", table, before, err)
+		}
+		return nil
+	})
+}
+
+// keepRows detaches the partition and moves its rows which match the keep condition to the default partition, as no
+// other partition holds the period of the detached partition
+func keepRows(ctx context.Context, tx *sqlx.Tx, table, partition, keep string, before time.Time) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", pq.QuoteIdentifier(table), pq.QuoteIdentifier(partition))); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s SELECT * FROM %s WHERE %s",
+		pq.QuoteIdentifier(table), pq.QuoteIdentifier(partition), keep), before)
+	if err != nil {
+		return err
+	}
+	if kept, err := result.RowsAffected(); err == nil && kept > 0 {
+		log.C(ctx).Infof("Moved %d rows of partition %s of %s to the default partition", kept, partition, table)
+	}
+	return nil
+}
+
+// createPartition creates the partition of the table starting at the given time unless it exists
+func (ps *Storage) createPartition(ctx context.Context, table string, partitioning timePartitioning, start time.Time) error {
+	end := start.Add(partitioning.interval)
+	partition := partitionNamePrefix(table) + start.Format(partitioning.nameLayout)
+	return ps.inLockedTransaction(ctx, partitionsLockIndex, func(tx *sqlx.Tx) error {
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, "SELECT to_regclass($1) IS NOT NULL", partition); err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+
+		log.C(ctx).Infof("Creating partition %s of %s holding the rows created from %s to %s", partition, table, start, end)
+		// the rows of the period stored in the default partition have to be moved before attaching the partition
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)",
+			pq.QuoteIdentifier(partition), pq.QuoteIdentifier(table))); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"WITH moved AS (DELETE FROM %s WHERE created_at >= $1 AND created_at < $2 RETURNING *) INSERT INTO %s SELECT * FROM moved",
+			pq.QuoteIdentifier(defaultPartitionName(table)), pq.QuoteIdentifier(partition)), start, end); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (%s) TO (%s)",
+			pq.QuoteIdentifier(table), pq.QuoteIdentifier(partition),
+			pq.QuoteLiteral(start.Format(time.RFC3339)), pq.QuoteLiteral(end.Format(time.RFC3339))))
+		return err
+	})
+}
+
+// partitionedTable returns the table of the objects of the type together with its partitioning
+func (ps *Storage) partitionedTable(objectType types.ObjectType) (string, timePartitioning, error) {
+	entity, err := ps.scheme.provide(objectType)
+	if err != nil {
+		return "", timePartitioning{}, err
+	}
+	partitioning, found := timePartitionedTables[entity.TableName()]
+	if !found {
+		return "", timePartitioning{}, &util.UnsupportedQueryError{Message: fmt.Sprintf("%s are not partitioned by creation time", objectType)}
+	}
+	return entity.TableName(), partitioning, nil
+}
+
+// inLockedTransaction runs the function in a transaction holding the advisory lock with the given index, which
+// serializes the transactions between the Service Manager instances
+func (ps *Storage) inLockedTransaction(ctx context.Context, lockIndex int, f func(tx *sqlx.Tx) error) error {
+	tx, err := ps.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	ok := false
+	defer func() {
+		if !ok {
+			if txError := tx.Rollback(); txError != nil && txError != sql.ErrTxDone {
+				log.C(ctx).Error("Could not rollback transaction", txError)
+			}
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", lockIndex); err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	ok = true
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Partitions", func() {
+	var s *Storage
+	var mock sqlmock.Sqlmock
+
+	BeforeEach(func() {
+		var mockdb *sql.DB
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20200624100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		encryptionKey := make([]byte, 32)
+		_, err = rand.Read(encryptionKey)
+		Expect(err).ToNot(HaveOccurred())
+		settings := storage.DefaultSettings()
+		settings.EncryptionKey = string(encryptionKey)
+		settings.URI = "sqlmock://sqlmock"
+		Expect(s.Open(settings)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		s.Close()
+	})
+
+	expectPartitionsTransaction := func() {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WithArgs(partitionsLockIndex).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	Describe("CreatePartitions", func() {
+		It("creates the missing partitions of the period", func() {
+			from := time.Date(2020, 6, 3, 10, 30, 0, 0, time.UTC)
+
+			expectPartitionsTransaction()
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass($1) IS NOT NULL")).WithArgs("operations_p20200603").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mock.ExpectCommit()
+
+			expectPartitionsTransaction()
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass($1) IS NOT NULL")).WithArgs("operations_p20200604").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE "operations_p20200604" (LIKE "operations" INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "operations_default" WHERE created_at >= $1 AND created_at < $2 RETURNING *) INSERT INTO "operations_p20200604"`)).
+				WithArgs(time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC), time.Date(2020, 6, 5, 0, 0, 0, 0, time.UTC)).
+				WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "operations" ATTACH PARTITION "operations_p20200604" FOR VALUES FROM ('2020-06-04T00:00:00Z') TO ('2020-06-05T00:00:00Z')`)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+
+			Expect(s.CreatePartitions(context.Background(), types.OperationType, from, from.Add(24*time.Hour))).To(Succeed())
+		})
+
+		It("fails for objects which are not partitioned", func() {
+			err := s.CreatePartitions(context.Background(), types.PlatformType, time.Now(), time.Now().Add(time.Hour))
+			Expect(err).To(BeAssignableToTypeOf(&util.UnsupportedQueryError{}))
+		})
+	})
+
+	Describe("DropPartitions", func() {
+		It("keeps the operations which are updated after the expiry time or are still processed", func() {
+			Expect(keptOperationsCondition).To(Equal("updated_at >= $1 OR (platform_id = 'service-manager' AND state <> 'succeeded' AND " +
+				"(state <> 'failed' OR reschedule OR deletion_scheduled <> '0001-01-01 00:00:00+00'))"))
+		})
+
+		It("drops the partitions holding only notifications created before the given time", func() {
+			before := time.Date(2020, 6, 3, 10, 30, 0, 0, time.UTC)
+
+			expectPartitionsTransaction()
+			mock.ExpectQuery("SELECT child.relname FROM pg_inherits").WithArgs("notifications").
+				WillReturnRows(sqlmock.NewRows([]string{"relname"}).
+					AddRow("notifications_default").
+					AddRow("notifications_p2020060309").
+					AddRow("notifications_p2020060310").
+					AddRow("notifications_p2020060311"))
+			mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE "notifications_p2020060309"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "notifications_default" WHERE created_at < $1`)).WithArgs(before).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			Expect(s.DropPartitions(context.Background(), types.NotificationType, before)).To(Succeed())
+		})
+
+		It("drops the partitions of operations after moving the kept operations to the default partition", func() {
+			before := time.Date(2020, 6, 3, 10, 30, 0, 0, time.UTC)
+
+			expectPartitionsTransaction()
+			mock.ExpectQuery("SELECT child.relname FROM pg_inherits").WithArgs("operations").
+				WillReturnRows(sqlmock.NewRows([]string{"relname"}).
+					AddRow("operations_default").
+					AddRow("operations_p20200601").
+					AddRow("operations_p20200602").
+					AddRow("operations_p20200603"))
+			for _, partition := range []string{"operations_p20200601", "operations_p20200602"} {
+				mock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "operations" DETACH PARTITION "` + partition + `"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "operations" SELECT * FROM "` + partition + `" WHERE ` + keptOperationsCondition)).
+					WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE "` + partition + `"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "operations_default" WHERE created_at < $1 AND NOT (` + keptOperationsCondition + `)`)).
+				WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectCommit()
+
+			Expect(s.DropPartitions(context.Background(), types.OperationType, before)).To(Succeed())
+		})
+	})
+})
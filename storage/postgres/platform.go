@@ -38,6 +38,14 @@ type Platform struct {
 	Integrity   []byte         `db:"integrity"`
 	Active      bool           `db:"active"`
 	LastActive  time.Time      `db:"last_active"`
+
+	OldUsername              string    `db:"old_username"`
+	OldPassword              string    `db:"old_password"`
+	OldCredentialsExpireAt   time.Time `db:"old_credentials_expire_at"`
+	OldCredentialsLastUsedAt time.Time `db:"old_credentials_last_used_at"`
+	CredentialsLastUsedAt    time.Time `db:"credentials_last_used_at"`
+
+	AcknowledgedNotificationRevision int64 `db:"acknowledged_notification_revision"`
 }
 
 func (p *Platform) FromObject(object types.Object) (storage.Entity, error) {
@@ -58,6 +66,12 @@ func (p *Platform) FromObject(object types.Object) (storage.Entity, error) {
 		Description: toNullString(platform.Description),
 		Active:      platform.Active,
 		LastActive:  platform.LastActive,
+
+		OldCredentialsExpireAt:   platform.OldCredentialsExpireAt,
+		OldCredentialsLastUsedAt: platform.OldCredentialsLastUsedAt,
+		CredentialsLastUsedAt:    platform.CredentialsLastUsedAt,
+
+		AcknowledgedNotificationRevision: platform.AcknowledgedNotificationRevision,
 	}
 
 	if platform.Description != "" {
@@ -68,11 +82,15 @@ func (p *Platform) FromObject(object types.Object) (storage.Entity, error) {
 		result.Password = platform.Credentials.Basic.Password
 		result.Integrity = platform.Credentials.Integrity
 	}
+	if platform.OldCredentials != nil && platform.OldCredentials.Basic != nil {
+		result.OldUsername = platform.OldCredentials.Basic.Username
+		result.OldPassword = platform.OldCredentials.Basic.Password
+	}
 	return result, nil
 }
 
 func (p *Platform) ToObject() (types.Object, error) {
-	return &types.Platform{
+	platform := &types.Platform{
 		Base: types.Base{
 			ID:             p.ID,
 			CreatedAt:      p.CreatedAt,
@@ -92,5 +110,20 @@ func (p *Platform) ToObject() (types.Object, error) {
 		},
 		Active:     p.Active,
 		LastActive: p.LastActive,
-	}, nil
+
+		OldCredentialsExpireAt:   p.OldCredentialsExpireAt,
+		OldCredentialsLastUsedAt: p.OldCredentialsLastUsedAt,
+		CredentialsLastUsedAt:    p.CredentialsLastUsedAt,
+
+		AcknowledgedNotificationRevision: p.AcknowledgedNotificationRevision,
+	}
+	if p.OldUsername != "" {
+		platform.OldCredentials = &types.Credentials{
+			Basic: &types.Basic{
+				Username: p.OldUsername,
+				Password: p.OldPassword,
+			},
+		}
+	}
+	return platform, nil
 }
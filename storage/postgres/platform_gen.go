@@ -4,53 +4,22 @@ package postgres
 
 import (
 	"github.com/Peripli/service-manager/pkg/types"
-	"github.com/Peripli/service-manager/storage"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
-
-	"database/sql"
-	"time"
 )
 
-var _ PostgresEntity = &Platform{}
+var _ LabeledEntity = &Platform{}
 
 const PlatformTable = "platforms"
 
-func (*Platform) LabelEntity() PostgresLabel {
-	return &PlatformLabel{}
-}
-
 func (*Platform) TableName() string {
 	return PlatformTable
 }
 
-func (e *Platform) NewLabel(id, key, value string) storage.Label {
-	now := pq.NullTime{
-		Time:  time.Now(),
-		Valid: true,
-	}
-	return &PlatformLabel{
-		BaseLabelEntity: BaseLabelEntity{
-			ID:        sql.NullString{String: id, Valid: id != ""},
-			Key:       sql.NullString{String: key, Valid: key != ""},
-			Val:       sql.NullString{String: value, Valid: value != ""},
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		PlatformID: sql.NullString{String: e.ID, Valid: e.ID != ""},
-	}
-}
-
 func (e *Platform) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
-	rowCreator := func() EntityLabelRow {
-		return &struct {
-			*Platform
-			PlatformLabel `db:"platform_labels"`
-		}{}
-	}
-	result := &types.Platforms{
-		Platforms: make([]*types.Platform, 0),
+	rowCreator := func() PostgresEntity {
+		return &Platform{}
 	}
+	result := e.NewList()
 	err := rowsToList(rows, rowCreator, result)
 	if err != nil {
 		return nil, err
@@ -58,15 +27,8 @@ func (e *Platform) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
 	return result, nil
 }
 
-type PlatformLabel struct {
-	BaseLabelEntity
-	PlatformID sql.NullString `db:"platform_id"`
-}
-
-func (el PlatformLabel) LabelsTableName() string {
-	return "platform_labels"
-}
-
-func (el PlatformLabel) ReferenceColumn() string {
-	return "platform_id"
+func (*Platform) NewList() types.ObjectList {
+	return &types.Platforms{
+		Platforms: make([]*types.Platform, 0),
+	}
 }
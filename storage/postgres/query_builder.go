@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -20,75 +19,50 @@ import (
 const PrimaryKeyColumn = "id"
 
 const CountQueryTemplate = `
-SELECT COUNT(DISTINCT {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}})
+SELECT COUNT(*)
 FROM {{.ENTITY_TABLE}}
-	{{if .hasLabelCriteria}}
-	{{.JOIN}} {{.LABELS_TABLE}}
-		ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
-	{{end}}
 {{.WHERE}}
 {{.FOR_UPDATE_OF}}
 {{.LIMIT}};`
 
 const SelectQueryTemplate = `
-{{if or .hasFieldCriteria .hasLabelCriteria}}
-WITH matching_resources as (SELECT DISTINCT {{.ENTITY_TABLE}}.paging_sequence
-							FROM {{.ENTITY_TABLE}}
-							{{if .hasLabelCriteria}}
-							{{.JOIN}} {{.LABELS_TABLE}} 
-								ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
-							{{end}}
-							{{.WHERE}}
-							{{.ORDER_BY_SEQUENCE}}
-							{{.LIMIT}})
-{{end}}
-SELECT 
-{{.ENTITY_TABLE}}.*,
-{{.LABELS_TABLE}}.id         "{{.LABELS_TABLE}}.id",
-{{.LABELS_TABLE}}.key        "{{.LABELS_TABLE}}.key",
-{{.LABELS_TABLE}}.val        "{{.LABELS_TABLE}}.val",
-{{.LABELS_TABLE}}.created_at "{{.LABELS_TABLE}}.created_at",
-{{.LABELS_TABLE}}.updated_at "{{.LABELS_TABLE}}.updated_at",
-{{.LABELS_TABLE}}.{{.REF_COLUMN}} "{{.LABELS_TABLE}}.{{.REF_COLUMN}}" 
+SELECT *
 FROM {{.ENTITY_TABLE}}
-	{{.JOIN}} {{.LABELS_TABLE}}
-		ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
-{{if or .hasFieldCriteria .hasLabelCriteria}}
-WHERE {{.ENTITY_TABLE}}.paging_sequence IN 
-	(SELECT matching_resources.paging_sequence FROM matching_resources)
-{{end}}
+{{.WHERE}}
 {{.ORDER_BY}}
+{{.LIMIT}}
 {{.FOR_UPDATE_OF}};`
 
 const SelectNoLabelsQueryTemplate = `
-{{if .hasFieldCriteria}}
-WITH matching_resources as (SELECT DISTINCT {{.ENTITY_TABLE}}.paging_sequence
-							FROM {{.ENTITY_TABLE}}
-							{{.WHERE}}
-							{{.ORDER_BY_SEQUENCE}}
-							{{.LIMIT}})
-{{end}}
-SELECT *
+SELECT {{.COLUMNS}}
 FROM {{.ENTITY_TABLE}}
-{{if .hasFieldCriteria}}
-WHERE {{.ENTITY_TABLE}}.paging_sequence IN 
-	(SELECT matching_resources.paging_sequence FROM matching_resources)
-{{end}}
+{{.WHERE}}
 {{.ORDER_BY}}
+{{.LIMIT}}
 {{.FOR_UPDATE_OF}};`
 
+const LabelValuesQueryTemplate = `
+SELECT label.key, label_value.value, COUNT(*) AS count
+FROM {{.ENTITY_TABLE}}, jsonb_each({{.ENTITY_TABLE}}.labels) AS label(key, label_values),
+     jsonb_array_elements_text(label.label_values) AS label_value(value)
+{{.WHERE}}
+GROUP BY label.key, label_value.value
+ORDER BY label.key, label_value.value;`
+
+// SearchQueryTemplate selects the ids of the entities matching a full-text search ordered by rank. The search text is
+// the first query parameter, as it is used to rank the matches before the criteria are applied.
+const SearchQueryTemplate = `
+SELECT {{.ENTITY_TABLE}}.id, ts_rank(search_document.document, plainto_tsquery('simple', ?)) AS rank
+FROM {{.ENTITY_TABLE}}
+JOIN search_documents AS search_document
+  ON search_document.resource_type = '{{.ENTITY_TABLE}}' AND search_document.resource_id = {{.ENTITY_TABLE}}.id
+{{.WHERE}}
+ORDER BY rank DESC, {{.ENTITY_TABLE}}.id
+{{.LIMIT}};`
+
 const DeleteQueryTemplate = `
 DELETE FROM {{.ENTITY_TABLE}}
-{{if .hasLabelCriteria}}
-	USING (SELECT {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}}
-			FROM {{.ENTITY_TABLE}}
-				{{.JOIN}} {{.LABELS_TABLE}}
-					ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
-			{{.WHERE}}) t
-	WHERE {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = t.{{.PRIMARY_KEY}}
-{{else if .hasFieldCriteria}}
-	{{.WHERE}}
-{{end}}
+{{.WHERE}}
 {{.RETURNING}};`
 
 // QueryBuilder is used to construct new queries. It is safe for concurrent usage
@@ -105,23 +79,21 @@ func NewQueryBuilder(db pgDB) *QueryBuilder {
 
 // NewQuery constructs new queries for the current query builder db
 func (qb *QueryBuilder) NewQuery(entity PostgresEntity) *pgQuery {
+	_, hasLabels := entity.(LabeledEntity)
 	return &pgQuery{
-		labelEntity:       entity.LabelEntity(),
-		entityTableName:   entity.TableName(),
-		entityTags:        getDBTags(entity, nil),
-		labelEntityTags:   getDBTags(entity.LabelEntity(), nil),
-		db:                qb.db,
-		fieldsWhereClause: &whereClauseTree{},
-		labelsWhereClause: &whereClauseTree{
-			sqlBuilder: &treeSqlBuilder{
-				buildSQL: func(childrenSQL []string) string {
-					return fmt.Sprintf("(%s IN (%s))", entity.LabelEntity().ReferenceColumn(), strings.Join(childrenSQL, fmt.Sprintf(" %s ", INTERSECT)))
-				},
-			},
-		},
+		hasLabels:       hasLabels,
+		entityTableName: entity.TableName(),
+		entityTags:      getDBTags(entity, isLabelsColumn),
+		db:              qb.db,
+		whereClause:     &whereClauseTree{},
 	}
 }
 
+// isLabelsColumn excludes the labels column from the entity columns as the labels are queried only by label queries
+func isLabelsColumn(tagValue string) bool {
+	return tagValue == LabelsColumn
+}
+
 type orderRule struct {
 	field     string
 	orderType query.OrderType
@@ -129,23 +101,22 @@ type orderRule struct {
 
 // pgQuery is used to construct postgres queries. It should be constructed only via the query builder. It is not safe for concurrent use.
 type pgQuery struct {
-	db              pgDB
-	labelEntity     PostgresLabel
-	entityTags      []tagType
-	labelEntityTags []tagType
+	db         pgDB
+	hasLabels  bool
+	entityTags []tagType
 
 	queryParams []interface{}
 
 	orderByFields   []orderRule
+	pageAfter       []string
 	hasLock         bool
 	limit           string
 	returningFields []string
 	entityTableName string
 
-	fieldsWhereClause *whereClauseTree
-	labelsWhereClause *whereClauseTree
-	shouldRebind      bool
-	err               error
+	whereClause  *whereClauseTree
+	shouldRebind bool
+	err          error
 }
 
 func (pq *pgQuery) List(ctx context.Context) (*sqlx.Rows, error) {
@@ -156,10 +127,8 @@ func (pq *pgQuery) List(ctx context.Context) (*sqlx.Rows, error) {
 	return pq.db.QueryxContext(ctx, q, pq.queryParams...)
 }
 
+// ListNoLabels lists the entities without selecting their labels. The label queries are applied to the entities.
 func (pq *pgQuery) ListNoLabels(ctx context.Context) (*sqlx.Rows, error) {
-	if !pq.labelsWhereClause.isEmpty() {
-		return nil, errors.New("label queries are not supported by ListNoLabels")
-	}
 	q, err := pq.resolveQueryTemplate(ctx, SelectNoLabelsQueryTemplate)
 	if err != nil {
 		return nil, err
@@ -179,6 +148,35 @@ func (pq *pgQuery) Count(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// LabelValues counts the entities having each of the label values. The criteria are applied to the entities.
+func (pq *pgQuery) LabelValues(ctx context.Context) (*sqlx.Rows, error) {
+	if !pq.hasLabels {
+		return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("labels are not supported for %s", pq.entityTableName)}
+	}
+	q, err := pq.resolveQueryTemplate(ctx, LabelValuesQueryTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return pq.db.QueryxContext(ctx, q, pq.queryParams...)
+}
+
+// Search lists the ids of the entities which match the full-text search text together with the rank of the match
+func (pq *pgQuery) Search(ctx context.Context, text string) (*sqlx.Rows, error) {
+	if pq.err != nil {
+		return nil, pq.err
+	}
+	pq.queryParams = append(pq.queryParams, text)
+	pq.whereClause.children = append(pq.whereClause.children, &whereClauseTree{
+		sql:       "search_document.document @@ plainto_tsquery('simple', ?)",
+		sqlParams: []interface{}{text},
+	})
+	q, err := pq.resolveQueryTemplate(ctx, SearchQueryTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return pq.db.QueryxContext(ctx, q, pq.queryParams...)
+}
+
 func (pq *pgQuery) Delete(ctx context.Context) (sql.Result, error) {
 	q, err := pq.resolveQueryTemplate(ctx, DeleteQueryTemplate)
 	if err != nil {
@@ -214,25 +212,17 @@ func (pq *pgQuery) resolveQueryTemplate(ctx context.Context, template string) (s
 	if pq.err != nil {
 		return "", pq.err
 	}
-	if pq.labelEntity == nil {
-		return "", fmt.Errorf("query builder requires the entity to have associated label entity")
+	if err := pq.applyPageAfter(); err != nil {
+		return "", err
 	}
-	hasFieldCriteria := len(pq.fieldsWhereClause.children) != 0 || len(pq.limit) != 0
-	hasLabelCriteria := len(pq.labelsWhereClause.children) != 0
 	data := map[string]interface{}{
-		"hasFieldCriteria":  hasFieldCriteria,
-		"hasLabelCriteria":  hasLabelCriteria,
-		"ENTITY_TABLE":      pq.entityTableName,
-		"PRIMARY_KEY":       PrimaryKeyColumn,
-		"LABELS_TABLE":      pq.labelEntity.LabelsTableName(),
-		"REF_COLUMN":        pq.labelEntity.ReferenceColumn(),
-		"JOIN":              pq.joinSQL(),
-		"WHERE":             pq.whereSQL(),
-		"FOR_UPDATE_OF":     pq.lockSQL(),
-		"ORDER_BY":          pq.orderBySQL(),
-		"ORDER_BY_SEQUENCE": pq.orderBySequenceSQL(),
-		"LIMIT":             pq.limitSQL(),
-		"RETURNING":         pq.returningSQL(),
+		"ENTITY_TABLE":  pq.entityTableName,
+		"COLUMNS":       pq.columnsSQL(),
+		"WHERE":         pq.whereSQL(),
+		"FOR_UPDATE_OF": pq.lockSQL(),
+		"ORDER_BY":      pq.orderBySQL(),
+		"LIMIT":         pq.limitSQL(),
+		"RETURNING":     pq.returningSQL(),
 	}
 
 	q, err := tsprintf(template, data)
@@ -250,11 +240,6 @@ func (pq *pgQuery) WithCriteria(criteria ...query.Criterion) *pgQuery {
 	if pq.err != nil {
 		return pq
 	}
-	labelQueryCount := 0
-	var builder = *defaultTreeSqlBuilder
-	var subSelectStatementFunc = func(childrenSQL []string) string {
-		return fmt.Sprintf("(SELECT %s FROM %s WHERE (%s))", pq.labelEntity.ReferenceColumn(), pq.labelEntity.LabelsTableName(), strings.Join(childrenSQL, fmt.Sprintf(" %s ", AND)))
-	}
 	for _, criterion := range criteria {
 		if err := criterion.Validate(); err != nil {
 			pq.err = err
@@ -270,28 +255,24 @@ func (pq *pgQuery) WithCriteria(criteria ...query.Criterion) *pgQuery {
 				pq.err = &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field query key: %s", criterion.LeftOp)}
 				return pq
 			}
-			pq.fieldsWhereClause.children = append(pq.fieldsWhereClause.children, &whereClauseTree{
+			pq.whereClause.children = append(pq.whereClause.children, &whereClauseTree{
 				criterion: criterion,
 				dbTags:    pq.entityTags,
 				tableName: pq.entityTableName,
 			})
 		case query.LabelQuery:
-			labelQueryCount++
-			if labelQueryCount > 1 { // 2 or more labelQueries need to be intersected
-				builder.buildSQL = subSelectStatementFunc
+			if !pq.hasLabels {
+				pq.err = &util.UnsupportedQueryError{Message: fmt.Sprintf("label queries are not supported for %s", pq.entityTableName)}
+				return pq
+			}
+			sql, sqlParams, err := labelCriterionSQL(criterion, pq.entityTableName)
+			if err != nil {
+				pq.err = err
+				return pq
 			}
-			pq.labelsWhereClause.children = append(pq.labelsWhereClause.children, &whereClauseTree{
-				children: []*whereClauseTree{
-					{
-						criterion: query.ByField(query.EqualsOperator, "key", criterion.LeftOp),
-						dbTags:    pq.labelEntityTags,
-					},
-					{
-						criterion: query.ByField(criterion.Operator, "val", criterion.RightOp...),
-						dbTags:    pq.labelEntityTags,
-					},
-				},
-				sqlBuilder: &builder,
+			pq.whereClause.children = append(pq.whereClause.children, &whereClauseTree{
+				sql:       sql,
+				sqlParams: sqlParams,
 			})
 		case query.ResultQuery:
 			pq.processResultCriteria(criterion)
@@ -319,24 +300,8 @@ func (pq *pgQuery) limitSQL() string {
 	return ""
 }
 
-// joinSQL is a performance improvement - queries can work with LEFT JOIN always but are slower
-// JOIN is used when a label query is present, LEFT JOIN is used when no label query is present so that resultset includes
-// unlabelled resources
-func (pq *pgQuery) joinSQL() string {
-	if len(pq.labelsWhereClause.children) == 0 {
-		return "LEFT JOIN"
-	}
-	return "JOIN"
-}
-
 func (pq *pgQuery) whereSQL() string {
-	whereClause := &whereClauseTree{
-		children: []*whereClauseTree{
-			pq.fieldsWhereClause,
-			pq.labelsWhereClause,
-		},
-	}
-	whereSQL, queryParams := whereClause.compileSQL()
+	whereSQL, queryParams := pq.whereClause.compileSQL()
 	if len(whereSQL) == 0 {
 		return ""
 	}
@@ -344,6 +309,15 @@ func (pq *pgQuery) whereSQL() string {
 	return fmt.Sprintf(" WHERE %s", whereSQL)
 }
 
+// columnsSQL returns the columns of the entity table without the labels column
+func (pq *pgQuery) columnsSQL() string {
+	columns := make([]string, 0, len(pq.entityTags))
+	for _, tag := range pq.entityTags {
+		columns = append(columns, fmt.Sprintf("%s.%s", pq.entityTableName, strings.Split(tag.Tag, ",")[0]))
+	}
+	return strings.Join(columns, ", ")
+}
+
 func (pq *pgQuery) returningSQL() string {
 	fieldsCount := len(pq.returningFields)
 	switch fieldsCount {
@@ -407,6 +381,12 @@ func (pq *pgQuery) processResultCriteria(c query.Criterion) *pgQuery {
 			return pq
 		}
 		pq.limit = c.RightOp[0]
+	case query.PageAfter:
+		if pq.pageAfter != nil {
+			pq.err = fmt.Errorf("zero/one page after criterion expected but multiple provided")
+			return pq
+		}
+		pq.pageAfter = c.RightOp
 	}
 	return pq
 }
@@ -428,11 +408,66 @@ func (pq *pgQuery) orderBySQL() string {
 	return sql
 }
 
-func (pq *pgQuery) orderBySequenceSQL() string {
-	if len(pq.limit) > 0 {
-		return fmt.Sprintf("ORDER BY %s.paging_sequence ASC", pq.entityTableName)
+// applyPageAfter adds a keyset condition selecting the resources ordered after the page after values.
+// For rules r1..rn the condition is (r1 after v1) OR (r1 = v1 AND r2 after v2) OR ... where after means
+// greater than for ascending and less than for descending order. Nullable columns are sorted as NULLS LAST
+// in ascending and NULLS FIRST in descending order and an empty value represents NULL for them.
+func (pq *pgQuery) applyPageAfter() error {
+	if pq.pageAfter == nil {
+		return nil
+	}
+	if len(pq.pageAfter) != len(pq.orderByFields) {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("page after expects %d values but %d were provided", len(pq.orderByFields), len(pq.pageAfter))}
+	}
+
+	alternatives := make([]string, 0, len(pq.orderByFields))
+	params := make([]interface{}, 0)
+	equalities := make([]string, 0, len(pq.orderByFields))
+	equalityParams := make([]interface{}, 0)
+	for i, rule := range pq.orderByFields {
+		column := fmt.Sprintf("%s.%s", pq.entityTableName, rule.field)
+		value := pq.pageAfter[i]
+		isNull := value == "" && isNullableType(findTagType(pq.entityTags, rule.field))
+
+		var after string
+		var afterParams []interface{}
+		switch {
+		case isNull && rule.orderType == query.AscOrder:
+			after = ""
+		case isNull:
+			after = fmt.Sprintf("%s IS NOT NULL", column)
+		case rule.orderType == query.AscOrder && isNullableType(findTagType(pq.entityTags, rule.field)):
+			after = fmt.Sprintf("(%s > ? OR %s IS NULL)", column, column)
+			afterParams = []interface{}{value}
+		case rule.orderType == query.AscOrder:
+			after = fmt.Sprintf("%s > ?", column)
+			afterParams = []interface{}{value}
+		default:
+			after = fmt.Sprintf("%s < ?", column)
+			afterParams = []interface{}{value}
+		}
+		if len(after) != 0 {
+			alternatives = append(alternatives, fmt.Sprintf("(%s)", strings.Join(append(append([]string{}, equalities...), after), fmt.Sprintf(" %s ", AND))))
+			params = append(append(params, equalityParams...), afterParams...)
+		}
+
+		if isNull {
+			equalities = append(equalities, fmt.Sprintf("%s IS NULL", column))
+		} else {
+			equalities = append(equalities, fmt.Sprintf("%s = ?", column))
+			equalityParams = append(equalityParams, value)
+		}
 	}
-	return ""
+	if len(alternatives) == 0 {
+		alternatives = append(alternatives, "FALSE")
+	}
+
+	pq.whereClause.children = append(pq.whereClause.children, &whereClauseTree{
+		sql:       fmt.Sprintf("(%s)", strings.Join(alternatives, " OR ")),
+		sqlParams: params,
+	})
+	pq.pageAfter = nil
+	return nil
 }
 
 func validateOrderFields(columns map[string]bool, orderRules ...orderRule) error {
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
 
 	. "github.com/onsi/gomega"
 
@@ -62,11 +63,13 @@ var _ = Describe("Postgres Storage Query builder", func() {
 	})
 
 	Describe("List", func() {
-		Context("when entity does not have an associated label entity", func() {
+		Context("when label criteria is used for entity without labels", func() {
 			It("returns error", func() {
-				_, err := qb.NewQuery(&postgres.Safe{}).List(ctx)
+				_, err := qb.NewQuery(&postgres.Safe{}).
+					WithCriteria(query.ByLabel(query.EqualsOperator, "labelKey", "labelValue")).
+					List(ctx)
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("query builder requires the entity to have associated label entity"))
+				Expect(err.Error()).To(ContainSubstring("label queries are not supported for safe"))
 			})
 		})
 
@@ -75,15 +78,8 @@ var _ = Describe("Postgres Storage Query builder", func() {
 				_, err := qb.NewQuery(entity).List(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT visibilities.*,
-       visibility_labels.id            "visibility_labels.id",
-       visibility_labels.key           "visibility_labels.key",
-       visibility_labels.val           "visibility_labels.val",
-       visibility_labels.created_at    "visibility_labels.created_at",
-       visibility_labels.updated_at    "visibility_labels.updated_at",
-       visibility_labels.visibility_id "visibility_labels.visibility_id"
+SELECT *
 FROM visibilities
-         LEFT JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
 ORDER BY visibilities.paging_sequence ASC ;`)))
 				Expect(queryArgs).To(HaveLen(0))
 			})
@@ -96,24 +92,11 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					List(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
-                            FROM visibilities
-								JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-                            WHERE (key::text = ? AND val::text = ?) )
-SELECT visibilities.*,
-       visibility_labels.id            "visibility_labels.id",
-       visibility_labels.key           "visibility_labels.key",
-       visibility_labels.val           "visibility_labels.val",
-       visibility_labels.created_at    "visibility_labels.created_at",
-       visibility_labels.updated_at    "visibility_labels.updated_at",
-       visibility_labels.visibility_id "visibility_labels.visibility_id"
+SELECT *
 FROM visibilities
-	JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
+WHERE (visibilities.labels @> ?::jsonb)
 ORDER BY visibilities.paging_sequence ASC ;`)))
-				Expect(queryArgs).To(HaveLen(2))
-				Expect(queryArgs[0]).Should(Equal("labelKey"))
-				Expect(queryArgs[1]).Should(Equal("labelValue"))
+				Expect(queryArgs).To(Equal([]interface{}{`{"labelKey":["labelValue"]}`}))
 			})
 		})
 
@@ -124,19 +107,9 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					List(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
-                            FROM visibilities
-                            WHERE visibilities.id::text = ? )
-SELECT visibilities.*,
-       visibility_labels.id            "visibility_labels.id",
-       visibility_labels.key           "visibility_labels.key",
-       visibility_labels.val           "visibility_labels.val",
-       visibility_labels.created_at    "visibility_labels.created_at",
-       visibility_labels.updated_at    "visibility_labels.updated_at",
-       visibility_labels.visibility_id "visibility_labels.visibility_id"
+SELECT *
 FROM visibilities
-         LEFT JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
+WHERE visibilities.id::text = ?
 ORDER BY visibilities.paging_sequence ASC ;`)))
 				Expect(queryArgs).To(HaveLen(1))
 				Expect(queryArgs[0]).Should(Equal("1"))
@@ -158,15 +131,8 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					List(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT visibilities.*,
-       visibility_labels.id            "visibility_labels.id",
-       visibility_labels.key           "visibility_labels.key",
-       visibility_labels.val           "visibility_labels.val",
-       visibility_labels.created_at    "visibility_labels.created_at",
-       visibility_labels.updated_at    "visibility_labels.updated_at",
-       visibility_labels.visibility_id "visibility_labels.visibility_id"
+SELECT *
 FROM visibilities
-         LEFT JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
 ORDER BY id DESC, created_at ASC ;`)))
 				Expect(queryArgs).To(HaveLen(0))
 			})
@@ -216,6 +182,54 @@ ORDER BY id DESC, created_at ASC ;`)))
 			})
 		})
 
+		Context("when page after criterion is used", func() {
+			It("builds query selecting the resources ordered after the values", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.OrderResultBy("service_plan_id", query.DescOrder),
+						query.OrderResultBy("platform_id", query.AscOrder),
+						query.OrderResultBy("paging_sequence", query.AscOrder),
+						query.ResultAfter("plan", "platform", "5"),
+						query.LimitResultBy(10)).
+					List(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT *
+FROM visibilities
+WHERE ((visibilities.service_plan_id < ?) OR
+       (visibilities.service_plan_id = ? AND (visibilities.platform_id > ? OR visibilities.platform_id IS NULL)) OR
+       (visibilities.service_plan_id = ? AND visibilities.platform_id = ? AND visibilities.paging_sequence > ?))
+ORDER BY service_plan_id DESC, platform_id ASC, paging_sequence ASC
+LIMIT ? ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"plan", "plan", "platform", "plan", "platform", "5", "10"}))
+			})
+
+			It("treats empty values of nullable columns as NULL", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.OrderResultBy("platform_id", query.AscOrder),
+						query.OrderResultBy("paging_sequence", query.AscOrder),
+						query.ResultAfter("", "5")).
+					ListNoLabels(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
+FROM visibilities
+WHERE ((visibilities.platform_id IS NULL AND visibilities.paging_sequence > ?))
+ORDER BY platform_id ASC, paging_sequence ASC ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"5"}))
+			})
+
+			Context("when the number of values does not match the order by criteria", func() {
+				It("returns error", func() {
+					_, err := qb.NewQuery(entity).
+						WithCriteria(query.OrderResultBy("platform_id", query.AscOrder), query.ResultAfter("a", "b")).
+						List(ctx)
+					Expect(err).Should(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("page after expects 1 values but 2 were provided"))
+				})
+			})
+		})
+
 		Context("when limit criteria is used", func() {
 			It("builds query with limit clause", func() {
 				_, err := qb.NewQuery(entity).
@@ -223,21 +237,10 @@ ORDER BY id DESC, created_at ASC ;`)))
 					List(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
-                            FROM visibilities
-							ORDER BY visibilities.paging_sequence ASC
-                            LIMIT ?)
-SELECT visibilities.*,
-       visibility_labels.id            "visibility_labels.id",
-       visibility_labels.key           "visibility_labels.key",
-       visibility_labels.val           "visibility_labels.val",
-       visibility_labels.created_at    "visibility_labels.created_at",
-       visibility_labels.updated_at    "visibility_labels.updated_at",
-       visibility_labels.visibility_id "visibility_labels.visibility_id"
+SELECT *
 FROM visibilities
-         LEFT JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
-ORDER BY visibilities.paging_sequence ASC ;`)))
+ORDER BY visibilities.paging_sequence ASC
+LIMIT ? ;`)))
 				Expect(queryArgs).To(HaveLen(1))
 				Expect(queryArgs[0]).Should(Equal("10"))
 			})
@@ -277,63 +280,30 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					List(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
-                            FROM visibilities
-								JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-                            WHERE ((visibilities.id::text != ? AND
-                                    visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
-                                    (visibilities.platform_id::text = ? OR platform_id IS NULL)) AND
-									(visibility_id IN ((SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text = ?))
-										INTERSECT
-										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text IN (?, ?)))
-										INTERSECT
-										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text != ?)))))
-                            ORDER BY visibilities.paging_sequence ASC
-                            LIMIT ?)
-SELECT visibilities.*,
-       visibility_labels.id            "visibility_labels.id",
-       visibility_labels.key           "visibility_labels.key",
-       visibility_labels.val           "visibility_labels.val",
-       visibility_labels.created_at    "visibility_labels.created_at",
-       visibility_labels.updated_at    "visibility_labels.updated_at",
-       visibility_labels.visibility_id "visibility_labels.visibility_id"
+SELECT *
 FROM visibilities
-	JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
-ORDER BY id ASC ;`)))
-				Expect(queryArgs).To(HaveLen(13))
-				Expect(queryArgs[0]).Should(Equal("1"))
-				Expect(queryArgs[1]).Should(Equal("2"))
-				Expect(queryArgs[2]).Should(Equal("3"))
-				Expect(queryArgs[3]).Should(Equal("4"))
-				Expect(queryArgs[4]).Should(Equal("5"))
-				Expect(queryArgs[5]).Should(Equal("left1"))
-				Expect(queryArgs[6]).Should(Equal("right1"))
-				Expect(queryArgs[7]).Should(Equal("left2"))
-				Expect(queryArgs[8]).Should(Equal("right2"))
-				Expect(queryArgs[9]).Should(Equal("right3"))
-				Expect(queryArgs[10]).Should(Equal("left3"))
-				Expect(queryArgs[11]).Should(Equal("right4"))
-				Expect(queryArgs[12]).Should(Equal("10"))
+WHERE (visibilities.id::text != ? AND
+       visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
+       (visibilities.platform_id::text = ? OR platform_id IS NULL) AND
+       (visibilities.labels @> ?::jsonb) AND
+       (visibilities.labels @> ?::jsonb OR visibilities.labels @> ?::jsonb) AND
+       EXISTS (SELECT 1 FROM jsonb_array_elements_text(visibilities.labels -> ?::text) AS label(value) WHERE label.value != ?))
+ORDER BY id ASC
+LIMIT ? ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"1", "2", "3", "4", "5",
+					`{"left1":["right1"]}`, `{"left2":["right2"]}`, `{"left2":["right3"]}`, "left3", "right4", "10"}))
 			})
 		})
 	})
 
 	Describe("ListNoLabels", func() {
-		Context("when entity does not have an associated label entity", func() {
-			It("returns error", func() {
-				_, err := qb.NewQuery(&postgres.Safe{}).ListNoLabels(ctx)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("query builder requires the entity to have associated label entity"))
-			})
-		})
-
 		Context("when no criteria is used", func() {
 			It("builds simple query for entity and its labels", func() {
 				_, err := qb.NewQuery(entity).ListNoLabels(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT *
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
 FROM visibilities
 ORDER BY visibilities.paging_sequence ASC ;`)))
 				Expect(queryArgs).To(HaveLen(0))
@@ -341,12 +311,58 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 		})
 
 		Context("when label criteria is used", func() {
-			It("should return error", func() {
+			It("should build query with label criteria", func() {
 				_, err := qb.NewQuery(entity).
-					WithCriteria(query.ByLabel(query.EqualsOperator, "labelKey", "labelValue")).
+					WithCriteria(query.ByLabel(query.NotInOperator, "labelKey", "labelValue1", "labelValue2")).
+					ListNoLabels(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
+FROM visibilities
+WHERE EXISTS (SELECT 1 FROM jsonb_array_elements_text(visibilities.labels -> ?::text) AS label(value) WHERE label.value NOT IN (?, ?))
+ORDER BY visibilities.paging_sequence ASC ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"labelKey", "labelValue1", "labelValue2"}))
+			})
+
+			It("should build query with label existence criteria", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.ByLabel(query.ExistsOperator, "labelKey1"), query.ByLabel(query.NotExistsOperator, "labelKey2")).
+					ListNoLabels(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
+FROM visibilities
+WHERE (visibilities.labels @> ?::jsonb AND NOT (visibilities.labels @> ?::jsonb))
+ORDER BY visibilities.paging_sequence ASC ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{`{"labelKey1":[]}`, `{"labelKey2":[]}`}))
+			})
+
+			It("should compare the values of int labels as numbers", func() {
+				criterion := query.ByLabel(query.GreaterThanOperator, "labelKey1", "5")
+				criterion.ValueType = types.LabelValueInt
+				_, err := qb.NewQuery(entity).
+					WithCriteria(criterion).
+					ListNoLabels(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
+FROM visibilities
+WHERE EXISTS (SELECT 1 FROM jsonb_array_elements_text(visibilities.labels -> ?::text) AS label(value) WHERE CASE WHEN label.value ~ '^[+-]{0,1}[0-9]+$' THEN label.value::numeric END > ?::numeric)
+ORDER BY visibilities.paging_sequence ASC ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"labelKey1", "5"}))
+			})
+		})
+
+		Context("when the labels column is used in a field criteria", func() {
+			It("returns error", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.ByField(query.EqualsOperator, "labels", "{}")).
 					ListNoLabels(ctx)
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).Should(Equal("label queries are not supported by ListNoLabels"))
+				Expect(err.Error()).To(ContainSubstring("unsupported field query key: labels"))
 			})
 		})
 
@@ -357,12 +373,10 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					ListNoLabels(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
-                            FROM visibilities
-                            WHERE visibilities.id::text = ? )
-SELECT *
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
 FROM visibilities
-WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
+WHERE visibilities.id::text = ?
 ORDER BY visibilities.paging_sequence ASC ;`)))
 				Expect(queryArgs).To(HaveLen(1))
 				Expect(queryArgs[0]).Should(Equal("1"))
@@ -384,7 +398,8 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					ListNoLabels(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT *
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
 FROM visibilities
 ORDER BY id DESC, created_at ASC ;`)))
 				Expect(queryArgs).To(HaveLen(0))
@@ -442,14 +457,11 @@ ORDER BY id DESC, created_at ASC ;`)))
 					ListNoLabels(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
-                            FROM visibilities
-							ORDER BY visibilities.paging_sequence ASC
-                            LIMIT ?)
-SELECT *
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
 FROM visibilities
-WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
-ORDER BY visibilities.paging_sequence ASC ;`)))
+ORDER BY visibilities.paging_sequence ASC
+LIMIT ? ;`)))
 				Expect(queryArgs).To(HaveLen(1))
 				Expect(queryArgs[0]).Should(Equal("10"))
 			})
@@ -485,17 +497,14 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					ListNoLabels(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
-                            FROM visibilities
-							WHERE (visibilities.id::text != ? AND
-                                    visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
-                                    (visibilities.platform_id::text = ? OR platform_id IS NULL))
-                            ORDER BY visibilities.paging_sequence ASC
-                            LIMIT ?)
-SELECT *
+SELECT visibilities.id, visibilities.created_at, visibilities.updated_at, visibilities.paging_sequence, visibilities.ready,
+       visibilities.platform_id, visibilities.service_plan_id
 FROM visibilities
-WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
-ORDER BY id ASC ;`)))
+WHERE (visibilities.id::text != ? AND
+       visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
+       (visibilities.platform_id::text = ? OR platform_id IS NULL))
+ORDER BY id ASC
+LIMIT ? ;`)))
 				Expect(queryArgs).To(HaveLen(6))
 				Expect(queryArgs[0]).Should(Equal("1"))
 				Expect(queryArgs[1]).Should(Equal("2"))
@@ -508,20 +517,12 @@ ORDER BY id ASC ;`)))
 	})
 
 	Describe("Count", func() {
-		Context("when entity does not have an associated label entity", func() {
-			It("returns error", func() {
-				_, err := qb.NewQuery(&postgres.Safe{}).Count(ctx)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("query builder requires the entity to have associated label entity"))
-			})
-		})
-
 		Context("when no criteria is used", func() {
 			It("builds simple query for entity and its labels", func() {
 				_, err := qb.NewQuery(entity).Count(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT COUNT(DISTINCT visibilities.id)
+SELECT COUNT(*)
 FROM visibilities ;`)))
 				Expect(queryArgs).To(HaveLen(0))
 			})
@@ -534,13 +535,10 @@ FROM visibilities ;`)))
 					Count(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT COUNT(DISTINCT visibilities.id)
+SELECT COUNT(*)
 FROM visibilities
-	JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-WHERE (key::text = ? AND val::text = ?) ;`)))
-				Expect(queryArgs).To(HaveLen(2))
-				Expect(queryArgs[0]).Should(Equal("labelKey"))
-				Expect(queryArgs[1]).Should(Equal("labelValue"))
+WHERE (visibilities.labels @> ?::jsonb) ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{`{"labelKey":["labelValue"]}`}))
 			})
 		})
 
@@ -551,7 +549,7 @@ WHERE (key::text = ? AND val::text = ?) ;`)))
 					Count(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT COUNT(DISTINCT visibilities.id)
+SELECT COUNT(*)
 FROM visibilities
 WHERE visibilities.id::text = ? ;`)))
 				Expect(queryArgs).To(HaveLen(1))
@@ -574,7 +572,7 @@ WHERE visibilities.id::text = ? ;`)))
 					Count(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT COUNT(DISTINCT visibilities.id)
+SELECT COUNT(*)
 FROM visibilities ;`)))
 				Expect(queryArgs).To(HaveLen(0))
 			})
@@ -587,7 +585,7 @@ FROM visibilities ;`)))
 					Count(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT COUNT(DISTINCT visibilities.id)
+SELECT COUNT(*)
 FROM visibilities
 LIMIT ?;`)))
 				Expect(queryArgs).To(HaveLen(1))
@@ -630,45 +628,40 @@ LIMIT ?;`)))
 					Count(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-SELECT COUNT(DISTINCT visibilities.id)
+SELECT COUNT(*)
 FROM visibilities
-         JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-WHERE ((visibilities.id::text != ? AND
-	visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
-		(visibilities.platform_id::text = ? OR platform_id IS NULL)) AND
-		(visibility_id IN ((SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text = ?))
-										INTERSECT
-										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text IN (?, ?)))
-										INTERSECT
-										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text != ?)))))
+WHERE (visibilities.id::text != ? AND
+       visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
+       (visibilities.platform_id::text = ? OR platform_id IS NULL) AND
+       (visibilities.labels @> ?::jsonb) AND
+       (visibilities.labels @> ?::jsonb OR visibilities.labels @> ?::jsonb) AND
+       EXISTS (SELECT 1 FROM jsonb_array_elements_text(visibilities.labels -> ?::text) AS label(value) WHERE label.value != ?))
 LIMIT ?;`)))
-				Expect(queryArgs).To(HaveLen(13))
-				Expect(queryArgs[0]).Should(Equal("1"))
-				Expect(queryArgs[1]).Should(Equal("2"))
-				Expect(queryArgs[2]).Should(Equal("3"))
-				Expect(queryArgs[3]).Should(Equal("4"))
-				Expect(queryArgs[4]).Should(Equal("5"))
-				Expect(queryArgs[5]).Should(Equal("left1"))
-				Expect(queryArgs[6]).Should(Equal("right1"))
-				Expect(queryArgs[7]).Should(Equal("left2"))
-				Expect(queryArgs[8]).Should(Equal("right2"))
-				Expect(queryArgs[9]).Should(Equal("right3"))
-				Expect(queryArgs[10]).Should(Equal("left3"))
-				Expect(queryArgs[11]).Should(Equal("right4"))
-				Expect(queryArgs[12]).Should(Equal("10"))
+				Expect(queryArgs).To(Equal([]interface{}{"1", "2", "3", "4", "5",
+					`{"left1":["right1"]}`, `{"left2":["right2"]}`, `{"left2":["right3"]}`, "left3", "right4", "10"}))
 			})
 		})
 	})
 
-	Describe("Delete", func() {
-		Context("when entity does not have an associated label entity", func() {
-			It("returns error", func() {
-				_, err := qb.NewQuery(&postgres.Safe{}).Delete(ctx)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("query builder requires the entity to have associated label entity"))
-			})
+	Describe("Search", func() {
+		It("should rank the matches of the search text with the criteria applied", func() {
+			_, err := qb.NewQuery(&postgres.Platform{}).
+				WithCriteria(query.ByLabel(query.EqualsOperator, "tenant", "t1"), query.LimitResultBy(10)).
+				Search(ctx, "my platform")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(executedQuery).Should(Equal(trim(`
+SELECT platforms.id, ts_rank(search_document.document, plainto_tsquery('simple', ?)) AS rank
+FROM platforms
+JOIN search_documents AS search_document
+  ON search_document.resource_type = 'platforms' AND search_document.resource_id = platforms.id
+WHERE ((platforms.labels @> ?::jsonb) AND search_document.document @@ plainto_tsquery('simple', ?))
+ORDER BY rank DESC, platforms.id
+LIMIT ?;`)))
+			Expect(queryArgs).To(Equal([]interface{}{"my platform", `{"tenant":["t1"]}`, "my platform", "10"}))
 		})
+	})
 
+	Describe("Delete", func() {
 		Context("when no criteria is used", func() {
 			It("builds query to delete all entries", func() {
 				_, err := qb.NewQuery(entity).Delete(ctx)
@@ -687,19 +680,10 @@ FROM visibilities ;`)))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
 DELETE
-FROM visibilities USING (SELECT visibilities.id
-                         FROM visibilities
-                                  JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-						 WHERE (visibility_id IN ((SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text = ?))
-										INTERSECT
-										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text IN (?, ?)))))) t
-WHERE visibilities.id = t.id ;`)))
-				Expect(queryArgs).To(HaveLen(5))
-				Expect(queryArgs[0]).Should(Equal("left1"))
-				Expect(queryArgs[1]).Should(Equal("right1"))
-				Expect(queryArgs[2]).Should(Equal("left2"))
-				Expect(queryArgs[3]).Should(Equal("right2"))
-				Expect(queryArgs[4]).Should(Equal("right3"))
+FROM visibilities
+WHERE ((visibilities.labels @> ?::jsonb) AND
+       (visibilities.labels @> ?::jsonb OR visibilities.labels @> ?::jsonb)) ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{`{"left1":["right1"]}`, `{"left2":["right2"]}`, `{"left2":["right3"]}`}))
 			})
 		})
 
@@ -779,30 +763,15 @@ RETURNING *;`)))
 				Expect(err).ToNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
 DELETE
-FROM visibilities USING (SELECT visibilities.id
-                         FROM visibilities
-                                  JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
-                         WHERE ((visibilities.id::text != ? AND visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
-                                 (visibilities.platform_id::text = ? OR platform_id IS NULL)) AND
-								 (visibility_id IN ((SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text = ?))
-										INTERSECT
-										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text IN (?, ?)))
-										INTERSECT
-										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text != ?)))))) t
-WHERE visibilities.id = t.id RETURNING *;`)))
-				Expect(queryArgs).To(HaveLen(12))
-				Expect(queryArgs[0]).Should(Equal("1"))
-				Expect(queryArgs[1]).Should(Equal("2"))
-				Expect(queryArgs[2]).Should(Equal("3"))
-				Expect(queryArgs[3]).Should(Equal("4"))
-				Expect(queryArgs[4]).Should(Equal("5"))
-				Expect(queryArgs[5]).Should(Equal("left1"))
-				Expect(queryArgs[6]).Should(Equal("right1"))
-				Expect(queryArgs[7]).Should(Equal("left2"))
-				Expect(queryArgs[8]).Should(Equal("right2"))
-				Expect(queryArgs[9]).Should(Equal("right3"))
-				Expect(queryArgs[10]).Should(Equal("left3"))
-				Expect(queryArgs[11]).Should(Equal("right4"))
+FROM visibilities
+WHERE (visibilities.id::text != ? AND
+       visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
+       (visibilities.platform_id::text = ? OR platform_id IS NULL) AND
+       (visibilities.labels @> ?::jsonb) AND
+       (visibilities.labels @> ?::jsonb OR visibilities.labels @> ?::jsonb) AND
+       EXISTS (SELECT 1 FROM jsonb_array_elements_text(visibilities.labels -> ?::text) AS label(value) WHERE label.value != ?)) RETURNING *;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"1", "2", "3", "4", "5",
+					`{"left1":["right1"]}`, `{"left2":["right2"]}`, `{"left2":["right3"]}`, "left3", "right4"}))
 			})
 		})
 	})
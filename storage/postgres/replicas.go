@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/Peripli/service-manager/pkg/log"
+)
+
+const replicaCheckInterval = 5 * time.Second
+
+// replica is a read replica of the database. A replica which fails with a connection error is not used until it
+// responds to a ping again, which is checked at most once per check interval.
+type replica struct {
+	index        int
+	db           *sqlx.DB
+	queryBuilder *QueryBuilder
+
+	mutex         sync.Mutex
+	available     bool
+	lastCheckTime time.Time
+	checkInterval time.Duration
+}
+
+func newReplica(index int, db *sqlx.DB) *replica {
+	return &replica{
+		index:         index,
+		db:            db,
+		queryBuilder:  NewQueryBuilder(db),
+		available:     true,
+		checkInterval: replicaCheckInterval,
+	}
+}
+
+func (r *replica) isAvailable(ctx context.Context) bool {
+	r.mutex.Lock()
+	if r.available || time.Since(r.lastCheckTime) < r.checkInterval {
+		defer r.mutex.Unlock()
+		return r.available
+	}
+	r.lastCheckTime = time.Now()
+	r.mutex.Unlock()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		log.C(ctx).WithError(err).Debugf("Read replica %d is still not available", r.index)
+		return false
+	}
+	log.C(ctx).Infof("Read replica %d is available again", r.index)
+	r.mutex.Lock()
+	r.available = true
+	r.mutex.Unlock()
+	return true
+}
+
+func (r *replica) markUnavailable(ctx context.Context, err error) {
+	log.C(ctx).WithError(err).Warnf("Read replica %d failed, reads will be served by the primary database until it is available again", r.index)
+	r.mutex.Lock()
+	r.available = false
+	r.lastCheckTime = time.Now()
+	r.mutex.Unlock()
+}
+
+// replicaSet balances the reads between the available replicas in round-robin order
+type replicaSet struct {
+	replicas []*replica
+	next     uint32
+}
+
+// pick returns the next available replica or nil if none of the replicas is available
+func (rs *replicaSet) pick(ctx context.Context) *replica {
+	count := len(rs.replicas)
+	start := int(atomic.AddUint32(&rs.next, 1))
+	for i := 0; i < count; i++ {
+		r := rs.replicas[(start+i)%count]
+		if r.isAvailable(ctx) {
+			return r
+		}
+	}
+	return nil
+}
+
+func (rs *replicaSet) close() error {
+	var result error
+	for _, r := range rs.replicas {
+		if err := r.db.Close(); err != nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// isConnectionFailure returns whether the error shows that the database cannot serve queries, as opposed to an
+// error caused by the query itself
+func isConnectionFailure(err error) bool {
+	if err == driver.ErrBadConn || err == sql.ErrConnDone || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	pqError, ok := err.(*pq.Error)
+	if !ok {
+		return false
+	}
+	switch pqError.Code.Class() {
+	case "08", "53":
+		// connection exception and insufficient resources
+		return true
+	}
+	// admin shutdown, crash shutdown and cannot connect now
+	return pqError.Code == "57P01" || pqError.Code == "57P02" || pqError.Code == "57P03"
+}
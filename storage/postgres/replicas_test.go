@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Read replicas", func() {
+	const readURI = "sqlmock://replica"
+
+	var s *Storage
+	var primary, replica sqlmock.Sqlmock
+	var ctx context.Context
+
+	expectCount := func(mock sqlmock.Sqlmock, count int) {
+		mock.ExpectQuery(`SELECT COUNT`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+	}
+
+	BeforeEach(func() {
+		primaryDB, primaryMock, err := sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		replicaDB, replicaMock, err := sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		primary, replica = primaryMock, replicaMock
+
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				if url == readURI {
+					return replicaDB, nil
+				}
+				return primaryDB, nil
+			},
+		}
+
+		primary.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		primary.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		primary.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		primary.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20200624100000,false"))
+		primary.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		encryptionKey := make([]byte, 32)
+		_, err = rand.Read(encryptionKey)
+		Expect(err).ToNot(HaveOccurred())
+		settings := storage.DefaultSettings()
+		settings.EncryptionKey = string(encryptionKey)
+		settings.URI = "sqlmock://primary"
+		settings.ReadURIs = []string{readURI}
+		Expect(s.Open(settings)).To(Succeed())
+
+		ctx = storage.ContextWithWriteTracking(context.Background())
+	})
+
+	AfterEach(func() {
+		Expect(primary.ExpectationsWereMet()).To(Succeed())
+		Expect(replica.ExpectationsWereMet()).To(Succeed())
+		s.Close()
+	})
+
+	It("should serve the reads of requests from the replica", func() {
+		expectCount(replica, 3)
+		replica.ExpectQuery(`SELECT`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		count, err := s.Count(ctx, types.PlatformType)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(3))
+		_, err = s.List(ctx, types.PlatformType)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should serve the reads without write tracking from the primary database", func() {
+		expectCount(primary, 3)
+
+		count, err := s.Count(context.Background(), types.PlatformType)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(3))
+	})
+
+	It("should serve the reads from the primary database once the request has written", func() {
+		primary.ExpectExec(`DELETE FROM`).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectCount(primary, 2)
+
+		Expect(s.Delete(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", "1"))).To(Succeed())
+		count, err := s.Count(ctx, types.PlatformType)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(2))
+	})
+
+	It("should serve the reads in transactions from the primary database", func() {
+		primary.ExpectBegin()
+		expectCount(primary, 1)
+		primary.ExpectCommit()
+
+		err := s.InTransaction(ctx, func(ctx context.Context, repository storage.Repository) error {
+			count, err := repository.Count(ctx, types.PlatformType)
+			Expect(count).To(Equal(1))
+			return err
+		}, storage.WithReadOnly())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should not return errors caused by the query from the primary database", func() {
+		queryErr := &pq.Error{Code: "42703", Message: "column does not exist"}
+		replica.ExpectQuery(`SELECT COUNT`).WillReturnError(queryErr)
+
+		_, err := s.Count(ctx, types.PlatformType)
+		Expect(err).To(Equal(queryErr))
+	})
+
+	Context("when the replica fails", func() {
+		BeforeEach(func() {
+			replica.ExpectQuery(`SELECT COUNT`).WillReturnError(&pq.Error{Code: "57P03", Message: "the database system is starting up"})
+			expectCount(primary, 3)
+
+			count, err := s.Count(ctx, types.PlatformType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(3))
+		})
+
+		It("should serve the reads from the primary database until the replica is available again", func() {
+			expectCount(primary, 4)
+			count, err := s.Count(ctx, types.PlatformType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(4))
+
+			s.replicas.replicas[0].checkInterval = time.Millisecond
+			time.Sleep(2 * time.Millisecond)
+			expectCount(replica, 5)
+			count, err = s.Count(ctx, types.PlatformType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(5))
+		})
+	})
+})
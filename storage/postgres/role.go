@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+	sqlxtypes "github.com/jmoiron/sqlx/types"
+)
+
+// Role entity
+//go:generate smgen storage Role github.com/Peripli/service-manager/pkg/types
+type Role struct {
+	BaseEntity
+	Name        string             `db:"name"`
+	Description sql.NullString     `db:"description"`
+	AccessLevel sql.NullString     `db:"access_level"`
+	Permissions sqlxtypes.JSONText `db:"permissions"`
+	Bindings    sqlxtypes.JSONText `db:"bindings"`
+}
+
+func (r *Role) ToObject() (types.Object, error) {
+	permissions := make([]*types.Permission, 0)
+	if r.Permissions.String() != "" {
+		if err := util.BytesToObject(getJSONRawMessage(r.Permissions), &permissions); err != nil {
+			return nil, err
+		}
+	}
+	bindings := make([]*types.RoleBinding, 0)
+	if r.Bindings.String() != "" {
+		if err := util.BytesToObject(getJSONRawMessage(r.Bindings), &bindings); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.Role{
+		Base: types.Base{
+			ID:             r.ID,
+			CreatedAt:      r.CreatedAt,
+			UpdatedAt:      r.UpdatedAt,
+			Labels:         make(map[string][]string),
+			PagingSequence: r.PagingSequence,
+			Ready:          r.Ready,
+		},
+		Name:        r.Name,
+		Description: r.Description.String,
+		AccessLevel: r.AccessLevel.String,
+		Permissions: permissions,
+		Bindings:    bindings,
+	}, nil
+}
+
+func (*Role) FromObject(object types.Object) (storage.Entity, error) {
+	role, ok := object.(*types.Role)
+	if !ok {
+		return nil, fmt.Errorf("object is not of type Role")
+	}
+	if role.Permissions == nil {
+		role.Permissions = make([]*types.Permission, 0)
+	}
+	if role.Bindings == nil {
+		role.Bindings = make([]*types.RoleBinding, 0)
+	}
+	permissionsBytes, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	bindingsBytes, err := json.Marshal(role.Bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Role{
+		BaseEntity: BaseEntity{
+			ID:             role.ID,
+			CreatedAt:      role.CreatedAt,
+			UpdatedAt:      role.UpdatedAt,
+			PagingSequence: role.PagingSequence,
+			Ready:          role.Ready,
+		},
+		Name:        role.Name,
+		Description: toNullString(role.Description),
+		AccessLevel: toNullString(role.AccessLevel),
+		Permissions: getJSONText(permissionsBytes),
+		Bindings:    getJSONText(bindingsBytes),
+	}, nil
+}
@@ -0,0 +1,34 @@
+// GENERATED. DO NOT MODIFY!
+
+package postgres
+
+import (
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/jmoiron/sqlx"
+)
+
+var _ LabeledEntity = &Role{}
+
+const RoleTable = "roles"
+
+func (*Role) TableName() string {
+	return RoleTable
+}
+
+func (e *Role) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
+	rowCreator := func() PostgresEntity {
+		return &Role{}
+	}
+	result := e.NewList()
+	err := rowsToList(rows, rowCreator, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (*Role) NewList() types.ObjectList {
+	return &types.Roles{
+		Roles: make([]*types.Role, 0),
+	}
+}
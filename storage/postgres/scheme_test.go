@@ -242,14 +242,6 @@ func (storageEntity) FromObject(object types.Object) (storage.Entity, error) {
 	return &storageEntity{}, nil
 }
 
-func (storageEntity) BuildLabels(labels types.Labels, newLabel func(id, key, value string) storage.Label) ([]storage.Label, error) {
-	return []storage.Label{}, nil
-}
-
-func (storageEntity) NewLabel(id, key, value string) storage.Label {
-	return nil
-}
-
 type postgresEntity struct {
 	*storageEntity
 }
@@ -262,10 +254,6 @@ func (postgresEntity) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
 	return nil, nil
 }
 
-func (postgresEntity) LabelEntity() PostgresLabel {
-	return nil
-}
-
 func (postgresEntity) FromObject(object types.Object) (storage.Entity, error) {
 	return &postgresEntity{}, nil
 }
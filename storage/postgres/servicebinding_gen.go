@@ -4,53 +4,22 @@ package postgres
 
 import (
 	"github.com/Peripli/service-manager/pkg/types"
-	"github.com/Peripli/service-manager/storage"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
-
-	"database/sql"
-	"time"
 )
 
-var _ PostgresEntity = &ServiceBinding{}
+var _ LabeledEntity = &ServiceBinding{}
 
 const ServiceBindingTable = "service_bindings"
 
-func (*ServiceBinding) LabelEntity() PostgresLabel {
-	return &ServiceBindingLabel{}
-}
-
 func (*ServiceBinding) TableName() string {
 	return ServiceBindingTable
 }
 
-func (e *ServiceBinding) NewLabel(id, key, value string) storage.Label {
-	now := pq.NullTime{
-		Time:  time.Now(),
-		Valid: true,
-	}
-	return &ServiceBindingLabel{
-		BaseLabelEntity: BaseLabelEntity{
-			ID:        sql.NullString{String: id, Valid: id != ""},
-			Key:       sql.NullString{String: key, Valid: key != ""},
-			Val:       sql.NullString{String: value, Valid: value != ""},
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		ServiceBindingID: sql.NullString{String: e.ID, Valid: e.ID != ""},
-	}
-}
-
 func (e *ServiceBinding) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
-	rowCreator := func() EntityLabelRow {
-		return &struct {
-			*ServiceBinding
-			ServiceBindingLabel `db:"service_binding_labels"`
-		}{}
-	}
-	result := &types.ServiceBindings{
-		ServiceBindings: make([]*types.ServiceBinding, 0),
+	rowCreator := func() PostgresEntity {
+		return &ServiceBinding{}
 	}
+	result := e.NewList()
 	err := rowsToList(rows, rowCreator, result)
 	if err != nil {
 		return nil, err
@@ -58,15 +27,8 @@ func (e *ServiceBinding) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
 	return result, nil
 }
 
-type ServiceBindingLabel struct {
-	BaseLabelEntity
-	ServiceBindingID sql.NullString `db:"service_binding_id"`
-}
-
-func (el ServiceBindingLabel) LabelsTableName() string {
-	return "service_binding_labels"
-}
-
-func (el ServiceBindingLabel) ReferenceColumn() string {
-	return "service_binding_id"
+func (*ServiceBinding) NewList() types.ObjectList {
+	return &types.ServiceBindings{
+		ServiceBindings: make([]*types.ServiceBinding, 0),
+	}
 }
@@ -4,53 +4,22 @@ package postgres
 
 import (
 	"github.com/Peripli/service-manager/pkg/types"
-	"github.com/Peripli/service-manager/storage"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
-
-	"database/sql"
-	"time"
 )
 
-var _ PostgresEntity = &ServicePlan{}
+var _ LabeledEntity = &ServicePlan{}
 
 const ServicePlanTable = "service_plans"
 
-func (*ServicePlan) LabelEntity() PostgresLabel {
-	return &ServicePlanLabel{}
-}
-
 func (*ServicePlan) TableName() string {
 	return ServicePlanTable
 }
 
-func (e *ServicePlan) NewLabel(id, key, value string) storage.Label {
-	now := pq.NullTime{
-		Time:  time.Now(),
-		Valid: true,
-	}
-	return &ServicePlanLabel{
-		BaseLabelEntity: BaseLabelEntity{
-			ID:        sql.NullString{String: id, Valid: id != ""},
-			Key:       sql.NullString{String: key, Valid: key != ""},
-			Val:       sql.NullString{String: value, Valid: value != ""},
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		ServicePlanID: sql.NullString{String: e.ID, Valid: e.ID != ""},
-	}
-}
-
 func (e *ServicePlan) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
-	rowCreator := func() EntityLabelRow {
-		return &struct {
-			*ServicePlan
-			ServicePlanLabel `db:"service_plan_labels"`
-		}{}
-	}
-	result := &types.ServicePlans{
-		ServicePlans: make([]*types.ServicePlan, 0),
+	rowCreator := func() PostgresEntity {
+		return &ServicePlan{}
 	}
+	result := e.NewList()
 	err := rowsToList(rows, rowCreator, result)
 	if err != nil {
 		return nil, err
@@ -58,15 +27,8 @@ func (e *ServicePlan) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
 	return result, nil
 }
 
-type ServicePlanLabel struct {
-	BaseLabelEntity
-	ServicePlanID sql.NullString `db:"service_plan_id"`
-}
-
-func (el ServicePlanLabel) LabelsTableName() string {
-	return "service_plan_labels"
-}
-
-func (el ServicePlanLabel) ReferenceColumn() string {
-	return "service_plan_id"
+func (*ServicePlan) NewList() types.ObjectList {
+	return &types.ServicePlans{
+		ServicePlans: make([]*types.ServicePlan, 0),
+	}
 }
@@ -38,8 +38,10 @@ import (
 )
 
 const (
-	postgresDriverName  = "postgres"
-	foreignKeyViolation = "foreign_key_violation"
+	postgresDriverName   = "postgres"
+	foreignKeyViolation  = "foreign_key_violation"
+	serializationFailure = "serialization_failure"
+	deadlockDetected     = "deadlock_detected"
 )
 
 type Storage struct {
@@ -48,10 +50,15 @@ type Storage struct {
 	pgDB                  pgDB
 	db                    *sqlx.DB
 	queryBuilder          *QueryBuilder
+	replicas              *replicaSet
 	state                 *storageState
 	layerOneEncryptionKey []byte
 	scheme                *scheme
+	connectionCreator     notificationConnectionCreator
 	mutex                 sync.Mutex
+
+	transactionRetries       int
+	transactionRetryInterval time.Duration
 }
 
 func (ps *Storage) Introduce(entity storage.Entity) {
@@ -88,9 +95,20 @@ func (ps *Storage) Open(settings *storage.Settings) error {
 			storageCheckInterval: time.Second * 5,
 		}
 		ps.layerOneEncryptionKey = []byte(settings.EncryptionKey)
+		ps.connectionCreator = &notificationConnectionCreatorImpl{
+			storageURI:           settings.URI,
+			skipSSLValidation:    settings.SkipSSLValidation,
+			minReconnectInterval: settings.Notification.MinReconnectInterval,
+			maxReconnectInterval: settings.Notification.MaxReconnectInterval,
+		}
+		ps.transactionRetries = settings.TransactionRetries
+		ps.transactionRetryInterval = settings.TransactionRetryInterval
 		ps.db.SetMaxIdleConns(settings.MaxIdleConnections)
 		ps.pgDB = ps.db
 		ps.queryBuilder = NewQueryBuilder(ps.pgDB)
+		if err := ps.connectReplicas(settings.ReadURIs, sslModeParam, settings.MaxIdleConnections); err != nil {
+			return err
+		}
 
 		log.D().Debugf("Updating database schema using migrations from %s", settings.MigrationsURL)
 		if err := ps.updateSchema(settings.MigrationsURL, postgresDriverName); err != nil {
@@ -107,6 +125,8 @@ func (ps *Storage) Open(settings *storage.Settings) error {
 		ps.scheme.introduce(&ServiceInstance{})
 		ps.scheme.introduce(&ServiceBinding{})
 		ps.scheme.introduce(&BrokerPlatformCredential{})
+		ps.scheme.introduce(&Role{})
+		ps.scheme.introduce(&LabelDefinition{})
 	}
 
 	return nil
@@ -115,6 +135,11 @@ func (ps *Storage) Open(settings *storage.Settings) error {
 func (ps *Storage) Close() error {
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
+	if ps.replicas != nil {
+		if err := ps.replicas.close(); err != nil {
+			log.D().WithError(err).Error("Could not close read replica connections")
+		}
+	}
 	if ps.db != nil {
 		return ps.db.Close()
 	}
@@ -122,6 +147,53 @@ func (ps *Storage) Close() error {
 	return nil
 }
 
+// connectReplicas connects to the read replicas. The replicas are not used if there are no read URIs.
+func (ps *Storage) connectReplicas(readURIs []string, sslModeParam string, maxIdleConnections int) error {
+	if len(readURIs) == 0 {
+		return nil
+	}
+	replicas := &replicaSet{}
+	for i, readURI := range readURIs {
+		db, err := ps.ConnectFunc(postgresDriverName, readURI+sslModeParam)
+		if err != nil {
+			return fmt.Errorf("could not connect to PostgreSQL read replica %d: %s", i, err)
+		}
+		replicaDB := sqlx.NewDb(db, postgresDriverName)
+		replicaDB.SetMaxIdleConns(maxIdleConnections)
+		replicas.replicas = append(replicas.replicas, newReplica(i, replicaDB))
+	}
+	ps.replicas = replicas
+	log.D().Infof("Reads outside of transactions are balanced between %d read replicas", len(readURIs))
+	return nil
+}
+
+// read runs the read on an available replica if the reads with the context may be served by replicas and falls back
+// to the primary database if there is no available replica or the replica fails. Storages in transactions have no
+// replicas, so all their reads are served by the primary database.
+func (ps *Storage) read(ctx context.Context, read func(queryBuilder *QueryBuilder) error) error {
+	if ps.replicas == nil || !storage.CanReadFromReplica(ctx) {
+		return read(ps.queryBuilder)
+	}
+	r := ps.replicas.pick(ctx)
+	if r == nil {
+		return read(ps.queryBuilder)
+	}
+
+	err := read(r.queryBuilder)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if isConnectionFailure(err) {
+		r.markUnavailable(ctx, err)
+	} else if !IsRetriableTransactionError(err) {
+		// the query failed on its own, the primary database would fail it as well
+		return err
+	}
+	// queries on hot standby replicas may also be cancelled due to conflicts with the replication
+	log.C(ctx).WithError(err).Debugf("Read from replica %d failed, reading from the primary database", r.index)
+	return read(ps.queryBuilder)
+}
+
 func (ps *Storage) checkOpen() {
 	if ps.pgDB == nil {
 		log.D().Panicln("Storage is not yet open")
@@ -152,10 +224,19 @@ func (ps *Storage) PingContext(ctx context.Context) error {
 }
 
 func (ps *Storage) Create(ctx context.Context, obj types.Object) (types.Object, error) {
+	storage.RecordWrite(ctx)
 	pgEntity, err := ps.scheme.convert(obj)
 	if err != nil {
 		return nil, err
 	}
+	if labeledEntity, ok := pgEntity.(LabeledEntity); ok {
+		if err := validateLabels(obj.GetLabels()); err != nil {
+			return nil, err
+		}
+		if err := labeledEntity.SetLabels(obj.GetLabels()); err != nil {
+			return nil, err
+		}
+	}
 	result, err := ps.scheme.provide(obj.GetType())
 	if err != nil {
 		return nil, err
@@ -171,36 +252,9 @@ func (ps *Storage) Create(ctx context.Context, obj types.Object) (types.Object,
 	}
 	createdObj.SetLabels(obj.GetLabels())
 
-	var labels []storage.Label
-	if labels, err = pgEntity.BuildLabels(createdObj.GetLabels(), pgEntity.NewLabel); err != nil {
-		return nil, err
-	}
-
-	if err = ps.createLabels(ctx, createdObj.GetID(), labels); err != nil {
-		return nil, err
-	}
-
 	return createdObj, nil
 }
 
-func (ps *Storage) createLabels(ctx context.Context, entityID string, labels []storage.Label) error {
-	if err := validateLabels(labels); err != nil {
-		return err
-	}
-
-	for _, label := range labels {
-		pgLabel, ok := label.(PostgresLabel)
-		if !ok {
-			return fmt.Errorf("postgres storage requires labels to implement LabelEntity, got %T", label)
-		}
-		if err := create(ctx, ps.pgDB, pgLabel.LabelsTableName(), pgLabel, pgLabel); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (ps *Storage) Get(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.Object, error) {
 	result, err := ps.List(ctx, objectType, criteria...)
 	if err != nil {
@@ -236,13 +290,26 @@ func (ps *Storage) list(ctx context.Context, objType types.ObjectType, forUpdate
 	if err != nil {
 		return nil, err
 	}
+	if forUpdate {
+		return listEntities(ctx, ps.queryBuilder, entity, forUpdate, withLabels, criteria...)
+	}
+
+	var result types.ObjectList
+	err = ps.read(ctx, func(queryBuilder *QueryBuilder) (err error) {
+		result, err = listEntities(ctx, queryBuilder, entity, forUpdate, withLabels, criteria...)
+		return
+	})
+	return result, err
+}
 
-	queryBuilder := ps.queryBuilder.NewQuery(entity).WithCriteria(criteria...)
+func listEntities(ctx context.Context, qb *QueryBuilder, entity PostgresEntity, forUpdate, withLabels bool, criteria ...query.Criterion) (types.ObjectList, error) {
+	queryBuilder := qb.NewQuery(entity).WithCriteria(criteria...)
 	if forUpdate {
 		queryBuilder = queryBuilder.WithLock()
 	}
 
 	var rows *sqlx.Rows
+	var err error
 	if withLabels {
 		rows, err = queryBuilder.List(ctx)
 	} else {
@@ -260,9 +327,6 @@ func (ps *Storage) list(ctx context.Context, objType types.ObjectType, forUpdate
 			log.C(ctx).WithError(err).Error("Could not release connection when checking database")
 		}
 	}()
-	if err != nil {
-		return nil, err
-	}
 	return entity.RowsToList(rows)
 }
 
@@ -271,10 +335,77 @@ func (ps *Storage) Count(ctx context.Context, objType types.ObjectType, criteria
 	if err != nil {
 		return 0, err
 	}
-	return ps.queryBuilder.NewQuery(entity).WithCriteria(criteria...).Count(ctx)
+	var count int
+	err = ps.read(ctx, func(queryBuilder *QueryBuilder) (err error) {
+		count, err = queryBuilder.NewQuery(entity).WithCriteria(criteria...).Count(ctx)
+		return
+	})
+	return count, err
+}
+
+func (ps *Storage) CountLabelValues(ctx context.Context, objType types.ObjectType, criteria ...query.Criterion) ([]*types.LabelValueCount, error) {
+	entity, err := ps.scheme.provide(objType)
+	if err != nil {
+		return nil, err
+	}
+	var result []*types.LabelValueCount
+	err = ps.read(ctx, func(queryBuilder *QueryBuilder) error {
+		rows, err := queryBuilder.NewQuery(entity).WithCriteria(criteria...).LabelValues(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				log.C(ctx).WithError(err).Error("Could not release connection when checking database")
+			}
+		}()
+		result = make([]*types.LabelValueCount, 0)
+		for rows.Next() {
+			labelValueCount := &types.LabelValueCount{}
+			if err := rows.StructScan(labelValueCount); err != nil {
+				return err
+			}
+			result = append(result, labelValueCount)
+		}
+		return rows.Err()
+	})
+	return result, err
+}
+
+func (ps *Storage) Search(ctx context.Context, objType types.ObjectType, text string, criteria ...query.Criterion) ([]*types.SearchMatch, error) {
+	if !types.IsSearchable(objType) {
+		return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("search is not supported for %s", objType)}
+	}
+	entity, err := ps.scheme.provide(objType)
+	if err != nil {
+		return nil, err
+	}
+	var result []*types.SearchMatch
+	err = ps.read(ctx, func(queryBuilder *QueryBuilder) error {
+		rows, err := queryBuilder.NewQuery(entity).WithCriteria(criteria...).Search(ctx, text)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				log.C(ctx).WithError(err).Error("Could not release connection when checking database")
+			}
+		}()
+		result = make([]*types.SearchMatch, 0)
+		for rows.Next() {
+			match := &types.SearchMatch{}
+			if err := rows.StructScan(match); err != nil {
+				return err
+			}
+			result = append(result, match)
+		}
+		return rows.Err()
+	})
+	return result, err
 }
 
 func (ps *Storage) DeleteReturning(ctx context.Context, objType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+	storage.RecordWrite(ctx)
 	entity, err := ps.scheme.provide(objType)
 	if err != nil {
 		return nil, err
@@ -307,6 +438,7 @@ func (ps *Storage) DeleteReturning(ctx context.Context, objType types.ObjectType
 }
 
 func (ps *Storage) Delete(ctx context.Context, objType types.ObjectType, criteria ...query.Criterion) error {
+	storage.RecordWrite(ctx)
 	entity, err := ps.scheme.provide(objType)
 	if err != nil {
 		return err
@@ -331,6 +463,7 @@ func (ps *Storage) Delete(ctx context.Context, objType types.ObjectType, criteri
 }
 
 func (ps *Storage) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, _ ...query.Criterion) (types.Object, error) {
+	storage.RecordWrite(ctx)
 	obj.SetUpdatedAt(time.Now().UTC())
 
 	entity, err := ps.scheme.convert(obj)
@@ -340,7 +473,7 @@ func (ps *Storage) Update(ctx context.Context, obj types.Object, labelChanges ty
 	if err = update(ctx, ps.pgDB, entity.TableName(), entity); err != nil {
 		return nil, err
 	}
-	if err = ps.updateLabels(ctx, entity.GetID(), entity, labelChanges); err != nil {
+	if err = updateLabels(ctx, ps.pgDB, entity.TableName(), entity.GetID(), labelChanges); err != nil {
 		return nil, err
 	}
 
@@ -351,38 +484,52 @@ func (ps *Storage) Update(ctx context.Context, obj types.Object, labelChanges ty
 	return result, nil
 }
 
-func (ps *Storage) updateLabels(ctx context.Context, entityID string, entity PostgresEntity, updateActions []*types.LabelChange) error {
-	newLabelFunc := func(labelID string, labelKey string, labelValue string) (PostgresLabel, error) {
-		label := entity.NewLabel(labelID, labelKey, labelValue)
-		pgLabel, ok := label.(PostgresLabel)
-		if !ok {
-			return nil, fmt.Errorf("postgres storage requires labels to implement LabelEntity, got %T", label)
-		}
-		return pgLabel, nil
+// InTransaction runs the function in a transaction started with the given options. The transaction is retried if it
+// fails due to a serialization failure or a deadlock only if retries are requested with the options.
+func (ps *Storage) InTransaction(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error, opts ...storage.TransactionOption) error {
+	ps.checkOpen()
+	options := storage.NewTransactionOptions(opts...)
+	if !options.Retries {
+		return ps.inTransaction(ctx, f, options)
 	}
-	return updateLabelsAbstract(ctx, newLabelFunc, ps.pgDB, entityID, updateActions)
+	return storage.RetryTransaction(ctx, ps.transactionRetries, ps.transactionRetryInterval, IsRetriableTransactionError, func() error {
+		return ps.inTransaction(ctx, f, options)
+	})
 }
 
-func (ps *Storage) InTransaction(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error) error {
+func (ps *Storage) inTransaction(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error, options *storage.TransactionOptions) error {
 	ok := false
-	tx, err := ps.db.Beginx()
+	tx, err := ps.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: options.Isolation,
+		ReadOnly:  options.ReadOnly,
+	})
 	if err != nil {
 		return err
 	}
 	defer func() {
 		if !ok {
-			if txError := tx.Rollback(); txError != nil {
+			// the transaction is already rolled back if the context is done
+			if txError := tx.Rollback(); txError != nil && txError != sql.ErrTxDone {
 				log.C(ctx).Error("Could not rollback transaction", txError)
 			}
 		}
 	}()
 
+	if options.StatementTimeout > 0 {
+		timeout := int64(options.StatementTimeout / time.Millisecond)
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout)); err != nil {
+			return fmt.Errorf("could not set statement timeout of transaction: %s", err)
+		}
+	}
+
 	transactionalStorage := &Storage{
-		pgDB:                  tx,
-		db:                    ps.db,
-		queryBuilder:          NewQueryBuilder(tx),
-		scheme:                ps.scheme,
-		layerOneEncryptionKey: ps.layerOneEncryptionKey,
+		pgDB:                     tx,
+		db:                       ps.db,
+		queryBuilder:             NewQueryBuilder(tx),
+		scheme:                   ps.scheme,
+		layerOneEncryptionKey:    ps.layerOneEncryptionKey,
+		transactionRetries:       ps.transactionRetries,
+		transactionRetryInterval: ps.transactionRetryInterval,
 	}
 
 	if err = f(ctx, transactionalStorage); err != nil {
@@ -393,9 +540,26 @@ func (ps *Storage) InTransaction(ctx context.Context, f func(ctx context.Context
 		return err
 	}
 	ok = true
+	if !options.ReadOnly {
+		// the reads after the transaction must see its changes
+		storage.RecordWrite(ctx)
+	}
 	return nil
 }
 
+// IsRetriableTransactionError returns whether the transaction failed due to a serialization failure or a deadlock,
+// in which case it can be retried
+func IsRetriableTransactionError(err error) bool {
+	if badRequestErr, ok := err.(*util.ErrBadRequestStorage); ok {
+		err = badRequestErr.Cause
+	}
+	pqError, ok := err.(*pq.Error)
+	if !ok {
+		return false
+	}
+	return pqError.Code.Name() == serializationFailure || pqError.Code.Name() == deadlockDetected
+}
+
 type migrateLogger struct{}
 
 func (migrateLogger) Printf(format string, v ...interface{}) {
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transactions", func() {
+	var s *Storage
+	var mock sqlmock.Sqlmock
+	var calls int
+
+	serializationFailure := &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+
+	BeforeEach(func() {
+		var mockdb *sql.DB
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20200624100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		encryptionKey := make([]byte, 32)
+		_, err = rand.Read(encryptionKey)
+		Expect(err).ToNot(HaveOccurred())
+		settings := storage.DefaultSettings()
+		settings.EncryptionKey = string(encryptionKey)
+		settings.URI = "sqlmock://sqlmock"
+		settings.TransactionRetries = 2
+		settings.TransactionRetryInterval = time.Millisecond
+		Expect(s.Open(settings)).To(Succeed())
+
+		calls = 0
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		s.Close()
+	})
+
+	failing := func(errs ...error) func(ctx context.Context, repository storage.Repository) error {
+		return func(ctx context.Context, repository storage.Repository) error {
+			Expect(repository).ToNot(Equal(s))
+			calls++
+			if calls > len(errs) {
+				return nil
+			}
+			return errs[calls-1]
+		}
+	}
+
+	It("should commit the transaction if the function succeeds", func() {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		Expect(s.InTransaction(context.Background(), failing())).To(Succeed())
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should roll back the transaction if the function fails", func() {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		expectedErr := errors.New("expected")
+		Expect(s.InTransaction(context.Background(), failing(expectedErr))).To(Equal(expectedErr))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should set the statement timeout of the transaction", func() {
+		mock.ExpectBegin()
+		mock.ExpectExec("SET LOCAL statement_timeout = 1500").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		Expect(s.InTransaction(context.Background(), failing(), storage.WithStatementTimeout(1500*time.Millisecond))).To(Succeed())
+	})
+
+	It("should retry the transaction after a serialization failure", func() {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		Expect(s.InTransaction(context.Background(), failing(serializationFailure), storage.WithRetries())).To(Succeed())
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should not retry the transaction unless retries are requested", func() {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		Expect(s.InTransaction(context.Background(), failing(serializationFailure))).To(Equal(serializationFailure))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should retry the transaction if the commit fails due to a serialization failure", func() {
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(serializationFailure)
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		Expect(s.InTransaction(context.Background(), failing(), storage.WithRetries())).To(Succeed())
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should return the serialization failure once the retries are exhausted", func() {
+		for i := 0; i < 3; i++ {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+		}
+
+		err := s.InTransaction(context.Background(), failing(serializationFailure, serializationFailure, serializationFailure), storage.WithRetries())
+		Expect(err).To(Equal(serializationFailure))
+		Expect(calls).To(Equal(3))
+	})
+
+	Describe("IsRetriableTransactionError", func() {
+		It("should be true for serialization failures and deadlocks", func() {
+			Expect(IsRetriableTransactionError(serializationFailure)).To(BeTrue())
+			Expect(IsRetriableTransactionError(&pq.Error{Code: "40P01"})).To(BeTrue())
+			Expect(IsRetriableTransactionError(&util.ErrBadRequestStorage{Cause: &pq.Error{Code: "40P01"}})).To(BeTrue())
+		})
+
+		It("should be false for other errors", func() {
+			Expect(IsRetriableTransactionError(&pq.Error{Code: "23505"})).To(BeFalse())
+			Expect(IsRetriableTransactionError(util.ErrNotFoundInStorage)).To(BeFalse())
+		})
+	})
+})
@@ -17,17 +17,23 @@
 package postgres
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
 )
 
 type logicalOperator string
 
+// intLabelValueSQL casts the label value to a number if it is an integer. The pattern has no question mark, as it would
+// be taken for a bind variable.
+const intLabelValueSQL = "CASE WHEN label.value ~ '^[+-]{0,1}[0-9]+$' THEN label.value::numeric END"
+
 const (
-	AND       logicalOperator = "AND"
-	INTERSECT logicalOperator = "INTERSECT"
+	AND logicalOperator = "AND"
+	OR  logicalOperator = "OR"
 )
 
 // treeSqlBuilder is a helper struct to allow for dynamic changing of the function that builds the sql template statements
@@ -47,6 +53,10 @@ type whereClauseTree struct {
 	dbTags    []tagType
 	tableName string
 
+	// sql and sqlParams hold a leaf condition which cannot be expressed as a single criterion
+	sql       string
+	sqlParams []interface{}
+
 	children   []*whereClauseTree
 	sqlBuilder *treeSqlBuilder
 }
@@ -56,13 +66,16 @@ func (t *whereClauseTree) isLeaf() bool {
 }
 
 func (t *whereClauseTree) isEmpty() bool {
-	return t.criterion.Operator == nil && len(t.children) == 0
+	return t.criterion.Operator == nil && len(t.children) == 0 && len(t.sql) == 0
 }
 
 func (t *whereClauseTree) compileSQL() (string, []interface{}) {
 	if t.isEmpty() {
 		return "", []interface{}{}
 	}
+	if t.isLeaf() && len(t.sql) != 0 {
+		return t.sql, t.sqlParams
+	}
 	if t.isLeaf() {
 		sql, queryParam := criterionSQL(t.criterion, t.dbTags, t.tableName)
 		return sql, []interface{}{queryParam}
@@ -111,6 +124,53 @@ func criterionSQL(c query.Criterion, dbTags []tagType, tableAlias string) (strin
 	return clause, rightOpQueryValue
 }
 
+// labelCriterionSQL builds the condition of a label query on the labels column of the table. The equality and existence
+// queries are expressed as containment of the label in the labels, so that they are served by the GIN index of the column.
+// The other operators are applied to the values of the label key. The values of int labels are compared as numbers and the
+// values which are not integers do not match.
+func labelCriterionSQL(c query.Criterion, tableAlias string) (string, []interface{}, error) {
+	labelsColumn := fmt.Sprintf("%s.%s", tableAlias, LabelsColumn)
+	switch c.Operator {
+	case query.EqualsOperator:
+		fallthrough
+	case query.InOperator:
+		conditions := make([]string, 0, len(c.RightOp))
+		queryParams := make([]interface{}, 0, len(c.RightOp))
+		for _, value := range c.RightOp {
+			label, err := json.Marshal(types.Labels{c.LeftOp: {value}})
+			if err != nil {
+				return "", nil, fmt.Errorf("could not encode label query for key %s: %s", c.LeftOp, err)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s @> ?::jsonb", labelsColumn))
+			queryParams = append(queryParams, string(label))
+		}
+		return fmt.Sprintf("(%s)", strings.Join(conditions, fmt.Sprintf(" %s ", OR))), queryParams, nil
+	case query.ExistsOperator:
+		fallthrough
+	case query.NotExistsOperator:
+		// every label values array contains the empty array, so this matches exactly the entities having the key
+		label, err := json.Marshal(map[string][]string{c.LeftOp: {}})
+		if err != nil {
+			return "", nil, fmt.Errorf("could not encode label query for key %s: %s", c.LeftOp, err)
+		}
+		clause := fmt.Sprintf("%s @> ?::jsonb", labelsColumn)
+		if c.Operator == query.NotExistsOperator {
+			clause = fmt.Sprintf("NOT (%s)", clause)
+		}
+		return clause, []interface{}{string(label)}, nil
+	default:
+		rightOpBindVar, rightOpQueryValue := buildRightOp(c.Operator, c.RightOp)
+		sqlOperation := translateOperationToSQLEquivalent(c.Operator)
+		labelValue := "label.value"
+		if c.ValueType == types.LabelValueInt && c.Operator.IsNumeric() {
+			labelValue = intLabelValueSQL
+			rightOpBindVar = fmt.Sprintf("%s::numeric", rightOpBindVar)
+		}
+		clause := fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements_text(%s -> ?::text) AS label(value) WHERE %s %s %s)", labelsColumn, labelValue, sqlOperation, rightOpBindVar)
+		return clause, []interface{}{c.LeftOp, rightOpQueryValue}, nil
+	}
+}
+
 func buildRightOp(operator query.Operator, rightOp []string) (string, interface{}) {
 	rightOpBindVar := "?"
 	var rhs interface{}
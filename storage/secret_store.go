@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// NoSecretStore keeps the encrypted secrets in the database
+	NoSecretStore = ""
+	// FileSecretStore keeps the encrypted secrets in files on the local file system
+	FileSecretStore = "file"
+	// VaultSecretStore keeps the encrypted secrets in a Vault compatible key/value secrets engine (version 2)
+	VaultSecretStore = "vault"
+)
+
+// SecretStore stores secrets outside of the database. Secrets are addressed by a slash separated path.
+type SecretStore interface {
+	// Put stores the secret under the specified path, replacing any existing secret
+	Put(ctx context.Context, path string, secret []byte) error
+
+	// Get returns the secret stored under the specified path or util.ErrNotFoundInStorage if there is none
+	Get(ctx context.Context, path string) ([]byte, error)
+
+	// Delete deletes the secret stored under the specified path. Deleting a missing secret is not an error
+	Delete(ctx context.Context, path string) error
+}
+
+// SecretStoreSettings type to be loaded from the environment
+type SecretStoreSettings struct {
+	Type            string               `mapstructure:"type" description:"type of the external secret store in which credentials are kept instead of the database (file or vault). If not set credentials are kept in the database"`
+	MigrateExisting bool                 `mapstructure:"migrate_existing" description:"whether credentials which are still kept in the database should be moved to the secret store on startup"`
+	File            *FileSecretSettings  `mapstructure:"file"`
+	Vault           *VaultSecretSettings `mapstructure:"vault"`
+}
+
+// FileSecretSettings configures the file based secret store
+type FileSecretSettings struct {
+	Path string `mapstructure:"path" description:"directory in which the secrets are stored"`
+}
+
+// VaultSecretSettings configures the Vault compatible secret store
+type VaultSecretSettings struct {
+	Address string        `mapstructure:"address" description:"address of the vault server"`
+	Token   string        `mapstructure:"token" description:"token used to authenticate to the vault server"`
+	Mount   string        `mapstructure:"mount" description:"mount path of the key/value secrets engine"`
+	Prefix  string        `mapstructure:"prefix" description:"path prefix under which the secrets are stored"`
+	Timeout time.Duration `mapstructure:"timeout" description:"timeout of the requests to the vault server"`
+}
+
+// DefaultSecretStoreSettings returns default values for the secret store settings
+func DefaultSecretStoreSettings() *SecretStoreSettings {
+	return &SecretStoreSettings{
+		Type:            NoSecretStore,
+		MigrateExisting: true,
+		File: &FileSecretSettings{
+			Path: "",
+		},
+		Vault: &VaultSecretSettings{
+			Address: "",
+			Token:   "",
+			Mount:   "secret",
+			Prefix:  "service-manager",
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Validate validates the secret store settings
+func (s *SecretStoreSettings) Validate() error {
+	switch s.Type {
+	case NoSecretStore:
+	case FileSecretStore:
+		if s.File == nil || len(s.File.Path) == 0 {
+			return fmt.Errorf("validate Settings: path of file secret store missing")
+		}
+	case VaultSecretStore:
+		if s.Vault == nil || len(s.Vault.Address) == 0 {
+			return fmt.Errorf("validate Settings: address of vault secret store missing")
+		}
+		if len(s.Vault.Mount) == 0 {
+			return fmt.Errorf("validate Settings: mount of vault secret store missing")
+		}
+		if s.Vault.Timeout <= 0 {
+			return fmt.Errorf("validate Settings: timeout of vault secret store should be > 0")
+		}
+	default:
+		return fmt.Errorf("validate Settings: unsupported secret store type %s", s.Type)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// FileStore keeps each secret in a separate file below a root directory
+type FileStore struct {
+	root string
+}
+
+// NewFileStore returns a file based secret store which keeps the secrets below the specified directory
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("could not create secret store directory %s: %s", root, err)
+	}
+	return &FileStore{
+		root: root,
+	}, nil
+}
+
+// Put implements storage.SecretStore. The secret is written to a temporary file which then replaces the
+// existing one, so that readers never see a partially written secret.
+func (s *FileStore) Put(_ context.Context, path string, secret []byte) error {
+	fileName, err := s.fileName(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(fileName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("could not create directory for secret %s: %s", path, err)
+	}
+
+	tmpFile, err := ioutil.TempFile(dir, ".secret")
+	if err != nil {
+		return fmt.Errorf("could not store secret %s: %s", path, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(secret); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("could not store secret %s: %s", path, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("could not store secret %s: %s", path, err)
+	}
+	if err := os.Rename(tmpFile.Name(), fileName); err != nil {
+		return fmt.Errorf("could not store secret %s: %s", path, err)
+	}
+	return nil
+}
+
+// Get implements storage.SecretStore
+func (s *FileStore) Get(_ context.Context, path string) ([]byte, error) {
+	fileName, err := s.fileName(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, util.ErrNotFoundInStorage
+		}
+		return nil, fmt.Errorf("could not read secret %s: %s", path, err)
+	}
+	return secret, nil
+}
+
+// Delete implements storage.SecretStore
+func (s *FileStore) Delete(_ context.Context, path string) error {
+	fileName, err := s.fileName(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not delete secret %s: %s", path, err)
+	}
+	return nil
+}
+
+func (s *FileStore) fileName(path string) (string, error) {
+	if err := validatePath(path); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(path)), nil
+}
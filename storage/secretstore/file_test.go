@@ -0,0 +1,75 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package secretstore_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage/secretstore"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File secret store", func() {
+	var (
+		ctx   context.Context
+		root  string
+		store *secretstore.FileStore
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		root, err = ioutil.TempDir("", "secrets")
+		Expect(err).ToNot(HaveOccurred())
+		store, err = secretstore.NewFileStore(root)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(root)).To(Succeed())
+	})
+
+	It("stores, replaces and deletes secrets", func() {
+		Expect(store.Put(ctx, "service_brokers/id/v1", []byte("secret"))).To(Succeed())
+		secret, err := store.Get(ctx, "service_brokers/id/v1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(secret)).To(Equal("secret"))
+
+		Expect(store.Put(ctx, "service_brokers/id/v1", []byte("new secret"))).To(Succeed())
+		secret, err = store.Get(ctx, "service_brokers/id/v1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(secret)).To(Equal("new secret"))
+
+		Expect(store.Delete(ctx, "service_brokers/id/v1")).To(Succeed())
+		_, err = store.Get(ctx, "service_brokers/id/v1")
+		Expect(err).To(Equal(util.ErrNotFoundInStorage))
+	})
+
+	It("does not fail when deleting a missing secret", func() {
+		Expect(store.Delete(ctx, "service_brokers/missing")).To(Succeed())
+	})
+
+	It("rejects paths outside of the store", func() {
+		Expect(store.Put(ctx, "../outside", []byte("secret"))).To(HaveOccurred())
+		_, err := store.Get(ctx, "service_brokers/../../outside")
+		Expect(err).To(HaveOccurred())
+	})
+})
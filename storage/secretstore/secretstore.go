@@ -0,0 +1,55 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package secretstore contains the implementations of storage.SecretStore
+package secretstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Peripli/service-manager/storage"
+)
+
+// New returns the secret store configured in the settings or nil if credentials should be kept in the database
+func New(settings *storage.SecretStoreSettings) (storage.SecretStore, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	switch settings.Type {
+	case storage.NoSecretStore:
+		return nil, nil
+	case storage.FileSecretStore:
+		return NewFileStore(settings.File.Path)
+	case storage.VaultSecretStore:
+		return NewVaultStore(settings.Vault), nil
+	default:
+		return nil, fmt.Errorf("unsupported secret store type %s", settings.Type)
+	}
+}
+
+// validatePath ensures that the secret path cannot escape the location of the secret store
+func validatePath(path string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("secret path must not be empty")
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid secret path %s", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/httpclient"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const vaultTokenHeader = "X-Vault-Token"
+
+// VaultStore keeps the secrets in a Vault compatible key/value secrets engine (version 2).
+// Each secret is stored as the value field of the secret data.
+type VaultStore struct {
+	client  *http.Client
+	address string
+	token   string
+	mount   string
+	prefix  string
+}
+
+type vaultSecretData struct {
+	Value []byte `json:"value"`
+}
+
+type vaultSecret struct {
+	Data vaultSecretData `json:"data"`
+}
+
+type vaultReadResponse struct {
+	Data vaultSecret `json:"data"`
+}
+
+// NewVaultStore returns a secret store which keeps the secrets in a Vault compatible key/value secrets engine
+func NewVaultStore(settings *storage.VaultSecretSettings) *VaultStore {
+	transport := &http.Transport{}
+	httpclient.ConfigureTransport(transport)
+	return &VaultStore{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   settings.Timeout,
+		},
+		address: strings.TrimRight(settings.Address, "/"),
+		token:   settings.Token,
+		mount:   strings.Trim(settings.Mount, "/"),
+		prefix:  strings.Trim(settings.Prefix, "/"),
+	}
+}
+
+// Put implements storage.SecretStore
+func (s *VaultStore) Put(ctx context.Context, path string, secret []byte) error {
+	body, err := json.Marshal(vaultSecret{Data: vaultSecretData{Value: secret}})
+	if err != nil {
+		return err
+	}
+	response, err := s.do(ctx, http.MethodPost, "data", path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return vaultError(response, "store", path)
+	}
+	return nil
+}
+
+// Get implements storage.SecretStore
+func (s *VaultStore) Get(ctx context.Context, path string) ([]byte, error) {
+	response, err := s.do(ctx, http.MethodGet, "data", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, util.ErrNotFoundInStorage
+	default:
+		return nil, vaultError(response, "read", path)
+	}
+
+	secret := &vaultReadResponse{}
+	if err := json.NewDecoder(response.Body).Decode(secret); err != nil {
+		return nil, fmt.Errorf("could not decode secret %s: %s", path, err)
+	}
+	return secret.Data.Data.Value, nil
+}
+
+// Delete implements storage.SecretStore. All versions of the secret are deleted.
+func (s *VaultStore) Delete(ctx context.Context, path string) error {
+	response, err := s.do(ctx, http.MethodDelete, "metadata", path, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusNotFound {
+		return vaultError(response, "delete", path)
+	}
+	return nil
+}
+
+func (s *VaultStore) do(ctx context.Context, method, endpoint, path string, body io.Reader) (*http.Response, error) {
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+	if len(s.prefix) != 0 {
+		path = s.prefix + "/" + path
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", s.address, s.mount, endpoint, path)
+	request, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set(vaultTokenHeader, s.token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach vault secret store: %s", err)
+	}
+	return response, nil
+}
+
+func vaultError(response *http.Response, operation, path string) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("could not %s secret %s: vault responded with status %d: %s", operation, path, response.StatusCode, strings.TrimSpace(string(body)))
+}
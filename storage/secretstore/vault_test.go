@@ -0,0 +1,144 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package secretstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/secretstore"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// vaultStandIn emulates the parts of the key/value secrets engine (version 2) used by the vault secret store
+type vaultStandIn struct {
+	mutex   sync.Mutex
+	token   string
+	secrets map[string]json.RawMessage
+}
+
+func (v *vaultStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != v.token {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		var body struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		v.secrets[strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")] = body.Data
+		w.Write([]byte(`{"data":{"version":1}}`))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		data, found := v.secrets[strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": data},
+		})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+		delete(v.secrets, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+var _ = Describe("Vault secret store", func() {
+	var (
+		ctx      context.Context
+		standIn  *vaultStandIn
+		server   *httptest.Server
+		settings *storage.VaultSecretSettings
+		store    *secretstore.VaultStore
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		standIn = &vaultStandIn{
+			token:   "root",
+			secrets: make(map[string]json.RawMessage),
+		}
+		server = httptest.NewServer(standIn)
+		settings = &storage.VaultSecretSettings{
+			Address: server.URL,
+			Token:   "root",
+			Mount:   "secret",
+			Prefix:  "sm",
+			Timeout: 5 * time.Second,
+		}
+		store = secretstore.NewVaultStore(settings)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("stores secrets below the prefix", func() {
+		Expect(store.Put(ctx, "platforms/id/v1", []byte("secret"))).To(Succeed())
+		Expect(standIn.secrets).To(HaveKey("sm/platforms/id/v1"))
+
+		secret, err := store.Get(ctx, "platforms/id/v1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(secret)).To(Equal("secret"))
+	})
+
+	It("deletes secrets", func() {
+		Expect(store.Put(ctx, "platforms/id/v1", []byte("secret"))).To(Succeed())
+		Expect(store.Delete(ctx, "platforms/id/v1")).To(Succeed())
+
+		_, err := store.Get(ctx, "platforms/id/v1")
+		Expect(err).To(Equal(util.ErrNotFoundInStorage))
+	})
+
+	It("fails if the token is rejected", func() {
+		settings.Token = "invalid"
+		store = secretstore.NewVaultStore(settings)
+
+		err := store.Put(ctx, "platforms/id/v1", []byte("secret"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("status 403"))
+	})
+
+	It("fails if the server cannot be reached", func() {
+		server.Close()
+
+		_, err := store.Get(ctx, "platforms/id/v1")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("could not reach vault secret store"))
+	})
+})
@@ -10,11 +10,12 @@ import (
 )
 
 type FakeNotificator struct {
-	RegisterConsumerStub        func(*types.Platform, int64) (storage.NotificationQueue, int64, error)
+	RegisterConsumerStub        func(*types.Platform, int64, *storage.NotificationSubscription) (storage.NotificationQueue, int64, error)
 	registerConsumerMutex       sync.RWMutex
 	registerConsumerArgsForCall []struct {
 		arg1 *types.Platform
 		arg2 int64
+		arg3 *storage.NotificationSubscription
 	}
 	registerConsumerReturns struct {
 		result1 storage.NotificationQueue
@@ -26,6 +27,23 @@ type FakeNotificator struct {
 		result2 int64
 		result3 error
 	}
+	RegisterSnapshotConsumerStub        func(*types.Platform, storage.NotificationsSnapshotFunc, *storage.NotificationSubscription) (storage.NotificationQueue, int64, error)
+	registerSnapshotConsumerMutex       sync.RWMutex
+	registerSnapshotConsumerArgsForCall []struct {
+		arg1 *types.Platform
+		arg2 storage.NotificationsSnapshotFunc
+		arg3 *storage.NotificationSubscription
+	}
+	registerSnapshotConsumerReturns struct {
+		result1 storage.NotificationQueue
+		result2 int64
+		result3 error
+	}
+	registerSnapshotConsumerReturnsOnCall map[int]struct {
+		result1 storage.NotificationQueue
+		result2 int64
+		result3 error
+	}
 	RegisterFilterStub        func(storage.ReceiversFilterFunc)
 	registerFilterMutex       sync.RWMutex
 	registerFilterArgsForCall []struct {
@@ -58,17 +76,18 @@ type FakeNotificator struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeNotificator) RegisterConsumer(arg1 *types.Platform, arg2 int64) (storage.NotificationQueue, int64, error) {
+func (fake *FakeNotificator) RegisterConsumer(arg1 *types.Platform, arg2 int64, arg3 *storage.NotificationSubscription) (storage.NotificationQueue, int64, error) {
 	fake.registerConsumerMutex.Lock()
 	ret, specificReturn := fake.registerConsumerReturnsOnCall[len(fake.registerConsumerArgsForCall)]
 	fake.registerConsumerArgsForCall = append(fake.registerConsumerArgsForCall, struct {
 		arg1 *types.Platform
 		arg2 int64
-	}{arg1, arg2})
-	fake.recordInvocation("RegisterConsumer", []interface{}{arg1, arg2})
+		arg3 *storage.NotificationSubscription
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("RegisterConsumer", []interface{}{arg1, arg2, arg3})
 	fake.registerConsumerMutex.Unlock()
 	if fake.RegisterConsumerStub != nil {
-		return fake.RegisterConsumerStub(arg1, arg2)
+		return fake.RegisterConsumerStub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
@@ -83,17 +102,17 @@ func (fake *FakeNotificator) RegisterConsumerCallCount() int {
 	return len(fake.registerConsumerArgsForCall)
 }
 
-func (fake *FakeNotificator) RegisterConsumerCalls(stub func(*types.Platform, int64) (storage.NotificationQueue, int64, error)) {
+func (fake *FakeNotificator) RegisterConsumerCalls(stub func(*types.Platform, int64, *storage.NotificationSubscription) (storage.NotificationQueue, int64, error)) {
 	fake.registerConsumerMutex.Lock()
 	defer fake.registerConsumerMutex.Unlock()
 	fake.RegisterConsumerStub = stub
 }
 
-func (fake *FakeNotificator) RegisterConsumerArgsForCall(i int) (*types.Platform, int64) {
+func (fake *FakeNotificator) RegisterConsumerArgsForCall(i int) (*types.Platform, int64, *storage.NotificationSubscription) {
 	fake.registerConsumerMutex.RLock()
 	defer fake.registerConsumerMutex.RUnlock()
 	argsForCall := fake.registerConsumerArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeNotificator) RegisterConsumerReturns(result1 storage.NotificationQueue, result2 int64, result3 error) {
@@ -125,6 +144,74 @@ func (fake *FakeNotificator) RegisterConsumerReturnsOnCall(i int, result1 storag
 	}{result1, result2, result3}
 }
 
+func (fake *FakeNotificator) RegisterSnapshotConsumer(arg1 *types.Platform, arg2 storage.NotificationsSnapshotFunc, arg3 *storage.NotificationSubscription) (storage.NotificationQueue, int64, error) {
+	fake.registerSnapshotConsumerMutex.Lock()
+	ret, specificReturn := fake.registerSnapshotConsumerReturnsOnCall[len(fake.registerSnapshotConsumerArgsForCall)]
+	fake.registerSnapshotConsumerArgsForCall = append(fake.registerSnapshotConsumerArgsForCall, struct {
+		arg1 *types.Platform
+		arg2 storage.NotificationsSnapshotFunc
+		arg3 *storage.NotificationSubscription
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("RegisterSnapshotConsumer", []interface{}{arg1, arg2, arg3})
+	fake.registerSnapshotConsumerMutex.Unlock()
+	if fake.RegisterSnapshotConsumerStub != nil {
+		return fake.RegisterSnapshotConsumerStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	fakeReturns := fake.registerSnapshotConsumerReturns
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeNotificator) RegisterSnapshotConsumerCallCount() int {
+	fake.registerSnapshotConsumerMutex.RLock()
+	defer fake.registerSnapshotConsumerMutex.RUnlock()
+	return len(fake.registerSnapshotConsumerArgsForCall)
+}
+
+func (fake *FakeNotificator) RegisterSnapshotConsumerCalls(stub func(*types.Platform, storage.NotificationsSnapshotFunc, *storage.NotificationSubscription) (storage.NotificationQueue, int64, error)) {
+	fake.registerSnapshotConsumerMutex.Lock()
+	defer fake.registerSnapshotConsumerMutex.Unlock()
+	fake.RegisterSnapshotConsumerStub = stub
+}
+
+func (fake *FakeNotificator) RegisterSnapshotConsumerArgsForCall(i int) (*types.Platform, storage.NotificationsSnapshotFunc, *storage.NotificationSubscription) {
+	fake.registerSnapshotConsumerMutex.RLock()
+	defer fake.registerSnapshotConsumerMutex.RUnlock()
+	argsForCall := fake.registerSnapshotConsumerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeNotificator) RegisterSnapshotConsumerReturns(result1 storage.NotificationQueue, result2 int64, result3 error) {
+	fake.registerSnapshotConsumerMutex.Lock()
+	defer fake.registerSnapshotConsumerMutex.Unlock()
+	fake.RegisterSnapshotConsumerStub = nil
+	fake.registerSnapshotConsumerReturns = struct {
+		result1 storage.NotificationQueue
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeNotificator) RegisterSnapshotConsumerReturnsOnCall(i int, result1 storage.NotificationQueue, result2 int64, result3 error) {
+	fake.registerSnapshotConsumerMutex.Lock()
+	defer fake.registerSnapshotConsumerMutex.Unlock()
+	fake.RegisterSnapshotConsumerStub = nil
+	if fake.registerSnapshotConsumerReturnsOnCall == nil {
+		fake.registerSnapshotConsumerReturnsOnCall = make(map[int]struct {
+			result1 storage.NotificationQueue
+			result2 int64
+			result3 error
+		})
+	}
+	fake.registerSnapshotConsumerReturnsOnCall[i] = struct {
+		result1 storage.NotificationQueue
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeNotificator) RegisterFilter(arg1 storage.ReceiversFilterFunc) {
 	fake.registerFilterMutex.Lock()
 	fake.registerFilterArgsForCall = append(fake.registerFilterArgsForCall, struct {
@@ -284,6 +371,8 @@ func (fake *FakeNotificator) Invocations() map[string][][]interface{} {
 	defer fake.registerConsumerMutex.RUnlock()
 	fake.registerFilterMutex.RLock()
 	defer fake.registerFilterMutex.RUnlock()
+	fake.registerSnapshotConsumerMutex.RLock()
+	defer fake.registerSnapshotConsumerMutex.RUnlock()
 	fake.startMutex.RLock()
 	defer fake.startMutex.RUnlock()
 	fake.unregisterConsumerMutex.RLock()
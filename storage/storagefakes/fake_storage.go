@@ -36,6 +36,21 @@ type FakeStorage struct {
 		result1 int
 		result2 error
 	}
+	CountLabelValuesStub        func(context.Context, types.ObjectType, ...query.Criterion) ([]*types.LabelValueCount, error)
+	countLabelValuesMutex       sync.RWMutex
+	countLabelValuesArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 []query.Criterion
+	}
+	countLabelValuesReturns struct {
+		result1 []*types.LabelValueCount
+		result2 error
+	}
+	countLabelValuesReturnsOnCall map[int]struct {
+		result1 []*types.LabelValueCount
+		result2 error
+	}
 	CreateStub        func(context.Context, types.Object) (types.Object, error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
@@ -108,11 +123,12 @@ type FakeStorage struct {
 		result1 types.Object
 		result2 error
 	}
-	InTransactionStub        func(context.Context, func(ctx context.Context, storage storage.Repository) error) error
+	InTransactionStub        func(context.Context, func(ctx context.Context, storage storage.Repository) error, ...storage.TransactionOption) error
 	inTransactionMutex       sync.RWMutex
 	inTransactionArgsForCall []struct {
 		arg1 context.Context
 		arg2 func(ctx context.Context, storage storage.Repository) error
+		arg3 []storage.TransactionOption
 	}
 	inTransactionReturns struct {
 		result1 error
@@ -177,6 +193,22 @@ type FakeStorage struct {
 	pingContextReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SearchStub        func(context.Context, types.ObjectType, string, ...query.Criterion) ([]*types.SearchMatch, error)
+	searchMutex       sync.RWMutex
+	searchArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 string
+		arg4 []query.Criterion
+	}
+	searchReturns struct {
+		result1 []*types.SearchMatch
+		result2 error
+	}
+	searchReturnsOnCall map[int]struct {
+		result1 []*types.SearchMatch
+		result2 error
+	}
 	UpdateStub        func(context.Context, types.Object, types.LabelChanges, ...query.Criterion) (types.Object, error)
 	updateMutex       sync.RWMutex
 	updateArgsForCall []struct {
@@ -314,6 +346,71 @@ func (fake *FakeStorage) CountReturnsOnCall(i int, result1 int, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeStorage) CountLabelValues(arg1 context.Context, arg2 types.ObjectType, arg3 ...query.Criterion) ([]*types.LabelValueCount, error) {
+	fake.countLabelValuesMutex.Lock()
+	ret, specificReturn := fake.countLabelValuesReturnsOnCall[len(fake.countLabelValuesArgsForCall)]
+	fake.countLabelValuesArgsForCall = append(fake.countLabelValuesArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 []query.Criterion
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("CountLabelValues", []interface{}{arg1, arg2, arg3})
+	fake.countLabelValuesMutex.Unlock()
+	if fake.CountLabelValuesStub != nil {
+		return fake.CountLabelValuesStub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.countLabelValuesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) CountLabelValuesCallCount() int {
+	fake.countLabelValuesMutex.RLock()
+	defer fake.countLabelValuesMutex.RUnlock()
+	return len(fake.countLabelValuesArgsForCall)
+}
+
+func (fake *FakeStorage) CountLabelValuesCalls(stub func(context.Context, types.ObjectType, ...query.Criterion) ([]*types.LabelValueCount, error)) {
+	fake.countLabelValuesMutex.Lock()
+	defer fake.countLabelValuesMutex.Unlock()
+	fake.CountLabelValuesStub = stub
+}
+
+func (fake *FakeStorage) CountLabelValuesArgsForCall(i int) (context.Context, types.ObjectType, []query.Criterion) {
+	fake.countLabelValuesMutex.RLock()
+	defer fake.countLabelValuesMutex.RUnlock()
+	argsForCall := fake.countLabelValuesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeStorage) CountLabelValuesReturns(result1 []*types.LabelValueCount, result2 error) {
+	fake.countLabelValuesMutex.Lock()
+	defer fake.countLabelValuesMutex.Unlock()
+	fake.CountLabelValuesStub = nil
+	fake.countLabelValuesReturns = struct {
+		result1 []*types.LabelValueCount
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) CountLabelValuesReturnsOnCall(i int, result1 []*types.LabelValueCount, result2 error) {
+	fake.countLabelValuesMutex.Lock()
+	defer fake.countLabelValuesMutex.Unlock()
+	fake.CountLabelValuesStub = nil
+	if fake.countLabelValuesReturnsOnCall == nil {
+		fake.countLabelValuesReturnsOnCall = make(map[int]struct {
+			result1 []*types.LabelValueCount
+			result2 error
+		})
+	}
+	fake.countLabelValuesReturnsOnCall[i] = struct {
+		result1 []*types.LabelValueCount
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) Create(arg1 context.Context, arg2 types.Object) (types.Object, error) {
 	fake.createMutex.Lock()
 	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
@@ -635,17 +732,18 @@ func (fake *FakeStorage) GetForUpdateReturnsOnCall(i int, result1 types.Object,
 	}{result1, result2}
 }
 
-func (fake *FakeStorage) InTransaction(arg1 context.Context, arg2 func(ctx context.Context, storage storage.Repository) error) error {
+func (fake *FakeStorage) InTransaction(arg1 context.Context, arg2 func(ctx context.Context, storage storage.Repository) error, arg3 ...storage.TransactionOption) error {
 	fake.inTransactionMutex.Lock()
 	ret, specificReturn := fake.inTransactionReturnsOnCall[len(fake.inTransactionArgsForCall)]
 	fake.inTransactionArgsForCall = append(fake.inTransactionArgsForCall, struct {
 		arg1 context.Context
 		arg2 func(ctx context.Context, storage storage.Repository) error
-	}{arg1, arg2})
-	fake.recordInvocation("InTransaction", []interface{}{arg1, arg2})
+		arg3 []storage.TransactionOption
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("InTransaction", []interface{}{arg1, arg2, arg3})
 	fake.inTransactionMutex.Unlock()
 	if fake.InTransactionStub != nil {
-		return fake.InTransactionStub(arg1, arg2)
+		return fake.InTransactionStub(arg1, arg2, arg3...)
 	}
 	if specificReturn {
 		return ret.result1
@@ -660,17 +758,17 @@ func (fake *FakeStorage) InTransactionCallCount() int {
 	return len(fake.inTransactionArgsForCall)
 }
 
-func (fake *FakeStorage) InTransactionCalls(stub func(context.Context, func(ctx context.Context, storage storage.Repository) error) error) {
+func (fake *FakeStorage) InTransactionCalls(stub func(context.Context, func(ctx context.Context, storage storage.Repository) error, ...storage.TransactionOption) error) {
 	fake.inTransactionMutex.Lock()
 	defer fake.inTransactionMutex.Unlock()
 	fake.InTransactionStub = stub
 }
 
-func (fake *FakeStorage) InTransactionArgsForCall(i int) (context.Context, func(ctx context.Context, storage storage.Repository) error) {
+func (fake *FakeStorage) InTransactionArgsForCall(i int) (context.Context, func(ctx context.Context, storage storage.Repository) error, []storage.TransactionOption) {
 	fake.inTransactionMutex.RLock()
 	defer fake.inTransactionMutex.RUnlock()
 	argsForCall := fake.inTransactionArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeStorage) InTransactionReturns(result1 error) {
@@ -977,6 +1075,72 @@ func (fake *FakeStorage) PingContextReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeStorage) Search(arg1 context.Context, arg2 types.ObjectType, arg3 string, arg4 ...query.Criterion) ([]*types.SearchMatch, error) {
+	fake.searchMutex.Lock()
+	ret, specificReturn := fake.searchReturnsOnCall[len(fake.searchArgsForCall)]
+	fake.searchArgsForCall = append(fake.searchArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 string
+		arg4 []query.Criterion
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Search", []interface{}{arg1, arg2, arg3, arg4})
+	fake.searchMutex.Unlock()
+	if fake.SearchStub != nil {
+		return fake.SearchStub(arg1, arg2, arg3, arg4...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.searchReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) SearchCallCount() int {
+	fake.searchMutex.RLock()
+	defer fake.searchMutex.RUnlock()
+	return len(fake.searchArgsForCall)
+}
+
+func (fake *FakeStorage) SearchCalls(stub func(context.Context, types.ObjectType, string, ...query.Criterion) ([]*types.SearchMatch, error)) {
+	fake.searchMutex.Lock()
+	defer fake.searchMutex.Unlock()
+	fake.SearchStub = stub
+}
+
+func (fake *FakeStorage) SearchArgsForCall(i int) (context.Context, types.ObjectType, string, []query.Criterion) {
+	fake.searchMutex.RLock()
+	defer fake.searchMutex.RUnlock()
+	argsForCall := fake.searchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeStorage) SearchReturns(result1 []*types.SearchMatch, result2 error) {
+	fake.searchMutex.Lock()
+	defer fake.searchMutex.Unlock()
+	fake.SearchStub = nil
+	fake.searchReturns = struct {
+		result1 []*types.SearchMatch
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) SearchReturnsOnCall(i int, result1 []*types.SearchMatch, result2 error) {
+	fake.searchMutex.Lock()
+	defer fake.searchMutex.Unlock()
+	fake.SearchStub = nil
+	if fake.searchReturnsOnCall == nil {
+		fake.searchReturnsOnCall = make(map[int]struct {
+			result1 []*types.SearchMatch
+			result2 error
+		})
+	}
+	fake.searchReturnsOnCall[i] = struct {
+		result1 []*types.SearchMatch
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) Update(arg1 context.Context, arg2 types.Object, arg3 types.LabelChanges, arg4 ...query.Criterion) (types.Object, error) {
 	fake.updateMutex.Lock()
 	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
@@ -1050,6 +1214,8 @@ func (fake *FakeStorage) Invocations() map[string][][]interface{} {
 	defer fake.closeMutex.RUnlock()
 	fake.countMutex.RLock()
 	defer fake.countMutex.RUnlock()
+	fake.countLabelValuesMutex.RLock()
+	defer fake.countLabelValuesMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
 	fake.deleteMutex.RLock()
@@ -1072,6 +1238,8 @@ func (fake *FakeStorage) Invocations() map[string][][]interface{} {
 	defer fake.openMutex.RUnlock()
 	fake.pingContextMutex.RLock()
 	defer fake.pingContextMutex.RUnlock()
+	fake.searchMutex.RLock()
+	defer fake.searchMutex.RUnlock()
 	fake.updateMutex.RLock()
 	defer fake.updateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
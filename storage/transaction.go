@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+)
+
+// TransactionOptions are the options with which a transaction is started
+type TransactionOptions struct {
+	// Isolation is the isolation level of the transaction. The default isolation level of the storage is used if not set.
+	Isolation sql.IsolationLevel
+	// ReadOnly specifies that the transaction must not modify the storage
+	ReadOnly bool
+	// StatementTimeout is the maximum duration of each statement in the transaction. There is no limit if not set.
+	StatementTimeout time.Duration
+	// Retries specifies that the transaction is retried if it fails due to a serialization failure or a deadlock
+	Retries bool
+}
+
+// TransactionOption customizes a transaction started with InTransaction
+type TransactionOption func(options *TransactionOptions)
+
+// NewTransactionOptions returns the transaction options with the given customizations applied
+func NewTransactionOptions(opts ...TransactionOption) *TransactionOptions {
+	options := &TransactionOptions{
+		Isolation: sql.LevelDefault,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithIsolationLevel starts the transaction with the given isolation level
+func WithIsolationLevel(level sql.IsolationLevel) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.Isolation = level
+	}
+}
+
+// WithReadOnly starts a transaction which must not modify the storage
+func WithReadOnly() TransactionOption {
+	return func(options *TransactionOptions) {
+		options.ReadOnly = true
+	}
+}
+
+// WithStatementTimeout limits the duration of each statement in the transaction
+func WithStatementTimeout(timeout time.Duration) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.StatementTimeout = timeout
+	}
+}
+
+// WithRetries retries the transaction if it fails due to a serialization failure or a deadlock. The function of the
+// transaction is called again on each retry, so it must not depend on changes made by its previous calls, e.g. it must
+// not store objects created outside of it which the storage modifies in place, such as objects with secret fields.
+func WithRetries() TransactionOption {
+	return func(options *TransactionOptions) {
+		options.Retries = true
+	}
+}
+
+// RetryTransaction runs the transaction until it succeeds or fails with an error which is not retriable. A retriable
+// failure is retried up to maxRetries times, waiting the given interval before the first retry and doubling it
+// before each subsequent one. The transaction function must start a new transaction on each call.
+func RetryTransaction(ctx context.Context, maxRetries int, interval time.Duration, isRetriable func(err error) bool, transaction func() error) error {
+	for retry := 0; ; retry++ {
+		err := transaction()
+		if err == nil || retry >= maxRetries || !isRetriable(err) {
+			return err
+		}
+
+		log.C(ctx).WithError(err).Debugf("Transaction failed, retrying in %s (retry %d of %d)", interval, retry+1, maxRetries)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transaction", func() {
+	Describe("NewTransactionOptions", func() {
+		It("should use the default isolation level and no limits if there are no options", func() {
+			Expect(storage.NewTransactionOptions()).To(Equal(&storage.TransactionOptions{
+				Isolation: sql.LevelDefault,
+			}))
+		})
+
+		It("should apply the options", func() {
+			options := storage.NewTransactionOptions(
+				storage.WithIsolationLevel(sql.LevelSerializable),
+				storage.WithReadOnly(),
+				storage.WithStatementTimeout(time.Second))
+			Expect(options).To(Equal(&storage.TransactionOptions{
+				Isolation:        sql.LevelSerializable,
+				ReadOnly:         true,
+				StatementTimeout: time.Second,
+			}))
+		})
+	})
+
+	Describe("RetryTransaction", func() {
+		var (
+			retriableErr = errors.New("retriable")
+			otherErr     = errors.New("other")
+			calls        int
+		)
+
+		isRetriable := func(err error) bool {
+			return err == retriableErr
+		}
+
+		failing := func(errs ...error) func() error {
+			return func() error {
+				calls++
+				if calls > len(errs) {
+					return nil
+				}
+				return errs[calls-1]
+			}
+		}
+
+		BeforeEach(func() {
+			calls = 0
+		})
+
+		It("should run the transaction once if it succeeds", func() {
+			Expect(storage.RetryTransaction(context.Background(), 3, time.Millisecond, isRetriable, failing())).To(Succeed())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should retry the transaction while it fails with a retriable error", func() {
+			err := storage.RetryTransaction(context.Background(), 3, time.Millisecond, isRetriable, failing(retriableErr, retriableErr))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(calls).To(Equal(3))
+		})
+
+		It("should return the error once the retries are exhausted", func() {
+			err := storage.RetryTransaction(context.Background(), 2, time.Millisecond, isRetriable, failing(retriableErr, retriableErr, retriableErr))
+			Expect(err).To(Equal(retriableErr))
+			Expect(calls).To(Equal(3))
+		})
+
+		It("should not retry the transaction if it fails with an error which is not retriable", func() {
+			err := storage.RetryTransaction(context.Background(), 3, time.Millisecond, isRetriable, failing(otherErr))
+			Expect(err).To(Equal(otherErr))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should not retry the transaction if the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := storage.RetryTransaction(ctx, 3, time.Minute, isRetriable, failing(retriableErr))
+			Expect(err).To(Equal(retriableErr))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should double the interval before each retry", func() {
+			start := time.Now()
+			err := storage.RetryTransaction(context.Background(), 3, 20*time.Millisecond, isRetriable, failing(retriableErr, retriableErr, retriableErr))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically(">=", 140*time.Millisecond))
+		})
+	})
+})
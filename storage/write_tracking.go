@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type writeTrackerKey struct{}
+
+// writeTracker records whether the storage has been written with a context
+type writeTracker struct {
+	written int32
+}
+
+// ContextWithWriteTracking returns a context in which the writes to the storage are tracked. Reads with such a context
+// may be served by read replicas until the storage is written with it, so that the writes are always read back.
+// Reads with contexts without write tracking are served by the primary storage.
+func ContextWithWriteTracking(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(writeTrackerKey{}).(*writeTracker); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, writeTrackerKey{}, &writeTracker{})
+}
+
+// RecordWrite records that the storage has been written with the context
+func RecordWrite(ctx context.Context) {
+	if tracker, ok := ctx.Value(writeTrackerKey{}).(*writeTracker); ok {
+		atomic.StoreInt32(&tracker.written, 1)
+	}
+}
+
+// CanReadFromReplica returns whether the reads with the context may be served by read replicas
+func CanReadFromReplica(ctx context.Context) bool {
+	tracker, ok := ctx.Value(writeTrackerKey{}).(*writeTracker)
+	return ok && atomic.LoadInt32(&tracker.written) == 0
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"context"
+
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Write tracking", func() {
+	It("should not allow reads from replicas without write tracking", func() {
+		Expect(storage.CanReadFromReplica(context.Background())).To(BeFalse())
+	})
+
+	It("should allow reads from replicas until the storage is written", func() {
+		ctx := storage.ContextWithWriteTracking(context.Background())
+		Expect(storage.CanReadFromReplica(ctx)).To(BeTrue())
+
+		storage.RecordWrite(context.WithValue(ctx, struct{}{}, "derived"))
+		Expect(storage.CanReadFromReplica(ctx)).To(BeFalse())
+	})
+
+	It("should keep the tracking of the context if it is already tracked", func() {
+		ctx := storage.ContextWithWriteTracking(context.Background())
+		storage.RecordWrite(ctx)
+		Expect(storage.CanReadFromReplica(storage.ContextWithWriteTracking(ctx))).To(BeFalse())
+	})
+})
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/sm"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+)
+
+const tenantLabelKey = "tenant"
+
+var _ = Describe("Multiple token issuers", func() {
+	var ctx *common.TestContext
+
+	BeforeEach(func() {
+		ctx = common.NewTestContextBuilderWithSecurity().
+			ShouldUseSeparateOAuthServerForTenantAccess(true).
+			WithTenantTokenClaims(map[string]interface{}{
+				"zid": "tenant-id",
+			}).
+			WithEnvPostExtensions(func(e env.Environment, servers map[string]common.FakeServer) {
+				tenantIssuerURL := servers[common.TenantOauthServer].URL()
+				e.Set("api.token_issuers", []interface{}{
+					map[string]interface{}{
+						"url":          tenantIssuerURL,
+						"issuer":       tenantIssuerURL + "/oauth/token",
+						"tenant_claim": "zid",
+					},
+				})
+			}).
+			WithSMExtensions(func(ctx context.Context, smb *sm.ServiceManagerBuilder, e env.Environment) error {
+				_, err := smb.EnableMultitenancy(tenantLabelKey, func(request *web.Request) (string, error) {
+					return "", nil
+				})
+				return err
+			}).Build()
+	})
+
+	AfterEach(func() {
+		ctx.Cleanup()
+	})
+
+	It("accepts tokens of the default issuer", func() {
+		ctx.SMWithOAuth.GET(web.ServiceBrokersURL).Expect().Status(http.StatusOK)
+	})
+
+	It("accepts tokens of an additional issuer", func() {
+		ctx.SMWithOAuthForTenant.GET(web.ServiceInstancesURL).Expect().Status(http.StatusOK)
+	})
+
+	It("labels the resources of users of an additional issuer with the tenant in its tenant claim", func() {
+		ctx.SMWithOAuthForTenant.POST(web.PlatformsURL).WithJSON(common.GenerateRandomPlatform()).
+			Expect().Status(http.StatusCreated).
+			JSON().Path("$.labels." + tenantLabelKey).Array().Contains("tenant-id")
+	})
+
+	It("does not label the resources of users of an issuer without a tenant claim", func() {
+		ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(common.GenerateRandomPlatform()).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().NotContainsKey("labels")
+	})
+
+	It("accepts tokens of an additional issuer after its signing key is rotated", func() {
+		ctx.Servers[common.TenantOauthServer].(*common.OAuthServer).RotateTokenKey()
+		ctx.SM.GET(web.ServiceInstancesURL).
+			WithHeader("Authorization", "Bearer "+ctx.TenantTokenProvider()).
+			Expect().Status(http.StatusOK)
+	})
+
+	It("rejects tokens of an untrusted issuer", func() {
+		untrustedServer := common.NewOAuthServer()
+		defer untrustedServer.Close()
+
+		token := untrustedServer.CreateToken(map[string]interface{}{"zid": "tenant-id"})
+		expectUnauthorizedRequest(ctx, http.MethodGet, web.ServiceInstancesURL, "Bearer "+token)
+	})
+})
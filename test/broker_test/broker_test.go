@@ -18,6 +18,7 @@ package broker_test
 import (
 	"context"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -436,6 +437,82 @@ var _ = test.DescribeTestsFor(test.TestCase{
 
 				})
 
+				Context("when broker is behind tls with a private CA", func() {
+					var postBrokerRequestWithCA Object
+
+					BeforeEach(func() {
+						settings := *ctx.Config.HTTPClient
+						settings.SkipSSLValidation = false
+						httpclient.SetHTTPClientGlobalSettings(&settings)
+						httpclient.Configure()
+
+						postBrokerRequestWithCA = Object{
+							"name":        "brokerNameCA",
+							"broker_url":  brokerServerWithTLS.URL(),
+							"description": "description",
+							"credentials": Object{
+								"basic": Object{
+									"username": brokerServer.Username,
+									"password": brokerServer.Password,
+								},
+								"tls": Object{
+									"client_certificate": tls_settings.ClientCertificate,
+									"client_key":         tls_settings.ClientKey,
+									"ca_certificate": string(pem.EncodeToMemory(&pem.Block{
+										Type:  "CERTIFICATE",
+										Bytes: brokerServerWithTLS.Certificate().Raw,
+									})),
+									"server_name": "example.com",
+								},
+							},
+						}
+					})
+
+					AfterEach(func() {
+						httpclient.SetHTTPClientGlobalSettings(ctx.Config.HTTPClient)
+						httpclient.Configure()
+					})
+
+					Context("when the CA bundle and server name match the broker certificate", func() {
+						It("returns StatusCreated", func() {
+							ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(postBrokerRequestWithCA).
+								Expect().
+								Status(http.StatusCreated)
+							assertInvocationCount(brokerServerWithTLS.CatalogEndpointRequests, 1)
+						})
+					})
+
+					Context("when the CA bundle does not match the broker certificate", func() {
+						It("returns StatusBadGateway", func() {
+							postBrokerRequestWithCA["credentials"].(Object)["tls"].(Object)["ca_certificate"] = tls_settings.ClientCertificate
+							ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(postBrokerRequestWithCA).
+								Expect().
+								Status(http.StatusBadGateway)
+							assertInvocationCount(brokerServerWithTLS.CatalogEndpointRequests, 0)
+						})
+					})
+
+					Context("when the server name does not match the broker certificate", func() {
+						It("returns StatusBadGateway", func() {
+							postBrokerRequestWithCA["credentials"].(Object)["tls"].(Object)["server_name"] = "other.example.com"
+							ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(postBrokerRequestWithCA).
+								Expect().
+								Status(http.StatusBadGateway)
+							assertInvocationCount(brokerServerWithTLS.CatalogEndpointRequests, 0)
+						})
+					})
+
+					Context("when the CA bundle is not a valid PEM certificate", func() {
+						It("returns StatusBadRequest", func() {
+							postBrokerRequestWithCA["credentials"].(Object)["tls"].(Object)["ca_certificate"] = "invalid"
+							ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(postBrokerRequestWithCA).
+								Expect().
+								Status(http.StatusBadRequest)
+							assertInvocationCount(brokerServerWithTLS.CatalogEndpointRequests, 0)
+						})
+					})
+				})
+
 				Context("when the broker catalog is incomplete", func() {
 					verifyPOSTWhenCatalogFieldIsMissing := func(responseVerifier func(r *httpexpect.Response), fieldPath string) {
 						BeforeEach(func() {
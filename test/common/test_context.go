@@ -791,6 +791,8 @@ func (ctx *TestContext) CleanupAdditionalResources() {
 	RemoveAllOperations(ctx.SMRepository)
 
 	ctx.SMWithOAuth.DELETE(web.ServiceBrokersURL).Expect()
+	ctx.SMWithOAuth.DELETE(web.RolesURL).Expect()
+	ctx.SMWithOAuth.DELETE(web.LabelDefinitionsURL).Expect()
 
 	ctx.CleanupPlatforms()
 	serversToDelete := make([]string, 0)
@@ -9,8 +9,10 @@ import (
 	"gopkg.in/square/go-jose.v2/json"
 
 	"github.com/benjamintf1/unmarshalledmatchers"
+	"github.com/gavv/httpexpect"
 
 	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/maintenance"
 
 	"github.com/Peripli/service-manager/pkg/web"
 
@@ -34,7 +36,7 @@ var _ = Describe("Service Manager Config API", func() {
 	)
 
 	BeforeSuite(func() {
-		ctx = common.NewTestContextBuilder().Build()
+		ctx = common.NewTestContextBuilderWithSecurity().Build()
 	})
 
 	AfterSuite(func() {
@@ -213,4 +215,151 @@ var _ = Describe("Service Manager Config API", func() {
 			})
 		})
 	})
+
+	Describe("Maintenance API", func() {
+		var admin *common.SMExpect
+
+		BeforeEach(func() {
+			token := ctx.Servers[common.OauthServer].(*common.OAuthServer).CreateToken(map[string]interface{}{
+				"scope": []string{maintenance.AdminScope},
+			})
+			admin = &common.SMExpect{
+				Expect: ctx.SM.Builder(func(req *httpexpect.Request) {
+					req.WithHeader("Authorization", "Bearer "+token)
+				}),
+			}
+		})
+
+		AfterEach(func() {
+			admin.PUT(web.MaintenanceConfigURL).
+				WithJSON(common.Object{"read_only": false}).
+				Expect().Status(http.StatusOK)
+		})
+
+		When("read-only mode is switched on", func() {
+			BeforeEach(func() {
+				admin.PUT(web.MaintenanceConfigURL).
+					WithJSON(common.Object{"read_only": true, "reason": "database upgrade"}).
+					Expect().Status(http.StatusOK).JSON().Object().ContainsMap(map[string]interface{}{
+					"read_only": true,
+					"reason":    "database upgrade",
+				})
+			})
+
+			It("rejects changes with 503", func() {
+				resp := ctx.SMWithOAuth.POST(web.PlatformsURL).
+					WithJSON(common.GenerateRandomPlatform()).
+					Expect().Status(http.StatusServiceUnavailable)
+				resp.Header("Retry-After").NotEmpty()
+				resp.JSON().Object().Value("description").String().Contains("database upgrade")
+			})
+
+			It("rejects other configuration changes with 503", func() {
+				admin.PUT(web.LoggingConfigURL).
+					WithJSON(common.Object{"level": "debug"}).
+					Expect().Status(http.StatusServiceUnavailable)
+			})
+
+			It("serves reads", func() {
+				ctx.SMWithOAuth.GET(web.PlatformsURL).Expect().Status(http.StatusOK)
+				ctx.SMWithOAuth.GET(web.MaintenanceConfigURL).
+					Expect().Status(http.StatusOK).JSON().Object().ValueEqual("read_only", true)
+				ctx.SMWithOAuth.GET(web.InfoURL).
+					Expect().Status(http.StatusOK).JSON().Object().ValueEqual("read_only", true)
+			})
+
+			It("accepts changes once read-only mode is switched off", func() {
+				admin.PUT(web.MaintenanceConfigURL).
+					WithJSON(common.Object{"read_only": false}).
+					Expect().Status(http.StatusOK)
+
+				ctx.SMWithOAuth.POST(web.PlatformsURL).
+					WithJSON(common.GenerateRandomPlatform()).
+					Expect().Status(http.StatusCreated)
+			})
+		})
+
+		When("the user is not an administrator", func() {
+			It("returns 403", func() {
+				ctx.SMWithOAuth.PUT(web.MaintenanceConfigURL).
+					WithJSON(common.Object{"read_only": true}).
+					Expect().Status(http.StatusForbidden)
+
+				ctx.SMWithOAuth.GET(web.MaintenanceConfigURL).
+					Expect().Status(http.StatusOK).JSON().Object().ValueEqual("read_only", false)
+			})
+		})
+
+		When("read_only is missing", func() {
+			It("returns 400", func() {
+				admin.PUT(web.MaintenanceConfigURL).
+					WithJSON(common.Object{"reason": "database upgrade"}).
+					Expect().Status(http.StatusBadRequest)
+			})
+		})
+	})
+
+	Describe("Runtime changes", func() {
+		AfterEach(func() {
+			ctx.SMWithOAuth.PATCH(web.ConfigURL).
+				WithJSON(common.Object{
+					"api": common.Object{
+						"max_page_size":    nil,
+						"protected_labels": nil,
+					},
+				}).Expect().Status(http.StatusOK)
+		})
+
+		It("changes the page sizes", func() {
+			ctx.RegisterPlatform()
+			ctx.RegisterPlatform()
+
+			ctx.SMWithOAuth.PATCH(web.ConfigURL).
+				WithJSON(common.Object{
+					"api": common.Object{"max_page_size": 1},
+				}).Expect().Status(http.StatusOK).
+				JSON().Path("$.api.max_page_size").Equal(1)
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("max_items", 5).
+				Expect().Status(http.StatusOK).
+				JSON().Path("$.items").Array().Length().Equal(1)
+			ctx.SMWithOAuth.GET(web.ConfigURL).
+				Expect().Status(http.StatusOK).
+				JSON().Path("$.api.max_page_size").Equal(1)
+		})
+
+		It("changes the protected labels", func() {
+			ctx.SMWithOAuth.PATCH(web.ConfigURL).
+				WithJSON(common.Object{
+					"api": common.Object{"protected_labels": []string{"protected"}},
+				}).Expect().Status(http.StatusOK)
+
+			platform := common.GenerateRandomPlatform()
+			platform["labels"] = common.Object{"protected": common.Array{"value"}}
+			ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(platform).
+				Expect().Status(http.StatusBadRequest)
+		})
+
+		When("a key cannot be changed at runtime", func() {
+			It("returns 400", func() {
+				ctx.SMWithOAuth.PATCH(web.ConfigURL).
+					WithJSON(common.Object{
+						"api": common.Object{"client_id": "other"},
+					}).Expect().Status(http.StatusBadRequest)
+			})
+		})
+
+		When("the changed settings are invalid", func() {
+			It("returns 400 and does not change the configuration", func() {
+				ctx.SMWithOAuth.PATCH(web.ConfigURL).
+					WithJSON(common.Object{
+						"operations": common.Object{"default_pool_size": 0},
+					}).Expect().Status(http.StatusBadRequest)
+
+				ctx.SMWithOAuth.GET(web.ConfigURL).
+					Expect().Status(http.StatusOK).
+					JSON().Path("$.operations.default_pool_size").Equal(20)
+			})
+		})
+	})
 })
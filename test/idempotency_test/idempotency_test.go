@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package idempotency_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Peripli/service-manager/api/filters"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestIdempotency(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Idempotency Tests Suite")
+}
+
+var _ = Describe("Idempotency-Key", func() {
+	var ctx *common.TestContext
+
+	BeforeSuite(func() {
+		ctx = common.DefaultTestContext()
+	})
+
+	AfterSuite(func() {
+		ctx.Cleanup()
+	})
+
+	AfterEach(func() {
+		ctx.CleanupAdditionalResources()
+	})
+
+	Context("when a platform is created twice with the same key", func() {
+		It("creates the platform once and replays the response", func() {
+			platform := common.MakePlatform("", "idempotent-platform", "kubernetes", "")
+			first := ctx.SMWithOAuth.POST(web.PlatformsURL).WithHeader(filters.IdempotencyKeyHeader, "create-platform").
+				WithJSON(platform).Expect().Status(http.StatusCreated)
+			first.Header(filters.IdempotentReplayedHeader).Empty()
+			platformID := first.JSON().Object().Value("id").String().Raw()
+
+			second := ctx.SMWithOAuth.POST(web.PlatformsURL).WithHeader(filters.IdempotencyKeyHeader, "create-platform").
+				WithJSON(platform).Expect().Status(http.StatusCreated)
+			second.Header(filters.IdempotentReplayedHeader).Equal("true")
+			second.JSON().Object().Value("id").Equal(platformID)
+			second.JSON().Object().Value("credentials").Object().Value("basic").Object().Value("password").
+				Equal(first.JSON().Object().Path("$.credentials.basic.password").Raw())
+
+			ctx.SMWithOAuth.ListWithQuery(web.PlatformsURL, "fieldQuery=name eq 'idempotent-platform'").Length().Equal(1)
+		})
+
+		It("rejects the key for a different request with 422", func() {
+			ctx.SMWithOAuth.POST(web.PlatformsURL).WithHeader(filters.IdempotencyKeyHeader, "reused-key").
+				WithJSON(common.GenerateRandomPlatform()).Expect().Status(http.StatusCreated)
+
+			ctx.SMWithOAuth.POST(web.PlatformsURL).WithHeader(filters.IdempotencyKeyHeader, "reused-key").
+				WithJSON(common.GenerateRandomPlatform()).Expect().Status(http.StatusUnprocessableEntity)
+		})
+	})
+
+	Context("when a broker is registered asynchronously twice with the same key", func() {
+		It("replays the location of the operation", func() {
+			broker := common.GenerateRandomBroker()
+			first := ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithQuery("async", "true").
+				WithHeader(filters.IdempotencyKeyHeader, "register-broker").
+				WithJSON(broker).Expect().Status(http.StatusAccepted)
+			location := first.Header("Location").NotEmpty().Raw()
+
+			ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithQuery("async", "true").
+				WithHeader(filters.IdempotencyKeyHeader, "register-broker").
+				WithJSON(broker).Expect().Status(http.StatusAccepted).
+				Header("Location").Equal(location)
+		})
+	})
+
+	Context("when the requests are sent without a key", func() {
+		It("processes each of them", func() {
+			platform := common.GenerateRandomPlatform()
+			ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(platform).Expect().Status(http.StatusCreated)
+			ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(platform).Expect().Status(http.StatusConflict)
+		})
+	})
+})
@@ -64,6 +64,7 @@ var _ = Describe("Info API", func() {
 				JSON().Object().Equal(common.Object{
 				"token_issuer_url": ctx.Servers[common.OauthServer].URL(),
 				"token_basic_auth": tc.expectBasicAuth,
+				"read_only":        false,
 			})
 		})
 	}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package label_definition_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLabelDefinitions(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Label Definitions Tests Suite")
+}
+
+var _ = Describe("Label definitions", func() {
+	var ctx *common.TestContext
+
+	postPlatform := func(id string, labels common.Object) int {
+		return ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(common.Object{
+			"id":     id,
+			"name":   id,
+			"type":   "kubernetes",
+			"labels": labels,
+		}).Expect().Raw().StatusCode
+	}
+
+	BeforeSuite(func() {
+		ctx = common.NewTestContextBuilderWithSecurity().
+			WithEnvPostExtensions(func(e env.Environment, servers map[string]common.FakeServer) {
+				e.Set("api.label_definitions_refresh_interval", time.Nanosecond)
+			}).Build()
+	})
+
+	AfterSuite(func() {
+		ctx.Cleanup()
+	})
+
+	BeforeEach(func() {
+		ctx.SMWithOAuth.POST(web.LabelDefinitionsURL).WithJSON(common.Object{
+			"key":            "tier",
+			"resource_types": common.Array{web.PlatformsURL},
+			"value_type":     "int",
+			"cardinality":    "single",
+			"required":       true,
+		}).Expect().Status(http.StatusCreated).JSON().Object().ContainsMap(common.Object{
+			"key":        "tier",
+			"value_type": "int",
+			"required":   true,
+		})
+		ctx.SMWithOAuth.POST(web.LabelDefinitionsURL).WithJSON(common.Object{
+			"key":            "env",
+			"resource_types": common.Array{web.PlatformsURL},
+			"value_type":     "enum",
+			"allowed_values": common.Array{"dev", "prod"},
+		}).Expect().Status(http.StatusCreated)
+	})
+
+	AfterEach(func() {
+		ctx.CleanupAdditionalResources()
+	})
+
+	Context("when an invalid label definition is created", func() {
+		It("returns 400", func() {
+			ctx.SMWithOAuth.POST(web.LabelDefinitionsURL).WithJSON(common.Object{
+				"key":            "region",
+				"resource_types": common.Array{web.PlatformsURL},
+				"value_type":     "float",
+			}).Expect().Status(http.StatusBadRequest)
+			ctx.SMWithOAuth.POST(web.LabelDefinitionsURL).WithJSON(common.Object{
+				"key":            "region",
+				"resource_types": common.Array{web.PlatformsURL},
+				"value_type":     "regex",
+				"pattern":        "[a-",
+			}).Expect().Status(http.StatusBadRequest)
+			ctx.SMWithOAuth.POST(web.LabelDefinitionsURL).WithJSON(common.Object{
+				"key":            "region",
+				"resource_types": common.Array{"/v1/unknown"},
+				"value_type":     "string",
+			}).Expect().Status(http.StatusBadRequest)
+		})
+	})
+
+	Context("when a label definition with an existing key is created", func() {
+		It("returns 409", func() {
+			ctx.SMWithOAuth.POST(web.LabelDefinitionsURL).WithJSON(common.Object{
+				"key":            "tier",
+				"resource_types": common.Array{web.ServiceBrokersURL},
+				"value_type":     "string",
+			}).Expect().Status(http.StatusConflict)
+		})
+	})
+
+	Context("when a resource is created", func() {
+		It("validates its labels against the definitions", func() {
+			Expect(postPlatform("missing-tier", common.Object{"env": common.Array{"dev"}})).To(Equal(http.StatusBadRequest))
+			Expect(postPlatform("invalid-tier", common.Object{"tier": common.Array{"high"}})).To(Equal(http.StatusBadRequest))
+			Expect(postPlatform("multiple-tiers", common.Object{"tier": common.Array{"1", "2"}})).To(Equal(http.StatusBadRequest))
+			Expect(postPlatform("invalid-env", common.Object{"tier": common.Array{"1"}, "env": common.Array{"test"}})).To(Equal(http.StatusBadRequest))
+			Expect(postPlatform("valid", common.Object{"tier": common.Array{"1"}, "env": common.Array{"prod"}})).To(Equal(http.StatusCreated))
+		})
+
+		It("does not validate the labels of resources of other types", func() {
+			ctx.SMWithOAuth.POST(web.VisibilitiesURL).WithJSON(common.Object{
+				"labels": common.Object{"tier": common.Array{"high"}},
+			}).Expect().Status(http.StatusBadRequest).JSON().Object().Value("description").String().NotContains("tier")
+		})
+	})
+
+	Context("when the labels of a resource are patched", func() {
+		BeforeEach(func() {
+			Expect(postPlatform("patched", common.Object{"tier": common.Array{"1"}})).To(Equal(http.StatusCreated))
+		})
+
+		It("validates the resulting labels against the definitions", func() {
+			ctx.SMWithOAuth.PATCH(web.PlatformsURL + "/patched").WithJSON(common.Object{
+				"labels": common.Array{common.Object{"op": "remove", "key": "tier"}},
+			}).Expect().Status(http.StatusBadRequest)
+			ctx.SMWithOAuth.PATCH(web.PlatformsURL + "/patched").WithJSON(common.Object{
+				"labels": common.Array{common.Object{"op": "add_values", "key": "tier", "values": common.Array{"2"}}},
+			}).Expect().Status(http.StatusBadRequest)
+			ctx.SMWithOAuth.PATCH(web.PlatformsURL + "/patched").WithJSON(common.Object{
+				"labels": common.Array{
+					common.Object{"op": "remove_values", "key": "tier", "values": common.Array{"1"}},
+					common.Object{"op": "add_values", "key": "tier", "values": common.Array{"2"}},
+				},
+			}).Expect().Status(http.StatusOK)
+		})
+	})
+
+	Context("when resources are listed with a label query on an int label", func() {
+		BeforeEach(func() {
+			Expect(postPlatform("tier-9", common.Object{"tier": common.Array{"9"}})).To(Equal(http.StatusCreated))
+			Expect(postPlatform("tier-10", common.Object{"tier": common.Array{"10"}})).To(Equal(http.StatusCreated))
+		})
+
+		It("compares the label values as numbers", func() {
+			ctx.SMWithOAuth.ListWithQuery(web.PlatformsURL, "labelQuery=tier gt 9").
+				Path("$[*].id").Array().ContainsOnly("tier-10")
+			ctx.SMWithOAuth.ListWithQuery(web.PlatformsURL, "labelQuery=tier le 9").
+				Path("$[*].id").Array().ContainsOnly("tier-9")
+		})
+
+		It("returns 400 if the right operand is not an integer", func() {
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("labelQuery", "tier gt 9.5").
+				Expect().Status(http.StatusBadRequest)
+		})
+
+		It("returns 400 for comparisons of labels which are not int", func() {
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("labelQuery", "env gt 1").
+				Expect().Status(http.StatusBadRequest)
+		})
+	})
+})
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
 
 	"github.com/gavv/httpexpect"
 
@@ -384,6 +385,26 @@ func DescribeListTestsFor(ctx *common.TestContext, t TestCase, responseMode Resp
 		verifyListOpWithAuth(listOpEntry, query, ctx.SMWithOAuth)
 	}
 
+	labelValueCount := func(auth *common.SMExpect, query, key, value string) int {
+		req := auth.GET(t.API + web.ResourceLabelsURL)
+		if query != "" {
+			req = req.WithQueryString(query)
+		}
+		for _, item := range req.Expect().Status(http.StatusOK).JSON().Object().Value("items").Array().Raw() {
+			labelKey := item.(map[string]interface{})
+			if labelKey["key"] != key {
+				continue
+			}
+			for _, v := range labelKey["values"].([]interface{}) {
+				labelValue := v.(map[string]interface{})
+				if labelValue["value"] == value {
+					return int(labelValue["count"].(float64))
+				}
+			}
+		}
+		return 0
+	}
+
 	return Describe("List", func() {
 		Context("with basic auth", func() {
 			if !t.DisableBasicAuth {
@@ -432,6 +453,45 @@ func DescribeListTestsFor(ctx *common.TestContext, t TestCase, responseMode Resp
 			})
 		})
 
+		Context("with label existence query", func() {
+			It("returns only resources having the label", func() {
+				verifyListOp(listOpEntry{
+					resourcesToExpectBeforeOp:   []common.Object{r[0], r[1], rWithMandatoryFields},
+					resourcesToExpectAfterOp:    []common.Object{r[0], r[1]},
+					resourcesNotToExpectAfterOp: []common.Object{rWithMandatoryFields},
+					expectedStatusCode:          http.StatusOK,
+				}, "labelQuery=labelKey2 exists")
+			})
+
+			It("returns only resources not having the label", func() {
+				verifyListOp(listOpEntry{
+					resourcesToExpectBeforeOp:   []common.Object{r[0], r[1], rWithMandatoryFields},
+					resourcesToExpectAfterOp:    []common.Object{rWithMandatoryFields},
+					resourcesNotToExpectAfterOp: []common.Object{r[0], r[1]},
+					expectedStatusCode:          http.StatusOK,
+				}, "labelQuery=labelKey2 notexists")
+			})
+
+			It("returns 400 for field queries", func() {
+				verifyListOp(listOpEntry{
+					expectedStatusCode: http.StatusBadRequest,
+				}, "fieldQuery=id exists")
+			})
+		})
+
+		Context("label keys", func() {
+			It("returns the label keys with the number of resources having each label value", func() {
+				query := fmt.Sprintf("fieldQuery=id in ('%s','%s')", r[0]["id"], r[1]["id"])
+				Expect(labelValueCount(ctx.SMWithOAuth, query, "labelKey2", "str")).To(Equal(2))
+				Expect(labelValueCount(ctx.SMWithOAuth, query, commonLabelKey, commonLabelValue)).To(Equal(2))
+			})
+
+			It("applies the label queries", func() {
+				query := fmt.Sprintf("fieldQuery=id in ('%s','%s')&labelQuery=labelKey2 notexists", r[0]["id"], rWithMandatoryFields["id"])
+				Expect(labelValueCount(ctx.SMWithOAuth, query, "labelKey2", "str")).To(Equal(0))
+			})
+		})
+
 		Context("with bearer auth", func() {
 			if !t.DisableTenantResources {
 				Context("when authenticating with tenant scoped token", func() {
@@ -474,6 +534,17 @@ func DescribeListTestsFor(ctx *common.TestContext, t TestCase, responseMode Resp
 						}, "", ctx.SMWithOAuthForTenant)
 					})
 
+					It("counts the label values of tenant specific resources only", func() {
+						labelQuery := fmt.Sprintf("labelQuery=%s eq '%s'", commonLabelKey, commonLabelValue)
+						tenantCount := ctx.SMWithOAuthForTenant.ListWithQuery(t.API, labelQuery).Length().Raw()
+						Expect(labelValueCount(ctx.SMWithOAuthForTenant, "", commonLabelKey, commonLabelValue)).To(Equal(int(tenantCount)))
+						Expect(labelValueCount(ctx.SMWithOAuthForTenant, "", resourceSpecificLabel, commonLabelValue)).To(BeNumerically(">=", 1))
+
+						globalCount := ctx.SMWithOAuth.ListWithQuery(t.API, labelQuery).Length().Raw()
+						Expect(labelValueCount(ctx.SMWithOAuth, "", commonLabelKey, commonLabelValue)).To(Equal(int(globalCount)))
+						Expect(globalCount).To(BeNumerically(">", tenantCount))
+					})
+
 					Context("when authenticating with global token", func() {
 						It("it returns all resources", func() {
 							verifyListOpWithAuth(listOpEntry{
@@ -561,6 +632,48 @@ func DescribeListTestsFor(ctx *common.TestContext, t TestCase, responseMode Resp
 						resp.JSON().Path("$.items[*]").Array().Length().Gt(0).Le(pageSize)
 					})
 				})
+				Context("with order by query", func() {
+					listIDs := func(query map[string]interface{}) []string {
+						ids := make([]string, 0)
+						for {
+							req := ctx.SMWithOAuth.GET(t.API)
+							for key, value := range query {
+								req = req.WithQuery(key, value)
+							}
+							page := req.Expect().Status(http.StatusOK).JSON().Object().Raw()
+							for _, item := range page["items"].([]interface{}) {
+								ids = append(ids, item.(map[string]interface{})["id"].(string))
+							}
+							token, found := page["token"]
+							if !found {
+								return ids
+							}
+							query["token"] = token
+						}
+					}
+
+					It("pages through the ordered resources", func() {
+						allIDs := listIDs(map[string]interface{}{"orderBy": "created_at desc,id asc"})
+						pagedIDs := listIDs(map[string]interface{}{"orderBy": "created_at desc,id asc", "max_items": 2})
+
+						Expect(pagedIDs).To(Equal(allIDs))
+					})
+
+					It("returns 400 for unsupported fields", func() {
+						ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", "labels asc").Expect().Status(http.StatusBadRequest)
+					})
+
+					It("returns 400 for unsupported order types", func() {
+						ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", "created_at up").Expect().Status(http.StatusBadRequest)
+					})
+
+					It("returns 400 if the token was issued for a different order", func() {
+						token := ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", "created_at desc").WithQuery("max_items", 1).
+							Expect().Status(http.StatusOK).JSON().Path("$.token").String().Raw()
+						ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", "created_at asc").WithQuery("token", token).
+							Expect().Status(http.StatusBadRequest)
+					})
+				})
 				Context("with invalid token", func() {
 					executeWithInvalidToken := func(token string) {
 						ctx.SMWithOAuth.GET(t.API).WithQuery("token", token).Expect().Status(http.StatusBadRequest)
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestOpenAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OpenAPI Suite")
+}
+
+var _ = Describe("OpenAPI", func() {
+	var ctx *common.TestContext
+
+	BeforeSuite(func() {
+		ctx = common.DefaultTestContext()
+	})
+
+	AfterSuite(func() {
+		ctx.Cleanup()
+	})
+
+	It("serves the specification of the API without authentication", func() {
+		document := ctx.SM.GET(web.OpenAPIURL).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		document.Value("openapi").String().Equal("3.0.3")
+		paths := document.Value("paths").Object()
+		paths.ContainsKey(web.ServiceInstancesURL)
+		paths.ContainsKey(web.ServiceInstancesURL + "/{resource_id}")
+		paths.ContainsKey(web.PlatformsURL)
+		paths.Value(web.ServiceInstancesURL).Path("$.post.responses").Object().ContainsKey("202")
+		document.Path("$.components.schemas").Object().ContainsKey("ServiceInstance").ContainsKey("Error")
+	})
+})
@@ -208,6 +208,9 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					actionTimeout       = 1 * time.Second
 					cleanupInterval     = 2 * time.Second
 					operationExpiration = 2 * time.Second
+					// an operation is deleted by the first cleanup after it expires, which runs up to a cleanup interval
+					// later; waiting only for the expiration plus one interval fails whenever the cleanup runs last
+					cleanupTimeout = operationExpiration + cleanupInterval*2
 				)
 
 				var ctxBuilder *TestContextBuilder
@@ -248,7 +251,7 @@ var _ = test.DescribeTestsFor(test.TestCase{
 								Expect(err).To(BeNil())
 
 								return count
-							}, cleanupInterval*2).Should(Equal(0))
+							}, cleanupTimeout).Should(Equal(0))
 						})
 					})
 
@@ -307,7 +310,7 @@ var _ = test.DescribeTestsFor(test.TestCase{
 								Expect(err).To(BeNil())
 
 								return count
-							}, cleanupInterval*2).Should(Equal(0))
+							}, cleanupTimeout).Should(Equal(0))
 						})
 					})
 
@@ -341,7 +344,7 @@ var _ = test.DescribeTestsFor(test.TestCase{
 								Expect(err).To(BeNil())
 
 								return count
-							}, operationExpiration*2).Should(Equal(0))
+							}, cleanupTimeout).Should(Equal(0))
 						})
 					})
 				})
@@ -177,6 +177,18 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					})
 				})
 
+				Context("With the id of the labels path", func() {
+					It("fails", func() {
+						platform := common.MakePlatform("labels", "cf-10", "cf", "descr")
+
+						reply := ctx.SMWithOAuth.POST(web.PlatformsURL).
+							WithJSON(platform).
+							Expect().Status(http.StatusBadRequest).JSON().Object()
+
+						reply.Value("description").Equal("labels is reserved and cannot be used as id")
+					})
+				})
+
 				Context("Without id", func() {
 					It("returns the new platform with generated id and credentials", func() {
 						platform := common.MakePlatform("", "cf-10", "cf", "descr")
@@ -378,6 +390,92 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					})
 				})
 			})
+
+			Describe("credentials rotation", func() {
+				var platform *types.Platform
+
+				BeforeEach(func() {
+					platform = common.RegisterPlatformInSM(common.GenerateRandomPlatform(), ctx.SMWithOAuth, nil)
+				})
+
+				authenticate := func(credentials *types.Basic, status int) {
+					ctx.SM.GET(web.ServiceOfferingsURL).
+						WithBasicAuth(credentials.Username, credentials.Password).
+						Expect().Status(status)
+				}
+
+				rotate := func() *types.Basic {
+					status := ctx.SMWithOAuth.POST(web.PlatformsURL + "/" + platform.ID + web.PlatformCredentialsURL + "/rotate").
+						WithJSON(common.Object{}).
+						Expect().Status(http.StatusOK).JSON().Object()
+					status.ContainsKey("old_credentials_expire_at")
+					basic := status.Value("credentials").Object().Value("basic").Object()
+					return &types.Basic{
+						Username: basic.Value("username").String().Raw(),
+						Password: basic.Value("password").String().Raw(),
+					}
+				}
+
+				It("accepts both the old and the new credentials within the grace period", func() {
+					oldCredentials := platform.Credentials.Basic
+					newCredentials := rotate()
+					Expect(newCredentials.Username).ToNot(Equal(oldCredentials.Username))
+
+					authenticate(oldCredentials, http.StatusOK)
+					authenticate(newCredentials, http.StatusOK)
+				})
+
+				It("records the usage of the old credentials", func() {
+					oldCredentials := platform.Credentials.Basic
+					rotate()
+					authenticate(oldCredentials, http.StatusOK)
+
+					ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + platform.ID + web.PlatformCredentialsURL).
+						Expect().Status(http.StatusOK).JSON().Object().
+						ContainsKey("old_credentials_last_used_at").
+						NotContainsKey("credentials")
+				})
+
+				It("rejects a second rotation while the old credentials are valid", func() {
+					oldCredentials := platform.Credentials.Basic
+					newCredentials := rotate()
+
+					ctx.SMWithOAuth.POST(web.PlatformsURL + "/" + platform.ID + web.PlatformCredentialsURL + "/rotate").
+						WithJSON(common.Object{}).
+						Expect().Status(http.StatusConflict)
+
+					authenticate(oldCredentials, http.StatusOK)
+					authenticate(newCredentials, http.StatusOK)
+				})
+
+				It("rotates the credentials again after the old credentials are revoked", func() {
+					oldCredentials := platform.Credentials.Basic
+					rotate()
+					ctx.SMWithOAuth.DELETE(web.PlatformsURL + "/" + platform.ID + web.PlatformCredentialsURL + "/old").
+						Expect().Status(http.StatusOK)
+					newCredentials := rotate()
+
+					authenticate(oldCredentials, http.StatusUnauthorized)
+					authenticate(newCredentials, http.StatusOK)
+				})
+
+				It("rejects the old credentials after they are revoked", func() {
+					oldCredentials := platform.Credentials.Basic
+					newCredentials := rotate()
+
+					ctx.SMWithOAuth.DELETE(web.PlatformsURL + "/" + platform.ID + web.PlatformCredentialsURL + "/old").
+						Expect().Status(http.StatusOK)
+
+					authenticate(oldCredentials, http.StatusUnauthorized)
+					authenticate(newCredentials, http.StatusOK)
+				})
+
+				It("does not rotate the credentials of the service manager platform", func() {
+					ctx.SMWithOAuth.POST(web.PlatformsURL + "/" + types.SMPlatform + web.PlatformCredentialsURL + "/rotate").
+						WithJSON(common.Object{}).
+						Expect().Status(http.StatusNotFound)
+				})
+			})
 		})
 	},
 })
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rbac_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	"github.com/gavv/httpexpect"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRBAC(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RBAC Tests Suite")
+}
+
+const rolesFile = `
+roles:
+- name: sm-admin
+  access_level: global
+  permissions:
+  - resource_type: "*"
+    actions: ["*"]
+  bindings:
+  - claim: groups
+    value: sm-admins
+`
+
+var _ = Describe("RBAC", func() {
+	var (
+		ctx       *common.TestContext
+		rolesPath string
+	)
+
+	newUserExpect := func(groups ...string) *common.SMExpect {
+		token := ctx.Servers[common.OauthServer].(*common.OAuthServer).CreateToken(map[string]interface{}{
+			"groups": groups,
+		})
+		return &common.SMExpect{
+			Expect: ctx.SM.Builder(func(req *httpexpect.Request) {
+				req.WithHeader("Authorization", "Bearer "+token)
+			}),
+		}
+	}
+
+	createPlatform := func(id, team string) {
+		ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(common.Object{
+			"id":   id,
+			"name": id,
+			"type": "kubernetes",
+			"labels": common.Object{
+				"team": common.Array{team},
+			},
+		}).Expect().Status(http.StatusCreated)
+	}
+
+	BeforeEach(func() {
+		f, err := ioutil.TempFile("", "roles")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.WriteString(rolesFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+		rolesPath = f.Name()
+
+		ctx = common.NewTestContextBuilderWithSecurity().
+			WithDefaultTokenClaims(map[string]interface{}{
+				"groups": []string{"sm-admins"},
+			}).
+			WithEnvPostExtensions(func(e env.Environment, servers map[string]common.FakeServer) {
+				e.Set("rbac.enabled", true)
+				e.Set("rbac.roles_file", rolesPath)
+				e.Set("rbac.refresh_interval", time.Nanosecond)
+			}).Build()
+
+		createPlatform("team-a-platform", "a")
+		createPlatform("team-b-platform", "b")
+
+		ctx.SMWithOAuth.POST(web.RolesURL).WithJSON(common.Object{
+			"name": "team-a-platform-viewer",
+			"permissions": common.Array{
+				common.Object{
+					"resource_type":  web.PlatformsURL,
+					"actions":        common.Array{"get", "list"},
+					"label_selector": "team eq 'a'",
+				},
+			},
+			"bindings": common.Array{
+				common.Object{
+					"claim": "groups",
+					"value": "team-a",
+				},
+			},
+		}).Expect().Status(http.StatusCreated)
+	})
+
+	AfterEach(func() {
+		ctx.Cleanup()
+		os.Remove(rolesPath)
+	})
+
+	Context("when the user is bound to a role from the roles file", func() {
+		It("grants all actions", func() {
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/team-b-platform").Expect().Status(http.StatusOK)
+			ctx.SMWithOAuth.GET(web.RolesURL).Expect().Status(http.StatusOK).
+				JSON().Path("$.items[*].name").Array().Contains("team-a-platform-viewer")
+		})
+	})
+
+	Context("when the user is bound to a role with a label selector", func() {
+		It("lists only the resources matching the selector", func() {
+			userExpect := newUserExpect("team-a")
+			userExpect.GET(web.PlatformsURL).Expect().Status(http.StatusOK).
+				JSON().Path("$.items[*].id").Array().ContainsOnly("team-a-platform")
+		})
+
+		It("returns not found for resources not matching the selector", func() {
+			userExpect := newUserExpect("team-a")
+			userExpect.GET(web.PlatformsURL + "/team-a-platform").Expect().Status(http.StatusOK)
+			userExpect.GET(web.PlatformsURL + "/team-b-platform").Expect().Status(http.StatusNotFound)
+		})
+
+		It("forbids actions which the role does not grant", func() {
+			userExpect := newUserExpect("team-a")
+			userExpect.DELETE(web.PlatformsURL + "/team-a-platform").Expect().Status(http.StatusForbidden)
+			userExpect.GET(web.ServiceBrokersURL).Expect().Status(http.StatusForbidden)
+			userExpect.GET(web.RolesURL).Expect().Status(http.StatusForbidden)
+		})
+	})
+
+	Context("when the user is bound to a role updating resources with a label selector", func() {
+		BeforeEach(func() {
+			ctx.SMWithOAuth.POST(web.RolesURL).WithJSON(common.Object{
+				"name": "team-a-platform-editor",
+				"permissions": common.Array{
+					common.Object{
+						"resource_type":  web.PlatformsURL,
+						"actions":        common.Array{"get", "update"},
+						"label_selector": "team eq 'a'",
+					},
+				},
+				"bindings": common.Array{
+					common.Object{
+						"claim": "groups",
+						"value": "team-a-editors",
+					},
+				},
+			}).Expect().Status(http.StatusCreated)
+		})
+
+		It("updates the resources matching the selector", func() {
+			newUserExpect("team-a-editors").PATCH(web.PlatformsURL + "/team-a-platform").
+				WithJSON(common.Object{"description": "updated"}).
+				Expect().Status(http.StatusOK)
+		})
+
+		It("forbids label changes moving the resources out of the selector", func() {
+			newUserExpect("team-a-editors").PATCH(web.PlatformsURL + "/team-a-platform").
+				WithJSON(common.Object{
+					"labels": common.Array{
+						common.Object{"op": "remove", "key": "team"},
+						common.Object{"op": "add", "key": "team", "values": common.Array{"b"}},
+					},
+				}).
+				Expect().Status(http.StatusForbidden)
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/team-a-platform").Expect().Status(http.StatusOK).
+				JSON().Path("$.labels.team").Array().ContainsOnly("a")
+		})
+	})
+
+	Context("when the user is not bound to any role", func() {
+		It("forbids the request", func() {
+			newUserExpect("team-c").GET(web.PlatformsURL).Expect().Status(http.StatusForbidden)
+		})
+	})
+
+	Context("when the request is authenticated with platform credentials", func() {
+		It("is not subject to roles", func() {
+			ctx.SMWithBasic.GET(web.ServiceBrokersURL).Expect().Status(http.StatusOK)
+		})
+	})
+
+	Context("when a role with an invalid label selector is created", func() {
+		It("returns 400", func() {
+			ctx.SMWithOAuth.POST(web.RolesURL).WithJSON(common.Object{
+				"name": "invalid",
+				"permissions": common.Array{
+					common.Object{
+						"resource_type":  web.PlatformsURL,
+						"actions":        common.Array{"get"},
+						"label_selector": "team eqq 'a'",
+					},
+				},
+			}).Expect().Status(http.StatusBadRequest)
+		})
+	})
+})
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package search_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/multitenancy"
+	"github.com/Peripli/service-manager/pkg/sm"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	"github.com/gavv/httpexpect"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSearch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Search Tests Suite")
+}
+
+const (
+	tenantClientID = "tenancyClient"
+	tenantLabelKey = "tenant"
+)
+
+var _ = Describe("Search", func() {
+	var ctx *common.TestContext
+	var visiblePlanID, hiddenPlanID string
+
+	renamed := func(json, name string) string {
+		result, err := sjson.Set(json, "name", name)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	search := func(expect *common.SMExpect, queryString string) *httpexpect.Array {
+		return expect.GET(web.SearchURL).WithQueryString(queryString).
+			Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+	}
+
+	resultNames := func(items *httpexpect.Array, objectType types.ObjectType) []string {
+		names := make([]string, 0)
+		for _, item := range items.Iter() {
+			if item.Object().Value("type").String().Raw() == objectType.String() {
+				names = append(names, item.Object().Path("$.resource.name").String().Raw())
+			}
+		}
+		return names
+	}
+
+	BeforeSuite(func() {
+		ctx = common.NewTestContextBuilderWithSecurity().
+			WithSMExtensions(func(ctx context.Context, smb *sm.ServiceManagerBuilder, e env.Environment) error {
+				extractTenant := multitenancy.ExtractTenantFromTokenWrapperFunc("zid")
+				_, err := smb.EnableMultitenancy(tenantLabelKey, func(request *web.Request) (string, error) {
+					user, ok := web.UserFromContext(request.Context())
+					if !ok {
+						return "", nil
+					}
+					var userData json.RawMessage
+					if err := user.Data(&userData); err != nil {
+						return "", fmt.Errorf("could not unmarshal claims from token: %s", err)
+					}
+					if gjson.GetBytes(userData, "cid").String() != tenantClientID {
+						return "", nil
+					}
+					user.AccessLevel = web.TenantAccess
+					request.Request = request.WithContext(web.ContextWithUser(request.Context(), user))
+					return extractTenant(request)
+				})
+				return err
+			}).
+			Build()
+
+		catalog := common.NewEmptySBCatalog()
+		visiblePlan := renamed(common.GenerateFreeTestPlan(), "searchable-small")
+		hiddenPlan := renamed(common.GeneratePaidTestPlan(), "searchable-large")
+		visiblePlanCatalogID := gjson.Get(visiblePlan, "id").String()
+		catalog.AddService(renamed(common.GenerateTestServiceWithPlans(visiblePlan, hiddenPlan), "searchable-store"))
+		ctx.RegisterBrokerWithCatalogAndLabels(catalog, common.Object{"name": "searchable-broker"})
+
+		for _, plan := range ctx.SMWithOAuth.List(web.ServicePlansURL).Iter() {
+			planID := plan.Object().Value("id").String().Raw()
+			if plan.Object().Value("catalog_id").String().Raw() == visiblePlanCatalogID {
+				visiblePlanID = planID
+			} else {
+				hiddenPlanID = planID
+			}
+		}
+
+		common.RegisterPlatformInSM(common.Object{
+			"name": "searchable-platform",
+			"type": "cloudfoundry",
+		}, ctx.SMWithOAuth, map[string]string{})
+		common.RegisterPlatformInSM(common.Object{
+			"name":        "other-platform",
+			"type":        "cloudfoundry",
+			"description": "mirror of the searchable platform",
+		}, ctx.SMWithOAuth, map[string]string{})
+	})
+
+	AfterSuite(func() {
+		ctx.Cleanup()
+	})
+
+	Context("when the search text is missing", func() {
+		It("returns 400", func() {
+			ctx.SMWithOAuth.GET(web.SearchURL).Expect().Status(http.StatusBadRequest)
+		})
+	})
+
+	Context("when a type cannot be searched", func() {
+		It("returns 400", func() {
+			ctx.SMWithOAuth.GET(web.SearchURL).WithQuery("q", "searchable").WithQuery("types", "visibilities").
+				Expect().Status(http.StatusBadRequest)
+		})
+	})
+
+	It("returns the matching resources of all searchable types", func() {
+		items := search(ctx.SMWithOAuth, "q=searchable")
+		Expect(resultNames(items, types.ServiceBrokerType)).To(ConsistOf("searchable-broker"))
+		Expect(resultNames(items, types.ServiceOfferingType)).To(ConsistOf("searchable-store"))
+		Expect(resultNames(items, types.ServicePlanType)).To(ConsistOf("searchable-small", "searchable-large"))
+		Expect(resultNames(items, types.PlatformType)).To(ConsistOf("searchable-platform", "other-platform"))
+	})
+
+	It("ranks matches of the names above matches of the descriptions", func() {
+		items := search(ctx.SMWithOAuth, "q=searchable platform&types=platforms")
+		items.Length().Equal(2)
+		items.Element(0).Object().Path("$.resource.name").Equal("searchable-platform")
+		items.Element(1).Object().Path("$.resource.name").Equal("other-platform")
+		Expect(items.Element(0).Object().Value("rank").Number().Raw()).
+			To(BeNumerically(">", items.Element(1).Object().Value("rank").Number().Raw()))
+	})
+
+	It("returns only the matching resources of the requested types", func() {
+		items := search(ctx.SMWithOAuth, "q=searchable&types=service_plans,service_offerings")
+		Expect(resultNames(items, types.ServicePlanType)).To(HaveLen(2))
+		Expect(resultNames(items, types.ServiceOfferingType)).To(HaveLen(1))
+		items.Length().Equal(3)
+	})
+
+	It("pages the results", func() {
+		all := search(ctx.SMWithOAuth, "q=searchable")
+		names := make([]interface{}, 0)
+		token := ""
+		for {
+			req := ctx.SMWithOAuth.GET(web.SearchURL).WithQuery("q", "searchable").WithQuery("max_items", 2)
+			if token != "" {
+				req = req.WithQuery("token", token)
+			}
+			page := req.Expect().Status(http.StatusOK).JSON().Object()
+			for _, item := range page.Value("items").Array().Iter() {
+				names = append(names, item.Object().Path("$.resource.name").Raw())
+			}
+			nextToken, found := page.Raw()["token"]
+			if !found {
+				break
+			}
+			token = nextToken.(string)
+		}
+		Expect(names).To(HaveLen(int(all.Length().Raw())))
+		all.Path("$[*].resource.name").Array().Equal(names)
+	})
+
+	Context("with tenant access", func() {
+		It("returns only the resources of the tenant", func() {
+			tenantExpect := ctx.NewTenantExpect(tenantClientID, "tenant-a")
+			common.RegisterPlatformInSM(common.Object{
+				"name": "searchable-tenant-platform",
+				"type": "cloudfoundry",
+			}, tenantExpect, map[string]string{})
+
+			items := search(tenantExpect, "q=searchable&types=platforms")
+			Expect(resultNames(items, types.PlatformType)).To(ConsistOf("searchable-tenant-platform"))
+
+			items = search(ctx.SMWithOAuth, "q=searchable&types=platforms")
+			Expect(resultNames(items, types.PlatformType)).To(ContainElement("searchable-tenant-platform"))
+		})
+	})
+
+	Context("with the credentials of a kubernetes platform", func() {
+		It("returns only the plans visible to the platform", func() {
+			platform := ctx.RegisterPlatformWithType(types.K8sPlatformType)
+			common.RegisterVisibilityForPlanAndPlatform(ctx.SMWithOAuth, visiblePlanID, platform.ID)
+			platformExpect := &common.SMExpect{Expect: ctx.SM.Builder(func(req *httpexpect.Request) {
+				req.WithBasicAuth(platform.Credentials.Basic.Username, platform.Credentials.Basic.Password)
+			})}
+
+			items := search(platformExpect, "q=searchable&types=service_plans")
+			items.Length().Equal(1)
+			items.Element(0).Object().Path("$.resource.id").Equal(visiblePlanID)
+			Expect(hiddenPlanID).ToNot(BeEmpty())
+		})
+	})
+})
@@ -209,6 +209,14 @@ var _ = test.DescribeTestsFor(test.TestCase{
 								assertPlansForPlatform(k8sAgent, planID)
 							})
 
+							It("should embed the service offering of the visible plan", func() {
+								k8sAgent.GET(fmt.Sprintf("%s/%s", web.ServicePlansURL, planID)).
+									WithQuery("expand", "service_offering").
+									Expect().
+									Status(http.StatusOK).
+									JSON().Path("$.service_offering.id").Equal(plan["service_offering_id"])
+							})
+
 							It("should return only one plan with id in field query", func() {
 								assertPlansForPlatformWithQuery(k8sAgent,
 									map[string]interface{}{
@@ -259,6 +267,51 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					})
 				})
 
+				Context("with expand and fields query", func() {
+					var plan common.Object
+
+					BeforeEach(func() {
+						plan = blueprint(ctx, ctx.SMWithOAuth, false)
+					})
+
+					It("should embed the service offering of the plan", func() {
+						ctx.SMWithOAuth.GET(fmt.Sprintf("%s/%s", web.ServicePlansURL, plan["id"])).
+							WithQuery("expand", "service_offering").
+							Expect().
+							Status(http.StatusOK).
+							JSON().Path("$.service_offering.id").Equal(plan["service_offering_id"])
+					})
+
+					It("should return only the requested fields", func() {
+						result := ctx.SMWithOAuth.GET(fmt.Sprintf("%s/%s", web.ServicePlansURL, plan["id"])).
+							WithQuery("fields", "name").
+							Expect().
+							Status(http.StatusOK).
+							JSON().Object()
+						result.Keys().ContainsOnly("id", "name")
+					})
+
+					It("should return the expanded relations together with the requested fields", func() {
+						result := ctx.SMWithOAuth.GET(fmt.Sprintf("%s/%s", web.ServicePlansURL, plan["id"])).
+							WithQuery("fields", "name").
+							WithQuery("expand", "service_offering.service_broker").
+							Expect().
+							Status(http.StatusOK).
+							JSON().Object()
+						result.Keys().ContainsOnly("id", "name", "service_offering")
+						result.Path("$.service_offering.id").Equal(plan["service_offering_id"])
+						result.Path("$.service_offering.service_broker.id").NotNull()
+						result.Path("$.service_offering.service_broker").Object().NotContainsKey("credentials")
+					})
+
+					It("should reject unsupported relations", func() {
+						ctx.SMWithOAuth.GET(web.ServicePlansURL).
+							WithQuery("expand", "service_broker").
+							Expect().
+							Status(http.StatusBadRequest)
+					})
+				})
+
 			})
 
 			Describe("Labelled", func() {
@@ -206,6 +206,46 @@ var _ = Describe("WS", func() {
 			})
 		})
 
+		Context("and revision known to proxy is not known to sm anymore and snapshot is requested", func() {
+			var brokerID string
+
+			BeforeEach(func() {
+				brokerID = ctx.RegisterBroker().Broker.ID
+			})
+
+			It("should receive a snapshot of the current state followed by its end and new notifications", func() {
+				queryParams[notifications.LastKnownRevisionQueryParam] = strconv.FormatInt(notification.Revision-1, 10)
+				queryParams[notifications.SnapshotQueryParam] = "true"
+				conn, resp, err := ctx.ConnectWebSocket(platform, queryParams)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(resp.Header.Get(notifications.SnapshotHeader)).To(Equal("true"))
+				snapshotRevision, err := strconv.ParseInt(resp.Header.Get(notifications.LastKnownRevisionHeader), 10, 64)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				brokerNotification := readNotification(conn)
+				Expect(brokerNotification["resource"]).To(Equal(string(types.ServiceBrokerType)))
+				Expect(brokerNotification["type"]).To(Equal(string(types.CREATED)))
+				Expect(brokerNotification["platform_id"]).To(Equal(platform.ID))
+				Expect(brokerNotification["revision"]).To(BeNumerically("==", snapshotRevision))
+				payload := brokerNotification["payload"].(map[string]interface{})
+				resource := payload["new"].(map[string]interface{})["resource"].(map[string]interface{})
+				Expect(resource["id"]).To(Equal(brokerID))
+				Expect(resource).ToNot(HaveKey("credentials"))
+
+				for {
+					receivedNotification := readNotification(conn)
+					Expect(receivedNotification["revision"]).To(BeNumerically("==", snapshotRevision))
+					if receivedNotification["type"] == string(types.SNAPSHOT_END) {
+						break
+					}
+					Expect(receivedNotification["resource"]).To(Equal(string(types.VisibilityType)))
+				}
+
+				newNotification := createNotification(repository, platform.ID)
+				Expect(readNotification(conn)["id"]).To(Equal(newNotification.ID))
+			})
+		})
+
 		Context("and proxy known revision is greater than sm known revision", func() {
 			It("should receive 410 Gone", func() {
 				queryParams[notifications.LastKnownRevisionQueryParam] = strconv.FormatInt(notification.Revision+1, 10)
@@ -331,6 +371,87 @@ var _ = Describe("WS", func() {
 		})
 	})
 
+	Context("when subscribed to a resource type", func() {
+		BeforeEach(func() {
+			queryParams[notifications.ResourceTypesQueryParam] = string(types.VisibilityType)
+		})
+
+		It("should receive only notifications for this resource type", func() {
+			createNotification(repository, platform.ID)
+			visibilityNotification := common.GenerateRandomNotification()
+			visibilityNotification.PlatformID = platform.ID
+			visibilityNotification.Resource = types.VisibilityType
+			_, err := repository.Create(context.Background(), visibilityNotification)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			expectNotification(wsconn, visibilityNotification.ID, platform.ID)
+		})
+	})
+
+	Context("when subscribed to an unknown resource type", func() {
+		It("should return status 400", func() {
+			queryParams[notifications.ResourceTypesQueryParam] = string(types.VisibilityType) + "," + string(types.PlatformType)
+			_, resp, err := ctx.ConnectWebSocket(platform, queryParams)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when label query is invalid", func() {
+		It("should return status 400", func() {
+			queryParams["labelQuery"] = "env eq"
+			_, resp, err := ctx.ConnectWebSocket(platform, queryParams)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when notifications are acknowledged", func() {
+		BeforeEach(func() {
+			queryParams[notifications.AckQueryParam] = "true"
+		})
+
+		It("should redeliver the unacknowledged notifications on reconnect", func() {
+			notification1 := createNotification(repository, platform.ID)
+			notification2 := createNotification(repository, platform.ID)
+			expectNotification(wsconn, notification1.ID, platform.ID)
+			expectNotification(wsconn, notification2.ID, platform.ID)
+
+			err := wsconn.WriteJSON(&notifications.Acknowledgement{Revision: notification1.Revision})
+			Expect(err).ShouldNot(HaveOccurred())
+			// the acknowledgement is stored asynchronously, so the lag may be missing in the first responses
+			Eventually(func() interface{} {
+				return ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + platform.ID).Expect().
+					Status(http.StatusOK).JSON().Object().Raw()["notifications_lag"]
+			}, pingTimeout).Should(Equal(float64(1)))
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("fieldQuery", fmt.Sprintf("id eq '%s'", platform.ID)).Expect().
+				Status(http.StatusOK).JSON().Path("$.items[0].notifications_lag").Equal(1)
+
+			conn, _, err := ctx.ConnectWebSocket(platform, queryParams)
+			Expect(err).ShouldNot(HaveOccurred())
+			expectNotification(conn, notification2.ID, platform.ID)
+		})
+
+		It("should reject acknowledgements of revisions after the last notification", func() {
+			notification1 := createNotification(repository, platform.ID)
+			notification2 := createNotification(repository, platform.ID)
+			expectNotification(wsconn, notification1.ID, platform.ID)
+			expectNotification(wsconn, notification2.ID, platform.ID)
+
+			notificationsLag := func() interface{} {
+				return ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + platform.ID).Expect().
+					Status(http.StatusOK).JSON().Object().Raw()["notifications_lag"]
+			}
+			err := wsconn.WriteJSON(&notifications.Acknowledgement{Revision: notification1.Revision})
+			Expect(err).ShouldNot(HaveOccurred())
+			Eventually(notificationsLag, pingTimeout).Should(Equal(float64(1)))
+
+			err = wsconn.WriteJSON(&notifications.Acknowledgement{Revision: notification2.Revision + 100})
+			Expect(err).ShouldNot(HaveOccurred())
+			Consistently(notificationsLag, time.Second).Should(Equal(float64(1)))
+		})
+	})
+
 	Context("when notification are created after ws conn is created", func() {
 		It("should receive new notifications", func() {
 			notification := createNotification(repository, platform.ID)